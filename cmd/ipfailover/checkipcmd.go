@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"go.uber.org/zap"
+)
+
+// checkIPTimeout bounds the check-ip command, which may try several endpoints
+const checkIPTimeout = 30 * time.Second
+
+// runCheckIPCommand implements the check-ip subcommand: it detects the current public IP through
+// the check endpoints of the configuration, or those given with -endpoint, prints it to stdout and
+// returns the exit code. Without -verbose nothing else is printed on success, for use in scripts.
+func runCheckIPCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check-ip", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays, endpoints stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	flags.Var(&endpoints, "endpoint", "Check endpoint URL used instead of the configured ones, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file, for the check endpoints, proxy and source (optional with -endpoint)")
+	verbose := flags.Bool("verbose", false, "Also print the endpoint that responded and its response time")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover check-ip -config /path/to/config.yaml [-endpoint URL] [-verbose]\n\n")
+		fmt.Fprintf(stderr, "Print the current public IP address.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" && len(endpoints) == 0 {
+		fmt.Fprintf(stderr, "Error: -config or -endpoint flag is required for check-ip\n")
+		return 1
+	}
+
+	cfg := &config.Config{}
+	if *configFile != "" {
+		loaded, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		for _, overlay := range missing {
+			fmt.Fprintf(stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+		}
+		cfg = loaded
+	}
+	if len(endpoints) == 0 {
+		endpoints = cfg.CheckEndpoints
+	}
+
+	checker := ipchecker.NewHTTPChecker(endpoints, zap.NewNop())
+	if proxy := cfg.ProxyFunc(); proxy != nil {
+		checker.SetProxy(proxy)
+	}
+	if source := cfg.Source(); !source.IsZero() {
+		if err := source.Validate(); err != nil {
+			fmt.Fprintf(stderr, "Invalid source: %v\n", err)
+			return 1
+		}
+		checker.SetSource(source)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkIPTimeout)
+	defer cancel()
+
+	result, err := checker.Check(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "IP check failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, result.IP)
+	if *verbose {
+		fmt.Fprintf(stdout, "Endpoint: %s\n", result.Endpoint)
+		fmt.Fprintf(stdout, "Response time: %s\n", result.ResponseTime.Round(time.Millisecond))
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+)
+
+// configAPIPath is the admin API path serving the redacted effective configuration
+const configAPIPath = "/api/v1/config"
+
+// configHandler serves the redacted effective configuration as JSON, or as YAML with ?format=yaml
+func (app *Application) configHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		redacted := app.getConfig().Redact()
+
+		var data []byte
+		var err error
+		switch format := r.URL.Query().Get("format"); format {
+		case "", "json":
+			w.Header().Set("Content-Type", "application/json")
+			data, err = json.MarshalIndent(redacted, "", "  ")
+			data = append(data, '\n')
+		case "yaml":
+			w.Header().Set("Content-Type", "application/yaml")
+			data, err = yaml.Marshal(redacted)
+		default:
+			http.Error(w, fmt.Sprintf("unsupported format %q, use json or yaml", format), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			app.logger.Error("failed to encode configuration", zap.Error(err))
+			http.Error(w, "failed to encode configuration", http.StatusInternalServerError)
+			return
+		}
+
+		if _, err := w.Write(data); err != nil {
+			app.logger.Error("failed to write configuration response", zap.Error(err))
+		}
+	})
+}
+
+// runDiffConfig prints the differences between the running configuration and the candidate file.
+// The running configuration is fetched from the daemon at daemonURL, or loaded from the local
+// configuration file and overlays if daemonURL is empty. Secrets are compared in redacted form only.
+func runDiffConfig(candidatePath, daemonURL, configPath string, overlays []string, out io.Writer) error {
+	candidate, err := config.LoadConfig(candidatePath)
+	if err != nil {
+		return fmt.Errorf("failed to load candidate configuration: %w", err)
+	}
+
+	var running map[string]interface{}
+	if daemonURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		running, err = fetchRunningConfig(ctx, daemonURL)
+		if err != nil {
+			return err
+		}
+	} else {
+		if configPath == "" {
+			return fmt.Errorf("-daemon-url or -config is required with -diff-config")
+		}
+		cfg, missing, err := config.LoadConfigWithOverlays(configPath, overlays)
+		if err != nil {
+			return fmt.Errorf("failed to load running configuration: %w", err)
+		}
+		warnMissingOverlays(missing)
+		running = cfg.Redact()
+	}
+
+	changes := config.Diff(running, candidate.Redact())
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(out, "No differences")
+		return err
+	}
+
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(out, change.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchRunningConfig fetches the redacted configuration from the admin API of a running daemon
+func fetchRunningConfig(ctx context.Context, daemonURL string) (map[string]interface{}, error) {
+	requestURL := strings.TrimSuffix(daemonURL, "/") + configAPIPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch running configuration: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("failed to fetch running configuration"))
+	}
+
+	var running map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&running); err != nil {
+		return nil, fmt.Errorf("failed to decode running configuration: %w", err)
+	}
+	return running, nil
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/audit"
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/internal/state"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestApplication_EgressIPWeight(t *testing.T) {
+	ipChecker := ipchecker.NewMockChecker("203.0.113.10", nil)
+	healthChecker := healthcheck.NewMockChecker()
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipChecker, healthChecker, provider)
+	app.config.EgressIPWeight = 2
+	ctx := context.Background()
+	value := func() string {
+		v, _ := provider.value("home.example.com", "A")
+		return v
+	}
+	failures := func() int {
+		count, err := app.stateStore.GetPrimaryFailureCount(ctx)
+		require.NoError(t, err)
+		return count
+	}
+
+	// Egressing through the primary uplink counts as a success without a reachability check
+	healthChecker.SetError("203.0.113.10", errors.New("connection refused"))
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+	assert.Zero(t, failures())
+	assert.Zero(t, healthChecker.GetChecks("203.0.113.10"))
+
+	// Egressing through the secondary uplink counts as two failures, reaching failover_retries at once
+	healthChecker.SetError("203.0.113.10", nil)
+	ipChecker.SetIP("198.51.100.20")
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "198.51.100.20", value())
+	assert.Equal(t, 2, failures())
+	assert.Zero(t, healthChecker.GetChecks("203.0.113.10"))
+
+	// Any other public IP leaves the decision to the reachability check
+	ipChecker.SetIP("192.0.2.50")
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+	assert.Zero(t, failures())
+	assert.Equal(t, 1, healthChecker.GetChecks("203.0.113.10"))
+
+	// Without a weight the public IP is ignored
+	app.config.EgressIPWeight = 0
+	ipChecker.SetIP("198.51.100.20")
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+	assert.Zero(t, failures())
+	assert.Equal(t, 2, healthChecker.GetChecks("203.0.113.10"))
+}
+
+// recordingNotifier sends the events it is notified of to events
+type recordingNotifier struct {
+	events chan interfaces.NotificationEvent
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	n.events <- event
+	return nil
+}
+
+func TestApplication_NotificationEvents(t *testing.T) {
+	ipChecker := ipchecker.NewMockChecker("203.0.113.10", nil)
+	healthChecker := healthcheck.NewMockChecker()
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipChecker, healthChecker, provider)
+	app.config.FailoverRetries = 1
+	notifier := &recordingNotifier{events: make(chan interfaces.NotificationEvent, 10)}
+	app.notifications = notification.NewFanOutNotifier(notifier)
+	app.digest = notification.NewDigest(time.Now())
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	app.audit = audit.NewLogger(auditPath, 0, 0)
+	ctx := context.Background()
+	next := func() interfaces.NotificationEvent {
+		select {
+		case event := <-notifier.events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("no notification sent")
+			return interfaces.NotificationEvent{}
+		}
+	}
+
+	// The first IP applied without state is not a failover
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+
+	healthChecker.SetError("203.0.113.10", errors.New("connection refused"))
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	// Notifications are sent concurrently, so they may arrive in any order
+	started, completed := next(), next()
+	if started.Type != notification.TypeFailoverStarted {
+		started, completed = completed, started
+	}
+	assert.Equal(t, notification.TypeFailoverStarted, started.Type)
+	assert.Equal(t, "203.0.113.10", started.FromIP)
+	assert.Equal(t, "198.51.100.20", started.ToIP)
+	assert.Equal(t, []string{"home.example.com"}, started.Records)
+	assert.False(t, started.Timestamp.IsZero())
+	assert.Equal(t, notification.TypeFailover, completed.Type)
+	assert.Equal(t, map[string]string{"home.example.com": "203.0.113.10"}, completed.PreviousValues)
+	assert.False(t, completed.Cached)
+
+	// A failback is only reported once completed
+	healthChecker.SetError("203.0.113.10", nil)
+	provider.updateErr = errors.New("API unavailable")
+	require.Error(t, app.checkAndUpdateIP(ctx))
+	failed := next()
+	assert.Equal(t, notification.TypeDNSUpdateFailed, failed.Type)
+	assert.Equal(t, "198.51.100.20", failed.FromIP)
+	assert.Equal(t, "203.0.113.10", failed.ToIP)
+	assert.Contains(t, failed.Message, "API unavailable")
+
+	// A record that cannot be read is reported with its last known value
+	provider.updateErr = nil
+	provider.getErr = errors.New("read timeout")
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	failback := next()
+	assert.Equal(t, notification.TypeFailback, failback.Type)
+	assert.Equal(t, map[string]string{"home.example.com": "198.51.100.20"}, failback.PreviousValues)
+	assert.True(t, failback.Cached)
+	provider.getErr = nil
+
+	ipChecker.SetError(errors.New("all endpoints failed"))
+	require.Error(t, app.checkAndUpdateIP(ctx))
+	checkFailed := next()
+	assert.Equal(t, notification.TypeIPCheckFailed, checkFailed.Type)
+	assert.Contains(t, checkFailed.Message, "all endpoints failed")
+
+	select {
+	case event := <-notifier.events:
+		t.Fatalf("unexpected notification %s", event.Type)
+	default:
+	}
+
+	summary := app.digest.Flush(time.Now())
+	assert.Equal(t, 5, summary.Checks)
+	assert.Equal(t, 1, summary.CheckFailures)
+	assert.Equal(t, 1, summary.Failovers)
+	assert.Equal(t, 1, summary.Failbacks)
+	assert.Equal(t, 1, summary.UpdateFailures)
+	require.Len(t, summary.Errors, 2)
+	assert.Contains(t, summary.Errors[0], "API unavailable")
+	assert.Contains(t, summary.Errors[1], "all endpoints failed")
+
+	require.NoError(t, app.audit.Close())
+	data, err := os.ReadFile(auditPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	var changes []map[string]any
+	for _, line := range lines {
+		var change map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &change))
+		changes = append(changes, change)
+	}
+	assert.Equal(t, "198.51.100.20", changes[1]["to_ip"])
+	assert.Equal(t, false, changes[1]["from_ip_cached"])
+	assert.Equal(t, "198.51.100.20", changes[2]["from_ip"])
+	assert.Equal(t, true, changes[2]["from_ip_cached"])
+}
+
+func TestApplication_IncidentNotifications(t *testing.T) {
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthcheck.NewMockChecker(), provider)
+	app.config.IncidentThreshold = 2
+	notifier := &recordingNotifier{events: make(chan interfaces.NotificationEvent, 10)}
+	app.notifications = notification.NewFanOutNotifier(notifier)
+	app.digest = notification.NewDigest(time.Now())
+	ctx := context.Background()
+
+	// Incident events are sent alongside the failed updates, in any order
+	var incidents []interfaces.NotificationEvent
+	collect := func(n int) {
+		for i := 0; i < n; i++ {
+			select {
+			case event := <-notifier.events:
+				if event.Type != notification.TypeDNSUpdateFailed {
+					incidents = append(incidents, event)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no notification sent")
+			}
+		}
+	}
+
+	provider.updateErr = errors.New("API unavailable")
+	require.Error(t, app.checkAndUpdateIP(ctx))
+	collect(1)
+	require.Error(t, app.checkAndUpdateIP(ctx))
+	collect(2)
+	require.Len(t, incidents, 1)
+	assert.Equal(t, notification.TypeIncidentOpened, incidents[0].Type)
+	assert.Equal(t, "cloudflare", incidents[0].Provider)
+	assert.Contains(t, incidents[0].Message, "API unavailable")
+	assert.False(t, incidents[0].Timestamp.IsZero())
+
+	provider.updateErr = nil
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	collect(1)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, notification.TypeIncidentResolved, incidents[1].Type)
+	assert.Equal(t, "cloudflare", incidents[1].Provider)
+
+	summary := app.digest.Flush(time.Now())
+	assert.Equal(t, 2, summary.UpdateFailures)
+	assert.Equal(t, 1, summary.Incidents)
+}
+
+func TestApplication_EgressEvidence(t *testing.T) {
+	app := &Application{}
+	cfg := &config.Config{
+		PrimaryIP:      "2001:db8::10",
+		SecondaryIP:    "203.0.113.20",
+		EgressIPWeight: 1,
+	}
+
+	assert.Equal(t, egressPrimary, app.egressEvidence(cfg, "2001:0db8:0:0::10"))
+	assert.Equal(t, egressSecondary, app.egressEvidence(cfg, "::ffff:203.0.113.20"))
+	assert.Equal(t, egressUnknown, app.egressEvidence(cfg, "198.51.100.7"))
+	assert.Equal(t, egressUnknown, app.egressEvidence(cfg, ""))
+
+	cfg.EgressIPWeight = 0
+	assert.Equal(t, egressUnknown, app.egressEvidence(cfg, "203.0.113.20"))
+}
+
+func TestApplication_SameNameRecordOverrides(t *testing.T) {
+	ipChecker := ipchecker.NewMockChecker("203.0.113.10", nil)
+	public := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	lan := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipChecker, healthcheck.NewMockChecker(), public)
+	app.config.StateFile = filepath.Join(t.TempDir(), "state.json")
+	// The same record published by two providers, both failing over on their own
+	app.config.DNS = []config.DNSConfig{
+		{Name: "cdn.example.com", Type: "A", Provider: "cloudflare", TTL: 300, FailoverRetries: 5},
+		{Name: "cdn.example.com", Type: "A", Provider: "adguard", TTL: 300, FailoverRetries: 5},
+	}
+	app.dnsProviders = map[string]interfaces.DNSProvider{
+		app.config.DNS[0].Key(): public,
+		app.config.DNS[1].Key(): lan,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	publicValue, _ := public.value("cdn.example.com", "A")
+	lanValue, _ := lan.value("cdn.example.com", "A")
+	assert.Equal(t, "203.0.113.10", publicValue)
+	assert.Equal(t, "203.0.113.10", lanValue, "the second record is written, not skipped as already applied")
+
+	// Each record keeps the applied IP in a state file of its own
+	for _, name := range []string{"state.cdn.example.com_A_cloudflare.json", "state.cdn.example.com_A_adguard.json"} {
+		store := state.NewFileStateStore(filepath.Join(filepath.Dir(app.config.StateFile), name), zap.NewNop())
+		applied, err := store.GetLastAppliedIP(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.10", applied, name)
+	}
+}
+
+func TestApplication_DetermineTargetIP(t *testing.T) {
+	const (
+		primary   = "203.0.113.10"
+		secondary = "198.51.100.20"
+	)
+	// A step checks the primary once, reachable unless down, and expects the target IP
+	type step struct {
+		down bool
+		want string
+	}
+
+	tests := []struct {
+		name            string
+		failoverRetries int
+		failbackRetries int
+		failbackDelay   time.Duration
+		lastAppliedIP   string
+		recoveredAgo    time.Duration // Primary recovery recorded this long ago, if set
+		steps           []step
+	}{
+		{
+			name:            "fails over once the failures reach the threshold",
+			failoverRetries: 3,
+			failbackRetries: 1,
+			lastAppliedIP:   primary,
+			steps:           []step{{true, primary}, {true, primary}, {true, secondary}, {true, secondary}},
+		},
+		{
+			name:            "a reachable primary resets the failure count",
+			failoverRetries: 3,
+			failbackRetries: 1,
+			lastAppliedIP:   primary,
+			steps:           []step{{true, primary}, {true, primary}, {false, primary}, {true, primary}, {true, primary}, {true, secondary}},
+		},
+		{
+			name:            "fails back after consecutive successes",
+			failoverRetries: 1,
+			failbackRetries: 3,
+			lastAppliedIP:   secondary,
+			steps:           []step{{false, secondary}, {false, secondary}, {false, primary}},
+		},
+		{
+			name:            "a failure resets the success count",
+			failoverRetries: 1,
+			failbackRetries: 2,
+			lastAppliedIP:   secondary,
+			steps:           []step{{false, secondary}, {true, secondary}, {false, secondary}, {false, primary}},
+		},
+		{
+			name:            "fails back only after the failback delay",
+			failoverRetries: 1,
+			failbackRetries: 1,
+			failbackDelay:   time.Hour,
+			lastAppliedIP:   secondary,
+			steps:           []step{{false, secondary}, {false, secondary}},
+		},
+		{
+			name:            "fails back once the failback delay has passed",
+			failoverRetries: 1,
+			failbackRetries: 1,
+			failbackDelay:   time.Hour,
+			lastAppliedIP:   secondary,
+			recoveredAgo:    2 * time.Hour,
+			steps:           []step{{false, primary}},
+		},
+		{
+			name:            "a failure restarts the failback delay",
+			failoverRetries: 1,
+			failbackRetries: 1,
+			failbackDelay:   time.Hour,
+			lastAppliedIP:   secondary,
+			recoveredAgo:    2 * time.Hour,
+			steps:           []step{{true, secondary}, {false, secondary}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthChecker := healthcheck.NewMockChecker()
+			app := newTestApplication(ipchecker.NewMockChecker(primary, nil), healthChecker,
+				&recordingProvider{records: make(map[string]interfaces.DNSRecord)})
+			app.config.FailoverRetries = tt.failoverRetries
+			app.config.FailbackRetries = tt.failbackRetries
+			app.config.FailbackDelay = tt.failbackDelay
+			ctx := context.Background()
+			dnsConfig := app.config.DNS[0]
+
+			if tt.recoveredAgo > 0 {
+				require.NoError(t, app.stateStore.SetPrimaryRecovery(ctx, time.Now().Add(-tt.recoveredAgo), 0))
+			}
+
+			lastAppliedIP := tt.lastAppliedIP
+			for i, s := range tt.steps {
+				var err error
+				if s.down {
+					err = errors.New("connection refused")
+				}
+				healthChecker.SetError(primary, err)
+
+				lastAppliedIP = app.determineTargetIP(ctx, dnsConfig, lastAppliedIP, "")
+				require.Equal(t, s.want, lastAppliedIP, "step %d", i+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/state"
+	"go.uber.org/zap"
+)
+
+// runFailoverCommand implements the failover subcommand: it stores a forced target in the state
+// file of the configuration, or clears it, for the running daemon to apply on its next check, see
+// Application.SetForcedTarget, and returns the exit code
+func runFailoverCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("failover", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file, used to locate the state file")
+	to := flags.String("to", "", "Pin the DNS records to the primary or secondary IPs regardless of reachability")
+	clearTarget := flags.Bool("clear", false, "Clear the forced target and resume automatic failover decisions")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover failover -config /path/to/config.yaml -to primary|secondary\n")
+		fmt.Fprintf(stderr, "       ipfailover failover -config /path/to/config.yaml -clear\n\n")
+		fmt.Fprintf(stderr, "Force the failover target of the running daemon until it is cleared.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" {
+		fmt.Fprintf(stderr, "Error: -config flag is required for failover\n")
+		return 1
+	}
+	switch {
+	case *clearTarget && *to != "":
+		fmt.Fprintf(stderr, "Error: -to and -clear are mutually exclusive\n")
+		return 1
+	case !*clearTarget && *to != api.TargetPrimary && *to != api.TargetSecondary:
+		fmt.Fprintf(stderr, "Error: -to must be %s or %s, or -clear given\n", api.TargetPrimary, api.TargetSecondary)
+		return 1
+	}
+
+	cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	for _, overlay := range missing {
+		fmt.Fprintf(stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := state.NewFileStateStore(cfg.StateFile, zap.NewNop()).SetForcedTarget(ctx, *to); err != nil {
+		fmt.Fprintf(stderr, "Failed to store forced target: %v\n", err)
+		return 1
+	}
+	if *clearTarget {
+		fmt.Fprintf(stdout, "Cleared forced target in %s, automatic failover resumes on the next check\n", cfg.StateFile)
+	} else {
+		fmt.Fprintf(stdout, "Forced target %s stored in %s, it is applied on the next check\n", *to, cfg.StateFile)
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// ForceFailover switches the DNS records of all failover groups to their primary or secondary IP,
+// as requested through the API, without checking reachability. Later checks proceed as usual from
+// the new IP, so a forced failover to the secondary IP is failed back once the failback conditions are met.
+// To keep the records on an IP, see SetForcedTarget.
+func (app *Application) ForceFailover(ctx context.Context, req api.ForceFailoverRequest) error {
+	// A client that disconnects must not leave the failover half applied
+	ctx = context.WithoutCancel(ctx)
+
+	app.checkMu.Lock()
+	defer app.checkMu.Unlock()
+
+	app.logger.Warn("forced failover requested",
+		zap.String("target", req.Target),
+		zap.String("reason", req.Reason),
+		zap.String("requested_by", req.RequestedBy),
+	)
+
+	var errs error
+	for _, cfg := range app.getConfig().FailoverGroups() {
+		targetIP := cfg.SecondaryIP
+		if req.Target == api.TargetPrimary {
+			targetIP = cfg.PrimaryIP
+		}
+
+		dnsConfig := cfg.DNS[0]
+		group := app.failoverGroup(ctx, dnsConfig)
+		lastAppliedIP, err := group.store.GetLastAppliedIP(ctx)
+		if err != nil {
+			app.logger.Warn("failed to get last applied IP", zap.Error(err))
+		}
+
+		app.logger.Info("forcing failover",
+			zap.String("record", dnsConfig.Name),
+			recordIDField(dnsConfig),
+			zap.String("from_ip", lastAppliedIP),
+			zap.String("to_ip", targetIP),
+		)
+
+		// A pending change or recovery counted towards the previous IP no longer applies
+		app.resetPendingIP(ctx, group.store)
+		app.resetPrimaryRecovery(ctx, group.store)
+
+		if _, err := app.applyTargetIP(ctx, cfg, group, targetIP, lastAppliedIP); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("failover group of %s: %w", dnsConfig.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// forcedTargetIP returns the IP of cfg that target pins the DNS records to, empty if target is not set
+func forcedTargetIP(cfg *config.Config, target string) string {
+	switch target {
+	case api.TargetPrimary:
+		return cfg.PrimaryIP
+	case api.TargetSecondary:
+		return cfg.SecondaryIP
+	default:
+		return ""
+	}
+}
+
+// loadForcedTarget reads the target pinned by a manual failover from state, where the failover
+// command stores it for the running daemon, and reports it in the metrics. The caller must hold checkMu.
+func (app *Application) loadForcedTarget(ctx context.Context) {
+	target, err := app.stateStore.GetForcedTarget(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		app.logger.Warn("failed to get forced target, keeping the previous one",
+			zap.String("forced_target", app.forcedTarget),
+			zap.Error(err),
+		)
+		return
+	}
+	if target != app.forcedTarget {
+		app.logForcedTarget(target, "state")
+	}
+	app.forcedTarget = target
+	app.metrics.SetForcedTarget(target)
+}
+
+// SetForcedTarget pins the DNS records of all failover groups to target, api.TargetPrimary or
+// api.TargetSecondary, regardless of reachability, or resumes automatic failover decisions if target
+// is empty. The target is stored in state, so it persists across restarts, and applied by the next
+// check. source tells what requested the change, for the log.
+func (app *Application) SetForcedTarget(ctx context.Context, target, source string) error {
+	app.checkMu.Lock()
+	defer app.checkMu.Unlock()
+	return app.setForcedTarget(ctx, target, source)
+}
+
+// toggleForcedSecondary pins the DNS records to the secondary IPs, or resumes automatic failover
+// decisions if they already are, see SetForcedTarget
+func (app *Application) toggleForcedSecondary(ctx context.Context, source string) error {
+	app.checkMu.Lock()
+	defer app.checkMu.Unlock()
+
+	// The failover command may have changed the target since the last check
+	app.loadForcedTarget(ctx)
+	target := api.TargetSecondary
+	if app.forcedTarget == api.TargetSecondary {
+		target = ""
+	}
+	return app.setForcedTarget(ctx, target, source)
+}
+
+// setForcedTarget implements SetForcedTarget, the caller must hold checkMu
+func (app *Application) setForcedTarget(ctx context.Context, target, source string) error {
+	if err := app.stateStore.SetForcedTarget(ctx, target); err != nil {
+		return fmt.Errorf("failed to store forced target: %w", err)
+	}
+	app.logForcedTarget(target, source)
+	app.forcedTarget = target
+	app.metrics.SetForcedTarget(target)
+	return nil
+}
+
+// logForcedTarget logs a change of the forced target requested by source
+func (app *Application) logForcedTarget(target, source string) {
+	if target == "" {
+		app.logger.Info("forced target cleared, resuming automatic failover decisions",
+			zap.String("source", source),
+		)
+		return
+	}
+	app.logger.Warn("forced target set, automatic failover decisions suspended",
+		zap.String("forced_target", target),
+		zap.String("source", source),
+	)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/state"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestApplication_ForcedTarget(t *testing.T) {
+	healthChecker := healthcheck.NewMockChecker()
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthChecker, provider)
+	app.config.MinChangeInterval = time.Hour
+	ctx := context.Background()
+	value := func() string {
+		v, _ := provider.value("home.example.com", "A")
+		return v
+	}
+
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+	assert.Equal(t, 1, healthChecker.GetChecks("203.0.113.10"))
+
+	// The reachable primary is left at once, without reaching failover_retries or min_change_interval
+	require.NoError(t, app.toggleForcedSecondary(ctx, "test"))
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "198.51.100.20", value())
+	require.NoError(t, app.checkReachability(ctx))
+	assert.Equal(t, "198.51.100.20", value())
+	assert.Equal(t, 1, healthChecker.GetChecks("203.0.113.10"), "reachability checked while forced")
+
+	status, err := app.APIStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, api.TargetSecondary, status.ForcedTarget)
+
+	// A restarted daemon keeps the forced target
+	restarted := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthChecker, provider)
+	restarted.stateStore = app.stateStore
+	restarted.config.MinChangeInterval = time.Hour
+	require.NoError(t, restarted.checkAndUpdateIP(ctx))
+	assert.Equal(t, "198.51.100.20", value())
+	assert.Equal(t, 1, healthChecker.GetChecks("203.0.113.10"), "reachability checked while forced")
+
+	// Toggling again resumes automatic decisions
+	require.NoError(t, restarted.toggleForcedSecondary(ctx, "test"))
+	target, err := restarted.stateStore.GetForcedTarget(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, target)
+	require.NoError(t, restarted.checkAndUpdateIP(ctx))
+	assert.Equal(t, 2, healthChecker.GetChecks("203.0.113.10"))
+
+	// A forced primary is kept while it is unreachable
+	require.NoError(t, app.SetForcedTarget(ctx, api.TargetPrimary, "test"))
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+	require.NoError(t, app.SetForcedTarget(ctx, "", "test"))
+}
+
+func TestApplication_CheckAndRecordStatus(t *testing.T) {
+	healthChecker := healthcheck.NewMockChecker()
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthChecker, provider)
+
+	status, err := app.APIStatus(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, status.ActiveTarget)
+	assert.Equal(t, []api.RecordStatus{{Name: "home.example.com", Type: "A", Provider: "cloudflare"}}, status.Records)
+
+	// A client disconnecting does not interrupt the requested check
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, app.Check(ctx))
+	v, _ := provider.value("home.example.com", "A")
+	assert.Equal(t, "203.0.113.10", v)
+
+	status, err = app.APIStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, api.TargetPrimary, status.ActiveTarget)
+	assert.Equal(t, []api.RecordStatus{
+		{Name: "home.example.com", Type: "A", Provider: "cloudflare", LastAppliedIP: "203.0.113.10", ActiveTarget: api.TargetPrimary},
+	}, status.Records)
+}
+
+func TestRunFailoverCommand(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, "state.json")
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+primary_ip: "203.0.113.10"
+secondary_ip: "198.51.100.20"
+state_file: "`+stateFile+`"
+dns:
+  - name: "home.example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "token"
+      zone_id: "zone"
+`), 0o600))
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, runFailoverCommand([]string{"-config", configFile, "--to", "secondary"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Forced target secondary stored in "+stateFile)
+	target, err := store.GetForcedTarget(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, api.TargetSecondary, target)
+
+	// The running daemon picks the target up on its next check
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthcheck.NewMockChecker(), provider)
+	app.stateStore = store
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	v, _ := provider.value("home.example.com", "A")
+	assert.Equal(t, "198.51.100.20", v)
+
+	stdout.Reset()
+	require.Equal(t, 0, runFailoverCommand([]string{"-config", configFile, "-clear"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Cleared forced target")
+	target, err = store.GetForcedTarget(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, target)
+
+	for _, args := range [][]string{
+		{"-config", configFile},
+		{"-config", configFile, "-to", "tertiary"},
+		{"-config", configFile, "-to", "primary", "-clear"},
+		{"-to", "primary"},
+	} {
+		stderr.Reset()
+		assert.Equal(t, 1, runFailoverCommand(args, &stdout, &stderr), args)
+		assert.Contains(t, stderr.String(), "Error:", args)
+	}
+}
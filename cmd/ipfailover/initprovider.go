@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"golang.org/x/term"
+)
+
+// runInitProvider prints a commented configuration snippet for the provider.
+// In interactive mode it prompts for the record and provider settings instead and
+// appends the resulting record to the configuration file.
+func runInitProvider(providerName string, interactive bool, configPath string) error {
+	provider, err := config.LookupProvider(providerName)
+	if err != nil {
+		return err
+	}
+
+	if !interactive {
+		fmt.Print(provider.Snippet())
+		return nil
+	}
+
+	if configPath == "" {
+		return fmt.Errorf("-config is required with -interactive")
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+
+	name, err := p.ask("Record name (e.g. home.example.com)", "", true)
+	if err != nil {
+		return err
+	}
+	recordType, err := p.ask("Record type", "A", true)
+	if err != nil {
+		return err
+	}
+	ttlValue, err := p.ask("TTL in seconds", "300", true)
+	if err != nil {
+		return err
+	}
+	ttl, err := strconv.Atoi(ttlValue)
+	if err != nil {
+		return fmt.Errorf("TTL must be an integer: %w", err)
+	}
+
+	values := make(map[string]string, len(provider.Fields))
+	for _, field := range provider.Fields {
+		label := fmt.Sprintf("%s [%s]", field.Description, field.Key)
+		if !field.Required {
+			label += " (optional)"
+		}
+
+		var value string
+		if field.Secret {
+			value, err = p.askSecret(label, field.Required)
+		} else {
+			value, err = p.ask(label, "", field.Required)
+		}
+		if err != nil {
+			return err
+		}
+		values[field.Key] = value
+	}
+
+	dnsConfig, err := provider.NewDNSConfig(name, strings.ToUpper(recordType), ttl, values)
+	if err != nil {
+		return fmt.Errorf("invalid record configuration: %w", err)
+	}
+
+	if err := config.AppendDNSRecord(configPath, provider.RecordYAML(dnsConfig, values)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Added %s record %s to %s\n", provider.Name, dnsConfig.Name, configPath)
+	return nil
+}
+
+// prompter reads answers to interactive prompts
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prompts for a value, returning def when the answer is empty.
+// Required values are asked for again until an answer is given.
+func (p *prompter) ask(label, def string, required bool) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [default %s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+
+		line, err := p.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = def
+		}
+		if value != "" || !required {
+			return value, nil
+		}
+		fmt.Fprintf(p.out, "A value is required.\n")
+	}
+}
+
+// askSecret prompts for a secret value without echoing it when stdin is a terminal
+func (p *prompter) askSecret(label string, required bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return p.ask(label, "", required)
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s: ", label)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(p.out)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+
+		value := strings.TrimSpace(string(secret))
+		if value != "" || !required {
+			return value, nil
+		}
+		fmt.Fprintf(p.out, "A value is required.\n")
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+)
+
+// runListRecordsCommand implements the list-records subcommand and returns the exit code
+func runListRecordsCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("list-records", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file")
+	providerName := flags.String("provider", "", "DNS provider whose zone to list, e.g. cloudflare; its first record in the configuration supplies the credentials")
+	recordName := flags.String("record", "", "Record whose credentials and zone to use among several records of the provider")
+	asJSON := flags.Bool("json", false, "Print the records as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover list-records -config /path/to/config.yaml -provider NAME [-record NAME] [-json]\n\n")
+		fmt.Fprintf(stderr, "List all records of the zone managed by a DNS provider.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" {
+		fmt.Fprintf(stderr, "Error: -config flag is required for list-records\n")
+		return 1
+	}
+	if *providerName == "" {
+		fmt.Fprintf(stderr, "Error: -provider flag is required for list-records\n")
+		return 1
+	}
+
+	cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	for _, overlay := range missing {
+		fmt.Fprintf(stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+	}
+
+	dnsConfig, ok := selectProviderRecord(cfg, *providerName, *recordName)
+	if !ok {
+		fmt.Fprintf(stderr, "Error: no record with provider %q in the configuration\n", *providerName)
+		return 1
+	}
+
+	app := newCommandApplication(cfg)
+	provider, err := app.newUnguardedDNSProvider(cfg, dnsConfig)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to create DNS provider: %v\n", err)
+		return 1
+	}
+	defer app.closeDNSProviders(map[string]interfaces.DNSProvider{dnsConfig.Key(): provider})
+
+	records, err := listRecords(provider)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to list records: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			fmt.Fprintf(stderr, "Failed to encode records: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	printRecords(stdout, records)
+	return 0
+}
+
+// listRecords lists the records of the provider's zone, failing for providers that do not
+// implement interfaces.RecordLister
+func listRecords(provider interfaces.DNSProvider) ([]interfaces.DNSRecord, error) {
+	lister, ok := provider.(interfaces.RecordLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support listing records", provider.Name())
+	}
+
+	records := []interfaces.DNSRecord{}
+	err := validateWithTimeout(func(ctx context.Context) error {
+		listed, err := lister.ListRecords(ctx)
+		if listed != nil {
+			records = listed
+		}
+		return err
+	})
+	return records, err
+}
+
+// printRecords prints a table of records to out
+func printRecords(out io.Writer, records []interfaces.DNSRecord) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "NAME\tTYPE\tTTL\tVALUE\n")
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", record.Name, record.Type, record.TTL, record.Value)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listingProvider is a recordingProvider implementing interfaces.RecordLister
+type listingProvider struct {
+	recordingProvider
+	listed []interfaces.DNSRecord
+}
+
+func (p *listingProvider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	return p.listed, nil
+}
+
+func TestListRecords(t *testing.T) {
+	t.Run("prints the records of the zone", func(t *testing.T) {
+		provider := &listingProvider{listed: []interfaces.DNSRecord{
+			{Name: "example.com", Type: "MX", Value: "10 mail.example.com.", TTL: 3600},
+			{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300},
+		}}
+
+		records, err := listRecords(provider)
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		printRecords(&out, records)
+		assert.Equal(t, ""+
+			"NAME              TYPE  TTL   VALUE\n"+
+			"example.com       MX    3600  10 mail.example.com.\n"+
+			"home.example.com  A     300   203.0.113.10\n", out.String())
+	})
+
+	t.Run("empty zone", func(t *testing.T) {
+		records, err := listRecords(&listingProvider{})
+		require.NoError(t, err)
+		assert.NotNil(t, records, "encoded as [] with -json")
+	})
+
+	t.Run("provider without listing", func(t *testing.T) {
+		_, err := listRecords(&recordingProvider{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provider recording does not support listing records")
+	})
+}
+
+func TestRunListRecordsCommand_Flags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 1, runListRecordsCommand([]string{"-config", "config.yaml"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-provider flag is required")
+
+	stderr.Reset()
+	assert.Equal(t, 1, runListRecordsCommand([]string{"-provider", "cloudflare"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-config flag is required")
+}
@@ -2,21 +2,36 @@ package main
 
 import (
 	"context"
+	stderrors "errors"
 	"flag"
 	"fmt"
-	"net"
+	"io"
+	"math/rand"
+	"net/netip"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/audit"
 	"github.com/devhat/ipfailover/internal/config"
 	"github.com/devhat/ipfailover/internal/dns"
-	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/fleet"
+	"github.com/devhat/ipfailover/internal/hook"
 	"github.com/devhat/ipfailover/internal/metrics"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/internal/prober"
+	"github.com/devhat/ipfailover/internal/propagation"
+	"github.com/devhat/ipfailover/internal/retry"
 	"github.com/devhat/ipfailover/internal/state"
+	"github.com/devhat/ipfailover/internal/tracing"
 	"github.com/devhat/ipfailover/pkg/errors"
 	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -28,15 +43,71 @@ var (
 	BuildTime = "unknown"
 )
 
+// Exit codes for -once mode
+const (
+	exitOK              = 0
+	exitIPCheckFailed   = 1 // IP check failed, or any other error
+	exitDNSUpdateFailed = 2
+)
+
+// minReachabilityTimeout bounds the reachability checks of a poll, unless the configured attempts take longer
+const minReachabilityTimeout = 5 * time.Second
+
+// notificationTimeout bounds sending a notification, including connecting to the server
+const notificationTimeout = 30 * time.Second
+
+// tracingShutdownTimeout bounds exporting the buffered spans on shutdown
+const tracingShutdownTimeout = 5 * time.Second
+
+// errDNSUpdate marks failures to apply DNS record updates
+var errDNSUpdate = stderrors.New("failed to update DNS records")
+
 // Application represents the main application
 type Application struct {
-	config                *config.Config
-	logger                *zap.Logger
-	ipChecker             interfaces.IPChecker
-	dnsProviders          map[string]interfaces.DNSProvider
-	stateStore            interfaces.StateStore
-	metrics               interfaces.MetricsCollector
-	transientFailureCount int // In-memory fallback counter for when persistence fails
+	// configMu guards config, the IP and health checkers, dnsProviders, notifications and the prober, which are replaced on reload
+	configMu        sync.RWMutex
+	reloadMu        sync.Mutex // Serializes configuration reloads
+	config          *config.Config
+	logger          *zap.Logger
+	ipChecker       interfaces.IPChecker
+	ipv6Checker     interfaces.IPChecker     // Detects the public IPv6 address in dual-stack mode, nil otherwise
+	healthChecker   interfaces.HealthChecker // Checks the reachability of the primary and secondary IPs
+	rng             *rand.Rand               // Source of fleet randomization, seedable for deterministic runs
+	dnsProviders    map[string]interfaces.DNSProvider
+	circuitBreakers map[string]*dns.CircuitBreaker // By provider instance, shared by its records; guarded by reloadMu
+	stateStore      interfaces.StateStore
+	metrics         interfaces.MetricsCollector
+	audit           *audit.Logger                // Records DNS changes and REST API requests if audit_log_file is set, nil otherwise
+	digest          *notification.Digest         // Counts checks and events for the daily email digest if enabled, nil otherwise
+	shutdownTracing func(context.Context) error  // Flushes and stops the trace exporter
+	notifications   *notification.FanOutNotifier // Delivers failover and incident events to the configured channels
+	prober          *prober.Prober               // Optional background reachability prober
+	proberCancel    context.CancelFunc
+	runCtx          context.Context           // Set once Run starts, used to start background workers on reload
+	pollIntervalCh  chan time.Duration        // Notifies the main loop of poll interval changes
+	checkMu         sync.Mutex                // Serializes check cycles, forced failovers and reloads replacing the DNS providers
+	groupsMu        sync.Mutex                // Guards the failoverGroups map, read by the REST API status outside check cycles
+	failoverGroups  map[string]*failoverGroup // By state name, see config.Config.GroupStateName; group fields guarded by checkMu
+	forcedTarget    string                    // Target pinned by a manual failover, see loadForcedTarget; guarded by checkMu
+	startTime       time.Time                 // When the application was created, for the API uptime
+	updates         updateStats               // DNS updates since startup, reported by the API
+
+	// reachabilityIntervalCh notifies the main loop of reachability interval changes
+	reachabilityIntervalCh chan time.Duration
+
+	// ConfigPath and ConfigOverlays are the configuration files read again by API reloads
+	ConfigPath     string
+	ConfigOverlays []string
+
+	// DryRun logs DNS updates instead of applying them and keeps state changes in memory
+	DryRun bool
+
+	// Once makes Run perform a single check-and-update cycle and return
+	Once bool
+
+	// ForceUpdate pushes DNS records even if state says the target IP is already applied.
+	// It is cleared after the first successful update.
+	ForceUpdate bool
 }
 
 // HealthCheck performs a health check and returns the status
@@ -45,7 +116,7 @@ func (app *Application) HealthCheck() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := app.ipChecker.GetCurrentIP(ctx)
+	_, err := app.getIPChecker().GetCurrentIP(ctx)
 	if err != nil {
 		return fmt.Errorf("IP check failed: %w", err)
 	}
@@ -62,32 +133,180 @@ func (app *Application) HealthCheck() error {
 // NewApplication creates a new application instance
 func NewApplication(cfg *config.Config, logger *zap.Logger) (*Application, error) {
 	app := &Application{
-		config:       cfg,
-		logger:       logger,
-		dnsProviders: make(map[string]interfaces.DNSProvider),
+		config:          cfg,
+		logger:          logger,
+		dnsProviders:    make(map[string]interfaces.DNSProvider),
+		circuitBreakers: make(map[string]*dns.CircuitBreaker),
+		failoverGroups:  make(map[string]*failoverGroup),
+		pollIntervalCh:  make(chan time.Duration, 1),
+		startTime:       time.Now(),
+
+		reachabilityIntervalCh: make(chan time.Duration, 1),
 	}
 
-	// Initialize IP checker
-	app.ipChecker = ipchecker.NewHTTPChecker(cfg.CheckEndpoints, logger)
+	// Initialize IP checker, whose source address and interface must exist on this host
+	if err := cfg.Source().Validate(); err != nil {
+		return nil, err
+	}
+	app.rng = fleet.NewRand(0)
+	app.ipChecker = app.newIPChecker(cfg)
+	app.ipv6Checker = app.newIPv6Checker(cfg)
+	app.healthChecker = app.newHealthChecker(cfg)
+
+	// Initialize metrics collector, which also serves the status and configuration endpoints.
+	// It is created before the DNS providers, whose circuit breakers report their state to it.
+	collector := metrics.NewPrometheusCollector(logger)
+	collector.Handle("/status", app.statusHandler())
+	collector.Handle(configAPIPath, app.configHandler())
+	collector.SetTLS(cfg.MetricsTLSCertFile, cfg.MetricsTLSKeyFile)
+	collector.SetBasicAuth(cfg.MetricsBasicAuthUser, cfg.MetricsBasicAuthPassword)
+	if err := collector.SetAllowedCIDRs(cfg.MetricsAllowedCIDRs); err != nil {
+		return nil, fmt.Errorf("invalid metrics_allowed_cidrs: %w", err)
+	}
+	collector.SetStartTime(app.startTime)
+	collector.SetBuildInfo(Version, BuildTime)
+	app.metrics = collector
 
 	// Initialize DNS providers
 	for _, dnsConfig := range cfg.DNS {
-		provider, err := app.createDNSProvider(dnsConfig)
+		provider, err := app.newDNSProvider(cfg, dnsConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create DNS provider for %s: %w", dnsConfig.Name, err)
+			return nil, fmt.Errorf("failed to create DNS provider for %s: %w", dnsConfig.Key(), err)
 		}
-		app.dnsProviders[dnsConfig.Name] = provider
+		app.dnsProviders[dnsConfig.Key()] = provider
 	}
 
 	// Initialize state store
 	app.stateStore = state.NewFileStateStore(cfg.StateFile, logger)
 
-	// Initialize metrics collector
-	app.metrics = metrics.NewPrometheusCollector(logger)
+	// Initialize the audit log of DNS changes if enabled
+	if cfg.AuditLogFile != "" {
+		app.audit = audit.NewLogger(cfg.AuditLogFile, cfg.AuditLogMaxSizeMB, cfg.AuditLogMaxBackups)
+	}
+
+	// Initialize the daily email digest if enabled
+	if email := cfg.Notifications.Email; email != nil && email.DailyDigest {
+		app.digest = notification.NewDigest(time.Now())
+	}
+
+	// Initialize the trace exporter if enabled, a no-op otherwise
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.OTelExporterEndpoint, Version, cfg.InstanceName)
+	if err != nil {
+		return nil, err
+	}
+	app.shutdownTracing = shutdownTracing
+
+	// Initialize the notification channels for failover events
+	app.notifications = app.newNotifications(cfg)
+
+	// Initialize background reachability prober if enabled
+	app.prober = app.newProber(cfg)
 
 	return app, nil
 }
 
+// newDNSProvider creates the DNS provider for a record, with its API requests sent through the proxy
+// of the record. Its calls pass through the circuit breaker of the provider instance, see
+// config.DNSConfig.ProviderInstance, which is created on first use and shared by all records of the
+// instance. reloadMu must be held, or the application not yet shared.
+func (app *Application) newDNSProvider(cfg *config.Config, dnsConfig config.DNSConfig) (interfaces.DNSProvider, error) {
+	provider, err := app.newUnguardedDNSProvider(cfg, dnsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker, ok := app.circuitBreakers[dnsConfig.ProviderInstance()]
+	if !ok {
+		name, cooldown := dnsConfig.ProviderInstance(), cfg.CircuitBreakerCooldown
+		breaker = dns.NewCircuitBreaker(name, cfg.CircuitBreakerThreshold, cooldown, func(state dns.CircuitState) {
+			app.circuitStateChanged(name, state, cooldown)
+		})
+		app.circuitBreakers[name] = breaker
+		app.metrics.SetCircuitState(name, int(dns.CircuitClosed))
+	}
+
+	return dns.WithCircuitBreaker(provider, breaker), nil
+}
+
+// newUnguardedDNSProvider creates the DNS provider of a record using its proxy, without the circuit
+// breaker of newDNSProvider. It is used by commands making one-off calls, which need no breaker
+// but may use optional interfaces of the provider that the breaker does not pass through.
+func (app *Application) newUnguardedDNSProvider(cfg *config.Config, dnsConfig config.DNSConfig) (interfaces.DNSProvider, error) {
+	provider, err := app.createDNSProvider(dnsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy := cfg.RecordProxyFunc(dnsConfig); proxy != nil {
+		if configurable, ok := provider.(interfaces.ProxyConfigurable); ok {
+			configurable.SetProxy(proxy)
+		} else {
+			app.logger.Warn("DNS provider does not support proxy_url, its requests are not proxied",
+				zap.String("provider", dnsConfig.Provider),
+				zap.String("record", dnsConfig.Name),
+				recordIDField(dnsConfig),
+			)
+		}
+	}
+
+	return provider, nil
+}
+
+// circuitStateChanged logs and reports a state change of a provider's circuit breaker
+func (app *Application) circuitStateChanged(provider string, state dns.CircuitState, cooldown time.Duration) {
+	app.metrics.SetCircuitState(provider, int(state))
+
+	switch state {
+	case dns.CircuitOpen:
+		app.logger.Warn("circuit breaker opened, DNS provider calls are suspended",
+			zap.String("provider", provider),
+			zap.Duration("cooldown", cooldown),
+		)
+	case dns.CircuitHalfOpen:
+		app.logger.Info("circuit breaker half-open, probing DNS provider",
+			zap.String("provider", provider),
+		)
+	case dns.CircuitClosed:
+		app.logger.Info("circuit breaker closed, DNS provider recovered",
+			zap.String("provider", provider),
+		)
+	}
+}
+
+// flushTraces exports the spans still buffered and stops the trace exporter
+func (app *Application) flushTraces() {
+	if app.shutdownTracing == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := app.shutdownTracing(ctx); err != nil {
+		app.logger.Warn("failed to flush traces", zap.Error(err))
+	}
+}
+
+// closeDNSProviders closes the providers that hold resources, such as plugin processes
+func (app *Application) closeDNSProviders(providers map[string]interfaces.DNSProvider) {
+	for name, provider := range providers {
+		closer, ok := provider.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			app.logger.Warn("failed to close DNS provider",
+				zap.String("record", name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// recordIDField returns the log field identifying the configuration entry of a record, which
+// tells apart entries managing the same record name, see config.DNSConfig.Key
+func recordIDField(dnsConfig config.DNSConfig) zap.Field {
+	return zap.String("record_id", dnsConfig.Key())
+}
+
 // createDNSProvider creates a DNS provider based on configuration
 func (app *Application) createDNSProvider(dnsConfig config.DNSConfig) (interfaces.DNSProvider, error) {
 	switch dnsConfig.Provider {
@@ -95,22 +314,109 @@ func (app *Application) createDNSProvider(dnsConfig config.DNSConfig) (interface
 		if dnsConfig.Cloudflare == nil {
 			return nil, fmt.Errorf("cloudflare configuration is required")
 		}
-		return dns.NewCloudflareProvider(dnsConfig.Cloudflare, app.logger), nil
+		return dns.NewCloudflareProvider(dnsConfig.Cloudflare, app.logger).ForRecord(dnsConfig.Name), nil
 	case "cpanel":
 		if dnsConfig.CPanel == nil {
 			return nil, fmt.Errorf("cpanel configuration is required")
 		}
-		return dns.NewCPanelProvider(dnsConfig.CPanel, app.logger), nil
+		provider := dns.NewCPanelProvider(dnsConfig.CPanel, app.logger)
+		if provider == nil {
+			return nil, fmt.Errorf("invalid cpanel configuration")
+		}
+		provider.SetHTTPConfig(dnsConfig.HTTP, func() {
+			app.metrics.IncrementHTTPRetries("cpanel")
+		})
+		return provider, nil
 	case "route53":
 		if dnsConfig.Route53 == nil {
 			return nil, fmt.Errorf("route53 configuration is required")
 		}
-		return dns.NewRoute53Provider(dnsConfig.Route53, app.logger)
+		provider, err := dns.NewRoute53Provider(dnsConfig.Route53, app.logger)
+		if err != nil {
+			return nil, err
+		}
+		provider.SetSyncObserver(func(duration time.Duration) {
+			app.metrics.ObserveChangeSync("route53", duration)
+		})
+		return provider, nil
 	case "hetzner":
 		if dnsConfig.Hetzner == nil {
 			return nil, fmt.Errorf("hetzner configuration is required")
 		}
 		return dns.NewHetznerProvider(dnsConfig.Hetzner, app.logger), nil
+	case "alidns":
+		if dnsConfig.AliDNS == nil {
+			return nil, fmt.Errorf("alidns configuration is required")
+		}
+		return dns.NewAliDNSProvider(dnsConfig.AliDNS, app.logger), nil
+	case "netcup":
+		if dnsConfig.Netcup == nil {
+			return nil, fmt.Errorf("netcup configuration is required")
+		}
+		return dns.NewNetcupProvider(dnsConfig.Netcup, app.logger), nil
+	case "namecom":
+		if dnsConfig.Namecom == nil {
+			return nil, fmt.Errorf("namecom configuration is required")
+		}
+		return dns.NewNamecomProvider(dnsConfig.Namecom, app.logger), nil
+	case "adguard":
+		if dnsConfig.AdGuard == nil {
+			return nil, fmt.Errorf("adguard configuration is required")
+		}
+		provider := dns.NewAdGuardProvider(dnsConfig.AdGuard, app.logger)
+		if provider == nil {
+			return nil, fmt.Errorf("invalid adguard configuration")
+		}
+		return provider, nil
+	case "transip":
+		if dnsConfig.TransIP == nil {
+			return nil, fmt.Errorf("transip configuration is required")
+		}
+		return dns.NewTransIPProvider(dnsConfig.TransIP, app.logger), nil
+	case "mythicbeasts":
+		if dnsConfig.MythicBeasts == nil {
+			return nil, fmt.Errorf("mythicbeasts configuration is required")
+		}
+		return dns.NewMythicBeastsProvider(dnsConfig.MythicBeasts, app.logger), nil
+	case "domeneshop":
+		if dnsConfig.Domeneshop == nil {
+			return nil, fmt.Errorf("domeneshop configuration is required")
+		}
+		return dns.NewDomeneshopProvider(dnsConfig.Domeneshop, app.logger), nil
+	case "easydns":
+		if dnsConfig.EasyDNS == nil {
+			return nil, fmt.Errorf("easydns configuration is required")
+		}
+		return dns.NewEasyDNSProvider(dnsConfig.EasyDNS, app.logger), nil
+	case "loopia":
+		if dnsConfig.Loopia == nil {
+			return nil, fmt.Errorf("loopia configuration is required")
+		}
+		return dns.NewLoopiaProvider(dnsConfig.Loopia, app.logger), nil
+	case "infomaniak":
+		if dnsConfig.Infomaniak == nil {
+			return nil, fmt.Errorf("infomaniak configuration is required")
+		}
+		return dns.NewInfomaniakProvider(dnsConfig.Infomaniak, app.logger), nil
+	case "dynu":
+		if dnsConfig.Dynu == nil {
+			return nil, fmt.Errorf("dynu configuration is required")
+		}
+		return dns.NewDynuProvider(dnsConfig.Dynu, app.logger), nil
+	case "webhook":
+		if dnsConfig.Webhook == nil {
+			return nil, fmt.Errorf("webhook configuration is required")
+		}
+		provider := dns.NewWebhookProvider(dnsConfig.Webhook, app.logger)
+		if provider == nil {
+			return nil, fmt.Errorf("invalid webhook configuration")
+		}
+		return provider, nil
+	case "plugin":
+		if dnsConfig.Plugin == nil {
+			return nil, fmt.Errorf("plugin configuration is required")
+		}
+		return dns.NewPluginProvider(dnsConfig.Plugin, app.logger), nil
 	default:
 		return nil, fmt.Errorf("unsupported DNS provider: %s", dnsConfig.Provider)
 	}
@@ -118,64 +424,202 @@ func (app *Application) createDNSProvider(dnsConfig config.DNSConfig) (interface
 
 // Run starts the application
 func (app *Application) Run(ctx context.Context) error {
+	defer func() {
+		app.closeDNSProviders(app.getDNSProviders())
+		if err := app.audit.Close(); err != nil {
+			app.logger.Warn("failed to close audit log", zap.Error(err))
+		}
+		app.flushTraces()
+	}()
+
+	if app.Once {
+		// Single run mode: no metrics server, background prober or poll ticker
+		app.logger.Info("running single IP check")
+		if err := app.validateProviders(ctx); err != nil {
+			return err
+		}
+		return app.RunOnce(ctx)
+	}
+
 	app.logger.Info("starting IP failover daemon")
 
+	cfg := app.getConfig()
+
 	// Start metrics server
-	metricsCtx, metricsCancel := context.WithCancel(ctx)
-	defer metricsCancel()
+	serverCtx, serverCancel := context.WithCancel(ctx)
+	defer serverCancel()
 
 	go func() {
-		if err := app.metrics.StartMetricsServer(metricsCtx, app.config.MetricsAddr); err != nil {
+		if err := app.metrics.StartMetricsServer(serverCtx, cfg.MetricsAddr); err != nil {
 			app.logger.Error("metrics server error", zap.Error(err))
 		}
 	}()
 
+	// Start REST API server if enabled
+	if cfg.APIAddr != "" {
+		go func() {
+			handler := api.NewHandler(app, cfg.APIToken, app.logger)
+			handler.SetAuditLog(app.audit)
+			if err := api.ListenAndServe(serverCtx, cfg.APIAddr, handler, app.logger); err != nil {
+				app.logger.Error("API server error", zap.Error(err))
+			}
+		}()
+	}
+
+	// Start background reachability prober
+	app.startProber(ctx)
+
+	if app.digest != nil {
+		go app.runDigest(ctx, cfg.Notifications.Email.DigestTime)
+	}
+
 	// Validate DNS providers
-	for name, provider := range app.dnsProviders {
-		if err := provider.Validate(ctx); err != nil {
-			app.logger.Error("DNS provider validation failed",
-				zap.String("provider", name),
-				zap.Error(err),
-			)
-			return fmt.Errorf("DNS provider %s validation failed: %w", name, err)
-		}
-		app.logger.Info("DNS provider validated successfully",
-			zap.String("provider", name),
-		)
+	if err := app.validateProviders(ctx); err != nil {
+		return err
+	}
+
+	if email := cfg.Notifications.Email; email != nil && email.TestNotification {
+		app.logger.Info("sending test email notification", zap.Strings("to", email.To))
+		app.sendNotification(ctx, notification.NewEmailNotifier(email, app.logger), interfaces.NotificationEvent{
+			Type:      notification.TypeTest,
+			Message:   "Test notification: ipfailover started and can deliver email notifications",
+			Timestamp: time.Now(),
+		})
 	}
 
 	// Start main loop
-	ticker := time.NewTicker(app.config.PollInterval)
+	ticker := time.NewTicker(cfg.PollInterval)
 	defer ticker.Stop()
 
+	// With fleet randomization the ticker is started at the instance's poll phase
+	phaseTimer := time.NewTimer(0)
+	phaseTimer.Stop()
+	defer phaseTimer.Stop()
+	interval := cfg.PollInterval
+	alignPollPhase := func() {
+		if delay, ok := app.pollPhaseDelay(app.getConfig(), interval); ok {
+			ticker.Stop()
+			phaseTimer.Reset(delay)
+			app.logger.Debug("waiting for poll phase",
+				zap.Duration("delay", delay),
+			)
+		}
+	}
+
+	// Between polls, reachability is checked at the reachability interval if one is set
+	reachabilityTicker := time.NewTicker(time.Hour)
+	reachabilityTicker.Stop()
+	defer reachabilityTicker.Stop()
+	setReachabilityInterval := func(reachabilityInterval time.Duration) {
+		reachabilityTicker.Stop()
+		if reachabilityInterval > 0 {
+			reachabilityTicker.Reset(reachabilityInterval)
+		}
+	}
+	setReachabilityInterval(cfg.ReachabilityInterval)
+
 	// Run initial check
-	if err := app.checkAndUpdateIP(ctx); err != nil {
+	if err := app.RunOnce(ctx); err != nil {
 		app.logger.Error("initial IP check failed", zap.Error(err))
 	}
+	alignPollPhase()
 
 	for {
 		select {
 		case <-ctx.Done():
 			app.logger.Info("shutting down application")
 			return ctx.Err()
+		case <-phaseTimer.C:
+			ticker.Reset(interval)
+			if err := app.RunOnce(ctx); err != nil {
+				app.logger.Error("IP check failed", zap.Error(err))
+			}
 		case <-ticker.C:
-			if err := app.checkAndUpdateIP(ctx); err != nil {
+			if err := app.RunOnce(ctx); err != nil {
 				app.logger.Error("IP check failed", zap.Error(err))
 			}
+		case <-reachabilityTicker.C:
+			if err := app.checkReachability(ctx); err != nil {
+				app.logger.Error("reachability check failed", zap.Error(err))
+			}
+		case interval = <-app.pollIntervalCh:
+			ticker.Reset(interval)
+			alignPollPhase()
+			app.logger.Info("poll interval updated",
+				zap.Duration("poll_interval", interval),
+			)
+		case reachabilityInterval := <-app.reachabilityIntervalCh:
+			setReachabilityInterval(reachabilityInterval)
+			app.logger.Info("reachability interval updated",
+				zap.Duration("reachability_interval", reachabilityInterval),
+			)
+		}
+	}
+}
+
+// RunOnce performs a single check-and-update cycle, as on every poll of the main loop and once
+// with -once. A failed DNS update is reported as errDNSUpdate, see onceExitCode.
+func (app *Application) RunOnce(ctx context.Context) error {
+	return app.checkAndUpdateIP(ctx)
+}
+
+// validateProviders validates all configured DNS providers
+func (app *Application) validateProviders(ctx context.Context) error {
+	for name, provider := range app.getDNSProviders() {
+		if err := provider.Validate(ctx); err != nil {
+			app.logger.Error("DNS provider validation failed",
+				zap.String("provider", name),
+				zap.Error(err),
+			)
+			return fmt.Errorf("DNS provider %s validation failed: %w", name, err)
 		}
+		app.logger.Info("DNS provider validated successfully",
+			zap.String("provider", name),
+		)
 	}
+
+	return nil
+}
+
+// onceExitCode maps the result of a single run to the process exit code
+func onceExitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case stderrors.Is(err, errDNSUpdate):
+		return exitDNSUpdateFailed
+	default:
+		return exitIPCheckFailed
+	}
+}
+
+// Check runs a check cycle as requested through the API, waiting for a cycle in progress to finish first
+func (app *Application) Check(ctx context.Context) error {
+	// A client that disconnects must not leave the cycle half applied
+	return app.checkAndUpdateIP(context.WithoutCancel(ctx))
 }
 
 // checkAndUpdateIP checks the current IP and updates DNS records if needed
 func (app *Application) checkAndUpdateIP(ctx context.Context) error {
+	app.checkMu.Lock()
+	defer app.checkMu.Unlock()
+
 	app.logger.Debug("checking current IP")
+	app.loadForcedTarget(ctx)
 	app.metrics.IncrementIPChecks()
 
 	// Get current IP
-	currentIP, err := app.ipChecker.GetCurrentIP(ctx)
+	ipChecker := app.getIPChecker()
+	currentIP, err := ipChecker.GetCurrentIP(ctx)
+	app.digest.RecordCheck(err)
 	if err != nil {
 		app.metrics.IncrementIPCheckErrors()
-		return errors.NewIPCheckError(app.ipChecker.Name(), err)
+		err = errors.NewIPCheckError(ipChecker.Name(), err)
+		app.notify(ctx, interfaces.NotificationEvent{
+			Type:    notification.TypeIPCheckFailed,
+			Message: err.Error(),
+		})
+		return err
 	}
 
 	app.logger.Info("current IP detected",
@@ -189,311 +633,1741 @@ func (app *Application) checkAndUpdateIP(ctx context.Context) error {
 		app.logger.Warn("failed to store check info", zap.Error(err))
 	}
 
+	// In dual-stack mode a failed IPv6 check is reported, but the records of both families are still checked
+	var errs error
+	var currentIPv6 string
+	if ipv6Checker := app.getIPv6Checker(); ipv6Checker != nil {
+		currentIPv6, errs = app.checkIPv6(ctx, ipv6Checker)
+	}
+
+	// Records with failover overrides, and in dual-stack mode the AAAA records, fail over independently of the others
+	updatedAll := true
+	cfg := app.getConfig()
+	for _, groupCfg := range cfg.FailoverGroups() {
+		egressIP := currentIP
+		if cfg.IsIPv6Record(groupCfg.DNS[0]) {
+			egressIP = currentIPv6
+		}
+		updated, err := app.checkFailoverGroup(ctx, groupCfg, egressIP)
+		errs = multierr.Append(errs, err)
+		updatedAll = updatedAll && updated
+	}
+
+	// A forced update only applies until every group was updated successfully
+	if errs == nil && updatedAll {
+		app.ForceUpdate = false
+	}
+
+	return errs
+}
+
+// checkIPv6 detects the current public IPv6 address in dual-stack mode, stores it in the state
+// of the AAAA records and returns it
+func (app *Application) checkIPv6(ctx context.Context, ipv6Checker interfaces.IPChecker) (string, error) {
+	app.metrics.IncrementIPChecks()
+
+	currentIPv6, err := ipv6Checker.GetCurrentIP(ctx)
+	app.digest.RecordCheck(err)
+	if err != nil {
+		app.metrics.IncrementIPCheckErrors()
+		return "", errors.NewIPCheckError(ipv6Checker.Name(), err)
+	}
+
+	app.logger.Info("current IPv6 detected",
+		zap.String("ip", currentIPv6),
+	)
+
+	app.metrics.SetCurrentIPv6(currentIPv6)
+
+	store := app.stateGroup(ctx, config.IPv6StateName).store
+	if err := store.SetLastCheckInfo(ctx, currentIPv6, time.Now()); err != nil {
+		app.logger.Warn("failed to store IPv6 check info", zap.Error(err))
+	}
+	return currentIPv6, nil
+}
+
+// failoverGroup is the runtime state of DNS records that fail over together, see config.Config.FailoverGroups
+type failoverGroup struct {
+	store                 interfaces.StateStore
+	transientFailureCount int       // In-memory fallback counter for when persistence fails
+	lastCheck             time.Time // When the group was last checked, for poll intervals longer than the global one
+}
+
+// failoverGroup returns the runtime state of the failover group of the record, creating it on first use.
+// Records with failover overrides, and in dual-stack mode the AAAA records, keep their state in a
+// file of their own, see config.Config.GroupStateName.
+func (app *Application) failoverGroup(ctx context.Context, dnsConfig config.DNSConfig) *failoverGroup {
+	return app.stateGroup(ctx, app.getConfig().GroupStateName(dnsConfig))
+}
+
+// stateGroup returns the runtime state of the failover group with the state name key, see failoverGroup
+func (app *Application) stateGroup(ctx context.Context, key string) *failoverGroup {
+	app.groupsMu.Lock()
+	defer app.groupsMu.Unlock()
+
+	if group, ok := app.failoverGroups[key]; ok {
+		return group
+	}
+
+	group := &failoverGroup{store: app.stateStore}
+	if key != "" {
+		group.store = state.NewFileStateStore(state.RecordStateFile(app.getConfig().StateFile, key), app.logger)
+		if app.DryRun {
+			group.store = state.NewDryRunStateStore(ctx, group.store, app.logger)
+		}
+	}
+	app.failoverGroups[key] = group
+	return group
+}
+
+// checkFailoverGroup determines the target IP of a failover group and updates its DNS records if needed.
+// cfg is the configuration of the group, see config.Config.ForRecord. It reports whether the records
+// were updated to the target IP, which is false if the group was skipped or no target IP was determined.
+// egressIP is the public IP of the family of the group detected by the poll, empty if unknown.
+func (app *Application) checkFailoverGroup(ctx context.Context, cfg *config.Config, egressIP string) (bool, error) {
+	dnsConfig := cfg.DNS[0]
+	group := app.failoverGroup(ctx, dnsConfig)
+
+	// The check loop runs at the global poll interval, groups with a longer one skip polls in between.
+	// Half a global interval of slack keeps ticks that arrive slightly early from skipping a check.
+	now := time.Now()
+	globalInterval := app.getConfig().PollInterval
+	if !app.ForceUpdate && !group.lastCheck.IsZero() && cfg.PollInterval > globalInterval &&
+		now.Sub(group.lastCheck) < cfg.PollInterval-globalInterval/2 {
+		app.logger.Debug("record poll interval not elapsed, skipping check",
+			zap.String("record", dnsConfig.Name),
+			recordIDField(dnsConfig),
+			zap.Duration("poll_interval", cfg.PollInterval),
+		)
+		return false, nil
+	}
+	group.lastCheck = now
+
+	return app.reconcileFailoverGroup(ctx, cfg, group, egressIP, app.ForceUpdate)
+}
+
+// checkReachability determines the target IPs of the failover groups between polls, see
+// config.Config.ReachabilityInterval, and updates the DNS records of groups whose target changed.
+// The public IP is not checked and the poll intervals of the records are not advanced. Groups
+// without an applied IP are left to the next poll, as are forced updates.
+func (app *Application) checkReachability(ctx context.Context) error {
+	app.checkMu.Lock()
+	defer app.checkMu.Unlock()
+
+	app.logger.Debug("checking reachability between polls")
+
+	var errs error
+	for _, groupCfg := range app.getConfig().FailoverGroups() {
+		group := app.failoverGroup(ctx, groupCfg.DNS[0])
+		lastAppliedIP, err := group.store.GetLastAppliedIP(ctx)
+		if err != nil || lastAppliedIP == "" {
+			continue
+		}
+		_, err = app.reconcileFailoverGroup(ctx, groupCfg, group, "", false)
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
+
+// reconcileFailoverGroup determines the target IP of a failover group and updates its DNS records
+// if the target differs from the applied IP, or regardless of state if force is set. egressIP is
+// passed to determineTargetIP. It reports whether the records are at the target IP.
+func (app *Application) reconcileFailoverGroup(ctx context.Context, cfg *config.Config, group *failoverGroup, egressIP string, force bool) (bool, error) {
 	// Check if we need to update
-	lastAppliedIP, err := app.stateStore.GetLastAppliedIP(ctx)
+	lastAppliedIP, err := group.store.GetLastAppliedIP(ctx)
 	if err != nil {
 		app.logger.Warn("failed to get last applied IP", zap.Error(err))
 	}
 
 	// Determine target IP
-	targetIP := app.determineTargetIP(ctx, lastAppliedIP)
+	targetIP := app.determineTargetIP(ctx, cfg.DNS[0], lastAppliedIP, egressIP)
+	app.reportPrimaryFailureCount(ctx, cfg, group)
 	if targetIP == "" {
 		app.logger.Debug("no target IP determined, skipping update")
-		return nil
+		return false, nil
+	}
+
+	app.adjustRecordTTLs(ctx, cfg, group, targetIP, lastAppliedIP)
+
+	if lastAppliedIP == targetIP && !force {
+		app.resetPendingIP(ctx, group.store)
+		app.logger.Debug("IP already applied, skipping update",
+			zap.String("ip", targetIP),
+		)
+		return true, nil
+	}
+
+	// A manual failover is applied at once
+	pinned := app.forcedTarget != ""
+	if !force && !pinned && !app.changeIntervalElapsed(ctx, cfg, group.store, targetIP, lastAppliedIP) {
+		return false, nil
+	}
+
+	if !force && !pinned && !app.changeDebounced(ctx, group.store, targetIP, lastAppliedIP) {
+		return false, nil
+	}
+
+	if force {
+		app.logger.Warn("forced update active, pushing DNS records regardless of state",
+			zap.String("last_applied_ip", lastAppliedIP),
+			zap.String("target_ip", targetIP),
+		)
+	}
+
+	return app.applyTargetIP(ctx, cfg, group, targetIP, lastAppliedIP)
+}
+
+// applyTargetIP updates the DNS records of a failover group from lastAppliedIP to targetIP, running
+// the failover hooks around it, and records the change in state and notifications. It reports
+// whether the records were updated.
+func (app *Application) applyTargetIP(ctx context.Context, cfg *config.Config, group *failoverGroup, targetIP, lastAppliedIP string) (bool, error) {
+	start := time.Now()
+
+	// Hooks run for actual IP changes only, not for forced pushes of the applied IP
+	runHooks := lastAppliedIP != targetIP && !app.DryRun
+	if runHooks && lastAppliedIP != "" && targetIP != cfg.PrimaryIP {
+		app.notify(ctx, interfaces.NotificationEvent{
+			Type:    notification.TypeFailoverStarted,
+			FromIP:  lastAppliedIP,
+			ToIP:    targetIP,
+			Records: recordNames(cfg),
+			Message: fmt.Sprintf("Failing over DNS records from %s to %s", lastAppliedIP, targetIP),
+		})
+	}
+	if runHooks {
+		if err := app.runFailoverHook(ctx, "pre", cfg.PreFailoverHook, lastAppliedIP, targetIP); err != nil {
+			app.logger.Error("pre-failover hook failed, aborting failover",
+				zap.String("from_ip", lastAppliedIP),
+				zap.String("to_ip", targetIP),
+				zap.Error(err),
+			)
+			return false, fmt.Errorf("failover aborted by pre-failover hook: %w", err)
+		}
+	}
+
+	// Update DNS records
+	results, err := app.updateDNSRecords(ctx, cfg, group.store, targetIP, lastAppliedIP)
+	if err != nil {
+		app.notify(ctx, interfaces.NotificationEvent{
+			Type:    notification.TypeDNSUpdateFailed,
+			FromIP:  lastAppliedIP,
+			ToIP:    targetIP,
+			Records: recordNames(cfg),
+			Message: fmt.Sprintf("Failed to update DNS records to %s: %v", targetIP, err),
+		})
+		return false, fmt.Errorf("%w: %w", errDNSUpdate, err)
+	}
+
+	if cfg.PropagationCheck && !app.DryRun {
+		app.verifyPropagation(ctx, cfg, results)
+	}
+
+	if runHooks {
+		// The DNS change is not rolled back, the post-failover hook only reports on it
+		if err := app.runFailoverHook(ctx, "post", cfg.PostFailoverHook, lastAppliedIP, targetIP); err != nil {
+			app.logger.Warn("post-failover hook failed",
+				zap.String("from_ip", lastAppliedIP),
+				zap.String("to_ip", targetIP),
+				zap.Error(err),
+			)
+		}
+	}
+
+	// Update state
+	if err := group.store.SetLastAppliedIP(ctx, targetIP); err != nil {
+		return false, fmt.Errorf("failed to update state: %w", err)
+	}
+
+	if app.DryRun {
+		app.logger.Info("dry run: IP failover would have completed",
+			zap.String("from_ip", lastAppliedIP),
+			zap.String("to_ip", targetIP),
+		)
+		return true, nil
+	}
+
+	app.metrics.SetLastChangeTime(time.Now())
+	if direction := failoverDirection(cfg, lastAppliedIP, targetIP); direction != "" {
+		app.metrics.ObserveFailover(direction, time.Since(start))
+	}
+	app.updates.recordChange()
+
+	app.logger.Info("IP failover completed successfully",
+		zap.String("from_ip", lastAppliedIP),
+		zap.String("to_ip", targetIP),
+	)
+
+	// The first IP applied after startup without state is not a failover
+	if runHooks && lastAppliedIP != "" {
+		app.notifyFailover(ctx, cfg, lastAppliedIP, targetIP, results)
+	}
+
+	return true, nil
+}
+
+// notifyFailover reports a completed IP change through the configured notification channels, with
+// the previous values of the records as read by prepareRecordUpdate. A change to the primary IP is a
+// failback, any other a failover.
+func (app *Application) notifyFailover(ctx context.Context, cfg *config.Config, fromIP, toIP string, results []interfaces.RecordUpdateResult) {
+	records := make([]string, 0, len(results))
+	previousValues := make(map[string]string, len(results))
+	cached := false
+	for _, result := range results {
+		records = append(records, result.Record.Name)
+		if result.PreviousValue != "" {
+			previousValues[result.Record.Name] = result.PreviousValue
+		}
+		cached = cached || result.PreviousValueCached
+	}
+
+	eventType := notification.TypeFailover
+	if toIP == cfg.PrimaryIP {
+		eventType = notification.TypeFailback
+	}
+
+	app.notify(ctx, interfaces.NotificationEvent{
+		Type:           eventType,
+		FromIP:         fromIP,
+		ToIP:           toIP,
+		Records:        records,
+		Message:        fmt.Sprintf("DNS records were changed from %s to %s", fromIP, toIP),
+		PreviousValues: previousValues,
+		Cached:         cached,
+	})
+}
+
+// notify sends the event, timestamped now, through the configured notification channels.
+// Dry runs change nothing and send no notifications.
+func (app *Application) notify(ctx context.Context, event interfaces.NotificationEvent) {
+	if app.DryRun {
+		return
+	}
+	event.Timestamp = time.Now()
+	app.digest.RecordEvent(event)
+	app.sendNotification(ctx, app.getNotifications(), event)
+}
+
+// runDigest sends the daily email digest at the configured time of day until ctx ends
+func (app *Application) runDigest(ctx context.Context, at string) {
+	for {
+		next, err := notification.NextDigestTime(time.Now(), at)
+		if err != nil {
+			app.logger.Error("daily digest disabled", zap.Error(err))
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		app.sendDigest(ctx)
+	}
+}
+
+// sendDigest sends the summary of the past digest period by email in the background, with
+// the email settings of the current configuration. Failures are only logged.
+func (app *Application) sendDigest(ctx context.Context) {
+	summary := app.digest.Flush(time.Now())
+	email := app.getConfig().Notifications.Email
+	if app.DryRun || email == nil {
+		return
+	}
+
+	notifier := notification.NewEmailNotifier(email, app.logger)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+	go func() {
+		defer cancel()
+		if err := notifier.SendDigest(ctx, summary); err != nil {
+			app.logger.Warn("failed to send daily digest", zap.Error(err))
+		}
+	}()
+}
+
+// recordNames returns the names of the DNS records of cfg
+func recordNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.DNS))
+	for _, dnsConfig := range cfg.DNS {
+		names = append(names, dnsConfig.Name)
+	}
+	return names
+}
+
+// sendNotification sends the event through provider. It is sent in the background, so that
+// a slow or unreachable server does not delay failover; failures are only logged.
+func (app *Application) sendNotification(ctx context.Context, provider interfaces.NotificationProvider, event interfaces.NotificationEvent) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+	go func() {
+		defer cancel()
+		if err := provider.Notify(ctx, event); err != nil {
+			app.logger.Warn("failed to send notification",
+				zap.String("channel", provider.Name()),
+				zap.String("type", event.Type),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// determineTargetIP determines which IP should be used based on active reachability check
+// Implements retry logic: only switches to secondary after configurable number of consecutive failures
+// and only fails back to primary after FailbackRetries consecutive successes and FailbackDelay
+// On first run (lastAppliedIP empty), verifies primary reachability before returning it
+// The IPs and retries are those of the record's failover group, see config.Config.ForRecord
+// With egress_ip_weight, a detected public egressIP equal to the primary or secondary IP replaces the check
+// With fallback_ips, failing over selects the first usable fallback IP, see fallbackIP
+// While a manual failover pins the target, see SetForcedTarget, its IP is returned without any check
+func (app *Application) determineTargetIP(ctx context.Context, dnsConfig config.DNSConfig, lastAppliedIP, egressIP string) string {
+	cfg := app.getConfig().ForRecord(dnsConfig)
+	group := app.failoverGroup(ctx, dnsConfig)
+
+	if targetIP := forcedTargetIP(cfg, app.forcedTarget); targetIP != "" {
+		app.logger.Info("forced target active, automatic failover decisions suspended",
+			zap.String("forced_target", app.forcedTarget),
+			zap.String("target_ip", targetIP),
+		)
+		return targetIP
+	}
+
+	// Create a context with a short timeout for reachability checks
+	loopCtx := ctx
+	ctx, cancel := context.WithTimeout(ctx, app.reachabilityTimeout(cfg))
+	defer cancel()
+
+	// Try to reach the primary IP first, unless the public IP tells which uplink is up
+	failureWeight := 1
+	var err error
+	switch egress := app.egressEvidence(cfg, egressIP); egress {
+	case egressSecondary:
+		failureWeight = cfg.EgressIPWeight
+		err = fmt.Errorf("public IP %s is the secondary IP, the primary uplink is down", egressIP)
+	case egressPrimary:
+		app.logger.Debug("public IP is the primary IP, counting the primary as reachable",
+			zap.String("primary_ip", cfg.PrimaryIP),
+		)
+	default:
+		err = app.probeReachability(ctx, cfg.PrimaryIP)
+	}
+	if loopCtx.Err() != nil {
+		// Interrupted by shutdown, which says nothing about the primary
+		app.logger.Debug("reachability check interrupted, skipping update", zap.Error(loopCtx.Err()))
+		return ""
+	}
+	if err == nil {
+		// Primary is reachable, reset failure count and use primary
+		if resetErr := group.store.ResetPrimaryFailureCount(ctx); resetErr != nil {
+			app.logger.Error("critical: failed to reset primary failure count - state persistence compromised",
+				zap.Error(resetErr),
+				zap.String("primary_ip", cfg.PrimaryIP),
+				zap.Int("transient_failure_count", group.transientFailureCount),
+			)
+			// Handle based on configured strategy
+			if cfg.StateFailureStrategy == "fail_fast" {
+				app.logger.Fatal("state persistence failure - failing fast as configured")
+			}
+			// Continue with primary but log critical error for monitoring
+		} else {
+			// Successfully reset persisted count - also reset transient counter
+			if group.transientFailureCount > 0 {
+				app.logger.Info("primary IP recovered, resetting transient failure count",
+					zap.String("primary_ip", cfg.PrimaryIP),
+					zap.Int("transient_failure_count", group.transientFailureCount),
+				)
+				group.transientFailureCount = 0
+			}
+		}
+
+		if !app.failbackReady(ctx, cfg, group.store, lastAppliedIP) {
+			return lastAppliedIP
+		}
+
+		app.logger.Debug("Primary IP is reachable, using primary",
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.Int("transient_failure_count", group.transientFailureCount),
+		)
+		return cfg.PrimaryIP
+	}
+
+	// Primary is unreachable, any pending failback starts over once it recovers
+	app.resetPrimaryRecovery(ctx, group.store)
+
+	// Primary is unreachable, increment failure count
+	failureCount, getErr := group.store.GetPrimaryFailureCount(ctx)
+	if getErr != nil {
+		app.logger.Error("critical: failed to get primary failure count - failover tracking compromised",
+			zap.Error(getErr),
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.Int("transient_failure_count", group.transientFailureCount),
+		)
+
+		// Handle based on configured strategy
+		switch cfg.StateFailureStrategy {
+		case "fail_fast":
+			app.logger.Fatal("state persistence failure - failing fast as configured")
+		case "immediate_failover":
+			app.logger.Warn("state persistence failure - immediately failing over to secondary",
+				zap.String("primary_ip", cfg.PrimaryIP),
+				zap.String("secondary_ip", cfg.SecondaryIP),
+				zap.Int("transient_failure_count", group.transientFailureCount),
+			)
+			return app.fallbackIP(loopCtx, cfg, group.store, lastAppliedIP)
+		case "continue_with_warning":
+			fallthrough
+		default:
+			// Use transient counter instead of resetting to 0
+			failureCount = 0 // This will be the persisted count
+			app.logger.Warn("using transient failure counter due to state persistence failure",
+				zap.Int("transient_failure_count", group.transientFailureCount),
+				zap.Error(getErr),
+			)
+		}
+	}
+
+	failureCount += failureWeight
+
+	// The background prober may have observed more consecutive failures than polls so far
+	if status, ok := app.probeStatus(cfg.PrimaryIP); ok && status.ConsecutiveFailures > failureCount {
+		failureCount = status.ConsecutiveFailures
+	}
+
+	if setErr := group.store.SetPrimaryFailureCount(ctx, failureCount); setErr != nil {
+		// Persistence failed - increment transient counter instead of losing the count
+		group.transientFailureCount++
+		app.logger.Error("critical: failed to persist primary failure count - using transient counter",
+			zap.Error(setErr),
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.Int("failure_count", failureCount),
+			zap.Int("transient_failure_count", group.transientFailureCount),
+		)
+
+		// Handle based on configured strategy
+		switch cfg.StateFailureStrategy {
+		case "fail_fast":
+			app.logger.Fatal("state persistence failure - failing fast as configured")
+		case "immediate_failover":
+			app.logger.Warn("state persistence failure - immediately failing over to secondary",
+				zap.String("primary_ip", cfg.PrimaryIP),
+				zap.String("secondary_ip", cfg.SecondaryIP),
+				zap.Int("failure_count", failureCount),
+				zap.Int("transient_failure_count", group.transientFailureCount),
+			)
+			return app.fallbackIP(loopCtx, cfg, group.store, lastAppliedIP)
+		case "continue_with_warning":
+			fallthrough
+		default:
+			// State persistence failed - continue with transient counter
+			app.logger.Warn("continuing with transient failure counter due to persistence failure",
+				zap.Int("transient_failure_count", group.transientFailureCount),
+				zap.Error(setErr),
+			)
+		}
+	} else {
+		// Persistence succeeded - reset transient counter
+		group.transientFailureCount = 0
+	}
+
+	// If we have transient failures, attempt to persist them
+	if group.transientFailureCount > 0 {
+		app.attemptTransientPersistence(ctx, group, failureCount)
+	}
+
+	// Calculate total failure count including transient failures
+	totalFailureCount := failureCount + group.transientFailureCount
+
+	app.logger.Debug("Primary IP unreachable, incrementing failure count",
+		zap.String("primary_ip", cfg.PrimaryIP),
+		zap.Int("failure_count", failureCount),
+		zap.Int("transient_failure_count", group.transientFailureCount),
+		zap.Int("total_failure_count", totalFailureCount),
+		zap.Int("max_retries", cfg.FailoverRetries),
+		zap.Error(err),
+	)
+
+	// Check if we've exceeded the retry threshold (including transient failures)
+	if totalFailureCount >= cfg.FailoverRetries {
+		app.logger.Warn("Primary IP exceeded retry threshold, falling back to secondary",
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.String("secondary_ip", cfg.SecondaryIP),
+			zap.Int("failure_count", failureCount),
+			zap.Int("transient_failure_count", group.transientFailureCount),
+			zap.Int("total_failure_count", totalFailureCount),
+			zap.Int("max_retries", cfg.FailoverRetries),
+		)
+		return app.fallbackIP(loopCtx, cfg, group.store, lastAppliedIP)
+	}
+
+	// Still within retry threshold, but check if this is first run
+	if lastAppliedIP == "" {
+		// First run: primary is unreachable, check if secondary is reachable before using it
+		app.logger.Error("First run detected with unreachable primary - checking secondary IP reachability",
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.String("secondary_ip", cfg.SecondaryIP),
+			zap.Int("failure_count", failureCount),
+			zap.Int("max_retries", cfg.FailoverRetries),
+		)
+
+		// Check the secondary IP, and then the fallback IPs in order, for a reachable one
+		for _, fallbackIP := range cfg.FallbackTargets() {
+			err := app.probeReachability(ctx, fallbackIP)
+			if err != nil {
+				app.logger.Error("Fallback IP is also unreachable",
+					zap.String("primary_ip", cfg.PrimaryIP),
+					zap.String("fallback_ip", fallbackIP),
+					zap.Int("failure_count", failureCount),
+					zap.Int("max_retries", cfg.FailoverRetries),
+					zap.Error(err),
+				)
+				continue
+			}
+
+			app.logger.Info("Fallback IP is reachable - using it for DNS update",
+				zap.String("primary_ip", cfg.PrimaryIP),
+				zap.String("fallback_ip", fallbackIP),
+				zap.Int("failure_count", failureCount),
+				zap.Int("max_retries", cfg.FailoverRetries),
+			)
+			// Return the fallback IP to ensure DNS points to a reachable host
+			return fallbackIP
+		}
+
+		app.logger.Error("Secondary and fallback IPs are also unreachable - skipping DNS update to avoid pointing to unreachable host",
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.Strings("fallback_ips", cfg.FallbackTargets()),
+		)
+		// Return empty string to skip DNS update
+		return ""
+	}
+
+	// Not first run: still within retry threshold, continue using primary
+	app.logger.Debug("Primary IP still within retry threshold, continuing with primary",
+		zap.String("primary_ip", cfg.PrimaryIP),
+		zap.Int("failure_count", failureCount),
+		zap.Int("max_retries", cfg.FailoverRetries),
+	)
+	return cfg.PrimaryIP
+}
+
+// reachabilityTimeout returns the timeout of the reachability check of one IP, see checkTimeout
+func (app *Application) reachabilityTimeout(cfg *config.Config) time.Duration {
+	return checkTimeout(cfg, app.getHealthChecker())
+}
+
+// checkTimeout returns the timeout of the reachability check of one IP by healthChecker: the
+// configured budget, or else a short timeout extended to the worst case of the configured attempts
+func checkTimeout(cfg *config.Config, healthChecker interfaces.HealthChecker) time.Duration {
+	timeout := cfg.Reachability.Budget
+	if timeout <= 0 {
+		timeout = minReachabilityTimeout
+		if checker, ok := healthChecker.(interface{ Budget() time.Duration }); ok {
+			timeout = max(timeout, checker.Budget())
+		}
+	}
+	return timeout
+}
+
+// fallbackIP returns the IP to fail over to once the primary IP exceeded its retries. With only a
+// secondary IP it is returned without a check. With fallback_ips, the secondary and fallback IPs are
+// checked in order, see config.Config.FallbackTargets, and the first reachable one is returned; an
+// applied fallback IP is kept until it failed FailoverRetries consecutive checks. The failure counts
+// are kept in state by IP. If no fallback IP is usable, the secondary IP is returned.
+func (app *Application) fallbackIP(ctx context.Context, cfg *config.Config, store interfaces.StateStore, lastAppliedIP string) string {
+	targets := cfg.FallbackTargets()
+	if len(targets) == 1 {
+		return cfg.SecondaryIP
+	}
+
+	for _, ip := range targets {
+		checkCtx, cancel := context.WithTimeout(ctx, app.reachabilityTimeout(cfg))
+		err := app.probeReachability(checkCtx, ip)
+		cancel()
+		if ctx.Err() != nil {
+			// Interrupted by shutdown, keep the applied IP
+			return lastAppliedIP
+		}
+
+		failureCount, getErr := store.GetFailureCount(ctx, ip)
+		if getErr != nil && !errors.IsNotFoundError(getErr) {
+			app.logger.Warn("failed to get fallback IP failure count",
+				zap.String("fallback_ip", ip),
+				zap.Error(getErr),
+			)
+		}
+
+		if err == nil {
+			if failureCount > 0 {
+				app.setFailureCount(ctx, store, ip, 0)
+			}
+			app.logger.Debug("fallback IP is reachable",
+				zap.String("fallback_ip", ip),
+			)
+			return ip
+		}
+
+		failureCount++
+		app.setFailureCount(ctx, store, ip, failureCount)
+		app.logger.Warn("fallback IP unreachable",
+			zap.String("fallback_ip", ip),
+			zap.Int("failure_count", failureCount),
+			zap.Int("max_retries", cfg.FailoverRetries),
+			zap.Error(err),
+		)
+		if ip == lastAppliedIP && failureCount < cfg.FailoverRetries {
+			return ip
+		}
+	}
+
+	app.logger.Error("all fallback IPs are unreachable, using the secondary IP",
+		zap.Strings("fallback_ips", targets),
+	)
+	return cfg.SecondaryIP
+}
+
+// setFailureCount stores the failure count of a fallback IP
+func (app *Application) setFailureCount(ctx context.Context, store interfaces.StateStore, ip string, count int) {
+	if err := store.SetFailureCount(ctx, ip, count); err != nil {
+		app.logger.Warn("failed to persist fallback IP failure count",
+			zap.String("fallback_ip", ip),
+			zap.Int("failure_count", count),
+			zap.Error(err),
+		)
+	}
+}
+
+// isFallbackIP reports whether ip is the secondary IP or one of the fallback IPs of cfg
+func isFallbackIP(cfg *config.Config, ip string) bool {
+	return slices.Contains(cfg.FallbackTargets(), ip)
+}
+
+// runFailoverHook runs a failover hook command, if configured, logging its output
+func (app *Application) runFailoverHook(ctx context.Context, phase, command, fromIP, toIP string) error {
+	if command == "" {
+		return nil
+	}
+
+	app.logger.Info("running failover hook",
+		zap.String("phase", phase),
+		zap.String("from_ip", fromIP),
+		zap.String("to_ip", toIP),
+	)
+
+	output, err := hook.Run(ctx, command, fromIP, toIP, app.getConfig().HookTimeout)
+	if output != "" {
+		app.logger.Info("failover hook output",
+			zap.String("phase", phase),
+			zap.String("output", output),
+		)
+	}
+	return err
+}
+
+// changeIntervalElapsed reports whether DNS may change from lastAppliedIP to targetIP, which is not
+// the case until MinChangeInterval has passed since the last change. With MinChangeIntervalFailbackOnly
+// only changes to the primary IP are held down. Suppressed changes are logged and counted.
+func (app *Application) changeIntervalElapsed(ctx context.Context, cfg *config.Config, store interfaces.StateStore, targetIP, lastAppliedIP string) bool {
+	if cfg.MinChangeInterval <= 0 || lastAppliedIP == "" {
+		return true
+	}
+	if cfg.MinChangeIntervalFailbackOnly && targetIP != cfg.PrimaryIP {
+		return true
+	}
+
+	lastChange, err := store.GetLastChangeTime(ctx)
+	if err != nil {
+		if !errors.IsNotFoundError(err) {
+			app.logger.Warn("failed to get last change time - not holding down the change", zap.Error(err))
+		}
+		return true
+	}
+
+	sinceChange := time.Since(lastChange)
+	if lastChange.IsZero() || sinceChange >= cfg.MinChangeInterval {
+		return true
+	}
+
+	app.metrics.IncrementChangesSuppressed()
+	app.logger.Info("IP change suppressed, min_change_interval has not elapsed since the last change",
+		zap.String("from_ip", lastAppliedIP),
+		zap.String("to_ip", targetIP),
+		zap.Time("last_change_time", lastChange),
+		zap.Duration("min_change_interval", cfg.MinChangeInterval),
+		zap.Duration("remaining", cfg.MinChangeInterval-sinceChange),
+	)
+	return false
+}
+
+// changeDebounced records that this poll selected targetIP as a new IP and reports whether enough
+// consecutive polls selected it to act on it, see ChangeDebounceCount. The pending IP and its count
+// are kept in state, so they also accumulate across -once runs.
+func (app *Application) changeDebounced(ctx context.Context, store interfaces.StateStore, targetIP, lastAppliedIP string) bool {
+	debounceCount := app.getConfig().ChangeDebounceCount
+	if debounceCount <= 1 {
+		return true
+	}
+
+	pendingIP, pendingCount, err := store.GetPendingIP(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		app.logger.Warn("failed to get pending IP - debounce starts over", zap.Error(err))
+	}
+	if pendingIP != targetIP {
+		// A different IP than last poll was selected, start counting again
+		pendingIP, pendingCount = targetIP, 0
+	}
+	pendingCount++
+
+	if pendingCount >= debounceCount {
+		app.logger.Info("IP change confirmed by consecutive polls",
+			zap.String("from_ip", lastAppliedIP),
+			zap.String("to_ip", targetIP),
+			zap.Int("pending_count", pendingCount),
+		)
+		app.resetPendingIP(ctx, store)
+		return true
+	}
+
+	if err := store.SetPendingIP(ctx, pendingIP, pendingCount); err != nil {
+		app.logger.Warn("failed to persist pending IP", zap.Error(err))
+	}
+
+	app.logger.Info("IP change pending, waiting for consecutive polls to confirm it",
+		zap.String("from_ip", lastAppliedIP),
+		zap.String("to_ip", targetIP),
+		zap.Int("pending_count", pendingCount),
+		zap.Int("change_debounce_count", debounceCount),
+	)
+	return false
+}
+
+// resetPendingIP clears a pending IP change, if any
+func (app *Application) resetPendingIP(ctx context.Context, store interfaces.StateStore) {
+	pendingIP, _, err := store.GetPendingIP(ctx)
+	if err != nil || pendingIP == "" {
+		return
+	}
+
+	if err := store.ResetPendingIP(ctx); err != nil {
+		app.logger.Warn("failed to reset pending IP", zap.Error(err))
+	}
+}
+
+// adjustRecordTTLs lowers the TTL of the DNS records to PreFailoverTTL once the primary failure count
+// reaches one below FailoverRetries, so resolvers pick up an upcoming failover sooner. The configured
+// TTLs are restored once the primary IP is selected and reachable again, that is after failing back or
+// when the primary recovered before the failover. Failures are logged and retried on the next poll.
+func (app *Application) adjustRecordTTLs(ctx context.Context, cfg *config.Config, group *failoverGroup, targetIP, lastAppliedIP string) {
+	lowTTL, err := group.store.GetLowTTLMode(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		app.logger.Warn("failed to get low TTL mode", zap.Error(err))
+		return
+	}
+	if !lowTTL && cfg.PreFailoverTTL <= 0 {
+		return
+	}
+
+	failureCount, err := group.store.GetPrimaryFailureCount(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		app.logger.Warn("failed to get primary failure count for TTL adjustment", zap.Error(err))
+		return
+	}
+	failureCount += group.transientFailureCount
+
+	switch {
+	case !lowTTL && lastAppliedIP == cfg.PrimaryIP && failureCount > 0 && failureCount >= cfg.FailoverRetries-1:
+		app.logger.Info("primary IP about to fail over, lowering DNS record TTLs",
+			zap.Int("failure_count", failureCount),
+			zap.Int("max_retries", cfg.FailoverRetries),
+			zap.Int("ttl", cfg.PreFailoverTTL),
+		)
+		if app.setRecordTTLs(ctx, cfg, func(config.DNSConfig) int { return cfg.PreFailoverTTL }) {
+			app.setLowTTLMode(ctx, group.store, true)
+		}
+
+	case lowTTL && targetIP == cfg.PrimaryIP && failureCount == 0:
+		if lastAppliedIP != cfg.PrimaryIP {
+			// Failing back in this cycle, the DNS update writes the configured TTLs
+			app.logger.Info("failing back to primary IP, restoring DNS record TTLs")
+			app.setLowTTLMode(ctx, group.store, false)
+			return
+		}
+
+		app.logger.Info("primary IP reachable, restoring DNS record TTLs")
+		if app.setRecordTTLs(ctx, cfg, func(dnsConfig config.DNSConfig) int { return dnsConfig.TTL }) {
+			app.setLowTTLMode(ctx, group.store, false)
+		}
+	}
+}
+
+// setRecordTTLs sets the TTL of each existing DNS record to the value returned by ttl, keeping its value.
+// Records that do not exist or already have the TTL are skipped. It reports whether all records were handled.
+func (app *Application) setRecordTTLs(ctx context.Context, cfg *config.Config, ttl func(config.DNSConfig) int) bool {
+	providers := app.getDNSProviders()
+
+	ok := true
+	for _, dnsConfig := range cfg.DNS {
+		provider := providers[dnsConfig.Key()]
+		if provider == nil {
+			ok = false
+			continue
+		}
+
+		record := interfaces.DNSRecord{
+			Name:     dnsConfig.Name,
+			Type:     dnsConfig.Type,
+			TTL:      ttl(dnsConfig),
+			Provider: dnsConfig.Provider,
+			Metadata: dnsConfig.Metadata,
+		}
+
+		var existing *interfaces.DNSRecord
+		err := app.withRetry(ctx, record, "get", func(ctx context.Context) error {
+			var err error
+			existing, err = getRecord(ctx, provider, record)
+			return err
+		})
+		if err != nil {
+			app.logger.Warn("failed to read DNS record for TTL change",
+				zap.String("provider", dnsConfig.Provider),
+				zap.String("record", dnsConfig.Name),
+				recordIDField(dnsConfig),
+				zap.Error(err),
+			)
+			ok = false
+			continue
+		}
+		if existing == nil || existing.TTL == record.TTL {
+			continue
+		}
+		// Providers may report the role of records that do not hold an IP, such as Route53 alias records
+		role := ipRole(cfg, existing.Value)
+		if role == "" {
+			role = existing.Metadata[interfaces.MetadataRole]
+		}
+		record.Value = existing.Value
+		record.Metadata = recordMetadata(dnsConfig, role)
+
+		if app.DryRun {
+			app.logger.Info("dry run: skipping DNS record TTL change",
+				zap.String("provider", dnsConfig.Provider),
+				zap.String("record", dnsConfig.Name),
+				recordIDField(dnsConfig),
+				zap.Int("old_ttl", existing.TTL),
+				zap.Int("new_ttl", record.TTL),
+			)
+			continue
+		}
+
+		var written bool
+		err = app.withRetry(ctx, record, "update", func(ctx context.Context) error {
+			var err error
+			written, err = app.writeRecord(ctx, provider, record, existing, false)
+			return err
+		})
+		if err != nil {
+			app.metrics.IncrementDNSErrors(dnsConfig.Provider, dnsConfig.Name)
+			app.logger.Warn("failed to change DNS record TTL",
+				zap.String("provider", dnsConfig.Provider),
+				zap.String("record", dnsConfig.Name),
+				recordIDField(dnsConfig),
+				zap.Int("ttl", record.TTL),
+				zap.Error(err),
+			)
+			ok = false
+			continue
+		}
+		if !written {
+			// The concurrent change was kept under the theirs-wins conflict policy
+			continue
+		}
+
+		app.metrics.IncrementRecordWrites(dnsConfig.Provider, dnsConfig.Name)
+		app.audit.Log(audit.Change{
+			Event:    audit.EventUpdate,
+			Provider: dnsConfig.Provider,
+			Record:   dnsConfig.Name,
+			FromIP:   existing.Value,
+			ToIP:     record.Value,
+			TTL:      record.TTL,
+		})
+		app.logger.Info("DNS record TTL changed",
+			zap.String("provider", dnsConfig.Provider),
+			zap.String("record", dnsConfig.Name),
+			recordIDField(dnsConfig),
+			zap.Int("old_ttl", existing.TTL),
+			zap.Int("new_ttl", record.TTL),
+		)
+	}
+	return ok
+}
+
+// setLowTTLMode stores whether the DNS records use the pre-failover TTL
+func (app *Application) setLowTTLMode(ctx context.Context, store interfaces.StateStore, enabled bool) {
+	if err := store.SetLowTTLMode(ctx, enabled); err != nil {
+		app.logger.Warn("failed to persist low TTL mode",
+			zap.Bool("in_low_ttl_mode", enabled),
+			zap.Error(err),
+		)
+	}
+}
+
+// failbackReady records a successful check of the primary IP and reports whether DNS may point to it.
+// While failed over to the secondary IP, failing back requires FailbackRetries consecutive successful
+// checks and FailbackDelay to have passed since the primary became reachable again. The recovery is
+// kept in state, so a restart does not shorten the delay.
+func (app *Application) failbackReady(ctx context.Context, cfg *config.Config, store interfaces.StateStore, lastAppliedIP string) bool {
+	if !isFallbackIP(cfg, lastAppliedIP) {
+		// Not failed over, nothing to delay
+		app.resetPrimaryRecovery(ctx, store)
+		return true
+	}
+
+	now := time.Now()
+	recoveredAt, successCount, err := store.GetPrimaryRecovery(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		app.logger.Error("failed to get primary recovery state - failback delay starts over",
+			zap.Error(err),
+		)
+	}
+	if recoveredAt.IsZero() {
+		recoveredAt = now
+		successCount = 0
+	}
+	successCount++
+
+	// The background prober may have observed more consecutive successes than polls so far
+	if status, ok := app.probeStatus(cfg.PrimaryIP); ok && status.ConsecutiveSuccesses > successCount {
+		successCount = status.ConsecutiveSuccesses
+	}
+
+	recoveredFor := now.Sub(recoveredAt)
+	if successCount >= cfg.FailbackRetries && recoveredFor >= cfg.FailbackDelay {
+		app.logger.Info("Primary IP recovered, failing back to primary",
+			zap.String("primary_ip", cfg.PrimaryIP),
+			zap.Int("success_count", successCount),
+			zap.Duration("recovered_for", recoveredFor),
+		)
+		return true
+	}
+
+	if err := store.SetPrimaryRecovery(ctx, recoveredAt, successCount); err != nil {
+		app.logger.Error("failed to persist primary recovery state",
+			zap.Error(err),
+			zap.Int("success_count", successCount),
+		)
+	}
+
+	app.logger.Info("Primary IP reachable again, delaying failback",
+		zap.String("primary_ip", cfg.PrimaryIP),
+		zap.String("secondary_ip", cfg.SecondaryIP),
+		zap.Int("success_count", successCount),
+		zap.Int("failback_retries", cfg.FailbackRetries),
+		zap.Duration("recovered_for", recoveredFor),
+		zap.Duration("failback_delay", cfg.FailbackDelay),
+	)
+	return false
+}
+
+// resetPrimaryRecovery clears a pending primary recovery, if any
+func (app *Application) resetPrimaryRecovery(ctx context.Context, store interfaces.StateStore) {
+	recoveredAt, successCount, err := store.GetPrimaryRecovery(ctx)
+	if err != nil || (recoveredAt.IsZero() && successCount == 0) {
+		return
+	}
+
+	if err := store.ResetPrimaryRecovery(ctx); err != nil {
+		app.logger.Error("failed to reset primary recovery state", zap.Error(err))
+	}
+}
+
+// probeStatus returns the latest background probe state for the IP, if the prober is enabled and has
+// probed it recently, see prober.Prober.FreshStatus. Stale results are neither used as a check nor
+// counted towards failing over or back.
+func (app *Application) probeStatus(ip string) (prober.TargetStatus, bool) {
+	app.configMu.RLock()
+	p := app.prober
+	app.configMu.RUnlock()
+
+	if p == nil {
+		return prober.TargetStatus{}, false
+	}
+	return p.FreshStatus(ip)
+}
+
+// probeReachability returns the latest background probe result for the IP,
+// falling back to a direct check when no recent probe result is available
+func (app *Application) probeReachability(ctx context.Context, ip string) error {
+	if status, ok := app.probeStatus(ip); ok {
+		return status.LastError
+	}
+	return app.checkIPReachability(ctx, ip)
+}
+
+// Evidence of the public IP detected by a poll, see egressEvidence
+const (
+	egressUnknown   = iota // Not detected, ignored, or neither the primary nor the secondary IP
+	egressPrimary          // The host egresses through the primary uplink
+	egressSecondary        // The host egresses through the secondary uplink, the primary is down
+)
+
+// egressEvidence reports what the detected public egressIP says about the uplinks of cfg, if
+// egress_ip_weight is set. IPv6 addresses are compared in their canonical form.
+func (app *Application) egressEvidence(cfg *config.Config, egressIP string) int {
+	egress, err := netip.ParseAddr(egressIP)
+	if cfg.EgressIPWeight <= 0 || err != nil {
+		return egressUnknown
+	}
+
+	if egress.Unmap() == parseAddr(cfg.PrimaryIP) {
+		return egressPrimary
+	}
+	for _, fallbackIP := range cfg.FallbackTargets() {
+		if egress.Unmap() == parseAddr(fallbackIP) {
+			return egressSecondary
+		}
+	}
+	return egressUnknown
+}
+
+// parseAddr parses ip, returning the zero address if it is not an IP address
+func parseAddr(ip string) netip.Addr {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// checkIPReachability checks whether the given IP address is reachable, as configured in the
+// reachability settings
+func (app *Application) checkIPReachability(ctx context.Context, ip string) error {
+	ctx, span := tracing.Start(ctx, "reachability.Check", tracing.AttrTargetIP.String(ip))
+	start := time.Now()
+	err := app.getHealthChecker().Check(ctx, ip)
+	app.metrics.ObserveReachabilityDuration(ip, time.Since(start))
+	tracing.End(span, err)
+	return err
+}
+
+// reportPrimaryFailureCount sets the primary failure count metric of a failover group, including
+// failures counted only in memory because they could not be persisted
+func (app *Application) reportPrimaryFailureCount(ctx context.Context, cfg *config.Config, group *failoverGroup) {
+	failureCount, err := group.store.GetPrimaryFailureCount(ctx)
+	if err != nil {
+		failureCount = 0
+	}
+	app.metrics.SetPrimaryFailureCount(cfg.PrimaryIP, failureCount+group.transientFailureCount)
+}
+
+// updateDNSRecords updates all configured DNS records concurrently, at most MaxConcurrentUpdates at a time,
+// and returns the result of each successful update once all of them finished. The records are those
+// of a failover group, see config.Config.ForRecord, and store the group's state store.
+func (app *Application) updateDNSRecords(ctx context.Context, cfg *config.Config, store interfaces.StateStore, targetIP, lastAppliedIP string) ([]interfaces.RecordUpdateResult, error) {
+	providers := app.getDNSProviders()
+
+	// Each record is updated in its own goroutine. Outcomes are collected by index,
+	// so results and errors keep the order of the configuration.
+	outcomes := make([]recordUpdateOutcome, len(cfg.DNS))
+
+	var sem chan struct{}
+	if cfg.MaxConcurrentUpdates > 0 {
+		sem = make(chan struct{}, cfg.MaxConcurrentUpdates)
+	}
+
+	// While the records use the pre-failover TTL, failover writes keep it
+	lowTTL, err := store.GetLowTTLMode(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		app.logger.Warn("failed to get low TTL mode", zap.Error(err))
+	}
+	lowTTL = lowTTL && cfg.PreFailoverTTL > 0
+
+	role := ipRole(cfg, targetIP)
+
+	dnsConfigs := make([]config.DNSConfig, len(cfg.DNS))
+	for i, dnsConfig := range cfg.DNS {
+		if lowTTL {
+			dnsConfig.TTL = cfg.PreFailoverTTL
+		}
+		dnsConfigs[i] = dnsConfig
+	}
+
+	// Records of a batch take a single slot of the concurrency limit
+	var wg sync.WaitGroup
+	for _, batch := range app.recordBatches(cfg, providers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					for _, i := range batch {
+						outcomes[i].err = fmt.Errorf("failed to update DNS record %s with provider %s: %w", dnsConfigs[i].Name, dnsConfigs[i].Provider, ctx.Err())
+					}
+					return
+				}
+			}
+
+			if len(batch) == 1 {
+				i := batch[0]
+				outcomes[i] = app.updateDNSRecord(ctx, dnsConfigs[i], providers[dnsConfigs[i].Key()], targetIP, lastAppliedIP, role)
+				return
+			}
+
+			batchConfigs := make([]config.DNSConfig, len(batch))
+			for j, i := range batch {
+				batchConfigs[j] = dnsConfigs[i]
+			}
+			batcher := providers[batchConfigs[0].Key()].(interfaces.BatchUpdater)
+			for j, outcome := range app.updateDNSRecordBatch(ctx, batcher, batchConfigs, providers, targetIP, lastAppliedIP, role) {
+				outcomes[batch[j]] = outcome
+			}
+		}()
+	}
+	wg.Wait()
+
+	var errs error
+	var results []interfaces.RecordUpdateResult
+
+	// Providers written to in this cycle and the last error of those that failed, for incident tracking
+	attempted := make(map[string]bool)
+	failures := make(map[string]error)
+	for i, outcome := range outcomes {
+		provider := cfg.DNS[i].Provider
+		if outcome.attempted {
+			attempted[provider] = true
+		}
+		if outcome.err != nil {
+			errs = multierr.Append(errs, outcome.err)
+			if outcome.attempted {
+				failures[provider] = outcome.cause
+			}
+		}
+		if outcome.result != nil {
+			results = append(results, *outcome.result)
+		}
+	}
+	app.recordProviderOutcomes(ctx, attempted, failures)
+
+	return results, errs
+}
+
+// recordBatches groups the records of cfg, by index, into the batches they are updated in. Records
+// whose providers implement interfaces.BatchUpdater with the same batch key, such as records in one
+// Route53 hosted zone or Cloudflare zone, form a batch; all other records are a batch of their own.
+// Batch updates are not conditional, so records are not batched under the theirs-wins and
+// alert-only conflict policies.
+func (app *Application) recordBatches(cfg *config.Config, providers map[string]interfaces.DNSProvider) [][]int {
+	batchable := cfg.ConflictPolicy != "theirs-wins" && cfg.ConflictPolicy != "alert-only"
+
+	var batches [][]int
+	byKey := make(map[string]int) // Batch key -> index in batches
+	for i, dnsConfig := range cfg.DNS {
+		batcher, ok := providers[dnsConfig.Key()].(interfaces.BatchUpdater)
+		if !batchable || !ok {
+			batches = append(batches, []int{i})
+			continue
+		}
+
+		key := batcher.BatchKey()
+		if j, exists := byKey[key]; exists {
+			batches[j] = append(batches[j], i)
+			continue
+		}
+		byKey[key] = len(batches)
+		batches = append(batches, []int{i})
+	}
+	return batches
+}
+
+// verifyPropagation waits until the updated A and AAAA records resolve to their new value on
+// the propagation resolvers, and logs a warning for those that do not within the timeout.
+// Records are checked concurrently. The outcome does not affect the update.
+func (app *Application) verifyPropagation(ctx context.Context, cfg *config.Config, results []interfaces.RecordUpdateResult) {
+	checker := &propagation.Checker{
+		Resolvers: cfg.PropagationResolvers,
+		Interval:  cfg.PropagationCheckInterval,
+		Timeout:   cfg.PropagationCheckTimeout,
+	}
+
+	var wg sync.WaitGroup
+	for _, result := range results {
+		record := result.Record
+		if record.Type != "A" && record.Type != "AAAA" {
+			app.logger.Debug("skipping propagation check of non-address record",
+				zap.String("record", record.Name),
+				zap.String("type", record.Type),
+			)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			unconfirmed := checker.Wait(ctx, record.Name, record.Value)
+			if len(unconfirmed) > 0 {
+				app.logger.Warn("DNS propagation not confirmed",
+					zap.String("record", record.Name),
+					zap.String("ip", record.Value),
+					zap.Strings("unconfirmed_resolvers", unconfirmed),
+					zap.Duration("timeout", cfg.PropagationCheckTimeout),
+				)
+				return
+			}
+
+			app.logger.Info("DNS propagation confirmed",
+				zap.String("record", record.Name),
+				zap.String("ip", record.Value),
+				zap.Duration("elapsed", time.Since(start)),
+			)
+		}()
+	}
+	wg.Wait()
+}
+
+// withRetry calls fn, a call of the record's DNS provider, and retries it on retryable errors
+// with exponential backoff as configured by ProviderMaxRetries and the retry delays
+func (app *Application) withRetry(ctx context.Context, record interfaces.DNSRecord, operation string, fn func(ctx context.Context) error) error {
+	cfg := app.getConfig()
+	policy := retry.Policy{
+		MaxRetries: cfg.ProviderMaxRetries,
+		BaseDelay:  cfg.ProviderRetryBaseDelay,
+		MaxDelay:   cfg.ProviderRetryMaxDelay,
+		Jitter:     retry.DefaultJitter,
+	}
+
+	return retry.Do(ctx, policy, fn, func(attempt int, err error, delay time.Duration) {
+		app.metrics.IncrementProviderRetries(record.Provider, record.Name)
+		app.logger.Warn("DNS provider call failed, retrying",
+			zap.String("provider", record.Provider),
+			zap.String("record", record.Name),
+			zap.String("operation", operation),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+	})
+}
+
+// recordUpdateOutcome is the outcome of updating a single DNS record, see updateDNSRecord
+type recordUpdateOutcome struct {
+	result    *interfaces.RecordUpdateResult // Nil if the record was not updated
+	attempted bool                           // Whether the provider was written to
+	cause     error                          // Error returned by the provider write
+	err       error
+}
+
+// pendingRecordWrite is a DNS record that needs to be written to its provider, see prepareRecordUpdate
+type pendingRecordWrite struct {
+	dnsConfig     config.DNSConfig
+	record        interfaces.DNSRecord
+	existing      *interfaces.DNSRecord // Record read before the update, nil if it does not exist or was not read
+	previousValue string
+	cached        bool
+}
+
+// updateDNSRecord updates a single DNS record to targetIP, whose role is given by role, unless it
+// is already up to date. It is called concurrently for all records, see updateDNSRecords.
+func (app *Application) updateDNSRecord(ctx context.Context, dnsConfig config.DNSConfig, provider interfaces.DNSProvider, targetIP, lastAppliedIP, role string) recordUpdateOutcome {
+	write, outcome := app.prepareRecordUpdate(ctx, dnsConfig, provider, targetIP, lastAppliedIP, role)
+	if write == nil {
+		return outcome
+	}
+	return app.writePendingRecord(ctx, provider, write)
+}
+
+// writePendingRecord writes a DNS record on its own, with retries and conflict handling
+func (app *Application) writePendingRecord(ctx context.Context, provider interfaces.DNSProvider, write *pendingRecordWrite) recordUpdateOutcome {
+	var written bool
+	err := app.withRetry(ctx, write.record, "update", func(ctx context.Context) error {
+		var err error
+		written, err = app.writeRecord(ctx, provider, write.record, write.existing, write.cached)
+		return err
+	})
+	if err != nil {
+		return app.recordWriteFailed(write, err)
+	}
+	if !written {
+		// The concurrent change was kept under the theirs-wins conflict policy
+		return recordUpdateOutcome{attempted: true}
+	}
+
+	return app.recordWritten(write)
+}
+
+// updateDNSRecordBatch updates DNS records whose providers share a batch key, see recordBatches.
+// Records that need a write are written in a single change by batcher, so they are all updated or
+// none is; a single record is written on its own like by updateDNSRecord. Outcomes are by index of dnsConfigs.
+func (app *Application) updateDNSRecordBatch(ctx context.Context, batcher interfaces.BatchUpdater, dnsConfigs []config.DNSConfig, providers map[string]interfaces.DNSProvider, targetIP, lastAppliedIP, role string) []recordUpdateOutcome {
+	outcomes := make([]recordUpdateOutcome, len(dnsConfigs))
+
+	var writes []*pendingRecordWrite
+	var indexes []int
+	for i, dnsConfig := range dnsConfigs {
+		write, outcome := app.prepareRecordUpdate(ctx, dnsConfig, providers[dnsConfig.Key()], targetIP, lastAppliedIP, role)
+		if write == nil {
+			outcomes[i] = outcome
+			continue
+		}
+		writes = append(writes, write)
+		indexes = append(indexes, i)
+	}
+
+	switch len(writes) {
+	case 0:
+		return outcomes
+	case 1:
+		outcomes[indexes[0]] = app.writePendingRecord(ctx, providers[writes[0].dnsConfig.Key()], writes[0])
+		return outcomes
+	}
+
+	records := make([]interfaces.DNSRecord, len(writes))
+	for i, write := range writes {
+		records[i] = write.record
+	}
+
+	err := app.withRetry(ctx, records[0], "batch update", func(ctx context.Context) error {
+		ctx, span := tracing.Start(ctx, "dns.UpdateRecords",
+			tracing.AttrProvider.String(records[0].Provider),
+			tracing.AttrRecordCount.Int(len(records)),
+			tracing.AttrTargetIP.String(records[0].Value),
+		)
+		// Each record of the batch is observed with the duration of the whole change
+		start := time.Now()
+		err := batcher.UpdateRecords(ctx, records)
+		duration := time.Since(start)
+		tracing.End(span, err)
+		for _, record := range records {
+			app.metrics.ObserveDNSUpdateDuration(record.Provider, record.Name, duration)
+		}
+		return err
+	})
+	for i, write := range writes {
+		if err != nil {
+			outcomes[indexes[i]] = app.recordWriteFailed(write, err)
+		} else {
+			outcomes[indexes[i]] = app.recordWritten(write)
+		}
+	}
+	return outcomes
+}
+
+// prepareRecordUpdate reads a DNS record before it is updated to targetIP and returns the write it
+// needs. If it needs none, because it is already up to date or in dry-run mode, the returned write
+// is nil and the outcome is returned instead.
+func (app *Application) prepareRecordUpdate(ctx context.Context, dnsConfig config.DNSConfig, provider interfaces.DNSProvider, targetIP, lastAppliedIP, role string) (*pendingRecordWrite, recordUpdateOutcome) {
+	if provider == nil {
+		app.logger.Error("DNS provider not found",
+			zap.String("record", dnsConfig.Name),
+			recordIDField(dnsConfig),
+		)
+		return nil, recordUpdateOutcome{err: fmt.Errorf("DNS provider not found for record %s", dnsConfig.Name)}
+	}
+
+	record := interfaces.DNSRecord{
+		Name:     dnsConfig.Name,
+		Type:     dnsConfig.Type,
+		Value:    targetIP,
+		TTL:      dnsConfig.TTL,
+		Provider: dnsConfig.Provider,
+		Metadata: recordMetadata(dnsConfig, role),
 	}
 
-	if lastAppliedIP == targetIP {
-		app.logger.Debug("IP already applied, skipping update",
+	existing, previousValue, cached := app.currentRecord(ctx, provider, record, lastAppliedIP)
+
+	// Skip the write if the provider already holds the target value, unless forced
+	if !app.ForceUpdate && recordUpToDate(existing, record) {
+		app.metrics.IncrementRecordNoops(dnsConfig.Provider, dnsConfig.Name)
+		app.logger.Info("DNS record already up to date, skipping update",
+			zap.String("provider", dnsConfig.Provider),
+			zap.String("record", dnsConfig.Name),
+			recordIDField(dnsConfig),
 			zap.String("ip", targetIP),
 		)
-		return nil
-	}
 
-	// Update DNS records
-	if err := app.updateDNSRecords(ctx, targetIP); err != nil {
-		return fmt.Errorf("failed to update DNS records: %w", err)
+		return nil, recordUpdateOutcome{result: &interfaces.RecordUpdateResult{
+			Record:        record,
+			PreviousValue: previousValue,
+		}}
 	}
 
-	// Update state
-	if err := app.stateStore.SetLastAppliedIP(ctx, targetIP); err != nil {
-		return fmt.Errorf("failed to update state: %w", err)
+	if app.DryRun {
+		app.metrics.IncrementDryRunUpdates(dnsConfig.Provider, dnsConfig.Name)
+		app.logger.Info("dry run: skipping DNS record update",
+			zap.String("provider", dnsConfig.Provider),
+			zap.String("record", dnsConfig.Name),
+			recordIDField(dnsConfig),
+			zap.String("old_ip", previousValue),
+			zap.String("new_ip", targetIP),
+			zap.Bool("previous_value_cached", cached),
+		)
+
+		return nil, recordUpdateOutcome{result: &interfaces.RecordUpdateResult{
+			Record:              record,
+			PreviousValue:       previousValue,
+			PreviousValueCached: cached,
+		}}
 	}
 
-	app.metrics.SetLastChangeTime(time.Now())
+	return &pendingRecordWrite{
+		dnsConfig:     dnsConfig,
+		record:        record,
+		existing:      existing,
+		previousValue: previousValue,
+		cached:        cached,
+	}, recordUpdateOutcome{}
+}
 
-	app.logger.Info("IP failover completed successfully",
-		zap.String("from_ip", lastAppliedIP),
-		zap.String("to_ip", targetIP),
+// recordWriteFailed logs and counts a failed write of a DNS record and returns its outcome
+func (app *Application) recordWriteFailed(write *pendingRecordWrite, err error) recordUpdateOutcome {
+	dnsConfig := write.dnsConfig
+	app.metrics.IncrementDNSErrors(dnsConfig.Provider, dnsConfig.Name)
+	app.logger.Error("failed to update DNS record",
+		zap.String("provider", dnsConfig.Provider),
+		zap.String("record", dnsConfig.Name),
+		recordIDField(dnsConfig),
+		zap.String("ip", write.record.Value),
+		zap.Error(err),
 	)
-
-	return nil
+	return recordUpdateOutcome{
+		attempted: true,
+		cause:     err,
+		err:       fmt.Errorf("failed to update DNS record %s with provider %s: %w", dnsConfig.Name, dnsConfig.Provider, err),
+	}
 }
 
-// determineTargetIP determines which IP should be used based on active reachability check
-// Implements retry logic: only switches to secondary after configurable number of consecutive failures
-// On first run (lastAppliedIP empty), verifies primary reachability before returning it
-func (app *Application) determineTargetIP(ctx context.Context, lastAppliedIP string) string {
-	// Create a context with a short timeout for reachability checks
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	// Try to reach the primary IP first
-	err := app.checkIPReachability(ctx, app.config.PrimaryIP)
-	if err == nil {
-		// Primary is reachable, reset failure count and use primary
-		if resetErr := app.stateStore.ResetPrimaryFailureCount(ctx); resetErr != nil {
-			app.logger.Error("critical: failed to reset primary failure count - state persistence compromised",
-				zap.Error(resetErr),
-				zap.String("primary_ip", app.config.PrimaryIP),
-				zap.Int("transient_failure_count", app.transientFailureCount),
-			)
-			// Handle based on configured strategy
-			if app.config.StateFailureStrategy == "fail_fast" {
-				app.logger.Fatal("state persistence failure - failing fast as configured")
-			}
-			// Continue with primary but log critical error for monitoring
-		} else {
-			// Successfully reset persisted count - also reset transient counter
-			if app.transientFailureCount > 0 {
-				app.logger.Info("primary IP recovered, resetting transient failure count",
-					zap.String("primary_ip", app.config.PrimaryIP),
-					zap.Int("transient_failure_count", app.transientFailureCount),
-				)
-				app.transientFailureCount = 0
-			}
-		}
+// recordWritten logs and counts a successful write of a DNS record and returns its outcome
+func (app *Application) recordWritten(write *pendingRecordWrite) recordUpdateOutcome {
+	dnsConfig := write.dnsConfig
+	app.metrics.IncrementRecordWrites(dnsConfig.Provider, dnsConfig.Name)
 
-		app.logger.Debug("Primary IP is reachable, using primary",
-			zap.String("primary_ip", app.config.PrimaryIP),
-			zap.Int("transient_failure_count", app.transientFailureCount),
-		)
-		return app.config.PrimaryIP
+	// A record that could not be read but has a value from state is assumed to exist
+	event := audit.EventUpdate
+	if write.existing == nil && write.previousValue == "" {
+		event = audit.EventCreate
 	}
+	app.audit.Log(audit.Change{
+		Event:    event,
+		Provider: dnsConfig.Provider,
+		Record:   dnsConfig.Name,
+		FromIP:   write.previousValue,
+		ToIP:     write.record.Value,
+		TTL:      write.record.TTL,
+		Cached:   write.cached,
+	})
+
+	app.logger.Info("DNS record updated successfully",
+		zap.String("provider", dnsConfig.Provider),
+		zap.String("record", dnsConfig.Name),
+		recordIDField(dnsConfig),
+		zap.String("ip", write.record.Value),
+		zap.String("previous_value", write.previousValue),
+		zap.Bool("previous_value_cached", write.cached),
+	)
 
-	// Primary is unreachable, increment failure count
-	failureCount, getErr := app.stateStore.GetPrimaryFailureCount(ctx)
-	if getErr != nil {
-		app.logger.Error("critical: failed to get primary failure count - failover tracking compromised",
-			zap.Error(getErr),
-			zap.String("primary_ip", app.config.PrimaryIP),
-			zap.Int("transient_failure_count", app.transientFailureCount),
-		)
-
-		// Handle based on configured strategy
-		switch app.config.StateFailureStrategy {
-		case "fail_fast":
-			app.logger.Fatal("state persistence failure - failing fast as configured")
-		case "immediate_failover":
-			app.logger.Warn("state persistence failure - immediately failing over to secondary",
-				zap.String("primary_ip", app.config.PrimaryIP),
-				zap.String("secondary_ip", app.config.SecondaryIP),
-				zap.Int("transient_failure_count", app.transientFailureCount),
-			)
-			return app.config.SecondaryIP
-		case "continue_with_warning":
-			fallthrough
-		default:
-			// Use transient counter instead of resetting to 0
-			failureCount = 0 // This will be the persisted count
-			app.logger.Warn("using transient failure counter due to state persistence failure",
-				zap.Int("transient_failure_count", app.transientFailureCount),
-				zap.Error(getErr),
-			)
-		}
+	return recordUpdateOutcome{
+		attempted: true,
+		result: &interfaces.RecordUpdateResult{
+			Record:              write.record,
+			PreviousValue:       write.previousValue,
+			PreviousValueCached: write.cached,
+		},
 	}
+}
 
-	failureCount++
-	if setErr := app.stateStore.SetPrimaryFailureCount(ctx, failureCount); setErr != nil {
-		// Persistence failed - increment transient counter instead of losing the count
-		app.transientFailureCount++
-		app.logger.Error("critical: failed to persist primary failure count - using transient counter",
-			zap.Error(setErr),
-			zap.String("primary_ip", app.config.PrimaryIP),
-			zap.Int("failure_count", failureCount),
-			zap.Int("transient_failure_count", app.transientFailureCount),
-		)
-
-		// Handle based on configured strategy
-		switch app.config.StateFailureStrategy {
-		case "fail_fast":
-			app.logger.Fatal("state persistence failure - failing fast as configured")
-		case "immediate_failover":
-			app.logger.Warn("state persistence failure - immediately failing over to secondary",
-				zap.String("primary_ip", app.config.PrimaryIP),
-				zap.String("secondary_ip", app.config.SecondaryIP),
-				zap.Int("failure_count", failureCount),
-				zap.Int("transient_failure_count", app.transientFailureCount),
-			)
-			return app.config.SecondaryIP
-		case "continue_with_warning":
-			fallthrough
-		default:
-			// State persistence failed - continue with transient counter
-			app.logger.Warn("continuing with transient failure counter due to persistence failure",
-				zap.Int("transient_failure_count", app.transientFailureCount),
-				zap.Error(setErr),
-			)
+// writeRecord writes a DNS record and reports whether it was written.
+// For providers that support conditional updates, the write only succeeds if the record still matches
+// existing, as read before the update, and a concurrent change is resolved according to the conflict policy.
+func (app *Application) writeRecord(ctx context.Context, provider interfaces.DNSProvider, record interfaces.DNSRecord, existing *interfaces.DNSRecord, cached bool) (bool, error) {
+	updater, ok := provider.(interfaces.ConditionalUpdater)
+	if !ok || cached {
+		// Without a successful read there is nothing to condition the update on
+		err := app.timeRecordUpdate(ctx, "dns.UpdateRecord", record, func(ctx context.Context) error {
+			return provider.UpdateRecord(ctx, record)
+		})
+		if err != nil {
+			return false, err
 		}
-	} else {
-		// Persistence succeeded - reset transient counter
-		app.transientFailureCount = 0
+		return true, nil
 	}
 
-	// If we have transient failures, attempt to persist them
-	if app.transientFailureCount > 0 {
-		app.attemptTransientPersistence(ctx, failureCount)
+	err := app.timeRecordUpdate(ctx, "dns.UpdateRecordIf", record, func(ctx context.Context) error {
+		return updater.UpdateRecordIf(ctx, record, existing)
+	})
+	var conflictErr *errors.ConflictError
+	if !stderrors.As(err, &conflictErr) {
+		return err == nil, err
 	}
 
-	// Calculate total failure count including transient failures
-	totalFailureCount := failureCount + app.transientFailureCount
-
-	app.logger.Debug("Primary IP unreachable, incrementing failure count",
-		zap.String("primary_ip", app.config.PrimaryIP),
-		zap.Int("failure_count", failureCount),
-		zap.Int("transient_failure_count", app.transientFailureCount),
-		zap.Int("total_failure_count", totalFailureCount),
-		zap.Int("max_retries", app.config.FailoverRetries),
-		zap.Error(err),
+	policy := app.getConfig().ConflictPolicy
+	app.metrics.IncrementDNSConflicts(record.Provider, record.Name)
+	app.logger.Warn("DNS record was modified concurrently",
+		zap.String("provider", record.Provider),
+		zap.String("record", record.Name),
+		zap.String("expected_value", conflictErr.Expected),
+		zap.String("actual_value", conflictErr.Actual),
+		zap.String("desired_value", record.Value),
+		zap.String("conflict_policy", policy),
 	)
 
-	// Check if we've exceeded the retry threshold (including transient failures)
-	if totalFailureCount >= app.config.FailoverRetries {
-		app.logger.Warn("Primary IP exceeded retry threshold, falling back to secondary",
-			zap.String("primary_ip", app.config.PrimaryIP),
-			zap.String("secondary_ip", app.config.SecondaryIP),
-			zap.Int("failure_count", failureCount),
-			zap.Int("transient_failure_count", app.transientFailureCount),
-			zap.Int("total_failure_count", totalFailureCount),
-			zap.Int("max_retries", app.config.FailoverRetries),
+	switch policy {
+	case "theirs-wins":
+		app.logger.Info("keeping concurrently modified DNS record",
+			zap.String("provider", record.Provider),
+			zap.String("record", record.Name),
+			zap.String("value", conflictErr.Actual),
 		)
-		return app.config.SecondaryIP
+		return false, nil
+	case "alert-only":
+		return false, err
+	default: // ours-wins
+		// Re-read the record and apply the update once more against its new contents
+		current, err := getRecord(ctx, provider, record)
+		if err != nil {
+			return false, fmt.Errorf("failed to re-read record after conflict: %w", err)
+		}
+		if recordUpToDate(current, record) {
+			return true, nil
+		}
+		err = app.timeRecordUpdate(ctx, "dns.UpdateRecordIf", record, func(ctx context.Context) error {
+			return updater.UpdateRecordIf(ctx, record, current)
+		})
+		if err != nil {
+			return false, err
+		}
+		return true, nil
 	}
+}
 
-	// Still within retry threshold, but check if this is first run
-	if lastAppliedIP == "" {
-		// First run: primary is unreachable, check if secondary is reachable before using it
-		app.logger.Error("First run detected with unreachable primary - checking secondary IP reachability",
-			zap.String("primary_ip", app.config.PrimaryIP),
-			zap.String("secondary_ip", app.config.SecondaryIP),
-			zap.Int("failure_count", failureCount),
-			zap.Int("max_retries", app.config.FailoverRetries),
-		)
+// timeRecordUpdate runs update, a provider call writing record, in a trace span named name and
+// observes its duration, also if it failed
+func (app *Application) timeRecordUpdate(ctx context.Context, name string, record interfaces.DNSRecord, update func(context.Context) error) error {
+	ctx, span := tracing.Start(ctx, name, recordSpanAttributes(record)...)
+	start := time.Now()
+	err := update(ctx)
+	app.metrics.ObserveDNSUpdateDuration(record.Provider, record.Name, time.Since(start))
+	tracing.End(span, err)
+	return err
+}
 
-		// Check if secondary IP is reachable
-		err := app.checkIPReachability(ctx, app.config.SecondaryIP)
-		if err != nil {
-			app.logger.Error("Secondary IP is also unreachable - skipping DNS update to avoid pointing to unreachable host",
-				zap.String("primary_ip", app.config.PrimaryIP),
-				zap.String("secondary_ip", app.config.SecondaryIP),
-				zap.Int("failure_count", failureCount),
-				zap.Int("max_retries", app.config.FailoverRetries),
-				zap.Error(err),
-			)
-			// Return empty string to skip DNS update
-			return ""
-		}
+// getRecord reads the current contents of record from provider in a trace span
+func getRecord(ctx context.Context, provider interfaces.DNSProvider, record interfaces.DNSRecord) (*interfaces.DNSRecord, error) {
+	ctx, span := tracing.Start(ctx, "dns.GetRecord", recordSpanAttributes(record)...)
+	existing, err := provider.GetRecord(ctx, record.Name, record.Type)
+	tracing.End(span, err)
+	return existing, err
+}
 
-		app.logger.Info("Secondary IP is reachable - using secondary IP for DNS update",
-			zap.String("primary_ip", app.config.PrimaryIP),
-			zap.String("secondary_ip", app.config.SecondaryIP),
-			zap.Int("failure_count", failureCount),
-			zap.Int("max_retries", app.config.FailoverRetries),
-		)
-		// Return secondary IP to ensure DNS points to a reachable host
-		return app.config.SecondaryIP
+// recordSpanAttributes returns the trace span attributes of a provider call for record
+func recordSpanAttributes(record interfaces.DNSRecord) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		tracing.AttrProvider.String(record.Provider),
+		tracing.AttrRecordName.String(record.Name),
+		tracing.AttrRecordType.String(record.Type),
 	}
-
-	// Not first run: still within retry threshold, continue using primary
-	app.logger.Debug("Primary IP still within retry threshold, continuing with primary",
-		zap.String("primary_ip", app.config.PrimaryIP),
-		zap.Int("failure_count", failureCount),
-		zap.Int("max_retries", app.config.FailoverRetries),
-	)
-	return app.config.PrimaryIP
+	if record.Value != "" {
+		attrs = append(attrs, tracing.AttrTargetIP.String(record.Value))
+	}
+	return attrs
 }
 
-// checkIPReachability attempts to verify connectivity to the given IP address
-func (app *Application) checkIPReachability(ctx context.Context, ip string) error {
-	// Try to establish a TCP connection to a common port (80 for HTTP)
-	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, "80"), 3*time.Second)
+// currentRecord reads a record before it is updated and returns it, or nil if it does not exist
+// or could not be read, together with its previous value.
+// If the provider lookup fails, the last applied IP from state is returned as the previous value and marked as cached.
+func (app *Application) currentRecord(ctx context.Context, provider interfaces.DNSProvider, record interfaces.DNSRecord, lastAppliedIP string) (*interfaces.DNSRecord, string, bool) {
+	var existing *interfaces.DNSRecord
+	err := app.withRetry(ctx, record, "get", func(ctx context.Context) error {
+		var err error
+		existing, err = getRecord(ctx, provider, record)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s:80: %w", ip, err)
+		app.logger.Debug("failed to read record before update, using last known value from state",
+			zap.String("provider", record.Provider),
+			zap.String("record", record.Name),
+			zap.Error(err),
+		)
+		return nil, lastAppliedIP, true
 	}
-	defer func() {
-		if closeErr := conn.Close(); closeErr != nil {
-			app.logger.Debug("failed to close connection", zap.Error(closeErr))
-		}
-	}()
-
-	// Connection successful
-	return nil
-}
 
-// updateDNSRecords updates all configured DNS records
-func (app *Application) updateDNSRecords(ctx context.Context, targetIP string) error {
-	var errs error
+	if existing == nil {
+		// Record does not exist yet and will be created
+		return nil, "", false
+	}
 
-	for _, dnsConfig := range app.config.DNS {
-		provider, exists := app.dnsProviders[dnsConfig.Name]
-		if !exists {
-			app.logger.Error("DNS provider not found",
-				zap.String("record", dnsConfig.Name),
-			)
-			errs = multierr.Append(errs, fmt.Errorf("DNS provider not found for record %s", dnsConfig.Name))
-			continue
-		}
+	return existing, existing.Value, false
+}
 
-		record := interfaces.DNSRecord{
-			Name:     dnsConfig.Name,
-			Type:     dnsConfig.Type,
-			Value:    targetIP,
-			TTL:      dnsConfig.TTL,
-			Provider: dnsConfig.Provider,
-			Metadata: dnsConfig.Metadata,
-		}
+// ipRole returns the failover role of ip in cfg, interfaces.RolePrimary or interfaces.RoleSecondary
+// for the secondary and fallback IPs, or "" if ip is none of them
+func ipRole(cfg *config.Config, ip string) string {
+	switch {
+	case ip == cfg.PrimaryIP:
+		return interfaces.RolePrimary
+	case isFallbackIP(cfg, ip):
+		return interfaces.RoleSecondary
+	default:
+		return ""
+	}
+}
 
-		if err := provider.UpdateRecord(ctx, record); err != nil {
-			app.metrics.IncrementDNSErrors(dnsConfig.Provider, dnsConfig.Name)
-			app.logger.Error("failed to update DNS record",
-				zap.String("provider", dnsConfig.Provider),
-				zap.String("record", dnsConfig.Name),
-				zap.String("ip", targetIP),
-				zap.Error(err),
-			)
-			errs = multierr.Append(errs, fmt.Errorf("failed to update DNS record %s with provider %s: %w", dnsConfig.Name, dnsConfig.Provider, err))
-			continue
-		}
+// failoverDirection returns the metrics direction of a change from fromIP to toIP, or "" if it is
+// not between the primary IP and the secondary or a fallback IP, such as the first IP applied without state
+func failoverDirection(cfg *config.Config, fromIP, toIP string) string {
+	switch {
+	case fromIP == cfg.PrimaryIP && isFallbackIP(cfg, toIP):
+		return metrics.DirectionPrimaryToSecondary
+	case isFallbackIP(cfg, fromIP) && toIP == cfg.PrimaryIP:
+		return metrics.DirectionSecondaryToPrimary
+	default:
+		return ""
+	}
+}
 
-		app.metrics.IncrementDNSUpdates(dnsConfig.Provider, dnsConfig.Name)
-		app.logger.Info("DNS record updated successfully",
-			zap.String("provider", dnsConfig.Provider),
-			zap.String("record", dnsConfig.Name),
-			zap.String("ip", targetIP),
-		)
+// recordMetadata returns the configured metadata of a record with the role of the written IP added.
+// The configured map is copied, since records are written concurrently.
+func recordMetadata(dnsConfig config.DNSConfig, role string) map[string]string {
+	if role == "" {
+		return dnsConfig.Metadata
+	}
+	metadata := make(map[string]string, len(dnsConfig.Metadata)+1)
+	for key, value := range dnsConfig.Metadata {
+		metadata[key] = value
 	}
+	metadata[interfaces.MetadataRole] = role
+	return metadata
+}
 
-	return errs
+// recordUpToDate reports whether the record read from the provider already matches the desired record.
+// A TTL of zero means the provider does not report TTLs and is not compared.
+func recordUpToDate(existing *interfaces.DNSRecord, desired interfaces.DNSRecord) bool {
+	if existing == nil || existing.Value != desired.Value {
+		return false
+	}
+	return existing.TTL == 0 || existing.TTL == desired.TTL
 }
 
 // attemptTransientPersistence attempts to persist transient failure count when possible
-func (app *Application) attemptTransientPersistence(ctx context.Context, persistedCount int) {
+func (app *Application) attemptTransientPersistence(ctx context.Context, group *failoverGroup, persistedCount int) {
 	// Calculate the total count we want to persist
-	totalCount := persistedCount + app.transientFailureCount
+	totalCount := persistedCount + group.transientFailureCount
 
 	// Attempt to persist the total count
-	if err := app.stateStore.SetPrimaryFailureCount(ctx, totalCount); err != nil {
+	if err := group.store.SetPrimaryFailureCount(ctx, totalCount); err != nil {
 		app.logger.Debug("failed to persist transient failure count - will retry later",
 			zap.Error(err),
-			zap.Int("transient_failure_count", app.transientFailureCount),
+			zap.Int("transient_failure_count", group.transientFailureCount),
 			zap.Int("total_count", totalCount),
 		)
 	} else {
 		// Successfully persisted - reset transient counter
 		app.logger.Info("successfully persisted transient failure count",
-			zap.Int("transient_failure_count", app.transientFailureCount),
+			zap.Int("transient_failure_count", group.transientFailureCount),
 			zap.Int("total_count", totalCount),
 		)
-		app.transientFailureCount = 0
+		group.transientFailureCount = 0
 	}
 }
 
@@ -502,13 +2376,67 @@ func getVersion() string {
 	return fmt.Sprintf("%s (built %s)", Version, BuildTime)
 }
 
+// stringListFlag is a command line flag that can be given multiple times
+type stringListFlag []string
+
+// String returns the values joined by commas
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set appends a value
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// warnMissingOverlays reports configuration overlays that were skipped because they do not exist
+func warnMissingOverlays(missing []string) {
+	for _, overlay := range missing {
+		fmt.Fprintf(os.Stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+	}
+}
+
 func main() {
+	// Subcommands parse their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "status":
+			os.Exit(runStatusCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "validate":
+			os.Exit(runValidateCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "check-ip":
+			os.Exit(runCheckIPCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "test-provider":
+			os.Exit(runTestProviderCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "list-records":
+			os.Exit(runListRecordsCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "reset-state":
+			os.Exit(runResetStateCommand(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "failover":
+			os.Exit(runFailoverCommand(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	// Define command line flags
+	var configOverlays stringListFlag
+	flag.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times (later overlays take precedence)")
+
 	var (
-		configFile  = flag.String("config", "", "Path to configuration file")
-		healthCheck = flag.Bool("health-check", false, "Perform health check and exit")
-		version     = flag.Bool("version", false, "Show version information")
-		help        = flag.Bool("help", false, "Show help information")
+		configFile   = flag.String("config", "", "Path to configuration file")
+		healthCheck  = flag.Bool("health-check", false, "Perform health check and exit")
+		exportState  = flag.Bool("export-state", false, "Print the current state as JSON to stdout and exit")
+		importState  = flag.String("import-state", "", "Import state from the given JSON file and exit")
+		dryRun       = flag.Bool("dry-run", false, "Log DNS updates without applying them or writing state")
+		once         = flag.Bool("once", false, "Run a single check-and-update cycle and exit (exit code 1: IP check failed, 2: DNS update failed)")
+		forceUpdate  = flag.Bool("force-update", false, "Push DNS records on the first check even if state says the target IP is already applied")
+		initProvider = flag.String("init-provider", "", "Print a configuration snippet for the given DNS provider and exit")
+		interactive  = flag.Bool("interactive", false, "With -init-provider, prompt for values and append the record to the -config file")
+		configSchema = flag.Bool("config-schema", false, "Print the JSON Schema of the configuration file and exit")
+		diffConfig   = flag.String("diff-config", "", "Print the differences between the running configuration and the given file and exit")
+		daemonURL    = flag.String("daemon-url", "", "With -diff-config, base URL of the running daemon's metrics server, e.g. http://host:8080 (default: compare with -config)")
+		version      = flag.Bool("version", false, "Show version information")
+		help         = flag.Bool("help", false, "Show help information")
 	)
 
 	flag.Parse()
@@ -516,12 +2444,45 @@ func main() {
 	// Handle help flag
 	if *help {
 		fmt.Printf("IP Failover - Automatic DNS failover service\n\n")
-		fmt.Printf("Usage: %s [options]\n\n", os.Args[0])
+		fmt.Printf("Usage: %s [options]\n", os.Args[0])
+		fmt.Printf("       %s status -config /path/to/config.yaml [-json]\n", os.Args[0])
+		fmt.Printf("       %s validate -config /path/to/config.yaml\n", os.Args[0])
+		fmt.Printf("       %s check-ip -config /path/to/config.yaml [-endpoint URL] [-verbose]\n", os.Args[0])
+		fmt.Printf("       %s test-provider -config /path/to/config.yaml -provider-name NAME [-record NAME] [-write]\n", os.Args[0])
+		fmt.Printf("       %s list-records -config /path/to/config.yaml -provider NAME [-record NAME] [-json]\n", os.Args[0])
+		fmt.Printf("       %s reset-state -config /path/to/config.yaml [-yes] [-reason TEXT]\n", os.Args[0])
+		fmt.Printf("       %s failover -config /path/to/config.yaml (-to primary|secondary | -clear)\n\n", os.Args[0])
+		fmt.Printf("Commands:\n")
+		fmt.Printf("  status\tPrint the recorded failover state and exit, see %s status -help\n", os.Args[0])
+		fmt.Printf("  validate\tValidate the configuration, the DNS providers and IP detection and exit, see %s validate -help\n", os.Args[0])
+		fmt.Printf("  check-ip\tPrint the current public IP address and exit, see %s check-ip -help\n", os.Args[0])
+		fmt.Printf("  test-provider\tSmoke-test a DNS provider's credentials and API access and exit, see %s test-provider -help\n", os.Args[0])
+		fmt.Printf("  list-records\tList all records of the zone managed by a DNS provider and exit, see %s list-records -help\n", os.Args[0])
+		fmt.Printf("  reset-state\tDelete the recorded failover state and exit, see %s reset-state -help\n", os.Args[0])
+		fmt.Printf("  failover\tForce the failover target of the running daemon, or clear it, and exit, see %s failover -help\n\n", os.Args[0])
 		fmt.Printf("Options:\n")
 		flag.PrintDefaults()
 		fmt.Printf("\nExamples:\n")
 		fmt.Printf("  %s -config /path/to/config.yaml\n", os.Args[0])
 		fmt.Printf("  %s -health-check\n", os.Args[0])
+		fmt.Printf("  %s -config /path/to/config.yaml -dry-run\n", os.Args[0])
+		fmt.Printf("  %s -config base.yaml -config-overlay production.yaml -config-overlay local.yaml\n", os.Args[0])
+		fmt.Printf("  %s -config /path/to/config.yaml -once\n", os.Args[0])
+		fmt.Printf("  %s -config /path/to/config.yaml -once -force-update\n", os.Args[0])
+		fmt.Printf("  %s status -config /path/to/config.yaml\n", os.Args[0])
+		fmt.Printf("  %s validate -config /path/to/config.yaml\n", os.Args[0])
+		fmt.Printf("  %s check-ip -endpoint https://ifconfig.io/ip -verbose\n", os.Args[0])
+		fmt.Printf("  %s test-provider -config /path/to/config.yaml -provider-name cloudflare -write\n", os.Args[0])
+		fmt.Printf("  %s list-records -config /path/to/config.yaml -provider route53\n", os.Args[0])
+		fmt.Printf("  %s reset-state -config /path/to/config.yaml -yes -reason \"state file corrupted\"\n", os.Args[0])
+		fmt.Printf("  %s failover -config /path/to/config.yaml -to secondary\n", os.Args[0])
+		fmt.Printf("  %s -config /path/to/config.yaml -export-state > state-backup.json\n", os.Args[0])
+		fmt.Printf("  %s -config /path/to/config.yaml -import-state state-backup.json\n", os.Args[0])
+		fmt.Printf("  %s -init-provider cloudflare >> config.yaml\n", os.Args[0])
+		fmt.Printf("  %s -init-provider hetzner -interactive -config /path/to/config.yaml\n", os.Args[0])
+		fmt.Printf("  %s -config-schema > ipfailover.schema.json\n", os.Args[0])
+		fmt.Printf("  %s -diff-config new.yaml -daemon-url http://host:8080\n", os.Args[0])
+		fmt.Printf("  %s -diff-config new.yaml -config /path/to/config.yaml\n", os.Args[0])
 		fmt.Printf("  %s -version\n", os.Args[0])
 		os.Exit(0)
 	}
@@ -532,6 +2493,33 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle configuration schema export
+	if *configSchema {
+		if _, err := os.Stdout.Write(config.Schema()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write configuration schema: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Handle provider snippet generation
+	if *initProvider != "" {
+		if err := runInitProvider(*initProvider, *interactive, *configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Provider initialization failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Handle configuration diff
+	if *diffConfig != "" {
+		if err := runDiffConfig(*diffConfig, *daemonURL, *configFile, configOverlays, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration diff failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Handle health check flag
 	if *healthCheck {
 		if *configFile == "" {
@@ -540,11 +2528,12 @@ func main() {
 		}
 
 		// Load minimal configuration for health check
-		cfg, err := config.LoadConfig(*configFile)
+		cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 			os.Exit(1)
 		}
+		warnMissingOverlays(missing)
 
 		// Setup minimal logging for health check
 		logger, err := setupLogging(cfg.LogLevel)
@@ -570,6 +2559,39 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle state export/import flags
+	if *exportState || *importState != "" {
+		if *configFile == "" {
+			fmt.Fprintf(os.Stderr, "Error: -config flag is required for state export/import\n")
+			os.Exit(1)
+		}
+
+		cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			os.Exit(1)
+		}
+		warnMissingOverlays(missing)
+
+		store := state.NewFileStateStore(cfg.StateFile, zap.NewNop())
+
+		if *exportState {
+			if err := runExportState(store); err != nil {
+				fmt.Fprintf(os.Stderr, "State export failed: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+
+		if err := runImportState(store, *importState); err != nil {
+			fmt.Fprintf(os.Stderr, "State import failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("State imported into %s\n", cfg.StateFile)
+		os.Exit(0)
+	}
+
 	// Validate required config file
 	if *configFile == "" {
 		fmt.Fprintf(os.Stderr, "Error: -config flag is required\n")
@@ -578,7 +2600,7 @@ func main() {
 	}
 
 	// Load configuration
-	cfg, err := config.LoadConfig(*configFile)
+	cfg, missingOverlays, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 		os.Exit(1)
@@ -596,9 +2618,20 @@ func main() {
 		}
 	}()
 
+	for _, overlay := range missingOverlays {
+		logger.Warn("configuration overlay does not exist, skipping", zap.String("overlay", overlay))
+	}
+	for _, warning := range cfg.Warnings() {
+		logger.Warn(warning)
+	}
+
 	logger.Info("IP failover daemon starting",
 		zap.String("config", *configFile),
+		zap.Strings("config_overlays", configOverlays),
 		zap.String("log_level", cfg.LogLevel),
+		zap.Bool("dry_run", *dryRun),
+		zap.Bool("once", *once),
+		zap.Bool("force_update", *forceUpdate),
 	)
 
 	// Create application
@@ -607,29 +2640,103 @@ func main() {
 		logger.Fatal("Failed to create application", zap.Error(err))
 	}
 
+	app.Once = *once
+	app.ConfigPath = *configFile
+	app.ConfigOverlays = configOverlays
+
+	if *forceUpdate {
+		app.ForceUpdate = true
+		logger.Warn("FORCED UPDATE ACTIVE: DNS records will be pushed on the first check regardless of state")
+	}
+
 	// Setup signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// In dry-run mode, keep state changes in memory so the state file is never written
+	if *dryRun {
+		app.DryRun = true
+		app.stateStore = state.NewDryRunStateStore(ctx, app.stateStore, logger)
+		logger.Warn("dry-run mode enabled, DNS records and state will not be modified")
+	}
+
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}, forcedTargetSignals...)...)
 
 	go func() {
-		sig := <-sigChan
-		logger.Info("Received signal, shutting down",
-			zap.String("signal", sig.String()),
-		)
-		cancel()
+		for sig := range sigChan {
+			if sig == syscall.SIGHUP {
+				logger.Info("Received SIGHUP, reloading configuration",
+					zap.String("config", *configFile),
+				)
+				if _, _, err := app.ReloadConfig(ctx, *configFile, configOverlays); err != nil {
+					logger.Error("Configuration reload failed, continuing with previous configuration",
+						zap.Error(err),
+					)
+				}
+				continue
+			}
+			if app.handleForcedTargetSignal(ctx, sig) {
+				continue
+			}
+
+			logger.Info("Received signal, shutting down",
+				zap.String("signal", sig.String()),
+			)
+			cancel()
+			return
+		}
 	}()
 
 	// Run application
-	if err := app.Run(ctx); err != nil && err != context.Canceled {
+	err = app.Run(ctx)
+
+	if app.Once {
+		code := onceExitCode(err)
+		if err != nil {
+			logger.Error("Single run failed", zap.Error(err), zap.Int("exit_code", code))
+		} else {
+			logger.Info("Single run completed")
+		}
+		cancel()
+		_ = logger.Sync()
+		os.Exit(code)
+	}
+
+	if err != nil && err != context.Canceled {
 		logger.Fatal("Application error", zap.Error(err))
 	}
 
 	logger.Info("Application shutdown complete")
 }
 
+// runExportState writes the persisted state to stdout as pretty-printed JSON
+func runExportState(store *state.FileStateStore) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	data, err := store.ExportState(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// runImportState reads a JSON state file and writes it to the configured state path
+func runImportState(store *state.FileStateStore, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return store.ImportState(ctx, data)
+}
+
 // setupLogging configures logging based on the log level
 func setupLogging(level string) (*zap.Logger, error) {
 	config := zap.NewProductionConfig()
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/internal/fleet"
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/metrics"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/internal/state"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// recordingProvider is a DNS provider keeping records in memory
+type recordingProvider struct {
+	mu        sync.Mutex
+	records   map[string]interfaces.DNSRecord
+	updates   int
+	updateErr error // Returned by UpdateRecord if set
+	getErr    error // Returned by GetRecord if set
+}
+
+func (p *recordingProvider) Name() string { return "recording" }
+
+func (p *recordingProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.updateErr != nil {
+		return p.updateErr
+	}
+	p.records[record.Name+"/"+record.Type] = record
+	p.updates++
+	return nil
+}
+
+func (p *recordingProvider) GetRecord(ctx context.Context, name, rtype string) (*interfaces.DNSRecord, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.getErr != nil {
+		return nil, p.getErr
+	}
+	record, ok := p.records[name+"/"+rtype]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (p *recordingProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	return nil
+}
+
+func (p *recordingProvider) Validate(ctx context.Context) error { return nil }
+
+// value returns the value of the record and how many updates the provider received
+func (p *recordingProvider) value(name, rtype string) (string, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.records[name+"/"+rtype].Value, p.updates
+}
+
+// newTestApplication creates an application publishing home.example.com through provider, failing
+// over from 203.0.113.10 to 198.51.100.20 after two failed checks
+func newTestApplication(ipChecker interfaces.IPChecker, healthChecker interfaces.HealthChecker, provider interfaces.DNSProvider) *Application {
+	dnsConfig := config.DNSConfig{Name: "home.example.com", Type: "A", Provider: "cloudflare", TTL: 300}
+	logger := zap.NewNop()
+	return &Application{
+		config: &config.Config{
+			PollInterval:         time.Hour,
+			MetricsAddr:          "127.0.0.1:0",
+			PrimaryIP:            "203.0.113.10",
+			SecondaryIP:          "198.51.100.20",
+			FailoverRetries:      2,
+			FailbackRetries:      1,
+			DNS:                  []config.DNSConfig{dnsConfig},
+			StateFailureStrategy: "continue_with_warning",
+		},
+		logger:                 logger,
+		rng:                    fleet.NewRand(0),
+		ipChecker:              ipChecker,
+		healthChecker:          healthChecker,
+		dnsProviders:           map[string]interfaces.DNSProvider{dnsConfig.Key(): provider},
+		circuitBreakers:        make(map[string]*dns.CircuitBreaker),
+		stateStore:             state.NewMockStateStore(),
+		metrics:                metrics.NewPrometheusCollector(logger),
+		notifications:          notification.NewFanOutNotifier(),
+		pollIntervalCh:         make(chan time.Duration, 1),
+		reachabilityIntervalCh: make(chan time.Duration, 1),
+		failoverGroups:         make(map[string]*failoverGroup),
+	}
+}
+
+// serverRecordingMetrics is a metrics collector that records whether its server was started
+type serverRecordingMetrics struct {
+	interfaces.MetricsCollector
+	started atomic.Bool
+}
+
+func (m *serverRecordingMetrics) StartMetricsServer(ctx context.Context, addr string) error {
+	m.started.Store(true)
+	return nil
+}
+
+func TestApplication_RunOnceExitCodes(t *testing.T) {
+	run := func(ipErr, updateErr error) (int, *recordingProvider) {
+		provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord), updateErr: updateErr}
+		app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", ipErr), healthcheck.NewMockChecker(), provider)
+		metrics := &serverRecordingMetrics{MetricsCollector: app.metrics}
+		app.metrics = metrics
+		app.Once = true
+		code := onceExitCode(app.Run(context.Background()))
+		assert.False(t, metrics.started.Load(), "metrics server started in single run mode")
+		return code, provider
+	}
+
+	code, provider := run(nil, nil)
+	assert.Equal(t, exitOK, code)
+	value, updates := provider.value("home.example.com", "A")
+	assert.Equal(t, "203.0.113.10", value)
+	assert.Equal(t, 1, updates)
+
+	code, provider = run(errors.New("IP check endpoint unavailable"), nil)
+	assert.Equal(t, exitIPCheckFailed, code)
+	_, updates = provider.value("home.example.com", "A")
+	assert.Zero(t, updates)
+
+	code, _ = run(nil, errors.New("authentication failed"))
+	assert.Equal(t, exitDNSUpdateFailed, code)
+}
+
+func TestApplication_RunOnceCountsFailuresAcrossPolls(t *testing.T) {
+	healthChecker := healthcheck.NewMockChecker()
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthChecker, provider)
+	ctx := context.Background()
+	value := func() string {
+		v, _ := provider.value("home.example.com", "A")
+		return v
+	}
+
+	assert.NoError(t, app.RunOnce(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+
+	// Each poll counts one failure, failing over once failover_retries is reached
+	healthChecker.SetError("203.0.113.10", errors.New("connection refused"))
+	assert.NoError(t, app.RunOnce(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+	assert.NoError(t, app.RunOnce(ctx))
+	assert.Equal(t, "198.51.100.20", value())
+
+	healthChecker.SetError("203.0.113.10", nil)
+	assert.NoError(t, app.RunOnce(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/prober"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// blockingChecker is a health checker whose checks block until their context is done
+type blockingChecker struct {
+	started chan struct{}
+}
+
+func (c *blockingChecker) Check(ctx context.Context, ip string) error {
+	select {
+	case c.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestApplication_CheckReachabilityFailsOverBetweenPolls(t *testing.T) {
+	ipChecker := ipchecker.NewMockChecker("203.0.113.10", nil)
+	healthChecker := healthcheck.NewMockChecker()
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipChecker, healthChecker, provider)
+	app.config.ReachabilityInterval = 5 * time.Second
+	ctx := context.Background()
+	value := func() string {
+		v, _ := provider.value("home.example.com", "A")
+		return v
+	}
+
+	// Before the first poll nothing is applied, which is left to the poll
+	require.NoError(t, app.checkReachability(ctx))
+	_, updates := provider.value("home.example.com", "A")
+	assert.Zero(t, updates)
+
+	require.NoError(t, app.checkAndUpdateIP(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+
+	// The public IP is not checked between polls, so a failing IP check does not hold up the failover
+	ipChecker.SetError(errors.New("IP check endpoint unavailable"))
+	healthChecker.SetError("203.0.113.10", errors.New("connection refused"))
+
+	require.NoError(t, app.checkReachability(ctx))
+	assert.Equal(t, "203.0.113.10", value(), "failed over before failover_retries was reached")
+	require.NoError(t, app.checkReachability(ctx))
+	assert.Equal(t, "198.51.100.20", value())
+
+	// Unchanged targets are not pushed again
+	_, updates = provider.value("home.example.com", "A")
+	require.NoError(t, app.checkReachability(ctx))
+	_, after := provider.value("home.example.com", "A")
+	assert.Equal(t, updates, after)
+
+	healthChecker.SetError("203.0.113.10", nil)
+	require.NoError(t, app.checkReachability(ctx))
+	assert.Equal(t, "203.0.113.10", value())
+
+	// Polls and reachability checks share the failover state, run with -race
+	ipChecker.SetError(nil)
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, app.checkAndUpdateIP(ctx))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, app.checkReachability(ctx))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, "203.0.113.10", value())
+}
+
+func TestApplication_RunStopsDuringReachabilityCheck(t *testing.T) {
+	healthChecker := &blockingChecker{started: make(chan struct{}, 1)}
+	provider := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthChecker, provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx) }()
+
+	select {
+	case <-healthChecker.started:
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("the initial reachability check did not start")
+	}
+
+	// The check would otherwise wait for the reachability timeout of at least 5 seconds
+	start := time.Now()
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return promptly after its context was cancelled")
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The interrupted check is not counted as a failure of the primary
+	failures, err := app.stateStore.GetPrimaryFailureCount(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, failures)
+	_, updates := provider.value("home.example.com", "A")
+	assert.Zero(t, updates)
+}
+
+// manualClock is a prober.Clock whose time only moves when the test says so
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) NewTicker(d time.Duration) prober.Ticker { return manualTicker{} }
+
+func (c *manualClock) forward(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type manualTicker struct{}
+
+func (manualTicker) C() <-chan time.Time { return nil }
+
+func (manualTicker) Stop() {}
+
+func TestApplication_ProbeReachabilityIgnoresStaleResults(t *testing.T) {
+	healthChecker := healthcheck.NewMockChecker()
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthChecker,
+		&recordingProvider{records: make(map[string]interfaces.DNSRecord)})
+
+	errProbe := errors.New("probe: connection refused")
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	app.prober = prober.NewProberWithClock([]string{"203.0.113.10"}, 5*time.Second,
+		func(ctx context.Context, ip string) error { return errProbe }, clock, zap.NewNop())
+	app.prober.ProbeOnce(context.Background())
+
+	// A recent probe result is used instead of checking the IP
+	assert.ErrorIs(t, app.probeReachability(context.Background(), "203.0.113.10"), errProbe)
+	assert.Equal(t, 0, healthChecker.GetChecks("203.0.113.10"))
+
+	// Once the prober has not probed for more than two intervals, the IP is checked directly
+	clock.forward(11 * time.Second)
+	assert.NoError(t, app.probeReachability(context.Background(), "203.0.113.10"))
+	assert.Equal(t, 1, healthChecker.GetChecks("203.0.113.10"))
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/fleet"
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/internal/prober"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// getConfig returns the current configuration snapshot
+func (app *Application) getConfig() *config.Config {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+	return app.config
+}
+
+// getDNSProviders returns the current DNS providers snapshot. The returned map must not be modified.
+func (app *Application) getDNSProviders() map[string]interfaces.DNSProvider {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+	return app.dnsProviders
+}
+
+// getIPChecker returns the current IP checker
+func (app *Application) getIPChecker() interfaces.IPChecker {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+	return app.ipChecker
+}
+
+// getIPv6Checker returns the current IPv6 checker, nil unless in dual-stack mode
+func (app *Application) getIPv6Checker() interfaces.IPChecker {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+	return app.ipv6Checker
+}
+
+// getHealthChecker returns the current reachability checker
+func (app *Application) getHealthChecker() interfaces.HealthChecker {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+	return app.healthChecker
+}
+
+// getNotifications returns the current notification channels
+func (app *Application) getNotifications() *notification.FanOutNotifier {
+	app.configMu.RLock()
+	defer app.configMu.RUnlock()
+	return app.notifications
+}
+
+// newNotifications creates the notification channels enabled in the configuration
+func (app *Application) newNotifications(cfg *config.Config) *notification.FanOutNotifier {
+	var providers []interfaces.NotificationProvider
+	if cfg.Notifications.Email != nil {
+		providers = append(providers, notification.NewEmailNotifier(cfg.Notifications.Email, app.logger))
+	}
+	if cfg.Notifications.PagerDuty != nil {
+		providers = append(providers, notification.NewPagerDutyNotifier(cfg.Notifications.PagerDuty, app.logger))
+	}
+	for i := range cfg.Notifications.Webhooks {
+		providers = append(providers, notification.NewWebhookNotifier(&cfg.Notifications.Webhooks[i], app.logger))
+	}
+	return notification.NewFanOutNotifier(providers...)
+}
+
+// newIPChecker creates the IP checker for the configuration, shuffling the endpoint order
+// if fleet randomization is enabled. In dual-stack mode it detects the public IPv4 address.
+// configMu must be held for writing, or not yet shared.
+func (app *Application) newIPChecker(cfg *config.Config) interfaces.IPChecker {
+	return app.newHTTPChecker(cfg, cfg.CheckEndpoints, false)
+}
+
+// newIPv6Checker creates the checker of the public IPv6 address in dual-stack mode, or returns
+// nil otherwise, see newIPChecker
+func (app *Application) newIPv6Checker(cfg *config.Config) interfaces.IPChecker {
+	if !cfg.DualStack() {
+		return nil
+	}
+	return app.newHTTPChecker(cfg, cfg.IPv6CheckEndpoints(), true)
+}
+
+// newHTTPChecker creates an IP checker querying endpoints. In dual-stack mode it connects over
+// IPv6 if ipv6 is set and over IPv4 otherwise, from the source of that family.
+func (app *Application) newHTTPChecker(cfg *config.Config, endpoints []string, ipv6 bool) *ipchecker.HTTPChecker {
+	if cfg.FleetRandomization {
+		endpoints = fleet.ShuffleEndpoints(endpoints, app.rng)
+		app.logger.Debug("shuffled check endpoints", zap.Strings("endpoints", endpoints), zap.Bool("ipv6", ipv6))
+	}
+	checker := ipchecker.NewHTTPChecker(endpoints, app.logger)
+	if proxy := cfg.ProxyFunc(); proxy != nil {
+		checker.SetProxy(proxy)
+	}
+	source := cfg.Source()
+	if cfg.DualStack() {
+		source = source.ForFamily(ipv6)
+		network := "tcp4"
+		if ipv6 {
+			network = "tcp6"
+		}
+		checker.SetNetwork(network)
+	}
+	if !source.IsZero() {
+		checker.SetSource(source)
+	}
+	return checker
+}
+
+// pollPhaseDelay returns how long to wait for the instance's next poll phase tick,
+// or false if fleet randomization is disabled
+func (app *Application) pollPhaseDelay(cfg *config.Config, interval time.Duration) (time.Duration, bool) {
+	if !cfg.FleetRandomization {
+		return 0, false
+	}
+
+	now := time.Now()
+	offset := fleet.PhaseOffset(cfg.InstanceName, interval)
+	return fleet.NextPhaseTick(now, interval, offset).Sub(now), true
+}
+
+// newHealthChecker creates the reachability checker of the configuration, connecting from its source
+func (app *Application) newHealthChecker(cfg *config.Config) interfaces.HealthChecker {
+	opts := healthcheck.Options{
+		Protocol: cfg.Reachability.Protocol,
+		Ports:    cfg.Reachability.Ports,
+		Policy:   cfg.Reachability.Policy,
+		Timeout:  cfg.Reachability.Timeout,
+		Attempts: cfg.Reachability.Attempts,
+		Source:   cfg.Source(),
+		Logger:   app.logger,
+	}
+	if check := cfg.Reachability.ICMP; check != nil {
+		opts.ICMP = healthcheck.ICMPOptions{
+			Count:         check.Count,
+			Interval:      check.Interval,
+			LossThreshold: check.LossThreshold,
+		}
+	}
+	if check := cfg.Reachability.HTTP; check != nil {
+		opts.HTTP = healthcheck.HTTPOptions{
+			URL:                check.URL,
+			ExpectedStatus:     check.ExpectedStatus,
+			BodyContains:       check.BodyContains,
+			InsecureSkipVerify: check.InsecureSkipVerify,
+		}
+	}
+	return healthcheck.NewChecker(opts)
+}
+
+// newProber creates a background reachability prober for the configuration, or nil if probing is disabled.
+// configMu must be held, unless the application is not running yet.
+func (app *Application) newProber(cfg *config.Config) *prober.Prober {
+	if cfg.ProbeInterval <= 0 {
+		return nil
+	}
+
+	p := prober.NewProber(
+		probeTargets(cfg),
+		cfg.ProbeInterval,
+		app.checkIPReachability,
+		app.logger,
+	)
+	// A probe is bounded like the check of a poll
+	p.SetTimeout(checkTimeout(cfg, app.healthChecker))
+	return p
+}
+
+// probeTargets returns the IPs probed in the background: the primary, secondary and fallback IPs, and
+// in dual-stack mode the primary and secondary IPv6 address
+func probeTargets(cfg *config.Config) []string {
+	targets := append([]string{cfg.PrimaryIP}, cfg.FallbackTargets()...)
+	if cfg.DualStack() {
+		targets = append(targets, cfg.PrimaryIPv6, cfg.SecondaryIPv6)
+	}
+	return targets
+}
+
+// startProber starts the background prober, if enabled, and remembers the run context for reloads
+func (app *Application) startProber(ctx context.Context) {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	app.runCtx = ctx
+	app.startProberLocked()
+}
+
+// startProberLocked starts the current prober. configMu must be held for writing.
+func (app *Application) startProberLocked() {
+	if app.prober == nil || app.runCtx == nil {
+		return
+	}
+
+	proberCtx, cancel := context.WithCancel(app.runCtx)
+	app.proberCancel = cancel
+	go app.prober.Run(proberCtx)
+}
+
+// Reload reloads the configuration from ConfigPath and ConfigOverlays, as requested through the API
+func (app *Application) Reload(ctx context.Context) (*api.ReloadResult, error) {
+	// A client that disconnects must not abort the reload halfway
+	ctx = context.WithoutCancel(ctx)
+
+	newCfg, changes, err := app.ReloadConfig(ctx, app.ConfigPath, app.ConfigOverlays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ReloadResult{
+		Config:  newCfg.Redact(),
+		Changes: changes,
+	}, nil
+}
+
+// ReloadConfig reloads the configuration file and its overlays and applies the changes.
+// DNS providers whose configuration is unchanged keep their existing clients.
+// It returns the new configuration and how it differs from the previous one.
+// If the new configuration is invalid the current configuration is kept and an error is returned.
+// The changes are applied between check cycles, so a cycle in progress keeps its providers open.
+func (app *Application) ReloadConfig(ctx context.Context, configPath string, overlays []string) (*config.Config, []config.Change, error) {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+
+	newCfg, missing, err := config.LoadConfigWithOverlays(configPath, overlays)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, overlay := range missing {
+		app.logger.Warn("configuration overlay does not exist, skipping", zap.String("overlay", overlay))
+	}
+	for _, warning := range newCfg.Warnings() {
+		app.logger.Warn(warning)
+	}
+	if err := newCfg.Source().Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	oldCfg := app.getConfig()
+	oldProviders := app.getDNSProviders()
+
+	oldDNS := make(map[string]config.DNSConfig, len(oldCfg.DNS))
+	for _, dnsConfig := range oldCfg.DNS {
+		oldDNS[dnsConfig.Key()] = dnsConfig
+	}
+
+	proxyChanged := oldCfg.ProxyURL != newCfg.ProxyURL || !reflect.DeepEqual(oldCfg.NoProxy, newCfg.NoProxy)
+	sourceChanged := oldCfg.Source() != newCfg.Source()
+
+	// Build the new provider set, reusing providers whose configuration, including the proxy, is unchanged
+	providers := make(map[string]interfaces.DNSProvider, len(newCfg.DNS))
+	var rebuilt []string
+	for _, dnsConfig := range newCfg.DNS {
+		key := dnsConfig.Key()
+		if old, ok := oldDNS[key]; ok && !proxyChanged && reflect.DeepEqual(old, dnsConfig) {
+			if provider, exists := oldProviders[key]; exists {
+				providers[key] = provider
+				continue
+			}
+		}
+
+		provider, err := app.newDNSProvider(newCfg, dnsConfig)
+		if err != nil {
+			app.closeRebuiltProviders(providers, rebuilt)
+			return nil, nil, fmt.Errorf("failed to create DNS provider for %s: %w", key, err)
+		}
+		providers[key] = provider
+		rebuilt = append(rebuilt, key)
+
+		if err := provider.Validate(ctx); err != nil {
+			app.closeRebuiltProviders(providers, rebuilt)
+			return nil, nil, fmt.Errorf("DNS provider %s validation failed: %w", key, err)
+		}
+	}
+
+	app.warnRestartRequired(oldCfg, newCfg)
+	changes := config.Diff(oldCfg.Redact(), newCfg.Redact())
+
+	// Replaced providers are closed, so wait for a check cycle that may still use them
+	app.checkMu.Lock()
+	defer app.checkMu.Unlock()
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	app.config = newCfg
+	app.dnsProviders = providers
+
+	// Close replaced providers, such as plugins that are no longer used
+	replaced := make(map[string]interfaces.DNSProvider)
+	for name, provider := range oldProviders {
+		if providers[name] != provider {
+			replaced[name] = provider
+		}
+	}
+	app.closeDNSProviders(replaced)
+
+	if !reflect.DeepEqual(oldCfg.CheckEndpoints, newCfg.CheckEndpoints) ||
+		!reflect.DeepEqual(oldCfg.IPv6CheckEndpoints(), newCfg.IPv6CheckEndpoints()) ||
+		oldCfg.DualStack() != newCfg.DualStack() ||
+		oldCfg.FleetRandomization != newCfg.FleetRandomization || proxyChanged || sourceChanged {
+		app.ipChecker = app.newIPChecker(newCfg)
+		app.ipv6Checker = app.newIPv6Checker(newCfg)
+	}
+
+	if !reflect.DeepEqual(oldCfg.Reachability, newCfg.Reachability) || sourceChanged {
+		app.healthChecker = app.newHealthChecker(newCfg)
+	}
+
+	if !reflect.DeepEqual(oldCfg.Notifications, newCfg.Notifications) {
+		app.notifications = app.newNotifications(newCfg)
+	}
+
+	if oldCfg.ProbeInterval != newCfg.ProbeInterval ||
+		!reflect.DeepEqual(probeTargets(oldCfg), probeTargets(newCfg)) ||
+		!reflect.DeepEqual(oldCfg.Reachability, newCfg.Reachability) {
+		if app.proberCancel != nil {
+			app.proberCancel()
+			app.proberCancel = nil
+		}
+		app.prober = app.newProber(newCfg)
+		app.startProberLocked()
+	}
+
+	if oldCfg.PollInterval != newCfg.PollInterval {
+		// Replace any pending, not yet applied interval change
+		select {
+		case <-app.pollIntervalCh:
+		default:
+		}
+		app.pollIntervalCh <- newCfg.PollInterval
+	}
+
+	if oldCfg.ReachabilityInterval != newCfg.ReachabilityInterval {
+		select {
+		case <-app.reachabilityIntervalCh:
+		default:
+		}
+		app.reachabilityIntervalCh <- newCfg.ReachabilityInterval
+	}
+
+	app.logger.Info("configuration reloaded",
+		zap.String("config", configPath),
+		zap.Strings("rebuilt_providers", rebuilt),
+		zap.Int("dns_records", len(newCfg.DNS)),
+		zap.Int("changed_keys", len(changes)),
+	)
+
+	return newCfg, changes, nil
+}
+
+// closeRebuiltProviders closes the newly created providers of a reload that failed
+func (app *Application) closeRebuiltProviders(providers map[string]interfaces.DNSProvider, rebuilt []string) {
+	created := make(map[string]interfaces.DNSProvider, len(rebuilt))
+	for _, name := range rebuilt {
+		created[name] = providers[name]
+	}
+	app.closeDNSProviders(created)
+}
+
+// warnRestartRequired logs settings that changed but only take effect after a restart
+func (app *Application) warnRestartRequired(oldCfg, newCfg *config.Config) {
+	if oldCfg.MetricsAddr != newCfg.MetricsAddr {
+		app.logger.Warn("metrics_addr changed, restart required for it to take effect",
+			zap.String("current", oldCfg.MetricsAddr),
+			zap.String("configured", newCfg.MetricsAddr),
+		)
+	}
+
+	if oldCfg.MetricsTLSCertFile != newCfg.MetricsTLSCertFile ||
+		oldCfg.MetricsTLSKeyFile != newCfg.MetricsTLSKeyFile ||
+		oldCfg.MetricsBasicAuthUser != newCfg.MetricsBasicAuthUser ||
+		oldCfg.MetricsBasicAuthPassword != newCfg.MetricsBasicAuthPassword ||
+		!slices.Equal(oldCfg.MetricsAllowedCIDRs, newCfg.MetricsAllowedCIDRs) {
+		app.logger.Warn("metrics server TLS, basic auth or allowed CIDR settings changed, restart required for them to take effect")
+	}
+
+	if oldCfg.APIAddr != newCfg.APIAddr || oldCfg.APIToken != newCfg.APIToken {
+		app.logger.Warn("api_addr or api_token changed, restart required for them to take effect",
+			zap.String("current_addr", oldCfg.APIAddr),
+			zap.String("configured_addr", newCfg.APIAddr),
+		)
+	}
+
+	if oldCfg.OTelExporterEndpoint != newCfg.OTelExporterEndpoint {
+		app.logger.Warn("otel_exporter_endpoint changed, restart required for it to take effect",
+			zap.String("current", oldCfg.OTelExporterEndpoint),
+			zap.String("configured", newCfg.OTelExporterEndpoint),
+		)
+	}
+
+	if oldEmail, newEmail := oldCfg.Notifications.Email, newCfg.Notifications.Email; digestSettings(oldEmail) != digestSettings(newEmail) {
+		app.logger.Warn("daily digest settings changed, restart required for them to take effect",
+			zap.String("current", digestSettings(oldEmail)),
+			zap.String("configured", digestSettings(newEmail)),
+		)
+	}
+
+	if oldCfg.AuditLogFile != newCfg.AuditLogFile ||
+		oldCfg.AuditLogMaxSizeMB != newCfg.AuditLogMaxSizeMB ||
+		oldCfg.AuditLogMaxBackups != newCfg.AuditLogMaxBackups {
+		app.logger.Warn("audit log settings changed, restart required for them to take effect",
+			zap.String("current_file", oldCfg.AuditLogFile),
+			zap.String("configured_file", newCfg.AuditLogFile),
+		)
+	}
+
+	if oldCfg.CircuitBreakerThreshold != newCfg.CircuitBreakerThreshold ||
+		oldCfg.CircuitBreakerCooldown != newCfg.CircuitBreakerCooldown {
+		app.logger.Warn("circuit breaker settings changed, restart required for them to take effect",
+			zap.Int("current_threshold", oldCfg.CircuitBreakerThreshold),
+			zap.Int("configured_threshold", newCfg.CircuitBreakerThreshold),
+			zap.Duration("current_cooldown", oldCfg.CircuitBreakerCooldown),
+			zap.Duration("configured_cooldown", newCfg.CircuitBreakerCooldown),
+		)
+	}
+
+	if oldCfg.StateFile != newCfg.StateFile {
+		app.logger.Warn("state_file changed, restart required for it to take effect",
+			zap.String("current", oldCfg.StateFile),
+			zap.String("configured", newCfg.StateFile),
+		)
+	}
+
+	if oldCfg.LogLevel != newCfg.LogLevel {
+		app.logger.Warn("log_level changed, restart required for it to take effect",
+			zap.String("current", oldCfg.LogLevel),
+			zap.String("configured", newCfg.LogLevel),
+		)
+	}
+}
+
+// digestSettings describes the daily digest settings of email, which only take effect on restart
+func digestSettings(email *config.EmailConfig) string {
+	if email == nil || !email.DailyDigest {
+		return "disabled"
+	}
+	if email.DigestTime == "" {
+		return "daily at " + notification.DefaultDigestTime
+	}
+	return "daily at " + email.DigestTime
+}
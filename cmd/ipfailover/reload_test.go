@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closingProvider is a DNS provider whose updates block until released, and that records when
+// it is closed relative to its updates
+type closingProvider struct {
+	recordingProvider
+	started  chan struct{}
+	release  chan struct{}
+	mu       sync.Mutex
+	updating bool
+	closed   bool
+	closedIn bool // Closed while an update was in progress
+}
+
+func (p *closingProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	p.mu.Lock()
+	p.updating = true
+	p.mu.Unlock()
+
+	p.started <- struct{}{}
+	<-p.release
+
+	p.mu.Lock()
+	p.updating = false
+	p.mu.Unlock()
+	return p.recordingProvider.UpdateRecord(ctx, record)
+}
+
+func (p *closingProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.closedIn = p.updating
+	return nil
+}
+
+func (p *closingProvider) isClosed() (closed, closedIn bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed, p.closedIn
+}
+
+const reloadTestConfig = `
+poll_interval: "1h"
+check_endpoints:
+  - "https://api.ipify.org"
+primary_ip: "203.0.113.10"
+secondary_ip: "198.51.100.20"
+state_file: "%s"
+dns:
+  - name: "home.example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "test-token"
+      zone_id: "test-zone"
+`
+
+const reloadTestRemovedRecord = `
+  - name: "vpn.example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "other-token"
+      zone_id: "test-zone"
+`
+
+func TestApplication_ReloadWaitsForCheckCycle(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	content := fmt.Sprintf(reloadTestConfig, filepath.Join(dir, "state.json"))
+	require.NoError(t, os.WriteFile(configPath, []byte(content+reloadTestRemovedRecord), 0o600))
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	kept := &recordingProvider{records: make(map[string]interfaces.DNSRecord)}
+	removed := &closingProvider{
+		recordingProvider: recordingProvider{records: make(map[string]interfaces.DNSRecord)},
+		started:           make(chan struct{}, 1),
+		release:           make(chan struct{}),
+	}
+	app := newTestApplication(ipchecker.NewMockChecker("203.0.113.10", nil), healthcheck.NewMockChecker(), kept)
+	app.config = cfg
+	app.dnsProviders = map[string]interfaces.DNSProvider{
+		cfg.DNS[0].Key(): kept,
+		cfg.DNS[1].Key(): removed,
+	}
+	ctx := context.Background()
+
+	checked := make(chan error, 1)
+	go func() { checked <- app.checkAndUpdateIP(ctx) }()
+	select {
+	case <-removed.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("update of the removed record did not start")
+	}
+
+	// Removing the record replaces its provider while the cycle is still writing to it
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+	reloaded := make(chan error, 1)
+	go func() {
+		_, _, err := app.ReloadConfig(ctx, configPath, nil)
+		reloaded <- err
+	}()
+
+	select {
+	case err := <-reloaded:
+		t.Fatalf("reload finished during a check cycle: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	closed, _ := removed.isClosed()
+	assert.False(t, closed, "provider closed during a check cycle")
+
+	close(removed.release)
+	require.NoError(t, <-checked)
+	select {
+	case err := <-reloaded:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload did not finish after the check cycle")
+	}
+
+	closed, closedIn := removed.isClosed()
+	assert.True(t, closed, "replaced provider not closed")
+	assert.False(t, closedIn, "provider closed while an update was in progress")
+	assert.Len(t, app.getDNSProviders(), 1)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/state"
+)
+
+// runResetStateCommand implements the reset-state subcommand: it deletes the state file of the
+// configuration, and those of records that fail over on their own, after asking for confirmation
+// on stdin unless -yes is given, and returns the exit code
+func runResetStateCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("reset-state", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file, used to locate the state files")
+	yes := flags.Bool("yes", false, "Reset without asking for confirmation")
+	reason := flags.String("reason", "", "Reason for the reset, logged with it")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover reset-state -config /path/to/config.yaml [-yes] [-reason TEXT]\n\n")
+		fmt.Fprintf(stderr, "Delete the recorded failover state, so the next poll starts fresh.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" {
+		fmt.Fprintf(stderr, "Error: -config flag is required for reset-state\n")
+		return 1
+	}
+
+	cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	for _, overlay := range missing {
+		fmt.Fprintf(stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+	}
+
+	stateFiles := configuredStateFiles(cfg)
+	if !*yes {
+		fmt.Fprintf(stderr, "This deletes the failover state in %s.\n", strings.Join(stateFiles, ", "))
+		p := &prompter{in: bufio.NewReader(stdin), out: stderr}
+		answer, err := p.ask("Reset the state? (yes/no)", "no", false)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to read confirmation: %v\n", err)
+			return 1
+		}
+		if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+			fmt.Fprintln(stderr, "State not reset")
+			return 1
+		}
+	}
+
+	logger, err := setupLogging(cfg.LogLevel)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to setup logging: %v\n", err)
+		return 1
+	}
+	defer func() { _ = logger.Sync() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, stateFile := range stateFiles {
+		existed, err := state.NewFileStateStore(stateFile, logger).ResetState(ctx, *reason)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to reset state: %v\n", err)
+			return 1
+		}
+		if existed {
+			fmt.Fprintf(stdout, "Reset state in %s\n", stateFile)
+		} else {
+			fmt.Fprintf(stdout, "No state recorded in %s\n", stateFile)
+		}
+	}
+	return 0
+}
+
+// configuredStateFiles returns the state file of each failover group of the configuration, see
+// readRecordedStates
+func configuredStateFiles(cfg *config.Config) []string {
+	var stateFiles []string
+	for _, group := range cfg.FailoverGroups() {
+		stateFile := cfg.StateFile
+		if name := cfg.GroupStateName(group.DNS[0]); name != "" {
+			stateFile = state.RecordStateFile(cfg.StateFile, name)
+		}
+		stateFiles = append(stateFiles, stateFile)
+	}
+	return stateFiles
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfiguredStateFiles_SameNameRecords(t *testing.T) {
+	cfg := sameNameConfig(t)
+	dir := filepath.Dir(cfg.StateFile)
+
+	assert.Equal(t, []string{
+		cfg.StateFile,
+		filepath.Join(dir, "state.cdn.example.com_A_cloudflare.json"),
+		filepath.Join(dir, "state.cdn.example.com_A_adguard.json"),
+	}, configuredStateFiles(cfg))
+}
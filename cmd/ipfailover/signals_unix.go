@@ -0,0 +1,35 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// forcedTargetSignals change the forced target, see handleForcedTargetSignal
+var forcedTargetSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
+
+// handleForcedTargetSignal changes the forced target on SIGUSR1, which toggles a forced failover to
+// the secondary IPs, and SIGUSR2, which clears it. It reports whether sig was one of them.
+func (app *Application) handleForcedTargetSignal(ctx context.Context, sig os.Signal) bool {
+	var err error
+	switch sig {
+	case syscall.SIGUSR1:
+		err = app.toggleForcedSecondary(ctx, sig.String())
+	case syscall.SIGUSR2:
+		err = app.SetForcedTarget(ctx, "", sig.String())
+	default:
+		return false
+	}
+	if err != nil {
+		app.logger.Error("failed to change forced target",
+			zap.String("signal", sig.String()),
+			zap.Error(err),
+		)
+	}
+	return true
+}
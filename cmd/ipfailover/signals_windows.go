@@ -0,0 +1,16 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os"
+)
+
+// forcedTargetSignals is empty, Windows has no user-defined signals
+var forcedTargetSignals []os.Signal
+
+// handleForcedTargetSignal reports that sig does not change the forced target
+func (app *Application) handleForcedTargetSignal(ctx context.Context, sig os.Signal) bool {
+	return false
+}
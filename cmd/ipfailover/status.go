@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/incident"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Status is the daemon status reported by the /status endpoint
+type Status struct {
+	LastAppliedIP       string                                      `json:"last_applied_ip"`
+	LastChangeTime      time.Time                                   `json:"last_change_time"`
+	LastCheckIP         string                                      `json:"last_check_ip"`
+	LastCheckTime       time.Time                                   `json:"last_check_time"`
+	PrimaryFailureCount int                                         `json:"primary_failure_count"`
+	ForcedTarget        string                                      `json:"forced_target,omitempty"`
+	OpenIncidents       []interfaces.ProviderFailureStreak          `json:"open_incidents"`
+	FailureStreaks      map[string]interfaces.ProviderFailureStreak `json:"failure_streaks,omitempty"`
+}
+
+// updateStats tracks the DNS updates since startup, for the REST API
+type updateStats struct {
+	mu        sync.Mutex
+	count     int64                         // IP changes applied to the DNS records
+	providers map[string]api.ProviderStatus // Outcome of the last update cycle that wrote to the provider
+}
+
+// recordChange counts an IP change applied to the DNS records
+func (s *updateStats) recordChange() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+}
+
+// recordProvider stores the outcome of an update cycle that wrote to provider; err is nil if it succeeded
+func (s *updateStats) recordProvider(provider string, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := api.ProviderStatus{
+		Name:             provider,
+		LastUpdateStatus: api.UpdateStatusOK,
+		LastUpdateTime:   &now,
+	}
+	if err != nil {
+		status.LastUpdateStatus = api.UpdateStatusFailed
+		status.LastError = err.Error()
+	}
+
+	if s.providers == nil {
+		s.providers = make(map[string]api.ProviderStatus)
+	}
+	s.providers[provider] = status
+}
+
+// snapshot returns the update count and the status of each of providers, sorted by name
+func (s *updateStats) snapshot(providers []string) (int64, []api.ProviderStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]api.ProviderStatus, 0, len(providers))
+	for _, provider := range providers {
+		status, ok := s.providers[provider]
+		if !ok {
+			status = api.ProviderStatus{Name: provider, LastUpdateStatus: api.UpdateStatusNone}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return s.count, statuses
+}
+
+// newIncidentTracker creates an incident tracker for the current configuration and state store
+func (app *Application) newIncidentTracker() *incident.Tracker {
+	return incident.NewTracker(app.stateStore, app.notify, app.getConfig().IncidentThreshold, app.logger)
+}
+
+// recordProviderOutcomes updates the provider failure streaks after a DNS update cycle.
+// Providers in failures failed to update at least one record; all other providers in attempted succeeded.
+// Tracking errors are logged and do not fail the cycle.
+func (app *Application) recordProviderOutcomes(ctx context.Context, attempted map[string]bool, failures map[string]error) {
+	tracker := app.newIncidentTracker()
+	now := time.Now()
+
+	for provider := range attempted {
+		app.updates.recordProvider(provider, failures[provider], now)
+
+		var err error
+		if cause, failed := failures[provider]; failed {
+			err = tracker.RecordFailure(ctx, provider, cause)
+		} else {
+			err = tracker.RecordSuccess(ctx, provider)
+		}
+		if err != nil {
+			app.logger.Warn("failed to track provider failure streak",
+				zap.String("provider", provider),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// status collects the daemon status from the state store. Missing state is reported as empty.
+func (app *Application) status(ctx context.Context) (*Status, error) {
+	var status Status
+	var errs error
+
+	ignoreNotFound := func(err error) error {
+		if errors.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	var err error
+	status.LastAppliedIP, err = app.stateStore.GetLastAppliedIP(ctx)
+	errs = multierr.Append(errs, ignoreNotFound(err))
+
+	status.LastChangeTime, err = app.stateStore.GetLastChangeTime(ctx)
+	errs = multierr.Append(errs, ignoreNotFound(err))
+
+	status.LastCheckIP, status.LastCheckTime, err = app.stateStore.GetLastCheckInfo(ctx)
+	errs = multierr.Append(errs, ignoreNotFound(err))
+
+	status.PrimaryFailureCount, err = app.stateStore.GetPrimaryFailureCount(ctx)
+	errs = multierr.Append(errs, ignoreNotFound(err))
+
+	status.ForcedTarget, err = app.stateStore.GetForcedTarget(ctx)
+	errs = multierr.Append(errs, ignoreNotFound(err))
+
+	status.FailureStreaks, err = app.stateStore.GetProviderFailureStreaks(ctx)
+	errs = multierr.Append(errs, ignoreNotFound(err))
+	status.OpenIncidents = incident.OpenIncidents(status.FailureStreaks)
+
+	if errs != nil {
+		return nil, errs
+	}
+	return &status, nil
+}
+
+// statusHandler serves the daemon status as JSON
+func (app *Application) statusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := app.status(r.Context())
+		if err != nil {
+			app.logger.Error("failed to read status", zap.Error(err))
+			http.Error(w, "failed to read status", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(status); err != nil {
+			app.logger.Error("failed to write status response", zap.Error(err))
+		}
+	})
+}
+
+// APIStatus collects the daemon status served by the REST API. Providers are listed by type,
+// like their circuit breakers and incidents, and the update count is since startup.
+func (app *Application) APIStatus(ctx context.Context) (*api.Status, error) {
+	status, err := app.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var providers []string
+	for _, dnsConfig := range app.getConfig().DNS {
+		if !seen[dnsConfig.Provider] {
+			seen[dnsConfig.Provider] = true
+			providers = append(providers, dnsConfig.Provider)
+		}
+	}
+	updateCount, providerStatuses := app.updates.snapshot(providers)
+
+	cfg := app.getConfig()
+	_, primarySuccessCount, err := app.stateStore.GetPrimaryRecovery(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		return nil, err
+	}
+	records, err := app.recordStatuses(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Status{
+		CurrentIP:           status.LastCheckIP,
+		LastAppliedIP:       status.LastAppliedIP,
+		ActiveTarget:        activeTarget(cfg, status.LastAppliedIP),
+		LastChangeTime:      status.LastChangeTime,
+		PrimaryFailureCount: status.PrimaryFailureCount,
+		PrimarySuccessCount: primarySuccessCount,
+		ForcedTarget:        status.ForcedTarget,
+		UpdateCount:         updateCount,
+		Records:             records,
+		Providers:           providerStatuses,
+		UptimeSeconds:       time.Since(app.startTime).Seconds(),
+	}, nil
+}
+
+// recordStatuses returns the IP last applied to each DNS record, from the state of its failover group
+func (app *Application) recordStatuses(ctx context.Context, cfg *config.Config) ([]api.RecordStatus, error) {
+	records := make([]api.RecordStatus, 0, len(cfg.DNS))
+	for _, dnsConfig := range cfg.DNS {
+		lastAppliedIP, err := app.failoverGroup(ctx, dnsConfig).store.GetLastAppliedIP(ctx)
+		if err != nil && !errors.IsNotFoundError(err) {
+			return nil, fmt.Errorf("state of %s: %w", dnsConfig.Name, err)
+		}
+		records = append(records, api.RecordStatus{
+			Name:          dnsConfig.Name,
+			Type:          dnsConfig.Type,
+			Provider:      dnsConfig.Provider,
+			LastAppliedIP: lastAppliedIP,
+			ActiveTarget:  activeTarget(cfg.ForRecord(dnsConfig), lastAppliedIP),
+		})
+	}
+	return records, nil
+}
+
+// activeTarget returns api.TargetPrimary if ip is the primary IP of cfg, api.TargetSecondary if it
+// is the secondary or a fallback IP, or an empty string otherwise
+func activeTarget(cfg *config.Config, ip string) string {
+	switch {
+	case ip == "":
+		return ""
+	case ip == cfg.PrimaryIP:
+		return api.TargetPrimary
+	case isFallbackIP(cfg, ip):
+		return api.TargetSecondary
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/state"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// recordedState is the state of a state file, as shown by the status command
+type recordedState struct {
+	StateFile string       `json:"state_file"`
+	Records   []string     `json:"records"` // DNS records failing over with this state
+	State     *state.State `json:"state"`
+}
+
+// runStatusCommand implements the status subcommand: it prints the state recorded in the state
+// file of the configuration, and in those of records that fail over on their own, and returns the
+// exit code.
+func runStatusCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file, used to locate the state file")
+	asJSON := flags.Bool("json", false, "Print the state as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover status -config /path/to/config.yaml [-json]\n\n")
+		fmt.Fprintf(stderr, "Print the recorded failover state.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" {
+		fmt.Fprintf(stderr, "Error: -config flag is required for status\n")
+		return 1
+	}
+
+	cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	for _, overlay := range missing {
+		fmt.Fprintf(stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	states, err := readRecordedStates(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to read state: %v\n", err)
+		return 1
+	}
+	if len(states) == 0 {
+		fmt.Fprintln(stdout, "No state recorded yet")
+		return 0
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(states)
+	} else {
+		err = printRecordedStates(stdout, states)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to print state: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readRecordedStates reads the state of each failover group of the configuration, skipping
+// state files that do not exist yet
+func readRecordedStates(ctx context.Context, cfg *config.Config) ([]recordedState, error) {
+	var states []recordedState
+	for _, group := range cfg.FailoverGroups() {
+		stateFile := cfg.StateFile
+		if name := cfg.GroupStateName(group.DNS[0]); name != "" {
+			stateFile = state.RecordStateFile(cfg.StateFile, name)
+		}
+		records := make([]string, 0, len(group.DNS))
+		for _, dnsConfig := range group.DNS {
+			records = append(records, dnsConfig.Name)
+		}
+
+		data, err := state.NewFileStateStore(stateFile, zap.NewNop()).ExportState(ctx)
+		if errors.IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var recorded state.State
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			return nil, fmt.Errorf("failed to parse state file %s: %w", stateFile, err)
+		}
+		states = append(states, recordedState{StateFile: stateFile, Records: records, State: &recorded})
+	}
+	return states, nil
+}
+
+// printRecordedStates prints the states as tables of their fields
+func printRecordedStates(out io.Writer, states []recordedState) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, recorded := range states {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		s := recorded.State
+
+		fmt.Fprintf(w, "State file:\t%s\n", recorded.StateFile)
+		fmt.Fprintf(w, "Records:\t%s\n", strings.Join(recorded.Records, ", "))
+		fmt.Fprintf(w, "Last applied IP:\t%s\n", orNone(s.LastAppliedIP))
+		fmt.Fprintf(w, "Last change time:\t%s\n", formatStateTime(s.LastChangeTime))
+		fmt.Fprintf(w, "Last check IP:\t%s\n", orNone(s.LastCheckIP))
+		fmt.Fprintf(w, "Last check time:\t%s\n", formatStateTime(s.LastCheckTime))
+		fmt.Fprintf(w, "Update count:\t%d\n", s.UpdateCount)
+		fmt.Fprintf(w, "Primary failure count:\t%d\n", s.PrimaryFailureCount)
+		if !s.PrimaryRecoveredAt.IsZero() {
+			fmt.Fprintf(w, "Primary recovered at:\t%s (%d successful checks since)\n", formatStateTime(s.PrimaryRecoveredAt), s.PrimarySuccessCount)
+		}
+		if s.PendingIP != "" {
+			fmt.Fprintf(w, "Pending IP:\t%s (selected by %d polls)\n", s.PendingIP, s.PendingIPCount)
+		}
+		fmt.Fprintf(w, "Low TTL mode:\t%s\n", strconv.FormatBool(s.InLowTTLMode))
+		if s.ForcedTarget != "" {
+			fmt.Fprintf(w, "Forced target:\t%s (automatic failover suspended)\n", s.ForcedTarget)
+		}
+
+		providers := make([]string, 0, len(s.ProviderFailureStreaks))
+		for provider := range s.ProviderFailureStreaks {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+		for _, provider := range providers {
+			streak := s.ProviderFailureStreaks[provider]
+			fmt.Fprintf(w, "Failing provider %s:\t%d failures since %s, last error: %s\n",
+				provider, streak.FailureCount, formatStateTime(streak.FirstFailure), streak.LastError)
+		}
+	}
+	return w.Flush()
+}
+
+// formatStateTime formats a state timestamp, or "never" if it is not set
+func formatStateTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// orNone returns s, or "none" if it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// sameNameConfig returns a configuration with the global records and two records with the same
+// name and type but different providers, each failing over on its own
+func sameNameConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		PrimaryIP:   "203.0.113.10",
+		SecondaryIP: "198.51.100.20",
+		StateFile:   filepath.Join(t.TempDir(), "state.json"),
+		DNS: []config.DNSConfig{
+			{Name: "home.example.com", Type: "A", Provider: "cloudflare"},
+			{Name: "cdn.example.com", Type: "A", Provider: "cloudflare", PrimaryIP: "192.0.2.10"},
+			{Name: "cdn.example.com", Type: "A", Provider: "adguard", PrimaryIP: "192.0.2.11"},
+		},
+	}
+}
+
+func TestReadRecordedStates_SameNameRecords(t *testing.T) {
+	cfg := sameNameConfig(t)
+	ctx := context.Background()
+	dir := filepath.Dir(cfg.StateFile)
+	publicFile := filepath.Join(dir, "state.cdn.example.com_A_cloudflare.json")
+	lanFile := filepath.Join(dir, "state.cdn.example.com_A_adguard.json")
+	require.NoError(t, state.NewFileStateStore(publicFile, zap.NewNop()).SetLastAppliedIP(ctx, "192.0.2.10"))
+	require.NoError(t, state.NewFileStateStore(lanFile, zap.NewNop()).SetLastAppliedIP(ctx, "192.0.2.11"))
+
+	states, err := readRecordedStates(ctx, cfg)
+	require.NoError(t, err)
+
+	// The global state file does not exist yet and is skipped
+	require.Len(t, states, 2)
+	assert.Equal(t, publicFile, states[0].StateFile)
+	assert.Equal(t, []string{"cdn.example.com"}, states[0].Records)
+	assert.Equal(t, "192.0.2.10", states[0].State.LastAppliedIP)
+	assert.Equal(t, lanFile, states[1].StateFile)
+	assert.Equal(t, []string{"cdn.example.com"}, states[1].Records)
+	assert.Equal(t, "192.0.2.11", states[1].State.LastAppliedIP)
+}
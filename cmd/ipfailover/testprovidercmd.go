@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+)
+
+// testRecordPrefix is the label of the TXT record created by test-provider -write
+const testRecordPrefix = "_ipfailover-test"
+
+// runTestProviderCommand implements the test-provider subcommand and returns the exit code
+func runTestProviderCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("test-provider", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file")
+	providerName := flags.String("provider-name", "", "DNS provider to test, e.g. cloudflare; its first record in the configuration supplies the credentials")
+	recordName := flags.String("record", "", "Record name to look up, and to select among several records of the provider (default: the record's configured name)")
+	domain := flags.String("domain", "", "With -write, domain of the test record (default: the record name without its first label)")
+	write := flags.Bool("write", false, "Also create, read back and delete a TXT test record "+testRecordPrefix+".<domain>")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover test-provider -config /path/to/config.yaml -provider-name NAME [-record NAME] [-write [-domain DOMAIN]]\n\n")
+		fmt.Fprintf(stderr, "Smoke-test the credentials and API access of a DNS provider.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" {
+		fmt.Fprintf(stderr, "Error: -config flag is required for test-provider\n")
+		return 1
+	}
+	if *providerName == "" {
+		fmt.Fprintf(stderr, "Error: -provider-name flag is required for test-provider\n")
+		return 1
+	}
+
+	cfg, missing, err := config.LoadConfigWithOverlays(*configFile, configOverlays)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	for _, overlay := range missing {
+		fmt.Fprintf(stderr, "Warning: configuration overlay %s does not exist, skipping\n", overlay)
+	}
+
+	dnsConfig, ok := selectProviderRecord(cfg, *providerName, *recordName)
+	if !ok {
+		fmt.Fprintf(stderr, "Error: no record with provider %q in the configuration\n", *providerName)
+		return 1
+	}
+
+	opts := testProviderOptions{record: *recordName, domain: *domain, write: *write}
+	if err := runTestProvider(cfg, dnsConfig, opts, stdout); err != nil {
+		fmt.Fprintf(stderr, "Provider test failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// testProviderOptions holds the flags of the test-provider command
+type testProviderOptions struct {
+	record string
+	domain string
+	write  bool
+}
+
+// selectProviderRecord returns the record of cfg using providerName, preferring the one named
+// recordName if there are several
+func selectProviderRecord(cfg *config.Config, providerName, recordName string) (config.DNSConfig, bool) {
+	var selected config.DNSConfig
+	found := false
+	for _, dnsConfig := range cfg.DNS {
+		if !strings.EqualFold(dnsConfig.Provider, providerName) {
+			continue
+		}
+		if strings.EqualFold(dnsConfig.Name, recordName) {
+			return dnsConfig, true
+		}
+		if !found {
+			selected, found = dnsConfig, true
+		}
+	}
+	return selected, found
+}
+
+// runTestProvider validates the provider of dnsConfig and looks up a record through it. With
+// opts.write it also creates a TXT test record, reads it back and deletes it again. It prints a
+// result line per step to out and returns an error if any step failed.
+func runTestProvider(cfg *config.Config, dnsConfig config.DNSConfig, opts testProviderOptions, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	app := newCommandApplication(cfg)
+	provider, err := app.newDNSProvider(cfg, dnsConfig)
+	if err == nil {
+		defer app.closeDNSProviders(map[string]interfaces.DNSProvider{dnsConfig.Key(): provider})
+		err = validateWithTimeout(provider.Validate)
+	}
+	printValidateResult(w, "validate", fmt.Sprintf("%s, credentials of record %s", dnsConfig.Provider, dnsConfig.Name), err)
+	if err != nil {
+		return fmt.Errorf("provider validation failed")
+	}
+
+	steps, failed := 2, 0
+	name := opts.record
+	if name == "" {
+		name = dnsConfig.Name
+	}
+	component := fmt.Sprintf("get %s %s", dnsConfig.Type, name)
+	var record *interfaces.DNSRecord
+	err = validateWithTimeout(func(ctx context.Context) error {
+		var err error
+		record, err = provider.GetRecord(ctx, name, dnsConfig.Type)
+		return err
+	})
+	printValidateResult(w, component, describeRecord(record), err)
+	if err != nil {
+		failed++
+	}
+
+	if opts.write {
+		domain := opts.domain
+		if domain == "" {
+			domain = parentDomain(dnsConfig.Name)
+		}
+		steps += 3
+		failed += testProviderWrite(w, provider, domain, dnsConfig.TTL)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d steps failed", failed, steps)
+	}
+	return nil
+}
+
+// testProviderWrite creates the TXT test record of domain, reads it back and deletes it, printing a
+// result line per step to w. The record is deleted even if reading it back failed. It returns the
+// number of failed steps, counting the steps not run after a failed create.
+func testProviderWrite(w io.Writer, provider interfaces.DNSProvider, domain string, ttl int) int {
+	test := interfaces.DNSRecord{
+		Name:     testRecordPrefix + "." + domain,
+		Type:     "TXT",
+		Value:    fmt.Sprintf("test-%d", time.Now().Unix()),
+		TTL:      ttl,
+		Provider: provider.Name(),
+	}
+
+	err := validateWithTimeout(func(ctx context.Context) error {
+		return provider.UpdateRecord(ctx, test)
+	})
+	printValidateResult(w, fmt.Sprintf("create TXT %s", test.Name), test.Value, err)
+	if err != nil {
+		return 3
+	}
+
+	failed := 0
+	var record *interfaces.DNSRecord
+	err = validateWithTimeout(func(ctx context.Context) error {
+		var err error
+		record, err = provider.GetRecord(ctx, test.Name, test.Type)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return fmt.Errorf("record not found after creating it")
+		}
+		if value := strings.Trim(record.Value, `"`); value != test.Value {
+			return fmt.Errorf("record has value %q, expected %q", value, test.Value)
+		}
+		return nil
+	})
+	printValidateResult(w, fmt.Sprintf("verify TXT %s", test.Name), test.Value, err)
+	if err != nil {
+		failed++
+	}
+
+	err = validateWithTimeout(func(ctx context.Context) error {
+		return provider.DeleteRecord(ctx, test.Name, test.Type)
+	})
+	printValidateResult(w, fmt.Sprintf("delete TXT %s", test.Name), "deleted", err)
+	if err != nil {
+		failed++
+	}
+	return failed
+}
+
+// describeRecord returns the value and TTL of a looked up record, or "not found" for nil
+func describeRecord(record *interfaces.DNSRecord) string {
+	if record == nil {
+		return "not found"
+	}
+	return fmt.Sprintf("%s (ttl %d)", record.Value, record.TTL)
+}
+
+// parentDomain returns name without its first label if it has at least three labels, e.g.
+// example.com for home.example.com, and name itself otherwise
+func parentDomain(name string) string {
+	name = strings.TrimSuffix(name, ".")
+	if strings.Count(name, ".") < 2 {
+		return name
+	}
+	return name[strings.Index(name, ".")+1:]
+}
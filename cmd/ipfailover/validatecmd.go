@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/internal/fleet"
+	"github.com/devhat/ipfailover/internal/metrics"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// validateTimeout bounds each check of the validate command
+const validateTimeout = 10 * time.Second
+
+// runValidateCommand implements the validate subcommand and returns the exit code
+func runValidateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var configOverlays stringListFlag
+	flags.Var(&configOverlays, "config-overlay", "Path to a configuration file merged on top of -config, can be given multiple times")
+	configFile := flags.String("config", "", "Path to configuration file")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ipfailover validate -config /path/to/config.yaml\n\n")
+		fmt.Fprintf(stderr, "Validate the configuration, the DNS providers and IP detection.\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *configFile == "" {
+		fmt.Fprintf(stderr, "Error: -config flag is required for validate\n")
+		return 1
+	}
+
+	if err := runValidate(*configFile, configOverlays, stdout); err != nil {
+		fmt.Fprintf(stderr, "Validation failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runValidate loads and validates the configuration, then validates the DNS provider of each
+// record, the source address and interface if set, and detects the current IP, in dual-stack mode
+// also the current IPv6 address, as the daemon does at startup. It prints a result line per
+// check to out and returns an error if any check failed. Nothing is changed at the providers.
+func runValidate(configFile string, configOverlays []string, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	cfg, missing, err := config.LoadConfigWithOverlays(configFile, configOverlays)
+	printValidateResult(w, "configuration", configFile, err)
+	if err != nil {
+		return fmt.Errorf("invalid configuration")
+	}
+	for _, overlay := range missing {
+		fmt.Fprintf(w, "WARN\tconfiguration\toverlay %s does not exist, skipped\n", overlay)
+	}
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(w, "WARN\tconfiguration\t%s\n", warning)
+	}
+
+	app := newCommandApplication(cfg)
+
+	failed := 0
+	providers := make(map[string]interfaces.DNSProvider)
+	defer app.closeDNSProviders(providers)
+	for _, dnsConfig := range cfg.DNS {
+		component := fmt.Sprintf("dns %s", dnsConfig.Name)
+		provider, err := app.newDNSProvider(cfg, dnsConfig)
+		if err == nil {
+			providers[dnsConfig.Key()] = provider
+			err = validateWithTimeout(provider.Validate)
+		}
+		printValidateResult(w, component, dnsConfig.Provider, err)
+		if err != nil {
+			failed++
+		}
+	}
+
+	checks := len(cfg.DNS) + 1
+	if source := cfg.Source(); !source.IsZero() {
+		checks++
+		err := source.Validate()
+		printValidateResult(w, "source", fmt.Sprintf("ip %s, interface %s", orNone(source.IP), orNone(source.Interface)), err)
+		if err != nil {
+			failed++
+		}
+	}
+
+	ipChecker := app.newIPChecker(cfg)
+	var currentIP string
+	err = validateWithTimeout(func(ctx context.Context) error {
+		var err error
+		currentIP, err = ipChecker.GetCurrentIP(ctx)
+		return err
+	})
+	printValidateResult(w, "ip check", fmt.Sprintf("current IP %s", currentIP), err)
+	if err != nil {
+		failed++
+	}
+
+	if ipv6Checker := app.newIPv6Checker(cfg); ipv6Checker != nil {
+		checks++
+		var currentIPv6 string
+		err = validateWithTimeout(func(ctx context.Context) error {
+			var err error
+			currentIPv6, err = ipv6Checker.GetCurrentIP(ctx)
+			return err
+		})
+		printValidateResult(w, "ipv6 check", fmt.Sprintf("current IPv6 %s", currentIPv6), err)
+		if err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, checks)
+	}
+	return nil
+}
+
+// newCommandApplication returns an Application for subcommands that create DNS providers and IP
+// checkers from cfg, without logging, state or background loops
+func newCommandApplication(cfg *config.Config) *Application {
+	logger := zap.NewNop()
+	return &Application{
+		config:          cfg,
+		logger:          logger,
+		rng:             fleet.NewRand(0),
+		metrics:         metrics.NewPrometheusCollector(logger),
+		circuitBreakers: make(map[string]*dns.CircuitBreaker),
+	}
+}
+
+// validateWithTimeout runs a check with validateTimeout
+func validateWithTimeout(check func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+	return check(ctx)
+}
+
+// printValidateResult prints the result line of a check, with its details if it passed or its
+// error if it failed
+func printValidateResult(w io.Writer, component, details string, err error) {
+	if err != nil {
+		fmt.Fprintf(w, "FAIL\t%s\t%v\n", component, err)
+		return
+	}
+	fmt.Fprintf(w, "PASS\t%s\t%s\n", component, details)
+}
@@ -0,0 +1,195 @@
+// Command hosts-plugin is an example DNS provider plugin. It keeps A and AAAA records in a
+// hosts file, for example one read by dnsmasq or CoreDNS' hosts plugin, set by HOSTS_FILE.
+//
+// Build it and configure it as a record's provider:
+//
+//	go build -o /usr/local/lib/ipfailover/hosts-plugin ./examples/hosts-plugin
+//
+//	dns:
+//	  - provider: plugin
+//	    name: home.example.com
+//	    type: A
+//	    plugin:
+//	      command: /usr/local/lib/ipfailover/hosts-plugin
+//	      env:
+//	        HOSTS_FILE: /etc/hosts.d/failover
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/devhat/ipfailover/pkg/plugin"
+)
+
+// HostsProvider implements interfaces.DNSProvider on a hosts file with one
+// "address name" line per record
+type HostsProvider struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Name returns the provider name
+func (h *HostsProvider) Name() string {
+	return "hosts"
+}
+
+// UpdateRecord replaces the line of the record, or appends one
+func (h *HostsProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	if err := checkAddress(record.Type, record.Value); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lines, err := h.read()
+	if err != nil {
+		return err
+	}
+
+	entry := record.Value + " " + record.Name
+	replaced := false
+	for i, line := range lines {
+		if matches(line, record.Name, record.Type) {
+			lines[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		lines = append(lines, entry)
+	}
+
+	return h.write(lines)
+}
+
+// GetRecord returns the record of the name and type, or nil if there is none
+func (h *HostsProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lines, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if matches(line, name, rtype) {
+			return &interfaces.DNSRecord{
+				Name:  name,
+				Type:  rtype,
+				Value: strings.Fields(line)[0],
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// DeleteRecord removes the line of the record, if any
+func (h *HostsProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lines, err := h.read()
+	if err != nil {
+		return err
+	}
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if !matches(line, name, recordType) {
+			kept = append(kept, line)
+		}
+	}
+
+	return h.write(kept)
+}
+
+// Validate checks that the hosts file is configured and its directory exists
+func (h *HostsProvider) Validate(ctx context.Context) error {
+	if h.path == "" {
+		return fmt.Errorf("HOSTS_FILE is not set")
+	}
+
+	info, err := os.Stat(filepath.Dir(h.path))
+	if err != nil {
+		return fmt.Errorf("hosts file directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("hosts file directory %s is not a directory", filepath.Dir(h.path))
+	}
+
+	return nil
+}
+
+// read returns the lines of the hosts file, none if it does not exist
+func (h *HostsProvider) read() ([]string, error) {
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// write atomically replaces the hosts file with lines
+func (h *HostsProvider) write(lines []string) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write hosts file: %w", err)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("failed to replace hosts file: %w", err)
+	}
+	return nil
+}
+
+// matches reports whether a hosts file line is the record of the name and type
+func matches(line, name, rtype string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || fields[1] != name {
+		return false
+	}
+	return checkAddress(rtype, fields[0]) == nil
+}
+
+// checkAddress checks that value is an address of the record type
+func checkAddress(rtype, value string) error {
+	ip := net.ParseIP(value)
+	switch {
+	case ip == nil:
+		return fmt.Errorf("invalid IP address %q", value)
+	case rtype == "A" && ip.To4() != nil, rtype == "AAAA" && ip.To4() == nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported record type %s for address %s", rtype, value)
+	}
+}
+
+func main() {
+	plugin.Serve(&HostsProvider{path: os.Getenv("HOSTS_FILE")})
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/audit"
+	"github.com/devhat/ipfailover/internal/config"
+	"go.uber.org/zap"
+)
+
+// API endpoint paths
+const (
+	StatusPath        = "/api/v1/status"
+	ForceFailoverPath = "/api/v1/force-failover"
+	FailoverPath      = "/api/v1/failover" // Forced failover to TargetSecondary
+	FailbackPath      = "/api/v1/failback" // Forced failover to TargetPrimary
+	CheckPath         = "/api/v1/check"
+	ReloadPath        = "/api/v1/reload"
+)
+
+// Targets of a forced failover, see ForceFailoverRequest
+const (
+	TargetPrimary   = "primary"
+	TargetSecondary = "secondary"
+)
+
+// maxRequestBody limits the size of request bodies
+const maxRequestBody = 64 * 1024
+
+// Provider update statuses reported in ProviderStatus
+const (
+	UpdateStatusNone   = "none" // Not written to since startup
+	UpdateStatusOK     = "ok"
+	UpdateStatusFailed = "failed"
+)
+
+// Status is the daemon state reported by GET /api/v1/status
+type Status struct {
+	CurrentIP           string           `json:"current_ip"`
+	LastAppliedIP       string           `json:"last_applied_ip"`
+	ActiveTarget        string           `json:"active_target,omitempty"` // TargetPrimary or TargetSecondary if LastAppliedIP is one of them
+	LastChangeTime      time.Time        `json:"last_change_time"`
+	PrimaryFailureCount int              `json:"primary_failure_count"`
+	PrimarySuccessCount int              `json:"primary_success_count"`   // Successful checks of the recovering primary after a failover
+	ForcedTarget        string           `json:"forced_target,omitempty"` // TargetPrimary or TargetSecondary while a manual failover pins the records
+	UpdateCount         int64            `json:"update_count"`
+	Records             []RecordStatus   `json:"records"`
+	Providers           []ProviderStatus `json:"providers"`
+	UptimeSeconds       float64          `json:"uptime_seconds"`
+}
+
+// RecordStatus is the IP last applied to a DNS record, by the failover group it belongs to
+type RecordStatus struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Provider      string `json:"provider"`
+	LastAppliedIP string `json:"last_applied_ip"`
+	ActiveTarget  string `json:"active_target,omitempty"` // TargetPrimary or TargetSecondary if LastAppliedIP is one of the record's IPs
+}
+
+// ProviderStatus is the outcome of the last DNS update of a provider
+type ProviderStatus struct {
+	Name             string     `json:"name"`
+	LastUpdateStatus string     `json:"last_update_status"`
+	LastUpdateTime   *time.Time `json:"last_update_time,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+}
+
+// ForceFailoverRequest is the body of POST /api/v1/force-failover
+type ForceFailoverRequest struct {
+	// Target is the IP the DNS records are switched to, TargetPrimary or TargetSecondary
+	Target string `json:"target"`
+
+	// Reason is logged with the failover, e.g. the maintenance it is for
+	Reason string `json:"reason,omitempty"`
+
+	// RequestedBy identifies the client, from the request metadata
+	RequestedBy string `json:"-"`
+}
+
+// TargetRequest is the optional body of POST /api/v1/failover and /api/v1/failback
+type TargetRequest struct {
+	// Reason is logged with the failover, e.g. the maintenance it is for
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReloadResult is the response of POST /api/v1/reload
+type ReloadResult struct {
+	// Config is the reloaded configuration with secrets redacted
+	Config map[string]interface{} `json:"config"`
+
+	// Changes are the configuration keys that differ from the previous configuration
+	Changes []config.Change `json:"changes"`
+}
+
+// Daemon provides the daemon state served by the API and carries out its requests
+type Daemon interface {
+	// APIStatus returns the current daemon state
+	APIStatus(ctx context.Context) (*Status, error)
+
+	// ForceFailover switches the DNS records to the requested target IP without checking its reachability
+	ForceFailover(ctx context.Context, req ForceFailoverRequest) error
+
+	// Check runs a check cycle at once, as on the next poll
+	Check(ctx context.Context) error
+
+	// Reload reloads the configuration as on SIGHUP. The current configuration is kept if it fails.
+	Reload(ctx context.Context) (*ReloadResult, error)
+}
+
+// Handler serves the REST API. Every request must carry the API token as a Bearer token
+// in the Authorization header.
+type Handler struct {
+	daemon Daemon
+	token  string
+	mux    *http.ServeMux
+	audit  *audit.Logger // Records state-changing requests, nil to not record them
+	logger *zap.Logger
+}
+
+// NewHandler creates the API handler for daemon, authenticating requests with token
+func NewHandler(daemon Daemon, token string, logger *zap.Logger) *Handler {
+	h := &Handler{
+		daemon: daemon,
+		token:  token,
+		mux:    http.NewServeMux(),
+		logger: logger,
+	}
+	h.mux.HandleFunc("GET "+StatusPath, h.handleStatus)
+	h.mux.HandleFunc("POST "+ForceFailoverPath, h.handleForceFailover)
+	h.mux.HandleFunc("POST "+FailoverPath, h.handleTarget(TargetSecondary))
+	h.mux.HandleFunc("POST "+FailbackPath, h.handleTarget(TargetPrimary))
+	h.mux.HandleFunc("POST "+CheckPath, h.handleCheck)
+	h.mux.HandleFunc("POST "+ReloadPath, h.handleReload)
+	return h
+}
+
+// SetAuditLog records the requests changing the daemon state in auditLog, including rejected ones
+func (h *Handler) SetAuditLog(auditLog *audit.Logger) {
+	h.audit = auditLog
+}
+
+// auditedResponse captures the status of a response and the reason given in the request, for the audit log
+type auditedResponse struct {
+	http.ResponseWriter
+	status int
+	reason string
+}
+
+// WriteHeader records the status code
+func (a *auditedResponse) WriteHeader(code int) {
+	a.status = code
+	a.ResponseWriter.WriteHeader(code)
+}
+
+// setAuditReason records the reason given in the request in the audit log
+func setAuditReason(w http.ResponseWriter, reason string) {
+	if audited, ok := w.(*auditedResponse); ok {
+		audited.reason = reason
+	}
+}
+
+// ServeHTTP authenticates the request and dispatches it to the endpoint
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		audited := &auditedResponse{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			h.audit.LogRequest(audit.Request{
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestedBy: requestedBy(r),
+				Reason:      audited.reason,
+				Status:      audited.status,
+			})
+		}()
+		w = audited
+	}
+
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ipfailover"`)
+		writeError(w, http.StatusUnauthorized, "missing or invalid API token")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorized reports whether the request carries the API token
+func (h *Handler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || h.token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// handleStatus serves the daemon status
+func (h *Handler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	h.writeStatus(w, r)
+}
+
+// handleForceFailover switches the DNS records to the requested target and responds with the new status
+func (h *Handler) handleForceFailover(w http.ResponseWriter, r *http.Request) {
+	var req ForceFailoverRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Target != TargetPrimary && req.Target != TargetSecondary {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("target must be %q or %q, got %q", TargetPrimary, TargetSecondary, req.Target))
+		return
+	}
+	h.forceFailover(w, r, req)
+}
+
+// handleTarget returns the handler switching the DNS records to target, with an optional TargetRequest body
+func (h *Handler) handleTarget(target string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body TargetRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&body); err != nil && err != io.EOF {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		h.forceFailover(w, r, ForceFailoverRequest{Target: target, Reason: body.Reason})
+	}
+}
+
+// forceFailover switches the DNS records to the target of req and responds with the new status
+func (h *Handler) forceFailover(w http.ResponseWriter, r *http.Request, req ForceFailoverRequest) {
+	req.RequestedBy = requestedBy(r)
+	setAuditReason(w, req.Reason)
+
+	if err := h.daemon.ForceFailover(r.Context(), req); err != nil {
+		h.logger.Error("forced failover failed",
+			zap.String("target", req.Target),
+			zap.String("requested_by", req.RequestedBy),
+			zap.Error(err),
+		)
+		writeError(w, http.StatusInternalServerError, "forced failover failed: "+err.Error())
+		return
+	}
+
+	h.writeStatus(w, r)
+}
+
+// handleCheck runs a check cycle and responds with the new status
+func (h *Handler) handleCheck(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("check requested", zap.String("requested_by", requestedBy(r)))
+
+	if err := h.daemon.Check(r.Context()); err != nil {
+		h.logger.Error("requested check failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "check failed: "+err.Error())
+		return
+	}
+
+	h.writeStatus(w, r)
+}
+
+// handleReload reloads the configuration and responds with the new configuration and its changes
+func (h *Handler) handleReload(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("configuration reload requested", zap.String("requested_by", requestedBy(r)))
+
+	result, err := h.daemon.Reload(r.Context())
+	if err != nil {
+		h.logger.Error("configuration reload failed, continuing with previous configuration", zap.Error(err))
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if result.Changes == nil {
+		result.Changes = []config.Change{}
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// writeStatus responds with the daemon status
+func (h *Handler) writeStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.daemon.APIStatus(r.Context())
+	if err != nil {
+		h.logger.Error("failed to read status", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to read status")
+		return
+	}
+	if status.Providers == nil {
+		status.Providers = []ProviderStatus{}
+	}
+	if status.Records == nil {
+		status.Records = []RecordStatus{}
+	}
+
+	h.writeJSON(w, http.StatusOK, status)
+}
+
+// requestedBy identifies the client of a request by its address, the addresses it was forwarded
+// for, if any, and its user agent
+func requestedBy(r *http.Request) string {
+	client := r.RemoteAddr
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		client += " (forwarded for " + forwarded + ")"
+	}
+	if agent := r.UserAgent(); agent != "" {
+		client += " " + agent
+	}
+	return client
+}
+
+// writeJSON writes v as the JSON response body
+func (h *Handler) writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		h.logger.Error("failed to write API response", zap.Error(err))
+	}
+}
+
+// writeError writes an error response with a JSON body of the form {"error": message}
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,443 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/api"
+	"github.com/devhat/ipfailover/internal/audit"
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDaemon serves a fixed status, or err, and records forced failovers, checks and reloads
+type fakeDaemon struct {
+	status       *api.Status
+	err          error
+	failoverErr  error
+	failovers    []api.ForceFailoverRequest
+	checkErr     error
+	checks       int
+	reloadResult *api.ReloadResult
+	reloadErr    error
+	reloads      int
+}
+
+func (f *fakeDaemon) APIStatus(ctx context.Context) (*api.Status, error) {
+	return f.status, f.err
+}
+
+func (f *fakeDaemon) ForceFailover(ctx context.Context, req api.ForceFailoverRequest) error {
+	f.failovers = append(f.failovers, req)
+	return f.failoverErr
+}
+
+func (f *fakeDaemon) Check(ctx context.Context) error {
+	f.checks++
+	return f.checkErr
+}
+
+func (f *fakeDaemon) Reload(ctx context.Context) (*api.ReloadResult, error) {
+	f.reloads++
+	return f.reloadResult, f.reloadErr
+}
+
+func serve(handler http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	return serveBody(handler, method, path, token, "")
+}
+
+func serveBody(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Status(t *testing.T) {
+	updated := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	daemon := &fakeDaemon{status: &api.Status{
+		CurrentIP:           "203.0.113.10",
+		LastAppliedIP:       "198.51.100.77",
+		LastChangeTime:      updated,
+		PrimaryFailureCount: 2,
+		UpdateCount:         3,
+		Providers: []api.ProviderStatus{
+			{Name: "cloudflare", LastUpdateStatus: api.UpdateStatusOK, LastUpdateTime: &updated},
+			{Name: "route53", LastUpdateStatus: api.UpdateStatusNone},
+		},
+		UptimeSeconds: 42.5,
+	}}
+	handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+	t.Run("returns the status", func(t *testing.T) {
+		rec := serve(handler, http.MethodGet, api.StatusPath, "api-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "203.0.113.10", body["current_ip"])
+		assert.Equal(t, "198.51.100.77", body["last_applied_ip"])
+		assert.Equal(t, "2025-03-01T12:30:00Z", body["last_change_time"])
+		assert.Equal(t, float64(2), body["primary_failure_count"])
+		assert.Equal(t, float64(3), body["update_count"])
+		assert.Equal(t, 42.5, body["uptime_seconds"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "cloudflare", "last_update_status": "ok", "last_update_time": "2025-03-01T12:30:00Z"},
+			map[string]interface{}{"name": "route53", "last_update_status": "none"},
+		}, body["providers"])
+	})
+
+	t.Run("requires the token", func(t *testing.T) {
+		for name, token := range map[string]string{"missing": "", "wrong": "other-token"} {
+			rec := serve(handler, http.MethodGet, api.StatusPath, token)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+			assert.Equal(t, `Bearer realm="ipfailover"`, rec.Header().Get("WWW-Authenticate"), name)
+			assert.JSONEq(t, `{"error": "missing or invalid API token"}`, rec.Body.String(), name)
+		}
+	})
+
+	t.Run("rejects other schemes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, api.StatusPath, nil)
+		req.SetBasicAuth("admin", "api-token")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("only GET", func(t *testing.T) {
+		rec := serve(handler, http.MethodPost, api.StatusPath, "api-token")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		rec := serve(handler, http.MethodGet, "/api/v1/unknown", "api-token")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		handler := api.NewHandler(&fakeDaemon{err: fmt.Errorf("state file unreadable")}, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodGet, api.StatusPath, "api-token")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"error": "failed to read status"}`, rec.Body.String())
+	})
+
+	t.Run("empty providers and records are lists", func(t *testing.T) {
+		handler := api.NewHandler(&fakeDaemon{status: &api.Status{}}, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodGet, api.StatusPath, "api-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"providers": []`)
+		assert.Contains(t, rec.Body.String(), `"records": []`)
+	})
+
+	t.Run("records", func(t *testing.T) {
+		handler := api.NewHandler(&fakeDaemon{status: &api.Status{
+			LastAppliedIP:       "198.51.100.77",
+			ActiveTarget:        api.TargetSecondary,
+			PrimarySuccessCount: 1,
+			Records: []api.RecordStatus{
+				{Name: "home.example.com", Type: "A", Provider: "cloudflare", LastAppliedIP: "198.51.100.77", ActiveTarget: api.TargetSecondary},
+				{Name: "vpn.example.com", Type: "A", Provider: "route53"},
+			},
+		}}, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodGet, api.StatusPath, "api-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "secondary", body["active_target"])
+		assert.Equal(t, float64(1), body["primary_success_count"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "home.example.com", "type": "A", "provider": "cloudflare", "last_applied_ip": "198.51.100.77", "active_target": "secondary"},
+			map[string]interface{}{"name": "vpn.example.com", "type": "A", "provider": "route53", "last_applied_ip": ""},
+		}, body["records"])
+	})
+}
+
+func TestHandler_ForceFailover(t *testing.T) {
+	newDaemon := func() *fakeDaemon {
+		return &fakeDaemon{status: &api.Status{LastAppliedIP: "198.51.100.77", UpdateCount: 1}}
+	}
+
+	t.Run("switches to the target and returns the status", func(t *testing.T) {
+		daemon := newDaemon()
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPost, api.ForceFailoverPath, strings.NewReader(`{"target": "secondary", "reason": "maintenance"}`))
+		req.Header.Set("Authorization", "Bearer api-token")
+		req.Header.Set("User-Agent", "curl/8.5.0")
+		req.RemoteAddr = "192.0.2.10:51234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"last_applied_ip": "198.51.100.77"`)
+		require.Len(t, daemon.failovers, 1)
+		assert.Equal(t, api.ForceFailoverRequest{
+			Target:      api.TargetSecondary,
+			Reason:      "maintenance",
+			RequestedBy: "192.0.2.10:51234 curl/8.5.0",
+		}, daemon.failovers[0])
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for body, message := range map[string]string{
+			`{"target": "tertiary"}`:               `target must be \"primary\" or \"secondary\", got \"tertiary\"`,
+			`{}`:                                   `target must be \"primary\" or \"secondary\", got \"\"`,
+			`{"target": "primary", "force": true}`: `invalid request body: json: unknown field \"force\"`,
+			`not json`:                             `invalid request body`,
+		} {
+			daemon := newDaemon()
+			handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+			rec := serveBody(handler, http.MethodPost, api.ForceFailoverPath, "api-token", body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+			assert.Contains(t, rec.Body.String(), message, body)
+			assert.Empty(t, daemon.failovers, body)
+		}
+	})
+
+	t.Run("requires the token", func(t *testing.T) {
+		daemon := newDaemon()
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serveBody(handler, http.MethodPost, api.ForceFailoverPath, "", `{"target": "secondary"}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, daemon.failovers)
+	})
+
+	t.Run("only POST", func(t *testing.T) {
+		handler := api.NewHandler(newDaemon(), "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodGet, api.ForceFailoverPath, "api-token")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("failed update", func(t *testing.T) {
+		daemon := newDaemon()
+		daemon.failoverErr = fmt.Errorf("failed to update DNS records")
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serveBody(handler, http.MethodPost, api.ForceFailoverPath, "api-token", `{"target": "primary"}`)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"error": "forced failover failed: failed to update DNS records"}`, rec.Body.String())
+	})
+}
+
+func TestHandler_FailoverAndFailback(t *testing.T) {
+	for path, target := range map[string]string{api.FailoverPath: api.TargetSecondary, api.FailbackPath: api.TargetPrimary} {
+		t.Run(target, func(t *testing.T) {
+			daemon := &fakeDaemon{status: &api.Status{LastAppliedIP: "198.51.100.77"}}
+			handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+			rec := serveBody(handler, http.MethodPost, path, "api-token", `{"reason": "maintenance"}`)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"last_applied_ip": "198.51.100.77"`)
+
+			// The body is optional
+			rec = serve(handler, http.MethodPost, path, "api-token")
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			require.Len(t, daemon.failovers, 2)
+			assert.Equal(t, target, daemon.failovers[0].Target)
+			assert.Equal(t, "maintenance", daemon.failovers[0].Reason)
+			assert.Equal(t, target, daemon.failovers[1].Target)
+			assert.Empty(t, daemon.failovers[1].Reason)
+		})
+	}
+
+	t.Run("target cannot be given", func(t *testing.T) {
+		daemon := &fakeDaemon{}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serveBody(handler, http.MethodPost, api.FailoverPath, "api-token", `{"target": "primary"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `unknown field \"target\"`)
+		assert.Empty(t, daemon.failovers)
+	})
+
+	t.Run("requires the token", func(t *testing.T) {
+		daemon := &fakeDaemon{}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.FailbackPath, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, daemon.failovers)
+	})
+
+	t.Run("failed update", func(t *testing.T) {
+		daemon := &fakeDaemon{failoverErr: fmt.Errorf("failed to update DNS records")}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.FailbackPath, "api-token")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"error": "forced failover failed: failed to update DNS records"}`, rec.Body.String())
+	})
+}
+
+func TestHandler_Check(t *testing.T) {
+	t.Run("runs a check and returns the status", func(t *testing.T) {
+		daemon := &fakeDaemon{status: &api.Status{CurrentIP: "203.0.113.10"}}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.CheckPath, "api-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"current_ip": "203.0.113.10"`)
+		assert.Equal(t, 1, daemon.checks)
+	})
+
+	t.Run("failed check", func(t *testing.T) {
+		daemon := &fakeDaemon{checkErr: fmt.Errorf("all IP check endpoints failed")}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.CheckPath, "api-token")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"error": "check failed: all IP check endpoints failed"}`, rec.Body.String())
+	})
+
+	t.Run("requires the token", func(t *testing.T) {
+		daemon := &fakeDaemon{}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.CheckPath, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Zero(t, daemon.checks)
+	})
+
+	t.Run("only POST", func(t *testing.T) {
+		daemon := &fakeDaemon{}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodGet, api.CheckPath, "api-token")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Zero(t, daemon.checks)
+	})
+}
+
+func TestHandler_AuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog := audit.NewLogger(path, 1, 1)
+	handler := api.NewHandler(&fakeDaemon{status: &api.Status{}}, "api-token", zap.NewNop())
+	handler.SetAuditLog(auditLog)
+
+	serve(handler, http.MethodGet, api.StatusPath, "api-token")
+	serveBody(handler, http.MethodPost, api.FailoverPath, "api-token", `{"reason": "maintenance"}`)
+	serve(handler, http.MethodPost, api.CheckPath, "wrong-token")
+	serveBody(handler, http.MethodPost, api.ForceFailoverPath, "api-token", `{"target": "tertiary"}`)
+	require.NoError(t, auditLog.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		delete(entry, "timestamp")
+		entries = append(entries, entry)
+	}
+
+	// Reading the status is not recorded, rejected requests are
+	assert.Equal(t, []map[string]any{
+		{"event": "api_request", "method": "POST", "path": api.FailoverPath, "requested_by": "192.0.2.1:1234", "reason": "maintenance", "status": float64(200)},
+		{"event": "api_request", "method": "POST", "path": api.CheckPath, "requested_by": "192.0.2.1:1234", "reason": "", "status": float64(401)},
+		{"event": "api_request", "method": "POST", "path": api.ForceFailoverPath, "requested_by": "192.0.2.1:1234", "reason": "", "status": float64(400)},
+	}, entries)
+}
+
+func TestHandler_Reload(t *testing.T) {
+	t.Run("returns the new configuration and its changes", func(t *testing.T) {
+		daemon := &fakeDaemon{reloadResult: &api.ReloadResult{
+			Config: map[string]interface{}{"poll_interval": "1m0s", "api_token": config.Redacted},
+			Changes: []config.Change{
+				{Path: "poll_interval", Kind: config.ChangeChanged, Old: "30s", New: "1m0s"},
+			},
+		}}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.ReloadPath, "api-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, daemon.reloads)
+		assert.JSONEq(t, `{
+			"config": {"poll_interval": "1m0s", "api_token": "[REDACTED]"},
+			"changes": [{"path": "poll_interval", "kind": "changed", "old": "30s", "new": "1m0s"}]
+		}`, rec.Body.String())
+	})
+
+	t.Run("no changes are an empty list", func(t *testing.T) {
+		daemon := &fakeDaemon{reloadResult: &api.ReloadResult{Config: map[string]interface{}{}}}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.ReloadPath, "api-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"changes": []`)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		daemon := &fakeDaemon{reloadErr: fmt.Errorf("failed to load configuration: poll_interval must be positive")}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.ReloadPath, "api-token")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error": "failed to load configuration: poll_interval must be positive"}`, rec.Body.String())
+	})
+
+	t.Run("requires the token", func(t *testing.T) {
+		daemon := &fakeDaemon{}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodPost, api.ReloadPath, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Zero(t, daemon.reloads)
+	})
+
+	t.Run("only POST", func(t *testing.T) {
+		daemon := &fakeDaemon{}
+		handler := api.NewHandler(daemon, "api-token", zap.NewNop())
+
+		rec := serve(handler, http.MethodGet, api.ReloadPath, "api-token")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Zero(t, daemon.reloads)
+	})
+}
+
+func TestListenAndServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- api.ListenAndServe(ctx, "127.0.0.1:0", handler, zap.NewNop())
+	}()
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+
+	t.Run("address in use", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		err := api.ListenAndServe(context.Background(), server.Listener.Addr().String(), handler, zap.NewNop())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout bounds waiting for in-flight requests when the server is stopped
+const shutdownTimeout = 5 * time.Second
+
+// ListenAndServe serves handler on addr until ctx is cancelled, then shuts the server down
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler, logger *zap.Logger) error {
+	// Create listener first to detect startup issues early
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger.Info("starting API server",
+		zap.String("addr", listener.Addr().String()),
+	)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		logger.Info("shutting down API server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
+}
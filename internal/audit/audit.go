@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Events recorded in the audit log
+const (
+	EventCreate = "create"
+	EventUpdate = "update"
+	EventDelete = "delete"
+
+	// EventAPIRequest is a request to the REST API that changes the daemon state, see Request
+	EventAPIRequest = "api_request"
+)
+
+// Change is a DNS change recorded in the audit log
+type Change struct {
+	Event    string // EventCreate, EventUpdate or EventDelete
+	Provider string
+	Record   string
+	FromIP   string // Value before the change, empty for created records
+	ToIP     string // Value after the change, empty for deleted records
+	TTL      int
+	Cached   bool // FromIP is the last known value from state, as the record was not read
+}
+
+// Request is a state-changing REST API request recorded in the audit log
+type Request struct {
+	Method      string
+	Path        string
+	RequestedBy string // Client address and user agent
+	Reason      string // Reason given by the client, if any
+	Status      int    // HTTP status of the response
+}
+
+// Logger writes DNS changes and REST API requests as JSON lines to a size-rotated file. A nil Logger discards them.
+type Logger struct {
+	logger *zap.Logger
+	file   *lumberjack.Logger
+}
+
+// NewLogger creates an audit logger appending to path. The file is rotated once it reaches
+// maxSizeMB megabytes, 0 meaning lumberjack's default of 100, and maxBackups rotated files are
+// kept, 0 keeping all of them. The file is opened on the first write.
+func NewLogger(path string, maxSizeMB, maxBackups int) *Logger {
+	file := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		MessageKey:     zapcore.OmitKey,
+		LevelKey:       zapcore.OmitKey,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(file), zapcore.InfoLevel)
+
+	return &Logger{logger: zap.New(core), file: file}
+}
+
+// Log records change
+func (l *Logger) Log(change Change) {
+	if l == nil {
+		return
+	}
+	l.logger.Info("",
+		zap.String("event", change.Event),
+		zap.String("provider", change.Provider),
+		zap.String("record", change.Record),
+		zap.String("from_ip", change.FromIP),
+		zap.String("to_ip", change.ToIP),
+		zap.Int("ttl", change.TTL),
+		zap.Bool("from_ip_cached", change.Cached),
+	)
+}
+
+// LogRequest records a state-changing REST API request
+func (l *Logger) LogRequest(req Request) {
+	if l == nil {
+		return
+	}
+	l.logger.Info("",
+		zap.String("event", EventAPIRequest),
+		zap.String("method", req.Method),
+		zap.String("path", req.Path),
+		zap.String("requested_by", req.RequestedBy),
+		zap.String("reason", req.Reason),
+		zap.Int("status", req.Status),
+	)
+}
+
+// Close flushes and closes the audit log file
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	_ = l.logger.Sync()
+	return l.file.Close()
+}
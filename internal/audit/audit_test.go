@@ -0,0 +1,94 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Log(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger := audit.NewLogger(path, 1, 2)
+
+	logger.Log(audit.Change{
+		Event:    audit.EventCreate,
+		Provider: "cloudflare",
+		Record:   "home.example.com",
+		ToIP:     "203.0.113.10",
+		TTL:      300,
+	})
+	logger.Log(audit.Change{
+		Event:    audit.EventUpdate,
+		Provider: "cloudflare",
+		Record:   "home.example.com",
+		FromIP:   "203.0.113.10",
+		ToIP:     "198.51.100.77",
+		TTL:      60,
+		Cached:   true,
+	})
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "update", entry["event"])
+	assert.Equal(t, "cloudflare", entry["provider"])
+	assert.Equal(t, "home.example.com", entry["record"])
+	assert.Equal(t, "203.0.113.10", entry["from_ip"])
+	assert.Equal(t, "198.51.100.77", entry["to_ip"])
+	assert.Equal(t, float64(60), entry["ttl"])
+	assert.Equal(t, true, entry["from_ip_cached"])
+	assert.NotContains(t, entry, "msg")
+	assert.NotContains(t, entry, "level")
+
+	timestamp, ok := entry["timestamp"].(string)
+	require.True(t, ok)
+	_, err = time.Parse("2006-01-02T15:04:05.000Z0700", timestamp)
+	assert.NoError(t, err)
+}
+
+func TestLogger_LogRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger := audit.NewLogger(path, 1, 2)
+
+	logger.LogRequest(audit.Request{
+		Method:      "POST",
+		Path:        "/api/v1/failover",
+		RequestedBy: "192.0.2.10:51234 curl/8.5.0",
+		Reason:      "maintenance",
+		Status:      200,
+	})
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(data, &entry))
+	delete(entry, "timestamp")
+	assert.Equal(t, map[string]any{
+		"event":        "api_request",
+		"method":       "POST",
+		"path":         "/api/v1/failover",
+		"requested_by": "192.0.2.10:51234 curl/8.5.0",
+		"reason":       "maintenance",
+		"status":       float64(200),
+	}, entry)
+}
+
+func TestLogger_Nil(t *testing.T) {
+	var logger *audit.Logger
+	logger.Log(audit.Change{Event: audit.EventDelete})
+	logger.LogRequest(audit.Request{Method: "POST"})
+	assert.NoError(t, logger.Close())
+}
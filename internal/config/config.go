@@ -1,96 +1,765 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/devhat/ipfailover/internal/netbind"
+	"github.com/devhat/ipfailover/pkg/interfaces"
 	"github.com/spf13/viper"
 )
 
+// Poll interval limits
+const (
+	// DefaultMaxPollInterval is the largest accepted poll interval unless max_poll_interval is set
+	DefaultMaxPollInterval = 24 * time.Hour
+
+	// ShortPollInterval is the poll interval below which a rate limit warning is reported
+	ShortPollInterval = 10 * time.Second
+
+	// minPollInterval is the smallest accepted poll interval. Shorter intervals are almost
+	// always durations written without a unit, such as 300, which are read as nanoseconds.
+	minPollInterval = time.Second
+)
+
+// IPv6StateName names the state file of the AAAA records in dual-stack mode, see GroupStateName
+const IPv6StateName = "ipv6"
+
 // Config represents the application configuration
 type Config struct {
 	// PollInterval is how often to check the IP address
-	PollInterval time.Duration `mapstructure:"poll_interval"`
+	PollInterval time.Duration `mapstructure:"poll_interval" desc:"How often to check the public IP address, e.g. 30s"`
+
+	// MaxPollInterval is the largest accepted PollInterval. It catches unit-less durations
+	// such as 300, which would otherwise be read as nanoseconds and rejected only by accident.
+	MaxPollInterval time.Duration `mapstructure:"max_poll_interval" desc:"Largest accepted poll_interval, e.g. 24h"`
 
 	// CheckEndpoints are the IP detection services to use
-	CheckEndpoints []string `mapstructure:"check_endpoints"`
+	CheckEndpoints []string `mapstructure:"check_endpoints" desc:"URLs of services that return the public IP address"`
 
 	// PrimaryIP is the primary IP address to use
-	PrimaryIP string `mapstructure:"primary_ip"`
+	PrimaryIP string `mapstructure:"primary_ip" desc:"IP address published while the primary connection is up"`
 
 	// SecondaryIP is the secondary IP address to use
-	SecondaryIP string `mapstructure:"secondary_ip"`
+	SecondaryIP string `mapstructure:"secondary_ip" desc:"IP address published after failing over"`
+
+	// FallbackIPs are tried in order after SecondaryIP when it is unreachable, see FallbackTargets
+	FallbackIPs []string `mapstructure:"fallback_ips" desc:"IP addresses tried in order after secondary_ip when it is unreachable" required:"false"`
+
+	// PrimaryIPv6 and SecondaryIPv6 enable dual-stack failover: AAAA records then fail over between
+	// them independently of the A records, which use PrimaryIP and SecondaryIP, see IsIPv6Record
+	PrimaryIPv6   string `mapstructure:"primary_ipv6" desc:"IPv6 address published in AAAA records while the primary connection is up, enables dual-stack failover"`
+	SecondaryIPv6 string `mapstructure:"secondary_ipv6" desc:"IPv6 address published in AAAA records after failing over"`
+
+	// CheckEndpointsIPv6 are the IP detection services queried over IPv6 in dual-stack mode.
+	// Empty uses CheckEndpoints.
+	CheckEndpointsIPv6 []string `mapstructure:"check_endpoints_ipv6" desc:"URLs of services that return the public IPv6 address, queried over IPv6 (default: check_endpoints)"`
+
+	// ProbeInterval is how often the primary and secondary IPs are probed for reachability,
+	// independently of PollInterval. Zero disables the background prober.
+	ProbeInterval time.Duration `mapstructure:"probe_interval" desc:"How often to probe primary and secondary reachability in the background, 0s disables"`
+
+	// ReachabilityInterval is how often the target IPs are determined between polls, without
+	// checking the public IP, so a failed primary is acted on before the next poll. Zero checks
+	// reachability on polls only.
+	ReachabilityInterval time.Duration `mapstructure:"reachability_interval" desc:"How often to check reachability and fail over between polls, e.g. 5s; 0s checks on polls only"`
+
+	// Reachability configures how the primary and secondary IPs are checked for reachability
+	Reachability ReachabilityConfig `mapstructure:"reachability" desc:"How the primary and secondary IPs are checked for reachability"`
+
+	// FailoverRetries is the number of consecutive failures before switching to secondary IP, at least 1
+	FailoverRetries int `mapstructure:"failover_retries" desc:"Consecutive failures before failing over to the secondary IP, at least 1"`
+
+	// FailbackDelay is how long the primary IP must have been reachable again before failing back to it.
+	// Zero fails back as soon as FailbackRetries is reached.
+	FailbackDelay time.Duration `mapstructure:"failback_delay" desc:"How long the primary IP must be reachable again before failing back, e.g. 10m; 0s fails back immediately"`
+
+	// FailbackRetries is the number of consecutive successful checks of the primary IP before failing back to it.
+	// Zero behaves like 1.
+	FailbackRetries int `mapstructure:"failback_retries" desc:"Consecutive successful checks of the primary IP before failing back"`
+
+	// EgressIPWeight makes the public IP detected by a poll count in the failover decision: equal to
+	// the secondary IP it counts as this many failures of the primary, equal to the primary IP as a
+	// success, without a reachability check. Other IPs leave the decision to the check. Zero disables.
+	EgressIPWeight int `mapstructure:"egress_ip_weight" desc:"Failures counted when the detected public IP is the secondary IP, which also counts as a success when it is the primary IP; 0 ignores the public IP"`
+
+	// PreFailoverTTL is the TTL in seconds that DNS records are lowered to once the primary failure count
+	// is one below FailoverRetries, so resolvers pick up a failover sooner. The configured TTLs are
+	// restored after failing back. Zero disables lowering.
+	PreFailoverTTL int `mapstructure:"pre_failover_ttl" desc:"TTL in seconds DNS records are lowered to one failure before failing over, 0 disables"`
+
+	// ChangeDebounceCount is the number of consecutive polls that must select the same new IP
+	// before DNS is changed to it. Zero behaves like 1, acting on the first poll.
+	ChangeDebounceCount int `mapstructure:"change_debounce_count" desc:"Consecutive polls that must select the same new IP before DNS is changed"`
+
+	// MinChangeInterval is the hold-down time after an IP change during which DNS is not changed again.
+	// With MinChangeIntervalFailbackOnly only failbacks to the primary IP are held down. Zero disables it.
+	MinChangeInterval             time.Duration `mapstructure:"min_change_interval" desc:"Hold-down time after an IP change before DNS may change again, e.g. 10m; 0s disables"`
+	MinChangeIntervalFailbackOnly bool          `mapstructure:"min_change_interval_failback_only" desc:"Apply min_change_interval to failbacks to the primary IP only, failing over is always allowed"`
+
+	// PreFailoverHook is a shell command run before DNS is changed to a new IP.
+	// A non-zero exit status aborts the change.
+	PreFailoverHook string `mapstructure:"pre_failover_hook" desc:"Shell command run before DNS is changed to a new IP; a non-zero exit status aborts the change"`
+
+	// PostFailoverHook is a shell command run after DNS was changed to a new IP.
+	// A non-zero exit status is only logged.
+	PostFailoverHook string `mapstructure:"post_failover_hook" desc:"Shell command run after DNS was changed to a new IP"`
+
+	// HookTimeout is how long a failover hook may run before it is killed. Zero disables the timeout.
+	HookTimeout time.Duration `mapstructure:"hook_timeout" desc:"How long a failover hook may run before it is killed, e.g. 30s; 0s disables the timeout"`
+
+	// ProviderMaxRetries is the number of times a failed DNS provider call is retried,
+	// for errors that are retryable such as HTTP 5xx and 429 responses. Zero disables retries.
+	ProviderMaxRetries int `mapstructure:"provider_max_retries" desc:"Retries of a DNS provider call that failed with a retryable error, 0 disables"`
+
+	// ProviderRetryBaseDelay is the delay before the first retry. It doubles with every further retry.
+	ProviderRetryBaseDelay time.Duration `mapstructure:"provider_retry_base_delay" desc:"Delay before the first retry of a DNS provider call, doubled for every further retry, e.g. 500ms"`
+
+	// ProviderRetryMaxDelay caps the delay between retries. Delays vary by ±10% jitter.
+	ProviderRetryMaxDelay time.Duration `mapstructure:"provider_retry_max_delay" desc:"Largest delay between retries of a DNS provider call, e.g. 30s"`
+
+	// CircuitBreakerThreshold is the number of consecutive failed calls to a DNS provider after
+	// which its circuit breaker opens and further calls fail immediately. Zero disables the breaker.
+	CircuitBreakerThreshold int `mapstructure:"circuit_breaker_threshold" desc:"Consecutive failed DNS provider calls that open the provider's circuit breaker, 0 disables"`
+
+	// CircuitBreakerCooldown is how long an open circuit breaker rejects calls before
+	// a single probe call is let through
+	CircuitBreakerCooldown time.Duration `mapstructure:"circuit_breaker_cooldown" desc:"How long an open circuit breaker rejects calls before probing the provider again, e.g. 60s"`
+
+	// PropagationCheck enables resolving updated records from PropagationResolvers until they
+	// return the new IP. Unconfirmed propagation is logged but does not fail the update.
+	PropagationCheck bool `mapstructure:"propagation_check" desc:"Confirm that updated records resolve to the new IP on the propagation resolvers"`
+
+	// PropagationResolvers are the DNS resolvers queried by the propagation check, as host:port
+	PropagationResolvers []string `mapstructure:"propagation_resolvers" desc:"DNS resolvers queried by the propagation check, as host:port"`
+
+	// PropagationCheckInterval is the time between lookups of a resolver that does not return the new IP yet
+	PropagationCheckInterval time.Duration `mapstructure:"propagation_check_interval" desc:"Time between propagation lookups of a resolver, e.g. 5s"`
+
+	// PropagationCheckTimeout is how long the propagation check polls before giving up
+	PropagationCheckTimeout time.Duration `mapstructure:"propagation_check_timeout" desc:"How long the propagation check polls before logging a warning, e.g. 2m"`
+
+	// MaxConcurrentUpdates limits how many DNS records are updated at the same time. Zero is unlimited.
+	MaxConcurrentUpdates int `mapstructure:"max_concurrent_updates" desc:"Maximum number of DNS records updated concurrently, 0 is unlimited"`
 
-	// FailoverRetries is the number of consecutive failures before switching to secondary IP
-	FailoverRetries int `mapstructure:"failover_retries"`
+	// IncidentThreshold is the number of consecutive failed DNS updates of a provider
+	// after which an incident is opened. Zero disables incidents.
+	IncidentThreshold int `mapstructure:"incident_threshold" desc:"Consecutive failed DNS updates of a provider before an incident is opened, 0 disables"`
+
+	// Notifications configures where failover notifications are delivered in addition to the log
+	Notifications NotificationsConfig `mapstructure:"notifications" desc:"Failover notification channels"`
 
 	// StateFailureStrategy defines how to handle state persistence failures
 	// Options: "fail_fast", "continue_with_warning", "immediate_failover"
-	StateFailureStrategy string `mapstructure:"state_failure_strategy"`
+	StateFailureStrategy string `mapstructure:"state_failure_strategy" desc:"How to handle state persistence failures" enum:"fail_fast,continue_with_warning,immediate_failover"`
+
+	// ConflictPolicy defines how to resolve a DNS record that was modified concurrently,
+	// for providers that support conditional updates
+	// Options: "ours-wins", "theirs-wins", "alert-only"
+	ConflictPolicy string `mapstructure:"conflict_policy" desc:"How to resolve DNS records modified concurrently" enum:"ours-wins,theirs-wins,alert-only"`
 
 	// StateFile is the path to the state persistence file
-	StateFile string `mapstructure:"state_file"`
+	StateFile string `mapstructure:"state_file" desc:"Path to the state persistence file"`
 
 	// MetricsAddr is the address for the metrics server
-	MetricsAddr string `mapstructure:"metrics_addr"`
+	MetricsAddr string `mapstructure:"metrics_addr" desc:"Listen address of the metrics server"`
+
+	// MetricsTLSCertFile and MetricsTLSKeyFile make the metrics server serve HTTPS, if both are set
+	MetricsTLSCertFile string `mapstructure:"metrics_tls_cert_file" desc:"PEM certificate file of the metrics server, serves HTTPS together with metrics_tls_key_file"`
+	MetricsTLSKeyFile  string `mapstructure:"metrics_tls_key_file" desc:"PEM private key file of the metrics server"`
+
+	// MetricsBasicAuthUser and MetricsBasicAuthPassword protect /metrics with HTTP Basic Auth, if set
+	MetricsBasicAuthUser     string `mapstructure:"metrics_basic_auth_user" desc:"User required by /metrics with HTTP Basic Auth, empty disables it"`
+	MetricsBasicAuthPassword string `mapstructure:"metrics_basic_auth_password" desc:"Password required by /metrics with HTTP Basic Auth" secret:"true"`
+
+	// MetricsAllowedCIDRs restricts the metrics server to clients in these ranges, all clients are allowed if empty
+	MetricsAllowedCIDRs []string `mapstructure:"metrics_allowed_cidrs" desc:"CIDR ranges or IP addresses of the clients allowed to use the metrics server, e.g. 10.0.0.0/8; empty allows all"`
+
+	// APIAddr is the address for the REST API server, which is disabled if empty
+	APIAddr string `mapstructure:"api_addr" desc:"Listen address of the REST API server, empty disables it"`
+
+	// APIToken authenticates REST API requests as a Bearer token
+	APIToken string `mapstructure:"api_token" desc:"Bearer token required by the REST API" secret:"true"`
 
 	// LogLevel is the logging level (debug, info, warn, error)
-	LogLevel string `mapstructure:"log_level"`
+	LogLevel string `mapstructure:"log_level" desc:"Logging level: debug, info, warn or error"`
+
+	// AuditLogFile is the path of the audit log, which records each DNS change as a JSON line.
+	// It is disabled if empty. The file is rotated once it reaches AuditLogMaxSizeMB, keeping
+	// AuditLogMaxBackups rotated files.
+	AuditLogFile       string `mapstructure:"audit_log_file" desc:"Path of the JSON lines audit log of DNS changes, empty disables it"`
+	AuditLogMaxSizeMB  int    `mapstructure:"audit_log_max_size_mb" desc:"Size in megabytes at which the audit log is rotated"`
+	AuditLogMaxBackups int    `mapstructure:"audit_log_max_backups" desc:"Rotated audit log files to keep, 0 keeps all"`
+
+	// OTelExporterEndpoint is the OTLP gRPC endpoint traces of DNS provider calls and reachability
+	// checks are exported to, tracing is disabled if empty
+	OTelExporterEndpoint string `mapstructure:"otel_exporter_endpoint" desc:"OTLP gRPC endpoint of the trace collector, host:port with TLS or an http:// or https:// URL; empty disables tracing"`
+
+	// FleetRandomization spreads the load of many instances sharing a configuration: the check endpoint
+	// order is shuffled per process and polls are offset within the poll interval by a hash of InstanceName
+	FleetRandomization bool `mapstructure:"fleet_randomization" desc:"Shuffle check endpoints and offset the poll phase per instance"`
+
+	// InstanceName identifies this instance, it defaults to the hostname
+	InstanceName string `mapstructure:"instance_name" desc:"Name of this instance, used to derive its poll phase; defaults to the hostname"`
+
+	// ProxyURL is the outbound proxy of IP checks and DNS provider API requests. Empty uses the
+	// proxy environment variables, ProxyDirect connects directly. Records can override it in their http block.
+	ProxyURL string `mapstructure:"proxy_url" desc:"Proxy of IP checks and provider API requests, e.g. http://proxy:3128 or socks5://proxy:1080; direct ignores the proxy environment variables" secret:"true"`
+
+	// NoProxy lists the hosts reached without ProxyURL, in the format of NO_PROXY
+	NoProxy []string `mapstructure:"no_proxy" desc:"Hosts, domains (.example.com) and CIDR ranges reached without proxy_url"`
+
+	// SourceIP and SourceInterface bind the connections of IP checks and reachability checks to a
+	// local address and network interface, to test a chosen uplink of a multi-homed host
+	SourceIP        string `mapstructure:"source_ip" desc:"Local IP address IP checks and reachability checks connect from"`
+	SourceInterface string `mapstructure:"source_interface" desc:"Network interface IP checks and reachability checks are bound to (Linux only)"`
 
 	// DNS records to manage
-	DNS []DNSConfig `mapstructure:"dns"`
+	DNS []DNSConfig `mapstructure:"dns" desc:"DNS records to manage"`
 }
 
 // DNSConfig represents configuration for a DNS record
 type DNSConfig struct {
-	Name     string            `mapstructure:"name"`
-	Type     string            `mapstructure:"type"`
-	Provider string            `mapstructure:"provider"`
-	TTL      int               `mapstructure:"ttl"`
-	Metadata map[string]string `mapstructure:"metadata"`
+	ID       string            `mapstructure:"id" desc:"Unique identifier of the record entry in logs, needed to manage the same record name through several providers of one type (default: name/type/provider)"`
+	Name     string            `mapstructure:"name" desc:"Fully qualified record name" required:"true"`
+	Type     string            `mapstructure:"type" desc:"Record type, e.g. A or AAAA" required:"true"`
+	Provider string            `mapstructure:"provider" desc:"DNS provider managing the record" required:"true"`
+	TTL      int               `mapstructure:"ttl" desc:"Record TTL in seconds" required:"true"`
+	Metadata map[string]string `mapstructure:"metadata" desc:"Free-form key/value pairs attached to the record"`
+
+	// Failover overrides. A record that sets any of them fails over on its own, with the unset
+	// ones taken from the global configuration, see Config.ForRecord.
+	PrimaryIP       string        `mapstructure:"primary_ip" desc:"IP address published for this record while its primary is up, overrides the global primary_ip"`
+	SecondaryIP     string        `mapstructure:"secondary_ip" desc:"IP address published for this record after failing over, overrides the global secondary_ip"`
+	FailoverRetries int           `mapstructure:"failover_retries" desc:"Consecutive failures before this record fails over, overrides the global failover_retries"`
+	PollInterval    time.Duration `mapstructure:"poll_interval" desc:"How often this record is checked, at least the global poll_interval, e.g. 5m"`
+
+	// HTTP tunes the provider's API client, for providers that support it
+	HTTP HTTPConfig `mapstructure:"http" desc:"Timeout and retries (cpanel) and proxy of requests to the provider API"`
 
 	// Provider-specific configuration
-	Cloudflare *CloudflareConfig `mapstructure:"cloudflare,omitempty"`
-	CPanel     *CPanelConfig     `mapstructure:"cpanel,omitempty"`
-	Route53    *Route53Config    `mapstructure:"route53,omitempty"`
-	Hetzner    *HetznerConfig    `mapstructure:"hetzner,omitempty"`
+	Cloudflare   *CloudflareConfig   `mapstructure:"cloudflare,omitempty" desc:"Cloudflare settings"`
+	CPanel       *CPanelConfig       `mapstructure:"cpanel,omitempty" desc:"cPanel settings"`
+	Route53      *Route53Config      `mapstructure:"route53,omitempty" desc:"AWS Route53 settings"`
+	Hetzner      *HetznerConfig      `mapstructure:"hetzner,omitempty" desc:"Hetzner DNS settings"`
+	AliDNS       *AliDNSConfig       `mapstructure:"alidns,omitempty" desc:"Alibaba Cloud DNS settings"`
+	Netcup       *NetcupConfig       `mapstructure:"netcup,omitempty" desc:"netcup CCP DNS settings"`
+	Namecom      *NamecomConfig      `mapstructure:"namecom,omitempty" desc:"Name.com settings"`
+	AdGuard      *AdGuardConfig      `mapstructure:"adguard,omitempty" desc:"AdGuard Home settings"`
+	TransIP      *TransIPConfig      `mapstructure:"transip,omitempty" desc:"TransIP settings"`
+	MythicBeasts *MythicBeastsConfig `mapstructure:"mythicbeasts,omitempty" desc:"Mythic Beasts settings"`
+	Domeneshop   *DomeneshopConfig   `mapstructure:"domeneshop,omitempty" desc:"Domeneshop settings"`
+	EasyDNS      *EasyDNSConfig      `mapstructure:"easydns,omitempty" desc:"EasyDNS settings"`
+	Loopia       *LoopiaConfig       `mapstructure:"loopia,omitempty" desc:"Loopia settings"`
+	Infomaniak   *InfomaniakConfig   `mapstructure:"infomaniak,omitempty" desc:"Infomaniak settings"`
+	Dynu         *DynuConfig         `mapstructure:"dynu,omitempty" desc:"Dynu settings"`
+	Webhook      *WebhookConfig      `mapstructure:"webhook,omitempty" desc:"Generic HTTP webhook settings"`
+	Plugin       *PluginConfig       `mapstructure:"plugin,omitempty" desc:"External provider plugin settings"`
+}
+
+// HTTPConfig represents the timeout and retries of requests to a DNS provider API. Requests that fail
+// with a network error or a retryable status, such as 429 or 5xx, are retried with exponential backoff.
+type HTTPConfig struct {
+	Timeout        time.Duration `mapstructure:"timeout" desc:"Timeout of each request, defaults to 30s"`
+	MaxRetries     int           `mapstructure:"max_retries" desc:"Retries of a failed request, 0 disables retries"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" desc:"Delay before the first retry, doubled with every further retry, defaults to 1s"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" desc:"Maximum delay between retries, also caps Retry-After, defaults to 30s"`
+	ProxyURL       string        `mapstructure:"proxy_url" desc:"Proxy of the provider API requests, overrides the global proxy_url; direct connects directly" secret:"true"`
+}
+
+// Validate checks the HTTP settings
+func (h *HTTPConfig) Validate() error {
+	if h.Timeout < 0 {
+		return fmt.Errorf("timeout must be non-negative")
+	}
+
+	if h.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must be non-negative")
+	}
+
+	if h.InitialBackoff < 0 {
+		return fmt.Errorf("initial_backoff must be non-negative")
+	}
+
+	if h.MaxBackoff < 0 {
+		return fmt.Errorf("max_backoff must be non-negative")
+	}
+
+	if h.MaxBackoff > 0 && h.InitialBackoff > h.MaxBackoff {
+		return fmt.Errorf("initial_backoff (%s) must not exceed max_backoff (%s)", h.InitialBackoff, h.MaxBackoff)
+	}
+
+	return validateProxyURL(h.ProxyURL)
+}
+
+// ReachabilityConfig represents how an IP is checked for reachability. Zero values select the
+// defaults: a TCP connect to port 80 with a single attempt timing out after 3s.
+type ReachabilityConfig struct {
+	Protocol string           `mapstructure:"protocol" desc:"tcp (connect), tls (handshake), http (health check request) or icmp (echo), defaults to tcp"`
+	Ports    []int            `mapstructure:"ports" desc:"Ports checked with tcp, tls and http, defaults to 80"`
+	Policy   string           `mapstructure:"policy" desc:"any: one reachable port suffices, all: every port must be reachable; defaults to any"`
+	Timeout  time.Duration    `mapstructure:"timeout" desc:"Timeout of each attempt, defaults to 3s"`
+	Attempts int              `mapstructure:"attempts" desc:"Attempts per port and check before the port counts as unreachable, defaults to 1"`
+	HTTP     *HTTPCheckConfig `mapstructure:"http,omitempty" desc:"Health check request of the http protocol"`
+	ICMP     *ICMPCheckConfig `mapstructure:"icmp,omitempty" desc:"Echo requests of the icmp protocol"`
+	Budget   time.Duration    `mapstructure:"budget" desc:"Time limit of the reachability checks of a poll, defaults to 5s or the worst case of the configured attempts if longer"`
+}
+
+// ICMPCheckConfig represents the echo requests of the icmp reachability protocol. Raw ICMP sockets
+// are used when running as root or with CAP_NET_RAW, unprivileged datagram sockets otherwise.
+type ICMPCheckConfig struct {
+	Count         int           `mapstructure:"count" desc:"Echo requests per attempt, defaults to 3"`
+	Interval      time.Duration `mapstructure:"interval" desc:"Time between the echo requests of an attempt, defaults to 200ms"`
+	LossThreshold int           `mapstructure:"loss_threshold" desc:"Packet loss in percent at which an attempt fails, defaults to 100 so a single reply suffices"`
 }
 
+// Validate checks the echo request settings
+func (i *ICMPCheckConfig) Validate() error {
+	if i.Count < 0 {
+		return fmt.Errorf("count must be non-negative")
+	}
+
+	if i.Interval < 0 {
+		return fmt.Errorf("interval must be non-negative")
+	}
+
+	if i.LossThreshold < 0 || i.LossThreshold > 100 {
+		return fmt.Errorf("loss_threshold must be between 0 and 100 percent, got %d", i.LossThreshold)
+	}
+
+	return nil
+}
+
+// HTTPCheckConfig represents the health check request of the http reachability protocol. Without
+// a URL, / is requested from each port over plain HTTP.
+type HTTPCheckConfig struct {
+	URL                string `mapstructure:"url" desc:"URL requested from the checked IP, e.g. https://www.example.com/healthz; its host is kept in the Host header and TLS server name, and its port replaces ports"`
+	ExpectedStatus     []int  `mapstructure:"expected_status" desc:"Status codes of a healthy response, defaults to any 2xx or 3xx status"`
+	BodyContains       string `mapstructure:"body_contains" desc:"Text a healthy response body must contain"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify" desc:"Do not verify the certificate of an https URL"`
+}
+
+// Validate checks the health check request
+func (h *HTTPCheckConfig) Validate() error {
+	if h.URL != "" {
+		parsed, err := url.Parse(h.URL)
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+			return fmt.Errorf("url must be an http or https URL with a host, got %q", h.URL)
+		}
+	}
+
+	for _, status := range h.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("expected status %d is not an HTTP status code", status)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the reachability settings
+func (r *ReachabilityConfig) Validate() error {
+	switch r.Protocol {
+	case "", "tcp", "tls", "http", "icmp":
+	default:
+		return fmt.Errorf("protocol must be tcp, tls, http or icmp, got %q", r.Protocol)
+	}
+
+	for _, port := range r.Ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("port %d is out of range", port)
+		}
+	}
+
+	switch r.Policy {
+	case "", "any", "all":
+	default:
+		return fmt.Errorf("policy must be any or all, got %q", r.Policy)
+	}
+
+	if r.Timeout < 0 {
+		return fmt.Errorf("timeout must be non-negative")
+	}
+
+	if r.Attempts < 0 {
+		return fmt.Errorf("attempts must be non-negative")
+	}
+
+	if r.Budget < 0 {
+		return fmt.Errorf("budget must be non-negative")
+	}
+
+	if r.HTTP != nil {
+		if err := r.HTTP.Validate(); err != nil {
+			return fmt.Errorf("http: %w", err)
+		}
+	}
+
+	if r.ICMP != nil {
+		if err := r.ICMP.Validate(); err != nil {
+			return fmt.Errorf("icmp: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// NotificationsConfig represents the channels failover notifications are delivered to
+type NotificationsConfig struct {
+	// Email sends notifications by SMTP, nil disables it
+	Email *EmailConfig `mapstructure:"email,omitempty" desc:"SMTP email notification settings"`
+
+	// PagerDuty triggers an alert on failover and resolves it on failback, nil disables it
+	PagerDuty *PagerDutyConfig `mapstructure:"pagerduty,omitempty" desc:"PagerDuty Events API v2 settings"`
+
+	// Webhooks receive every notification event as JSON, e.g. for ops tooling
+	Webhooks []NotificationWebhookConfig `mapstructure:"webhooks" desc:"HTTP endpoints receiving notification events as JSON"`
+}
+
+// EmailConfig represents SMTP email notification configuration
+type EmailConfig struct {
+	SMTPHost     string   `mapstructure:"smtp_host" desc:"SMTP server host name" required:"true"`
+	SMTPPort     int      `mapstructure:"smtp_port" desc:"SMTP server port, defaults to 465 with tls_enabled and 587 otherwise"`
+	SMTPUsername string   `mapstructure:"smtp_username" desc:"SMTP user name, empty disables authentication"`
+	SMTPPassword string   `mapstructure:"smtp_password" desc:"SMTP password" secret:"true"`
+	From         string   `mapstructure:"from" desc:"Sender address" required:"true"`
+	To           []string `mapstructure:"to" desc:"Recipient addresses" required:"true"`
+
+	// TLSEnabled connects with implicit TLS. Otherwise the connection is upgraded with STARTTLS if the server offers it.
+	TLSEnabled bool `mapstructure:"tls_enabled" desc:"Connect with implicit TLS, e.g. on port 465, instead of upgrading with STARTTLS"`
+
+	// TestNotification sends a test email on startup, to check the settings
+	TestNotification bool `mapstructure:"test_notification" desc:"Send a test email on startup"`
+
+	// SubjectTemplate renders the subject of event emails from the notification event, see ParseSubjectTemplate
+	SubjectTemplate string `mapstructure:"subject_template" desc:"Template of the subject of event emails, with the event fields .Type, .FromIP, .ToIP, .Records and .Message" example:"[ipfailover] {{.Type}} to {{.ToIP}}" required:"false"`
+
+	// DailyDigest sends a summary of the checks, IP changes and errors of the past day at DigestTime
+	DailyDigest bool   `mapstructure:"daily_digest" desc:"Send a daily summary of check counts and errors"`
+	DigestTime  string `mapstructure:"digest_time" desc:"Local time of day the daily digest is sent, HH:MM, defaults to 08:00" example:"08:00" required:"false"`
+}
+
+// NotificationWebhookConfig represents an HTTP endpoint receiving notification events as JSON.
+// With a secret, the body is signed with HMAC-SHA256 so the receiver can authenticate it.
+type NotificationWebhookConfig struct {
+	URL     string            `mapstructure:"url" desc:"URL the events are sent to" required:"true"`
+	Method  string            `mapstructure:"method" desc:"Request method, defaults to POST" enum:"POST,PUT,PATCH"`
+	Headers map[string]string `mapstructure:"headers" desc:"Headers sent with every request, e.g. for authentication" secret:"true"`
+	Secret  string            `mapstructure:"secret" desc:"Key signing the request body with HMAC-SHA256 in the X-Ipfailover-Signature header" secret:"true"`
+}
+
+// PagerDutyConfig represents PagerDuty Events API v2 configuration
+type PagerDutyConfig struct {
+	IntegrationKey string `mapstructure:"integration_key" desc:"Integration key of the PagerDuty service" required:"true" secret:"true"`
+	Severity       string `mapstructure:"severity" desc:"Severity of failover alerts, defaults to critical" enum:"critical,error,warning,info"`
+	Endpoint       string `mapstructure:"endpoint" desc:"Events API URL, defaults to https://events.pagerduty.com/v2/enqueue"`
+}
+
+// Configuration fields also carry JSON Schema metadata in struct tags, see GenerateSchema:
+// desc is a short description, enum lists the allowed values and required marks mandatory keys.
+
+// Provider configuration fields carry registry metadata in struct tags:
+// desc is a short description, example is a sample value and secret marks credentials.
+// See Providers.
+
 // CloudflareConfig represents Cloudflare-specific configuration
 type CloudflareConfig struct {
-	APIToken string `mapstructure:"api_token"`
-	ZoneID   string `mapstructure:"zone_id"`
-	Proxied  bool   `mapstructure:"proxied"`
+	APIToken string `mapstructure:"api_token" desc:"API token with Zone.DNS edit permission, or else set api_key and api_email" example:"${CLOUDFLARE_API_TOKEN}" secret:"true" required:"false"`
+
+	// APIKey and APIEmail authenticate with the legacy Global API Key of the account instead of APIToken
+	APIKey   string `mapstructure:"api_key" desc:"Global API Key of the account, used with api_email instead of api_token" example:"${CLOUDFLARE_API_KEY}" secret:"true" required:"false"`
+	APIEmail string `mapstructure:"api_email" desc:"Email address of the account of api_key" example:"${CLOUDFLARE_EMAIL}" required:"false"`
+
+	ZoneID   string `mapstructure:"zone_id" desc:"Zone ID of the domain, looked up from the account if empty" example:"${CLOUDFLARE_ZONE_ID}" required:"false"`
+	ZoneName string `mapstructure:"zone_name" desc:"Zone name to look up when zone_id is empty, defaults to the account zone containing the record" example:"example.com" required:"false"`
+	Proxied  bool   `mapstructure:"proxied" desc:"Proxy traffic through Cloudflare" example:"false" required:"false"`
+
+	// Per-role overrides of Proxied, applied when failover writes the primary or secondary IP
+	ProxiedPrimary   *bool `mapstructure:"proxied_primary" desc:"Proxy traffic through Cloudflare while the record points to the primary IP, defaults to proxied" example:"true" required:"false"`
+	ProxiedSecondary *bool `mapstructure:"proxied_secondary" desc:"Proxy traffic through Cloudflare while the record points to the secondary IP, defaults to proxied" example:"false" required:"false"`
 }
 
 // CPanelConfig represents cPanel-specific configuration
 type CPanelConfig struct {
-	BaseURL  string `mapstructure:"base_url"`
-	Username string `mapstructure:"username"`
-	APIToken string `mapstructure:"api_token"`
-	Zone     string `mapstructure:"zone"`
+	BaseURL  string `mapstructure:"base_url" desc:"cPanel base URL including port" example:"https://cpanel.example.com:2083"`
+	Username string `mapstructure:"username" desc:"cPanel account username" example:"${CPANEL_USERNAME}"`
+	APIToken string `mapstructure:"api_token" desc:"cPanel API token" example:"${CPANEL_API_TOKEN}" secret:"true"`
+	Zone     string `mapstructure:"zone" desc:"DNS zone containing the record" example:"example.com"`
+
+	// UseWHM calls the DNS functions through WHM on behalf of the cPanel account Username, for
+	// resellers and root. BaseURL is then the WHM URL and APIToken a WHM API token of WHMUsername.
+	UseWHM      bool   `mapstructure:"use_whm" desc:"Call the cPanel account's DNS functions through WHM, with a WHM API token" example:"true" required:"false"`
+	WHMUsername string `mapstructure:"whm_username" desc:"WHM user owning api_token with use_whm, defaults to root" example:"root"`
+
+	// TLS configures connections to servers with a certificate from an internal CA, or mutual TLS
+	TLS *TLSConfig `mapstructure:"tls,omitempty" desc:"TLS settings of the cPanel API connection"`
+}
+
+// WHMUser returns the WHM user authenticating the API calls with UseWHM, root unless WHMUsername is set
+func (c *CPanelConfig) WHMUser() string {
+	if c.WHMUsername == "" {
+		return "root"
+	}
+	return c.WHMUsername
 }
 
 // Route53Config represents Route53-specific configuration
 type Route53Config struct {
-	AccessKeyID     string `mapstructure:"access_key_id"`
-	SecretAccessKey string `mapstructure:"secret_access_key"`
-	Region          string `mapstructure:"region"`
-	HostedZoneID    string `mapstructure:"hosted_zone_id"`
+	AccessKeyID     string `mapstructure:"access_key_id" desc:"AWS access key ID, empty uses the default AWS credential chain" example:"${AWS_ACCESS_KEY_ID}" secret:"true" required:"false"`
+	SecretAccessKey string `mapstructure:"secret_access_key" desc:"AWS secret access key, set together with access_key_id" example:"${AWS_SECRET_ACCESS_KEY}" secret:"true" required:"false"`
+	Region          string `mapstructure:"region" desc:"AWS region" example:"us-east-1"`
+	HostedZoneID    string `mapstructure:"hosted_zone_id" desc:"Route53 hosted zone ID" example:"Z1234567890ABC"`
+
+	// RoleARN is assumed with the base credentials, from the access key or the default chain
+	RoleARN    string `mapstructure:"role_arn" desc:"IAM role to assume for Route53 access, e.g. in another account" example:"arn:aws:iam::123456789012:role/dns-failover" required:"false"`
+	ExternalID string `mapstructure:"external_id" desc:"External ID required by the trust policy of role_arn" example:"${AWS_EXTERNAL_ID}"`
+
+	// RoleSessionName identifies the sessions of the assumed role in CloudTrail, empty uses "ipfailover"
+	RoleSessionName string `mapstructure:"role_session_name" desc:"Session name of the assumed role, shown in CloudTrail (default ipfailover)" example:"ipfailover-edge-1"`
+
+	// WaitForSync makes updates wait until Route53 reports the change INSYNC on all authoritative servers
+	WaitForSync bool          `mapstructure:"wait_for_sync" desc:"Wait until changes are in sync on all Route53 authoritative servers" example:"true"`
+	SyncTimeout time.Duration `mapstructure:"sync_timeout" desc:"How long to wait for a change to be in sync, defaults to 2m" example:"2m"`
+
+	// Alias targets published instead of the IP while the record points to the primary or secondary IP,
+	// e.g. a load balancer on the primary site. Without one, the record is a plain record with the IP.
+	AliasTargetDNSName          string `mapstructure:"alias_target_dns_name" desc:"DNS name of the alias target published while the record points to the primary IP, e.g. a load balancer" example:"dualstack.primary-123456.us-east-1.elb.amazonaws.com." required:"false"`
+	AliasHostedZoneID           string `mapstructure:"alias_hosted_zone_id" desc:"Hosted zone ID of alias_target_dns_name" example:"Z35SXDOTRQ7X7K" required:"false"`
+	SecondaryAliasTargetDNSName string `mapstructure:"secondary_alias_target_dns_name" desc:"DNS name of the alias target published while the record points to the secondary IP" example:"dualstack.secondary-654321.eu-west-1.elb.amazonaws.com." required:"false"`
+	SecondaryAliasHostedZoneID  string `mapstructure:"secondary_alias_hosted_zone_id" desc:"Hosted zone ID of secondary_alias_target_dns_name" example:"Z32O12XQLNTSW2" required:"false"`
+	EvaluateTargetHealth        bool   `mapstructure:"evaluate_target_health" desc:"Let Route53 evaluate the health of alias targets" example:"true"`
+
+	// Routing of the record set. SetIdentifier selects one of several record sets with the name and
+	// type of the record, Failover makes it a record set of a Route53 failover routing policy and
+	// HealthCheckID associates a Route53 health check with it. Set values replace those of the
+	// existing record set, unset ones are preserved.
+	SetIdentifier string `mapstructure:"set_identifier" desc:"Set identifier of the record set, for records with a routing policy" example:"primary-site" required:"false"`
+	Failover      string `mapstructure:"failover" desc:"Role of the record set in a Route53 failover routing policy, requires set_identifier" enum:"PRIMARY,SECONDARY" example:"PRIMARY" required:"false"`
+	HealthCheckID string `mapstructure:"health_check_id" desc:"ID of the Route53 health check associated with the record set" example:"abcdef11-2222-3333-4444-555555fedcba" required:"false"`
 }
 
 // HetznerConfig represents Hetzner DNS-specific configuration
 type HetznerConfig struct {
-	APIToken string `mapstructure:"api_token"`
-	ZoneID   string `mapstructure:"zone_id"`
+	APIToken string `mapstructure:"api_token" desc:"Hetzner DNS API token" example:"${HETZNER_API_TOKEN}" secret:"true"`
+	ZoneID   string `mapstructure:"zone_id" desc:"Hetzner DNS zone ID" example:"${HETZNER_ZONE_ID}"`
+	API      string `mapstructure:"api" desc:"Hetzner API managing the zone: cloud (default) or console for the DNS Console at dns.hetzner.com" example:"cloud"`
+	Zone     string `mapstructure:"zone" desc:"Zone name, looked up when zone_id is not set (console API only)" example:"example.com"`
+	Endpoint string `mapstructure:"endpoint" desc:"DNS Console API endpoint, defaults to https://dns.hetzner.com/api/v1" example:"https://dns.hetzner.com/api/v1"`
+}
+
+// Hetzner APIs selectable with HetznerConfig.API
+const (
+	HetznerAPICloud   = "cloud"
+	HetznerAPIConsole = "console"
+)
+
+// AliDNSConfig represents Alibaba Cloud DNS-specific configuration
+type AliDNSConfig struct {
+	AccessKeyID     string `mapstructure:"access_key_id" desc:"Alibaba Cloud AccessKey ID" example:"${ALIDNS_ACCESS_KEY_ID}" secret:"true"`
+	AccessKeySecret string `mapstructure:"access_key_secret" desc:"Alibaba Cloud AccessKey secret" example:"${ALIDNS_ACCESS_KEY_SECRET}" secret:"true"`
+	Domain          string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.com"`
+	Endpoint        string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://alidns.aliyuncs.com" example:"https://alidns.aliyuncs.com"`
+}
+
+// NetcupConfig represents netcup CCP DNS-specific configuration
+type NetcupConfig struct {
+	CustomerNumber string `mapstructure:"customer_number" desc:"netcup customer number" example:"${NETCUP_CUSTOMER_NUMBER}"`
+	APIKey         string `mapstructure:"api_key" desc:"CCP API key" example:"${NETCUP_API_KEY}" secret:"true"`
+	APIPassword    string `mapstructure:"api_password" desc:"CCP API password" example:"${NETCUP_API_PASSWORD}" secret:"true"`
+	Domain         string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.com"`
+	Endpoint       string `mapstructure:"endpoint" desc:"API endpoint, defaults to the netcup CCP JSON endpoint" example:"https://ccp.netcup.net/run/webservice/servers/endpoint.php?JSON"`
+}
+
+// NamecomConfig represents Name.com-specific configuration
+type NamecomConfig struct {
+	Username string `mapstructure:"username" desc:"Name.com account username" example:"${NAMECOM_USERNAME}"`
+	APIToken string `mapstructure:"api_token" desc:"Name.com API token" example:"${NAMECOM_API_TOKEN}" secret:"true"`
+	Domain   string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.com"`
+	Endpoint string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://api.name.com" example:"https://api.dev.name.com"`
+}
+
+// AdGuardConfig represents AdGuard Home DNS rewrite-specific configuration
+type AdGuardConfig struct {
+	BaseURL  string `mapstructure:"base_url" desc:"AdGuard Home web interface URL" example:"http://192.168.1.2:3000"`
+	Username string `mapstructure:"username" desc:"AdGuard Home username" example:"${ADGUARD_USERNAME}"`
+	Password string `mapstructure:"password" desc:"AdGuard Home password" example:"${ADGUARD_PASSWORD}" secret:"true"`
+
+	// TLS configures connections to servers with a certificate from an internal CA, or mutual TLS
+	TLS *TLSConfig `mapstructure:"tls,omitempty" desc:"TLS settings of the AdGuard Home API connection"`
+}
+
+// TransIPConfig represents TransIP-specific configuration
+type TransIPConfig struct {
+	Login      string `mapstructure:"login" desc:"TransIP account login name" example:"${TRANSIP_LOGIN}"`
+	PrivateKey string `mapstructure:"private_key" desc:"Path to the API private key file, or the PEM encoded key itself" example:"/etc/ipfailover/transip.key" secret:"true"`
+	Domain     string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.com"`
+	Endpoint   string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://api.transip.nl/v6" example:"https://api.transip.nl/v6"`
+}
+
+// MythicBeastsConfig represents Mythic Beasts DNS API v2 configuration
+type MythicBeastsConfig struct {
+	KeyID        string `mapstructure:"key_id" desc:"API key ID" example:"${MYTHICBEASTS_KEY_ID}"`
+	Secret       string `mapstructure:"secret" desc:"API key secret" example:"${MYTHICBEASTS_SECRET}" secret:"true"`
+	Zone         string `mapstructure:"zone" desc:"Zone containing the record" example:"example.com"`
+	Endpoint     string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://api.mythic-beasts.com/dns/v2" example:"https://api.mythic-beasts.com/dns/v2"`
+	AuthEndpoint string `mapstructure:"auth_endpoint" desc:"OAuth2 token endpoint, defaults to https://auth.mythic-beasts.com/login" example:"https://auth.mythic-beasts.com/login"`
+}
+
+// DomeneshopConfig represents Domeneshop API configuration
+type DomeneshopConfig struct {
+	Token    string `mapstructure:"token" desc:"API token" example:"${DOMENESHOP_TOKEN}"`
+	Secret   string `mapstructure:"secret" desc:"API secret" example:"${DOMENESHOP_SECRET}" secret:"true"`
+	Domain   string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.no"`
+	Endpoint string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://api.domeneshop.no/v0" example:"https://api.domeneshop.no/v0"`
+}
+
+// EasyDNSConfig represents EasyDNS REST API configuration
+type EasyDNSConfig struct {
+	Token    string `mapstructure:"token" desc:"API token" example:"${EASYDNS_TOKEN}"`
+	Key      string `mapstructure:"key" desc:"API key" example:"${EASYDNS_KEY}" secret:"true"`
+	Domain   string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.com"`
+	Endpoint string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://rest.easydns.net" example:"https://sandbox.rest.easydns.net"`
+}
+
+// LoopiaConfig represents Loopia XML-RPC API configuration
+type LoopiaConfig struct {
+	Username string `mapstructure:"username" desc:"API user, e.g. user@loopiaapi" example:"${LOOPIA_USERNAME}"`
+	Password string `mapstructure:"password" desc:"API user password" example:"${LOOPIA_PASSWORD}" secret:"true"`
+	Domain   string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.se"`
+	Endpoint string `mapstructure:"endpoint" desc:"XML-RPC endpoint, defaults to https://api.loopia.se/RPCSERV" example:"https://api.loopia.rs/RPCSERV"`
+}
+
+// InfomaniakConfig represents Infomaniak API configuration
+type InfomaniakConfig struct {
+	APIToken string `mapstructure:"api_token" desc:"API token with the domain scope" example:"${INFOMANIAK_API_TOKEN}" secret:"true"`
+	Zone     string `mapstructure:"zone" desc:"Zone containing the record" example:"example.ch"`
+	Endpoint string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://api.infomaniak.com" example:"https://api.infomaniak.com"`
+}
+
+// DynuConfig represents Dynu API v2 configuration
+type DynuConfig struct {
+	APIKey   string `mapstructure:"api_key" desc:"API key" example:"${DYNU_API_KEY}" secret:"true"`
+	Domain   string `mapstructure:"domain" desc:"Domain (zone) containing the record" example:"example.com"`
+	Endpoint string `mapstructure:"endpoint" desc:"API endpoint, defaults to https://api.dynu.com/v2" example:"https://api.dynu.com/v2"`
+}
+
+// WebhookConfig represents a generic HTTP webhook for DNS backends without a dedicated provider.
+// URLs, header values and bodies are templates rendered with a WebhookRecord, see ParseTemplates.
+type WebhookConfig struct {
+	URL           string            `mapstructure:"url" desc:"Update request URL template" example:"https://ipam.example.com/api/records/{{.Name}}/{{.Type}}"`
+	Method        string            `mapstructure:"method" desc:"Update request method, defaults to POST" example:"PUT"`
+	Headers       map[string]string `mapstructure:"headers" desc:"Header templates sent with every request" example:"Authorization: Bearer ${WEBHOOK_TOKEN}" secret:"true"`
+	Body          string            `mapstructure:"body" desc:"Update request body template" example:"{\"value\": {{json .Value}}, \"ttl\": {{.TTL}}}"`
+	GetURL        string            `mapstructure:"get_url" desc:"URL template of a GET request returning the record as JSON" example:"https://ipam.example.com/api/records/{{.Name}}/{{.Type}}"`
+	GetValueField string            `mapstructure:"get_value_field" desc:"Dot-separated path of the record value in the get response, defaults to value" example:"value"`
+	DeleteURL     string            `mapstructure:"delete_url" desc:"Delete request URL template" example:"https://ipam.example.com/api/records/{{.Name}}/{{.Type}}"`
+	DeleteMethod  string            `mapstructure:"delete_method" desc:"Delete request method, defaults to DELETE" example:"DELETE"`
+	ValidateURL   string            `mapstructure:"validate_url" desc:"URL of a GET request that checks access at startup" example:"https://ipam.example.com/api/health"`
+
+	// TLS configures connections to servers with a certificate from an internal CA, or mutual TLS
+	TLS *TLSConfig `mapstructure:"tls,omitempty" desc:"TLS settings of the webhook requests"`
+}
+
+// PluginConfig represents an external DNS provider plugin, an executable serving the
+// gRPC protocol of pkg/plugin. Env is added to the plugin's environment, for its own settings.
+type PluginConfig struct {
+	Command string            `mapstructure:"command" desc:"Path of the plugin executable" example:"/usr/local/lib/ipfailover/ipfailover-hosts-plugin"`
+	Env     map[string]string `mapstructure:"env" desc:"Environment variables passed to the plugin" example:"HOSTS_FILE: /etc/hosts.d/failover" secret:"true"`
 }
 
-// LoadConfig loads configuration from file and environment variables
+// LoadConfig loads configuration from file and environment variables.
+// The file format is detected from its extension, see ConfigType, and its contents are checked against Schema.
 func LoadConfig(configPath string) (*Config, error) {
+	config, _, err := LoadConfigWithOverlays(configPath, nil)
+	return config, err
+}
+
+// LoadConfigWithOverlays loads the configuration file like LoadConfig and merges each overlay file on top
+// of it in order, so later overlays take precedence. Overlays only need to contain the keys they override
+// and may use a different format than the configuration file. Overlay files that do not exist are skipped
+// and returned so the caller can warn about them. The configuration is validated after all overlays are merged.
+func LoadConfigWithOverlays(configPath string, overlays []string) (*Config, []string, error) {
+	overlays, missingOverlays, err := existingFiles(overlays)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check the files against the schema before unmarshalling, so errors name the offending key
+	if err := validateConfigFile(configPath, overlays...); err != nil {
+		return nil, nil, err
+	}
+
+	config, err := readConfig(configPath, overlays...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Expand ${VAR} references in config values
+	missing := expandEnvVars(config)
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		if len(missing) > 0 {
+			names := make([]string, 0, len(missing))
+			for _, m := range missing {
+				names = append(names, m.String())
+			}
+			return nil, nil, fmt.Errorf("config validation failed: %w (unset environment variables: %s)", err, strings.Join(names, ", "))
+		}
+		return nil, nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return config, missingOverlays, nil
+}
+
+// existingFiles splits paths into the files that exist and the ones that do not
+func existingFiles(paths []string) (existing, missing []string, err error) {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				missing = append(missing, path)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to read config overlay: %w", err)
+		}
+		existing = append(existing, path)
+	}
+	return existing, missing, nil
+}
+
+// mergeOverlays merges each overlay file into v in order
+func mergeOverlays(v *viper.Viper, overlays []string) error {
+	for _, overlay := range overlays {
+		v.SetConfigFile(overlay)
+		v.SetConfigType(ConfigType(overlay))
+		if err := v.MergeInConfig(); err != nil {
+			return fmt.Errorf("failed to merge config overlay %s: %w", overlay, err)
+		}
+	}
+	return nil
+}
+
+// readConfig reads and unmarshals the configuration file and any overlays without expanding or validating it
+func readConfig(configPath string, overlays ...string) (*Config, error) {
 	viper.SetConfigFile(configPath)
-	viper.SetConfigType("yaml")
+	viper.SetConfigType(ConfigType(configPath))
 
 	// Set default values
 	setDefaults()
@@ -103,19 +772,33 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	if err := mergeOverlays(viper.GetViper(), overlays); err != nil {
+		return nil, err
+	}
+	viper.SetConfigFile(configPath)
+	viper.SetConfigType(ConfigType(configPath))
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
-	}
-
 	return &config, nil
 }
 
+// ConfigType returns the format of a configuration file based on its extension:
+// "json" for .json, "toml" for .toml and "yaml" for .yaml, .yml or any other extension
+func ConfigType(configPath string) string {
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	default:
+		return "yaml"
+	}
+}
+
 // getDefaultStateFilePath returns a cross-platform default path for the state file
 func getDefaultStateFilePath() string {
 	// Try to use user config directory first (more appropriate for user applications)
@@ -127,93 +810,642 @@ func getDefaultStateFilePath() string {
 	return filepath.Join(os.TempDir(), "ipfailover", "state.json")
 }
 
+// getDefaultInstanceName returns the hostname, or "ipfailover" if it cannot be determined
+func getDefaultInstanceName() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "ipfailover"
+}
+
 // setDefaults sets default configuration values
 func setDefaults() {
 	viper.SetDefault("poll_interval", "30s")
+	viper.SetDefault("max_poll_interval", DefaultMaxPollInterval.String())
 	viper.SetDefault("check_endpoints", []string{
 		"https://ifconfig.io/ip",
 		"https://api.ipify.org",
 	})
 	viper.SetDefault("failover_retries", 3)
+	viper.SetDefault("failback_delay", "0s")
+	viper.SetDefault("failback_retries", 1)
+	viper.SetDefault("pre_failover_ttl", 0)
+	viper.SetDefault("change_debounce_count", 1)
+	viper.SetDefault("hook_timeout", "30s")
+	viper.SetDefault("max_concurrent_updates", 0)
+	viper.SetDefault("provider_max_retries", 3)
+	viper.SetDefault("provider_retry_base_delay", "500ms")
+	viper.SetDefault("provider_retry_max_delay", "30s")
+	viper.SetDefault("circuit_breaker_threshold", 5)
+	viper.SetDefault("circuit_breaker_cooldown", "60s")
+	viper.SetDefault("propagation_check", false)
+	viper.SetDefault("propagation_resolvers", []string{"8.8.8.8:53", "1.1.1.1:53"})
+	viper.SetDefault("propagation_check_interval", "5s")
+	viper.SetDefault("propagation_check_timeout", "2m")
+	viper.SetDefault("incident_threshold", 5)
 	viper.SetDefault("state_failure_strategy", "continue_with_warning")
+	viper.SetDefault("conflict_policy", "ours-wins")
 	viper.SetDefault("state_file", getDefaultStateFilePath())
 	viper.SetDefault("metrics_addr", ":8080")
 	viper.SetDefault("log_level", "info")
+	viper.SetDefault("audit_log_max_size_mb", 100)
+	viper.SetDefault("audit_log_max_backups", 5)
+	viper.SetDefault("instance_name", getDefaultInstanceName())
 }
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	if c.PollInterval <= 0 {
-		return fmt.Errorf("poll_interval must be positive")
-	}
+// Warnings returns problems with a valid configuration that should be logged at startup
+func (c *Config) Warnings() []string {
+	var warnings []string
 
-	if len(c.CheckEndpoints) == 0 {
-		return fmt.Errorf("at least one check_endpoint must be specified")
+	if c.PollInterval < ShortPollInterval {
+		warnings = append(warnings, fmt.Sprintf(
+			"poll_interval %s is shorter than %s, aggressive polling may exhaust IP check and DNS provider API rate limits",
+			c.PollInterval, ShortPollInterval))
 	}
 
-	if c.PrimaryIP == "" {
-		return fmt.Errorf("primary_ip must be specified")
+	if c.ReachabilityInterval > 0 && c.ReachabilityInterval >= c.PollInterval {
+		warnings = append(warnings, fmt.Sprintf(
+			"reachability_interval %s is not shorter than poll_interval %s, reachability is only checked on polls",
+			c.ReachabilityInterval, c.PollInterval))
 	}
 
-	if c.SecondaryIP == "" {
-		return fmt.Errorf("secondary_ip must be specified")
-	}
+	seen := make(map[string]int, len(c.DNS))
+	for i, dns := range c.DNS {
+		record := dns.Name + "/" + dns.Type + "/" + dns.Provider
+		if first, ok := seen[record]; ok {
+			warnings = append(warnings, fmt.Sprintf(
+				"DNS records %d and %d both manage %s %s with provider %s, make sure they refer to different accounts or servers",
+				first, i, dns.Type, dns.Name, dns.Provider))
+		} else {
+			seen[record] = i
+		}
 
-	if c.FailoverRetries < 0 {
-		return fmt.Errorf("failover_retries must be non-negative")
+		if tlsConfig := dns.TLS(); tlsConfig != nil && tlsConfig.InsecureSkipVerify {
+			warnings = append(warnings, fmt.Sprintf(
+				"tls.insecure_skip_verify is enabled for DNS record %s, the %s API server certificate is not verified; do not use this in production",
+				dns.Name, dns.Provider))
+		}
 	}
 
-	// Validate state failure strategy
-	validStrategies := map[string]bool{
-		"fail_fast":             true,
-		"continue_with_warning": true,
-		"immediate_failover":    true,
+	if c.Reachability.HTTP != nil && c.Reachability.Protocol != "http" {
+		warnings = append(warnings, "reachability.http is set but reachability.protocol is not http, the health check request is not used")
 	}
-	if !validStrategies[c.StateFailureStrategy] {
-		allowedValues := []string{"fail_fast", "continue_with_warning", "immediate_failover"}
-		return fmt.Errorf("state_failure_strategy must be one of %v, got: %q", allowedValues, c.StateFailureStrategy)
+
+	if c.MetricsBasicAuthUser != "" && c.MetricsTLSCertFile == "" {
+		warnings = append(warnings, "metrics_basic_auth_user is set without metrics_tls_cert_file, the metrics password is sent unencrypted")
 	}
 
-	if c.StateFile == "" {
-		return fmt.Errorf("state_file must be specified")
+	if c.Reachability.ICMP != nil && c.Reachability.Protocol != "icmp" {
+		warnings = append(warnings, "reachability.icmp is set but reachability.protocol is not icmp, the echo request settings are not used")
 	}
 
-	if len(c.DNS) == 0 {
-		return fmt.Errorf("at least one DNS record must be configured")
+	return warnings
+}
+
+// validateOTelEndpoint checks that endpoint is empty, host:port or an http or https URL with a host
+func validateOTelEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return nil
 	}
 
-	// Validate DNS records
-	for i, dns := range c.DNS {
-		if err := dns.Validate(); err != nil {
-			return fmt.Errorf("DNS record %d validation failed: %w", i, err)
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("otel_exporter_endpoint must be host:port or an http or https URL with a host, got %q", endpoint)
 		}
+		return nil
 	}
 
+	if _, port, err := net.SplitHostPort(endpoint); err != nil || port == "" {
+		return fmt.Errorf("otel_exporter_endpoint must be host:port or an http or https URL with a host, got %q", endpoint)
+	}
 	return nil
 }
 
-// Validate validates a DNS configuration
-func (d *DNSConfig) Validate() error {
-	if d.Name == "" {
-		return fmt.Errorf("name is required")
+// Source returns the local address and interface of IP checks and reachability checks.
+// Unlike Validate, its Validate method checks that they exist on this host.
+func (c *Config) Source() netbind.Source {
+	return netbind.Source{IP: c.SourceIP, Interface: c.SourceInterface}
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive")
 	}
 
-	if d.Type == "" {
-		return fmt.Errorf("type is required")
+	if c.PollInterval < minPollInterval {
+		return fmt.Errorf("poll_interval %s is shorter than %s, durations need a unit, e.g. 300s", c.PollInterval, minPollInterval)
 	}
 
-	if d.Provider == "" {
-		return fmt.Errorf("provider is required")
+	maxPollInterval := c.MaxPollInterval
+	if maxPollInterval <= 0 {
+		maxPollInterval = DefaultMaxPollInterval
+	}
+	if c.PollInterval > maxPollInterval {
+		return fmt.Errorf("poll_interval %s exceeds max_poll_interval %s", c.PollInterval, maxPollInterval)
 	}
 
-	if d.TTL <= 0 {
-		return fmt.Errorf("TTL must be positive")
+	if c.ProbeInterval < 0 {
+		return fmt.Errorf("probe_interval must be non-negative")
 	}
 
-	// Validate provider-specific configuration
-	switch d.Provider {
-	case "cloudflare":
-		if d.Cloudflare == nil {
+	if c.ReachabilityInterval < 0 {
+		return fmt.Errorf("reachability_interval must be non-negative")
+	}
+
+	if err := c.Reachability.Validate(); err != nil {
+		return fmt.Errorf("reachability validation failed: %w", err)
+	}
+
+	if len(c.CheckEndpoints) == 0 {
+		return fmt.Errorf("at least one check_endpoint must be specified")
+	}
+
+	if c.PrimaryIP == "" {
+		return fmt.Errorf("primary_ip must be specified")
+	}
+
+	if c.SecondaryIP == "" {
+		return fmt.Errorf("secondary_ip must be specified")
+	}
+
+	primaryIP := net.ParseIP(c.PrimaryIP)
+	if primaryIP == nil {
+		return fmt.Errorf("primary_ip %q is not a valid IP address", c.PrimaryIP)
+	}
+
+	secondaryIP := net.ParseIP(c.SecondaryIP)
+	if secondaryIP == nil {
+		return fmt.Errorf("secondary_ip %q is not a valid IP address", c.SecondaryIP)
+	}
+
+	if primaryIP.Equal(secondaryIP) {
+		return fmt.Errorf("primary_ip and secondary_ip must be different addresses, both are %s", c.PrimaryIP)
+	}
+
+	if err := c.validateFallbackIPs(); err != nil {
+		return err
+	}
+
+	if c.PrimaryIPv6 != "" || c.SecondaryIPv6 != "" {
+		if err := c.validateIPv6(primaryIP, secondaryIP); err != nil {
+			return err
+		}
+	}
+
+	if c.FailoverRetries < 1 {
+		return fmt.Errorf("failover_retries must be at least 1, got %d", c.FailoverRetries)
+	}
+
+	if c.FailbackDelay < 0 {
+		return fmt.Errorf("failback_delay must be non-negative")
+	}
+
+	if c.FailbackRetries < 0 {
+		return fmt.Errorf("failback_retries must be non-negative")
+	}
+
+	if c.EgressIPWeight < 0 {
+		return fmt.Errorf("egress_ip_weight must be non-negative")
+	}
+
+	if c.PreFailoverTTL < 0 {
+		return fmt.Errorf("pre_failover_ttl must be non-negative")
+	}
+
+	if c.ChangeDebounceCount < 0 {
+		return fmt.Errorf("change_debounce_count must be non-negative")
+	}
+
+	if c.MinChangeInterval < 0 {
+		return fmt.Errorf("min_change_interval must be non-negative")
+	}
+
+	if c.HookTimeout < 0 {
+		return fmt.Errorf("hook_timeout must be non-negative")
+	}
+
+	if c.MaxConcurrentUpdates < 0 {
+		return fmt.Errorf("max_concurrent_updates must be non-negative")
+	}
+
+	if c.ProviderMaxRetries < 0 {
+		return fmt.Errorf("provider_max_retries must be non-negative")
+	}
+
+	if c.ProviderRetryBaseDelay < 0 || c.ProviderRetryMaxDelay < 0 {
+		return fmt.Errorf("provider_retry_base_delay and provider_retry_max_delay must be non-negative")
+	}
+
+	if c.ProviderRetryMaxDelay > 0 && c.ProviderRetryBaseDelay > c.ProviderRetryMaxDelay {
+		return fmt.Errorf("provider_retry_base_delay %s must not exceed provider_retry_max_delay %s",
+			c.ProviderRetryBaseDelay, c.ProviderRetryMaxDelay)
+	}
+
+	if c.CircuitBreakerThreshold < 0 {
+		return fmt.Errorf("circuit_breaker_threshold must be non-negative")
+	}
+
+	if c.CircuitBreakerCooldown < 0 {
+		return fmt.Errorf("circuit_breaker_cooldown must be non-negative")
+	}
+
+	if c.PropagationCheck {
+		if len(c.PropagationResolvers) == 0 {
+			return fmt.Errorf("propagation_resolvers must not be empty when propagation_check is enabled")
+		}
+		for _, resolver := range c.PropagationResolvers {
+			if _, _, err := net.SplitHostPort(resolver); err != nil {
+				return fmt.Errorf("invalid propagation resolver %q, expected host:port: %w", resolver, err)
+			}
+		}
+		if c.PropagationCheckInterval <= 0 || c.PropagationCheckTimeout <= 0 {
+			return fmt.Errorf("propagation_check_interval and propagation_check_timeout must be positive when propagation_check is enabled")
+		}
+	}
+
+	if c.IncidentThreshold < 0 {
+		return fmt.Errorf("incident_threshold must be non-negative")
+	}
+
+	if c.AuditLogMaxSizeMB < 0 || c.AuditLogMaxBackups < 0 {
+		return fmt.Errorf("audit_log_max_size_mb and audit_log_max_backups must be non-negative")
+	}
+
+	if c.Notifications.Email != nil {
+		if err := c.Notifications.Email.Validate(); err != nil {
+			return fmt.Errorf("notifications.email validation failed: %w", err)
+		}
+	}
+
+	if c.Notifications.PagerDuty != nil {
+		if err := c.Notifications.PagerDuty.Validate(); err != nil {
+			return fmt.Errorf("notifications.pagerduty validation failed: %w", err)
+		}
+	}
+
+	for i := range c.Notifications.Webhooks {
+		if err := c.Notifications.Webhooks[i].Validate(); err != nil {
+			return fmt.Errorf("notifications.webhooks[%d] validation failed: %w", i, err)
+		}
+	}
+
+	// Validate state failure strategy
+	validStrategies := map[string]bool{
+		"fail_fast":             true,
+		"continue_with_warning": true,
+		"immediate_failover":    true,
+	}
+	if !validStrategies[c.StateFailureStrategy] {
+		allowedValues := []string{"fail_fast", "continue_with_warning", "immediate_failover"}
+		return fmt.Errorf("state_failure_strategy must be one of %v, got: %q", allowedValues, c.StateFailureStrategy)
+	}
+
+	// Validate conflict policy, an empty policy means the default
+	validPolicies := map[string]bool{
+		"":            true,
+		"ours-wins":   true,
+		"theirs-wins": true,
+		"alert-only":  true,
+	}
+	if !validPolicies[c.ConflictPolicy] {
+		allowedValues := []string{"ours-wins", "theirs-wins", "alert-only"}
+		return fmt.Errorf("conflict_policy must be one of %v, got: %q", allowedValues, c.ConflictPolicy)
+	}
+
+	if (c.MetricsTLSCertFile == "") != (c.MetricsTLSKeyFile == "") {
+		return fmt.Errorf("metrics_tls_cert_file and metrics_tls_key_file must be set together")
+	}
+
+	if c.MetricsBasicAuthUser == "" && c.MetricsBasicAuthPassword != "" {
+		return fmt.Errorf("metrics_basic_auth_user must be specified when metrics_basic_auth_password is set")
+	}
+	if c.MetricsBasicAuthUser != "" && c.MetricsBasicAuthPassword == "" {
+		return fmt.Errorf("metrics_basic_auth_password must be specified when metrics_basic_auth_user is set")
+	}
+
+	for _, cidr := range c.MetricsAllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil && net.ParseIP(cidr) == nil {
+			return fmt.Errorf("metrics_allowed_cidrs: %q is not a CIDR range or IP address", cidr)
+		}
+	}
+
+	if c.APIAddr != "" {
+		if c.APIToken == "" {
+			return fmt.Errorf("api_token must be specified when api_addr is set")
+		}
+		if c.APIAddr == c.MetricsAddr {
+			return fmt.Errorf("api_addr must differ from metrics_addr, both are %q", c.APIAddr)
+		}
+	}
+
+	if err := validateProxyURL(c.ProxyURL); err != nil {
+		return err
+	}
+
+	if err := validateOTelEndpoint(c.OTelExporterEndpoint); err != nil {
+		return err
+	}
+
+	// Whether the source exists is checked at startup, see Source, so configurations of other hosts validate
+	if c.SourceIP != "" && net.ParseIP(c.SourceIP) == nil {
+		return fmt.Errorf("source_ip %q is not a valid IP address", c.SourceIP)
+	}
+
+	if c.StateFile == "" {
+		return fmt.Errorf("state_file must be specified")
+	}
+
+	if len(c.DNS) == 0 {
+		return fmt.Errorf("at least one DNS record must be configured")
+	}
+
+	// Validate DNS records
+	keys := make(map[string]int, len(c.DNS))
+	for i, dns := range c.DNS {
+		if err := dns.Validate(); err != nil {
+			return fmt.Errorf("DNS record %d validation failed: %w", i, err)
+		}
+
+		key := dns.Key()
+		if strings.EqualFold(dns.ID, IPv6StateName) {
+			return fmt.Errorf("DNS record %d validation failed: id %q is reserved for the state of the AAAA records", i, dns.ID)
+		}
+		if first, ok := keys[key]; ok {
+			if dns.ID == "" {
+				return fmt.Errorf("DNS record %d validation failed: DNS record %d has the same name, type and provider, set a distinct id on both", i, first)
+			}
+			return fmt.Errorf("DNS record %d validation failed: id %q is already used by DNS record %d", i, key, first)
+		}
+		keys[key] = i
+
+		if !dns.HasFailoverOverrides() {
+			continue
+		}
+		record := c.ForRecord(dns)
+		if net.ParseIP(record.PrimaryIP).Equal(net.ParseIP(record.SecondaryIP)) {
+			return fmt.Errorf("DNS record %d validation failed: primary_ip and secondary_ip must be different addresses, both are %s", i, record.PrimaryIP)
+		}
+		for _, fallbackIP := range record.FallbackIPs {
+			if net.ParseIP(record.PrimaryIP).Equal(net.ParseIP(fallbackIP)) {
+				return fmt.Errorf("DNS record %d validation failed: primary_ip %s is also one of the global fallback_ips", i, record.PrimaryIP)
+			}
+		}
+		if record.PollInterval < c.PollInterval || record.PollInterval > maxPollInterval {
+			return fmt.Errorf("DNS record %d validation failed: poll_interval %s must be between the global poll_interval %s and max_poll_interval %s",
+				i, record.PollInterval, c.PollInterval, maxPollInterval)
+		}
+	}
+
+	return nil
+}
+
+// validateFallbackIPs checks that the fallback IPs are valid and differ from each other and from the
+// primary and secondary IPs
+func (c *Config) validateFallbackIPs() error {
+	seen := []net.IP{net.ParseIP(c.PrimaryIP), net.ParseIP(c.SecondaryIP)}
+	for i, fallbackIP := range c.FallbackIPs {
+		ip := net.ParseIP(fallbackIP)
+		if ip == nil {
+			return fmt.Errorf("fallback_ips[%d] %q is not a valid IP address", i, fallbackIP)
+		}
+		if c.DualStack() && ip.To4() == nil {
+			return fmt.Errorf("fallback_ips[%d] %q must be an IPv4 address when primary_ipv6 is set", i, fallbackIP)
+		}
+		for _, other := range seen {
+			if ip.Equal(other) {
+				return fmt.Errorf("fallback_ips[%d] %s is already the primary, secondary or another fallback IP", i, fallbackIP)
+			}
+		}
+		seen = append(seen, ip)
+	}
+	return nil
+}
+
+// FallbackTargets returns the IPs that are failed over to in order of preference: SecondaryIP,
+// followed by FallbackIPs
+func (c *Config) FallbackTargets() []string {
+	return append([]string{c.SecondaryIP}, c.FallbackIPs...)
+}
+
+// Key returns the identifier of the record entry, its id or else name/type/provider, e.g.
+// home.example.com/A/cloudflare. The DNS providers of the application are keyed by it.
+func (d DNSConfig) Key() string {
+	if d.ID != "" {
+		return d.ID
+	}
+	return d.Name + "/" + d.Type + "/" + d.Provider
+}
+
+// ProviderInstance identifies the provider account and settings the record is managed through: its
+// provider type and a hash of the provider settings, e.g. cloudflare#1f2e3d4c. Records using the same
+// credentials, zone and settings share an instance, while the record name, TTL and failover
+// overrides do not matter. The circuit breakers of the DNS providers are keyed by it.
+func (d DNSConfig) ProviderInstance() string {
+	settings := d
+	settings.ID, settings.Name, settings.Type, settings.TTL, settings.Metadata = "", "", "", 0, nil
+	settings.PrimaryIP, settings.SecondaryIP, settings.FailoverRetries, settings.PollInterval = "", "", 0, 0
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		// Not expected for configuration values; a breaker of its own is the safe fallback
+		return d.Provider + "#" + d.Key()
+	}
+	sum := sha256.Sum256(data)
+	return d.Provider + "#" + hex.EncodeToString(sum[:4])
+}
+
+// StateName returns the name of the state file of a record with failover overrides, its Key, see
+// state.RecordStateFile. Records with the same name but a different type or provider thus keep
+// their state apart, and only an id can name the state file of the AAAA records, see IPv6StateName.
+func (d DNSConfig) StateName() string {
+	return d.Key()
+}
+
+// validateIPv6 checks the dual-stack settings: both IPv6 addresses are set and different, and the
+// primary and secondary IPs, which the A records use, are IPv4 addresses
+func (c *Config) validateIPv6(primaryIP, secondaryIP net.IP) error {
+	if c.PrimaryIPv6 == "" || c.SecondaryIPv6 == "" {
+		return fmt.Errorf("primary_ipv6 and secondary_ipv6 must be specified together")
+	}
+
+	primaryIPv6 := net.ParseIP(c.PrimaryIPv6)
+	if primaryIPv6 == nil || primaryIPv6.To4() != nil {
+		return fmt.Errorf("primary_ipv6 %q is not a valid IPv6 address", c.PrimaryIPv6)
+	}
+
+	secondaryIPv6 := net.ParseIP(c.SecondaryIPv6)
+	if secondaryIPv6 == nil || secondaryIPv6.To4() != nil {
+		return fmt.Errorf("secondary_ipv6 %q is not a valid IPv6 address", c.SecondaryIPv6)
+	}
+
+	if primaryIPv6.Equal(secondaryIPv6) {
+		return fmt.Errorf("primary_ipv6 and secondary_ipv6 must be different addresses, both are %s", c.PrimaryIPv6)
+	}
+
+	if primaryIP.To4() == nil || secondaryIP.To4() == nil {
+		return fmt.Errorf("primary_ip and secondary_ip must be IPv4 addresses when primary_ipv6 is set")
+	}
+
+	return nil
+}
+
+// DualStack reports whether AAAA records fail over between their own IPv6 addresses
+func (c *Config) DualStack() bool {
+	return c.PrimaryIPv6 != ""
+}
+
+// IsIPv6Record reports whether the record fails over between the IPv6 addresses, which is the
+// case for AAAA records in dual-stack mode
+func (c *Config) IsIPv6Record(d DNSConfig) bool {
+	return c.DualStack() && strings.EqualFold(d.Type, "AAAA")
+}
+
+// IPv6CheckEndpoints returns the IP detection services queried over IPv6 in dual-stack mode
+func (c *Config) IPv6CheckEndpoints() []string {
+	if len(c.CheckEndpointsIPv6) > 0 {
+		return c.CheckEndpointsIPv6
+	}
+	return c.CheckEndpoints
+}
+
+// GroupStateName returns the name of the state file of the record's failover group, see
+// state.RecordStateFile: empty for the records using the global state file, IPv6StateName for
+// the AAAA records in dual-stack mode, and DNSConfig.StateName for a record with failover overrides
+func (c *Config) GroupStateName(d DNSConfig) string {
+	switch {
+	case d.HasFailoverOverrides():
+		return d.StateName()
+	case c.IsIPv6Record(d):
+		return IPv6StateName
+	default:
+		return ""
+	}
+}
+
+// TLS returns the TLS settings of the record's provider, or nil if it has none
+func (d DNSConfig) TLS() *TLSConfig {
+	switch {
+	case d.CPanel != nil && d.Provider == "cpanel":
+		return d.CPanel.TLS
+	case d.AdGuard != nil && d.Provider == "adguard":
+		return d.AdGuard.TLS
+	case d.Webhook != nil && d.Provider == "webhook":
+		return d.Webhook.TLS
+	default:
+		return nil
+	}
+}
+
+// HasFailoverOverrides reports whether the record sets its own failover IPs, retries or poll interval
+func (d DNSConfig) HasFailoverOverrides() bool {
+	return d.PrimaryIP != "" || d.SecondaryIP != "" || d.FailoverRetries != 0 || d.PollInterval != 0
+}
+
+// ForRecord returns a copy of the configuration for the failover group of the record. For a record
+// with failover overrides, the overrides replace the global values and DNS holds only the record.
+// Otherwise the global values are kept and DNS holds all records without overrides.
+func (c *Config) ForRecord(d DNSConfig) *Config {
+	record := *c
+	ipv6 := c.IsIPv6Record(d)
+	if ipv6 {
+		record.PrimaryIP = c.PrimaryIPv6
+		record.SecondaryIP = c.SecondaryIPv6
+		record.FallbackIPs = nil
+	}
+
+	if !d.HasFailoverOverrides() {
+		record.DNS = nil
+		for _, dns := range c.DNS {
+			if !dns.HasFailoverOverrides() && c.IsIPv6Record(dns) == ipv6 {
+				record.DNS = append(record.DNS, dns)
+			}
+		}
+		return &record
+	}
+
+	record.DNS = []DNSConfig{d}
+	if d.PrimaryIP != "" {
+		record.PrimaryIP = d.PrimaryIP
+	}
+	if d.SecondaryIP != "" {
+		// The record's own secondary IP replaces all global fallback IPs
+		record.SecondaryIP = d.SecondaryIP
+		record.FallbackIPs = nil
+	}
+	if d.FailoverRetries != 0 {
+		record.FailoverRetries = d.FailoverRetries
+	}
+	if d.PollInterval != 0 {
+		record.PollInterval = d.PollInterval
+	}
+	return &record
+}
+
+// FailoverGroups returns the configuration of each set of DNS records that fail over together,
+// see ForRecord: the records using the global failover settings first, if any, then in dual-stack
+// mode the AAAA records using them, followed by each record with failover overrides in
+// configuration order.
+func (c *Config) FailoverGroups() []*Config {
+	var global, ipv6, overridden []*Config
+	for _, dns := range c.DNS {
+		switch {
+		case dns.HasFailoverOverrides():
+			overridden = append(overridden, c.ForRecord(dns))
+		case c.IsIPv6Record(dns):
+			if len(ipv6) == 0 {
+				ipv6 = append(ipv6, c.ForRecord(dns))
+			}
+		case len(global) == 0:
+			global = append(global, c.ForRecord(dns))
+		}
+	}
+	return append(append(global, ipv6...), overridden...)
+}
+
+// Validate validates a DNS configuration
+func (d *DNSConfig) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if d.Type == "" {
+		return fmt.Errorf("type is required")
+	}
+
+	if d.Provider == "" {
+		return fmt.Errorf("provider is required")
+	}
+
+	if d.TTL <= 0 {
+		return fmt.Errorf("TTL must be positive")
+	}
+
+	if d.PrimaryIP != "" && net.ParseIP(d.PrimaryIP) == nil {
+		return fmt.Errorf("primary_ip %q is not a valid IP address", d.PrimaryIP)
+	}
+
+	if d.SecondaryIP != "" && net.ParseIP(d.SecondaryIP) == nil {
+		return fmt.Errorf("secondary_ip %q is not a valid IP address", d.SecondaryIP)
+	}
+
+	if d.FailoverRetries < 0 {
+		return fmt.Errorf("failover_retries must be non-negative")
+	}
+
+	if d.PollInterval < 0 {
+		return fmt.Errorf("poll_interval must be non-negative")
+	}
+
+	if err := d.HTTP.Validate(); err != nil {
+		return fmt.Errorf("http config validation failed: %w", err)
+	}
+
+	// Validate provider-specific configuration
+	switch d.Provider {
+	case "cloudflare":
+		if d.Cloudflare == nil {
 			return fmt.Errorf("cloudflare configuration is required for cloudflare provider")
 		}
 		if err := d.Cloudflare.Validate(); err != nil {
@@ -240,6 +1472,97 @@ func (d *DNSConfig) Validate() error {
 		if err := d.Hetzner.Validate(); err != nil {
 			return fmt.Errorf("hetzner config validation failed: %w", err)
 		}
+	case "alidns":
+		if d.AliDNS == nil {
+			return fmt.Errorf("alidns configuration is required for alidns provider")
+		}
+		if err := d.AliDNS.Validate(); err != nil {
+			return fmt.Errorf("alidns config validation failed: %w", err)
+		}
+	case "netcup":
+		if d.Netcup == nil {
+			return fmt.Errorf("netcup configuration is required for netcup provider")
+		}
+		if err := d.Netcup.Validate(); err != nil {
+			return fmt.Errorf("netcup config validation failed: %w", err)
+		}
+	case "namecom":
+		if d.Namecom == nil {
+			return fmt.Errorf("namecom configuration is required for namecom provider")
+		}
+		if err := d.Namecom.Validate(); err != nil {
+			return fmt.Errorf("namecom config validation failed: %w", err)
+		}
+	case "adguard":
+		if d.AdGuard == nil {
+			return fmt.Errorf("adguard configuration is required for adguard provider")
+		}
+		if err := d.AdGuard.Validate(); err != nil {
+			return fmt.Errorf("adguard config validation failed: %w", err)
+		}
+	case "transip":
+		if d.TransIP == nil {
+			return fmt.Errorf("transip configuration is required for transip provider")
+		}
+		if err := d.TransIP.Validate(); err != nil {
+			return fmt.Errorf("transip config validation failed: %w", err)
+		}
+	case "mythicbeasts":
+		if d.MythicBeasts == nil {
+			return fmt.Errorf("mythicbeasts configuration is required for mythicbeasts provider")
+		}
+		if err := d.MythicBeasts.Validate(); err != nil {
+			return fmt.Errorf("mythicbeasts config validation failed: %w", err)
+		}
+	case "domeneshop":
+		if d.Domeneshop == nil {
+			return fmt.Errorf("domeneshop configuration is required for domeneshop provider")
+		}
+		if err := d.Domeneshop.Validate(); err != nil {
+			return fmt.Errorf("domeneshop config validation failed: %w", err)
+		}
+	case "easydns":
+		if d.EasyDNS == nil {
+			return fmt.Errorf("easydns configuration is required for easydns provider")
+		}
+		if err := d.EasyDNS.Validate(); err != nil {
+			return fmt.Errorf("easydns config validation failed: %w", err)
+		}
+	case "loopia":
+		if d.Loopia == nil {
+			return fmt.Errorf("loopia configuration is required for loopia provider")
+		}
+		if err := d.Loopia.Validate(); err != nil {
+			return fmt.Errorf("loopia config validation failed: %w", err)
+		}
+	case "infomaniak":
+		if d.Infomaniak == nil {
+			return fmt.Errorf("infomaniak configuration is required for infomaniak provider")
+		}
+		if err := d.Infomaniak.Validate(); err != nil {
+			return fmt.Errorf("infomaniak config validation failed: %w", err)
+		}
+	case "dynu":
+		if d.Dynu == nil {
+			return fmt.Errorf("dynu configuration is required for dynu provider")
+		}
+		if err := d.Dynu.Validate(); err != nil {
+			return fmt.Errorf("dynu config validation failed: %w", err)
+		}
+	case "webhook":
+		if d.Webhook == nil {
+			return fmt.Errorf("webhook configuration is required for webhook provider")
+		}
+		if err := d.Webhook.Validate(); err != nil {
+			return fmt.Errorf("webhook config validation failed: %w", err)
+		}
+	case "plugin":
+		if d.Plugin == nil {
+			return fmt.Errorf("plugin configuration is required for plugin provider")
+		}
+		if err := d.Plugin.Validate(); err != nil {
+			return fmt.Errorf("plugin config validation failed: %w", err)
+		}
 	default:
 		return fmt.Errorf("unsupported provider: %s", d.Provider)
 	}
@@ -247,14 +1570,124 @@ func (d *DNSConfig) Validate() error {
 	return nil
 }
 
+// Validate validates SMTP email notification configuration
+func (c *EmailConfig) Validate() error {
+	if c.SMTPHost == "" {
+		return fmt.Errorf("smtp_host is required")
+	}
+
+	if c.SMTPPort < 0 || c.SMTPPort > 65535 {
+		return fmt.Errorf("smtp_port must be between 1 and 65535, got %d", c.SMTPPort)
+	}
+
+	if c.From == "" {
+		return fmt.Errorf("from is required")
+	}
+
+	if len(c.To) == 0 {
+		return fmt.Errorf("at least one to address is required")
+	}
+
+	for _, address := range append([]string{c.From}, c.To...) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("invalid email address %q: %w", address, err)
+		}
+	}
+
+	if _, err := c.ParseSubjectTemplate(); err != nil {
+		return err
+	}
+
+	if c.DigestTime != "" {
+		if _, err := time.Parse("15:04", c.DigestTime); err != nil {
+			return fmt.Errorf("digest_time %q must be a time of day as HH:MM", c.DigestTime)
+		}
+	}
+
+	return nil
+}
+
+// sampleNotificationEvent is used to render the subject template once while validating the
+// configuration, which reports references to unknown fields at startup rather than on the first failover
+var sampleNotificationEvent = interfaces.NotificationEvent{
+	Type:      "failover",
+	FromIP:    "203.0.113.10",
+	ToIP:      "198.51.100.77",
+	Records:   []string{"home.example.com"},
+	Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+	Message:   "DNS records were changed from 203.0.113.10 to 198.51.100.77",
+}
+
+// ParseSubjectTemplate parses the subject template, rendered with an interfaces.NotificationEvent, and
+// renders it once with a sample event. Without a subject template it returns nil.
+func (c *EmailConfig) ParseSubjectTemplate() (*template.Template, error) {
+	if c.SubjectTemplate == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("subject_template").Option("missingkey=error").Parse(c.SubjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("subject_template is not a valid template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, sampleNotificationEvent); err != nil {
+		return nil, fmt.Errorf("subject_template is not a valid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Validate validates notification webhook configuration
+func (c *NotificationWebhookConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("url must be an http or https URL with a host, got %q", c.URL)
+	}
+
+	switch c.Method {
+	case "", "POST", "PUT", "PATCH":
+	default:
+		return fmt.Errorf("method must be POST, PUT or PATCH, got %q", c.Method)
+	}
+
+	return nil
+}
+
+// Validate validates PagerDuty configuration
+func (c *PagerDutyConfig) Validate() error {
+	if c.IntegrationKey == "" {
+		return fmt.Errorf("integration_key is required")
+	}
+
+	switch c.Severity {
+	case "", "critical", "error", "warning", "info":
+	default:
+		return fmt.Errorf("severity must be critical, error, warning or info, got %q", c.Severity)
+	}
+
+	return nil
+}
+
 // Validate validates Cloudflare configuration
 func (c *CloudflareConfig) Validate() error {
-	if c.APIToken == "" {
-		return fmt.Errorf("api_token is required")
+	if c.APIToken != "" && (c.APIKey != "" || c.APIEmail != "") {
+		return fmt.Errorf("api_token cannot be combined with api_key and api_email")
 	}
 
-	if c.ZoneID == "" {
-		return fmt.Errorf("zone_id is required")
+	if c.APIToken == "" {
+		if c.APIKey == "" && c.APIEmail == "" {
+			return fmt.Errorf("api_token is required, or api_key and api_email for Global API Key authentication")
+		}
+		if c.APIKey == "" || c.APIEmail == "" {
+			return fmt.Errorf("api_key and api_email must be set together")
+		}
+		if _, err := mail.ParseAddress(c.APIEmail); err != nil {
+			return fmt.Errorf("api_email %q is not a valid email address", c.APIEmail)
+		}
 	}
 
 	return nil
@@ -278,17 +1711,66 @@ func (c *CPanelConfig) Validate() error {
 		return fmt.Errorf("zone is required")
 	}
 
+	if c.WHMUsername != "" && !c.UseWHM {
+		return fmt.Errorf("whm_username requires use_whm")
+	}
+
+	if err := c.TLS.Validate(); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
 	return nil
 }
 
+// roleSessionNamePattern matches the session names accepted by AWS STS AssumeRole
+var roleSessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
 // Validate validates Route53 configuration
 func (c *Route53Config) Validate() error {
-	if c.AccessKeyID == "" {
-		return fmt.Errorf("access_key_id is required")
+	if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		return fmt.Errorf("access_key_id and secret_access_key must be set together")
+	}
+
+	if c.ExternalID != "" && c.RoleARN == "" {
+		return fmt.Errorf("external_id requires role_arn")
+	}
+
+	if c.RoleARN != "" && !strings.HasPrefix(c.RoleARN, "arn:") {
+		return fmt.Errorf("role_arn must be an IAM role ARN, got %q", c.RoleARN)
+	}
+
+	if c.RoleSessionName != "" {
+		if c.RoleARN == "" {
+			return fmt.Errorf("role_session_name requires role_arn")
+		}
+		if !roleSessionNamePattern.MatchString(c.RoleSessionName) {
+			return fmt.Errorf("role_session_name must be 2 to 64 letters, digits or +=,.@_- characters, got %q", c.RoleSessionName)
+		}
+	}
+
+	if c.SyncTimeout < 0 {
+		return fmt.Errorf("sync_timeout must be non-negative")
+	}
+
+	if c.Failover != "" {
+		if c.Failover != "PRIMARY" && c.Failover != "SECONDARY" {
+			return fmt.Errorf("failover must be PRIMARY or SECONDARY, got %q", c.Failover)
+		}
+		if c.SetIdentifier == "" {
+			return fmt.Errorf("failover requires set_identifier")
+		}
 	}
 
-	if c.SecretAccessKey == "" {
-		return fmt.Errorf("secret_access_key is required")
+	if (c.AliasTargetDNSName == "") != (c.AliasHostedZoneID == "") {
+		return fmt.Errorf("alias_target_dns_name and alias_hosted_zone_id must be set together")
+	}
+
+	if (c.SecondaryAliasTargetDNSName == "") != (c.SecondaryAliasHostedZoneID == "") {
+		return fmt.Errorf("secondary_alias_target_dns_name and secondary_alias_hosted_zone_id must be set together")
+	}
+
+	if c.EvaluateTargetHealth && c.AliasTargetDNSName == "" && c.SecondaryAliasTargetDNSName == "" {
+		return fmt.Errorf("evaluate_target_health requires alias_target_dns_name or secondary_alias_target_dns_name")
 	}
 
 	if c.Region == "" {
@@ -308,33 +1790,364 @@ func (c *HetznerConfig) Validate() error {
 		return fmt.Errorf("api_token is required")
 	}
 
-	if c.ZoneID == "" {
-		return fmt.Errorf("zone_id is required")
+	switch c.API {
+	case "", HetznerAPICloud:
+		if c.ZoneID == "" {
+			return fmt.Errorf("zone_id is required")
+		}
+	case HetznerAPIConsole:
+		if c.ZoneID == "" && c.Zone == "" {
+			return fmt.Errorf("zone_id or zone is required")
+		}
+	default:
+		return fmt.Errorf("api must be %q or %q, got %q", HetznerAPICloud, HetznerAPIConsole, c.API)
+	}
+
+	return nil
+}
+
+// Validate validates Alibaba Cloud DNS configuration
+func (c *AliDNSConfig) Validate() error {
+	if c.AccessKeyID == "" {
+		return fmt.Errorf("access_key_id is required")
+	}
+
+	if c.AccessKeySecret == "" {
+		return fmt.Errorf("access_key_secret is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates netcup configuration
+func (c *NetcupConfig) Validate() error {
+	if c.CustomerNumber == "" {
+		return fmt.Errorf("customer_number is required")
+	}
+
+	if c.APIKey == "" {
+		return fmt.Errorf("api_key is required")
+	}
+
+	if c.APIPassword == "" {
+		return fmt.Errorf("api_password is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates Name.com configuration
+func (c *NamecomConfig) Validate() error {
+	if c.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	if c.APIToken == "" {
+		return fmt.Errorf("api_token is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
 	}
 
 	return nil
 }
 
+// Validate validates AdGuard Home configuration
+func (c *AdGuardConfig) Validate() error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("base_url is required")
+	}
+
+	if c.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	if c.Password == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	if err := c.TLS.Validate(); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
+	return nil
+}
+
+// Validate validates TransIP configuration
+func (c *TransIPConfig) Validate() error {
+	if c.Login == "" {
+		return fmt.Errorf("login is required")
+	}
+
+	if c.PrivateKey == "" {
+		return fmt.Errorf("private_key is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates Mythic Beasts configuration
+func (c *MythicBeastsConfig) Validate() error {
+	if c.KeyID == "" {
+		return fmt.Errorf("key_id is required")
+	}
+
+	if c.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	if c.Zone == "" {
+		return fmt.Errorf("zone is required")
+	}
+
+	return nil
+}
+
+// Validate validates Domeneshop configuration
+func (c *DomeneshopConfig) Validate() error {
+	if c.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	if c.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates EasyDNS configuration
+func (c *EasyDNSConfig) Validate() error {
+	if c.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	if c.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates Loopia configuration
+func (c *LoopiaConfig) Validate() error {
+	if c.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	if c.Password == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates Infomaniak configuration
+func (c *InfomaniakConfig) Validate() error {
+	if c.APIToken == "" {
+		return fmt.Errorf("api_token is required")
+	}
+
+	if c.Zone == "" {
+		return fmt.Errorf("zone is required")
+	}
+
+	return nil
+}
+
+// Validate validates Dynu configuration
+func (c *DynuConfig) Validate() error {
+	if c.APIKey == "" {
+		return fmt.Errorf("api_key is required")
+	}
+
+	if c.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	return nil
+}
+
+// Validate validates plugin configuration
+func (c *PluginConfig) Validate() error {
+	if c.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+
+	for name := range c.Env {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+
+	return nil
+}
+
+// String returns a safe string representation of EmailConfig with sensitive fields redacted
+func (c *EmailConfig) String() string {
+	return fmt.Sprintf("EmailConfig{SMTPHost:%s, SMTPPort:%d, SMTPUsername:%s, SMTPPassword:%s, From:%s, To:%v, TLSEnabled:%v, SubjectTemplate:%s, DailyDigest:%v, DigestTime:%s}",
+		c.SMTPHost, c.SMTPPort, c.SMTPUsername, "[REDACTED]", c.From, c.To, c.TLSEnabled, c.SubjectTemplate, c.DailyDigest, c.DigestTime)
+}
+
+// String returns a safe string representation of NotificationWebhookConfig with header values and the secret redacted
+func (c *NotificationWebhookConfig) String() string {
+	headers := make([]string, 0, len(c.Headers))
+	for name := range c.Headers {
+		headers = append(headers, name+":[REDACTED]")
+	}
+	sort.Strings(headers)
+	return fmt.Sprintf("NotificationWebhookConfig{URL:%s, Method:%s, Headers:[%s], Secret:%s}",
+		c.URL, c.Method, strings.Join(headers, " "), "[REDACTED]")
+}
+
+// String returns a safe string representation of PagerDutyConfig with sensitive fields redacted
+func (c *PagerDutyConfig) String() string {
+	return fmt.Sprintf("PagerDutyConfig{IntegrationKey:%s, Severity:%s, Endpoint:%s}",
+		"[REDACTED]", c.Severity, c.Endpoint)
+}
+
 // String returns a safe string representation of CloudflareConfig with sensitive fields redacted
 func (c *CloudflareConfig) String() string {
-	return fmt.Sprintf("CloudflareConfig{APIToken:%s, ZoneID:%s, Proxied:%v}",
-		"[REDACTED]", c.ZoneID, c.Proxied)
+	return fmt.Sprintf("CloudflareConfig{APIToken:%s, APIKey:%s, APIEmail:%s, ZoneID:%s, ZoneName:%s, Proxied:%v, ProxiedPrimary:%s, ProxiedSecondary:%s}",
+		"[REDACTED]", "[REDACTED]", c.APIEmail, c.ZoneID, c.ZoneName, c.Proxied, optionalBool(c.ProxiedPrimary), optionalBool(c.ProxiedSecondary))
+}
+
+// optionalBool formats an optional boolean, with "unset" for nil
+func optionalBool(b *bool) string {
+	if b == nil {
+		return "unset"
+	}
+	return strconv.FormatBool(*b)
 }
 
 // String returns a safe string representation of CPanelConfig with sensitive fields redacted
 func (c *CPanelConfig) String() string {
-	return fmt.Sprintf("CPanelConfig{BaseURL:%s, Username:%s, APIToken:%s, Zone:%s}",
-		c.BaseURL, c.Username, "[REDACTED]", c.Zone)
+	return fmt.Sprintf("CPanelConfig{BaseURL:%s, Username:%s, APIToken:%s, Zone:%s, UseWHM:%v, WHMUsername:%s, TLS:%+v}",
+		c.BaseURL, c.Username, "[REDACTED]", c.Zone, c.UseWHM, c.WHMUsername, c.TLS)
 }
 
 // String returns a safe string representation of Route53Config with sensitive fields redacted
 func (c *Route53Config) String() string {
-	return fmt.Sprintf("Route53Config{AccessKeyID:%s, SecretAccessKey:%s, Region:%s, HostedZoneID:%s}",
-		"[REDACTED]", "[REDACTED]", c.Region, c.HostedZoneID)
+	return fmt.Sprintf("Route53Config{AccessKeyID:%s, SecretAccessKey:%s, Region:%s, HostedZoneID:%s, RoleARN:%s, ExternalID:%s, RoleSessionName:%s, WaitForSync:%v, SyncTimeout:%s, AliasTargetDNSName:%s, AliasHostedZoneID:%s, SecondaryAliasTargetDNSName:%s, SecondaryAliasHostedZoneID:%s, EvaluateTargetHealth:%v, SetIdentifier:%s, Failover:%s, HealthCheckID:%s}",
+		"[REDACTED]", "[REDACTED]", c.Region, c.HostedZoneID, c.RoleARN, c.ExternalID, c.RoleSessionName, c.WaitForSync, c.SyncTimeout,
+		c.AliasTargetDNSName, c.AliasHostedZoneID, c.SecondaryAliasTargetDNSName, c.SecondaryAliasHostedZoneID, c.EvaluateTargetHealth,
+		c.SetIdentifier, c.Failover, c.HealthCheckID)
 }
 
 // String returns a safe string representation of HetznerConfig with sensitive fields redacted
 func (c *HetznerConfig) String() string {
-	return fmt.Sprintf("HetznerConfig{APIToken:%s, ZoneID:%s}",
-		"[REDACTED]", c.ZoneID)
+	return fmt.Sprintf("HetznerConfig{APIToken:%s, ZoneID:%s, API:%s, Zone:%s, Endpoint:%s}",
+		"[REDACTED]", c.ZoneID, c.API, c.Zone, c.Endpoint)
+}
+
+// String returns a safe string representation of AliDNSConfig with sensitive fields redacted
+func (c *AliDNSConfig) String() string {
+	return fmt.Sprintf("AliDNSConfig{AccessKeyID:%s, AccessKeySecret:%s, Domain:%s, Endpoint:%s}",
+		"[REDACTED]", "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of NetcupConfig with sensitive fields redacted
+func (c *NetcupConfig) String() string {
+	return fmt.Sprintf("NetcupConfig{CustomerNumber:%s, APIKey:%s, APIPassword:%s, Domain:%s, Endpoint:%s}",
+		c.CustomerNumber, "[REDACTED]", "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of NamecomConfig with sensitive fields redacted
+func (c *NamecomConfig) String() string {
+	return fmt.Sprintf("NamecomConfig{Username:%s, APIToken:%s, Domain:%s, Endpoint:%s}",
+		c.Username, "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of AdGuardConfig with sensitive fields redacted
+func (c *AdGuardConfig) String() string {
+	return fmt.Sprintf("AdGuardConfig{BaseURL:%s, Username:%s, Password:%s, TLS:%+v}",
+		c.BaseURL, c.Username, "[REDACTED]", c.TLS)
+}
+
+// String returns a safe string representation of TransIPConfig with sensitive fields redacted
+func (c *TransIPConfig) String() string {
+	return fmt.Sprintf("TransIPConfig{Login:%s, PrivateKey:%s, Domain:%s, Endpoint:%s}",
+		c.Login, "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of MythicBeastsConfig with sensitive fields redacted
+func (c *MythicBeastsConfig) String() string {
+	return fmt.Sprintf("MythicBeastsConfig{KeyID:%s, Secret:%s, Zone:%s, Endpoint:%s, AuthEndpoint:%s}",
+		c.KeyID, "[REDACTED]", c.Zone, c.Endpoint, c.AuthEndpoint)
+}
+
+// String returns a safe string representation of DomeneshopConfig with sensitive fields redacted
+func (c *DomeneshopConfig) String() string {
+	return fmt.Sprintf("DomeneshopConfig{Token:%s, Secret:%s, Domain:%s, Endpoint:%s}",
+		c.Token, "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of EasyDNSConfig with sensitive fields redacted
+func (c *EasyDNSConfig) String() string {
+	return fmt.Sprintf("EasyDNSConfig{Token:%s, Key:%s, Domain:%s, Endpoint:%s}",
+		c.Token, "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of LoopiaConfig with sensitive fields redacted
+func (c *LoopiaConfig) String() string {
+	return fmt.Sprintf("LoopiaConfig{Username:%s, Password:%s, Domain:%s, Endpoint:%s}",
+		c.Username, "[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of InfomaniakConfig with sensitive fields redacted
+func (c *InfomaniakConfig) String() string {
+	return fmt.Sprintf("InfomaniakConfig{APIToken:%s, Zone:%s, Endpoint:%s}",
+		"[REDACTED]", c.Zone, c.Endpoint)
+}
+
+// String returns a safe string representation of DynuConfig with sensitive fields redacted
+func (c *DynuConfig) String() string {
+	return fmt.Sprintf("DynuConfig{APIKey:%s, Domain:%s, Endpoint:%s}",
+		"[REDACTED]", c.Domain, c.Endpoint)
+}
+
+// String returns a safe string representation of WebhookConfig with header values redacted
+func (c *WebhookConfig) String() string {
+	headers := make([]string, 0, len(c.Headers))
+	for name := range c.Headers {
+		headers = append(headers, name+":[REDACTED]")
+	}
+	sort.Strings(headers)
+	return fmt.Sprintf("WebhookConfig{URL:%s, Method:%s, Headers:[%s], GetURL:%s, DeleteURL:%s, ValidateURL:%s, TLS:%+v}",
+		c.URL, c.Method, strings.Join(headers, " "), c.GetURL, c.DeleteURL, c.ValidateURL, c.TLS)
+}
+
+// String returns a safe string representation of PluginConfig with environment values redacted
+func (c *PluginConfig) String() string {
+	env := make([]string, 0, len(c.Env))
+	for name := range c.Env {
+		env = append(env, name+":[REDACTED]")
+	}
+	sort.Strings(env)
+	return fmt.Sprintf("PluginConfig{Command:%s, Env:[%s]}", c.Command, strings.Join(env, " "))
 }
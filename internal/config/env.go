@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// envVarPattern matches ${VAR} references in configuration values
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// MissingEnvVar describes a configuration field that references an unset or empty environment variable
+type MissingEnvVar struct {
+	Variable string
+	Field    string
+}
+
+// String returns a human readable representation of the missing variable
+func (m MissingEnvVar) String() string {
+	return fmt.Sprintf("%s (%s)", m.Variable, m.Field)
+}
+
+// expandEnvVars replaces ${VAR} references in all string fields of the configuration
+// with the value of the environment variable and returns the references that were unset or empty
+func expandEnvVars(cfg *Config) []MissingEnvVar {
+	var missing []MissingEnvVar
+	expandValue(reflect.ValueOf(cfg), "", &missing)
+	return missing
+}
+
+// expandValue recursively expands environment variable references in v
+func expandValue(v reflect.Value, path string, missing *[]MissingEnvVar) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			expandValue(v.Elem(), path, missing)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			expandValue(v.Field(i), joinPath(path, fieldName(field)), missing)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			expandValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), missing)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			expanded := expandString(v.MapIndex(key).String(), joinPath(path, fmt.Sprint(key.Interface())), missing)
+			v.SetMapIndex(key, reflect.ValueOf(expanded).Convert(v.Type().Elem()))
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(expandString(v.String(), path, missing))
+		}
+	}
+}
+
+// expandString replaces ${VAR} references in s, recording unset or empty variables
+func expandString(s, path string, missing *[]MissingEnvVar) string {
+	return envVarPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envVarPattern.FindStringSubmatch(ref)[1]
+		value := os.Getenv(name)
+		if value == "" {
+			*missing = append(*missing, MissingEnvVar{Variable: name, Field: path})
+		}
+		return value
+	})
+}
+
+// fieldName returns the configuration key for a struct field
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("mapstructure"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// joinPath joins configuration key path segments
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envConfigContent = `
+poll_interval: "30s"
+primary_ip: "203.0.113.10"
+secondary_ip: "198.51.100.77"
+state_file: "${IPFAILOVER_TEST_STATE_DIR}/state.json"
+dns:
+  - name: "example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "${CF_TOKEN}"
+      zone_id: "${CF_ZONE}"
+    metadata:
+      owner: "${IPFAILOVER_TEST_OWNER}"
+  - name: "aws.example.com"
+    type: "A"
+    provider: "route53"
+    ttl: 300
+    route53:
+      access_key_id: "${AWS_TEST_KEY}"
+      secret_access_key: "${AWS_TEST_SECRET}"
+      region: "us-east-1"
+      hosted_zone_id: "Z123"
+`
+
+func writeEnvConfig(t *testing.T) string {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(envConfigContent), 0644))
+	return configFile
+}
+
+func TestLoadConfig_EnvVarSubstitution(t *testing.T) {
+	t.Run("expands nested values", func(t *testing.T) {
+		t.Setenv("IPFAILOVER_TEST_STATE_DIR", "/var/lib/ipfailover")
+		t.Setenv("CF_TOKEN", "cf-secret-token")
+		t.Setenv("CF_ZONE", "zone-123")
+		t.Setenv("IPFAILOVER_TEST_OWNER", "ops")
+		t.Setenv("AWS_TEST_KEY", "AKIAEXAMPLE")
+		t.Setenv("AWS_TEST_SECRET", "aws-secret")
+
+		cfg, err := config.LoadConfig(writeEnvConfig(t))
+		require.NoError(t, err)
+
+		assert.Equal(t, "/var/lib/ipfailover/state.json", cfg.StateFile)
+		assert.Equal(t, "cf-secret-token", cfg.DNS[0].Cloudflare.APIToken)
+		assert.Equal(t, "zone-123", cfg.DNS[0].Cloudflare.ZoneID)
+		assert.Equal(t, "ops", cfg.DNS[0].Metadata["owner"])
+		assert.Equal(t, "AKIAEXAMPLE", cfg.DNS[1].Route53.AccessKeyID)
+		assert.Equal(t, "aws-secret", cfg.DNS[1].Route53.SecretAccessKey)
+	})
+
+	t.Run("lists missing variables for required fields", func(t *testing.T) {
+		t.Setenv("IPFAILOVER_TEST_STATE_DIR", "/var/lib/ipfailover")
+		t.Setenv("CF_TOKEN", "")
+		t.Setenv("CF_ZONE", "zone-123")
+		t.Setenv("IPFAILOVER_TEST_OWNER", "ops")
+		t.Setenv("AWS_TEST_KEY", "AKIAEXAMPLE")
+		t.Setenv("AWS_TEST_SECRET", "")
+
+		_, err := config.LoadConfig(writeEnvConfig(t))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "api_token is required")
+		assert.Contains(t, err.Error(), "CF_TOKEN (dns[0].cloudflare.api_token)")
+		assert.Contains(t, err.Error(), "AWS_TEST_SECRET (dns[1].route53.secret_access_key)")
+	})
+
+	t.Run("empty optional value is allowed", func(t *testing.T) {
+		t.Setenv("IPFAILOVER_TEST_STATE_DIR", "/var/lib/ipfailover")
+		t.Setenv("CF_TOKEN", "cf-secret-token")
+		t.Setenv("CF_ZONE", "zone-123")
+		t.Setenv("IPFAILOVER_TEST_OWNER", "")
+		t.Setenv("AWS_TEST_KEY", "AKIAEXAMPLE")
+		t.Setenv("AWS_TEST_SECRET", "aws-secret")
+
+		cfg, err := config.LoadConfig(writeEnvConfig(t))
+		require.NoError(t, err)
+		assert.Empty(t, cfg.DNS[0].Metadata["owner"])
+	})
+}
@@ -0,0 +1,443 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProviderField describes a provider-specific configuration field
+type ProviderField struct {
+	Key         string // Configuration key, e.g. "api_token"
+	Description string
+	Example     string
+	Required    bool
+	Secret      bool
+	kind        reflect.Kind
+	optional    bool // Never required, see describeProvider
+}
+
+// ProviderInfo describes a supported DNS provider and its configuration fields
+type ProviderInfo struct {
+	Name   string
+	Fields []ProviderField
+}
+
+// validator is implemented by provider-specific configuration structs
+type validator interface {
+	Validate() error
+}
+
+// Providers returns the registry of supported DNS providers.
+// It is derived from the provider configuration structs referenced by DNSConfig,
+// so the field list, and which fields are required, cannot drift from the code.
+func Providers() []ProviderInfo {
+	t := reflect.TypeOf(DNSConfig{})
+
+	var providers []ProviderInfo
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() != reflect.Ptr || field.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		providers = append(providers, describeProvider(fieldName(field), field.Type.Elem()))
+	}
+
+	return providers
+}
+
+// LookupProvider returns the registry entry for the named provider
+func LookupProvider(name string) (ProviderInfo, error) {
+	var names []string
+	for _, provider := range Providers() {
+		if provider.Name == name {
+			return provider, nil
+		}
+		names = append(names, provider.Name)
+	}
+
+	sort.Strings(names)
+	return ProviderInfo{}, fmt.Errorf("unsupported provider %q, supported providers: %s", name, strings.Join(names, ", "))
+}
+
+// describeProvider builds the registry entry for a provider configuration struct
+func describeProvider(name string, t reflect.Type) ProviderInfo {
+	info := ProviderInfo{Name: name}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Nested sections, such as tls, are optional and only described by the schema
+		if !field.IsExported() || fieldKind(field.Type) == reflect.Struct {
+			continue
+		}
+		info.Fields = append(info.Fields, ProviderField{
+			Key:         fieldName(field),
+			Description: field.Tag.Get("desc"),
+			Example:     field.Tag.Get("example"),
+			Secret:      field.Tag.Get("secret") == "true",
+			kind:        fieldKind(field.Type),
+			optional:    field.Tag.Get("required") == "false",
+		})
+	}
+
+	// A field is required if the provider configuration fails validation without it. Fields that are
+	// only needed together with others, e.g. one of a key pair, are tagged required:"false".
+	for i := range info.Fields {
+		if info.Fields[i].optional {
+			continue
+		}
+
+		values := make(map[string]string, len(info.Fields))
+		for j, f := range info.Fields {
+			if j != i {
+				values[f.Key] = f.Example
+			}
+		}
+
+		cfg := reflect.New(t)
+		if err := setFields(cfg.Elem(), values); err != nil {
+			continue
+		}
+		if v, ok := cfg.Interface().(validator); ok && v.Validate() != nil {
+			info.Fields[i].Required = true
+		}
+	}
+
+	return info
+}
+
+// NewDNSConfig builds a validated DNS record configuration for the provider from field values keyed by configuration key
+func (p ProviderInfo) NewDNSConfig(name, recordType string, ttl int, values map[string]string) (DNSConfig, error) {
+	dnsConfig := DNSConfig{
+		Name:     name,
+		Type:     recordType,
+		Provider: p.Name,
+		TTL:      ttl,
+	}
+
+	v := reflect.ValueOf(&dnsConfig).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() != reflect.Ptr || fieldName(field) != p.Name {
+			continue
+		}
+
+		providerConfig := reflect.New(field.Type.Elem())
+		if err := setFields(providerConfig.Elem(), values); err != nil {
+			return DNSConfig{}, err
+		}
+		v.Field(i).Set(providerConfig)
+	}
+
+	if err := dnsConfig.Validate(); err != nil {
+		return DNSConfig{}, err
+	}
+
+	return dnsConfig, nil
+}
+
+// setFields sets struct fields from string values keyed by configuration key
+func setFields(v reflect.Value, values map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value, ok := values[fieldName(field)]
+		if !ok || value == "" || !field.IsExported() {
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.String:
+			v.Field(i).SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false", fieldName(field))
+			}
+			v.Field(i).SetBool(b)
+		case reflect.Int64:
+			if field.Type == reflect.TypeOf(time.Duration(0)) {
+				d, err := time.ParseDuration(value)
+				if err != nil {
+					return fmt.Errorf("%s must be a duration, e.g. 30s", fieldName(field))
+				}
+				v.Field(i).SetInt(int64(d))
+				continue
+			}
+			fallthrough
+		case reflect.Int:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s must be an integer", fieldName(field))
+			}
+			v.Field(i).SetInt(n)
+		case reflect.Map:
+			entries, err := parseMapValue(value)
+			if err != nil || field.Type.Elem().Kind() != reflect.String {
+				return fmt.Errorf("%s must be one or more \"Name: value\" lines", fieldName(field))
+			}
+			v.Field(i).Set(reflect.ValueOf(entries))
+		case reflect.Ptr:
+			// Optional booleans, where nil means unset
+			b, err := strconv.ParseBool(value)
+			if err != nil || field.Type.Elem().Kind() != reflect.Bool {
+				return fmt.Errorf("%s must be true or false", fieldName(field))
+			}
+			v.Field(i).Set(reflect.ValueOf(&b))
+		default:
+			return fmt.Errorf("%s has unsupported type %s", fieldName(field), field.Type)
+		}
+	}
+	return nil
+}
+
+// fieldKind returns the kind of a field type, or of the pointed-to type for optional fields
+func fieldKind(t reflect.Type) reflect.Kind {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem().Kind()
+	}
+	return t.Kind()
+}
+
+// Snippet returns a commented YAML DNS record block for the provider with example values.
+// Optional fields are commented out.
+func (p ProviderInfo) Snippet() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s DNS record; add under the top-level \"dns:\" list\n", p.Name)
+	b.WriteString("- name: \"home.example.com\" # required: fully qualified record name\n")
+	b.WriteString("  type: \"A\" # required: A or AAAA\n")
+	fmt.Fprintf(&b, "  provider: %q\n", p.Name)
+	b.WriteString("  ttl: 300 # required: record TTL in seconds\n")
+	fmt.Fprintf(&b, "  %s:\n", p.Name)
+
+	for _, field := range p.Fields {
+		requirement := "optional"
+		if field.Required {
+			requirement = "required"
+		}
+		if field.Secret {
+			requirement += ", secret"
+		}
+
+		fmt.Fprintf(&b, "    # %s (%s)\n", field.Description, requirement)
+		prefix := "    "
+		if !field.Required {
+			prefix += "# "
+		}
+		fmt.Fprintf(&b, "%s%s: %s\n", prefix, field.Key, field.yamlValue(field.Example))
+	}
+
+	return b.String()
+}
+
+// RecordYAML returns the YAML DNS record block for a record built by NewDNSConfig
+func (p ProviderInfo) RecordYAML(dnsConfig DNSConfig, values map[string]string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "- name: %q\n", dnsConfig.Name)
+	fmt.Fprintf(&b, "  type: %q\n", dnsConfig.Type)
+	fmt.Fprintf(&b, "  provider: %q\n", dnsConfig.Provider)
+	fmt.Fprintf(&b, "  ttl: %d\n", dnsConfig.TTL)
+	fmt.Fprintf(&b, "  %s:\n", p.Name)
+
+	for _, field := range p.Fields {
+		value, ok := values[field.Key]
+		if !ok || value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s: %s\n", field.Key, field.yamlValue(value))
+	}
+
+	return b.String()
+}
+
+// yamlValue formats a value for the field's type as a YAML scalar
+func (f ProviderField) yamlValue(value string) string {
+	switch f.kind {
+	case reflect.Bool, reflect.Int, reflect.Int64:
+		return value
+	case reflect.Map:
+		// Rendered as a YAML flow mapping
+		entries, err := parseMapValue(value)
+		if err != nil {
+			return strconv.Quote(value)
+		}
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, strconv.Quote(key)+": "+strconv.Quote(entries[key]))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	default:
+		return strconv.Quote(value)
+	}
+}
+
+// parseMapValue parses the string form of a map field: one "Name: value" entry per line
+func parseMapValue(value string) (map[string]string, error) {
+	entries := make(map[string]string)
+	for _, line := range strings.Split(value, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, entry, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid entry %q", line)
+		}
+		entries[strings.TrimSpace(key)] = strings.TrimSpace(entry)
+	}
+	return entries, nil
+}
+
+// AppendDNSRecord inserts a DNS record block, as returned by RecordYAML, at the end of the "dns:" list
+// of an existing configuration file. Comments and formatting of the rest of the file are preserved.
+// The file is only replaced if it still parses and the appended record validates. The rest of the
+// file is not validated, so it may reference environment variables that are unset in this shell.
+func AppendDNSRecord(configPath, record string) error {
+	if format := ConfigType(configPath); format != "yaml" {
+		return fmt.Errorf("appending DNS records is only supported for YAML configuration files, %s is %s", configPath, strings.ToUpper(format))
+	}
+
+	existing, err := readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	updated, err := insertDNSRecord(string(data), record)
+	if err != nil {
+		return err
+	}
+
+	// Write next to the original so the final rename is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), "."+filepath.Base(configPath)+".*"+filepath.Ext(configPath))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary config file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	if _, err := tmp.WriteString(updated); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary config file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+
+	updatedConfig, err := readConfig(tmpPath)
+	if err != nil {
+		return fmt.Errorf("configuration with new record is invalid: %w", err)
+	}
+	if len(updatedConfig.DNS) != len(existing.DNS)+1 {
+		return fmt.Errorf("configuration with new record has %d DNS records, expected %d", len(updatedConfig.DNS), len(existing.DNS)+1)
+	}
+	added := updatedConfig.DNS[len(updatedConfig.DNS)-1]
+	if err := added.Validate(); err != nil {
+		return fmt.Errorf("appended DNS record is invalid: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, configPath); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
+
+	return nil
+}
+
+// insertDNSRecord inserts a record block after the last entry of the top-level "dns:" list,
+// matching the indentation of the existing entries
+func insertDNSRecord(content, record string) (string, error) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	dnsLine := -1
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "dns:") {
+			continue
+		}
+		if key, _, _ := strings.Cut(line, "#"); strings.TrimSpace(key) != "dns:" {
+			return "", fmt.Errorf("the dns key must be a block list to append records, found %q", line)
+		}
+		dnsLine = i
+		break
+	}
+
+	if dnsLine == -1 {
+		return strings.Join(lines, "\n") + "\n\ndns:\n" + indentBlock(record, "  "), nil
+	}
+
+	// The list ends at the next top-level key; trailing blank lines and
+	// top-level comments belong to whatever follows
+	end := len(lines)
+	indent := "  "
+	foundItem := false
+	for i := dnsLine + 1; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(trimmed, "-") {
+			end = i
+			break
+		}
+		if !foundItem && strings.HasPrefix(trimmed, "-") {
+			indent = line[:len(line)-len(strings.TrimLeft(line, " "))]
+			foundItem = true
+		}
+	}
+	for end > dnsLine+1 {
+		previous := lines[end-1]
+		if strings.TrimSpace(previous) != "" && !strings.HasPrefix(previous, "#") {
+			break
+		}
+		end--
+	}
+
+	block := strings.Split(strings.TrimRight(indentBlock(record, indent), "\n"), "\n")
+	if foundItem {
+		block = append([]string{""}, block...)
+	}
+
+	result := make([]string, 0, len(lines)+len(block))
+	result = append(result, lines[:end]...)
+	result = append(result, block...)
+	result = append(result, lines[end:]...)
+
+	return strings.Join(result, "\n") + "\n", nil
+}
+
+// indentBlock prefixes every non-empty line of block with indent
+func indentBlock(block, indent string) string {
+	lines := strings.Split(strings.TrimRight(block, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,206 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviders(t *testing.T) {
+	providers := config.Providers()
+
+	var names []string
+	for _, provider := range providers {
+		names = append(names, provider.Name)
+
+		t.Run(provider.Name, func(t *testing.T) {
+			require.NotEmpty(t, provider.Fields)
+
+			values := make(map[string]string)
+			for _, field := range provider.Fields {
+				assert.NotEmpty(t, field.Description, "field %s has no description", field.Key)
+				assert.NotEmpty(t, field.Example, "field %s has no example", field.Key)
+				values[field.Key] = field.Example
+			}
+			if provider.Name == "cloudflare" {
+				// The Global API Key and email replace the API token
+				delete(values, "api_key")
+				delete(values, "api_email")
+			}
+
+			// The registry examples must form a valid configuration
+			_, err := provider.NewDNSConfig("home.example.com", "A", 300, values)
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.Contains(t, names, "cloudflare")
+	assert.Contains(t, names, "netcup")
+}
+
+func TestLookupProvider(t *testing.T) {
+	provider, err := config.LookupProvider("cloudflare")
+	require.NoError(t, err)
+
+	fields := make(map[string]config.ProviderField)
+	for _, field := range provider.Fields {
+		fields[field.Key] = field
+	}
+
+	// Either api_token or api_key with api_email is required
+	assert.False(t, fields["api_token"].Required)
+	assert.True(t, fields["api_token"].Secret)
+	assert.False(t, fields["api_key"].Required)
+	assert.True(t, fields["api_key"].Secret)
+	assert.False(t, fields["api_email"].Secret)
+	assert.False(t, fields["zone_id"].Required)
+	assert.False(t, fields["zone_id"].Secret)
+	assert.False(t, fields["proxied"].Required)
+	assert.False(t, fields["proxied_primary"].Required)
+	assert.Contains(t, provider.Snippet(), "    # proxied_primary: true\n")
+
+	route53, err := config.LookupProvider("route53")
+	require.NoError(t, err)
+	for _, field := range route53.Fields {
+		switch field.Key {
+		case "region", "hosted_zone_id":
+			assert.True(t, field.Required, field.Key)
+		default:
+			assert.False(t, field.Required, "%s is optional, credentials default to the AWS credential chain", field.Key)
+		}
+	}
+
+	_, err = config.LookupProvider("unknown")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "supported providers")
+}
+
+func TestProviderInfo_Snippet(t *testing.T) {
+	provider, err := config.LookupProvider("alidns")
+	require.NoError(t, err)
+
+	snippet := provider.Snippet()
+	assert.Contains(t, snippet, `provider: "alidns"`)
+	assert.Contains(t, snippet, "# Alibaba Cloud AccessKey secret (required, secret)")
+	assert.Contains(t, snippet, `    access_key_secret: "${ALIDNS_ACCESS_KEY_SECRET}"`)
+	// Optional fields are commented out
+	assert.Contains(t, snippet, `    # endpoint: "https://alidns.aliyuncs.com"`)
+}
+
+func TestAppendDNSRecord(t *testing.T) {
+	provider, err := config.LookupProvider("hetzner")
+	require.NoError(t, err)
+
+	values := map[string]string{"api_token": "secret-token", "zone_id": "zone-123"}
+	dnsConfig, err := provider.NewDNSConfig("new.example.com", "A", 600, values)
+	require.NoError(t, err)
+	record := provider.RecordYAML(dnsConfig, values)
+
+	t.Run("appends after existing records", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		content := `# main config
+poll_interval: "30s"
+primary_ip: "203.0.113.10"
+secondary_ip: "198.51.100.77"
+dns:
+  - name: "example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "token" # keep this comment
+      zone_id: "zone"
+
+# logging
+log_level: "debug"
+`
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+		require.NoError(t, config.AppendDNSRecord(configFile, record))
+
+		cfg, err := config.LoadConfig(configFile)
+		require.NoError(t, err)
+		require.Len(t, cfg.DNS, 2)
+		assert.Equal(t, "new.example.com", cfg.DNS[1].Name)
+		assert.Equal(t, "secret-token", cfg.DNS[1].Hetzner.APIToken)
+		assert.Equal(t, "debug", cfg.LogLevel)
+
+		data, err := os.ReadFile(configFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "# keep this comment")
+		assert.Contains(t, string(data), "  - name: \"new.example.com\"")
+
+		info, err := os.Stat(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("does not require environment variables of other records", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		content := `poll_interval: "30s"
+primary_ip: "203.0.113.10"
+secondary_ip: "198.51.100.77"
+dns:
+  - name: "example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "${IPFAILOVER_TEST_UNSET_TOKEN}"
+      zone_id: "zone"
+`
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0644))
+
+		require.NoError(t, config.AppendDNSRecord(configFile, record))
+
+		data, err := os.ReadFile(configFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "${IPFAILOVER_TEST_UNSET_TOKEN}")
+		assert.Contains(t, string(data), "new.example.com")
+	})
+
+	t.Run("invalid record leaves file untouched", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		content := `dns:
+  - name: "example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+`
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0644))
+
+		err := config.AppendDNSRecord(configFile, "- name: \"broken.example.com\"\n  provider: \"hetzner\"\n")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "appended DNS record is invalid")
+
+		data, err := os.ReadFile(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+
+		entries, err := os.ReadDir(filepath.Dir(configFile))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("rejects non-YAML config files", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(configFile, []byte(`{"dns": []}`), 0644))
+
+		err := config.AppendDNSRecord(configFile, record)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "only supported for YAML")
+	})
+
+	t.Run("rejects flow style dns list", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("dns: []\n"), 0644))
+
+		err := config.AppendDNSRecord(configFile, record)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "block list")
+	})
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyDirect as proxy_url sends requests directly, ignoring the proxy environment variables and,
+// in the http block of a record, the global proxy_url
+const ProxyDirect = "direct"
+
+// ProxyFunc selects the proxy of an HTTP request, see http.Transport.Proxy
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// validateProxyURL checks that proxyURL is empty, ProxyDirect, or an http, https, socks5 or socks5h URL
+func validateProxyURL(proxyURL string) error {
+	if proxyURL == "" || proxyURL == ProxyDirect {
+		return nil
+	}
+
+	// The parse error is not wrapped, it contains the URL with its credentials
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("proxy_url is not a valid URL")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("proxy_url must be an http, https, socks5 or socks5h URL or %q, got scheme %q", ProxyDirect, u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy_url %q has no host", u.Redacted())
+	}
+	return nil
+}
+
+// ProxyFunc returns the proxy of the IP check requests, see RecordProxyFunc
+func (c *Config) ProxyFunc() ProxyFunc {
+	return newProxyFunc(c.ProxyURL, c.NoProxy)
+}
+
+// RecordProxyFunc returns the proxy of the provider API requests of a record: the proxy_url of its
+// http block, or else the global proxy_url, bypassed for the hosts in no_proxy. It returns nil if
+// neither is set, so the default transport applies, which honors the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables.
+func (c *Config) RecordProxyFunc(dns DNSConfig) ProxyFunc {
+	proxyURL := dns.HTTP.ProxyURL
+	if proxyURL == "" {
+		proxyURL = c.ProxyURL
+	}
+	return newProxyFunc(proxyURL, c.NoProxy)
+}
+
+// newProxyFunc returns the proxy function of proxyURL, which validateProxyURL accepted
+func newProxyFunc(proxyURL string, noProxy []string) ProxyFunc {
+	switch proxyURL {
+	case "":
+		return nil
+	case ProxyDirect:
+		return func(*http.Request) (*url.URL, error) { return nil, nil }
+	}
+
+	// As with NO_PROXY, requests to localhost and loopback addresses are never proxied
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    strings.Join(noProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
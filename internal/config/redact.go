@@ -0,0 +1,254 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Redacted is the placeholder of secret values in redacted configurations
+const Redacted = "[REDACTED]"
+
+// ChangeKind describes how a configuration key differs between two configurations
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change is a single difference between two redacted configurations
+type Change struct {
+	Path string      `json:"path"`
+	Kind ChangeKind  `json:"kind"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// String returns a one-line representation of the change, prefixed with +, - or ~
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+	}
+}
+
+// Redact returns the canonical form of the configuration with secrets replaced by Redacted.
+// Keys are the configuration keys, durations are rendered as strings and unset provider
+// sections are omitted, so the result serializes to the same JSON or YAML for equal configurations.
+func (c *Config) Redact() map[string]interface{} {
+	redacted, _ := redactValue(reflect.ValueOf(c)).(map[string]interface{})
+	return redacted
+}
+
+// redactValue converts v into plain maps, slices and scalars, replacing non-empty secret strings
+func redactValue(v reflect.Value) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		fields := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get("secret") == "true" && v.Field(i).Kind() == reflect.String {
+				if v.Field(i).String() != "" {
+					fields[fieldName(field)] = Redacted
+				} else {
+					fields[fieldName(field)] = ""
+				}
+				continue
+			}
+			// Secret maps keep their keys, e.g. header names, and redact the values
+			if field.Tag.Get("secret") == "true" && v.Field(i).Kind() == reflect.Map && !v.Field(i).IsNil() {
+				entries := make(map[string]interface{}, v.Field(i).Len())
+				for _, key := range v.Field(i).MapKeys() {
+					entries[fmt.Sprint(key.Interface())] = Redacted
+				}
+				fields[fieldName(field)] = entries
+				continue
+			}
+			if value := redactValue(v.Field(i)); value != nil {
+				fields[fieldName(field)] = value
+			}
+		}
+		return fields
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = redactValue(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			entries[fmt.Sprint(key.Interface())] = redactValue(v.MapIndex(key))
+		}
+		return entries
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// Diff compares two redacted configurations and returns the added, removed and changed keys
+// sorted by path. Lists of objects with name and type keys, such as dns, are matched by those
+// keys rather than by position, so reordering records is not reported as a change.
+// Values decoded from JSON compare equal to values produced by Redact.
+func Diff(running, candidate map[string]interface{}) []Change {
+	var changes []Change
+	diffValues("", normalize(running), normalize(candidate), &changes)
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// diffValues appends the differences between old and new at path to changes
+func diffValues(path string, old, new interface{}, changes *[]Change) {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		diffMaps(oldMap, newMap, changes, func(key string) string { return joinPath(path, key) })
+		return
+	}
+
+	oldKeyed, oldIsKeyed := keyedList(old)
+	newKeyed, newIsKeyed := keyedList(new)
+	if oldIsKeyed && newIsKeyed {
+		diffMaps(oldKeyed, newKeyed, changes, func(key string) string { return path + key })
+		return
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, Change{Path: path, Kind: ChangeChanged, Old: old, New: new})
+	}
+}
+
+// diffMaps appends the differences between the entries of old and new to changes,
+// using keyPath to build the path of each entry
+func diffMaps(old, new map[string]interface{}, changes *[]Change, keyPath func(key string) string) {
+	for key, oldValue := range old {
+		newValue, ok := new[key]
+		if !ok {
+			*changes = append(*changes, Change{Path: keyPath(key), Kind: ChangeRemoved, Old: oldValue})
+			continue
+		}
+		diffValues(keyPath(key), oldValue, newValue, changes)
+	}
+	for key, newValue := range new {
+		if _, ok := old[key]; !ok {
+			*changes = append(*changes, Change{Path: keyPath(key), Kind: ChangeAdded, New: newValue})
+		}
+	}
+}
+
+// keyedList returns a list of objects as a map keyed by "[name type]", or "[id]" for objects
+// with an id, if every object has a unique key
+func keyedList(value interface{}) (map[string]interface{}, bool) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, false
+	}
+
+	keyed := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, nameOK := object["name"].(string)
+		recordType, typeOK := object["type"].(string)
+		if !nameOK || !typeOK {
+			return nil, false
+		}
+		key := fmt.Sprintf("[%s %s]", name, recordType)
+		if id, _ := object["id"].(string); id != "" {
+			key = fmt.Sprintf("[%s]", id)
+		}
+		if _, duplicate := keyed[key]; duplicate {
+			return nil, false
+		}
+		keyed[key] = object
+	}
+	return keyed, true
+}
+
+// normalize converts numbers to float64, as decoded from JSON, so configurations
+// produced by Redact compare equal to configurations fetched from the API
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[key] = normalize(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized[i] = normalize(item)
+		}
+		return normalized
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return value
+	}
+}
+
+// formatValue renders a configuration value for a diff line
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
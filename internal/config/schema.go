@@ -0,0 +1,353 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// schemaJSON is the JSON Schema of the configuration file as generated by GenerateSchema.
+// Regenerate it with "go test ./internal/config -run TestSchema -update" after changing the configuration structs.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// durationPattern matches durations accepted by time.ParseDuration, e.g. "30s" or "1h30m"
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// schemaNode is the subset of a draft-07 JSON Schema used to describe the configuration
+type schemaNode struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*schemaNode `json:"properties,omitempty"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties,omitempty"`
+	Items                *schemaNode            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Const                string                 `json:"const,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+	AllOf                []*schemaNode          `json:"allOf,omitempty"`
+	If                   *schemaNode            `json:"if,omitempty"`
+	Then                 *schemaNode            `json:"then,omitempty"`
+}
+
+// additionalProperties is either false, rejecting unknown keys, or the schema of the values of all keys
+type additionalProperties struct {
+	schema *schemaNode
+}
+
+// MarshalJSON encodes the value as false or as the schema
+func (a *additionalProperties) MarshalJSON() ([]byte, error) {
+	if a.schema == nil {
+		return []byte("false"), nil
+	}
+	return json.Marshal(a.schema)
+}
+
+// UnmarshalJSON decodes false or a schema
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "false" {
+		a.schema = nil
+		return nil
+	}
+	a.schema = &schemaNode{}
+	return json.Unmarshal(data, a.schema)
+}
+
+// Schema returns the JSON Schema of the configuration file embedded in the binary
+func Schema() []byte {
+	return schemaJSON
+}
+
+// GenerateSchema builds the JSON Schema of the configuration file from the configuration structs.
+// Keys come from mapstructure tags and descriptions, allowed values and required keys from the desc,
+// enum and required tags. Provider names and required provider fields come from Providers.
+func GenerateSchema() ([]byte, error) {
+	root := schemaFor(reflect.TypeOf(Config{}))
+	root.Schema = "http://json-schema.org/draft-07/schema#"
+	root.Title = "ipfailover configuration"
+
+	// Each DNS record must configure the provider it names
+	record := root.Properties["dns"].Items
+	for _, provider := range Providers() {
+		record.Properties["provider"].Enum = append(record.Properties["provider"].Enum, provider.Name)
+
+		providerSchema := record.Properties[provider.Name]
+		for _, field := range provider.Fields {
+			if field.Required {
+				providerSchema.Required = append(providerSchema.Required, field.Key)
+			}
+			if field.Secret {
+				providerSchema.Properties[field.Key].WriteOnly = true
+			}
+		}
+
+		record.AllOf = append(record.AllOf, &schemaNode{
+			If: &schemaNode{
+				Properties: map[string]*schemaNode{"provider": {Const: provider.Name}},
+				Required:   []string{"provider"},
+			},
+			Then: &schemaNode{Required: []string{provider.Name}},
+		})
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config schema: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// schemaFor returns the schema of a configuration value of type t
+func schemaFor(t reflect.Type) *schemaNode {
+	if t == durationType {
+		return &schemaNode{Type: "string", Pattern: durationPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return &schemaNode{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &schemaNode{Type: "object", AdditionalProperties: &additionalProperties{schema: schemaFor(t.Elem())}}
+	case reflect.Bool:
+		return &schemaNode{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schemaNode{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schemaNode{Type: "number"}
+	default:
+		return &schemaNode{Type: "string"}
+	}
+}
+
+// structSchema returns the schema of a configuration struct, which rejects unknown keys
+func structSchema(t reflect.Type) *schemaNode {
+	node := &schemaNode{
+		Type:                 "object",
+		Properties:           make(map[string]*schemaNode),
+		AdditionalProperties: &additionalProperties{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := fieldName(field)
+		property := schemaFor(field.Type)
+		property.Description = field.Tag.Get("desc")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		if field.Tag.Get("required") == "true" {
+			node.Required = append(node.Required, key)
+		}
+		node.Properties[key] = property
+	}
+
+	return node
+}
+
+// validateConfigFile checks the contents of a configuration file, with any overlays merged on top,
+// against the embedded schema. Defaults and environment overrides are not applied, so only what the
+// files themselves set is checked.
+func validateConfigFile(configPath string, overlays ...string) error {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetConfigType(ConfigType(configPath))
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := mergeOverlays(v, overlays); err != nil {
+		return err
+	}
+
+	var schema schemaNode
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		return fmt.Errorf("failed to parse config schema: %w", err)
+	}
+
+	if violations := schema.validate(v.AllSettings(), ""); len(violations) > 0 {
+		return fmt.Errorf("config does not match schema: %s", strings.Join(violations, "; "))
+	}
+
+	return nil
+}
+
+// validate checks a decoded configuration value against the schema and returns a message
+// for each violation, prefixed with the key path of the offending value
+func (s *schemaNode) validate(value interface{}, path string) []string {
+	// Keys without a value decode as if they were absent
+	if value == nil {
+		return nil
+	}
+
+	actual := valueType(value)
+	if s.Type != "" && s.Type != actual && (s.Type != "number" || actual != "integer") {
+		return []string{fmt.Sprintf("%s: must be %s, got %s", displayPath(path), article(s.Type), actual)}
+	}
+
+	var violations []string
+	switch actual {
+	case "string":
+		violations = append(violations, s.validateString(value.(string), path)...)
+	case "object":
+		violations = append(violations, s.validateObject(reflect.ValueOf(value), path)...)
+	case "array":
+		if s.Items != nil {
+			v := reflect.ValueOf(value)
+			for i := 0; i < v.Len(); i++ {
+				violations = append(violations, s.Items.validate(v.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		if sub.If != nil && len(sub.If.validate(value, path)) > 0 {
+			continue
+		}
+		if sub.Then != nil {
+			violations = append(violations, sub.Then.validate(value, path)...)
+		}
+	}
+
+	return violations
+}
+
+// validateString checks the allowed values and pattern of a string. Values referencing environment
+// variables are only known after expansion and are checked by Validate instead.
+func (s *schemaNode) validateString(value, path string) []string {
+	if s.Const != "" && value != s.Const {
+		return []string{fmt.Sprintf("%s: must be %q, got %q", displayPath(path), s.Const, value)}
+	}
+
+	if envVarPattern.MatchString(value) {
+		return nil
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, option := range s.Enum {
+			if value == option {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return []string{fmt.Sprintf("%s: must be one of %s, got %q", displayPath(path), strings.Join(s.Enum, ", "), value)}
+		}
+	}
+
+	if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(value) {
+		return []string{fmt.Sprintf("%s: invalid value %q", displayPath(path), value)}
+	}
+
+	return nil
+}
+
+// validateObject checks the keys of a map. Keys are matched case-insensitively like when unmarshalling.
+func (s *schemaNode) validateObject(v reflect.Value, path string) []string {
+	values := make(map[string]interface{}, v.Len())
+	keys := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		name := fmt.Sprint(key.Interface())
+		values[name] = v.MapIndex(key).Interface()
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	var violations []string
+	present := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		value := values[key]
+		if value != nil {
+			present[strings.ToLower(key)] = true
+		}
+
+		if property, ok := s.Properties[strings.ToLower(key)]; ok {
+			violations = append(violations, property.validate(value, joinPath(path, key))...)
+			continue
+		}
+
+		switch {
+		case s.AdditionalProperties == nil:
+			// Unknown keys are allowed
+		case s.AdditionalProperties.schema == nil:
+			violations = append(violations, fmt.Sprintf("%s: unknown key", displayPath(joinPath(path, key))))
+		default:
+			violations = append(violations, s.AdditionalProperties.schema.validate(value, joinPath(path, key))...)
+		}
+	}
+
+	for _, key := range s.Required {
+		if !present[key] {
+			violations = append(violations, fmt.Sprintf("%s: missing required key %s", displayPath(path), key))
+		}
+	}
+
+	return violations
+}
+
+// valueType returns the JSON Schema type of a decoded configuration value.
+// Whole numbers are integers regardless of how the file format decoded them.
+func valueType(value interface{}) string {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// article prefixes a JSON Schema type name with an indefinite article
+func article(schemaType string) string {
+	if strings.ContainsAny(schemaType[:1], "aeiou") {
+		return "an " + schemaType
+	}
+	return "a " + schemaType
+}
+
+// displayPath returns the key path used in validation messages, with the file root shown as "config"
+func displayPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
@@ -0,0 +1,188 @@
+package config_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateSchema = flag.Bool("update", false, "regenerate the embedded config schema")
+
+func TestSchema_UpToDate(t *testing.T) {
+	generated, err := config.GenerateSchema()
+	require.NoError(t, err)
+
+	if *updateSchema {
+		require.NoError(t, os.WriteFile("schema.json", generated, 0644))
+		return
+	}
+
+	assert.Equal(t, string(generated), string(config.Schema()),
+		"schema.json is out of date with the configuration structs, run: go test ./internal/config -run TestSchema -update")
+}
+
+func TestSchema_Contents(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(config.Schema(), &schema))
+
+	// Every key is documented
+	var undocumented []string
+	var walk func(node map[string]interface{}, path string)
+	walk = func(node map[string]interface{}, path string) {
+		properties, _ := node["properties"].(map[string]interface{})
+		for key, value := range properties {
+			property := value.(map[string]interface{})
+			if property["description"] == nil {
+				undocumented = append(undocumented, path+key)
+			}
+			walk(property, path+key+".")
+			if items, ok := property["items"].(map[string]interface{}); ok {
+				walk(items, path+key+"[].")
+			}
+		}
+	}
+	walk(schema, "")
+	sort.Strings(undocumented)
+	assert.Empty(t, undocumented, "configuration fields without a desc tag")
+
+	// Provider names and required provider fields match the provider registry
+	record := schema["properties"].(map[string]interface{})["dns"].(map[string]interface{})["items"].(map[string]interface{})
+	recordProperties := record["properties"].(map[string]interface{})
+	var names []interface{}
+	for _, provider := range config.Providers() {
+		names = append(names, provider.Name)
+
+		var required []interface{}
+		for _, field := range provider.Fields {
+			if field.Required {
+				required = append(required, field.Key)
+			}
+		}
+		providerSchema := recordProperties[provider.Name].(map[string]interface{})
+		if required == nil {
+			assert.Nil(t, providerSchema["required"], provider.Name)
+			continue
+		}
+		assert.Equal(t, required, providerSchema["required"], provider.Name)
+	}
+	assert.Equal(t, names, recordProperties["provider"].(map[string]interface{})["enum"])
+}
+
+func TestLoadConfig_Schema(t *testing.T) {
+	const validRecord = `
+dns:
+  - name: "home.example.com"
+    type: "A"
+    provider: "cloudflare"
+    ttl: 300
+    cloudflare:
+      api_token: "test-token"
+      zone_id: "test-zone"
+`
+	const header = `
+poll_interval: "30s"
+primary_ip: "203.0.113.10"
+secondary_ip: "198.51.100.77"
+state_file: "/tmp/state.json"
+`
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown provider key",
+			file:    "config.yaml",
+			content: header + validRecord + "      zone_idd: \"typo\"\n",
+			wantErr: "dns[0].cloudflare.zone_idd: unknown key",
+		},
+		{
+			name:    "unknown top-level key",
+			file:    "config.yaml",
+			content: header + "failover_retry: 3\n" + validRecord,
+			wantErr: "failover_retry: unknown key",
+		},
+		{
+			name:    "wrong type",
+			file:    "config.yaml",
+			content: header + "failover_retries: \"three\"\n" + validRecord,
+			wantErr: "failover_retries: must be an integer, got string",
+		},
+		{
+			name:    "invalid duration",
+			file:    "config.yaml",
+			content: "poll_interval: \"30 seconds\"\nprimary_ip: \"203.0.113.10\"\nsecondary_ip: \"198.51.100.77\"\n" + validRecord,
+			wantErr: `poll_interval: invalid value "30 seconds"`,
+		},
+		{
+			name:    "value not allowed",
+			file:    "config.yaml",
+			content: header + "conflict_policy: \"mine\"\n" + validRecord,
+			wantErr: `conflict_policy: must be one of ours-wins, theirs-wins, alert-only, got "mine"`,
+		},
+		{
+			name: "missing provider block",
+			file: "config.yaml",
+			content: header + `
+dns:
+  - name: "home.example.com"
+    type: "A"
+    provider: "hetzner"
+    ttl: 300
+`,
+			wantErr: "dns[0]: missing required key hetzner",
+		},
+		{
+			name: "missing required provider field",
+			file: "config.yaml",
+			content: header + `
+dns:
+  - name: "home.example.com"
+    type: "A"
+    provider: "hetzner"
+    ttl: 300
+    hetzner:
+      zone_id: "test-zone"
+`,
+			wantErr: "dns[0].hetzner: missing required key api_token",
+		},
+		{
+			name:    "unknown key in JSON",
+			file:    "config.json",
+			content: `{"poll_interval": "30s", "primary_ip": "203.0.113.10", "secondary_ip": "198.51.100.77", "dns": [{"name": "home.example.com", "type": "A", "provider": "cloudflare", "ttl": 300.5, "cloudflare": {"api_token": "t", "zone_id": "z"}}]}`,
+			wantErr: "dns[0].ttl: must be an integer, got number",
+		},
+		{
+			name:    "environment variable reference is checked after expansion",
+			file:    "config.yaml",
+			content: header + "conflict_policy: \"${IPFAILOVER_TEST_POLICY}\"\n" + validRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IPFAILOVER_TEST_POLICY", "theirs-wins")
+
+			configFile := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(configFile, []byte(tt.content), 0644))
+
+			_, err := config.LoadConfig(configFile)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "config does not match schema")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig represents the TLS settings of connections to a self-hosted provider API,
+// e.g. one with a certificate from an internal CA
+type TLSConfig struct {
+	CACertFile         string `mapstructure:"ca_cert_file" desc:"PEM file with CA certificates trusted for the API server in addition to the system roots"`
+	ClientCertFile     string `mapstructure:"client_cert_file" desc:"PEM client certificate for mutual TLS, set together with client_key_file"`
+	ClientKeyFile      string `mapstructure:"client_key_file" desc:"PEM private key of client_cert_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify" desc:"Do not verify the API server certificate; insecure, for testing only"`
+}
+
+// Validate checks that the certificate and key files can be loaded
+func (t *TLSConfig) Validate() error {
+	_, err := t.ClientConfig()
+	return err
+}
+
+// ClientConfig builds the tls.Config of connections to the provider API from the configured files.
+// It returns nil for a nil TLSConfig, so the default TLS settings apply.
+func (t *TLSConfig) ClientConfig() (*tls.Config, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify, // Explicitly requested, see Config.Warnings
+	}
+
+	if t.CACertFile != "" {
+		pem, err := os.ReadFile(t.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_cert_file: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert_file %s contains no PEM certificates", t.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (t.ClientCertFile == "") != (t.ClientKeyFile == "") {
+		return nil, fmt.Errorf("client_cert_file and client_key_file must be set together")
+	}
+	if t.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCertFile, t.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
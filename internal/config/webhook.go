@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// WebhookRecord is the data webhook templates are rendered with, e.g. {{.Name}} or {{json .Value}}.
+// Templates of the validate request are rendered with an empty record.
+type WebhookRecord struct {
+	Name  string
+	Type  string
+	Value string
+	TTL   int
+}
+
+// WebhookTemplates holds the parsed templates of a WebhookConfig.
+// Templates of optional requests that are not configured are nil.
+type WebhookTemplates struct {
+	URL         *template.Template
+	Body        *template.Template
+	Headers     map[string]*template.Template
+	GetURL      *template.Template
+	DeleteURL   *template.Template
+	ValidateURL *template.Template
+}
+
+// sampleWebhookRecord is used to render the templates once while validating the configuration,
+// which reports references to unknown fields at startup rather than on the first failover
+var sampleWebhookRecord = WebhookRecord{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300}
+
+// webhookMethods are the accepted request methods
+var webhookMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// webhookFuncs are the functions available to webhook templates in addition to the
+// text/template builtins such as urlquery
+var webhookFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. a quoted and escaped string for request bodies
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Validate validates webhook configuration, including its templates
+func (c *WebhookConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	if c.Method != "" && !webhookMethods[strings.ToUpper(c.Method)] {
+		return fmt.Errorf("method %q is not supported, use GET, POST, PUT, PATCH or DELETE", c.Method)
+	}
+
+	if c.DeleteMethod != "" && !webhookMethods[strings.ToUpper(c.DeleteMethod)] {
+		return fmt.Errorf("delete_method %q is not supported, use GET, POST, PUT, PATCH or DELETE", c.DeleteMethod)
+	}
+
+	if _, err := c.ParseTemplates(); err != nil {
+		return err
+	}
+
+	if err := c.TLS.Validate(); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
+	return nil
+}
+
+// ParseTemplates parses the templates of the configuration and renders each once with a sample record
+func (c *WebhookConfig) ParseTemplates() (*WebhookTemplates, error) {
+	var templates WebhookTemplates
+	var err error
+
+	if templates.URL, err = parseWebhookTemplate("url", c.URL, true); err != nil {
+		return nil, err
+	}
+	if templates.Body, err = parseWebhookTemplate("body", c.Body, false); err != nil {
+		return nil, err
+	}
+	if templates.GetURL, err = parseWebhookTemplate("get_url", c.GetURL, true); err != nil {
+		return nil, err
+	}
+	if templates.DeleteURL, err = parseWebhookTemplate("delete_url", c.DeleteURL, true); err != nil {
+		return nil, err
+	}
+	if templates.ValidateURL, err = parseWebhookTemplate("validate_url", c.ValidateURL, true); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(c.Headers))
+	for name := range c.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	templates.Headers = make(map[string]*template.Template, len(c.Headers))
+	for _, name := range names {
+		if templates.Headers[name], err = parseWebhookTemplate("headers."+name, c.Headers[name], false); err != nil {
+			return nil, err
+		}
+	}
+
+	return &templates, nil
+}
+
+// parseWebhookTemplate parses a template and renders it with the sample record.
+// An empty text yields a nil template. URL templates must render to an absolute http or https URL.
+func parseWebhookTemplate(key, text string, isURL bool) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(key).Funcs(webhookFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid template: %w", key, err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, sampleWebhookRecord); err != nil {
+		return nil, fmt.Errorf("%s is not a valid template: %w", key, err)
+	}
+
+	if isURL {
+		u, err := url.Parse(rendered.String())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%s must render to an http or https URL", key)
+		}
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,371 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// adguardMaxErrorBody is the maximum number of bytes of an error response included in errors
+const adguardMaxErrorBody = 512
+
+// AdGuardProvider implements DNSProvider for AdGuard Home DNS rewrites.
+// A rewrite maps a domain to an answer; the record type is derived from the answer.
+type AdGuardProvider struct {
+	config  *config.AdGuardConfig
+	client  *http.Client
+	baseURL string
+	logger  *zap.Logger
+}
+
+// AdGuardRewrite represents a DNS rewrite in the AdGuard Home API
+type AdGuardRewrite struct {
+	Domain string `json:"domain"`
+	Answer string `json:"answer"`
+}
+
+// NewAdGuardProvider creates a new AdGuard Home DNS provider. It returns nil if the TLS
+// certificate files cannot be loaded, which Validate of the configuration reports first.
+func NewAdGuardProvider(cfg *config.AdGuardConfig, logger *zap.Logger) *AdGuardProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("adguard config is nil")
+		}
+		return nil
+	}
+
+	tlsConfig, err := cfg.TLS.ClientConfig()
+	if err != nil {
+		if logger != nil {
+			logger.Error("invalid adguard TLS configuration", zap.Error(err))
+		}
+		return nil
+	}
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: newTLSTransport(tlsConfig),
+	}
+
+	return &AdGuardProvider{
+		config:  cfg,
+		client:  client,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		logger:  logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (a *AdGuardProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(a.client, proxy)
+}
+
+// Name returns the provider name
+func (a *AdGuardProvider) Name() string {
+	return "adguard"
+}
+
+// UpdateRecord updates or creates a DNS rewrite.
+// AdGuard Home has no update operation, so rewrites with an outdated answer are deleted and the new
+// rewrite is added. If adding fails, the deleted rewrites are restored.
+func (a *AdGuardProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	a.logger.Info("updating DNS record",
+		zap.String("provider", "adguard"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if err := validateAdGuardRecordType(record.Type); err != nil {
+		return errors.NewDNSProviderError("adguard", record.Name, err)
+	}
+
+	domain := adguardDomain(record.Name)
+	if rewriteType(record.Value) != record.Type {
+		return errors.NewDNSProviderError("adguard", record.Name, fmt.Errorf("value %q is not valid for a %s record", record.Value, record.Type))
+	}
+
+	existing, err := a.findRewrites(ctx, domain, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("adguard", record.Name, err)
+	}
+
+	present := false
+	var stale []AdGuardRewrite
+	for _, rewrite := range existing {
+		if rewrite.Answer == record.Value {
+			present = true
+			continue
+		}
+		stale = append(stale, rewrite)
+	}
+
+	var deleted []AdGuardRewrite
+	for _, rewrite := range stale {
+		if err := a.doRequest(ctx, http.MethodPost, "/control/rewrite/delete", rewrite, nil); err != nil {
+			return errors.NewDNSProviderError("adguard", record.Name, a.restoreRewrites(ctx, record, deleted, fmt.Errorf("failed to delete rewrite %s -> %s: %w", rewrite.Domain, rewrite.Answer, err)))
+		}
+		deleted = append(deleted, rewrite)
+	}
+
+	if present {
+		a.logger.Info("DNS record updated successfully",
+			zap.String("provider", "adguard"),
+			zap.String("record", record.Name),
+			zap.Int("removed_rewrites", len(deleted)),
+		)
+		return nil
+	}
+
+	rewrite := AdGuardRewrite{Domain: domain, Answer: record.Value}
+	if err := a.doRequest(ctx, http.MethodPost, "/control/rewrite/add", rewrite, nil); err != nil {
+		return errors.NewDNSProviderError("adguard", record.Name, a.restoreRewrites(ctx, record, deleted, fmt.Errorf("failed to add rewrite: %w", err)))
+	}
+
+	if len(deleted) > 0 {
+		a.logger.Info("DNS record updated successfully",
+			zap.String("provider", "adguard"),
+			zap.String("record", record.Name),
+			zap.Int("removed_rewrites", len(deleted)),
+		)
+		return nil
+	}
+
+	a.logger.Info("DNS record created successfully",
+		zap.String("provider", "adguard"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// restoreRewrites adds back rewrites deleted by a failed update and returns the update error,
+// annotated if the record is left without a rewrite
+func (a *AdGuardProvider) restoreRewrites(ctx context.Context, record interfaces.DNSRecord, deleted []AdGuardRewrite, updateErr error) error {
+	if len(deleted) == 0 {
+		return updateErr
+	}
+
+	var restoreErr error
+	for _, rewrite := range deleted {
+		if err := a.doRequest(ctx, http.MethodPost, "/control/rewrite/add", rewrite, nil); err != nil {
+			restoreErr = multierr.Append(restoreErr, fmt.Errorf("failed to restore rewrite %s -> %s: %w", rewrite.Domain, rewrite.Answer, err))
+		}
+	}
+
+	if restoreErr != nil {
+		a.logger.Error("rewrite deleted but the update failed and it could not be restored, the record is missing",
+			zap.String("provider", "adguard"),
+			zap.String("record", record.Name),
+			zap.String("type", record.Type),
+			zap.String("value", record.Value),
+			zap.Error(restoreErr),
+		)
+		return fmt.Errorf("%w; record is missing: %w", updateErr, restoreErr)
+	}
+
+	a.logger.Warn("update failed, restored previous rewrites",
+		zap.String("provider", "adguard"),
+		zap.String("record", record.Name),
+		zap.Int("restored_rewrites", len(deleted)),
+	)
+	return updateErr
+}
+
+// GetRecord retrieves an existing DNS rewrite
+func (a *AdGuardProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	a.logger.Debug("getting DNS record",
+		zap.String("provider", "adguard"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if err := validateAdGuardRecordType(rtype); err != nil {
+		return nil, errors.NewDNSProviderError("adguard", name, err)
+	}
+
+	rewrites, err := a.findRewrites(ctx, adguardDomain(name), rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("adguard", name, err)
+	}
+
+	if len(rewrites) == 0 {
+		return nil, nil // Record not found
+	}
+
+	// AdGuard Home has no per-rewrite TTL
+	return &interfaces.DNSRecord{
+		Name:     name,
+		Type:     rtype,
+		Value:    rewrites[0].Answer,
+		Provider: "adguard",
+		Metadata: map[string]string{
+			"domain": rewrites[0].Domain,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes all DNS rewrites of the given type for a domain
+func (a *AdGuardProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	a.logger.Info("deleting DNS record",
+		zap.String("provider", "adguard"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if err := validateAdGuardRecordType(recordType); err != nil {
+		return errors.NewDNSProviderError("adguard", name, err)
+	}
+
+	rewrites, err := a.findRewrites(ctx, adguardDomain(name), recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("adguard", name, err)
+	}
+
+	if len(rewrites) == 0 {
+		a.logger.Warn("record not found for deletion",
+			zap.String("provider", "adguard"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	for _, rewrite := range rewrites {
+		if err := a.doRequest(ctx, http.MethodPost, "/control/rewrite/delete", rewrite, nil); err != nil {
+			return errors.NewDNSProviderError("adguard", name, fmt.Errorf("failed to delete rewrite %s -> %s: %w", rewrite.Domain, rewrite.Answer, err))
+		}
+	}
+
+	a.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "adguard"),
+		zap.String("record", name),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (a *AdGuardProvider) Validate(ctx context.Context) error {
+	a.logger.Debug("validating adguard provider configuration")
+
+	// Test API access and credentials by fetching the server status
+	if err := a.doRequest(ctx, http.MethodGet, "/control/status", nil, nil); err != nil {
+		return errors.NewDNSProviderError("adguard", "validation", fmt.Errorf("failed to get server status: %w", err))
+	}
+
+	a.logger.Info("adguard provider validation successful")
+	return nil
+}
+
+// findRewrites returns the rewrites for a domain whose answer is of the given record type
+func (a *AdGuardProvider) findRewrites(ctx context.Context, domain, recordType string) ([]AdGuardRewrite, error) {
+	var rewrites []AdGuardRewrite
+	if err := a.doRequest(ctx, http.MethodGet, "/control/rewrite/list", nil, &rewrites); err != nil {
+		return nil, fmt.Errorf("failed to list rewrites: %w", err)
+	}
+
+	var matches []AdGuardRewrite
+	for _, rewrite := range rewrites {
+		if strings.EqualFold(rewrite.Domain, domain) && rewriteType(rewrite.Answer) == recordType {
+			matches = append(matches, rewrite)
+		}
+	}
+
+	return matches, nil
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the response into out if non-nil
+func (a *AdGuardProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := a.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(a.config.Username, a.config.Password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			a.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		// AdGuard Home returns plain text error messages
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, adguardMaxErrorBody))
+		if text := strings.TrimSpace(string(message)); text != "" {
+			return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", text))
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// adguardDomain converts a record name to the domain format used by AdGuard Home rewrites
+func adguardDomain(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// validateAdGuardRecordType checks that a record type can be represented as a rewrite
+func validateAdGuardRecordType(recordType string) error {
+	switch recordType {
+	case "A", "AAAA", "CNAME":
+		return nil
+	case "":
+		return fmt.Errorf("empty record type")
+	default:
+		return fmt.Errorf("unsupported record type %s, AdGuard Home rewrites support A, AAAA and CNAME", recordType)
+	}
+}
+
+// rewriteType returns the record type of a rewrite answer: A or AAAA for IP addresses and CNAME otherwise
+func rewriteType(answer string) string {
+	ip := net.ParseIP(answer)
+	switch {
+	case ip == nil:
+		return "CNAME"
+	case ip.To4() != nil:
+		return "A"
+	default:
+		return "AAAA"
+	}
+}
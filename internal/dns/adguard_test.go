@@ -0,0 +1,215 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeAdGuard is a minimal in-memory AdGuard Home rewrites API
+type fakeAdGuard struct {
+	t        *testing.T
+	mu       sync.Mutex
+	rewrites []dns.AdGuardRewrite
+	requests []string
+	failAdd  map[string]bool // Answers for which add fails
+}
+
+func newFakeAdGuard(t *testing.T, rewrites ...dns.AdGuardRewrite) *fakeAdGuard {
+	return &fakeAdGuard{t: t, rewrites: rewrites, failAdd: make(map[string]bool)}
+}
+
+func (f *fakeAdGuard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	username, password, ok := r.BasicAuth()
+	if !ok || username != "admin" || password != "test-password" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/control/status":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"running": true})
+	case r.Method == http.MethodGet && r.URL.Path == "/control/rewrite/list":
+		_ = json.NewEncoder(w).Encode(f.rewrites)
+	case r.Method == http.MethodPost && r.URL.Path == "/control/rewrite/add":
+		var rewrite dns.AdGuardRewrite
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&rewrite))
+		if f.failAdd[rewrite.Answer] {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("couldn't write config file"))
+			return
+		}
+		f.rewrites = append(f.rewrites, rewrite)
+	case r.Method == http.MethodPost && r.URL.Path == "/control/rewrite/delete":
+		var rewrite dns.AdGuardRewrite
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&rewrite))
+		for i, existing := range f.rewrites {
+			if existing == rewrite {
+				f.rewrites = append(f.rewrites[:i], f.rewrites[i+1:]...)
+				break
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newAdGuardTestProvider(t *testing.T, fake *fakeAdGuard, password string) *dns.AdGuardProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewAdGuardProvider(&config.AdGuardConfig{
+		BaseURL:  server.URL + "/",
+		Username: "admin",
+		Password: password,
+	}, zap.NewNop())
+}
+
+func TestAdGuardProvider_Name(t *testing.T) {
+	provider := dns.NewAdGuardProvider(&config.AdGuardConfig{
+		BaseURL:  "http://192.168.1.2:3000",
+		Username: "admin",
+		Password: "test-password",
+	}, zap.NewNop())
+
+	var _ interfaces.DNSProvider = provider
+	assert.Equal(t, "adguard", provider.Name())
+	assert.Nil(t, dns.NewAdGuardProvider(nil, zap.NewNop()))
+}
+
+func TestAdGuardProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:  "home.example.com",
+		Type:  "A",
+		Value: "203.0.113.10",
+		TTL:   300,
+	}
+
+	t.Run("replaces rewrite with outdated answer", func(t *testing.T) {
+		fake := newFakeAdGuard(t,
+			dns.AdGuardRewrite{Domain: "home.example.com", Answer: "192.0.2.1"},
+			dns.AdGuardRewrite{Domain: "home.example.com", Answer: "2001:db8::1"},
+			dns.AdGuardRewrite{Domain: "other.example.com", Answer: "192.0.2.1"},
+		)
+		provider := newAdGuardTestProvider(t, fake, "test-password")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+
+		// The AAAA rewrite and other domains are untouched
+		assert.ElementsMatch(t, []dns.AdGuardRewrite{
+			{Domain: "home.example.com", Answer: "2001:db8::1"},
+			{Domain: "other.example.com", Answer: "192.0.2.1"},
+			{Domain: "home.example.com", Answer: "203.0.113.10"},
+		}, fake.rewrites)
+	})
+
+	t.Run("creates missing rewrite", func(t *testing.T) {
+		fake := newFakeAdGuard(t)
+		provider := newAdGuardTestProvider(t, fake, "test-password")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, []dns.AdGuardRewrite{{Domain: "home.example.com", Answer: "203.0.113.10"}}, fake.rewrites)
+		assert.NotContains(t, fake.requests, "POST /control/rewrite/delete")
+	})
+
+	t.Run("leaves current rewrite alone", func(t *testing.T) {
+		fake := newFakeAdGuard(t, dns.AdGuardRewrite{Domain: "home.example.com", Answer: "203.0.113.10"})
+		provider := newAdGuardTestProvider(t, fake, "test-password")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, []string{"GET /control/rewrite/list"}, fake.requests)
+	})
+
+	t.Run("restores previous rewrite when add fails", func(t *testing.T) {
+		fake := newFakeAdGuard(t, dns.AdGuardRewrite{Domain: "home.example.com", Answer: "192.0.2.1"})
+		fake.failAdd["203.0.113.10"] = true
+		provider := newAdGuardTestProvider(t, fake, "test-password")
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to add rewrite")
+		assert.Contains(t, err.Error(), "couldn't write config file")
+		assert.NotContains(t, err.Error(), "record is missing")
+		assert.Equal(t, []dns.AdGuardRewrite{{Domain: "home.example.com", Answer: "192.0.2.1"}}, fake.rewrites)
+	})
+
+	t.Run("reports missing record when add and restore fail", func(t *testing.T) {
+		fake := newFakeAdGuard(t, dns.AdGuardRewrite{Domain: "home.example.com", Answer: "192.0.2.1"})
+		fake.failAdd["203.0.113.10"] = true
+		fake.failAdd["192.0.2.1"] = true
+		provider := newAdGuardTestProvider(t, fake, "test-password")
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to add rewrite")
+		assert.Contains(t, err.Error(), "record is missing")
+		assert.Contains(t, err.Error(), "failed to restore rewrite home.example.com -> 192.0.2.1")
+		assert.Empty(t, fake.rewrites)
+	})
+
+	t.Run("rejects value of the wrong type", func(t *testing.T) {
+		fake := newFakeAdGuard(t)
+		provider := newAdGuardTestProvider(t, fake, "test-password")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{Name: "home.example.com", Type: "AAAA", Value: "203.0.113.10"})
+		assert.Error(t, err)
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestAdGuardProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := newFakeAdGuard(t,
+		dns.AdGuardRewrite{Domain: "home.example.com", Answer: "192.0.2.1"},
+		dns.AdGuardRewrite{Domain: "home.example.com", Answer: "2001:db8::1"},
+	)
+	provider := newAdGuardTestProvider(t, fake, "test-password")
+
+	record, err := provider.GetRecord(context.Background(), "Home.Example.com.", "AAAA")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "2001:db8::1", record.Value)
+	assert.Equal(t, "adguard", record.Provider)
+	assert.Zero(t, record.TTL)
+
+	missing, err := provider.GetRecord(context.Background(), "other.example.com", "A")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = provider.GetRecord(context.Background(), "home.example.com", "MX")
+	assert.Error(t, err)
+
+	require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+	assert.Equal(t, []dns.AdGuardRewrite{{Domain: "home.example.com", Answer: "2001:db8::1"}}, fake.rewrites)
+
+	// Deleting a missing record is not an error
+	assert.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+}
+
+func TestAdGuardProvider_Validate(t *testing.T) {
+	t.Run("valid credentials", func(t *testing.T) {
+		provider := newAdGuardTestProvider(t, newFakeAdGuard(t), "test-password")
+		assert.NoError(t, provider.Validate(context.Background()))
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		provider := newAdGuardTestProvider(t, newFakeAdGuard(t), "wrong-password")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 401")
+	})
+}
@@ -0,0 +1,425 @@
+package dns
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const (
+	aliDNSDefaultEndpoint = "https://alidns.aliyuncs.com"
+	aliDNSAPIVersion      = "2015-01-09"
+	aliDNSPageSize        = 500
+
+	// aliDNSRecordDuplicate is the error code of a record that already exists with the same value,
+	// returned when a record is updated to its current value
+	aliDNSRecordDuplicate = "DomainRecordDuplicate"
+)
+
+// AliDNSProvider implements DNSProvider for Alibaba Cloud DNS
+type AliDNSProvider struct {
+	config   *config.AliDNSConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+}
+
+// AliDNSRecord represents a DNS record in Alibaba Cloud DNS
+type AliDNSRecord struct {
+	RecordID   string `json:"RecordId"`
+	RR         string `json:"RR"`
+	Type       string `json:"Type"`
+	Value      string `json:"Value"`
+	TTL        int    `json:"TTL"`
+	DomainName string `json:"DomainName"`
+}
+
+// aliDNSDescribeResponse represents a DescribeDomainRecords response
+type aliDNSDescribeResponse struct {
+	TotalCount    int `json:"TotalCount"`
+	PageNumber    int `json:"PageNumber"`
+	PageSize      int `json:"PageSize"`
+	DomainRecords struct {
+		Record []AliDNSRecord `json:"Record"`
+	} `json:"DomainRecords"`
+}
+
+// aliDNSErrorResponse represents an Alibaba Cloud API error response
+type aliDNSErrorResponse struct {
+	RequestID string `json:"RequestId"`
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+}
+
+func (e *aliDNSErrorResponse) Error() string {
+	return fmt.Sprintf("alidns API error %s: %s (request %s)", e.Code, e.Message, e.RequestID)
+}
+
+// isAliDNSRecordDuplicate reports whether err is an API error for a record that already has the value
+func isAliDNSRecordDuplicate(err error) bool {
+	var apiErr *aliDNSErrorResponse
+	return stderrors.As(err, &apiErr) && apiErr.Code == aliDNSRecordDuplicate
+}
+
+// NewAliDNSProvider creates a new Alibaba Cloud DNS provider
+func NewAliDNSProvider(cfg *config.AliDNSConfig, logger *zap.Logger) *AliDNSProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("alidns config is nil")
+		}
+		return nil
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = aliDNSDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &AliDNSProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: endpoint,
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (a *AliDNSProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(a.client, proxy)
+}
+
+// Name returns the provider name
+func (a *AliDNSProvider) Name() string {
+	return "alidns"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (a *AliDNSProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	a.logger.Info("updating DNS record",
+		zap.String("provider", "alidns"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("alidns", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	rr, err := relativeName(record.Name, a.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("alidns", record.Name, err)
+	}
+
+	existingRecord, err := a.findRecord(ctx, rr, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("alidns", record.Name, err)
+	}
+
+	if existingRecord != nil {
+		params := url.Values{}
+		params.Set("RecordId", existingRecord.RecordID)
+		params.Set("RR", rr)
+		params.Set("Type", record.Type)
+		params.Set("Value", record.Value)
+		params.Set("TTL", strconv.Itoa(record.TTL))
+
+		if err := a.doRequest(ctx, "UpdateDomainRecord", params, nil); err != nil {
+			if !isAliDNSRecordDuplicate(err) {
+				return errors.NewDNSProviderError("alidns", record.Name, err)
+			}
+			// The record already has the value, e.g. from an earlier attempt that timed out
+			a.logger.Info("DNS record already up to date",
+				zap.String("provider", "alidns"),
+				zap.String("record", record.Name),
+				zap.String("record_id", existingRecord.RecordID),
+			)
+			return nil
+		}
+
+		a.logger.Info("DNS record updated successfully",
+			zap.String("provider", "alidns"),
+			zap.String("record", record.Name),
+			zap.String("record_id", existingRecord.RecordID),
+		)
+		return nil
+	}
+
+	params := url.Values{}
+	params.Set("DomainName", a.config.Domain)
+	params.Set("RR", rr)
+	params.Set("Type", record.Type)
+	params.Set("Value", record.Value)
+	params.Set("TTL", strconv.Itoa(record.TTL))
+
+	if err := a.doRequest(ctx, "AddDomainRecord", params, nil); err != nil {
+		if !isAliDNSRecordDuplicate(err) {
+			return errors.NewDNSProviderError("alidns", record.Name, err)
+		}
+		a.logger.Info("DNS record already up to date",
+			zap.String("provider", "alidns"),
+			zap.String("record", record.Name),
+		)
+		return nil
+	}
+
+	a.logger.Info("DNS record created successfully",
+		zap.String("provider", "alidns"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (a *AliDNSProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	a.logger.Debug("getting DNS record",
+		zap.String("provider", "alidns"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("alidns", name, fmt.Errorf("empty record type"))
+	}
+
+	rr, err := relativeName(name, a.config.Domain)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("alidns", name, err)
+	}
+
+	record, err := a.findRecord(ctx, rr, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("alidns", name, err)
+	}
+
+	if record == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(record.RR, a.config.Domain),
+		Type:     record.Type,
+		Value:    record.Value,
+		TTL:      record.TTL,
+		Provider: "alidns",
+		Metadata: map[string]string{
+			"alidns_id": record.RecordID,
+			"rr":        record.RR,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (a *AliDNSProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	a.logger.Info("deleting DNS record",
+		zap.String("provider", "alidns"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("alidns", name, fmt.Errorf("empty record type"))
+	}
+
+	rr, err := relativeName(name, a.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("alidns", name, err)
+	}
+
+	record, err := a.findRecord(ctx, rr, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("alidns", name, err)
+	}
+
+	if record == nil {
+		a.logger.Warn("record not found for deletion",
+			zap.String("provider", "alidns"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	params := url.Values{}
+	params.Set("RecordId", record.RecordID)
+
+	if err := a.doRequest(ctx, "DeleteDomainRecord", params, nil); err != nil {
+		return errors.NewDNSProviderError("alidns", name, err)
+	}
+
+	a.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "alidns"),
+		zap.String("record", name),
+		zap.String("record_id", record.RecordID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (a *AliDNSProvider) Validate(ctx context.Context) error {
+	a.logger.Debug("validating Alibaba Cloud DNS provider configuration")
+
+	// Test API access by fetching a single page of records
+	params := url.Values{}
+	params.Set("DomainName", a.config.Domain)
+	params.Set("PageNumber", "1")
+	params.Set("PageSize", "1")
+
+	var resp aliDNSDescribeResponse
+	if err := a.doRequest(ctx, "DescribeDomainRecords", params, &resp); err != nil {
+		return errors.NewDNSProviderError("alidns", "validation", err)
+	}
+
+	a.logger.Info("Alibaba Cloud DNS provider validation successful")
+	return nil
+}
+
+// findRecord finds a record by RR and type, iterating through all result pages
+func (a *AliDNSProvider) findRecord(ctx context.Context, rr, recordType string) (*AliDNSRecord, error) {
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("DomainName", a.config.Domain)
+		params.Set("RRKeyWord", rr)
+		params.Set("PageNumber", strconv.Itoa(page))
+		params.Set("PageSize", strconv.Itoa(aliDNSPageSize))
+		if recordType != "" {
+			params.Set("TypeKeyWord", recordType)
+		}
+
+		var resp aliDNSDescribeResponse
+		if err := a.doRequest(ctx, "DescribeDomainRecords", params, &resp); err != nil {
+			return nil, err
+		}
+
+		// RRKeyWord is a fuzzy match, so filter for an exact match
+		for _, record := range resp.DomainRecords.Record {
+			if record.RR == rr && (recordType == "" || record.Type == recordType) {
+				rec := record
+				return &rec, nil
+			}
+		}
+
+		if len(resp.DomainRecords.Record) == 0 || page*aliDNSPageSize >= resp.TotalCount {
+			break
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// doRequest performs a signed RPC-style API call and decodes the response into out if non-nil
+func (a *AliDNSProvider) doRequest(ctx context.Context, action string, params url.Values, out interface{}) error {
+	nonce, err := aliDNSNonce()
+	if err != nil {
+		return fmt.Errorf("failed to generate signature nonce: %w", err)
+	}
+
+	params.Set("Action", action)
+	params.Set("Format", "JSON")
+	params.Set("Version", aliDNSAPIVersion)
+	params.Set("AccessKeyId", a.config.AccessKeyID)
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureVersion", "1.0")
+	params.Set("SignatureNonce", nonce)
+	params.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	params.Set("Signature", signAliDNSRequest(http.MethodGet, params, a.config.AccessKeySecret))
+
+	apiURL := a.endpoint + "/?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			a.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr aliDNSErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Code != "" {
+			return errors.NewHTTPError(resp.StatusCode, a.endpoint, &apiErr)
+		}
+		return errors.NewHTTPError(resp.StatusCode, a.endpoint, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// signAliDNSRequest computes the HMAC-SHA1 signature for an RPC-style request
+func signAliDNSRequest(method string, params url.Values, secret string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if key == "Signature" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, aliDNSPercentEncode(key)+"="+aliDNSPercentEncode(params.Get(key)))
+	}
+	canonicalized := strings.Join(pairs, "&")
+
+	stringToSign := method + "&" + aliDNSPercentEncode("/") + "&" + aliDNSPercentEncode(canonicalized)
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliDNSPercentEncode encodes a value per RFC 3986 as required by the signature algorithm
+func aliDNSPercentEncode(value string) string {
+	encoded := url.QueryEscape(value)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	encoded = strings.ReplaceAll(encoded, "%7E", "~")
+	return encoded
+}
+
+// aliDNSNonce returns a random nonce for request signing
+func aliDNSNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
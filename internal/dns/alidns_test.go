@@ -0,0 +1,293 @@
+package dns_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeAliDNS is a minimal in-memory Alibaba Cloud DNS API
+type fakeAliDNS struct {
+	t       *testing.T
+	secret  string
+	mu      sync.Mutex
+	records []map[string]interface{}
+	actions []url.Values
+}
+
+func (f *fakeAliDNS) expectedSignature(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if key != "Signature" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	encode := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(url.QueryEscape(s), "+", "%20"), "*", "%2A"), "%7E", "~")
+	}
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, encode(key)+"="+encode(query.Get(key)))
+	}
+
+	mac := hmac.New(sha1.New, []byte(f.secret+"&"))
+	mac.Write([]byte("GET&%2F&" + encode(strings.Join(pairs, "&"))))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (f *fakeAliDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("Signature") != f.expectedSignature(query) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"RequestId":"req-1","Code":"SignatureDoesNotMatch","Message":"bad signature"}`))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, query)
+
+	var resp interface{}
+	switch query.Get("Action") {
+	case "DescribeDomainRecords":
+		page, _ := strconv.Atoi(query.Get("PageNumber"))
+		size, _ := strconv.Atoi(query.Get("PageSize"))
+		start := (page - 1) * size
+		end := start + size
+		if start > len(f.records) {
+			start = len(f.records)
+		}
+		if end > len(f.records) {
+			end = len(f.records)
+		}
+		resp = map[string]interface{}{
+			"TotalCount":    len(f.records),
+			"PageNumber":    page,
+			"PageSize":      size,
+			"DomainRecords": map[string]interface{}{"Record": f.records[start:end]},
+		}
+	case "UpdateDomainRecord":
+		for _, record := range f.records {
+			if record["RecordId"] != query.Get("RecordId") {
+				continue
+			}
+			if record["Value"] == query.Get("Value") {
+				// The API rejects updates that do not change the record
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"RequestId":"req-2","Code":"DomainRecordDuplicate","Message":"The DNS record already exists."}`))
+				return
+			}
+			record["Value"] = query.Get("Value")
+		}
+		resp = map[string]interface{}{"RecordId": query.Get("RecordId")}
+	case "AddDomainRecord":
+		id := fmt.Sprintf("new-%d", len(f.records))
+		f.records = append(f.records, map[string]interface{}{
+			"RecordId": id,
+			"RR":       query.Get("RR"),
+			"Type":     query.Get("Type"),
+			"Value":    query.Get("Value"),
+		})
+		resp = map[string]interface{}{"RecordId": id}
+	case "DeleteDomainRecord":
+		resp = map[string]interface{}{"RecordId": query.Get("RecordId")}
+	default:
+		f.t.Errorf("unexpected action %s", query.Get("Action"))
+	}
+
+	require.NoError(f.t, json.NewEncoder(w).Encode(resp))
+}
+
+func (f *fakeAliDNS) actionNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.actions))
+	for _, action := range f.actions {
+		names = append(names, action.Get("Action"))
+	}
+	return names
+}
+
+func newAliDNSTestProvider(t *testing.T, fake *fakeAliDNS) *dns.AliDNSProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewAliDNSProvider(&config.AliDNSConfig{
+		AccessKeyID:     "test-key",
+		AccessKeySecret: fake.secret,
+		Domain:          "example.com",
+		Endpoint:        server.URL,
+	}, zap.NewNop())
+}
+
+func TestAliDNSProvider_Name(t *testing.T) {
+	provider := dns.NewAliDNSProvider(&config.AliDNSConfig{
+		AccessKeyID:     "test-key",
+		AccessKeySecret: "test-secret",
+		Domain:          "example.com",
+	}, zap.NewNop())
+
+	var _ interfaces.DNSProvider = provider
+	assert.Equal(t, "alidns", provider.Name())
+}
+
+func TestAliDNSProvider_NilConfig(t *testing.T) {
+	assert.Nil(t, dns.NewAliDNSProvider(nil, zap.NewNop()))
+}
+
+func TestAliDNSProvider_UpdateRecord(t *testing.T) {
+	t.Run("updates existing record beyond the first page", func(t *testing.T) {
+		fake := &fakeAliDNS{t: t, secret: "test-secret"}
+		for i := 0; i < 600; i++ {
+			fake.records = append(fake.records, map[string]interface{}{
+				"RecordId": fmt.Sprintf("id-%d", i),
+				"RR":       fmt.Sprintf("host%d", i),
+				"Type":     "A",
+				"Value":    "192.0.2.1",
+			})
+		}
+		fake.records = append(fake.records, map[string]interface{}{
+			"RecordId": "target", "RR": "home", "Type": "A", "Value": "192.0.2.1",
+		})
+
+		provider := newAliDNSTestProvider(t, fake)
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   600,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"DescribeDomainRecords", "DescribeDomainRecords", "UpdateDomainRecord"}, fake.actionNames())
+		assert.Equal(t, "203.0.113.10", fake.records[600]["Value"])
+	})
+
+	t.Run("accepts record that already has the value", func(t *testing.T) {
+		fake := &fakeAliDNS{t: t, secret: "test-secret"}
+		fake.records = append(fake.records, map[string]interface{}{
+			"RecordId": "target", "RR": "home", "Type": "A", "Value": "203.0.113.10",
+		})
+		provider := newAliDNSTestProvider(t, fake)
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   600,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"DescribeDomainRecords", "UpdateDomainRecord"}, fake.actionNames())
+	})
+
+	t.Run("creates apex record when missing", func(t *testing.T) {
+		fake := &fakeAliDNS{t: t, secret: "test-secret"}
+		provider := newAliDNSTestProvider(t, fake)
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   600,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"DescribeDomainRecords", "AddDomainRecord"}, fake.actionNames())
+		require.Len(t, fake.records, 1)
+		assert.Equal(t, "@", fake.records[0]["RR"])
+	})
+
+	t.Run("rejects record outside domain", func(t *testing.T) {
+		fake := &fakeAliDNS{t: t, secret: "test-secret"}
+		provider := newAliDNSTestProvider(t, fake)
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.org",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   600,
+		})
+		assert.Error(t, err)
+		assert.Empty(t, fake.actionNames())
+	})
+
+	t.Run("surfaces API errors", func(t *testing.T) {
+		fake := &fakeAliDNS{t: t, secret: "test-secret"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		provider := dns.NewAliDNSProvider(&config.AliDNSConfig{
+			AccessKeyID:     "test-key",
+			AccessKeySecret: "wrong-secret",
+			Domain:          "example.com",
+			Endpoint:        server.URL,
+		}, zap.NewNop())
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   600,
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "SignatureDoesNotMatch")
+	})
+}
+
+func TestAliDNSProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := &fakeAliDNS{t: t, secret: "test-secret"}
+	fake.records = []map[string]interface{}{
+		{"RecordId": "fuzzy", "RR": "home2", "Type": "A", "Value": "192.0.2.2", "TTL": 600},
+		{"RecordId": "exact", "RR": "home", "Type": "A", "Value": "192.0.2.1", "TTL": 600},
+	}
+	provider := newAliDNSTestProvider(t, fake)
+
+	record, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "home.example.com", record.Name)
+	assert.Equal(t, "192.0.2.1", record.Value)
+	assert.Equal(t, "exact", record.Metadata["alidns_id"])
+
+	missing, err := provider.GetRecord(context.Background(), "missing.example.com", "A")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+	actions := fake.actionNames()
+	assert.Equal(t, "DeleteDomainRecord", actions[len(actions)-1])
+
+	_, err = provider.GetRecord(context.Background(), "home.example.com", "")
+	assert.Error(t, err)
+}
+
+func TestAliDNSProvider_Validate(t *testing.T) {
+	fake := &fakeAliDNS{t: t, secret: "test-secret"}
+	provider := newAliDNSTestProvider(t, fake)
+
+	assert.NoError(t, provider.Validate(context.Background()))
+}
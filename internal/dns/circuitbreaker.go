@@ -0,0 +1,294 @@
+package dns
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+)
+
+// CircuitState is the state of a CircuitBreaker. The values are those of the ipfailover_circuit_state gauge.
+type CircuitState int
+
+// Circuit breaker states
+const (
+	CircuitClosed   CircuitState = 0 // Calls pass through
+	CircuitHalfOpen CircuitState = 1 // One probe call passes through after the cooldown
+	CircuitOpen     CircuitState = 2 // Calls fail immediately
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitOpenError is returned for calls rejected by an open circuit breaker.
+// It is not retryable, see errors.IsRetryableError.
+type CircuitOpenError struct {
+	Provider string
+	Until    time.Time // When the circuit becomes half-open
+}
+
+// Error implements error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for provider %s is open until %s", e.Provider, e.Until.Format(time.RFC3339))
+}
+
+// CircuitBreaker stops calling a DNS provider that failed Threshold consecutive times.
+// The circuit stays open for Cooldown, then a single probe call is let through: if it
+// succeeds the circuit closes, otherwise it opens again for another Cooldown.
+// A CircuitBreaker is safe for concurrent use and can be shared by several providers.
+type CircuitBreaker struct {
+	name          string
+	threshold     int
+	cooldown      time.Duration
+	now           func() time.Time
+	onStateChange func(state CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a closed circuit breaker for the named provider. A threshold of 0
+// never opens the circuit. onStateChange, if non-nil, is called with every new state.
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration, onStateChange func(state CircuitState)) *CircuitBreaker {
+	return NewCircuitBreakerWithClock(name, threshold, cooldown, time.Now, onStateChange)
+}
+
+// NewCircuitBreakerWithClock creates a closed circuit breaker using now as the time source
+func NewCircuitBreakerWithClock(name string, threshold int, cooldown time.Duration, now func() time.Time, onStateChange func(state CircuitState)) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:          name,
+		threshold:     threshold,
+		cooldown:      cooldown,
+		now:           now,
+		onStateChange: onStateChange,
+	}
+}
+
+// State returns the current state. An open circuit whose cooldown elapsed is reported
+// as open until the next call moves it to half-open.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Call calls fn unless the circuit is open, and records its outcome
+func (cb *CircuitBreaker) Call(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	cb.record(err)
+	return err
+}
+
+// allow reports whether a call may proceed, moving an open circuit to half-open after the cooldown
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		until := cb.openedAt.Add(cb.cooldown)
+		if cb.now().Before(until) {
+			return &CircuitOpenError{Provider: cb.name, Until: until}
+		}
+		cb.setState(CircuitHalfOpen)
+		cb.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if cb.probing {
+			// Only one probe call at a time
+			return &CircuitOpenError{Provider: cb.name, Until: cb.now()}
+		}
+		cb.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record updates the state with the outcome of a call
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !countsAsFailure(err) {
+		cb.failures = 0
+		cb.probing = false
+		if cb.state != CircuitClosed {
+			cb.setState(CircuitClosed)
+		}
+		return
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || (cb.threshold > 0 && cb.failures >= cb.threshold) {
+		cb.probing = false
+		cb.openedAt = cb.now()
+		cb.setState(CircuitOpen)
+	}
+}
+
+// setState changes the state and reports it. cb.mu must be held.
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	cb.state = state
+	if cb.onStateChange != nil {
+		cb.onStateChange(state)
+	}
+}
+
+// countsAsFailure reports whether err indicates a failing provider. Conflicts are a property
+// of the record and cancellations of the caller, so neither counts.
+func countsAsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var conflictErr *errors.ConflictError
+	if stderrors.As(err, &conflictErr) {
+		return false
+	}
+	return !stderrors.Is(err, context.Canceled)
+}
+
+// circuitBreakerProvider passes the calls of a DNS provider through a circuit breaker
+type circuitBreakerProvider struct {
+	provider interfaces.DNSProvider
+	breaker  *CircuitBreaker
+}
+
+// conditionalCircuitBreakerProvider is a circuitBreakerProvider for a provider that
+// implements interfaces.ConditionalUpdater, which it keeps implementing
+type conditionalCircuitBreakerProvider struct {
+	circuitBreakerProvider
+	updater interfaces.ConditionalUpdater
+}
+
+// circuitBreakerBatcher passes the batch updates of a provider that implements
+// interfaces.BatchUpdater through a circuit breaker
+type circuitBreakerBatcher struct {
+	batcher interfaces.BatchUpdater
+	breaker *CircuitBreaker
+}
+
+// batchCircuitBreakerProvider is a circuitBreakerProvider for a provider that
+// implements interfaces.BatchUpdater, which it keeps implementing
+type batchCircuitBreakerProvider struct {
+	circuitBreakerProvider
+	circuitBreakerBatcher
+}
+
+// conditionalBatchCircuitBreakerProvider is a conditionalCircuitBreakerProvider for a provider
+// that also implements interfaces.BatchUpdater
+type conditionalBatchCircuitBreakerProvider struct {
+	conditionalCircuitBreakerProvider
+	circuitBreakerBatcher
+}
+
+// WithCircuitBreaker returns provider with all calls passed through breaker. The result
+// implements interfaces.ConditionalUpdater and interfaces.BatchUpdater if provider does.
+func WithCircuitBreaker(provider interfaces.DNSProvider, breaker *CircuitBreaker) interfaces.DNSProvider {
+	wrapped := circuitBreakerProvider{provider: provider, breaker: breaker}
+	updater, conditional := provider.(interfaces.ConditionalUpdater)
+	batcher, batch := provider.(interfaces.BatchUpdater)
+
+	switch {
+	case conditional && batch:
+		return &conditionalBatchCircuitBreakerProvider{
+			conditionalCircuitBreakerProvider: conditionalCircuitBreakerProvider{circuitBreakerProvider: wrapped, updater: updater},
+			circuitBreakerBatcher:             circuitBreakerBatcher{batcher: batcher, breaker: breaker},
+		}
+	case conditional:
+		return &conditionalCircuitBreakerProvider{circuitBreakerProvider: wrapped, updater: updater}
+	case batch:
+		return &batchCircuitBreakerProvider{
+			circuitBreakerProvider: wrapped,
+			circuitBreakerBatcher:  circuitBreakerBatcher{batcher: batcher, breaker: breaker},
+		}
+	}
+	return &wrapped
+}
+
+// Name returns the provider name
+func (p *circuitBreakerProvider) Name() string {
+	return p.provider.Name()
+}
+
+// UpdateRecord updates or creates a DNS record
+func (p *circuitBreakerProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	return p.breaker.Call(func() error {
+		return p.provider.UpdateRecord(ctx, record)
+	})
+}
+
+// GetRecord retrieves an existing DNS record
+func (p *circuitBreakerProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	var record *interfaces.DNSRecord
+	err := p.breaker.Call(func() error {
+		var err error
+		record, err = p.provider.GetRecord(ctx, name, rtype)
+		return err
+	})
+	return record, err
+}
+
+// DeleteRecord deletes a DNS record
+func (p *circuitBreakerProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	return p.breaker.Call(func() error {
+		return p.provider.DeleteRecord(ctx, name, recordType)
+	})
+}
+
+// Validate checks if the provider configuration is valid
+func (p *circuitBreakerProvider) Validate(ctx context.Context) error {
+	return p.breaker.Call(func() error {
+		return p.provider.Validate(ctx)
+	})
+}
+
+// Close closes the provider if it holds resources, such as a plugin process
+func (p *circuitBreakerProvider) Close() error {
+	if closer, ok := p.provider.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// UpdateRecordIf updates or creates a DNS record only if it still has the expected contents
+func (p *conditionalCircuitBreakerProvider) UpdateRecordIf(ctx context.Context, record interfaces.DNSRecord, expected *interfaces.DNSRecord) error {
+	return p.breaker.Call(func() error {
+		return p.updater.UpdateRecordIf(ctx, record, expected)
+	})
+}
+
+// BatchKey identifies the zone and credentials of the provider
+func (b *circuitBreakerBatcher) BatchKey() string {
+	return b.batcher.BatchKey()
+}
+
+// UpdateRecords updates or creates all records in a single change
+func (b *circuitBreakerBatcher) UpdateRecords(ctx context.Context, records []interfaces.DNSRecord) error {
+	return b.breaker.Call(func() error {
+		return b.batcher.UpdateRecords(ctx, records)
+	})
+}
@@ -0,0 +1,269 @@
+package dns_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced time source
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestCircuitBreaker(threshold int) (*dns.CircuitBreaker, *fakeClock, *[]dns.CircuitState) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var states []dns.CircuitState
+	breaker := dns.NewCircuitBreakerWithClock("cloudflare", threshold, time.Minute, clock.Now, func(state dns.CircuitState) {
+		states = append(states, state)
+	})
+	return breaker, clock, &states
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	failure := errors.NewHTTPError(http.StatusServiceUnavailable, "https://api.example.com", fmt.Errorf("unavailable"))
+	succeed := func() error { return nil }
+	fail := func() error { return failure }
+
+	t.Run("opens after threshold consecutive failures", func(t *testing.T) {
+		breaker, _, states := newTestCircuitBreaker(3)
+
+		assert.Equal(t, failure, breaker.Call(fail))
+		assert.Equal(t, failure, breaker.Call(fail))
+		require.NoError(t, breaker.Call(succeed), "success resets the failure count")
+		assert.Equal(t, failure, breaker.Call(fail))
+		assert.Equal(t, failure, breaker.Call(fail))
+		assert.Equal(t, dns.CircuitClosed, breaker.State())
+
+		assert.Equal(t, failure, breaker.Call(fail))
+		assert.Equal(t, dns.CircuitOpen, breaker.State())
+		assert.Equal(t, []dns.CircuitState{dns.CircuitOpen}, *states)
+
+		called := false
+		err := breaker.Call(func() error {
+			called = true
+			return nil
+		})
+		var openErr *dns.CircuitOpenError
+		require.True(t, stderrors.As(err, &openErr))
+		assert.Equal(t, "cloudflare", openErr.Provider)
+		assert.False(t, called, "open circuit rejects calls")
+		assert.False(t, errors.IsRetryableError(err))
+	})
+
+	t.Run("half-open probe closes the circuit on success", func(t *testing.T) {
+		breaker, clock, states := newTestCircuitBreaker(1)
+		assert.Equal(t, failure, breaker.Call(fail))
+
+		clock.now = clock.now.Add(59 * time.Second)
+		assert.Error(t, breaker.Call(succeed), "still cooling down")
+
+		clock.now = clock.now.Add(time.Second)
+		err := breaker.Call(func() error {
+			assert.Equal(t, dns.CircuitHalfOpen, breaker.State())
+			var openErr *dns.CircuitOpenError
+			assert.True(t, stderrors.As(breaker.Call(succeed), &openErr), "only one probe at a time")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, dns.CircuitClosed, breaker.State())
+		assert.Equal(t, []dns.CircuitState{dns.CircuitOpen, dns.CircuitHalfOpen, dns.CircuitClosed}, *states)
+	})
+
+	t.Run("half-open probe reopens the circuit on failure", func(t *testing.T) {
+		breaker, clock, states := newTestCircuitBreaker(5)
+		for i := 0; i < 5; i++ {
+			_ = breaker.Call(fail)
+		}
+
+		clock.now = clock.now.Add(time.Minute)
+		assert.Equal(t, failure, breaker.Call(fail))
+		assert.Equal(t, dns.CircuitOpen, breaker.State())
+		assert.Equal(t, []dns.CircuitState{dns.CircuitOpen, dns.CircuitHalfOpen, dns.CircuitOpen}, *states)
+
+		clock.now = clock.now.Add(30 * time.Second)
+		assert.Error(t, breaker.Call(succeed), "cooldown restarts")
+	})
+
+	t.Run("conflicts and cancellations are not failures", func(t *testing.T) {
+		breaker, _, _ := newTestCircuitBreaker(1)
+
+		conflict := errors.NewConflictError("cloudflare", "example.com", "192.0.2.1", "203.0.113.10")
+		assert.Equal(t, conflict, breaker.Call(func() error { return conflict }))
+		assert.ErrorIs(t, breaker.Call(func() error { return fmt.Errorf("request failed: %w", context.Canceled) }), context.Canceled)
+		assert.Equal(t, dns.CircuitClosed, breaker.State())
+	})
+
+	t.Run("zero threshold never opens", func(t *testing.T) {
+		breaker, _, states := newTestCircuitBreaker(0)
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, failure, breaker.Call(fail))
+		}
+		assert.Equal(t, dns.CircuitClosed, breaker.State())
+		assert.Empty(t, *states)
+	})
+}
+
+// recordingProvider is a DNS provider that records its calls and fails them with err
+type recordingProvider struct {
+	err   error
+	calls []string
+}
+
+func (p *recordingProvider) Name() string {
+	return "cloudflare"
+}
+
+func (p *recordingProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	p.calls = append(p.calls, "UpdateRecord "+record.Name)
+	return p.err
+}
+
+func (p *recordingProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	p.calls = append(p.calls, "GetRecord "+name)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &interfaces.DNSRecord{Name: name, Type: rtype, Value: "203.0.113.10"}, nil
+}
+
+func (p *recordingProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	p.calls = append(p.calls, "DeleteRecord "+name)
+	return p.err
+}
+
+func (p *recordingProvider) Validate(ctx context.Context) error {
+	p.calls = append(p.calls, "Validate")
+	return p.err
+}
+
+// conditionalRecordingProvider is a recordingProvider that implements ConditionalUpdater
+type conditionalRecordingProvider struct {
+	recordingProvider
+}
+
+func (p *conditionalRecordingProvider) UpdateRecordIf(ctx context.Context, record interfaces.DNSRecord, expected *interfaces.DNSRecord) error {
+	p.calls = append(p.calls, "UpdateRecordIf "+record.Name)
+	return p.err
+}
+
+// batchRecordingProvider is a recordingProvider that implements BatchUpdater
+type batchRecordingProvider struct {
+	recordingProvider
+}
+
+func (p *batchRecordingProvider) BatchKey() string {
+	return "zone"
+}
+
+func (p *batchRecordingProvider) UpdateRecords(ctx context.Context, records []interfaces.DNSRecord) error {
+	p.calls = append(p.calls, fmt.Sprintf("UpdateRecords %d", len(records)))
+	return p.err
+}
+
+// conditionalBatchRecordingProvider implements both ConditionalUpdater and BatchUpdater
+type conditionalBatchRecordingProvider struct {
+	conditionalRecordingProvider
+}
+
+func (p *conditionalBatchRecordingProvider) BatchKey() string {
+	return "zone"
+}
+
+func (p *conditionalBatchRecordingProvider) UpdateRecords(ctx context.Context, records []interfaces.DNSRecord) error {
+	p.calls = append(p.calls, fmt.Sprintf("UpdateRecords %d", len(records)))
+	return p.err
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	record := interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300}
+
+	t.Run("passes calls through", func(t *testing.T) {
+		inner := &recordingProvider{}
+		breaker, _, _ := newTestCircuitBreaker(1)
+		provider := dns.WithCircuitBreaker(inner, breaker)
+
+		assert.Equal(t, "cloudflare", provider.Name())
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		found, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.10", found.Value)
+		require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+		require.NoError(t, provider.Validate(context.Background()))
+		assert.Equal(t, []string{"UpdateRecord home.example.com", "GetRecord home.example.com", "DeleteRecord home.example.com", "Validate"}, inner.calls)
+
+		_, ok := provider.(interfaces.ConditionalUpdater)
+		assert.False(t, ok, "does not add conditional updates")
+		_, ok = provider.(interfaces.BatchUpdater)
+		assert.False(t, ok, "does not add batch updates")
+	})
+
+	t.Run("shares the breaker", func(t *testing.T) {
+		failing := &recordingProvider{err: errors.NewDNSProviderError("cloudflare", record.Name, fmt.Errorf("unavailable"))}
+		healthy := &recordingProvider{}
+
+		breaker, _, _ := newTestCircuitBreaker(1)
+		first := dns.WithCircuitBreaker(failing, breaker)
+		second := dns.WithCircuitBreaker(healthy, breaker)
+
+		assert.Error(t, first.UpdateRecord(context.Background(), record))
+		var openErr *dns.CircuitOpenError
+		assert.True(t, stderrors.As(second.UpdateRecord(context.Background(), record), &openErr))
+		assert.Empty(t, healthy.calls)
+	})
+
+	t.Run("keeps conditional updates", func(t *testing.T) {
+		inner := &conditionalRecordingProvider{}
+		breaker, _, _ := newTestCircuitBreaker(1)
+		provider := dns.WithCircuitBreaker(inner, breaker)
+
+		updater, ok := provider.(interfaces.ConditionalUpdater)
+		require.True(t, ok)
+		require.NoError(t, updater.UpdateRecordIf(context.Background(), record, nil))
+		assert.Equal(t, []string{"UpdateRecordIf home.example.com"}, inner.calls)
+	})
+	t.Run("keeps batch updates", func(t *testing.T) {
+		inner := &batchRecordingProvider{}
+		breaker, _, _ := newTestCircuitBreaker(1)
+		provider := dns.WithCircuitBreaker(inner, breaker)
+
+		_, ok := provider.(interfaces.ConditionalUpdater)
+		assert.False(t, ok)
+		batcher, ok := provider.(interfaces.BatchUpdater)
+		require.True(t, ok)
+		assert.Equal(t, "zone", batcher.BatchKey())
+		require.NoError(t, batcher.UpdateRecords(context.Background(), []interfaces.DNSRecord{record, record}))
+		assert.Equal(t, []string{"UpdateRecords 2"}, inner.calls)
+	})
+
+	t.Run("keeps conditional and batch updates", func(t *testing.T) {
+		inner := &conditionalBatchRecordingProvider{}
+		inner.err = errors.NewDNSProviderError("route53", record.Name, fmt.Errorf("unavailable"))
+		breaker, _, _ := newTestCircuitBreaker(1)
+		provider := dns.WithCircuitBreaker(inner, breaker)
+
+		_, ok := provider.(interfaces.ConditionalUpdater)
+		assert.True(t, ok)
+		batcher, ok := provider.(interfaces.BatchUpdater)
+		require.True(t, ok)
+
+		// Batch updates count towards the breaker
+		assert.Error(t, batcher.UpdateRecords(context.Background(), []interfaces.DNSRecord{record}))
+		var openErr *dns.CircuitOpenError
+		assert.True(t, stderrors.As(batcher.UpdateRecords(context.Background(), []interfaces.DNSRecord{record}), &openErr))
+		assert.Equal(t, []string{"UpdateRecords 1"}, inner.calls)
+	})
+}
@@ -2,23 +2,45 @@ package dns
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/cloudflare/cloudflare-go/v2"
 	"github.com/cloudflare/cloudflare-go/v2/dns"
 	"github.com/cloudflare/cloudflare-go/v2/option"
+	"github.com/cloudflare/cloudflare-go/v2/zones"
 	"github.com/devhat/ipfailover/internal/config"
 	"github.com/devhat/ipfailover/pkg/errors"
 	"github.com/devhat/ipfailover/pkg/interfaces"
 	"go.uber.org/zap"
 )
 
-// CloudflareProvider implements DNSProvider for Cloudflare
+// Cloudflare API error codes returned when creating a record that already exists
+const (
+	cloudflareRecordExists          = 81057
+	cloudflareIdenticalRecordExists = 81058
+)
+
+// CloudflareProvider implements DNSProvider for Cloudflare.
+// Without a configured zone_id, the zone is looked up by zone_name, or derived from the record name,
+// once and cached.
 type CloudflareProvider struct {
-	config *config.CloudflareConfig
-	client *cloudflare.Client
-	logger *zap.Logger
+	config     *config.CloudflareConfig
+	client     *cloudflare.Client
+	logger     *zap.Logger
+	recordName string
+
+	zoneMu   sync.RWMutex
+	zoneID   string
+	zoneName string
 }
 
 // NewCloudflareProvider creates a new Cloudflare DNS provider
@@ -30,9 +52,7 @@ func NewCloudflareProvider(cfg *config.CloudflareConfig, logger *zap.Logger) *Cl
 		return nil
 	}
 
-	client := cloudflare.NewClient(
-		option.WithAPIToken(cfg.APIToken),
-	)
+	client := cloudflare.NewClient(cloudflareAuth(cfg)...)
 
 	return &CloudflareProvider{
 		config: cfg,
@@ -51,9 +71,7 @@ func NewCloudflareProviderWithClient(cfg *config.CloudflareConfig, client *cloud
 	}
 
 	if client == nil {
-		client = cloudflare.NewClient(
-			option.WithAPIToken(cfg.APIToken),
-		)
+		client = cloudflare.NewClient(cloudflareAuth(cfg)...)
 	}
 
 	return &CloudflareProvider{
@@ -63,11 +81,54 @@ func NewCloudflareProviderWithClient(cfg *config.CloudflareConfig, client *cloud
 	}
 }
 
+// cloudflareAuth returns the client options authenticating with the API token, or with the
+// Global API Key and email of the account if no token is configured
+func cloudflareAuth(cfg *config.CloudflareConfig) []option.RequestOption {
+	if cfg.APIToken == "" && cfg.APIKey != "" {
+		// Drop a token the client picked up from CLOUDFLARE_API_TOKEN, so only the key is sent
+		return []option.RequestOption{
+			option.WithHeaderDel("authorization"),
+			option.WithAPIKey(cfg.APIKey),
+			option.WithAPIEmail(cfg.APIEmail),
+		}
+	}
+	return []option.RequestOption{option.WithAPIToken(cfg.APIToken)}
+}
+
+// ForRecord sets the name of the record managed by the provider, from which Validate derives
+// the zone when neither zone_id nor zone_name is configured. It returns the provider.
+func (c *CloudflareProvider) ForRecord(name string) *CloudflareProvider {
+	c.recordName = name
+	return c
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (c *CloudflareProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	httpClient := &http.Client{Transport: newProxyTransport(nil, proxy)}
+	c.client = cloudflare.NewClient(append(slices.Clone(c.client.Options), option.WithHTTPClient(httpClient))...)
+}
+
 // Name returns the provider name
 func (c *CloudflareProvider) Name() string {
 	return "cloudflare"
 }
 
+// proxied returns whether record is proxied through Cloudflare: the override for the role of
+// the written IP if one is configured, or else the proxied setting
+func (c *CloudflareProvider) proxied(record interfaces.DNSRecord) bool {
+	switch record.Metadata[interfaces.MetadataRole] {
+	case interfaces.RolePrimary:
+		if c.config.ProxiedPrimary != nil {
+			return *c.config.ProxiedPrimary
+		}
+	case interfaces.RoleSecondary:
+		if c.config.ProxiedSecondary != nil {
+			return *c.config.ProxiedSecondary
+		}
+	}
+	return c.config.Proxied
+}
+
 // createRecordParam creates the appropriate RecordUnionParam based on the record type
 func (c *CloudflareProvider) createRecordParam(record interfaces.DNSRecord) (dns.RecordUnionParam, error) {
 	switch record.Type {
@@ -77,7 +138,7 @@ func (c *CloudflareProvider) createRecordParam(record interfaces.DNSRecord) (dns
 			Type:    cloudflare.Raw[dns.ARecordType](dns.ARecordType(record.Type)),
 			Content: cloudflare.String(record.Value),
 			TTL:     cloudflare.Raw[dns.TTL](dns.TTL(record.TTL)),
-			Proxied: cloudflare.Bool(c.config.Proxied),
+			Proxied: cloudflare.Bool(c.proxied(record)),
 		}, nil
 	case "AAAA":
 		return dns.AAAARecordParam{
@@ -85,7 +146,7 @@ func (c *CloudflareProvider) createRecordParam(record interfaces.DNSRecord) (dns
 			Type:    cloudflare.Raw[dns.AAAARecordType](dns.AAAARecordType(record.Type)),
 			Content: cloudflare.String(record.Value),
 			TTL:     cloudflare.Raw[dns.TTL](dns.TTL(record.TTL)),
-			Proxied: cloudflare.Bool(c.config.Proxied),
+			Proxied: cloudflare.Bool(c.proxied(record)),
 		}, nil
 	case "CNAME":
 		return dns.CNAMERecordParam{
@@ -93,7 +154,7 @@ func (c *CloudflareProvider) createRecordParam(record interfaces.DNSRecord) (dns
 			Type:    cloudflare.Raw[dns.CNAMERecordType](dns.CNAMERecordType(record.Type)),
 			Content: cloudflare.F[interface{}](record.Value),
 			TTL:     cloudflare.Raw[dns.TTL](dns.TTL(record.TTL)),
-			Proxied: cloudflare.Bool(c.config.Proxied),
+			Proxied: cloudflare.Bool(c.proxied(record)),
 		}, nil
 	case "TXT":
 		return dns.TXTRecordParam{
@@ -151,9 +212,14 @@ func (c *CloudflareProvider) UpdateRecord(ctx context.Context, record interfaces
 		zap.String("value", record.Value),
 	)
 
+	zoneID, err := c.getZoneID(ctx, record.Name)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", record.Name, err)
+	}
+
 	// First, try to find existing record
 	records, err := c.client.DNS.Records.List(ctx, dns.RecordListParams{
-		ZoneID: cloudflare.String(c.config.ZoneID),
+		ZoneID: cloudflare.String(zoneID),
 		Name:   cloudflare.String(record.Name),
 		Type:   cloudflare.Raw[dns.RecordListParamsType](dns.RecordListParamsType(record.Type)),
 	})
@@ -162,41 +228,231 @@ func (c *CloudflareProvider) UpdateRecord(ctx context.Context, record interfaces
 	}
 
 	if len(records.Result) > 0 {
-		// Update existing record
-		existingRecord := records.Result[0]
+		return c.writeRecord(ctx, records.Result[0].ID, record)
+	}
+
+	return c.createRecord(ctx, record, true)
+}
+
+// UpdateRecordIf updates or creates a DNS record only if it still matches expected.
+// Cloudflare has no conditional write, so the record is read again immediately before it is written;
+// this narrows the window in which a concurrent change can be lost but cannot close it.
+func (c *CloudflareProvider) UpdateRecordIf(ctx context.Context, record interfaces.DNSRecord, expected *interfaces.DNSRecord) error {
+	c.logger.Info("updating DNS record",
+		zap.String("provider", "cloudflare"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+		zap.Bool("conditional", true),
+	)
+
+	current, err := c.GetRecord(ctx, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+
+	if !recordMatches(current, expected) {
+		var actual string
+		if current != nil {
+			actual = current.Value
+		}
+		return errors.NewConflictError("cloudflare", record.Name, expectedValue(expected), actual)
+	}
+
+	if current != nil {
+		return c.writeRecord(ctx, current.Metadata["cloudflare_id"], record)
+	}
+
+	// A record created concurrently with a different value is a conflict, not something to overwrite
+	return c.createRecord(ctx, record, false)
+}
+
+// BatchKey identifies the zone, credentials and proxy settings of the provider, see UpdateRecords.
+// Without a configured zone_id or zone_name the zone is only known after a lookup, so the key
+// includes the record name and the record is not batched with others.
+func (c *CloudflareProvider) BatchKey() string {
+	zone := c.config.ZoneID
+	if zone == "" && c.config.ZoneName != "" {
+		zone = "name:" + strings.ToLower(strings.TrimSuffix(c.config.ZoneName, "."))
+	}
+	if zone == "" {
+		zone = "record:" + c.recordName
+	}
+	// The credentials are only compared, so they are hashed rather than kept in the key
+	credentials := sha256.Sum256([]byte(c.config.APIToken + "|" + c.config.APIKey + "|" + c.config.APIEmail))
+	return fmt.Sprintf("cloudflare|%s|%x|%t|%s|%s", zone, credentials, c.config.Proxied,
+		formatOptionalBool(c.config.ProxiedPrimary), formatOptionalBool(c.config.ProxiedSecondary))
+}
+
+// formatOptionalBool formats an optional setting, "" if it is not set
+func formatOptionalBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+// cloudflareBatch is the body of a request to the batch endpoint, whose operations Cloudflare
+// applies in a single transaction
+type cloudflareBatch struct {
+	Puts  []json.RawMessage `json:"puts,omitempty"`
+	Posts []json.RawMessage `json:"posts,omitempty"`
+}
+
+// UpdateRecords updates or creates records in the zone with a single request to the batch
+// endpoint, which Cloudflare applies atomically. Existing records are replaced, missing ones created.
+func (c *CloudflareProvider) UpdateRecords(ctx context.Context, records []interfaces.DNSRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Type == "" {
+			return errors.NewDNSProviderError("cloudflare", record.Name, fmt.Errorf("empty record type"))
+		}
+		names = append(names, record.Name)
+	}
+	batchName := strings.Join(names, ",")
+
+	c.logger.Info("updating DNS records in a single batch",
+		zap.String("provider", "cloudflare"),
+		zap.Strings("records", names),
+	)
+
+	zoneID, err := c.getZoneID(ctx, records[0].Name)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", batchName, err)
+	}
+
+	var batch cloudflareBatch
+	for _, record := range records {
+		if _, err := c.getZoneID(ctx, record.Name); err != nil {
+			return errors.NewDNSProviderError("cloudflare", record.Name, err)
+		}
+
+		existing, err := c.GetRecord(ctx, record.Name, record.Type)
+		if err != nil {
+			return err
+		}
+
 		recordParam, err := c.createRecordParam(record)
 		if err != nil {
 			return errors.NewDNSProviderError("cloudflare", record.Name, err)
 		}
-		_, err = c.client.DNS.Records.Update(ctx, existingRecord.ID, dns.RecordUpdateParams{
-			ZoneID: cloudflare.String(c.config.ZoneID),
-			Record: recordParam,
-		})
+		var recordID string
+		if existing != nil {
+			recordID = existing.Metadata["cloudflare_id"]
+		}
+		operation, err := cloudflareBatchOperation(recordParam, recordID)
 		if err != nil {
 			return errors.NewDNSProviderError("cloudflare", record.Name, err)
 		}
 
-		c.logger.Info("DNS record updated successfully",
-			zap.String("provider", "cloudflare"),
-			zap.String("record", record.Name),
-			zap.String("record_id", existingRecord.ID),
-		)
-		return nil
+		if existing != nil {
+			batch.Puts = append(batch.Puts, operation)
+		} else {
+			batch.Posts = append(batch.Posts, operation)
+		}
+	}
+
+	path := fmt.Sprintf("zones/%s/dns_records/batch", url.PathEscape(zoneID))
+	if err := c.client.Post(ctx, path, batch, nil); err != nil {
+		return errors.NewDNSProviderError("cloudflare", batchName, fmt.Errorf("failed to apply batch: %w", err))
 	}
 
-	// Create new record
+	c.logger.Info("DNS records updated successfully",
+		zap.String("provider", "cloudflare"),
+		zap.Strings("records", names),
+		zap.Int("updated", len(batch.Puts)),
+		zap.Int("created", len(batch.Posts)),
+	)
+
+	return nil
+}
+
+// cloudflareBatchOperation encodes a record for the batch endpoint, with the ID of the record it
+// replaces unless recordID is empty
+func cloudflareBatchOperation(recordParam dns.RecordUnionParam, recordID string) (json.RawMessage, error) {
+	data, err := json.Marshal(recordParam)
+	if err != nil || recordID == "" {
+		return data, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["id"] = recordID
+	return json.Marshal(fields)
+}
+
+// writeRecord updates the record with the given ID
+func (c *CloudflareProvider) writeRecord(ctx context.Context, recordID string, record interfaces.DNSRecord) error {
 	recordParam, err := c.createRecordParam(record)
 	if err != nil {
 		return errors.NewDNSProviderError("cloudflare", record.Name, err)
 	}
-	_, err = c.client.DNS.Records.New(ctx, dns.RecordNewParams{
-		ZoneID: cloudflare.String(c.config.ZoneID),
+
+	zoneID, err := c.getZoneID(ctx, record.Name)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", record.Name, err)
+	}
+
+	_, err = c.client.DNS.Records.Update(ctx, recordID, dns.RecordUpdateParams{
+		ZoneID: cloudflare.String(zoneID),
 		Record: recordParam,
 	})
 	if err != nil {
 		return errors.NewDNSProviderError("cloudflare", record.Name, err)
 	}
 
+	c.logger.Info("DNS record updated successfully",
+		zap.String("provider", "cloudflare"),
+		zap.String("record", record.Name),
+		zap.String("record_id", recordID),
+	)
+	return nil
+}
+
+// createRecord creates a record. If Cloudflare reports that it already exists, it is read back and
+// the create counts as successful if it has the desired value. Otherwise it is updated if overwrite
+// is set, or reported as a conflict.
+func (c *CloudflareProvider) createRecord(ctx context.Context, record interfaces.DNSRecord, overwrite bool) error {
+	recordParam, err := c.createRecordParam(record)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", record.Name, err)
+	}
+
+	zoneID, err := c.getZoneID(ctx, record.Name)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", record.Name, err)
+	}
+
+	_, err = c.client.DNS.Records.New(ctx, dns.RecordNewParams{
+		ZoneID: cloudflare.String(zoneID),
+		Record: recordParam,
+	})
+	if err != nil {
+		createErr := errors.NewDNSProviderError("cloudflare", record.Name, err)
+		if !isCloudflareRecordExists(err) {
+			return createErr
+		}
+
+		var update func(ctx context.Context, existing *interfaces.DNSRecord) error
+		if overwrite {
+			update = func(ctx context.Context, existing *interfaces.DNSRecord) error {
+				return c.writeRecord(ctx, existing.Metadata["cloudflare_id"], record)
+			}
+		}
+		return resolveDuplicateCreate(ctx, c.logger, "cloudflare", record, createErr,
+			func(ctx context.Context) (*interfaces.DNSRecord, error) {
+				return c.GetRecord(ctx, record.Name, record.Type)
+			},
+			update,
+		)
+	}
+
 	c.logger.Info("DNS record created successfully",
 		zap.String("provider", "cloudflare"),
 		zap.String("record", record.Name),
@@ -218,8 +474,13 @@ func (c *CloudflareProvider) GetRecord(ctx context.Context, name string, rtype s
 		return nil, errors.NewDNSProviderError("cloudflare", name, fmt.Errorf("empty record type"))
 	}
 
+	zoneID, err := c.getZoneID(ctx, name)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("cloudflare", name, err)
+	}
+
 	records, err := c.client.DNS.Records.List(ctx, dns.RecordListParams{
-		ZoneID: cloudflare.String(c.config.ZoneID),
+		ZoneID: cloudflare.String(zoneID),
 		Name:   cloudflare.String(name),
 		Type:   cloudflare.Raw[dns.RecordListParamsType](dns.RecordListParamsType(rtype)),
 	})
@@ -232,18 +493,65 @@ func (c *CloudflareProvider) GetRecord(ctx context.Context, name string, rtype s
 	}
 
 	// Return the first matching record
-	record := records.Result[0]
-	return &interfaces.DNSRecord{
+	return cloudflareRecord(records.Result[0]), nil
+}
+
+// ListRecords returns all records of the zone, see interfaces.RecordLister
+func (c *CloudflareProvider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	c.logger.Debug("listing DNS records",
+		zap.String("provider", "cloudflare"),
+	)
+
+	zoneID, err := c.getZoneID(ctx, c.recordName)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("cloudflare", c.recordName, err)
+	}
+
+	var records []interfaces.DNSRecord
+	iter := c.client.DNS.Records.ListAutoPaging(ctx, dns.RecordListParams{
+		ZoneID: cloudflare.String(zoneID),
+	})
+	for iter.Next() {
+		records = append(records, *cloudflareRecord(iter.Current()))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.NewDNSProviderError("cloudflare", c.recordName, fmt.Errorf("failed to list records: %w", err))
+	}
+
+	return records, nil
+}
+
+// cloudflareRecord converts a record returned by the API
+func cloudflareRecord(record dns.Record) *interfaces.DNSRecord {
+	result := &interfaces.DNSRecord{
 		Name:     record.Name,
 		Type:     string(record.Type),
-		Value:    record.Content.(string),
+		Value:    recordContent(record.Content),
 		TTL:      int(record.TTL),
 		Provider: "cloudflare",
 		Metadata: map[string]string{
 			"cloudflare_id": record.ID,
 			"proxied":       fmt.Sprintf("%t", record.Proxied),
 		},
-	}, nil
+	}
+	if record.Type == dns.RecordTypeMX {
+		// Read back by createRecordParam, so updates keep the priority
+		result.Metadata["priority"] = strconv.FormatFloat(record.Priority, 'f', -1, 64)
+	}
+	return result
+}
+
+// recordContent returns the content of a record as a string. The SDK decodes it untyped, and
+// records without a plain content, e.g. SRV records described by their data, may have none.
+func recordContent(content interface{}) string {
+	switch v := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
 // DeleteRecord deletes a DNS record
@@ -259,8 +567,13 @@ func (c *CloudflareProvider) DeleteRecord(ctx context.Context, name, recordType
 		return errors.NewDNSProviderError("cloudflare", name, fmt.Errorf("empty record type"))
 	}
 
+	zoneID, err := c.getZoneID(ctx, name)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", name, err)
+	}
+
 	records, err := c.client.DNS.Records.List(ctx, dns.RecordListParams{
-		ZoneID: cloudflare.String(c.config.ZoneID),
+		ZoneID: cloudflare.String(zoneID),
 		Name:   cloudflare.String(name),
 		Type:   cloudflare.Raw[dns.RecordListParamsType](dns.RecordListParamsType(recordType)),
 	})
@@ -280,7 +593,7 @@ func (c *CloudflareProvider) DeleteRecord(ctx context.Context, name, recordType
 	// Delete the first matching record
 	record := records.Result[0]
 	_, err = c.client.DNS.Records.Delete(ctx, record.ID, dns.RecordDeleteParams{
-		ZoneID: cloudflare.String(c.config.ZoneID),
+		ZoneID: cloudflare.String(zoneID),
 	})
 	if err != nil {
 		return errors.NewDNSProviderError("cloudflare", name, err)
@@ -299,9 +612,15 @@ func (c *CloudflareProvider) DeleteRecord(ctx context.Context, name, recordType
 func (c *CloudflareProvider) Validate(ctx context.Context) error {
 	c.logger.Debug("validating Cloudflare provider configuration")
 
+	// Resolve the zone first, so that a zone missing from the account fails at startup
+	zoneID, err := c.getZoneID(ctx, c.recordName)
+	if err != nil {
+		return errors.NewDNSProviderError("cloudflare", "validation", err)
+	}
+
 	// Test API access by listing records
-	_, err := c.client.DNS.Records.List(ctx, dns.RecordListParams{
-		ZoneID: cloudflare.String(c.config.ZoneID),
+	_, err = c.client.DNS.Records.List(ctx, dns.RecordListParams{
+		ZoneID: cloudflare.String(zoneID),
 	})
 	if err != nil {
 		return errors.NewDNSProviderError("cloudflare", "validation", err)
@@ -310,3 +629,100 @@ func (c *CloudflareProvider) Validate(ctx context.Context) error {
 	c.logger.Info("Cloudflare provider validation successful")
 	return nil
 }
+
+// getZoneID returns the ID of the zone containing the record name. Unless zone_id is configured,
+// the zone is looked up on first use and cached; names outside the cached zone are rejected.
+func (c *CloudflareProvider) getZoneID(ctx context.Context, name string) (string, error) {
+	if c.config.ZoneID != "" {
+		return c.config.ZoneID, nil
+	}
+
+	// Take read lock to check cached zone
+	c.zoneMu.RLock()
+	zoneID, zoneName := c.zoneID, c.zoneName
+	c.zoneMu.RUnlock()
+
+	if zoneID == "" {
+		// Zone not cached, acquire write lock
+		c.zoneMu.Lock()
+		defer c.zoneMu.Unlock()
+
+		// Re-check in case another goroutine looked it up meanwhile
+		if c.zoneID == "" {
+			var err error
+			if c.zoneID, c.zoneName, err = c.lookupZone(ctx, name); err != nil {
+				return "", err
+			}
+			c.logger.Debug("resolved cloudflare zone ID",
+				zap.String("zone", c.zoneName),
+				zap.String("zone_id", c.zoneID),
+			)
+		}
+		zoneID, zoneName = c.zoneID, c.zoneName
+	}
+
+	if name != "" {
+		if _, err := relativeName(name, zoneName); err != nil {
+			return "", err
+		}
+	}
+
+	return zoneID, nil
+}
+
+// lookupZone finds the zone named zone_name, or else the zone with the longest name that
+// contains the record name, among the zones of the account
+func (c *CloudflareProvider) lookupZone(ctx context.Context, name string) (string, string, error) {
+	params := zones.ZoneListParams{}
+	if c.config.ZoneName != "" {
+		params.Name = cloudflare.F(strings.TrimSuffix(c.config.ZoneName, "."))
+	} else if name == "" {
+		return "", "", fmt.Errorf("zone_id or zone_name is required when the record name is unknown")
+	}
+
+	var bestID, bestName string
+	iter := c.client.Zones.ListAutoPaging(ctx, params)
+	for iter.Next() {
+		zone := iter.Current()
+		zoneName := strings.ToLower(strings.TrimSuffix(zone.Name, "."))
+
+		if c.config.ZoneName != "" {
+			if zoneName == strings.ToLower(strings.TrimSuffix(c.config.ZoneName, ".")) {
+				return zone.ID, zoneName, nil
+			}
+			continue
+		}
+
+		if _, err := relativeName(name, zoneName); err == nil && len(zoneName) > len(bestName) {
+			bestID, bestName = zone.ID, zoneName
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return "", "", fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	if c.config.ZoneName != "" {
+		return "", "", fmt.Errorf("zone %s not found in the account", c.config.ZoneName)
+	}
+	if bestID == "" {
+		return "", "", fmt.Errorf("no zone in the account contains %s", name)
+	}
+
+	return bestID, bestName, nil
+}
+
+// isCloudflareRecordExists reports whether err is a Cloudflare API error for a record that already exists
+func isCloudflareRecordExists(err error) bool {
+	var apiErr *cloudflare.Error
+	if !stderrors.As(err, &apiErr) {
+		return false
+	}
+
+	for _, e := range apiErr.Errors {
+		if code := int64(e.Code); code == cloudflareRecordExists || code == cloudflareIdenticalRecordExists {
+			return true
+		}
+	}
+
+	return false
+}
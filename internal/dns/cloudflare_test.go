@@ -2,12 +2,22 @@ package dns_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/cloudflare/cloudflare-go/v2"
+	"github.com/cloudflare/cloudflare-go/v2/option"
 	"github.com/devhat/ipfailover/internal/config"
 	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/errors"
 	"github.com/devhat/ipfailover/pkg/interfaces"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -165,3 +175,568 @@ func TestCloudflareProvider_ConfigurationValidation(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+// fakeCloudflareRecord is a DNS record held by fakeCloudflare
+type fakeCloudflareRecord struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Content  interface{} `json:"content"` // Not a string for some record types, see TestCloudflareProvider_GetRecordTypes
+	TTL      int         `json:"ttl"`
+	Proxied  bool        `json:"proxied"`
+	Priority float64     `json:"priority,omitempty"`
+}
+
+type fakeCloudflareZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// fakeCloudflare is a minimal in-memory Cloudflare DNS records API for a single zone, test-zone.
+// The account may hold further zones, which are only listed.
+type fakeCloudflare struct {
+	t           *testing.T
+	mu          sync.Mutex
+	records     map[string]fakeCloudflareRecord
+	writes      int
+	batches     int
+	rejectBatch bool // Fails batches as invalid, leaving the records unchanged
+	zones       []fakeCloudflareZone
+	zoneLookups int
+
+	// rejectCreate makes creates fail with "record already exists", after adding concurrent to the zone
+	// as if another client had created it first
+	rejectCreate bool
+	concurrent   *fakeCloudflareRecord
+}
+
+func newFakeCloudflare(t *testing.T, records ...fakeCloudflareRecord) *fakeCloudflare {
+	f := &fakeCloudflare{t: t, records: make(map[string]fakeCloudflareRecord)}
+	for _, record := range records {
+		f.records[record.ID] = record
+	}
+	return f
+}
+
+// setContent changes a record the way an operator editing it in the dashboard would
+func (f *fakeCloudflare) setContent(id, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record := f.records[id]
+	record.Content = content
+	f.records[id] = record
+}
+
+func (f *fakeCloudflare) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const recordsPath = "/zones/test-zone/dns_records"
+	respond := func(result interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"errors":   []interface{}{},
+			"messages": []interface{}{},
+			"result":   result,
+		})
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones":
+		query := r.URL.Query()
+		if query.Get("page") == "" || query.Get("page") == "1" {
+			f.zoneLookups++
+		}
+		result := []fakeCloudflareZone{}
+		for _, zone := range f.zones {
+			if (query.Get("name") == "" || query.Get("name") == zone.Name) && (query.Get("page") == "" || query.Get("page") == "1") {
+				result = append(result, zone)
+			}
+		}
+		respond(result)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, recordsPath):
+		query := r.URL.Query()
+		result := []fakeCloudflareRecord{}
+		for _, record := range f.records {
+			if query.Get("page") != "" && query.Get("page") != "1" {
+				break
+			}
+			if (query.Get("name") == "" || query.Get("name") == record.Name) && (query.Get("type") == "" || query.Get("type") == record.Type) {
+				result = append(result, record)
+			}
+		}
+		respond(result)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, recordsPath+"/batch"):
+		var batch struct {
+			Puts  []fakeCloudflareRecord `json:"puts"`
+			Posts []fakeCloudflareRecord `json:"posts"`
+		}
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&batch))
+		if f.rejectBatch {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":1004,"message":"DNS Validation Error"}],"messages":[],"result":null}`))
+			return
+		}
+		for _, record := range batch.Puts {
+			f.records[record.ID] = record
+		}
+		for i, record := range batch.Posts {
+			record.ID = fmt.Sprintf("rec-batch-%d", i)
+			f.records[record.ID] = record
+		}
+		f.batches++
+		respond(batch)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, recordsPath) && f.rejectCreate:
+		if f.concurrent != nil {
+			f.records[f.concurrent.ID] = *f.concurrent
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":81057,"message":"Record already exists."}],"messages":[],"result":null}`))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, recordsPath):
+		var record fakeCloudflareRecord
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+		record.ID = "rec-new"
+		f.records[record.ID] = record
+		f.writes++
+		respond(record)
+	case r.Method == http.MethodPut && strings.Contains(r.URL.Path, recordsPath+"/"):
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		var record fakeCloudflareRecord
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+		record.ID = id
+		f.records[id] = record
+		f.writes++
+		respond(record)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newCloudflareTestProvider(t *testing.T, fake *fakeCloudflare) *dns.CloudflareProvider {
+	return newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{
+		APIToken: "test-token",
+		ZoneID:   "test-zone",
+	})
+}
+
+func newCloudflareTestProviderWithConfig(t *testing.T, fake *fakeCloudflare, cfg *config.CloudflareConfig) *dns.CloudflareProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := cloudflare.NewClient(
+		option.WithAPIToken("test-token"),
+		option.WithBaseURL(server.URL+"/"),
+		option.WithMaxRetries(0),
+	)
+
+	return dns.NewCloudflareProviderWithClient(cfg, client, zap.NewNop())
+}
+
+func TestCloudflareProvider_GetRecordTypes(t *testing.T) {
+	fake := newFakeCloudflare(t,
+		fakeCloudflareRecord{ID: "rec-aaaa", Name: "home.example.com", Type: "AAAA", Content: "2001:db8::10", TTL: 300},
+		fakeCloudflareRecord{ID: "rec-cname", Name: "www.example.com", Type: "CNAME", Content: "home.example.com", TTL: 1, Proxied: true},
+		fakeCloudflareRecord{ID: "rec-txt", Name: "example.com", Type: "TXT", Content: `"v=spf1 ip4:203.0.113.10 -all"`, TTL: 3600},
+		fakeCloudflareRecord{ID: "rec-mx", Name: "example.com", Type: "MX", Content: "mail.example.com", TTL: 3600, Priority: 20},
+		fakeCloudflareRecord{ID: "rec-srv", Name: "_sip._udp.example.com", Type: "SRV", TTL: 300},
+		fakeCloudflareRecord{ID: "rec-uri", Name: "_ftp._tcp.example.com", Type: "URI", Content: 10, TTL: 300},
+	)
+	provider := newCloudflareTestProvider(t, fake)
+	ctx := context.Background()
+
+	tests := []struct {
+		name, rtype string
+		want        interfaces.DNSRecord
+	}{
+		{"home.example.com", "AAAA", interfaces.DNSRecord{Value: "2001:db8::10", TTL: 300, Metadata: map[string]string{"cloudflare_id": "rec-aaaa", "proxied": "false"}}},
+		{"www.example.com", "CNAME", interfaces.DNSRecord{Value: "home.example.com", TTL: 1, Metadata: map[string]string{"cloudflare_id": "rec-cname", "proxied": "true"}}},
+		{"example.com", "TXT", interfaces.DNSRecord{Value: `"v=spf1 ip4:203.0.113.10 -all"`, TTL: 3600, Metadata: map[string]string{"cloudflare_id": "rec-txt", "proxied": "false"}}},
+		{"example.com", "MX", interfaces.DNSRecord{Value: "mail.example.com", TTL: 3600, Metadata: map[string]string{"cloudflare_id": "rec-mx", "proxied": "false", "priority": "20"}}},
+		// Records without a string content are read instead of panicking
+		{"_sip._udp.example.com", "SRV", interfaces.DNSRecord{Value: "", TTL: 300, Metadata: map[string]string{"cloudflare_id": "rec-srv", "proxied": "false"}}},
+		{"_ftp._tcp.example.com", "URI", interfaces.DNSRecord{Value: "10", TTL: 300, Metadata: map[string]string{"cloudflare_id": "rec-uri", "proxied": "false"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rtype, func(t *testing.T) {
+			record, err := provider.GetRecord(ctx, tt.name, tt.rtype)
+			require.NoError(t, err)
+			require.NotNil(t, record)
+
+			want := tt.want
+			want.Name = tt.name
+			want.Type = tt.rtype
+			want.Provider = "cloudflare"
+			assert.Equal(t, want, *record)
+		})
+	}
+
+	t.Run("MX priority is kept on update", func(t *testing.T) {
+		record, err := provider.GetRecord(ctx, "example.com", "MX")
+		require.NoError(t, err)
+		record.Value = "mail2.example.com"
+		require.NoError(t, provider.UpdateRecord(ctx, *record))
+
+		assert.Equal(t, "mail2.example.com", fake.records["rec-mx"].Content)
+		assert.Equal(t, float64(20), fake.records["rec-mx"].Priority)
+	})
+}
+
+func TestCloudflareProvider_UpdateRecords(t *testing.T) {
+	records := []interfaces.DNSRecord{
+		{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300, Provider: "cloudflare"},
+		{Name: "vpn.example.com", Type: "A", Value: "203.0.113.10", TTL: 60, Provider: "cloudflare"},
+	}
+
+	t.Run("applies all records in one batch", func(t *testing.T) {
+		fake := newFakeCloudflare(t, fakeCloudflareRecord{ID: "rec-home", Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 300})
+		provider := newCloudflareTestProvider(t, fake)
+
+		var _ interfaces.BatchUpdater = provider
+		require.NoError(t, provider.UpdateRecords(context.Background(), records))
+
+		assert.Equal(t, 1, fake.batches)
+		assert.Zero(t, fake.writes, "no single-record writes")
+		assert.Equal(t, "203.0.113.10", fake.records["rec-home"].Content)
+		assert.Equal(t, fakeCloudflareRecord{ID: "rec-batch-0", Name: "vpn.example.com", Type: "A", Content: "203.0.113.10", TTL: 60},
+			fake.records["rec-batch-0"])
+	})
+
+	t.Run("rejected batch changes nothing", func(t *testing.T) {
+		fake := newFakeCloudflare(t, fakeCloudflareRecord{ID: "rec-home", Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 300})
+		fake.rejectBatch = true
+		provider := newCloudflareTestProvider(t, fake)
+
+		err := provider.UpdateRecords(context.Background(), records)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to apply batch")
+		assert.Equal(t, "192.0.2.1", fake.records["rec-home"].Content)
+		assert.Len(t, fake.records, 1)
+	})
+
+	t.Run("empty record type", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		provider := newCloudflareTestProvider(t, fake)
+
+		invalid := append([]interfaces.DNSRecord{}, records...)
+		invalid[1].Type = ""
+		err := provider.UpdateRecords(context.Background(), invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "empty record type")
+		assert.Zero(t, fake.batches)
+	})
+
+	t.Run("batch key identifies zone, credentials and proxy settings", func(t *testing.T) {
+		key := func(cfg config.CloudflareConfig, record string) string {
+			return dns.NewCloudflareProvider(&cfg, zap.NewNop()).ForRecord(record).BatchKey()
+		}
+		zone := config.CloudflareConfig{APIToken: "token", ZoneID: "zone-1"}
+		otherZone := zone
+		otherZone.ZoneID = "zone-2"
+		otherToken := zone
+		otherToken.APIToken = "other-token"
+		proxied := zone
+		proxied.Proxied = true
+		derived := config.CloudflareConfig{APIToken: "token"}
+
+		assert.Equal(t, key(zone, "home.example.com"), key(zone, "vpn.example.com"))
+		assert.NotEqual(t, key(zone, "home.example.com"), key(otherZone, "home.example.com"))
+		assert.NotEqual(t, key(zone, "home.example.com"), key(otherToken, "home.example.com"))
+		assert.NotEqual(t, key(zone, "home.example.com"), key(proxied, "home.example.com"))
+		assert.NotContains(t, key(zone, "home.example.com"), "token")
+
+		// Records in a zone derived from their name are not batched
+		assert.NotEqual(t, key(derived, "home.example.com"), key(derived, "vpn.example.com"))
+	})
+}
+
+func TestCloudflareProvider_ListRecords(t *testing.T) {
+	fake := newFakeCloudflare(t,
+		fakeCloudflareRecord{ID: "rec-a", Name: "home.example.com", Type: "A", Content: "203.0.113.10", TTL: 300},
+		fakeCloudflareRecord{ID: "rec-mx", Name: "example.com", Type: "MX", Content: "mail.example.com", TTL: 3600, Priority: 10},
+	)
+	provider := newCloudflareTestProvider(t, fake)
+
+	records, err := provider.ListRecords(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []interfaces.DNSRecord{
+		{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300, Provider: "cloudflare",
+			Metadata: map[string]string{"cloudflare_id": "rec-a", "proxied": "false"}},
+		{Name: "example.com", Type: "MX", Value: "mail.example.com", TTL: 3600, Provider: "cloudflare",
+			Metadata: map[string]string{"cloudflare_id": "rec-mx", "proxied": "false", "priority": "10"}},
+	}, records)
+
+	t.Run("zone derived from the record name", func(t *testing.T) {
+		fake := newFakeCloudflare(t, fakeCloudflareRecord{ID: "rec-a", Name: "home.example.com", Type: "A", Content: "203.0.113.10", TTL: 300})
+		fake.zones = []fakeCloudflareZone{{ID: "test-zone", Name: "example.com"}}
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{APIToken: "test-token"}).ForRecord("home.example.com")
+
+		records, err := provider.ListRecords(context.Background())
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "home.example.com", records[0].Name)
+	})
+}
+
+func TestCloudflareProvider_UpdateRecordIf(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "cloudflare",
+	}
+	existing := fakeCloudflareRecord{ID: "rec-1", Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 300}
+
+	t.Run("updates unchanged record", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing)
+		provider := newCloudflareTestProvider(t, fake)
+
+		var _ interfaces.ConditionalUpdater = provider
+		expected, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+
+		require.NoError(t, provider.UpdateRecordIf(context.Background(), record, expected))
+		assert.Equal(t, "203.0.113.10", fake.records["rec-1"].Content)
+		assert.Equal(t, 1, fake.writes)
+	})
+
+	t.Run("detects concurrent modification", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing)
+		provider := newCloudflareTestProvider(t, fake)
+
+		expected, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+
+		// The record is edited between being read and being updated
+		fake.setContent("rec-1", "198.51.100.99")
+
+		err = provider.UpdateRecordIf(context.Background(), record, expected)
+		var conflictErr *errors.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "192.0.2.1", conflictErr.Expected)
+		assert.Equal(t, "198.51.100.99", conflictErr.Actual)
+		assert.Equal(t, "198.51.100.99", fake.records["rec-1"].Content)
+		assert.Zero(t, fake.writes)
+	})
+
+	t.Run("detects record created concurrently", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing)
+		provider := newCloudflareTestProvider(t, fake)
+
+		err := provider.UpdateRecordIf(context.Background(), record, nil)
+		var conflictErr *errors.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Empty(t, conflictErr.Expected)
+		assert.Equal(t, "192.0.2.1", conflictErr.Actual)
+		assert.Zero(t, fake.writes)
+	})
+}
+
+func TestCloudflareProvider_DuplicateCreate(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "cloudflare",
+	}
+
+	t.Run("record created concurrently with the desired value", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		fake.rejectCreate = true
+		fake.concurrent = &fakeCloudflareRecord{ID: "rec-1", Name: "home.example.com", Type: "A", Content: "203.0.113.10", TTL: 300}
+		provider := newCloudflareTestProvider(t, fake)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, "203.0.113.10", fake.records["rec-1"].Content)
+		assert.Zero(t, fake.writes)
+	})
+
+	t.Run("record created concurrently with a different value is updated", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		fake.rejectCreate = true
+		fake.concurrent = &fakeCloudflareRecord{ID: "rec-1", Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 300}
+		provider := newCloudflareTestProvider(t, fake)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, "203.0.113.10", fake.records["rec-1"].Content)
+		assert.Equal(t, 1, fake.writes)
+	})
+
+	t.Run("conditional create reports a different value as a conflict", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		fake.rejectCreate = true
+		fake.concurrent = &fakeCloudflareRecord{ID: "rec-1", Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 300}
+		provider := newCloudflareTestProvider(t, fake)
+
+		err := provider.UpdateRecordIf(context.Background(), record, nil)
+		var conflictErr *errors.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "192.0.2.1", conflictErr.Actual)
+		assert.Equal(t, "192.0.2.1", fake.records["rec-1"].Content)
+		assert.Zero(t, fake.writes)
+	})
+
+	t.Run("record missing after duplicate error", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		fake.rejectCreate = true
+		provider := newCloudflareTestProvider(t, fake)
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "81057")
+		assert.Zero(t, fake.writes)
+	})
+}
+
+func TestCloudflareProvider_ZoneResolution(t *testing.T) {
+	ctx := context.Background()
+	accountZones := []fakeCloudflareZone{
+		{ID: "parent-zone", Name: "example.com"},
+		{ID: "test-zone", Name: "lab.example.com"},
+		{ID: "other-zone", Name: "example.net"},
+	}
+	existing := []fakeCloudflareRecord{
+		{ID: "rec-1", Name: "home.lab.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+		{ID: "rec-2", Name: "lab.example.com", Type: "A", Content: "192.0.2.2", TTL: 300},
+	}
+
+	t.Run("subdomain resolves to the longest matching zone", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing...)
+		fake.zones = accountZones
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{APIToken: "test-token"}).
+			ForRecord("home.lab.example.com")
+
+		require.NoError(t, provider.Validate(ctx))
+		found, err := provider.GetRecord(ctx, "home.lab.example.com", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "192.0.2.1", found.Value)
+
+		require.NoError(t, provider.UpdateRecord(ctx, interfaces.DNSRecord{
+			Name:  "home.lab.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		}))
+		assert.Equal(t, "203.0.113.10", fake.records["rec-1"].Content)
+		assert.Equal(t, 1, fake.zoneLookups, "the zone is looked up once and cached")
+	})
+
+	t.Run("apex record", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing...)
+		fake.zones = accountZones
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{APIToken: "test-token"})
+
+		found, err := provider.GetRecord(ctx, "lab.example.com", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "192.0.2.2", found.Value)
+	})
+
+	t.Run("zone name", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing...)
+		fake.zones = accountZones
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{
+			APIToken: "test-token",
+			ZoneName: "lab.example.com",
+		})
+
+		require.NoError(t, provider.Validate(ctx))
+		found, err := provider.GetRecord(ctx, "home.lab.example.com", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "rec-1", found.Metadata["cloudflare_id"])
+
+		_, err = provider.GetRecord(ctx, "home.example.net", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "record home.example.net is not within zone lab.example.com")
+	})
+
+	t.Run("record whose zone is not in the account", func(t *testing.T) {
+		fake := newFakeCloudflare(t, existing...)
+		fake.zones = accountZones
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{APIToken: "test-token"}).
+			ForRecord("home.example.org")
+
+		err := provider.Validate(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no zone in the account contains home.example.org")
+
+		// A failed lookup is not cached
+		_, err = provider.GetRecord(ctx, "home.example.org", "A")
+		require.Error(t, err)
+		assert.Equal(t, 2, fake.zoneLookups)
+	})
+
+	t.Run("zone name not in the account", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		fake.zones = accountZones
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{
+			APIToken: "test-token",
+			ZoneName: "example.org",
+		})
+
+		err := provider.Validate(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "zone example.org not found in the account")
+	})
+}
+
+func TestCloudflareProvider_ProxiedByRole(t *testing.T) {
+	ctx := context.Background()
+	proxiedPrimary, proxiedSecondary := true, false
+	cfg := &config.CloudflareConfig{
+		APIToken:         "test-token",
+		ZoneID:           "test-zone",
+		ProxiedPrimary:   &proxiedPrimary,
+		ProxiedSecondary: &proxiedSecondary,
+	}
+	record := func(value, role string) interfaces.DNSRecord {
+		return interfaces.DNSRecord{
+			Name:     "home.example.com",
+			Type:     "A",
+			Value:    value,
+			TTL:      300,
+			Metadata: map[string]string{interfaces.MetadataRole: role},
+		}
+	}
+
+	t.Run("failing over to the secondary IP", func(t *testing.T) {
+		fake := newFakeCloudflare(t, fakeCloudflareRecord{
+			ID: "rec-1", Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 300, Proxied: true,
+		})
+		provider := newCloudflareTestProviderWithConfig(t, fake, cfg)
+
+		require.NoError(t, provider.UpdateRecord(ctx, record("198.51.100.77", interfaces.RoleSecondary)))
+		assert.Equal(t, "198.51.100.77", fake.records["rec-1"].Content)
+		assert.False(t, fake.records["rec-1"].Proxied)
+	})
+
+	t.Run("failing back to the primary IP", func(t *testing.T) {
+		fake := newFakeCloudflare(t, fakeCloudflareRecord{
+			ID: "rec-1", Name: "home.example.com", Type: "A", Content: "198.51.100.77", TTL: 300, Proxied: false,
+		})
+		provider := newCloudflareTestProviderWithConfig(t, fake, cfg)
+
+		require.NoError(t, provider.UpdateRecord(ctx, record("192.0.2.1", interfaces.RolePrimary)))
+		assert.Equal(t, "192.0.2.1", fake.records["rec-1"].Content)
+		assert.True(t, fake.records["rec-1"].Proxied)
+	})
+
+	t.Run("falls back to proxied without a role override", func(t *testing.T) {
+		fake := newFakeCloudflare(t)
+		provider := newCloudflareTestProviderWithConfig(t, fake, &config.CloudflareConfig{
+			APIToken:       "test-token",
+			ZoneID:         "test-zone",
+			Proxied:        true,
+			ProxiedPrimary: &proxiedSecondary,
+		})
+
+		require.NoError(t, provider.UpdateRecord(ctx, record("198.51.100.77", interfaces.RoleSecondary)))
+		assert.True(t, fake.records["rec-new"].Proxied)
+	})
+}
@@ -45,6 +45,14 @@ func (m *MockDNSProvider) Validate(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockDNSProvider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]interfaces.DNSRecord), args.Error(1)
+}
+
 func TestDNSProvider_Interfaces(t *testing.T) {
 	t.Run("Cloudflare implements DNSProvider", func(t *testing.T) {
 		logger := zap.NewNop()
@@ -55,8 +63,9 @@ func TestDNSProvider_Interfaces(t *testing.T) {
 
 		provider := dns.NewCloudflareProvider(cfg, logger)
 
-		// Test that it implements the interface
+		// Test that it implements the interfaces
 		var _ interfaces.DNSProvider = provider
+		var _ interfaces.RecordLister = provider
 		assert.NotNil(t, provider)
 	})
 
@@ -71,8 +80,9 @@ func TestDNSProvider_Interfaces(t *testing.T) {
 
 		provider := dns.NewCPanelProvider(cfg, logger)
 
-		// Test that it implements the interface
+		// Test that it implements the interfaces
 		var _ interfaces.DNSProvider = provider
+		var _ interfaces.RecordLister = provider
 		assert.NotNil(t, provider)
 	})
 
@@ -88,8 +98,9 @@ func TestDNSProvider_Interfaces(t *testing.T) {
 		provider, err := dns.NewRoute53Provider(cfg, logger)
 		assert.NoError(t, err)
 
-		// Test that it implements the interface
+		// Test that it implements the interfaces
 		var _ interfaces.DNSProvider = provider
+		var _ interfaces.RecordLister = provider
 		assert.NotNil(t, provider)
 	})
 
@@ -102,8 +113,9 @@ func TestDNSProvider_Interfaces(t *testing.T) {
 
 		provider := dns.NewHetznerProvider(cfg, logger)
 
-		// Test that it implements the interface
+		// Test that it implements the interfaces
 		var _ interfaces.DNSProvider = provider
+		var _ interfaces.RecordLister = provider
 		assert.NotNil(t, provider)
 	})
 }
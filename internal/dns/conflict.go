@@ -0,0 +1,24 @@
+package dns
+
+import "github.com/devhat/ipfailover/pkg/interfaces"
+
+// expectedValue returns the value a conditional update expects, or an empty string if the
+// record is expected not to exist
+func expectedValue(expected *interfaces.DNSRecord) string {
+	if expected == nil {
+		return ""
+	}
+	return expected.Value
+}
+
+// recordMatches reports whether a record read from a provider, nil if it does not exist,
+// still has the value and TTL of the expected record. A TTL of zero is not compared.
+func recordMatches(current, expected *interfaces.DNSRecord) bool {
+	if current == nil || expected == nil {
+		return current == nil && expected == nil
+	}
+	if current.Value != expected.Value {
+		return false
+	}
+	return expected.TTL == 0 || current.TTL == expected.TTL
+}
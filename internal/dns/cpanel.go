@@ -1,12 +1,18 @@
 package dns
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/devhat/ipfailover/internal/config"
@@ -15,36 +21,103 @@ import (
 	"go.uber.org/zap"
 )
 
-// CPanelProvider implements DNSProvider for cPanel
+// errCPanelSerialMismatch reports that mass_edit_zone rejected a change because the zone was
+// modified since it was read
+var errCPanelSerialMismatch = stderrors.New("zone serial number changed")
+
+// CPanelProvider implements DNSProvider for cPanel using the UAPI DNS module.
+// Records are read with DNS::parse_zone and changed with DNS::mass_edit_zone, which takes the
+// serial number of the zone as read, so changes based on an outdated view of the zone are rejected.
+// With use_whm the functions are called through WHM API 1 on behalf of the cPanel account.
 type CPanelProvider struct {
-	config *config.CPanelConfig
-	client *http.Client
-	logger *zap.Logger
+	config    *config.CPanelConfig
+	tlsConfig *tls.Config // Nil for the default TLS settings
+	client    *retryingHTTPClient
+	logger    *zap.Logger
 }
 
-// CPanelAPIResponse represents a cPanel API response
+// CPanelAPIResponse is the envelope of UAPI responses. Status is 1 on success,
+// otherwise Errors holds the reasons.
 type CPanelAPIResponse struct {
-	Result struct {
-		Data []CPanelDNSRecord `json:"data"`
-		Meta struct {
-			Result int `json:"result"`
-		} `json:"meta"`
-	} `json:"result"`
+	Status   int             `json:"status"`
+	Errors   []string        `json:"errors"`
+	Messages []string        `json:"messages"`
+	Warnings []string        `json:"warnings"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// cpanelWHMResponse is the response of the WHM API 1 cpanel function, which wraps the response of
+// the UAPI function in Result. Metadata reports why WHM did not call the function.
+type cpanelWHMResponse struct {
+	Result   *CPanelAPIResponse `json:"result"`
+	Metadata struct {
+		Result int    `json:"result"`
+		Reason string `json:"reason"`
+	} `json:"metadata"`
+}
+
+// CPanelZoneEntry is an entry of a zone file as returned by DNS::parse_zone.
+// Type is "record", "comment" or "control"; names and record data are base64 encoded.
+type CPanelZoneEntry struct {
+	LineIndex  int      `json:"line_index"`
+	Type       string   `json:"type"`
+	RecordType string   `json:"record_type"`
+	DNameB64   string   `json:"dname_b64"`
+	TTL        int      `json:"ttl"`
+	DataB64    []string `json:"data_b64"`
 }
 
-// CPanelDNSRecord represents a DNS record in cPanel
+// CPanelDNSRecord represents a resource record of a cPanel zone
 type CPanelDNSRecord struct {
-	ID     string `json:"id"`
-	Type   string `json:"type"`
-	Name   string `json:"name"`
-	Record string `json:"record"`
-	Data   string `json:"data"`
-	TTL    int    `json:"ttl"`
-	Serial int    `json:"serial"`
-	Line   int    `json:"line"`
+	Line int      // Line index in the zone file, used to edit and remove the record
+	Name string   // Fully qualified name without trailing dot
+	Type string   // Record type, e.g. A
+	Data []string // Record data, e.g. the address of an A record
+	TTL  int
 }
 
-// NewCPanelProvider creates a new cPanel DNS provider
+// dnsRecord converts the record, joining its data with spaces
+func (r *CPanelDNSRecord) dnsRecord() *interfaces.DNSRecord {
+	return &interfaces.DNSRecord{
+		Name:     r.Name,
+		Type:     r.Type,
+		Value:    strings.Join(r.Data, " "),
+		TTL:      r.TTL,
+		Provider: "cpanel",
+		Metadata: map[string]string{
+			"line": strconv.Itoa(r.Line),
+		},
+	}
+}
+
+// cpanelZone is the parsed contents of a zone together with its serial number
+type cpanelZone struct {
+	serial  string
+	records []CPanelDNSRecord
+}
+
+// find returns the first record with the name and, if recordType is not empty, the type
+func (z *cpanelZone) find(name, recordType string) *CPanelDNSRecord {
+	name = strings.TrimSuffix(name, ".")
+	for i, record := range z.records {
+		if strings.EqualFold(record.Name, name) && (recordType == "" || record.Type == recordType) {
+			return &z.records[i]
+		}
+	}
+	return nil
+}
+
+// cpanelRecordEdit is a record added or edited with DNS::mass_edit_zone. LineIndex is only set for edits.
+type cpanelRecordEdit struct {
+	LineIndex  *int     `json:"line_index,omitempty"`
+	DName      string   `json:"dname"`
+	TTL        int      `json:"ttl"`
+	RecordType string   `json:"record_type"`
+	Data       []string `json:"data"`
+}
+
+// NewCPanelProvider creates a new cPanel DNS provider. It returns nil if the TLS certificate
+// files cannot be loaded, which Validate of the configuration reports first.
 func NewCPanelProvider(cfg *config.CPanelConfig, logger *zap.Logger) *CPanelProvider {
 	if cfg == nil {
 		panic("NewCPanelProvider: cfg must not be nil")
@@ -53,20 +126,36 @@ func NewCPanelProvider(cfg *config.CPanelConfig, logger *zap.Logger) *CPanelProv
 		panic("NewCPanelProvider: logger must not be nil")
 	}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:       10,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: true,
-		},
+	tlsConfig, err := cfg.TLS.ClientConfig()
+	if err != nil {
+		logger.Error("invalid cPanel TLS configuration", zap.Error(err))
+		return nil
 	}
 
-	return &CPanelProvider{
-		config: cfg,
-		client: client,
-		logger: logger,
+	provider := &CPanelProvider{
+		config:    cfg,
+		tlsConfig: tlsConfig,
+		logger:    logger,
 	}
+	provider.SetHTTPConfig(config.HTTPConfig{}, nil)
+	return provider
+}
+
+// SetHTTPConfig sets the timeout and retries of API requests. onRetry, if non-nil, is called
+// before each retry of a request.
+func (c *CPanelProvider) SetHTTPConfig(cfg config.HTTPConfig, onRetry func()) {
+	transport := &http.Transport{
+		MaxIdleConns:       10,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: true,
+		TLSClientConfig:    c.tlsConfig,
+	}
+	c.client = newRetryingHTTPClient("cpanel", cfg, transport, onRetry, c.logger)
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (c *CPanelProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(c.client.client, proxy)
 }
 
 // Name returns the provider name
@@ -83,19 +172,37 @@ func (c *CPanelProvider) UpdateRecord(ctx context.Context, record interfaces.DNS
 		zap.String("value", record.Value),
 	)
 
-	// First, try to find existing record
-	existingRecord, err := c.findRecord(ctx, record.Name, record.Type)
+	err := c.editZone(ctx, func(zone *cpanelZone) (url.Values, error) {
+		edit := cpanelRecordEdit{
+			DName:      strings.TrimSuffix(record.Name, ".") + ".",
+			TTL:        record.TTL,
+			RecordType: record.Type,
+			Data:       []string{record.Value},
+		}
+
+		param := "add"
+		if existing := zone.find(record.Name, record.Type); existing != nil {
+			// Update the existing record by its line
+			param = "edit"
+			edit.LineIndex = &existing.Line
+		}
+
+		data, err := json.Marshal(edit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s data: %w", param, err)
+		}
+		return url.Values{param: {string(data)}}, nil
+	})
 	if err != nil {
 		return errors.NewDNSProviderError("cpanel", record.Name, err)
 	}
 
-	if existingRecord != nil {
-		// Update existing record
-		return c.updateExistingRecord(ctx, existingRecord.Line, record)
-	}
+	c.logger.Info("DNS record updated successfully",
+		zap.String("provider", "cpanel"),
+		zap.String("record", record.Name),
+	)
 
-	// Create new record
-	return c.createNewRecord(ctx, record)
+	return nil
 }
 
 // GetRecord retrieves an existing DNS record
@@ -106,28 +213,36 @@ func (c *CPanelProvider) GetRecord(ctx context.Context, name string, rtype strin
 		zap.String("type", rtype),
 	)
 
-	records, err := c.listRecords(ctx)
+	zone, err := c.parseZone(ctx)
 	if err != nil {
 		return nil, errors.NewDNSProviderError("cpanel", name, err)
 	}
 
-	for _, record := range records {
-		if record.Name == name && record.Type == rtype {
-			return &interfaces.DNSRecord{
-				Name:     record.Name,
-				Type:     record.Type,
-				Value:    record.Data,
-				TTL:      record.TTL,
-				Provider: "cpanel",
-				Metadata: map[string]string{
-					"cpanel_id": record.ID,
-					"line":      fmt.Sprintf("%d", record.Line),
-				},
-			}, nil
-		}
+	record := zone.find(name, rtype)
+	if record == nil {
+		return nil, nil // Record not found
 	}
 
-	return nil, nil // Record not found
+	return record.dnsRecord(), nil
+}
+
+// ListRecords returns all records of the zone, see interfaces.RecordLister
+func (c *CPanelProvider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	c.logger.Debug("listing DNS records",
+		zap.String("provider", "cpanel"),
+		zap.String("zone", c.config.Zone),
+	)
+
+	zone, err := c.parseZone(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("cpanel", c.config.Zone, err)
+	}
+
+	records := make([]interfaces.DNSRecord, 0, len(zone.records))
+	for _, record := range zone.records {
+		records = append(records, *record.dnsRecord())
+	}
+	return records, nil
 }
 
 // DeleteRecord deletes a DNS record
@@ -138,24 +253,22 @@ func (c *CPanelProvider) DeleteRecord(ctx context.Context, name, recordType stri
 		zap.String("type", recordType),
 	)
 
-	record, err := c.findRecord(ctx, name, recordType)
+	err := c.editZone(ctx, func(zone *cpanelZone) (url.Values, error) {
+		record := zone.find(name, recordType)
+		if record == nil {
+			c.logger.Warn("record not found for deletion",
+				zap.String("provider", "cpanel"),
+				zap.String("record", name),
+				zap.String("type", recordType),
+			)
+			return nil, nil // Record doesn't exist, consider it deleted
+		}
+		return url.Values{"remove": {strconv.Itoa(record.Line)}}, nil
+	})
 	if err != nil {
 		return errors.NewDNSProviderError("cpanel", name, err)
 	}
 
-	if record == nil {
-		c.logger.Warn("record not found for deletion",
-			zap.String("provider", "cpanel"),
-			zap.String("record", name),
-			zap.String("type", recordType),
-		)
-		return nil // Record doesn't exist, consider it deleted
-	}
-
-	if err := c.deleteRecordByLine(ctx, record.Line); err != nil {
-		return errors.NewDNSProviderError("cpanel", name, err)
-	}
-
 	return nil
 }
 
@@ -163,8 +276,8 @@ func (c *CPanelProvider) DeleteRecord(ctx context.Context, name, recordType stri
 func (c *CPanelProvider) Validate(ctx context.Context) error {
 	c.logger.Debug("validating cPanel provider configuration")
 
-	// Test API access by listing records
-	_, err := c.listRecords(ctx)
+	// Test API access by reading the zone
+	_, err := c.parseZone(ctx)
 	if err != nil {
 		return fmt.Errorf("cPanel API validation failed: %w", err)
 	}
@@ -173,87 +286,119 @@ func (c *CPanelProvider) Validate(ctx context.Context) error {
 	return nil
 }
 
-// findRecord finds a record by name and type
-func (c *CPanelProvider) findRecord(ctx context.Context, name, recordType string) (*CPanelDNSRecord, error) {
-	records, err := c.listRecords(ctx)
-	if err != nil {
-		return nil, err
-	}
+// editZone reads the zone and applies the change that edit builds from it with DNS::mass_edit_zone.
+// A nil change leaves the zone as it is. If the zone was modified in between, cPanel rejects the
+// serial number and the change is built again from a fresh read, once.
+func (c *CPanelProvider) editZone(ctx context.Context, edit func(zone *cpanelZone) (url.Values, error)) error {
+	for attempt := 1; ; attempt++ {
+		zone, err := c.parseZone(ctx)
+		if err != nil {
+			return err
+		}
 
-	for _, record := range records {
-		if record.Name == name && (recordType == "" || record.Type == recordType) {
-			return &record, nil
+		params, err := edit(zone)
+		if err != nil || params == nil {
+			return err
+		}
+		params.Set("zone", c.config.Zone)
+		params.Set("serial", zone.serial)
+
+		err = c.call(ctx, http.MethodPost, "mass_edit_zone", params, nil)
+		if stderrors.Is(err, errCPanelSerialMismatch) && attempt == 1 {
+			c.logger.Debug("zone was modified concurrently, reading it again",
+				zap.String("provider", "cpanel"),
+				zap.String("zone", c.config.Zone),
+				zap.Error(err),
+			)
+			continue
 		}
+		return err
 	}
-
-	return nil, nil // Record not found
 }
 
-// listRecords lists all DNS records for the zone
-func (c *CPanelProvider) listRecords(ctx context.Context) ([]CPanelDNSRecord, error) {
-	apiURL := fmt.Sprintf("%s/execute/DnsLookup/get_dns_records", c.config.BaseURL)
-
-	params := url.Values{}
-	params.Set("domain", c.config.Zone)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// parseZone reads the records and the serial number of the zone with DNS::parse_zone
+func (c *CPanelProvider) parseZone(ctx context.Context) (*cpanelZone, error) {
+	var entries []CPanelZoneEntry
+	if err := c.call(ctx, http.MethodGet, "parse_zone", url.Values{"zone": {c.config.Zone}}, &entries); err != nil {
+		return nil, err
 	}
 
-	req.SetBasicAuth(c.config.Username, c.config.APIToken)
+	zone := &cpanelZone{}
+	for _, entry := range entries {
+		if entry.Type != "record" {
+			continue
+		}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Debug("failed to close response body", zap.Error(closeErr))
+		dname, err := base64.StdEncoding.DecodeString(entry.DNameB64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name of zone line %d: %w", entry.LineIndex, err)
+		}
+		data := make([]string, len(entry.DataB64))
+		for i, value := range entry.DataB64 {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid data of zone line %d: %w", entry.LineIndex, err)
+			}
+			data[i] = string(decoded)
 		}
-	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.NewHTTPError(resp.StatusCode, apiURL, fmt.Errorf("unexpected status code"))
-	}
+		// The serial number is the third field of the SOA record
+		if entry.RecordType == "SOA" && len(data) > 2 {
+			zone.serial = data[2]
+		}
 
-	var apiResp CPanelAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		zone.records = append(zone.records, CPanelDNSRecord{
+			Line: entry.LineIndex,
+			Name: cpanelAbsoluteName(string(dname), c.config.Zone),
+			Type: entry.RecordType,
+			Data: data,
+			TTL:  entry.TTL,
+		})
 	}
 
-	if apiResp.Result.Meta.Result != 1 {
-		return nil, fmt.Errorf("cPanel API error: result code %d", apiResp.Result.Meta.Result)
+	if zone.serial == "" {
+		return nil, fmt.Errorf("zone %s has no SOA record", c.config.Zone)
 	}
 
-	return apiResp.Result.Data, nil
+	return zone, nil
 }
 
-// updateExistingRecord updates an existing DNS record
-func (c *CPanelProvider) updateExistingRecord(ctx context.Context, line int, record interfaces.DNSRecord) error {
-	apiURL := fmt.Sprintf("%s/execute/DnsLookup/update_dns_record", c.config.BaseURL)
-
-	updateData := map[string]interface{}{
-		"domain": c.config.Zone,
-		"line":   line,
-		"type":   record.Type,
-		"name":   record.Name,
-		"data":   record.Value,
-		"ttl":    record.TTL,
-	}
-
-	jsonData, err := json.Marshal(updateData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal update data: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
+// call calls a function of the UAPI DNS module and decodes the data of its response into out, if not nil.
+// Parameters are sent in the query string for GET and as form for POST requests.
+func (c *CPanelProvider) call(ctx context.Context, method, function string, params url.Values, out interface{}) error {
+	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
+	apiURL := fmt.Sprintf("%s/execute/DNS/%s", baseURL, function)
+	// API tokens authenticate with the cpanel scheme rather than basic authentication
+	authorization := fmt.Sprintf("cpanel %s:%s", c.config.Username, c.config.APIToken)
+	if c.config.UseWHM {
+		// WHM calls the UAPI function as the cPanel account and passes the parameters on
+		apiURL = baseURL + "/json-api/cpanel"
+		authorization = fmt.Sprintf("whm %s:%s", c.config.WHMUser(), c.config.APIToken)
+		params = maps.Clone(params)
+		params.Set("api.version", "1")
+		params.Set("cpanel_jsonapi_user", c.config.Username)
+		params.Set("cpanel_jsonapi_apiversion", "3")
+		params.Set("cpanel_jsonapi_module", "DNS")
+		params.Set("cpanel_jsonapi_func", function)
+	}
+
+	var body io.Reader
+	requestURL := apiURL
+	if method == http.MethodGet {
+		requestURL += "?" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.SetBasicAuth(c.config.Username, c.config.APIToken)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -269,129 +414,68 @@ func (c *CPanelProvider) updateExistingRecord(ctx context.Context, line int, rec
 		return errors.NewHTTPError(resp.StatusCode, apiURL, fmt.Errorf("unexpected status code"))
 	}
 
-	var apiResp CPanelAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if apiResp.Result.Meta.Result != 1 {
-		return fmt.Errorf("cPanel API error: result code %d", apiResp.Result.Meta.Result)
-	}
-
-	c.logger.Info("DNS record updated successfully",
-		zap.String("provider", "cpanel"),
-		zap.String("record", record.Name),
-		zap.Int("line", line),
-	)
-
-	return nil
-}
-
-// createNewRecord creates a new DNS record
-func (c *CPanelProvider) createNewRecord(ctx context.Context, record interfaces.DNSRecord) error {
-	apiURL := fmt.Sprintf("%s/execute/DnsLookup/add_dns_record", c.config.BaseURL)
-
-	createData := map[string]interface{}{
-		"domain": c.config.Zone,
-		"type":   record.Type,
-		"name":   record.Name,
-		"data":   record.Value,
-		"ttl":    record.TTL,
-	}
-
-	jsonData, err := json.Marshal(createData)
+	apiResp, err := c.decodeResponse(resp.Body, function)
 	if err != nil {
-		return fmt.Errorf("failed to marshal create data: %w", err)
+		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if apiResp.Status != 1 {
+		return cpanelAPIError(function, apiResp)
 	}
 
-	req.SetBasicAuth(c.config.Username, c.config.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	if out == nil {
+		return nil
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Debug("failed to close response body", zap.Error(closeErr))
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return errors.NewHTTPError(resp.StatusCode, apiURL, fmt.Errorf("unexpected status code"))
+	if err := json.Unmarshal(apiResp.Data, out); err != nil {
+		return fmt.Errorf("failed to decode %s data: %w", function, err)
 	}
-
-	var apiResp CPanelAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if apiResp.Result.Meta.Result != 1 {
-		return fmt.Errorf("cPanel API error: result code %d", apiResp.Result.Meta.Result)
-	}
-
-	c.logger.Info("DNS record created successfully",
-		zap.String("provider", "cpanel"),
-		zap.String("record", record.Name),
-	)
-
 	return nil
 }
 
-// deleteRecordByLine deletes a DNS record by its line number
-func (c *CPanelProvider) deleteRecordByLine(ctx context.Context, line int) error {
-	apiURL := fmt.Sprintf("%s/execute/DnsLookup/delete_dns_record", c.config.BaseURL)
-
-	deleteData := map[string]interface{}{
-		"domain": c.config.Zone,
-		"line":   line,
-	}
-
-	jsonData, err := json.Marshal(deleteData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal delete data: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// decodeResponse decodes the UAPI response of function from body, unwrapping it from the WHM response with use_whm
+func (c *CPanelProvider) decodeResponse(body io.Reader, function string) (*CPanelAPIResponse, error) {
+	if !c.config.UseWHM {
+		var apiResp CPanelAPIResponse
+		if err := json.NewDecoder(body).Decode(&apiResp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return &apiResp, nil
 	}
 
-	req.SetBasicAuth(c.config.Username, c.config.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	var whmResp cpanelWHMResponse
+	if err := json.NewDecoder(body).Decode(&whmResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Debug("failed to close response body", zap.Error(closeErr))
+	if whmResp.Result == nil {
+		if whmResp.Metadata.Reason == "" {
+			return nil, fmt.Errorf("WHM API error: %s failed", function)
 		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return errors.NewHTTPError(resp.StatusCode, apiURL, fmt.Errorf("unexpected status code"))
+		return nil, fmt.Errorf("WHM API error: %s failed: %s", function, whmResp.Metadata.Reason)
 	}
+	return whmResp.Result, nil
+}
 
-	var apiResp CPanelAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+// cpanelAbsoluteName converts a name as stored in the zone file to a fully qualified name without
+// trailing dot. Names without trailing dot are relative to the zone.
+func cpanelAbsoluteName(dname, zone string) string {
+	if strings.HasSuffix(dname, ".") {
+		return strings.TrimSuffix(dname, ".")
 	}
+	return absoluteName(dname, zone)
+}
 
-	if apiResp.Result.Meta.Result != 1 {
-		return fmt.Errorf("cPanel API error: result code %d", apiResp.Result.Meta.Result)
+// cpanelAPIError returns the error for a response reporting a failed API call, including the
+// messages cPanel gave. A rejected serial number is reported as errCPanelSerialMismatch.
+func cpanelAPIError(function string, apiResp *CPanelAPIResponse) error {
+	if len(apiResp.Errors) == 0 {
+		return fmt.Errorf("cPanel API error: %s failed", function)
 	}
 
-	c.logger.Info("DNS record deleted successfully",
-		zap.String("provider", "cpanel"),
-		zap.Int("line", line),
-	)
-
-	return nil
+	err := fmt.Errorf("cPanel API error: %s failed: %s", function, strings.Join(apiResp.Errors, "; "))
+	for _, message := range apiResp.Errors {
+		if strings.Contains(message, "serial number") && strings.Contains(message, "does not match") {
+			return fmt.Errorf("%w: %w", errCPanelSerialMismatch, err)
+		}
+	}
+	return err
 }
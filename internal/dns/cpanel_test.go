@@ -2,14 +2,26 @@ package dns_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/devhat/ipfailover/internal/config"
 	"github.com/devhat/ipfailover/internal/dns"
 	"github.com/devhat/ipfailover/pkg/interfaces"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -41,7 +53,7 @@ func TestCPanelProvider_Validate(t *testing.T) {
 		// Create mock server
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
-			if _, err := w.Write([]byte(`{"result":{"data":[],"meta":{"result":1}}}`)); err != nil {
+			if _, err := w.Write([]byte(`{"data":[],"errors":null,"status":1}`)); err != nil {
 				t.Errorf("failed to write mock response: %v", err)
 			}
 		}))
@@ -181,4 +193,639 @@ func TestCPanelProvider_ConfigurationValidation(t *testing.T) {
 		err := cfg.Validate()
 		assert.NoError(t, err)
 	})
+
+	t.Run("WHM user without WHM", func(t *testing.T) {
+		cfg := &config.CPanelConfig{
+			BaseURL:     "https://whm.example.com:2087",
+			Username:    "testuser",
+			APIToken:    "test-token",
+			Zone:        "example.com",
+			WHMUsername: "reseller",
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "whm_username requires use_whm")
+
+		cfg.UseWHM = true
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, "reseller", cfg.WHMUser())
+
+		cfg.WHMUsername = ""
+		assert.Equal(t, "root", cfg.WHMUser())
+	})
+}
+
+// fakeCPanel serves captured UAPI DNS module responses from testdata/cpanel. parse_zone and
+// mass_edit_zone answer with their fixtures in order, repeating the last one, and the parameters
+// of mass_edit_zone calls are recorded.
+type fakeCPanel struct {
+	t     *testing.T
+	mu    sync.Mutex
+	zones []string
+	edits []string
+
+	parses int
+	calls  []url.Values
+}
+
+func (f *fakeCPanel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	assert.Equal(f.t, "cpanel testuser:test-token", r.Header.Get("Authorization"))
+
+	fixture := func(names []string, n int) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", "cpanel", names[min(n, len(names)-1)]))
+		require.NoError(f.t, err)
+		return data
+	}
+
+	switch r.URL.Path {
+	case "/execute/DNS/parse_zone":
+		assert.Equal(f.t, http.MethodGet, r.Method)
+		assert.Equal(f.t, "example.com", r.URL.Query().Get("zone"))
+		_, _ = w.Write(fixture(f.zones, f.parses))
+		f.parses++
+	case "/execute/DNS/mass_edit_zone":
+		assert.Equal(f.t, http.MethodPost, r.Method)
+		require.NoError(f.t, r.ParseForm())
+		_, _ = w.Write(fixture(f.edits, len(f.calls)))
+		f.calls = append(f.calls, r.PostForm)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newFakeCPanelProvider(t *testing.T, fake http.Handler) *dns.CPanelProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewCPanelProvider(&config.CPanelConfig{
+		BaseURL:  server.URL + "/",
+		Username: "testuser",
+		APIToken: "test-token",
+		Zone:     "example.com",
+	}, zap.NewNop())
+}
+
+func TestCPanelProvider_GetRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		record    string
+		rtype     string
+		want      *interfaces.DNSRecord
+		wantError string
+	}{
+		{
+			name:   "relative name",
+			zone:   "parse_zone.json",
+			record: "home.example.com",
+			rtype:  "A",
+			want: &interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300,
+				Provider: "cpanel", Metadata: map[string]string{"line": "6"}},
+		},
+		{
+			name:   "fully qualified name",
+			zone:   "parse_zone.json",
+			record: "home.example.com.",
+			rtype:  "AAAA",
+			want: &interfaces.DNSRecord{Name: "home.example.com", Type: "AAAA", Value: "2001:db8::10", TTL: 300,
+				Provider: "cpanel", Metadata: map[string]string{"line": "7"}},
+		},
+		{
+			name:   "zone apex",
+			zone:   "parse_zone.json",
+			record: "example.com",
+			rtype:  "A",
+			want: &interfaces.DNSRecord{Name: "example.com", Type: "A", Value: "192.0.2.1", TTL: 14400,
+				Provider: "cpanel", Metadata: map[string]string{"line": "5"}},
+		},
+		{
+			name:   "missing record",
+			zone:   "parse_zone.json",
+			record: "vpn.example.com",
+			rtype:  "A",
+		},
+		{
+			name:   "empty zone",
+			zone:   "parse_zone_empty.json",
+			record: "home.example.com",
+			rtype:  "A",
+		},
+		{
+			name:      "API error",
+			zone:      "zone_not_found.json",
+			record:    "home.example.com",
+			rtype:     "A",
+			wantError: "parse_zone failed: You do not have a DNS zone named",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeCPanelProvider(t, &fakeCPanel{t: t, zones: []string{tt.zone}})
+
+			record, err := provider.GetRecord(context.Background(), tt.record, tt.rtype)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, record)
+		})
+	}
+}
+
+func TestCPanelProvider_ListRecords(t *testing.T) {
+	provider := newFakeCPanelProvider(t, &fakeCPanel{t: t, zones: []string{"parse_zone.json"}})
+
+	records, err := provider.ListRecords(context.Background())
+	require.NoError(t, err)
+
+	var listed []string
+	for _, record := range records {
+		assert.Equal(t, "cpanel", record.Provider)
+		listed = append(listed, fmt.Sprintf("%s %s %d %s line %s", record.Name, record.Type, record.TTL, record.Value, record.Metadata["line"]))
+	}
+	assert.Equal(t, []string{
+		"example.com SOA 86400 ns1.example.net. hostmaster.example.com. 2023041101 3600 1800 1209600 86400 line 3",
+		"example.com NS 86400 ns1.example.net. line 4",
+		"example.com A 14400 192.0.2.1 line 5",
+		"home.example.com A 300 203.0.113.10 line 6",
+		"home.example.com AAAA 300 2001:db8::10 line 7",
+		"example.com TXT 14400 v=spf1 +a +mx ~all line 8",
+	}, listed)
+
+	t.Run("API error", func(t *testing.T) {
+		provider := newFakeCPanelProvider(t, &fakeCPanel{t: t, zones: []string{"zone_not_found.json"}})
+
+		_, err := provider.ListRecords(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse_zone failed")
+	})
+}
+
+func TestCPanelProvider_UpdateRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		edits     []string
+		record    interfaces.DNSRecord
+		wantCalls []url.Values
+		wantError string
+	}{
+		{
+			name:   "existing record is edited by line",
+			zone:   "parse_zone.json",
+			edits:  []string{"mass_edit_zone.json"},
+			record: interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 60},
+			wantCalls: []url.Values{{
+				"zone":   {"example.com"},
+				"serial": {"2023041101"},
+				"edit":   {`{"line_index":6,"dname":"home.example.com.","ttl":60,"record_type":"A","data":["198.51.100.77"]}`},
+			}},
+		},
+		{
+			name:   "missing record is added",
+			zone:   "parse_zone_empty.json",
+			edits:  []string{"mass_edit_zone.json"},
+			record: interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 300},
+			wantCalls: []url.Values{{
+				"zone":   {"example.com"},
+				"serial": {"2023041101"},
+				"add":    {`{"dname":"home.example.com.","ttl":300,"record_type":"A","data":["198.51.100.77"]}`},
+			}},
+		},
+		{
+			name:   "serial mismatch reads the zone again",
+			zone:   "parse_zone.json",
+			edits:  []string{"serial_mismatch.json", "mass_edit_zone.json"},
+			record: interfaces.DNSRecord{Name: "home.example.com", Type: "AAAA", Value: "2001:db8::20", TTL: 300},
+			wantCalls: []url.Values{
+				{
+					"zone":   {"example.com"},
+					"serial": {"2023041101"},
+					"edit":   {`{"line_index":7,"dname":"home.example.com.","ttl":300,"record_type":"AAAA","data":["2001:db8::20"]}`},
+				},
+				{
+					"zone":   {"example.com"},
+					"serial": {"2023041101"},
+					"edit":   {`{"line_index":7,"dname":"home.example.com.","ttl":300,"record_type":"AAAA","data":["2001:db8::20"]}`},
+				},
+			},
+		},
+		{
+			name:      "repeated serial mismatch is returned",
+			zone:      "parse_zone.json",
+			edits:     []string{"serial_mismatch.json"},
+			record:    interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 300},
+			wantError: "does not match the DNS zone’s serial number",
+		},
+		{
+			name:      "API error",
+			zone:      "zone_not_found.json",
+			record:    interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 300},
+			wantError: "You do not have a DNS zone named",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeCPanel{t: t, zones: []string{tt.zone}, edits: tt.edits}
+			provider := newFakeCPanelProvider(t, fake)
+
+			err := provider.UpdateRecord(context.Background(), tt.record)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+				assert.LessOrEqual(t, len(fake.calls), 2)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCalls, fake.calls)
+		})
+	}
+}
+
+func TestCPanelProvider_DeleteRecord(t *testing.T) {
+	t.Run("record is removed by line", func(t *testing.T) {
+		fake := &fakeCPanel{t: t, zones: []string{"parse_zone.json"}, edits: []string{"mass_edit_zone.json"}}
+		provider := newFakeCPanelProvider(t, fake)
+
+		require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "AAAA"))
+		assert.Equal(t, []url.Values{{"zone": {"example.com"}, "serial": {"2023041101"}, "remove": {"7"}}}, fake.calls)
+	})
+
+	t.Run("missing record is not an error", func(t *testing.T) {
+		fake := &fakeCPanel{t: t, zones: []string{"parse_zone_empty.json"}}
+		provider := newFakeCPanelProvider(t, fake)
+
+		require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+		assert.Empty(t, fake.calls)
+	})
+}
+
+// fakeWHM serves WHM API 1 cpanel calls for testuser by passing the UAPI function call on to
+// cpanel and wrapping its response, or fails them with reason if set
+type fakeWHM struct {
+	t      *testing.T
+	cpanel http.Handler
+	reason string
+}
+
+func (f *fakeWHM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(f.t, "/json-api/cpanel", r.URL.Path)
+	assert.Equal(f.t, "whm reseller:test-token", r.Header.Get("Authorization"))
+	if f.reason != "" {
+		_, _ = w.Write([]byte(`{"metadata":{"version":1,"result":0,"command":"cpanel","reason":"` + f.reason + `"}}`))
+		return
+	}
+
+	require.NoError(f.t, r.ParseForm())
+	params := r.Form
+	assert.Equal(f.t, "1", params.Get("api.version"))
+	assert.Equal(f.t, "testuser", params.Get("cpanel_jsonapi_user"))
+	assert.Equal(f.t, "3", params.Get("cpanel_jsonapi_apiversion"))
+	assert.Equal(f.t, "DNS", params.Get("cpanel_jsonapi_module"))
+	function := params.Get("cpanel_jsonapi_func")
+	for _, key := range []string{"api.version", "cpanel_jsonapi_user", "cpanel_jsonapi_apiversion", "cpanel_jsonapi_module", "cpanel_jsonapi_func"} {
+		params.Del(key)
+	}
+
+	// Call the UAPI function as the cPanel account
+	var body io.Reader
+	target := "/execute/DNS/" + function
+	if r.Method == http.MethodGet {
+		target += "?" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+	uapiReq := httptest.NewRequest(r.Method, target, body)
+	uapiReq.Header.Set("Authorization", "cpanel testuser:test-token")
+	if body != nil {
+		uapiReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	recorder := httptest.NewRecorder()
+	f.cpanel.ServeHTTP(recorder, uapiReq)
+
+	_, _ = w.Write([]byte(`{"module":"DNS","func":"` + function + `","apiversion":3,"result":` + recorder.Body.String() + `}`))
+}
+
+func TestCPanelProvider_WHM(t *testing.T) {
+	newProvider := func(t *testing.T, fake *fakeWHM) *dns.CPanelProvider {
+		server := httptest.NewServer(fake)
+		t.Cleanup(server.Close)
+
+		return dns.NewCPanelProvider(&config.CPanelConfig{
+			BaseURL:     server.URL,
+			Username:    "testuser",
+			APIToken:    "test-token",
+			Zone:        "example.com",
+			UseWHM:      true,
+			WHMUsername: "reseller",
+		}, zap.NewNop())
+	}
+
+	t.Run("record is read and edited on behalf of the account", func(t *testing.T) {
+		cpanel := &fakeCPanel{t: t, zones: []string{"parse_zone.json"}, edits: []string{"mass_edit_zone.json"}}
+		provider := newProvider(t, &fakeWHM{t: t, cpanel: cpanel})
+
+		record, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.10", record.Value)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 60,
+		}))
+		assert.Equal(t, []url.Values{{
+			"zone":   {"example.com"},
+			"serial": {"2023041101"},
+			"edit":   {`{"line_index":6,"dname":"home.example.com.","ttl":60,"record_type":"A","data":["198.51.100.77"]}`},
+		}}, cpanel.calls)
+	})
+
+	t.Run("UAPI error", func(t *testing.T) {
+		cpanel := &fakeCPanel{t: t, zones: []string{"zone_not_found.json"}}
+		provider := newProvider(t, &fakeWHM{t: t, cpanel: cpanel})
+
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse_zone failed: You do not have a DNS zone named")
+	})
+
+	t.Run("WHM error", func(t *testing.T) {
+		provider := newProvider(t, &fakeWHM{t: t, reason: "The user testuser is not owned by reseller."})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WHM API error: parse_zone failed: The user testuser is not owned by reseller.")
+	})
+}
+
+// flakyHandler fails the first requests to path with the given responses, or by not answering
+// within the client timeout for status 0, and passes all other requests to next
+type flakyHandler struct {
+	next     http.Handler
+	path     string
+	mu       sync.Mutex
+	failures []flakyResponse
+	requests int
+}
+
+type flakyResponse struct {
+	status     int
+	retryAfter string
+	delay      time.Duration
+}
+
+func (f *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	var failure *flakyResponse
+	if r.URL.Path == f.path {
+		if f.requests < len(f.failures) {
+			failure = &f.failures[f.requests]
+		}
+		f.requests++
+	}
+	f.mu.Unlock()
+
+	if failure == nil {
+		f.next.ServeHTTP(w, r)
+		return
+	}
+	if failure.delay > 0 {
+		select {
+		case <-time.After(failure.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if failure.retryAfter != "" {
+		w.Header().Set("Retry-After", failure.retryAfter)
+	}
+	w.WriteHeader(failure.status)
+}
+
+func TestCPanelProvider_HTTPRetries(t *testing.T) {
+	record := interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 60}
+	wantCall := url.Values{
+		"zone":   {"example.com"},
+		"serial": {"2023041101"},
+		"edit":   {`{"line_index":6,"dname":"home.example.com.","ttl":60,"record_type":"A","data":["198.51.100.77"]}`},
+	}
+	httpConfig := config.HTTPConfig{
+		Timeout:        time.Second,
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}
+
+	newProvider := func(t *testing.T, flaky *flakyHandler, cfg config.HTTPConfig) (*dns.CPanelProvider, *fakeCPanel, *int) {
+		fake := &fakeCPanel{t: t, zones: []string{"parse_zone.json"}, edits: []string{"mass_edit_zone.json"}}
+		flaky.next = fake
+		provider := newFakeCPanelProvider(t, flaky)
+
+		retries := new(int)
+		provider.SetHTTPConfig(cfg, func() { *retries++ })
+		return provider, fake, retries
+	}
+
+	t.Run("retryable statuses are retried", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/mass_edit_zone", failures: []flakyResponse{
+			{status: http.StatusServiceUnavailable},
+			{status: http.StatusTooManyRequests, retryAfter: "0"},
+		}}
+		provider, fake, retries := newProvider(t, flaky, httpConfig)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, 3, flaky.requests)
+		assert.Equal(t, 2, *retries)
+		assert.Equal(t, []url.Values{wantCall}, fake.calls, "the form is sent again with each retry")
+	})
+
+	t.Run("timed out requests are retried", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusOK, delay: time.Second},
+		}}
+		cfg := httpConfig
+		cfg.Timeout = 50 * time.Millisecond
+		provider, _, retries := newProvider(t, flaky, cfg)
+
+		require.NoError(t, provider.Validate(context.Background()))
+		assert.Equal(t, 1, *retries)
+	})
+
+	t.Run("retries are used up", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusBadGateway}, {status: http.StatusBadGateway}, {status: http.StatusBadGateway},
+		}}
+		provider, _, retries := newProvider(t, flaky, httpConfig)
+
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 502")
+		assert.Equal(t, 3, flaky.requests)
+		assert.Equal(t, 2, *retries)
+	})
+
+	t.Run("no retries by default", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusServiceUnavailable},
+		}}
+		provider, _, retries := newProvider(t, flaky, config.HTTPConfig{})
+
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Equal(t, 1, flaky.requests)
+		assert.Zero(t, *retries)
+	})
+
+	t.Run("other statuses are not retried", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusForbidden},
+		}}
+		provider, _, retries := newProvider(t, flaky, httpConfig)
+
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 403")
+		assert.Zero(t, *retries)
+	})
+
+	t.Run("Retry-After is honored", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusTooManyRequests, retryAfter: "1"},
+		}}
+		cfg := httpConfig
+		cfg.MaxBackoff = 5 * time.Second
+		provider, _, _ := newProvider(t, flaky, cfg)
+
+		start := time.Now()
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Retry-After is capped by max_backoff", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusServiceUnavailable, retryAfter: "3600"},
+		}}
+		provider, _, _ := newProvider(t, flaky, httpConfig)
+
+		start := time.Now()
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("cancelled context stops retrying", func(t *testing.T) {
+		flaky := &flakyHandler{path: "/execute/DNS/parse_zone", failures: []flakyResponse{
+			{status: http.StatusServiceUnavailable, retryAfter: "3600"},
+		}}
+		cfg := httpConfig
+		cfg.MaxBackoff = time.Hour
+		provider, _, _ := newProvider(t, flaky, cfg)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := provider.GetRecord(ctx, "home.example.com", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 503")
+		assert.Equal(t, 1, flaky.requests)
+	})
+}
+
+func TestCPanelProvider_TLS(t *testing.T) {
+	newTLSServer := func(t *testing.T, clientAuth tls.ClientAuthType) *httptest.Server {
+		server := httptest.NewUnstartedServer(&fakeCPanel{t: t, zones: []string{"parse_zone.json"}})
+		server.TLS = &tls.Config{ClientAuth: clientAuth}
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server
+	}
+	newProvider := func(server *httptest.Server, tlsConfig *config.TLSConfig) *dns.CPanelProvider {
+		cfg := &config.CPanelConfig{
+			BaseURL:  server.URL,
+			Username: "testuser",
+			APIToken: "test-token",
+			Zone:     "example.com",
+			TLS:      tlsConfig,
+		}
+		require.NoError(t, cfg.Validate())
+		provider := dns.NewCPanelProvider(cfg, zap.NewNop())
+		require.NotNil(t, provider)
+		return provider
+	}
+
+	t.Run("untrusted certificate is rejected", func(t *testing.T) {
+		server := newTLSServer(t, tls.NoClientCert)
+
+		err := newProvider(server, nil).Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate")
+	})
+
+	t.Run("CA file", func(t *testing.T) {
+		server := newTLSServer(t, tls.NoClientCert)
+		certFile, _ := writeTLSServerFiles(t, server)
+
+		provider := newProvider(server, &config.TLSConfig{CACertFile: certFile})
+		require.NoError(t, provider.Validate(context.Background()))
+	})
+
+	t.Run("insecure skip verify", func(t *testing.T) {
+		server := newTLSServer(t, tls.NoClientCert)
+
+		provider := newProvider(server, &config.TLSConfig{InsecureSkipVerify: true})
+		require.NoError(t, provider.Validate(context.Background()))
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		server := newTLSServer(t, tls.RequireAnyClientCert)
+		certFile, keyFile := writeTLSServerFiles(t, server)
+
+		err := newProvider(server, &config.TLSConfig{CACertFile: certFile}).Validate(context.Background())
+		require.Error(t, err, "the server requires a client certificate")
+
+		provider := newProvider(server, &config.TLSConfig{CACertFile: certFile, ClientCertFile: certFile, ClientKeyFile: keyFile})
+		require.NoError(t, provider.Validate(context.Background()))
+	})
+
+	t.Run("unreadable files", func(t *testing.T) {
+		cfg := &config.CPanelConfig{
+			BaseURL:  "https://cpanel.example.com:2083",
+			Username: "testuser",
+			APIToken: "test-token",
+			Zone:     "example.com",
+			TLS:      &config.TLSConfig{CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tls: failed to read ca_cert_file")
+		assert.Nil(t, dns.NewCPanelProvider(cfg, zap.NewNop()))
+	})
+}
+
+// writeTLSServerFiles writes the certificate of a TLS test server, and its private key, as PEM files.
+// The certificate is self-signed, so it serves as CA file and, for servers that do not verify
+// client certificates, as client certificate.
+func writeTLSServerFiles(t *testing.T, server *httptest.Server) (certFile, keyFile string) {
+	dir := t.TempDir()
+	cert := server.TLS.Certificates[0]
+
+	certFile = filepath.Join(dir, "cert.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600))
+
+	return certFile, keyFile
 }
@@ -0,0 +1,396 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const domeneshopDefaultEndpoint = "https://api.domeneshop.no/v0"
+
+// DomeneshopProvider implements DNSProvider for the Domeneshop API v0.
+// Records are addressed through the numeric ID of their domain, which is looked up by name once and cached.
+type DomeneshopProvider struct {
+	config   *config.DomeneshopConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+
+	domainMu sync.RWMutex
+	domainID int
+}
+
+// DomeneshopDomain represents a domain in the Domeneshop API
+type DomeneshopDomain struct {
+	ID     int    `json:"id"`
+	Domain string `json:"domain"`
+}
+
+// DomeneshopRecord represents a DNS record in the Domeneshop API
+type DomeneshopRecord struct {
+	ID   int    `json:"id,omitempty"`
+	Host string `json:"host"`
+	TTL  int    `json:"ttl,omitempty"`
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// domeneshopErrorResponse represents an error returned by the Domeneshop API
+type domeneshopErrorResponse struct {
+	Code string `json:"code"`
+	Help string `json:"help"`
+}
+
+// NewDomeneshopProvider creates a new Domeneshop DNS provider
+func NewDomeneshopProvider(cfg *config.DomeneshopConfig, logger *zap.Logger) *DomeneshopProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("domeneshop config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = domeneshopDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &DomeneshopProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (d *DomeneshopProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(d.client, proxy)
+}
+
+// Name returns the provider name
+func (d *DomeneshopProvider) Name() string {
+	return "domeneshop"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (d *DomeneshopProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	d.logger.Info("updating DNS record",
+		zap.String("provider", "domeneshop"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("domeneshop", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(record.Name, d.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("domeneshop", record.Name, err)
+	}
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("domeneshop", record.Name, err)
+	}
+
+	existing, err := d.findRecord(ctx, domainID, host, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("domeneshop", record.Name, err)
+	}
+
+	body := DomeneshopRecord{
+		Host: host,
+		TTL:  record.TTL,
+		Type: record.Type,
+		Data: record.Value,
+	}
+
+	if existing != nil {
+		path := d.recordsPath(domainID) + "/" + strconv.Itoa(existing.ID)
+		if err := d.doRequest(ctx, http.MethodPut, path, body, nil); err != nil {
+			return errors.NewDNSProviderError("domeneshop", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		d.logger.Info("DNS record updated successfully",
+			zap.String("provider", "domeneshop"),
+			zap.String("record", record.Name),
+			zap.Int("record_id", existing.ID),
+		)
+		return nil
+	}
+
+	var created DomeneshopRecord
+	if err := d.doRequest(ctx, http.MethodPost, d.recordsPath(domainID), body, &created); err != nil {
+		return errors.NewDNSProviderError("domeneshop", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	d.logger.Info("DNS record created successfully",
+		zap.String("provider", "domeneshop"),
+		zap.String("record", record.Name),
+		zap.Int("record_id", created.ID),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (d *DomeneshopProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	d.logger.Debug("getting DNS record",
+		zap.String("provider", "domeneshop"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("domeneshop", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(name, d.config.Domain)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("domeneshop", name, err)
+	}
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("domeneshop", name, err)
+	}
+
+	found, err := d.findRecord(ctx, domainID, host, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("domeneshop", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Host, d.config.Domain),
+		Type:     found.Type,
+		Value:    found.Data,
+		TTL:      found.TTL,
+		Provider: "domeneshop",
+		Metadata: map[string]string{
+			"domeneshop_id": strconv.Itoa(found.ID),
+			"host":          found.Host,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (d *DomeneshopProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	d.logger.Info("deleting DNS record",
+		zap.String("provider", "domeneshop"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("domeneshop", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(name, d.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("domeneshop", name, err)
+	}
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("domeneshop", name, err)
+	}
+
+	existing, err := d.findRecord(ctx, domainID, host, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("domeneshop", name, err)
+	}
+
+	if existing != nil {
+		path := d.recordsPath(domainID) + "/" + strconv.Itoa(existing.ID)
+		err = d.doRequest(ctx, http.MethodDelete, path, nil, nil)
+		if err != nil && !isHTTPNotFound(err) {
+			return errors.NewDNSProviderError("domeneshop", name, fmt.Errorf("failed to delete record: %w", err))
+		}
+	}
+
+	if existing == nil || err != nil {
+		d.logger.Warn("record not found for deletion",
+			zap.String("provider", "domeneshop"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	d.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "domeneshop"),
+		zap.String("record", name),
+		zap.Int("record_id", existing.ID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (d *DomeneshopProvider) Validate(ctx context.Context) error {
+	d.logger.Debug("validating domeneshop provider configuration")
+
+	// Test the credentials and access to the domain by resolving its ID
+	if _, err := d.getDomainID(ctx); err != nil {
+		return errors.NewDNSProviderError("domeneshop", "validation", err)
+	}
+
+	d.logger.Info("domeneshop provider validation successful")
+	return nil
+}
+
+// getDomainID returns the ID of the configured domain, looking it up on first use
+func (d *DomeneshopProvider) getDomainID(ctx context.Context) (int, error) {
+	// Take read lock to check cached domain ID
+	d.domainMu.RLock()
+	if d.domainID != 0 {
+		domainID := d.domainID
+		d.domainMu.RUnlock()
+		return domainID, nil
+	}
+	d.domainMu.RUnlock()
+
+	// Domain ID not cached, acquire write lock
+	d.domainMu.Lock()
+	defer d.domainMu.Unlock()
+
+	// Re-check in case another goroutine looked it up meanwhile
+	if d.domainID != 0 {
+		return d.domainID, nil
+	}
+
+	var domains []DomeneshopDomain
+	path := "/domains?domain=" + url.QueryEscape(d.config.Domain)
+	if err := d.doRequest(ctx, http.MethodGet, path, nil, &domains); err != nil {
+		return 0, fmt.Errorf("failed to look up domain %s: %w", d.config.Domain, err)
+	}
+
+	// The filter also matches subdomains of other domains in the account, so look for an exact match
+	for _, domain := range domains {
+		if strings.EqualFold(strings.TrimSuffix(domain.Domain, "."), strings.TrimSuffix(d.config.Domain, ".")) {
+			d.domainID = domain.ID
+			d.logger.Debug("resolved domeneshop domain ID",
+				zap.String("domain", d.config.Domain),
+				zap.Int("domain_id", domain.ID),
+			)
+			return d.domainID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("domain %s not found in the account", d.config.Domain)
+}
+
+// recordsPath returns the API path of the domain's DNS records collection
+func (d *DomeneshopProvider) recordsPath(domainID int) string {
+	return "/domains/" + strconv.Itoa(domainID) + "/dns"
+}
+
+// findRecord finds a record by zone-relative host and type
+func (d *DomeneshopProvider) findRecord(ctx context.Context, domainID int, host, recordType string) (*DomeneshopRecord, error) {
+	query := url.Values{}
+	query.Set("host", host)
+	query.Set("type", recordType)
+
+	var records []DomeneshopRecord
+	err := d.doRequest(ctx, http.MethodGet, d.recordsPath(domainID)+"?"+query.Encode(), nil, &records)
+	if isHTTPNotFound(err) {
+		// The API answers 404 instead of an empty list when no record matches the filter
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	// Filter again in case the API ignored the filter parameters
+	for _, record := range records {
+		if strings.EqualFold(record.Host, host) && record.Type == recordType {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// isHTTPNotFound reports whether err is an HTTP 404 response
+func isHTTPNotFound(err error) bool {
+	var httpErr *errors.HTTPError
+	return stderrors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the response into out if non-nil
+func (d *DomeneshopProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := d.endpoint + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(d.config.Token, d.config.Secret)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			d.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr domeneshopErrorResponse
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&apiErr); decodeErr == nil && (apiErr.Code != "" || apiErr.Help != "") {
+			return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", strings.TrimSpace(apiErr.Code+" "+apiErr.Help)))
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
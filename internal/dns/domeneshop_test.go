@@ -0,0 +1,299 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDomeneshop is a minimal in-memory Domeneshop API v0. Like the real API, it answers
+// 404 instead of an empty list when no DNS record matches the filter.
+type fakeDomeneshop struct {
+	t             *testing.T
+	mu            sync.Mutex
+	domains       []dns.DomeneshopDomain
+	records       map[int][]dns.DomeneshopRecord // Keyed by domain ID
+	nextID        int
+	domainLookups int
+	requests      []string
+}
+
+func newFakeDomeneshop(t *testing.T, records ...dns.DomeneshopRecord) *fakeDomeneshop {
+	return &fakeDomeneshop{
+		t: t,
+		domains: []dns.DomeneshopDomain{
+			{ID: 7, Domain: "shop.example.no"},
+			{ID: 42, Domain: "example.no"},
+		},
+		records: map[int][]dns.DomeneshopRecord{42: records},
+		nextID:  1000,
+	}
+}
+
+func (f *fakeDomeneshop) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	token, secret, ok := r.BasicAuth()
+	if !ok || token != "api-token" || secret != "api-secret" {
+		writeDomeneshopError(w, http.StatusUnauthorized, "unauthorized", "Invalid credentials")
+		return
+	}
+
+	if r.URL.Path == "/v0/domains" {
+		f.domainLookups++
+		filter := r.URL.Query().Get("domain")
+		matching := []dns.DomeneshopDomain{}
+		for _, domain := range f.domains {
+			if strings.Contains(domain.Domain, filter) {
+				matching = append(matching, domain)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(matching)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v0/domains/"), "/")
+	if len(parts) < 2 || parts[1] != "dns" {
+		writeDomeneshopError(w, http.StatusNotFound, "notFound", "Not found")
+		return
+	}
+	domainID, err := strconv.Atoi(parts[0])
+	require.NoError(f.t, err)
+	records, ok := f.records[domainID]
+	if !ok {
+		writeDomeneshopError(w, http.StatusNotFound, "domain:notFound", "Domain not found")
+		return
+	}
+
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			host, recordType := r.URL.Query().Get("host"), r.URL.Query().Get("type")
+			var matching []dns.DomeneshopRecord
+			for _, record := range records {
+				if (host == "" || record.Host == host) && (recordType == "" || record.Type == recordType) {
+					matching = append(matching, record)
+				}
+			}
+			if len(matching) == 0 {
+				writeDomeneshopError(w, http.StatusNotFound, "record:notFound", "No records found")
+				return
+			}
+			_ = json.NewEncoder(w).Encode(matching)
+		case http.MethodPost:
+			var record dns.DomeneshopRecord
+			require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+			f.nextID++
+			record.ID = f.nextID
+			f.records[domainID] = append(records, record)
+			w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(record.ID))
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]int{"id": record.ID})
+		}
+		return
+	}
+
+	recordID, err := strconv.Atoi(parts[2])
+	require.NoError(f.t, err)
+	for i, record := range records {
+		if record.ID != recordID {
+			continue
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			var updated dns.DomeneshopRecord
+			require.NoError(f.t, json.NewDecoder(r.Body).Decode(&updated))
+			updated.ID = recordID
+			records[i] = updated
+		case http.MethodDelete:
+			f.records[domainID] = append(records[:i], records[i+1:]...)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeDomeneshopError(w, http.StatusNotFound, "record:notFound", "Record not found")
+}
+
+func writeDomeneshopError(w http.ResponseWriter, status int, code, help string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": code, "help": help})
+}
+
+func newDomeneshopTestProvider(t *testing.T, fake *fakeDomeneshop, domain, secret string) *dns.DomeneshopProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewDomeneshopProvider(&config.DomeneshopConfig{
+		Token:    "api-token",
+		Secret:   secret,
+		Domain:   domain,
+		Endpoint: server.URL + "/v0",
+	}, zap.NewNop())
+}
+
+func TestDomeneshopProvider_Name(t *testing.T) {
+	provider := dns.NewDomeneshopProvider(&config.DomeneshopConfig{
+		Token:  "api-token",
+		Secret: "api-secret",
+		Domain: "example.no",
+	}, zap.NewNop())
+	assert.Equal(t, "domeneshop", provider.Name())
+
+	assert.Nil(t, dns.NewDomeneshopProvider(nil, zap.NewNop()))
+}
+
+func TestDomeneshopProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.no",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "domeneshop",
+	}
+
+	t.Run("creates record", func(t *testing.T) {
+		// No record matches, so the list request answers 404
+		fake := newFakeDomeneshop(t, dns.DomeneshopRecord{ID: 1, Host: "www", TTL: 3600, Type: "A", Data: "192.0.2.80"})
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, []dns.DomeneshopRecord{
+			{ID: 1, Host: "www", TTL: 3600, Type: "A", Data: "192.0.2.80"},
+			{ID: 1001, Host: "home", TTL: 300, Type: "A", Data: "203.0.113.10"},
+		}, fake.records[42])
+		assert.Contains(t, fake.requests, "POST /v0/domains/42/dns")
+	})
+
+	t.Run("updates existing record", func(t *testing.T) {
+		fake := newFakeDomeneshop(t,
+			dns.DomeneshopRecord{ID: 5, Host: "home", TTL: 60, Type: "A", Data: "192.0.2.1"},
+			dns.DomeneshopRecord{ID: 6, Host: "home", TTL: 60, Type: "AAAA", Data: "2001:db8::1"},
+		)
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, []dns.DomeneshopRecord{
+			{ID: 5, Host: "home", TTL: 300, Type: "A", Data: "203.0.113.10"},
+			{ID: 6, Host: "home", TTL: 60, Type: "AAAA", Data: "2001:db8::1"},
+		}, fake.records[42])
+		assert.Contains(t, fake.requests, "PUT /v0/domains/42/dns/5")
+	})
+
+	t.Run("zone apex", func(t *testing.T) {
+		fake := newFakeDomeneshop(t)
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+
+		apex := record
+		apex.Name = "example.no"
+		require.NoError(t, provider.UpdateRecord(context.Background(), apex))
+		require.Len(t, fake.records[42], 1)
+		assert.Equal(t, "@", fake.records[42][0].Host)
+	})
+
+	t.Run("record outside domain", func(t *testing.T) {
+		fake := newFakeDomeneshop(t)
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+
+		outside := record
+		outside.Name = "home.example.com"
+		err := provider.UpdateRecord(context.Background(), outside)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not within zone example.no")
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestDomeneshopProvider_DomainID(t *testing.T) {
+	t.Run("resolved once and cached", func(t *testing.T) {
+		fake := newFakeDomeneshop(t, dns.DomeneshopRecord{ID: 5, Host: "home", TTL: 300, Type: "A", Data: "192.0.2.1"})
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := provider.GetRecord(context.Background(), "home.example.no", "A")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, fake.domainLookups)
+	})
+
+	t.Run("filter matches other domains", func(t *testing.T) {
+		// The domain filter also returns shop.example.no, which must not be used
+		fake := newFakeDomeneshop(t, dns.DomeneshopRecord{ID: 5, Host: "home", TTL: 300, Type: "A", Data: "192.0.2.1"})
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+
+		found, err := provider.GetRecord(context.Background(), "home.example.no", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Contains(t, fake.requests, "GET /v0/domains/42/dns")
+	})
+
+	t.Run("domain not in account", func(t *testing.T) {
+		fake := newFakeDomeneshop(t)
+		provider := newDomeneshopTestProvider(t, fake, "example.se", "api-secret")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "domain example.se not found in the account")
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		fake := newFakeDomeneshop(t)
+		provider := newDomeneshopTestProvider(t, fake, "example.no", "wrong-secret")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid credentials")
+		assert.NotContains(t, err.Error(), "wrong-secret")
+	})
+}
+
+func TestDomeneshopProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := newFakeDomeneshop(t,
+		dns.DomeneshopRecord{ID: 5, Host: "home", TTL: 120, Type: "A", Data: "192.0.2.1"},
+		dns.DomeneshopRecord{ID: 6, Host: "home", TTL: 120, Type: "AAAA", Data: "2001:db8::1"},
+	)
+	provider := newDomeneshopTestProvider(t, fake, "example.no", "api-secret")
+	ctx := context.Background()
+
+	found, err := provider.GetRecord(ctx, "home.example.no", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "home.example.no", found.Name)
+	assert.Equal(t, "192.0.2.1", found.Value)
+	assert.Equal(t, 120, found.TTL)
+	assert.Equal(t, "5", found.Metadata["domeneshop_id"])
+
+	// A 404 for the record list means there is no such record
+	missing, err := provider.GetRecord(ctx, "other.example.no", "A")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.no", "A"))
+	assert.Equal(t, []dns.DomeneshopRecord{{ID: 6, Host: "home", TTL: 120, Type: "AAAA", Data: "2001:db8::1"}}, fake.records[42])
+	assert.Contains(t, fake.requests, "DELETE /v0/domains/42/dns/5")
+
+	// Deleting a record that does not exist is not an error
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.no", "A"))
+}
@@ -0,0 +1,64 @@
+package dns
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// errRecordExists is wrapped by provider errors reporting that a record being created already exists
+var errRecordExists = stderrors.New("record already exists")
+
+// resolveDuplicateCreate handles a create that failed because the record already exists, which happens
+// when a concurrent cycle, or a retry after a lost response, created it first. The record is read back:
+// if it already has the desired value and TTL the create counts as successful, otherwise update is
+// called with it. A nil update reports the different value as a conflict instead of overwriting it.
+// If the record cannot be read back, createErr is returned.
+func resolveDuplicateCreate(
+	ctx context.Context,
+	logger *zap.Logger,
+	provider string,
+	record interfaces.DNSRecord,
+	createErr error,
+	get func(ctx context.Context) (*interfaces.DNSRecord, error),
+	update func(ctx context.Context, existing *interfaces.DNSRecord) error,
+) error {
+	logger.Info("DNS record already exists, reading it back",
+		zap.String("provider", provider),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+	)
+
+	existing, err := get(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; failed to read back existing record: %w", createErr, err)
+	}
+	if existing == nil {
+		return createErr
+	}
+
+	if recordMatches(existing, &record) {
+		logger.Info("DNS record already exists with the desired value",
+			zap.String("provider", provider),
+			zap.String("record", record.Name),
+			zap.String("value", record.Value),
+		)
+		return nil
+	}
+
+	if update == nil {
+		return errors.NewConflictError(provider, record.Name, "", existing.Value)
+	}
+
+	logger.Info("DNS record already exists with a different value, updating it",
+		zap.String("provider", provider),
+		zap.String("record", record.Name),
+		zap.String("existing_value", existing.Value),
+		zap.String("value", record.Value),
+	)
+	return update(ctx, existing)
+}
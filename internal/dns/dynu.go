@@ -0,0 +1,461 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const dynuDefaultEndpoint = "https://api.dynu.com/v2"
+
+// DynuProvider implements DNSProvider for the Dynu API v2.
+// Records are addressed through the numeric ID of their domain, which is looked up by name once and cached.
+type DynuProvider struct {
+	config   *config.DynuConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+
+	domainMu sync.RWMutex
+	domainID int
+}
+
+// DynuDomain represents a DNS domain in the Dynu API
+type DynuDomain struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// DynuRecord represents a DNS record in the Dynu API. The record value is held in a
+// field named after its type rather than in a common field, see value and setValue.
+type DynuRecord struct {
+	ID          int    `json:"id,omitempty"`
+	NodeName    string `json:"nodeName"`
+	RecordType  string `json:"recordType"`
+	TTL         int    `json:"ttl"`
+	State       bool   `json:"state"`
+	IPv4Address string `json:"ipv4Address,omitempty"`
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	Host        string `json:"host,omitempty"`
+	TextData    string `json:"textData,omitempty"`
+}
+
+// dynuStatus is embedded in every Dynu API response. Errors carry a type and a message.
+type dynuStatus struct {
+	StatusCode int    `json:"statusCode"`
+	Type       string `json:"type,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// NewDynuProvider creates a new Dynu DNS provider
+func NewDynuProvider(cfg *config.DynuConfig, logger *zap.Logger) *DynuProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("dynu config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = dynuDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &DynuProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (d *DynuProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(d.client, proxy)
+}
+
+// Name returns the provider name
+func (d *DynuProvider) Name() string {
+	return "dynu"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (d *DynuProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	d.logger.Info("updating DNS record",
+		zap.String("provider", "dynu"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("dynu", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	nodeName, err := relativeNameWithApex(record.Name, d.config.Domain, apexEmpty)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", record.Name, err)
+	}
+
+	body := DynuRecord{
+		NodeName:   nodeName,
+		RecordType: record.Type,
+		TTL:        record.TTL,
+		State:      true,
+	}
+	if err := body.setValue(record.Value); err != nil {
+		return errors.NewDNSProviderError("dynu", record.Name, err)
+	}
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", record.Name, err)
+	}
+
+	existing, err := d.findRecord(ctx, domainID, nodeName, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", record.Name, err)
+	}
+
+	if existing != nil {
+		// Records are updated with a POST to the record's own URL
+		if err := d.doRequest(ctx, http.MethodPost, d.recordPath(domainID, existing.ID), body, nil); err != nil {
+			return errors.NewDNSProviderError("dynu", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		d.logger.Info("DNS record updated successfully",
+			zap.String("provider", "dynu"),
+			zap.String("record", record.Name),
+			zap.Int("record_id", existing.ID),
+		)
+		return nil
+	}
+
+	var created DynuRecord
+	if err := d.doRequest(ctx, http.MethodPost, d.recordsPath(domainID), body, &created); err != nil {
+		return errors.NewDNSProviderError("dynu", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	d.logger.Info("DNS record created successfully",
+		zap.String("provider", "dynu"),
+		zap.String("record", record.Name),
+		zap.Int("record_id", created.ID),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (d *DynuProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	d.logger.Debug("getting DNS record",
+		zap.String("provider", "dynu"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("dynu", name, fmt.Errorf("empty record type"))
+	}
+
+	nodeName, err := relativeNameWithApex(name, d.config.Domain, apexEmpty)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("dynu", name, err)
+	}
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("dynu", name, err)
+	}
+
+	found, err := d.findRecord(ctx, domainID, nodeName, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("dynu", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.NodeName, d.config.Domain),
+		Type:     found.RecordType,
+		Value:    found.value(),
+		TTL:      found.TTL,
+		Provider: "dynu",
+		Metadata: map[string]string{
+			"dynu_id":   strconv.Itoa(found.ID),
+			"node_name": found.NodeName,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (d *DynuProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	d.logger.Info("deleting DNS record",
+		zap.String("provider", "dynu"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("dynu", name, fmt.Errorf("empty record type"))
+	}
+
+	nodeName, err := relativeNameWithApex(name, d.config.Domain, apexEmpty)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", name, err)
+	}
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", name, err)
+	}
+
+	existing, err := d.findRecord(ctx, domainID, nodeName, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", name, err)
+	}
+
+	if existing == nil {
+		d.logger.Warn("record not found for deletion",
+			zap.String("provider", "dynu"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	if err := d.doRequest(ctx, http.MethodDelete, d.recordPath(domainID, existing.ID), nil, nil); err != nil {
+		return errors.NewDNSProviderError("dynu", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	d.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "dynu"),
+		zap.String("record", name),
+		zap.Int("record_id", existing.ID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (d *DynuProvider) Validate(ctx context.Context) error {
+	d.logger.Debug("validating dynu provider configuration")
+
+	domainID, err := d.getDomainID(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("dynu", "validation", err)
+	}
+
+	// Test the API key and access to the domain by fetching it
+	var domain DynuDomain
+	if err := d.doRequest(ctx, http.MethodGet, "/dns/"+strconv.Itoa(domainID), nil, &domain); err != nil {
+		return errors.NewDNSProviderError("dynu", "validation", fmt.Errorf("failed to get domain %s: %w", d.config.Domain, err))
+	}
+
+	d.logger.Info("dynu provider validation successful")
+	return nil
+}
+
+// getDomainID returns the ID of the configured domain, looking it up on first use
+func (d *DynuProvider) getDomainID(ctx context.Context) (int, error) {
+	// Take read lock to check cached domain ID
+	d.domainMu.RLock()
+	if d.domainID != 0 {
+		domainID := d.domainID
+		d.domainMu.RUnlock()
+		return domainID, nil
+	}
+	d.domainMu.RUnlock()
+
+	// Domain ID not cached, acquire write lock
+	d.domainMu.Lock()
+	defer d.domainMu.Unlock()
+
+	// Re-check in case another goroutine looked it up meanwhile
+	if d.domainID != 0 {
+		return d.domainID, nil
+	}
+
+	var response struct {
+		Domains []DynuDomain `json:"domains"`
+	}
+	if err := d.doRequest(ctx, http.MethodGet, "/dns", nil, &response); err != nil {
+		return 0, fmt.Errorf("failed to look up domain %s: %w", d.config.Domain, err)
+	}
+
+	for _, domain := range response.Domains {
+		if strings.EqualFold(strings.TrimSuffix(domain.Name, "."), strings.TrimSuffix(d.config.Domain, ".")) {
+			d.domainID = domain.ID
+			d.logger.Debug("resolved dynu domain ID",
+				zap.String("domain", d.config.Domain),
+				zap.Int("domain_id", domain.ID),
+			)
+			return d.domainID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("domain %s not found in the account", d.config.Domain)
+}
+
+// recordsPath returns the API path of the domain's DNS records collection
+func (d *DynuProvider) recordsPath(domainID int) string {
+	return "/dns/" + strconv.Itoa(domainID) + "/record"
+}
+
+// recordPath returns the API path of the record with the given ID
+func (d *DynuProvider) recordPath(domainID, recordID int) string {
+	return d.recordsPath(domainID) + "/" + strconv.Itoa(recordID)
+}
+
+// findRecord finds a record by node name and type
+func (d *DynuProvider) findRecord(ctx context.Context, domainID int, nodeName, recordType string) (*DynuRecord, error) {
+	var response struct {
+		DNSRecords []DynuRecord `json:"dnsRecords"`
+	}
+	if err := d.doRequest(ctx, http.MethodGet, d.recordsPath(domainID), nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	for _, record := range response.DNSRecords {
+		if strings.EqualFold(record.NodeName, nodeName) && record.RecordType == recordType {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the response into out if non-nil.
+// Errors carry the type and message of the response rather than only the status code.
+func (d *DynuProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := d.endpoint + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("API-Key", d.config.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			d.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var status dynuStatus
+	decodeErr := json.Unmarshal(data, &status)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil {
+			if message := status.message(); message != "" {
+				return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", message))
+			}
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	// The API may also report errors in the body of a 200 response
+	if decodeErr == nil && status.StatusCode != 0 && (status.StatusCode < 200 || status.StatusCode >= 300) {
+		message := status.message()
+		if message == "" {
+			message = fmt.Sprintf("status code %d", status.StatusCode)
+		}
+		return fmt.Errorf("API error: %s", message)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// message returns the error message of the response, prefixed with its type
+func (s *dynuStatus) message() string {
+	switch {
+	case s.Type != "" && s.Message != "":
+		return s.Type + ": " + s.Message
+	case s.Message != "":
+		return s.Message
+	default:
+		return s.Type
+	}
+}
+
+// value returns the record value from the field used by its type
+func (r *DynuRecord) value() string {
+	switch r.RecordType {
+	case "A":
+		return r.IPv4Address
+	case "AAAA":
+		return r.IPv6Address
+	case "CNAME":
+		return r.Host
+	case "TXT":
+		return r.TextData
+	default:
+		return ""
+	}
+}
+
+// setValue stores value in the field used by the record's type
+func (r *DynuRecord) setValue(value string) error {
+	switch r.RecordType {
+	case "A":
+		r.IPv4Address = value
+	case "AAAA":
+		r.IPv6Address = value
+	case "CNAME":
+		r.Host = value
+	case "TXT":
+		r.TextData = value
+	default:
+		return fmt.Errorf("unsupported record type %s", r.RecordType)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDynu is a minimal in-memory Dynu API v2 with the domains example.com (ID 7) and example.org (ID 8)
+type fakeDynu struct {
+	t        *testing.T
+	mu       sync.Mutex
+	records  []dns.DynuRecord
+	bodies   []map[string]interface{}
+	nextID   int
+	requests []string
+}
+
+func newFakeDynu(t *testing.T, records ...dns.DynuRecord) *fakeDynu {
+	return &fakeDynu{t: t, records: records, nextID: 100}
+}
+
+func (f *fakeDynu) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	if r.Header.Get("API-Key") != "api-key" {
+		writeDynuError(w, http.StatusUnauthorized, "Authentication Exception", "Invalid API key")
+		return
+	}
+
+	const recordsPath = "/v2/dns/7/record"
+	switch {
+	case r.URL.Path == "/v2/dns" && r.Method == http.MethodGet:
+		writeDynu(w, map[string]interface{}{"domains": []dns.DynuDomain{
+			{ID: 8, Name: "example.org"},
+			{ID: 7, Name: "example.com"},
+		}})
+
+	case r.URL.Path == "/v2/dns/7" && r.Method == http.MethodGet:
+		writeDynu(w, map[string]interface{}{"id": 7, "name": "example.com"})
+
+	case r.URL.Path == recordsPath && r.Method == http.MethodGet:
+		writeDynu(w, map[string]interface{}{"dnsRecords": f.records})
+
+	case strings.HasPrefix(r.URL.Path, recordsPath):
+		record := f.decodeRecord(r)
+		if r.URL.Path == recordsPath && r.Method == http.MethodPost {
+			f.nextID++
+			record.ID = f.nextID
+			f.records = append(f.records, record)
+			writeDynu(w, record)
+			return
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, recordsPath+"/"))
+		require.NoError(f.t, err)
+		for i := range f.records {
+			if f.records[i].ID != id {
+				continue
+			}
+
+			switch r.Method {
+			case http.MethodPost:
+				record.ID = id
+				f.records[i] = record
+				writeDynu(w, record)
+			case http.MethodDelete:
+				f.records = append(f.records[:i], f.records[i+1:]...)
+				writeDynu(w, map[string]interface{}{})
+			}
+			return
+		}
+		writeDynuError(w, http.StatusNotFound, "Not Found Exception", "Record not found")
+
+	default:
+		writeDynuError(w, http.StatusNotFound, "Not Found Exception", "Domain not found")
+	}
+}
+
+// decodeRecord decodes a request body both as record and as raw JSON, to check the field names sent
+func (f *fakeDynu) decodeRecord(r *http.Request) dns.DynuRecord {
+	var record dns.DynuRecord
+	if r.Method != http.MethodPost {
+		return record
+	}
+
+	data, err := io.ReadAll(r.Body)
+	require.NoError(f.t, err)
+	require.NoError(f.t, json.Unmarshal(data, &record))
+
+	var raw map[string]interface{}
+	require.NoError(f.t, json.Unmarshal(data, &raw))
+	f.bodies = append(f.bodies, raw)
+	return record
+}
+
+func writeDynu(w http.ResponseWriter, data interface{}) {
+	payload, _ := json.Marshal(data)
+	var body map[string]interface{}
+	_ = json.Unmarshal(payload, &body)
+	body["statusCode"] = http.StatusOK
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeDynuError(w http.ResponseWriter, status int, errorType, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"statusCode": status,
+		"type":       errorType,
+		"message":    message,
+	})
+}
+
+func newDynuTestProvider(t *testing.T, handler http.Handler, apiKey, domain string) *dns.DynuProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return dns.NewDynuProvider(&config.DynuConfig{
+		APIKey:   apiKey,
+		Domain:   domain,
+		Endpoint: server.URL + "/v2",
+	}, zap.NewNop())
+}
+
+func countRequests(requests []string, request string) int {
+	count := 0
+	for _, r := range requests {
+		if r == request {
+			count++
+		}
+	}
+	return count
+}
+
+func TestDynuProvider_Name(t *testing.T) {
+	provider := dns.NewDynuProvider(&config.DynuConfig{
+		APIKey: "api-key",
+		Domain: "example.com",
+	}, zap.NewNop())
+	assert.Equal(t, "dynu", provider.Name())
+
+	assert.Nil(t, dns.NewDynuProvider(nil, zap.NewNop()))
+}
+
+func TestDynuProvider_DomainIDResolution(t *testing.T) {
+	t.Run("looked up once and cached", func(t *testing.T) {
+		fake := newFakeDynu(t, dns.DynuRecord{ID: 5, NodeName: "home", RecordType: "A", TTL: 60, State: true, IPv4Address: "192.0.2.1"})
+		provider := newDynuTestProvider(t, fake, "api-key", "example.com")
+		ctx := context.Background()
+
+		require.NoError(t, provider.Validate(ctx))
+		_, err := provider.GetRecord(ctx, "home.example.com", "A")
+		require.NoError(t, err)
+		require.NoError(t, provider.UpdateRecord(ctx, interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		}))
+
+		assert.Equal(t, 1, countRequests(fake.requests, "GET /v2/dns"))
+		assert.Contains(t, fake.requests, "GET /v2/dns/7")
+		assert.Contains(t, fake.requests, "POST /v2/dns/7/record/5")
+	})
+
+	t.Run("domain not in account", func(t *testing.T) {
+		fake := newFakeDynu(t)
+		provider := newDynuTestProvider(t, fake, "api-key", "example.net")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "domain example.net not found in the account")
+
+		// A failed lookup is not cached
+		_, err = provider.GetRecord(context.Background(), "home.example.net", "A")
+		require.Error(t, err)
+		assert.Equal(t, 2, countRequests(fake.requests, "GET /v2/dns"))
+	})
+
+	t.Run("invalid API key", func(t *testing.T) {
+		fake := newFakeDynu(t)
+		provider := newDynuTestProvider(t, fake, "wrong-key", "example.com")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Authentication Exception: Invalid API key")
+		assert.NotContains(t, err.Error(), "wrong-key")
+	})
+
+	t.Run("error status in 200 response", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"statusCode": 501,
+				"type":       "Server Exception",
+				"message":    "Temporarily unavailable",
+			})
+		})
+		provider := newDynuTestProvider(t, handler, "api-key", "example.com")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API error: Server Exception: Temporarily unavailable")
+	})
+}
+
+func TestDynuProvider_RecordFieldMapping(t *testing.T) {
+	t.Run("A record uses ipv4Address", func(t *testing.T) {
+		fake := newFakeDynu(t)
+		provider := newDynuTestProvider(t, fake, "api-key", "example.com")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		}))
+
+		require.Len(t, fake.bodies, 1)
+		assert.Equal(t, map[string]interface{}{
+			"nodeName":    "home",
+			"recordType":  "A",
+			"ttl":         float64(300),
+			"state":       true,
+			"ipv4Address": "203.0.113.10",
+		}, fake.bodies[0])
+		assert.Contains(t, fake.requests, "POST /v2/dns/7/record")
+	})
+
+	t.Run("AAAA record uses ipv6Address", func(t *testing.T) {
+		fake := newFakeDynu(t, dns.DynuRecord{ID: 6, NodeName: "", RecordType: "AAAA", TTL: 60, State: true, IPv6Address: "2001:db8::1"})
+		provider := newDynuTestProvider(t, fake, "api-key", "example.com")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "example.com",
+			Type:  "AAAA",
+			Value: "2001:db8::10",
+			TTL:   300,
+		}))
+
+		require.Len(t, fake.bodies, 1)
+		assert.Equal(t, map[string]interface{}{
+			"nodeName":    "",
+			"recordType":  "AAAA",
+			"ttl":         float64(300),
+			"state":       true,
+			"ipv6Address": "2001:db8::10",
+		}, fake.bodies[0])
+		assert.Contains(t, fake.requests, "POST /v2/dns/7/record/6")
+	})
+
+	t.Run("values are read from the field of their type", func(t *testing.T) {
+		fake := newFakeDynu(t,
+			dns.DynuRecord{ID: 5, NodeName: "home", RecordType: "A", TTL: 120, State: true, IPv4Address: "192.0.2.1"},
+			dns.DynuRecord{ID: 6, NodeName: "home", RecordType: "AAAA", TTL: 120, State: true, IPv6Address: "2001:db8::1"},
+		)
+		provider := newDynuTestProvider(t, fake, "api-key", "example.com")
+		ctx := context.Background()
+
+		found, err := provider.GetRecord(ctx, "home.example.com", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "home.example.com", found.Name)
+		assert.Equal(t, "192.0.2.1", found.Value)
+		assert.Equal(t, 120, found.TTL)
+		assert.Equal(t, "5", found.Metadata["dynu_id"])
+
+		found, err = provider.GetRecord(ctx, "home.example.com", "AAAA")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "2001:db8::1", found.Value)
+		assert.Equal(t, "6", found.Metadata["dynu_id"])
+	})
+
+	t.Run("unsupported record type", func(t *testing.T) {
+		fake := newFakeDynu(t)
+		provider := newDynuTestProvider(t, fake, "api-key", "example.com")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "SRV",
+			Value: "0 5 5060 sip.example.com",
+			TTL:   300,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported record type SRV")
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestDynuProvider_DeleteRecord(t *testing.T) {
+	fake := newFakeDynu(t,
+		dns.DynuRecord{ID: 5, NodeName: "home", RecordType: "A", TTL: 120, State: true, IPv4Address: "192.0.2.1"},
+		dns.DynuRecord{ID: 6, NodeName: "home", RecordType: "AAAA", TTL: 120, State: true, IPv6Address: "2001:db8::1"},
+	)
+	provider := newDynuTestProvider(t, fake, "api-key", "example.com")
+	ctx := context.Background()
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+	require.Len(t, fake.records, 1)
+	assert.Equal(t, "AAAA", fake.records[0].RecordType)
+	assert.Contains(t, fake.requests, "DELETE /v2/dns/7/record/5")
+
+	// Deleting a record that does not exist is not an error
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+}
@@ -0,0 +1,361 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const easyDNSDefaultEndpoint = "https://rest.easydns.net"
+
+// EasyDNSProvider implements DNSProvider for the EasyDNS REST API
+type EasyDNSProvider struct {
+	config   *config.EasyDNSConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+}
+
+// EasyDNSRecord represents a DNS record in the EasyDNS API
+type EasyDNSRecord struct {
+	ID     string `json:"id,omitempty"`
+	Domain string `json:"domain"`
+	Host   string `json:"host"`
+	TTL    string `json:"ttl"`
+	Prio   string `json:"prio"`
+	Type   string `json:"type"`
+	RData  string `json:"rdata"`
+}
+
+// easyDNSResponse is the envelope of every EasyDNS API response. The API can answer HTTP 200
+// with an error, which is only reported by the status field of the body.
+type easyDNSResponse struct {
+	Msg    string          `json:"msg"`
+	Status int             `json:"status"`
+	Data   json.RawMessage `json:"data"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewEasyDNSProvider creates a new EasyDNS provider
+func NewEasyDNSProvider(cfg *config.EasyDNSConfig, logger *zap.Logger) *EasyDNSProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("easydns config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = easyDNSDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &EasyDNSProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (e *EasyDNSProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(e.client, proxy)
+}
+
+// Name returns the provider name
+func (e *EasyDNSProvider) Name() string {
+	return "easydns"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (e *EasyDNSProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	e.logger.Info("updating DNS record",
+		zap.String("provider", "easydns"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("easydns", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(record.Name, e.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("easydns", record.Name, err)
+	}
+
+	existing, err := e.findRecord(ctx, host, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("easydns", record.Name, err)
+	}
+
+	body := EasyDNSRecord{
+		Domain: e.config.Domain,
+		Host:   host,
+		TTL:    strconv.Itoa(record.TTL),
+		Prio:   "0",
+		Type:   record.Type,
+		RData:  record.Value,
+	}
+
+	if existing != nil {
+		if err := e.doRequest(ctx, http.MethodPut, e.recordPath(existing.ID), body, nil); err != nil {
+			return errors.NewDNSProviderError("easydns", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		e.logger.Info("DNS record updated successfully",
+			zap.String("provider", "easydns"),
+			zap.String("record", record.Name),
+			zap.String("record_id", existing.ID),
+		)
+		return nil
+	}
+
+	path := "/zones/records/add/" + url.PathEscape(e.config.Domain) + "/" + url.PathEscape(record.Type)
+	var created EasyDNSRecord
+	if err := e.doRequest(ctx, http.MethodPut, path, body, &created); err != nil {
+		return errors.NewDNSProviderError("easydns", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	e.logger.Info("DNS record created successfully",
+		zap.String("provider", "easydns"),
+		zap.String("record", record.Name),
+		zap.String("record_id", created.ID),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (e *EasyDNSProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	e.logger.Debug("getting DNS record",
+		zap.String("provider", "easydns"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("easydns", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(name, e.config.Domain)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("easydns", name, err)
+	}
+
+	found, err := e.findRecord(ctx, host, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("easydns", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	ttl, err := strconv.Atoi(found.TTL)
+	if err != nil {
+		e.logger.Debug("failed to parse record TTL",
+			zap.String("record", name),
+			zap.String("ttl", found.TTL),
+		)
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Host, e.config.Domain),
+		Type:     found.Type,
+		Value:    found.RData,
+		TTL:      ttl,
+		Provider: "easydns",
+		Metadata: map[string]string{
+			"easydns_id": found.ID,
+			"host":       found.Host,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (e *EasyDNSProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	e.logger.Info("deleting DNS record",
+		zap.String("provider", "easydns"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("easydns", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(name, e.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("easydns", name, err)
+	}
+
+	existing, err := e.findRecord(ctx, host, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("easydns", name, err)
+	}
+
+	if existing == nil {
+		e.logger.Warn("record not found for deletion",
+			zap.String("provider", "easydns"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	if err := e.doRequest(ctx, http.MethodDelete, e.recordPath(existing.ID), nil, nil); err != nil {
+		return errors.NewDNSProviderError("easydns", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	e.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "easydns"),
+		zap.String("record", name),
+		zap.String("record_id", existing.ID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (e *EasyDNSProvider) Validate(ctx context.Context) error {
+	e.logger.Debug("validating easydns provider configuration")
+
+	// Test the credentials and access to the domain by listing its records
+	if _, err := e.listRecords(ctx); err != nil {
+		return errors.NewDNSProviderError("easydns", "validation", err)
+	}
+
+	e.logger.Info("easydns provider validation successful")
+	return nil
+}
+
+// recordPath returns the API path of the record with the given ID
+func (e *EasyDNSProvider) recordPath(id string) string {
+	return "/zones/records/" + url.PathEscape(e.config.Domain) + "/" + url.PathEscape(id)
+}
+
+// findRecord finds a record by zone-relative host and type
+func (e *EasyDNSProvider) findRecord(ctx context.Context, host, recordType string) (*EasyDNSRecord, error) {
+	records, err := e.listRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if strings.EqualFold(record.Host, host) && record.Type == recordType {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// listRecords lists all DNS records of the domain
+func (e *EasyDNSProvider) listRecords(ctx context.Context) ([]EasyDNSRecord, error) {
+	var records []EasyDNSRecord
+	path := "/zones/records/all/" + url.PathEscape(e.config.Domain)
+	if err := e.doRequest(ctx, http.MethodGet, path, nil, &records); err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+	return records, nil
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the data field of the response envelope into out if non-nil.
+// A status other than 200, or 201 for created records, in the envelope is an error even if the HTTP status is 200.
+func (e *EasyDNSProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := e.endpoint + path + "?format=json"
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(e.config.Token, e.config.Key)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			e.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	var envelope easyDNSResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&envelope)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil {
+			if message := envelope.message(); message != "" {
+				return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", message))
+			}
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+
+	if envelope.Status != 0 && envelope.Status != http.StatusOK && envelope.Status != http.StatusCreated {
+		message := envelope.message()
+		if message == "" {
+			message = "unexpected status"
+		}
+		return fmt.Errorf("API error (status %d): %s", envelope.Status, message)
+	}
+
+	if out == nil || len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+
+	return nil
+}
+
+// message returns the error message of the envelope
+func (r *easyDNSResponse) message() string {
+	if r.Error != nil && r.Error.Message != "" {
+		return r.Error.Message
+	}
+	return r.Msg
+}
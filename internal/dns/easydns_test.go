@@ -0,0 +1,254 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeEasyDNS is a minimal in-memory EasyDNS REST API for the example.com domain
+type fakeEasyDNS struct {
+	t        *testing.T
+	mu       sync.Mutex
+	records  []dns.EasyDNSRecord
+	nextID   int
+	requests []string
+
+	// bodyStatus, if set, is returned in the response envelope with HTTP 200
+	bodyStatus int
+	bodyMsg    string
+}
+
+func newFakeEasyDNS(t *testing.T, records ...dns.EasyDNSRecord) *fakeEasyDNS {
+	return &fakeEasyDNS{t: t, records: records, nextID: 100}
+}
+
+func (f *fakeEasyDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	assert.Equal(f.t, "json", r.URL.Query().Get("format"))
+
+	token, key, ok := r.BasicAuth()
+	if !ok || token != "api-token" || key != "api-key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{"code": 401, "message": "Authentication failed"},
+		})
+		return
+	}
+
+	if f.bodyStatus != 0 {
+		writeEasyDNS(w, f.bodyStatus, f.bodyMsg, nil)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones/records/all/example.com":
+		writeEasyDNS(w, http.StatusOK, "OK", f.records)
+
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/zones/records/add/example.com/"):
+		var record dns.EasyDNSRecord
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+		assert.Equal(f.t, strings.TrimPrefix(r.URL.Path, "/zones/records/add/example.com/"), record.Type)
+		f.nextID++
+		record.ID = strconv.Itoa(f.nextID)
+		f.records = append(f.records, record)
+		writeEasyDNS(w, http.StatusCreated, "OK", record)
+
+	case strings.HasPrefix(r.URL.Path, "/zones/records/example.com/"):
+		id := strings.TrimPrefix(r.URL.Path, "/zones/records/example.com/")
+		for i, record := range f.records {
+			if record.ID != id {
+				continue
+			}
+
+			switch r.Method {
+			case http.MethodPut:
+				var updated dns.EasyDNSRecord
+				require.NoError(f.t, json.NewDecoder(r.Body).Decode(&updated))
+				updated.ID = id
+				f.records[i] = updated
+				writeEasyDNS(w, http.StatusOK, "OK", updated)
+			case http.MethodDelete:
+				f.records = append(f.records[:i], f.records[i+1:]...)
+				writeEasyDNS(w, http.StatusOK, "OK", map[string]string{"id": id})
+			}
+			return
+		}
+		writeEasyDNS(w, http.StatusNotFound, "record not found", nil)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// writeEasyDNS writes a response envelope with HTTP 200, as the API does for most errors
+func writeEasyDNS(w http.ResponseWriter, status int, msg string, data interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"msg":    msg,
+		"status": status,
+		"data":   data,
+	})
+}
+
+func newEasyDNSTestProvider(t *testing.T, fake *fakeEasyDNS, key string) *dns.EasyDNSProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewEasyDNSProvider(&config.EasyDNSConfig{
+		Token:    "api-token",
+		Key:      key,
+		Domain:   "example.com",
+		Endpoint: server.URL,
+	}, zap.NewNop())
+}
+
+func TestEasyDNSProvider_Name(t *testing.T) {
+	provider := dns.NewEasyDNSProvider(&config.EasyDNSConfig{
+		Token:  "api-token",
+		Key:    "api-key",
+		Domain: "example.com",
+	}, zap.NewNop())
+	assert.Equal(t, "easydns", provider.Name())
+
+	assert.Nil(t, dns.NewEasyDNSProvider(nil, zap.NewNop()))
+}
+
+func TestEasyDNSProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "easydns",
+	}
+
+	t.Run("creates record", func(t *testing.T) {
+		fake := newFakeEasyDNS(t, dns.EasyDNSRecord{ID: "1", Domain: "example.com", Host: "www", TTL: "3600", Prio: "0", Type: "A", RData: "192.0.2.80"})
+		provider := newEasyDNSTestProvider(t, fake, "api-key")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		require.Len(t, fake.records, 2)
+		assert.Equal(t, dns.EasyDNSRecord{ID: "101", Domain: "example.com", Host: "home", TTL: "300", Prio: "0", Type: "A", RData: "203.0.113.10"}, fake.records[1])
+		assert.Contains(t, fake.requests, "PUT /zones/records/add/example.com/A")
+	})
+
+	t.Run("updates existing record", func(t *testing.T) {
+		fake := newFakeEasyDNS(t,
+			dns.EasyDNSRecord{ID: "5", Domain: "example.com", Host: "home", TTL: "60", Prio: "0", Type: "A", RData: "192.0.2.1"},
+			dns.EasyDNSRecord{ID: "6", Domain: "example.com", Host: "home", TTL: "60", Prio: "0", Type: "AAAA", RData: "2001:db8::1"},
+		)
+		provider := newEasyDNSTestProvider(t, fake, "api-key")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, "203.0.113.10", fake.records[0].RData)
+		assert.Equal(t, "300", fake.records[0].TTL)
+		assert.Equal(t, "2001:db8::1", fake.records[1].RData)
+		assert.Contains(t, fake.requests, "PUT /zones/records/example.com/5")
+	})
+
+	t.Run("zone apex", func(t *testing.T) {
+		fake := newFakeEasyDNS(t)
+		provider := newEasyDNSTestProvider(t, fake, "api-key")
+
+		apex := record
+		apex.Name = "example.com"
+		require.NoError(t, provider.UpdateRecord(context.Background(), apex))
+		require.Len(t, fake.records, 1)
+		assert.Equal(t, "@", fake.records[0].Host)
+	})
+}
+
+func TestEasyDNSProvider_EnvelopeStatus(t *testing.T) {
+	t.Run("error status with HTTP 200", func(t *testing.T) {
+		fake := newFakeEasyDNS(t)
+		fake.bodyStatus = http.StatusBadRequest
+		fake.bodyMsg = "Invalid domain"
+		provider := newEasyDNSTestProvider(t, fake, "api-key")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API error (status 400): Invalid domain")
+		assert.Empty(t, fake.records)
+	})
+
+	t.Run("error status fails validation", func(t *testing.T) {
+		fake := newFakeEasyDNS(t)
+		fake.bodyStatus = http.StatusForbidden
+		fake.bodyMsg = "Access denied"
+		provider := newEasyDNSTestProvider(t, fake, "api-key")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Access denied")
+	})
+
+	t.Run("error status on get is not treated as a missing record", func(t *testing.T) {
+		fake := newFakeEasyDNS(t)
+		fake.bodyStatus = http.StatusInternalServerError
+		fake.bodyMsg = "Temporary failure"
+		provider := newEasyDNSTestProvider(t, fake, "api-key")
+
+		found, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("HTTP error", func(t *testing.T) {
+		fake := newFakeEasyDNS(t)
+		provider := newEasyDNSTestProvider(t, fake, "wrong-key")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Authentication failed")
+		assert.NotContains(t, err.Error(), "wrong-key")
+	})
+}
+
+func TestEasyDNSProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := newFakeEasyDNS(t,
+		dns.EasyDNSRecord{ID: "5", Domain: "example.com", Host: "home", TTL: "120", Prio: "0", Type: "A", RData: "192.0.2.1"},
+		dns.EasyDNSRecord{ID: "6", Domain: "example.com", Host: "home", TTL: "120", Prio: "0", Type: "AAAA", RData: "2001:db8::1"},
+	)
+	provider := newEasyDNSTestProvider(t, fake, "api-key")
+	ctx := context.Background()
+
+	found, err := provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "home.example.com", found.Name)
+	assert.Equal(t, "192.0.2.1", found.Value)
+	assert.Equal(t, 120, found.TTL)
+	assert.Equal(t, "5", found.Metadata["easydns_id"])
+
+	missing, err := provider.GetRecord(ctx, "other.example.com", "A")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+	require.Len(t, fake.records, 1)
+	assert.Equal(t, "AAAA", fake.records[0].Type)
+	assert.Contains(t, fake.requests, "DELETE /zones/records/example.com/5")
+
+	// Deleting a record that does not exist is not an error
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+}
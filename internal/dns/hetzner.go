@@ -3,6 +3,8 @@ package dns
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 
@@ -13,16 +15,19 @@ import (
 	"go.uber.org/zap"
 )
 
-// HetznerProvider implements DNSProvider for Hetzner using the official hcloud-go SDK
+// HetznerProvider implements DNSProvider for Hetzner using the official hcloud-go SDK.
+// Zones still hosted on the DNS Console are managed through console instead, see NewHetznerProvider.
 type HetznerProvider struct {
-	config *config.HetznerConfig
-	client *hcloud.Client
-	logger *zap.Logger
-	zone   *hcloud.Zone
-	zoneMu sync.RWMutex
+	config  *config.HetznerConfig
+	client  *hcloud.Client
+	console *HetznerConsoleProvider
+	logger  *zap.Logger
+	zone    *hcloud.Zone
+	zoneMu  sync.RWMutex
 }
 
-// NewHetznerProvider creates a new Hetzner DNS provider using the official hcloud-go SDK
+// NewHetznerProvider creates a new Hetzner DNS provider. It uses the official hcloud-go SDK,
+// or the DNS Console API if the configured api is console.
 func NewHetznerProvider(cfg *config.HetznerConfig, logger *zap.Logger) *HetznerProvider {
 	if cfg == nil {
 		if logger != nil {
@@ -40,6 +45,14 @@ func NewHetznerProvider(cfg *config.HetznerConfig, logger *zap.Logger) *HetznerP
 		return nil
 	}
 
+	if cfg.API == config.HetznerAPIConsole {
+		return &HetznerProvider{
+			config:  cfg,
+			console: NewHetznerConsoleProvider(cfg, logger),
+			logger:  logger,
+		}
+	}
+
 	client := hcloud.NewClient(hcloud.WithToken(token))
 
 	return &HetznerProvider{
@@ -76,6 +89,17 @@ func NewHetznerProviderWithClient(cfg *config.HetznerConfig, client *hcloud.Clie
 	}
 }
 
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (h *HetznerProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	if h.console != nil {
+		h.console.SetProxy(proxy)
+		return
+	}
+
+	httpClient := &http.Client{Transport: newProxyTransport(nil, proxy)}
+	h.client = hcloud.NewClient(hcloud.WithToken(strings.TrimSpace(h.config.APIToken)), hcloud.WithHTTPClient(httpClient))
+}
+
 // Name returns the provider name
 func (h *HetznerProvider) Name() string {
 	return "hetzner"
@@ -83,6 +107,10 @@ func (h *HetznerProvider) Name() string {
 
 // UpdateRecord updates or creates a DNS record
 func (h *HetznerProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	if h.console != nil {
+		return h.console.UpdateRecord(ctx, record)
+	}
+
 	h.logger.Info("updating DNS record",
 		zap.String("provider", "hetzner"),
 		zap.String("record", record.Name),
@@ -114,11 +142,32 @@ func (h *HetznerProvider) UpdateRecord(ctx context.Context, record interfaces.DN
 	}
 
 	// Create new RRSet
-	return h.createNewRRSet(ctx, zone, record)
+	err = h.createNewRRSet(ctx, zone, record)
+	if hcloud.IsError(err, hcloud.ErrorCodeUniquenessError) {
+		var rrset *hcloud.ZoneRRSet
+		return resolveDuplicateCreate(ctx, h.logger, "hetzner", record, err,
+			func(ctx context.Context) (*interfaces.DNSRecord, error) {
+				found, err := h.findRRSet(ctx, zone, record.Name, rrsetType)
+				if err != nil || found == nil {
+					return nil, err
+				}
+				rrset = found
+				return h.rrsetToRecord(rrset), nil
+			},
+			func(ctx context.Context, _ *interfaces.DNSRecord) error {
+				return h.updateExistingRRSet(ctx, rrset, record)
+			},
+		)
+	}
+	return err
 }
 
 // GetRecord retrieves an existing DNS record
 func (h *HetznerProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	if h.console != nil {
+		return h.console.GetRecord(ctx, name, rtype)
+	}
+
 	h.logger.Debug("getting DNS record",
 		zap.String("provider", "hetzner"),
 		zap.String("record", name),
@@ -146,6 +195,11 @@ func (h *HetznerProvider) GetRecord(ctx context.Context, name string, rtype stri
 		return nil, nil // Record not found
 	}
 
+	return h.rrsetToRecord(rrset), nil
+}
+
+// rrsetToRecord converts a RRSet to a DNS record
+func (h *HetznerProvider) rrsetToRecord(rrset *hcloud.ZoneRRSet) *interfaces.DNSRecord {
 	// Get the first record value (assuming single value for simplicity)
 	var value string
 	if len(rrset.Records) > 0 {
@@ -163,6 +217,11 @@ func (h *HetznerProvider) GetRecord(ctx context.Context, name string, rtype stri
 		}
 	}
 
+	return h.rrsetRecord(rrset, value)
+}
+
+// rrsetRecord converts a RRSet with one of its values to a DNS record
+func (h *HetznerProvider) rrsetRecord(rrset *hcloud.ZoneRRSet, value string) *interfaces.DNSRecord {
 	var ttl int
 	if rrset.TTL != nil {
 		ttl = *rrset.TTL
@@ -178,11 +237,45 @@ func (h *HetznerProvider) GetRecord(ctx context.Context, name string, rtype stri
 			"rrset_id": rrset.ID,
 			"zone_id":  h.config.ZoneID,
 		},
-	}, nil
+	}
+}
+
+// ListRecords returns all records of the zone, see interfaces.RecordLister
+func (h *HetznerProvider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	if h.console != nil {
+		return h.console.ListRecords(ctx)
+	}
+
+	h.logger.Debug("listing DNS records",
+		zap.String("provider", "hetzner"),
+		zap.String("zone_id", h.config.ZoneID),
+	)
+
+	zone, err := h.getZone(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", h.config.ZoneID, err)
+	}
+
+	rrsets, err := h.client.Zone.AllRRSets(ctx, zone)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", h.config.ZoneID, fmt.Errorf("failed to list RRSets: %w", err))
+	}
+
+	var records []interfaces.DNSRecord
+	for _, rrset := range rrsets {
+		for _, value := range rrset.Records {
+			records = append(records, *h.rrsetRecord(rrset, value.Value))
+		}
+	}
+	return records, nil
 }
 
 // DeleteRecord deletes a DNS record
 func (h *HetznerProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	if h.console != nil {
+		return h.console.DeleteRecord(ctx, name, recordType)
+	}
+
 	h.logger.Info("deleting DNS record",
 		zap.String("provider", "hetzner"),
 		zap.String("record", name),
@@ -224,6 +317,10 @@ func (h *HetznerProvider) DeleteRecord(ctx context.Context, name, recordType str
 
 // Validate checks if the provider configuration is valid
 func (h *HetznerProvider) Validate(ctx context.Context) error {
+	if h.console != nil {
+		return h.console.Validate(ctx)
+	}
+
 	h.logger.Debug("validating Hetzner provider configuration")
 
 	// Test API access by getting the zone
@@ -2,9 +2,12 @@ package dns_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"github.com/devhat/ipfailover/pkg/interfaces"
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -311,6 +315,73 @@ func TestHetznerProvider_ConfigurationValidation(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "zone_id is required")
 	})
+
+	t.Run("Hetzner config validation - console API with zone name", func(t *testing.T) {
+		cfg := &config.HetznerConfig{
+			APIToken: "test-token",
+			API:      config.HetznerAPIConsole,
+			Zone:     "example.com",
+		}
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("Hetzner config validation - console API without zone", func(t *testing.T) {
+		cfg := &config.HetznerConfig{
+			APIToken: "test-token",
+			API:      config.HetznerAPIConsole,
+		}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "zone_id or zone is required")
+	})
+
+	t.Run("Hetzner config validation - unknown API", func(t *testing.T) {
+		cfg := &config.HetznerConfig{
+			APIToken: "test-token",
+			ZoneID:   "test-zone",
+			API:      "robot",
+		}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `api must be "cloud" or "console", got "robot"`)
+	})
+}
+
+func TestHetznerProvider_ConsoleAPI(t *testing.T) {
+	fake := newFakeHetznerConsole(t,
+		dns.HetznerConsoleRecord{ID: "rec-1", ZoneID: "zone-7", Type: "A", Name: "home", Value: "192.0.2.1", TTL: 60},
+	)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := dns.NewHetznerProvider(&config.HetznerConfig{
+		APIToken: "api-token",
+		API:      config.HetznerAPIConsole,
+		Zone:     "example.com",
+		Endpoint: server.URL + "/api/v1",
+	}, zap.NewNop())
+	require.NotNil(t, provider)
+	assert.Equal(t, "hetzner", provider.Name())
+
+	ctx := context.Background()
+	require.NoError(t, provider.Validate(ctx))
+
+	found, err := provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "192.0.2.1", found.Value)
+
+	require.NoError(t, provider.UpdateRecord(ctx, interfaces.DNSRecord{
+		Name:  "home.example.com",
+		Type:  "A",
+		Value: "203.0.113.10",
+		TTL:   300,
+	}))
+	assert.Equal(t, "203.0.113.10", fake.records[0].Value)
+	assert.Contains(t, fake.requests, "PUT /api/v1/records/rec-1")
 }
 
 func TestHetznerProvider_WithMockServer(t *testing.T) {
@@ -493,3 +564,141 @@ func TestHetznerProvider_WithMockServer(t *testing.T) {
 		assert.NotNil(t, provider)
 	})
 }
+
+// fakeHetznerDuplicate is a Hetzner zones API whose zone has no RRSet when it is first read, but
+// rejects the create because another client created the RRSet with value in the meantime
+type fakeHetznerDuplicate struct {
+	mu      sync.Mutex
+	value   string
+	created bool
+	writes  int
+}
+
+func (f *fakeHetznerDuplicate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const action = `{"action":{"id":1,"command":"set_rrset_records","status":"success","progress":100,` +
+		`"started":"2025-01-01T00:00:00Z","finished":"2025-01-01T00:00:01Z","resources":[],"error":null}}`
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones/test-zone":
+		_, _ = w.Write([]byte(`{"zone":{"id":12345,"name":"example.com","ttl":3600}}`))
+	case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/rrsets/"):
+		if !f.created {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"rrset not found"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"rrset":{"id":"home/A","name":"home","type":"A","ttl":300,"zone":12345,` +
+			`"records":[{"value":"` + f.value + `","comment":""}],"labels":{},"protection":{"change":false}}}`))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/rrsets"):
+		f.created = true
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"uniqueness_error","message":"rrset already exists"}}`))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/actions/set_records"):
+		var body struct {
+			Records []struct {
+				Value string `json:"value"`
+			} `json:"records"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Records) > 0 {
+			f.value = body.Records[0].Value
+		}
+		f.writes++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(action))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/actions/change_ttl"):
+		f.writes++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(action))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"not found"}}`))
+	}
+}
+
+func TestHetznerProvider_ListRecords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/zones/test-zone":
+			_, _ = w.Write([]byte(`{"zone":{"id":12345,"name":"example.com","ttl":3600}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/zones/12345/rrsets":
+			_, _ = w.Write([]byte(`{"rrsets":[` +
+				`{"id":"home/A","name":"home","type":"A","ttl":300,"zone":12345,` +
+				`"records":[{"value":"203.0.113.10","comment":""},{"value":"203.0.113.11","comment":""}],"labels":{},"protection":{"change":false}},` +
+				`{"id":"@/MX","name":"@","type":"MX","ttl":null,"zone":12345,` +
+				`"records":[{"value":"10 mail.example.com.","comment":""}],"labels":{},"protection":{"change":false}}` +
+				`],"meta":{"pagination":{"page":1,"per_page":50,"previous_page":null,"next_page":null,"last_page":1,"total_entries":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := hcloud.NewClient(
+		hcloud.WithToken("test-token"),
+		hcloud.WithEndpoint(server.URL),
+	)
+	provider := dns.NewHetznerProviderWithClient(&config.HetznerConfig{
+		APIToken: "test-token",
+		ZoneID:   "test-zone",
+	}, client, zap.NewNop())
+
+	records, err := provider.ListRecords(context.Background())
+	require.NoError(t, err)
+
+	metadata := func(id string) map[string]string {
+		return map[string]string{"rrset_id": id, "zone_id": "test-zone"}
+	}
+	assert.Equal(t, []interfaces.DNSRecord{
+		{Name: "home", Type: "A", Value: "203.0.113.10", TTL: 300, Provider: "hetzner", Metadata: metadata("home/A")},
+		{Name: "home", Type: "A", Value: "203.0.113.11", TTL: 300, Provider: "hetzner", Metadata: metadata("home/A")},
+		{Name: "@", Type: "MX", Value: "10 mail.example.com.", Provider: "hetzner", Metadata: metadata("@/MX")},
+	}, records)
+}
+
+func TestHetznerProvider_DuplicateCreate(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "hetzner",
+	}
+
+	newProvider := func(t *testing.T, fake *fakeHetznerDuplicate) *dns.HetznerProvider {
+		server := httptest.NewServer(fake)
+		t.Cleanup(server.Close)
+
+		client := hcloud.NewClient(
+			hcloud.WithToken("test-token"),
+			hcloud.WithEndpoint(server.URL),
+		)
+		return dns.NewHetznerProviderWithClient(&config.HetznerConfig{
+			APIToken: "test-token",
+			ZoneID:   "test-zone",
+		}, client, zap.NewNop())
+	}
+
+	t.Run("record created concurrently with the desired value", func(t *testing.T) {
+		fake := &fakeHetznerDuplicate{value: "203.0.113.10"}
+		provider := newProvider(t, fake)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Zero(t, fake.writes)
+	})
+
+	t.Run("record created concurrently with a different value is updated", func(t *testing.T) {
+		fake := &fakeHetznerDuplicate{value: "192.0.2.1"}
+		provider := newProvider(t, fake)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, "203.0.113.10", fake.value)
+		assert.Equal(t, 1, fake.writes)
+	})
+}
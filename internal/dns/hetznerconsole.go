@@ -0,0 +1,485 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const hetznerConsoleDefaultEndpoint = "https://dns.hetzner.com/api/v1"
+
+// hetznerConsolePageSize is the number of records requested per page when listing a zone
+const hetznerConsolePageSize = 100
+
+// HetznerConsoleProvider implements DNSProvider for the Hetzner DNS Console API (dns.hetzner.com).
+// Records are addressed through the ID of their zone, which is looked up by name when zone_id is not configured.
+type HetznerConsoleProvider struct {
+	config   *config.HetznerConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+
+	zoneMu sync.RWMutex
+	zone   *HetznerConsoleZone
+}
+
+// HetznerConsoleZone represents a DNS zone in the Hetzner DNS Console API
+type HetznerConsoleZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// HetznerConsoleRecord represents a DNS record in the Hetzner DNS Console API.
+// Names are relative to the zone, with "@" for the zone apex.
+type HetznerConsoleRecord struct {
+	ID     string `json:"id,omitempty"`
+	ZoneID string `json:"zone_id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	TTL    int    `json:"ttl,omitempty"`
+}
+
+// hetznerConsolePagination is the pagination metadata of list responses
+type hetznerConsolePagination struct {
+	Page     int `json:"page"`
+	LastPage int `json:"last_page"`
+}
+
+// hetznerConsoleError is the body of an error response. The API reports errors either
+// as an error object or, for authentication failures, as a top-level message.
+type hetznerConsoleError struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+	Message string `json:"message"`
+}
+
+// NewHetznerConsoleProvider creates a new DNS provider for the Hetzner DNS Console API
+func NewHetznerConsoleProvider(cfg *config.HetznerConfig, logger *zap.Logger) *HetznerConsoleProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("hetzner config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = hetznerConsoleDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &HetznerConsoleProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (h *HetznerConsoleProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(h.client, proxy)
+}
+
+// Name returns the provider name
+func (h *HetznerConsoleProvider) Name() string {
+	return "hetzner"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (h *HetznerConsoleProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	h.logger.Info("updating DNS record",
+		zap.String("provider", "hetzner"),
+		zap.String("api", config.HetznerAPIConsole),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("hetzner", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	zone, err := h.getZone(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("hetzner", record.Name, err)
+	}
+
+	name, err := relativeName(record.Name, zone.Name)
+	if err != nil {
+		return errors.NewDNSProviderError("hetzner", record.Name, err)
+	}
+
+	existing, err := h.findRecord(ctx, zone, name, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("hetzner", record.Name, err)
+	}
+
+	body := HetznerConsoleRecord{
+		ZoneID: zone.ID,
+		Type:   record.Type,
+		Name:   name,
+		Value:  record.Value,
+		TTL:    record.TTL,
+	}
+
+	var response struct {
+		Record HetznerConsoleRecord `json:"record"`
+	}
+
+	if existing != nil {
+		if err := h.doRequest(ctx, http.MethodPut, "/records/"+url.PathEscape(existing.ID), body, &response); err != nil {
+			return errors.NewDNSProviderError("hetzner", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		h.logger.Info("DNS record updated successfully",
+			zap.String("provider", "hetzner"),
+			zap.String("record", record.Name),
+			zap.String("record_id", existing.ID),
+			zap.Int("ttl", record.TTL),
+		)
+		return nil
+	}
+
+	if err := h.doRequest(ctx, http.MethodPost, "/records", body, &response); err != nil {
+		return errors.NewDNSProviderError("hetzner", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	h.logger.Info("DNS record created successfully",
+		zap.String("provider", "hetzner"),
+		zap.String("record", record.Name),
+		zap.String("record_id", response.Record.ID),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (h *HetznerConsoleProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	h.logger.Debug("getting DNS record",
+		zap.String("provider", "hetzner"),
+		zap.String("api", config.HetznerAPIConsole),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("hetzner", name, fmt.Errorf("empty record type"))
+	}
+
+	zone, err := h.getZone(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", name, err)
+	}
+
+	relative, err := relativeName(name, zone.Name)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", name, err)
+	}
+
+	found, err := h.findRecord(ctx, zone, relative, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return found.dnsRecord(zone), nil
+}
+
+// ListRecords returns all records of the zone, see interfaces.RecordLister
+func (h *HetznerConsoleProvider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	h.logger.Debug("listing DNS records",
+		zap.String("provider", "hetzner"),
+		zap.String("api", config.HetznerAPIConsole),
+	)
+
+	zone, err := h.getZone(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", "zone", err)
+	}
+
+	var records []interfaces.DNSRecord
+	err = h.eachRecord(ctx, zone, func(record HetznerConsoleRecord) bool {
+		records = append(records, *record.dnsRecord(zone))
+		return true
+	})
+	if err != nil {
+		return nil, errors.NewDNSProviderError("hetzner", zone.Name, err)
+	}
+	return records, nil
+}
+
+// dnsRecord converts a record of zone. Records without their own TTL use the zone default,
+// which is not part of the record.
+func (r *HetznerConsoleRecord) dnsRecord(zone *HetznerConsoleZone) *interfaces.DNSRecord {
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(r.Name, zone.Name),
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      r.TTL,
+		Provider: "hetzner",
+		Metadata: map[string]string{
+			"record_id": r.ID,
+			"zone_id":   zone.ID,
+		},
+	}
+}
+
+// DeleteRecord deletes a DNS record
+func (h *HetznerConsoleProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	h.logger.Info("deleting DNS record",
+		zap.String("provider", "hetzner"),
+		zap.String("api", config.HetznerAPIConsole),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("hetzner", name, fmt.Errorf("empty record type"))
+	}
+
+	zone, err := h.getZone(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("hetzner", name, err)
+	}
+
+	relative, err := relativeName(name, zone.Name)
+	if err != nil {
+		return errors.NewDNSProviderError("hetzner", name, err)
+	}
+
+	existing, err := h.findRecord(ctx, zone, relative, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("hetzner", name, err)
+	}
+
+	if existing == nil {
+		h.logger.Warn("record not found for deletion",
+			zap.String("provider", "hetzner"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	if err := h.doRequest(ctx, http.MethodDelete, "/records/"+url.PathEscape(existing.ID), nil, nil); err != nil {
+		return errors.NewDNSProviderError("hetzner", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	h.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "hetzner"),
+		zap.String("record", name),
+		zap.String("record_id", existing.ID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (h *HetznerConsoleProvider) Validate(ctx context.Context) error {
+	h.logger.Debug("validating Hetzner DNS Console provider configuration")
+
+	// Test API access by getting the zone
+	if _, err := h.getZone(ctx); err != nil {
+		return fmt.Errorf("hetzner API validation failed: %w", err)
+	}
+
+	h.logger.Info("Hetzner DNS Console provider validation successful")
+	return nil
+}
+
+// getZone returns the configured zone, fetching it by ID or looking it up by name on first use
+func (h *HetznerConsoleProvider) getZone(ctx context.Context) (*HetznerConsoleZone, error) {
+	// Take read lock to check cached zone
+	h.zoneMu.RLock()
+	if h.zone != nil {
+		zone := h.zone
+		h.zoneMu.RUnlock()
+		return zone, nil
+	}
+	h.zoneMu.RUnlock()
+
+	// Zone not cached, acquire write lock
+	h.zoneMu.Lock()
+	defer h.zoneMu.Unlock()
+
+	// Re-check in case another goroutine fetched it meanwhile
+	if h.zone != nil {
+		return h.zone, nil
+	}
+
+	zone, err := h.fetchZone(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Debug("resolved hetzner zone",
+		zap.String("zone", zone.Name),
+		zap.String("zone_id", zone.ID),
+	)
+	h.zone = zone
+	return zone, nil
+}
+
+// fetchZone gets the zone by its configured ID, or searches the account for the configured zone name
+func (h *HetznerConsoleProvider) fetchZone(ctx context.Context) (*HetznerConsoleZone, error) {
+	if h.config.ZoneID != "" {
+		var response struct {
+			Zone HetznerConsoleZone `json:"zone"`
+		}
+		if err := h.doRequest(ctx, http.MethodGet, "/zones/"+url.PathEscape(h.config.ZoneID), nil, &response); err != nil {
+			return nil, fmt.Errorf("failed to get zone %s: %w", h.config.ZoneID, err)
+		}
+		return &response.Zone, nil
+	}
+
+	zoneName := strings.TrimSuffix(h.config.Zone, ".")
+	var response struct {
+		Zones []HetznerConsoleZone `json:"zones"`
+	}
+	if err := h.doRequest(ctx, http.MethodGet, "/zones?name="+url.QueryEscape(zoneName), nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to look up zone %s: %w", zoneName, err)
+	}
+
+	for _, zone := range response.Zones {
+		if strings.EqualFold(strings.TrimSuffix(zone.Name, "."), zoneName) {
+			found := zone
+			return &found, nil
+		}
+	}
+
+	return nil, fmt.Errorf("zone %s not found in the account", zoneName)
+}
+
+// findRecord finds a record by zone-relative name and type, reading all pages of the zone's records
+func (h *HetznerConsoleProvider) findRecord(ctx context.Context, zone *HetznerConsoleZone, name, recordType string) (*HetznerConsoleRecord, error) {
+	var found *HetznerConsoleRecord
+	err := h.eachRecord(ctx, zone, func(record HetznerConsoleRecord) bool {
+		if strings.EqualFold(record.Name, name) && record.Type == recordType {
+			found = &record
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// eachRecord calls visit with the records of the zone, page by page, until visit returns false
+func (h *HetznerConsoleProvider) eachRecord(ctx context.Context, zone *HetznerConsoleZone, visit func(record HetznerConsoleRecord) bool) error {
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("zone_id", zone.ID)
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(hetznerConsolePageSize))
+
+		var response struct {
+			Records []HetznerConsoleRecord `json:"records"`
+			Meta    struct {
+				Pagination hetznerConsolePagination `json:"pagination"`
+			} `json:"meta"`
+		}
+		if err := h.doRequest(ctx, http.MethodGet, "/records?"+query.Encode(), nil, &response); err != nil {
+			return fmt.Errorf("failed to list DNS records: %w", err)
+		}
+
+		for _, record := range response.Records {
+			if !visit(record) {
+				return nil
+			}
+		}
+
+		if page >= response.Meta.Pagination.LastPage {
+			return nil
+		}
+	}
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the response into out if non-nil.
+// Errors carry the message of the response rather than only the status code.
+func (h *HetznerConsoleProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := h.endpoint + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Auth-API-Token", h.config.APIToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			h.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr hetznerConsoleError
+		if json.Unmarshal(data, &apiErr) == nil {
+			if message := apiErr.message(); message != "" {
+				return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", message))
+			}
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// message returns the error message of the response
+func (e *hetznerConsoleError) message() string {
+	if e.Error.Message != "" {
+		return e.Error.Message
+	}
+	return e.Message
+}
@@ -0,0 +1,287 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeHetznerConsole is a minimal in-memory Hetzner DNS Console API with the zones example.org (ID zone-8)
+// and example.com (ID zone-7). Records are listed pageSize at a time.
+type fakeHetznerConsole struct {
+	t        *testing.T
+	mu       sync.Mutex
+	records  []dns.HetznerConsoleRecord
+	bodies   []dns.HetznerConsoleRecord
+	pageSize int
+	nextID   int
+	requests []string
+}
+
+func newFakeHetznerConsole(t *testing.T, records ...dns.HetznerConsoleRecord) *fakeHetznerConsole {
+	return &fakeHetznerConsole{t: t, records: records, pageSize: 100, nextID: 100}
+}
+
+func (f *fakeHetznerConsole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.RequestURI())
+
+	if r.Header.Get("Auth-API-Token") != "api-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "Invalid authentication credentials"})
+		return
+	}
+
+	zones := []dns.HetznerConsoleZone{{ID: "zone-8", Name: "example.org"}, {ID: "zone-7", Name: "example.com"}}
+	switch {
+	case r.URL.Path == "/api/v1/zones" && r.Method == http.MethodGet:
+		var matched []dns.HetznerConsoleZone
+		for _, zone := range zones {
+			if zone.Name == r.URL.Query().Get("name") {
+				matched = append(matched, zone)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"zones": matched})
+
+	case strings.HasPrefix(r.URL.Path, "/api/v1/zones/") && r.Method == http.MethodGet:
+		for _, zone := range zones {
+			if r.URL.Path == "/api/v1/zones/"+zone.ID {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"zone": zone})
+				return
+			}
+		}
+		writeHetznerConsoleError(w, http.StatusNotFound, "zone not found")
+
+	case r.URL.Path == "/api/v1/records" && r.Method == http.MethodGet:
+		assert.Equal(f.t, "zone-7", r.URL.Query().Get("zone_id"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		lastPage := (len(f.records) + f.pageSize - 1) / f.pageSize
+		start := (page - 1) * f.pageSize
+		end := start + f.pageSize
+		if end > len(f.records) {
+			end = len(f.records)
+		}
+		var records []dns.HetznerConsoleRecord
+		if start < end {
+			records = f.records[start:end]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"records": records,
+			"meta":    map[string]interface{}{"pagination": map[string]int{"page": page, "last_page": lastPage}},
+		})
+
+	case r.URL.Path == "/api/v1/records" && r.Method == http.MethodPost:
+		record := f.decodeRecord(r)
+		f.nextID++
+		record.ID = "rec-" + strconv.Itoa(f.nextID)
+		f.records = append(f.records, record)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"record": record})
+
+	case strings.HasPrefix(r.URL.Path, "/api/v1/records/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/records/")
+		for i := range f.records {
+			if f.records[i].ID != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodPut:
+				record := f.decodeRecord(r)
+				record.ID = id
+				f.records[i] = record
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"record": record})
+			case http.MethodDelete:
+				f.records = append(f.records[:i], f.records[i+1:]...)
+			}
+			return
+		}
+		writeHetznerConsoleError(w, http.StatusNotFound, "record not found")
+
+	default:
+		writeHetznerConsoleError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *fakeHetznerConsole) decodeRecord(r *http.Request) dns.HetznerConsoleRecord {
+	var record dns.HetznerConsoleRecord
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+	f.bodies = append(f.bodies, record)
+	return record
+}
+
+func writeHetznerConsoleError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "code": status},
+	})
+}
+
+func newHetznerConsoleTestProvider(t *testing.T, handler http.Handler, cfg config.HetznerConfig) *dns.HetznerConsoleProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.API = config.HetznerAPIConsole
+	cfg.Endpoint = server.URL + "/api/v1"
+	return dns.NewHetznerConsoleProvider(&cfg, zap.NewNop())
+}
+
+func TestHetznerConsoleProvider_ZoneResolution(t *testing.T) {
+	t.Run("looked up by name once and cached", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t)
+		provider := newHetznerConsoleTestProvider(t, fake, config.HetznerConfig{APIToken: "api-token", Zone: "example.com"})
+		ctx := context.Background()
+
+		require.NoError(t, provider.Validate(ctx))
+		_, err := provider.GetRecord(ctx, "home.example.com", "A")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, countRequests(fake.requests, "GET /api/v1/zones?name=example.com"))
+	})
+
+	t.Run("fetched by configured ID", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t)
+		provider := newHetznerConsoleTestProvider(t, fake, config.HetznerConfig{APIToken: "api-token", ZoneID: "zone-7"})
+
+		require.NoError(t, provider.Validate(context.Background()))
+		assert.Equal(t, []string{"GET /api/v1/zones/zone-7"}, fake.requests)
+	})
+
+	t.Run("zone not in account", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t)
+		provider := newHetznerConsoleTestProvider(t, fake, config.HetznerConfig{APIToken: "api-token", Zone: "example.net"})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "zone example.net not found in the account")
+	})
+
+	t.Run("invalid API token", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t)
+		provider := newHetznerConsoleTestProvider(t, fake, config.HetznerConfig{APIToken: "wrong-token", ZoneID: "zone-7"})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid authentication credentials")
+		assert.NotContains(t, err.Error(), "wrong-token")
+	})
+}
+
+func TestHetznerConsoleProvider_Records(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.HetznerConfig{APIToken: "api-token", Zone: "example.com"}
+
+	t.Run("creates a missing record", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t)
+		provider := newHetznerConsoleTestProvider(t, fake, cfg)
+
+		require.NoError(t, provider.UpdateRecord(ctx, interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		}))
+
+		assert.Equal(t, []dns.HetznerConsoleRecord{
+			{ZoneID: "zone-7", Type: "A", Name: "home", Value: "203.0.113.10", TTL: 300},
+		}, fake.bodies)
+		assert.Contains(t, fake.requests, "POST /api/v1/records")
+	})
+
+	t.Run("updates an existing apex record", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t,
+			dns.HetznerConsoleRecord{ID: "rec-1", ZoneID: "zone-7", Type: "AAAA", Name: "@", Value: "2001:db8::1", TTL: 60},
+			dns.HetznerConsoleRecord{ID: "rec-2", ZoneID: "zone-7", Type: "A", Name: "@", Value: "192.0.2.1", TTL: 60},
+		)
+		provider := newHetznerConsoleTestProvider(t, fake, cfg)
+
+		require.NoError(t, provider.UpdateRecord(ctx, interfaces.DNSRecord{
+			Name:  "example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		}))
+
+		assert.Contains(t, fake.requests, "PUT /api/v1/records/rec-2")
+		assert.Equal(t, "203.0.113.10", fake.records[1].Value)
+		assert.Equal(t, "2001:db8::1", fake.records[0].Value)
+	})
+
+	t.Run("finds records on later pages", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t,
+			dns.HetznerConsoleRecord{ID: "rec-1", ZoneID: "zone-7", Type: "A", Name: "www", Value: "192.0.2.2", TTL: 60},
+			dns.HetznerConsoleRecord{ID: "rec-2", ZoneID: "zone-7", Type: "A", Name: "mail", Value: "192.0.2.3", TTL: 60},
+			dns.HetznerConsoleRecord{ID: "rec-3", ZoneID: "zone-7", Type: "A", Name: "home", Value: "192.0.2.1", TTL: 120},
+		)
+		fake.pageSize = 2
+		provider := newHetznerConsoleTestProvider(t, fake, cfg)
+
+		found, err := provider.GetRecord(ctx, "home.example.com", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "home.example.com", found.Name)
+		assert.Equal(t, "192.0.2.1", found.Value)
+		assert.Equal(t, 120, found.TTL)
+		assert.Equal(t, "rec-3", found.Metadata["record_id"])
+		assert.Equal(t, "zone-7", found.Metadata["zone_id"])
+
+		missing, err := provider.GetRecord(ctx, "other.example.com", "A")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("lists records of all pages", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t,
+			dns.HetznerConsoleRecord{ID: "rec-1", ZoneID: "zone-7", Type: "A", Name: "@", Value: "192.0.2.1", TTL: 60},
+			dns.HetznerConsoleRecord{ID: "rec-2", ZoneID: "zone-7", Type: "MX", Name: "@", Value: "10 mail.example.com."},
+			dns.HetznerConsoleRecord{ID: "rec-3", ZoneID: "zone-7", Type: "A", Name: "home", Value: "203.0.113.10", TTL: 300},
+		)
+		fake.pageSize = 2
+		provider := newHetznerConsoleTestProvider(t, fake, cfg)
+
+		records, err := provider.ListRecords(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []interfaces.DNSRecord{
+			{Name: "example.com", Type: "A", Value: "192.0.2.1", TTL: 60, Provider: "hetzner",
+				Metadata: map[string]string{"record_id": "rec-1", "zone_id": "zone-7"}},
+			{Name: "example.com", Type: "MX", Value: "10 mail.example.com.", Provider: "hetzner",
+				Metadata: map[string]string{"record_id": "rec-2", "zone_id": "zone-7"}},
+			{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300, Provider: "hetzner",
+				Metadata: map[string]string{"record_id": "rec-3", "zone_id": "zone-7"}},
+		}, records)
+	})
+
+	t.Run("deletes a record", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t,
+			dns.HetznerConsoleRecord{ID: "rec-1", ZoneID: "zone-7", Type: "A", Name: "home", Value: "192.0.2.1", TTL: 60},
+		)
+		provider := newHetznerConsoleTestProvider(t, fake, cfg)
+
+		require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+		assert.Empty(t, fake.records)
+		assert.Contains(t, fake.requests, "DELETE /api/v1/records/rec-1")
+
+		// Deleting a record that does not exist is not an error
+		require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+	})
+
+	t.Run("record outside the zone", func(t *testing.T) {
+		fake := newFakeHetznerConsole(t)
+		provider := newHetznerConsoleTestProvider(t, fake, cfg)
+
+		_, err := provider.GetRecord(ctx, "home.example.org", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not within zone example.com")
+	})
+}
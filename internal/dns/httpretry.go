@@ -0,0 +1,155 @@
+package dns
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/retry"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Defaults of the HTTP settings of a provider, see config.HTTPConfig
+const (
+	defaultHTTPTimeout        = 30 * time.Second
+	defaultHTTPInitialBackoff = time.Second
+	defaultHTTPMaxBackoff     = 30 * time.Second
+)
+
+// retryingHTTPClient sends the API requests of a provider with the timeout and retries of its HTTP
+// settings. Requests that fail with a network error, or with a status that errors.IsRetryableError
+// accepts, such as 429 or 5xx, are retried with exponential backoff, or after the delay given by
+// the Retry-After header of the response.
+type retryingHTTPClient struct {
+	provider string
+	client   *http.Client
+	policy   retry.Policy
+	onRetry  func() // Called before each retry, may be nil
+	logger   *zap.Logger
+}
+
+// newRetryingHTTPClient creates the HTTP client of a provider, sending requests with transport,
+// or http.DefaultTransport if nil
+func newRetryingHTTPClient(provider string, cfg config.HTTPConfig, transport http.RoundTripper, onRetry func(), logger *zap.Logger) *retryingHTTPClient {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+	initialBackoff := cfg.InitialBackoff
+	if initialBackoff == 0 {
+		initialBackoff = defaultHTTPInitialBackoff
+	}
+	maxBackoff := cfg.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = defaultHTTPMaxBackoff
+	}
+
+	return &retryingHTTPClient{
+		provider: provider,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		policy: retry.Policy{
+			MaxRetries: cfg.MaxRetries,
+			BaseDelay:  initialBackoff,
+			MaxDelay:   maxBackoff,
+			Jitter:     retry.DefaultJitter,
+		},
+		onRetry: onRetry,
+		logger:  logger,
+	}
+}
+
+// Do sends req and retries it as configured. The timeout applies to each attempt. The response
+// of the last attempt is returned even if its status is retryable, so callers handle it as usual.
+// Requests with a body are only retried if the body can be recreated with GetBody, which
+// http.NewRequest sets for bytes and strings readers. If the request context ends while waiting
+// for a retry, the error of the last attempt is returned.
+func (c *retryingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.client.Do(req)
+		if attempt > c.policy.MaxRetries || !replayable || ctx.Err() != nil {
+			return resp, err
+		}
+
+		// Network errors, including timeouts of the attempt, are retried
+		retryErr := err
+		if err == nil {
+			httpErr := errors.NewHTTPError(resp.StatusCode, req.URL.Redacted(), fmt.Errorf("unexpected status code"))
+			if !errors.IsRetryableError(httpErr) {
+				return resp, nil
+			}
+			retryErr = httpErr
+		}
+
+		delay := c.policy.Delay(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = min(after, c.policy.MaxDelay)
+			}
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		c.logger.Warn("DNS provider API request failed, retrying",
+			zap.String("provider", c.provider),
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.Redacted()),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(retryErr),
+		)
+		if c.onRetry != nil {
+			c.onRetry()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retryErr
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to recreate request body: %w", err)
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter parses the value of a Retry-After header, in seconds or as an HTTP date, into the
+// delay from now. It reports false if the header is missing or invalid.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
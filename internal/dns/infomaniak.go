@@ -0,0 +1,356 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const infomaniakDefaultEndpoint = "https://api.infomaniak.com"
+
+// InfomaniakProvider implements DNSProvider for the Infomaniak API v2
+type InfomaniakProvider struct {
+	config   *config.InfomaniakConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+}
+
+// InfomaniakRecord represents a DNS record in the Infomaniak API
+type InfomaniakRecord struct {
+	ID     int    `json:"id,omitempty"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+	TTL    int    `json:"ttl"`
+	Target string `json:"target"`
+}
+
+// infomaniakResponse is the envelope of every Infomaniak API response: {"result": "success", "data": ...}
+// on success and {"result": "error", "error": {...}} on failure
+type infomaniakResponse struct {
+	Result string          `json:"result"`
+	Data   json.RawMessage `json:"data"`
+	Error  *struct {
+		Code        string `json:"code"`
+		Description string `json:"description"`
+	} `json:"error,omitempty"`
+}
+
+// NewInfomaniakProvider creates a new Infomaniak DNS provider
+func NewInfomaniakProvider(cfg *config.InfomaniakConfig, logger *zap.Logger) *InfomaniakProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("infomaniak config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = infomaniakDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &InfomaniakProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (i *InfomaniakProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(i.client, proxy)
+}
+
+// Name returns the provider name
+func (i *InfomaniakProvider) Name() string {
+	return "infomaniak"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (i *InfomaniakProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	i.logger.Info("updating DNS record",
+		zap.String("provider", "infomaniak"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("infomaniak", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	source, err := relativeNameWithApex(record.Name, i.config.Zone, apexDot)
+	if err != nil {
+		return errors.NewDNSProviderError("infomaniak", record.Name, err)
+	}
+
+	existing, err := i.findRecord(ctx, source, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("infomaniak", record.Name, err)
+	}
+
+	body := InfomaniakRecord{
+		Source: source,
+		Type:   record.Type,
+		TTL:    record.TTL,
+		Target: record.Value,
+	}
+
+	if existing != nil {
+		if err := i.doRequest(ctx, http.MethodPut, i.recordPath(existing.ID), body, nil); err != nil {
+			return errors.NewDNSProviderError("infomaniak", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		i.logger.Info("DNS record updated successfully",
+			zap.String("provider", "infomaniak"),
+			zap.String("record", record.Name),
+			zap.Int("record_id", existing.ID),
+		)
+		return nil
+	}
+
+	if err := i.doRequest(ctx, http.MethodPost, i.recordsPath(), body, nil); err != nil {
+		return errors.NewDNSProviderError("infomaniak", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	i.logger.Info("DNS record created successfully",
+		zap.String("provider", "infomaniak"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (i *InfomaniakProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	i.logger.Debug("getting DNS record",
+		zap.String("provider", "infomaniak"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("infomaniak", name, fmt.Errorf("empty record type"))
+	}
+
+	source, err := relativeNameWithApex(name, i.config.Zone, apexDot)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("infomaniak", name, err)
+	}
+
+	found, err := i.findRecord(ctx, source, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("infomaniak", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Source, i.config.Zone),
+		Type:     found.Type,
+		Value:    found.Target,
+		TTL:      found.TTL,
+		Provider: "infomaniak",
+		Metadata: map[string]string{
+			"infomaniak_id": strconv.Itoa(found.ID),
+			"source":        found.Source,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (i *InfomaniakProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	i.logger.Info("deleting DNS record",
+		zap.String("provider", "infomaniak"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("infomaniak", name, fmt.Errorf("empty record type"))
+	}
+
+	source, err := relativeNameWithApex(name, i.config.Zone, apexDot)
+	if err != nil {
+		return errors.NewDNSProviderError("infomaniak", name, err)
+	}
+
+	existing, err := i.findRecord(ctx, source, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("infomaniak", name, err)
+	}
+
+	if existing == nil {
+		i.logger.Warn("record not found for deletion",
+			zap.String("provider", "infomaniak"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	if err := i.doRequest(ctx, http.MethodDelete, i.recordPath(existing.ID), nil, nil); err != nil {
+		return errors.NewDNSProviderError("infomaniak", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	i.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "infomaniak"),
+		zap.String("record", name),
+		zap.Int("record_id", existing.ID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (i *InfomaniakProvider) Validate(ctx context.Context) error {
+	i.logger.Debug("validating infomaniak provider configuration")
+
+	// Test the token and access to the zone by listing its records
+	if _, err := i.listRecords(ctx); err != nil {
+		return errors.NewDNSProviderError("infomaniak", "validation", err)
+	}
+
+	i.logger.Info("infomaniak provider validation successful")
+	return nil
+}
+
+// recordsPath returns the API path of the zone's records collection
+func (i *InfomaniakProvider) recordsPath() string {
+	return "/2/zones/" + url.PathEscape(strings.TrimSuffix(i.config.Zone, ".")) + "/records"
+}
+
+// recordPath returns the API path of the record with the given ID
+func (i *InfomaniakProvider) recordPath(id int) string {
+	return i.recordsPath() + "/" + strconv.Itoa(id)
+}
+
+// findRecord finds a record by zone-relative source and type
+func (i *InfomaniakProvider) findRecord(ctx context.Context, source, recordType string) (*InfomaniakRecord, error) {
+	records, err := i.listRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if strings.EqualFold(record.Source, source) && record.Type == recordType {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// listRecords lists all DNS records of the zone
+func (i *InfomaniakProvider) listRecords(ctx context.Context) ([]InfomaniakRecord, error) {
+	var records []InfomaniakRecord
+	if err := i.doRequest(ctx, http.MethodGet, i.recordsPath(), nil, &records); err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+	return records, nil
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the data field of the response envelope into out if non-nil.
+// Errors carry the description from the error envelope rather than only the status code.
+func (i *InfomaniakProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := i.endpoint + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+i.config.APIToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			i.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	var envelope infomaniakResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&envelope)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil {
+			if message := envelope.message(); message != "" {
+				return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", message))
+			}
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+
+	if envelope.Result != "success" {
+		message := envelope.message()
+		if message == "" {
+			message = fmt.Sprintf("unexpected result %q", envelope.Result)
+		}
+		return fmt.Errorf("API error: %s", message)
+	}
+
+	if out == nil || len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+
+	return nil
+}
+
+// message returns the error description of the envelope, prefixed with its code
+func (r *infomaniakResponse) message() string {
+	if r.Error == nil {
+		return ""
+	}
+	switch {
+	case r.Error.Code != "" && r.Error.Description != "":
+		return r.Error.Code + ": " + r.Error.Description
+	case r.Error.Description != "":
+		return r.Error.Description
+	default:
+		return r.Error.Code
+	}
+}
@@ -0,0 +1,246 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeInfomaniak is a minimal in-memory Infomaniak API v2 for the example.ch zone
+type fakeInfomaniak struct {
+	t        *testing.T
+	mu       sync.Mutex
+	records  []dns.InfomaniakRecord
+	nextID   int
+	requests []string
+}
+
+func newFakeInfomaniak(t *testing.T, records ...dns.InfomaniakRecord) *fakeInfomaniak {
+	return &fakeInfomaniak{t: t, records: records, nextID: 100}
+}
+
+func (f *fakeInfomaniak) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	if r.Header.Get("Authorization") != "Bearer api-token" {
+		writeInfomaniakError(w, http.StatusUnauthorized, "not_authorized", "Authorization required")
+		return
+	}
+
+	const recordsPath = "/2/zones/example.ch/records"
+	switch {
+	case r.URL.Path == recordsPath && r.Method == http.MethodGet:
+		writeInfomaniak(w, http.StatusOK, f.records)
+
+	case r.URL.Path == recordsPath && r.Method == http.MethodPost:
+		var record dns.InfomaniakRecord
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+		f.nextID++
+		record.ID = f.nextID
+		f.records = append(f.records, record)
+		writeInfomaniak(w, http.StatusCreated, record)
+
+	case strings.HasPrefix(r.URL.Path, recordsPath+"/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, recordsPath+"/"))
+		require.NoError(f.t, err)
+		for i, record := range f.records {
+			if record.ID != id {
+				continue
+			}
+
+			switch r.Method {
+			case http.MethodPut:
+				var updated dns.InfomaniakRecord
+				require.NoError(f.t, json.NewDecoder(r.Body).Decode(&updated))
+				updated.ID = id
+				f.records[i] = updated
+				writeInfomaniak(w, http.StatusOK, updated)
+			case http.MethodDelete:
+				f.records = append(f.records[:i], f.records[i+1:]...)
+				writeInfomaniak(w, http.StatusOK, true)
+			}
+			return
+		}
+		writeInfomaniakError(w, http.StatusNotFound, "object_not_found", "Record not found")
+
+	default:
+		writeInfomaniakError(w, http.StatusNotFound, "zone_not_found", "Zone not found")
+	}
+}
+
+func writeInfomaniak(w http.ResponseWriter, status int, data interface{}) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": "success", "data": data})
+}
+
+func writeInfomaniakError(w http.ResponseWriter, status int, code, description string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": "error",
+		"error":  map[string]string{"code": code, "description": description},
+	})
+}
+
+func newInfomaniakTestProvider(t *testing.T, handler http.Handler, token, zone string) *dns.InfomaniakProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return dns.NewInfomaniakProvider(&config.InfomaniakConfig{
+		APIToken: token,
+		Zone:     zone,
+		Endpoint: server.URL,
+	}, zap.NewNop())
+}
+
+func TestInfomaniakProvider_Name(t *testing.T) {
+	provider := dns.NewInfomaniakProvider(&config.InfomaniakConfig{
+		APIToken: "api-token",
+		Zone:     "example.ch",
+	}, zap.NewNop())
+	assert.Equal(t, "infomaniak", provider.Name())
+
+	assert.Nil(t, dns.NewInfomaniakProvider(nil, zap.NewNop()))
+}
+
+func TestInfomaniakProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.ch",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "infomaniak",
+	}
+
+	t.Run("creates record", func(t *testing.T) {
+		fake := newFakeInfomaniak(t, dns.InfomaniakRecord{ID: 1, Source: "www", Type: "A", TTL: 3600, Target: "192.0.2.80"})
+		provider := newInfomaniakTestProvider(t, fake, "api-token", "example.ch")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		require.Len(t, fake.records, 2)
+		assert.Equal(t, dns.InfomaniakRecord{ID: 101, Source: "home", Type: "A", TTL: 300, Target: "203.0.113.10"}, fake.records[1])
+		assert.Contains(t, fake.requests, "POST /2/zones/example.ch/records")
+	})
+
+	t.Run("updates existing record", func(t *testing.T) {
+		fake := newFakeInfomaniak(t,
+			dns.InfomaniakRecord{ID: 5, Source: "home", Type: "A", TTL: 60, Target: "192.0.2.1"},
+			dns.InfomaniakRecord{ID: 6, Source: "home", Type: "AAAA", TTL: 60, Target: "2001:db8::1"},
+		)
+		provider := newInfomaniakTestProvider(t, fake, "api-token", "example.ch")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, []dns.InfomaniakRecord{
+			{ID: 5, Source: "home", Type: "A", TTL: 300, Target: "203.0.113.10"},
+			{ID: 6, Source: "home", Type: "AAAA", TTL: 60, Target: "2001:db8::1"},
+		}, fake.records)
+		assert.Contains(t, fake.requests, "PUT /2/zones/example.ch/records/5")
+	})
+
+	t.Run("zone apex", func(t *testing.T) {
+		fake := newFakeInfomaniak(t)
+		provider := newInfomaniakTestProvider(t, fake, "api-token", "example.ch")
+
+		apex := record
+		apex.Name = "example.ch"
+		require.NoError(t, provider.UpdateRecord(context.Background(), apex))
+		require.Len(t, fake.records, 1)
+		assert.Equal(t, ".", fake.records[0].Source)
+
+		found, err := provider.GetRecord(context.Background(), "example.ch", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "example.ch", found.Name)
+	})
+}
+
+func TestInfomaniakProvider_ErrorEnvelope(t *testing.T) {
+	t.Run("error description is surfaced", func(t *testing.T) {
+		fake := newFakeInfomaniak(t)
+		provider := newInfomaniakTestProvider(t, fake, "wrong-token", "example.ch")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not_authorized: Authorization required")
+		assert.NotContains(t, err.Error(), "wrong-token")
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		fake := newFakeInfomaniak(t)
+		provider := newInfomaniakTestProvider(t, fake, "api-token", "example.org")
+
+		_, err := provider.GetRecord(context.Background(), "home.example.org", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "zone_not_found: Zone not found")
+	})
+
+	t.Run("error result with HTTP 200", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeInfomaniakError(w, http.StatusOK, "validation_failed", "The target field is invalid")
+		})
+		provider := newInfomaniakTestProvider(t, handler, "api-token", "example.ch")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.ch",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API error: validation_failed: The target field is invalid")
+	})
+
+	t.Run("error without envelope", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>Bad Gateway</html>"))
+		})
+		provider := newInfomaniakTestProvider(t, handler, "api-token", "example.ch")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "502")
+	})
+}
+
+func TestInfomaniakProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := newFakeInfomaniak(t,
+		dns.InfomaniakRecord{ID: 5, Source: "home", Type: "A", TTL: 120, Target: "192.0.2.1"},
+		dns.InfomaniakRecord{ID: 6, Source: "home", Type: "AAAA", TTL: 120, Target: "2001:db8::1"},
+	)
+	provider := newInfomaniakTestProvider(t, fake, "api-token", "example.ch")
+	ctx := context.Background()
+
+	found, err := provider.GetRecord(ctx, "home.example.ch", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "home.example.ch", found.Name)
+	assert.Equal(t, "192.0.2.1", found.Value)
+	assert.Equal(t, 120, found.TTL)
+	assert.Equal(t, "5", found.Metadata["infomaniak_id"])
+
+	missing, err := provider.GetRecord(ctx, "other.example.ch", "A")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.ch", "A"))
+	require.Len(t, fake.records, 1)
+	assert.Equal(t, "AAAA", fake.records[0].Type)
+	assert.Contains(t, fake.requests, "DELETE /2/zones/example.ch/records/5")
+
+	// Deleting a record that does not exist is not an error
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.ch", "A"))
+}
@@ -0,0 +1,356 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const loopiaDefaultEndpoint = "https://api.loopia.se/RPCSERV"
+
+// loopiaStatusOK is the status string returned by Loopia API methods that succeed
+const loopiaStatusOK = "OK"
+
+// LoopiaProvider implements DNSProvider for the Loopia XML-RPC API.
+// Records are addressed by domain and subdomain, with "@" for the zone apex.
+type LoopiaProvider struct {
+	config   *config.LoopiaConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+}
+
+// LoopiaRecord represents a zone record in the Loopia API
+type LoopiaRecord struct {
+	Type     string
+	TTL      int
+	Priority int
+	RData    string
+	RecordID int
+}
+
+// NewLoopiaProvider creates a new Loopia DNS provider
+func NewLoopiaProvider(cfg *config.LoopiaConfig, logger *zap.Logger) *LoopiaProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("loopia config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = loopiaDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &LoopiaProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: endpoint,
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (l *LoopiaProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(l.client, proxy)
+}
+
+// Name returns the provider name
+func (l *LoopiaProvider) Name() string {
+	return "loopia"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (l *LoopiaProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	l.logger.Info("updating DNS record",
+		zap.String("provider", "loopia"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("loopia", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	subdomain, err := relativeName(record.Name, l.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("loopia", record.Name, err)
+	}
+
+	existing, err := l.findRecord(ctx, subdomain, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("loopia", record.Name, err)
+	}
+
+	desired := LoopiaRecord{
+		Type:  record.Type,
+		TTL:   record.TTL,
+		RData: record.Value,
+	}
+
+	if existing != nil {
+		desired.Priority = existing.Priority
+		desired.RecordID = existing.RecordID
+		if err := l.callStatus(ctx, "updateZoneRecord", l.config.Domain, subdomain, desired.xmlrpc()); err != nil {
+			return errors.NewDNSProviderError("loopia", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		l.logger.Info("DNS record updated successfully",
+			zap.String("provider", "loopia"),
+			zap.String("record", record.Name),
+			zap.Int("record_id", existing.RecordID),
+		)
+		return nil
+	}
+
+	// addZoneRecord creates the subdomain if it does not exist yet
+	if err := l.callStatus(ctx, "addZoneRecord", l.config.Domain, subdomain, desired.xmlrpc()); err != nil {
+		return errors.NewDNSProviderError("loopia", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	l.logger.Info("DNS record created successfully",
+		zap.String("provider", "loopia"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (l *LoopiaProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	l.logger.Debug("getting DNS record",
+		zap.String("provider", "loopia"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("loopia", name, fmt.Errorf("empty record type"))
+	}
+
+	subdomain, err := relativeName(name, l.config.Domain)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("loopia", name, err)
+	}
+
+	found, err := l.findRecord(ctx, subdomain, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("loopia", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(subdomain, l.config.Domain),
+		Type:     found.Type,
+		Value:    found.RData,
+		TTL:      found.TTL,
+		Provider: "loopia",
+		Metadata: map[string]string{
+			"loopia_id": strconv.Itoa(found.RecordID),
+			"subdomain": subdomain,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record. The subdomain itself is kept, as other records may use it.
+func (l *LoopiaProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	l.logger.Info("deleting DNS record",
+		zap.String("provider", "loopia"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("loopia", name, fmt.Errorf("empty record type"))
+	}
+
+	subdomain, err := relativeName(name, l.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("loopia", name, err)
+	}
+
+	existing, err := l.findRecord(ctx, subdomain, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("loopia", name, err)
+	}
+
+	if existing == nil {
+		l.logger.Warn("record not found for deletion",
+			zap.String("provider", "loopia"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	if err := l.callStatus(ctx, "removeZoneRecord", l.config.Domain, subdomain, existing.RecordID); err != nil {
+		return errors.NewDNSProviderError("loopia", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	l.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "loopia"),
+		zap.String("record", name),
+		zap.Int("record_id", existing.RecordID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (l *LoopiaProvider) Validate(ctx context.Context) error {
+	l.logger.Debug("validating loopia provider configuration")
+
+	// Test the credentials and access to the domain by listing the records of the zone apex
+	if _, err := l.getZoneRecords(ctx, apexAt); err != nil {
+		return errors.NewDNSProviderError("loopia", "validation", err)
+	}
+
+	l.logger.Info("loopia provider validation successful")
+	return nil
+}
+
+// findRecord finds a record of the subdomain by type
+func (l *LoopiaProvider) findRecord(ctx context.Context, subdomain, recordType string) (*LoopiaRecord, error) {
+	records, err := l.getZoneRecords(ctx, subdomain)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.Type == recordType {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// getZoneRecords lists the records of a subdomain. A subdomain without records yields an empty list.
+func (l *LoopiaProvider) getZoneRecords(ctx context.Context, subdomain string) ([]LoopiaRecord, error) {
+	result, err := l.call(ctx, "getZoneRecords", l.config.Domain, subdomain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	// Errors are reported as a status string, either alone or as the only element of the list
+	if status, ok := result.(string); ok {
+		return nil, fmt.Errorf("failed to list DNS records: API error: %s", status)
+	}
+
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to list DNS records: unexpected response of type %T", result)
+	}
+	if len(items) == 1 {
+		if status, ok := items[0].(string); ok {
+			return nil, fmt.Errorf("failed to list DNS records: API error: %s", status)
+		}
+	}
+
+	records := make([]LoopiaRecord, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to list DNS records: unexpected record of type %T", item)
+		}
+		records = append(records, loopiaRecordFromXMLRPC(fields))
+	}
+
+	return records, nil
+}
+
+// callStatus calls a method that returns a status string and fails unless it is OK
+func (l *LoopiaProvider) callStatus(ctx context.Context, method string, params ...interface{}) error {
+	result, err := l.call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+
+	status, ok := result.(string)
+	if !ok {
+		return fmt.Errorf("unexpected response of type %T", result)
+	}
+	if status != loopiaStatusOK {
+		return fmt.Errorf("API error: %s", status)
+	}
+
+	return nil
+}
+
+// call performs an XML-RPC method call, prepending the API credentials to params
+func (l *LoopiaProvider) call(ctx context.Context, method string, params ...interface{}) (interface{}, error) {
+	payload, err := marshalXMLRPCCall(method, append([]interface{}{l.config.Username, l.config.Password}, params...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/xml")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			l.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.NewHTTPError(resp.StatusCode, l.endpoint, fmt.Errorf("%s", strings.TrimSpace(string(body))))
+	}
+
+	return unmarshalXMLRPCResponse(body)
+}
+
+// xmlrpc returns the record as the XML-RPC struct expected by addZoneRecord and updateZoneRecord
+func (r LoopiaRecord) xmlrpc() xmlrpcStruct {
+	return xmlrpcStruct{
+		{Name: "type", Value: r.Type},
+		{Name: "ttl", Value: r.TTL},
+		{Name: "priority", Value: r.Priority},
+		{Name: "rdata", Value: r.RData},
+		{Name: "record_id", Value: r.RecordID},
+	}
+}
+
+// loopiaRecordFromXMLRPC converts a record struct returned by getZoneRecords
+func loopiaRecordFromXMLRPC(fields map[string]interface{}) LoopiaRecord {
+	var record LoopiaRecord
+	record.Type, _ = fields["type"].(string)
+	record.TTL, _ = fields["ttl"].(int)
+	record.Priority, _ = fields["priority"].(int)
+	record.RData, _ = fields["rdata"].(string)
+	record.RecordID, _ = fields["record_id"].(int)
+	return record
+}
@@ -0,0 +1,320 @@
+package dns_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// The payloads below are recorded from the Loopia XML-RPC API. Requests are compared byte for byte,
+// so they also pin the XML-RPC encoding, including escaping of the password.
+
+const loopiaCredentials = `<param><value><string>user@loopiaapi</string></value></param>` +
+	`<param><value><string>p&amp;ss&lt;word&gt;</string></value></param>` +
+	`<param><value><string>example.se</string></value></param>`
+
+const loopiaGetHomeRequest = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+	`<methodCall><methodName>getZoneRecords</methodName><params>` + loopiaCredentials +
+	`<param><value><string>home</string></value></param></params></methodCall>`
+
+const loopiaGetHomeResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse>
+  <params>
+    <param>
+      <value>
+        <array>
+          <data>
+            <value>
+              <struct>
+                <member><name>type</name><value><string>A</string></value></member>
+                <member><name>ttl</name><value><int>3600</int></value></member>
+                <member><name>priority</name><value><int>0</int></value></member>
+                <member><name>rdata</name><value><string>192.0.2.1</string></value></member>
+                <member><name>record_id</name><value><int>12345</int></value></member>
+              </struct>
+            </value>
+            <value>
+              <struct>
+                <member><name>type</name><value>AAAA</value></member>
+                <member><name>ttl</name><value><i4>3600</i4></value></member>
+                <member><name>priority</name><value><i4>0</i4></value></member>
+                <member><name>rdata</name><value>2001:db8::1</value></member>
+                <member><name>record_id</name><value><i4>12346</i4></value></member>
+              </struct>
+            </value>
+          </data>
+        </array>
+      </value>
+    </param>
+  </params>
+</methodResponse>`
+
+const loopiaEmptyListResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse><params><param><value><array><data></data></array></value></param></params></methodResponse>`
+
+const loopiaOKResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse><params><param><value><string>OK</string></value></param></params></methodResponse>`
+
+// loopiaExchange is a recorded request and the response replayed for it.
+// An empty request matches any request body.
+type loopiaExchange struct {
+	request  string
+	response string
+	status   int
+}
+
+// fakeLoopia replays recorded exchanges in order
+type fakeLoopia struct {
+	t         *testing.T
+	mu        sync.Mutex
+	exchanges []loopiaExchange
+	calls     int
+}
+
+func (f *fakeLoopia) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	assert.Equal(f.t, http.MethodPost, r.Method)
+	assert.Equal(f.t, "text/xml", r.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(f.t, err)
+
+	if !assert.Less(f.t, f.calls, len(f.exchanges), "unexpected request: %s", body) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	exchange := f.exchanges[f.calls]
+	f.calls++
+
+	if exchange.request != "" {
+		assert.Equal(f.t, exchange.request, string(body))
+	}
+	if exchange.status != 0 {
+		w.WriteHeader(exchange.status)
+	}
+	_, _ = io.WriteString(w, exchange.response)
+}
+
+func newLoopiaTestProvider(t *testing.T, exchanges ...loopiaExchange) *dns.LoopiaProvider {
+	fake := &fakeLoopia{t: t, exchanges: exchanges}
+	server := httptest.NewServer(fake)
+	t.Cleanup(func() {
+		server.Close()
+		assert.Equal(t, len(fake.exchanges), fake.calls, "not all recorded exchanges were replayed")
+	})
+
+	return dns.NewLoopiaProvider(&config.LoopiaConfig{
+		Username: "user@loopiaapi",
+		Password: "p&ss<word>",
+		Domain:   "example.se",
+		Endpoint: server.URL,
+	}, zap.NewNop())
+}
+
+func TestLoopiaProvider_Name(t *testing.T) {
+	provider := dns.NewLoopiaProvider(&config.LoopiaConfig{
+		Username: "user@loopiaapi",
+		Password: "api-password",
+		Domain:   "example.se",
+	}, zap.NewNop())
+	assert.Equal(t, "loopia", provider.Name())
+
+	assert.Nil(t, dns.NewLoopiaProvider(nil, zap.NewNop()))
+}
+
+func TestLoopiaProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.se",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "loopia",
+	}
+
+	t.Run("updates existing record", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t,
+			loopiaExchange{request: loopiaGetHomeRequest, response: loopiaGetHomeResponse},
+			loopiaExchange{
+				request: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+					`<methodCall><methodName>updateZoneRecord</methodName><params>` + loopiaCredentials +
+					`<param><value><string>home</string></value></param>` +
+					`<param><value><struct>` +
+					`<member><name>type</name><value><string>A</string></value></member>` +
+					`<member><name>ttl</name><value><int>300</int></value></member>` +
+					`<member><name>priority</name><value><int>0</int></value></member>` +
+					`<member><name>rdata</name><value><string>203.0.113.10</string></value></member>` +
+					`<member><name>record_id</name><value><int>12345</int></value></member>` +
+					`</struct></value></param></params></methodCall>`,
+				response: loopiaOKResponse,
+			},
+		)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+	})
+
+	t.Run("creates record", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t,
+			loopiaExchange{request: loopiaGetHomeRequest, response: loopiaEmptyListResponse},
+			loopiaExchange{
+				request: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+					`<methodCall><methodName>addZoneRecord</methodName><params>` + loopiaCredentials +
+					`<param><value><string>home</string></value></param>` +
+					`<param><value><struct>` +
+					`<member><name>type</name><value><string>A</string></value></member>` +
+					`<member><name>ttl</name><value><int>300</int></value></member>` +
+					`<member><name>priority</name><value><int>0</int></value></member>` +
+					`<member><name>rdata</name><value><string>203.0.113.10</string></value></member>` +
+					`<member><name>record_id</name><value><int>0</int></value></member>` +
+					`</struct></value></param></params></methodCall>`,
+				response: loopiaOKResponse,
+			},
+		)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+	})
+
+	t.Run("zone apex", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t,
+			loopiaExchange{
+				request: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+					`<methodCall><methodName>getZoneRecords</methodName><params>` + loopiaCredentials +
+					`<param><value><string>@</string></value></param></params></methodCall>`,
+				response: loopiaEmptyListResponse,
+			},
+			loopiaExchange{response: loopiaOKResponse},
+		)
+
+		apex := record
+		apex.Name = "example.se"
+		require.NoError(t, provider.UpdateRecord(context.Background(), apex))
+	})
+
+	t.Run("record outside domain", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t)
+
+		outside := record
+		outside.Name = "home.example.com"
+		err := provider.UpdateRecord(context.Background(), outside)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not within zone example.se")
+	})
+}
+
+func TestLoopiaProvider_Errors(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:  "home.example.se",
+		Type:  "A",
+		Value: "203.0.113.10",
+		TTL:   300,
+	}
+
+	t.Run("status string in list response", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t, loopiaExchange{
+			response: `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse><params><param><value><array><data><value><string>AUTH_ERROR</string></value></data></array></value></param></params></methodResponse>`,
+		})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API error: AUTH_ERROR")
+		assert.NotContains(t, err.Error(), "p&ss<word>")
+	})
+
+	t.Run("status string from update", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t,
+			loopiaExchange{response: loopiaGetHomeResponse},
+			loopiaExchange{response: `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse><params><param><value><string>RATE_LIMITED</string></value></param></params></methodResponse>`},
+		)
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update record: API error: RATE_LIMITED")
+	})
+
+	t.Run("fault", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t, loopiaExchange{
+			response: `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse>
+  <fault>
+    <value>
+      <struct>
+        <member><name>faultCode</name><value><int>623</int></value></member>
+        <member><name>faultString</name><value><string>Method signature error: 42</string></value></member>
+      </struct>
+    </value>
+  </fault>
+</methodResponse>`,
+		})
+
+		found, err := provider.GetRecord(context.Background(), "home.example.se", "A")
+		require.Error(t, err)
+		assert.Nil(t, found)
+		assert.Contains(t, err.Error(), "XML-RPC fault 623: Method signature error: 42")
+	})
+
+	t.Run("HTTP error", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t, loopiaExchange{status: http.StatusServiceUnavailable, response: "maintenance"})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		provider := newLoopiaTestProvider(t, loopiaExchange{response: "<html>not xml-rpc</html>"})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to decode XML-RPC response")
+	})
+}
+
+func TestLoopiaProvider_GetAndDeleteRecord(t *testing.T) {
+	provider := newLoopiaTestProvider(t,
+		loopiaExchange{request: loopiaGetHomeRequest, response: loopiaGetHomeResponse},
+		loopiaExchange{request: loopiaGetHomeRequest, response: loopiaGetHomeResponse},
+		loopiaExchange{request: loopiaGetHomeRequest, response: loopiaGetHomeResponse},
+		loopiaExchange{
+			request: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<methodCall><methodName>removeZoneRecord</methodName><params>` + loopiaCredentials +
+				`<param><value><string>home</string></value></param>` +
+				`<param><value><int>12345</int></value></param></params></methodCall>`,
+			response: loopiaOKResponse,
+		},
+		loopiaExchange{request: loopiaGetHomeRequest, response: loopiaEmptyListResponse},
+	)
+	ctx := context.Background()
+
+	found, err := provider.GetRecord(ctx, "home.example.se", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "home.example.se", found.Name)
+	assert.Equal(t, "192.0.2.1", found.Value)
+	assert.Equal(t, 3600, found.TTL)
+	assert.Equal(t, "12345", found.Metadata["loopia_id"])
+
+	// Untyped values are strings and <i4> is an int
+	found, err = provider.GetRecord(ctx, "home.example.se", "AAAA")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "2001:db8::1", found.Value)
+	assert.Equal(t, "12346", found.Metadata["loopia_id"])
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.se", "A"))
+
+	// Deleting a record that does not exist is not an error
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.se", "A"))
+}
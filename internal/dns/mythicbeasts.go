@@ -0,0 +1,452 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const (
+	mythicBeastsDefaultEndpoint     = "https://api.mythic-beasts.com/dns/v2"
+	mythicBeastsDefaultAuthEndpoint = "https://auth.mythic-beasts.com/login"
+
+	// mythicBeastsTokenRenewMargin is how long before expiry an access token is replaced
+	mythicBeastsTokenRenewMargin = 30 * time.Second
+)
+
+// MythicBeastsProvider implements DNSProvider for the Mythic Beasts DNS API v2.
+// Requests are authorized with short-lived access tokens obtained with the OAuth2
+// client credentials grant, using the API key ID and secret.
+type MythicBeastsProvider struct {
+	config       *config.MythicBeastsConfig
+	client       *http.Client
+	endpoint     string
+	authEndpoint string
+	logger       *zap.Logger
+
+	tokenMu      sync.RWMutex
+	token        string
+	tokenExpires time.Time
+}
+
+// MythicBeastsRecord represents a DNS record in the Mythic Beasts API
+type MythicBeastsRecord struct {
+	Host string `json:"host"`
+	TTL  int    `json:"ttl"`
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// mythicBeastsRecords is the request and response body of the records endpoints
+type mythicBeastsRecords struct {
+	Records []MythicBeastsRecord `json:"records"`
+}
+
+// mythicBeastsChangeResponse represents the response of replacing or deleting records
+type mythicBeastsChangeResponse struct {
+	RecordsAdded   int    `json:"records_added"`
+	RecordsRemoved int    `json:"records_removed"`
+	Message        string `json:"message"`
+}
+
+// mythicBeastsTokenResponse represents the response of the OAuth2 token endpoint
+type mythicBeastsTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// mythicBeastsErrorResponse represents an error returned by the API or the token endpoint
+type mythicBeastsErrorResponse struct {
+	Error            string   `json:"error"`
+	ErrorDescription string   `json:"error_description"`
+	Errors           []string `json:"errors"`
+}
+
+// NewMythicBeastsProvider creates a new Mythic Beasts DNS provider
+func NewMythicBeastsProvider(cfg *config.MythicBeastsConfig, logger *zap.Logger) *MythicBeastsProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("mythicbeasts config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = mythicBeastsDefaultEndpoint
+	}
+
+	authEndpoint := cfg.AuthEndpoint
+	if authEndpoint == "" {
+		authEndpoint = mythicBeastsDefaultAuthEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &MythicBeastsProvider{
+		config:       cfg,
+		client:       client,
+		endpoint:     strings.TrimSuffix(endpoint, "/"),
+		authEndpoint: authEndpoint,
+		logger:       logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (m *MythicBeastsProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(m.client, proxy)
+}
+
+// Name returns the provider name
+func (m *MythicBeastsProvider) Name() string {
+	return "mythicbeasts"
+}
+
+// UpdateRecord updates or creates a DNS record.
+// The records of the name and type are replaced in a single request, so there is no window
+// in which the record is missing.
+func (m *MythicBeastsProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	m.logger.Info("updating DNS record",
+		zap.String("provider", "mythicbeasts"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("mythicbeasts", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(record.Name, m.config.Zone)
+	if err != nil {
+		return errors.NewDNSProviderError("mythicbeasts", record.Name, err)
+	}
+
+	body := mythicBeastsRecords{
+		Records: []MythicBeastsRecord{{
+			Host: host,
+			TTL:  record.TTL,
+			Type: record.Type,
+			Data: record.Value,
+		}},
+	}
+
+	var resp mythicBeastsChangeResponse
+	if err := m.doRequest(ctx, http.MethodPut, m.recordPath(host, record.Type), body, &resp); err != nil {
+		return errors.NewDNSProviderError("mythicbeasts", record.Name, fmt.Errorf("failed to replace records: %w", err))
+	}
+
+	if resp.RecordsRemoved == 0 {
+		m.logger.Info("DNS record created successfully",
+			zap.String("provider", "mythicbeasts"),
+			zap.String("record", record.Name),
+		)
+		return nil
+	}
+
+	m.logger.Info("DNS record updated successfully",
+		zap.String("provider", "mythicbeasts"),
+		zap.String("record", record.Name),
+		zap.Int("records_removed", resp.RecordsRemoved),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (m *MythicBeastsProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	m.logger.Debug("getting DNS record",
+		zap.String("provider", "mythicbeasts"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("mythicbeasts", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(name, m.config.Zone)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("mythicbeasts", name, err)
+	}
+
+	var resp mythicBeastsRecords
+	err = m.doRequest(ctx, http.MethodGet, m.recordPath(host, rtype), nil, &resp)
+	var httpErr *errors.HTTPError
+	if stderrors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, nil // Record not found
+	}
+	if err != nil {
+		return nil, errors.NewDNSProviderError("mythicbeasts", name, fmt.Errorf("failed to get records: %w", err))
+	}
+
+	if len(resp.Records) == 0 {
+		return nil, nil // Record not found
+	}
+
+	found := resp.Records[0]
+	if len(resp.Records) > 1 {
+		m.logger.Warn("multiple record values detected, using first value only",
+			zap.String("provider", "mythicbeasts"),
+			zap.String("record", name),
+			zap.Int("record_count", len(resp.Records)),
+			zap.String("used_value", found.Data),
+		)
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Host, m.config.Zone),
+		Type:     found.Type,
+		Value:    found.Data,
+		TTL:      found.TTL,
+		Provider: "mythicbeasts",
+		Metadata: map[string]string{
+			"host": found.Host,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (m *MythicBeastsProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	m.logger.Info("deleting DNS record",
+		zap.String("provider", "mythicbeasts"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("mythicbeasts", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeName(name, m.config.Zone)
+	if err != nil {
+		return errors.NewDNSProviderError("mythicbeasts", name, err)
+	}
+
+	var resp mythicBeastsChangeResponse
+	err = m.doRequest(ctx, http.MethodDelete, m.recordPath(host, recordType), nil, &resp)
+	var httpErr *errors.HTTPError
+	if err != nil && !(stderrors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound) {
+		return errors.NewDNSProviderError("mythicbeasts", name, fmt.Errorf("failed to delete records: %w", err))
+	}
+
+	if err != nil || resp.RecordsRemoved == 0 {
+		m.logger.Warn("record not found for deletion",
+			zap.String("provider", "mythicbeasts"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	m.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "mythicbeasts"),
+		zap.String("record", name),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (m *MythicBeastsProvider) Validate(ctx context.Context) error {
+	m.logger.Debug("validating mythicbeasts provider configuration")
+
+	// Test the credentials and access to the zone by listing its records
+	path := "/zones/" + url.PathEscape(m.config.Zone) + "/records"
+	if err := m.doRequest(ctx, http.MethodGet, path, nil, nil); err != nil {
+		return errors.NewDNSProviderError("mythicbeasts", "validation", fmt.Errorf("failed to list records of zone %s: %w", m.config.Zone, err))
+	}
+
+	m.logger.Info("mythicbeasts provider validation successful")
+	return nil
+}
+
+// recordPath returns the API path of the records with the given zone-relative host and type
+func (m *MythicBeastsProvider) recordPath(host, recordType string) string {
+	return "/zones/" + url.PathEscape(m.config.Zone) + "/records/" + url.PathEscape(host) + "/" + url.PathEscape(recordType)
+}
+
+// doRequest performs an API request with an access token. If the token is rejected,
+// for example because it expired early, a new token is requested and the request is retried once.
+func (m *MythicBeastsProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	token, err := m.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.doAuthorizedRequest(ctx, token, method, path, body, out)
+
+	var httpErr *errors.HTTPError
+	if stderrors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized {
+		m.logger.Debug("mythicbeasts access token rejected, requesting a new one")
+		m.invalidateToken(token)
+
+		token, err = m.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+		err = m.doAuthorizedRequest(ctx, token, method, path, body, out)
+	}
+
+	return err
+}
+
+// doAuthorizedRequest performs an API request with the given access token,
+// encoding body as JSON if non-nil and decoding the response into out if non-nil
+func (m *MythicBeastsProvider) doAuthorizedRequest(ctx context.Context, token, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.endpoint+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return m.send(req, out)
+}
+
+// accessToken returns the cached access token, requesting a new one if there is none or it is about to expire
+func (m *MythicBeastsProvider) accessToken(ctx context.Context) (string, error) {
+	// Take read lock to check the cached token
+	m.tokenMu.RLock()
+	if m.tokenValid() {
+		token := m.token
+		m.tokenMu.RUnlock()
+		return token, nil
+	}
+	m.tokenMu.RUnlock()
+
+	// Token is missing or expiring, acquire write lock
+	m.tokenMu.Lock()
+	defer m.tokenMu.Unlock()
+
+	// Re-check the token in case another request renewed it meanwhile
+	if m.tokenValid() {
+		return m.token, nil
+	}
+
+	requested := time.Now()
+	resp, err := m.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	m.token = resp.AccessToken
+	m.tokenExpires = requested.Add(time.Duration(resp.ExpiresIn) * time.Second)
+
+	m.logger.Debug("obtained mythicbeasts access token",
+		zap.Time("expires", m.tokenExpires),
+	)
+
+	return m.token, nil
+}
+
+// tokenValid reports whether the cached access token can still be used. The caller must hold tokenMu.
+func (m *MythicBeastsProvider) tokenValid() bool {
+	return m.token != "" && time.Now().Before(m.tokenExpires.Add(-mythicBeastsTokenRenewMargin))
+}
+
+// invalidateToken discards the access token unless it was already replaced
+func (m *MythicBeastsProvider) invalidateToken(token string) {
+	m.tokenMu.Lock()
+	defer m.tokenMu.Unlock()
+
+	if m.token == token {
+		m.token = ""
+	}
+}
+
+// requestToken obtains a new access token with the OAuth2 client credentials grant
+func (m *MythicBeastsProvider) requestToken(ctx context.Context) (*mythicBeastsTokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.authEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(m.config.KeyID, m.config.Secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var resp mythicBeastsTokenResponse
+	if err := m.send(req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to obtain access token: %w", err)
+	}
+
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("failed to obtain access token: empty token")
+	}
+	if resp.TokenType != "" && !strings.EqualFold(resp.TokenType, "bearer") {
+		return nil, fmt.Errorf("failed to obtain access token: unsupported token type %q", resp.TokenType)
+	}
+
+	return &resp, nil
+}
+
+// send performs a request and decodes the response into out if non-nil and the response has a body
+func (m *MythicBeastsProvider) send(req *http.Request, out interface{}) error {
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			m.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	requestURL := req.URL.String()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr mythicBeastsErrorResponse
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&apiErr); decodeErr == nil {
+			messages := apiErr.Errors
+			if apiErr.ErrorDescription != "" {
+				messages = append(messages, apiErr.ErrorDescription)
+			} else if apiErr.Error != "" {
+				messages = append(messages, apiErr.Error)
+			}
+			if len(messages) > 0 {
+				return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", strings.Join(messages, "; ")))
+			}
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
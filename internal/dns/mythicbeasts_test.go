@@ -0,0 +1,302 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeMythicBeasts is a minimal in-memory Mythic Beasts DNS API v2 and token endpoint for a single zone
+type fakeMythicBeasts struct {
+	t         *testing.T
+	mu        sync.Mutex
+	records   []dns.MythicBeastsRecord
+	tokens    map[string]bool
+	issued    int
+	authCalls int
+	expiresIn int
+	requests  []string
+}
+
+func newFakeMythicBeasts(t *testing.T, records ...dns.MythicBeastsRecord) *fakeMythicBeasts {
+	return &fakeMythicBeasts{
+		t:         t,
+		records:   records,
+		tokens:    make(map[string]bool),
+		expiresIn: 300,
+	}
+}
+
+func (f *fakeMythicBeasts) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	if r.URL.Path == "/login" {
+		f.authenticate(w, r)
+		return
+	}
+
+	if !f.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+		writeMythicBeastsError(w, http.StatusUnauthorized, "Invalid or expired token")
+		return
+	}
+
+	const zonePath = "/dns/v2/zones/example.com/records"
+	if r.URL.Path == zonePath && r.Method == http.MethodGet {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"records": f.records})
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, zonePath+"/"), "/")
+	if !strings.HasPrefix(r.URL.Path, zonePath+"/") || len(parts) != 2 {
+		writeMythicBeastsError(w, http.StatusNotFound, "Zone not found")
+		return
+	}
+	host, recordType := parts[0], parts[1]
+
+	var matching, others []dns.MythicBeastsRecord
+	for _, record := range f.records {
+		if record.Host == host && record.Type == recordType {
+			matching = append(matching, record)
+		} else {
+			others = append(others, record)
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"records": append([]dns.MythicBeastsRecord{}, matching...)})
+	case http.MethodPut:
+		var body struct {
+			Records []dns.MythicBeastsRecord `json:"records"`
+		}
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		for _, record := range body.Records {
+			assert.Equal(f.t, host, record.Host)
+			assert.Equal(f.t, recordType, record.Type)
+		}
+		f.records = append(others, body.Records...)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"records_added":   len(body.Records),
+			"records_removed": len(matching),
+			"message":         fmt.Sprintf("%d records added, %d records removed", len(body.Records), len(matching)),
+		})
+	case http.MethodDelete:
+		f.records = others
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"records_removed": len(matching)})
+	}
+}
+
+// authenticate checks the API key and issues a token
+func (f *fakeMythicBeasts) authenticate(w http.ResponseWriter, r *http.Request) {
+	f.authCalls++
+
+	keyID, secret, ok := r.BasicAuth()
+	require.NoError(f.t, r.ParseForm())
+	if !ok || keyID != "key-id" || secret != "key-secret" || r.PostForm.Get("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "Invalid API key"})
+		return
+	}
+
+	f.issued++
+	token := fmt.Sprintf("token-%d", f.issued)
+	f.tokens[token] = true
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"expires_in":   f.expiresIn,
+		"token_type":   "bearer",
+	})
+}
+
+// revokeTokens invalidates all issued tokens
+func (f *fakeMythicBeasts) revokeTokens() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = make(map[string]bool)
+}
+
+func writeMythicBeastsError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func newMythicBeastsTestProvider(t *testing.T, fake *fakeMythicBeasts, secret string) *dns.MythicBeastsProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewMythicBeastsProvider(&config.MythicBeastsConfig{
+		KeyID:        "key-id",
+		Secret:       secret,
+		Zone:         "example.com",
+		Endpoint:     server.URL + "/dns/v2",
+		AuthEndpoint: server.URL + "/login",
+	}, zap.NewNop())
+}
+
+func TestMythicBeastsProvider_Name(t *testing.T) {
+	provider := dns.NewMythicBeastsProvider(&config.MythicBeastsConfig{
+		KeyID:  "key-id",
+		Secret: "key-secret",
+		Zone:   "example.com",
+	}, zap.NewNop())
+	assert.Equal(t, "mythicbeasts", provider.Name())
+
+	assert.Nil(t, dns.NewMythicBeastsProvider(nil, zap.NewNop()))
+}
+
+func TestMythicBeastsProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "mythicbeasts",
+	}
+
+	t.Run("creates record", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t, dns.MythicBeastsRecord{Host: "www", TTL: 300, Type: "A", Data: "192.0.2.80"})
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.ElementsMatch(t, []dns.MythicBeastsRecord{
+			{Host: "www", TTL: 300, Type: "A", Data: "192.0.2.80"},
+			{Host: "home", TTL: 300, Type: "A", Data: "203.0.113.10"},
+		}, fake.records)
+	})
+
+	t.Run("replaces existing record", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t, dns.MythicBeastsRecord{Host: "home", TTL: 60, Type: "A", Data: "192.0.2.1"})
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, []dns.MythicBeastsRecord{{Host: "home", TTL: 300, Type: "A", Data: "203.0.113.10"}}, fake.records)
+		assert.Contains(t, fake.requests, "PUT /dns/v2/zones/example.com/records/home/A")
+	})
+
+	t.Run("zone apex", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		apex := record
+		apex.Name = "example.com"
+		require.NoError(t, provider.UpdateRecord(context.Background(), apex))
+		assert.Equal(t, []dns.MythicBeastsRecord{{Host: "@", TTL: 300, Type: "A", Data: "203.0.113.10"}}, fake.records)
+	})
+
+	t.Run("record outside zone", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		outside := record
+		outside.Name = "home.example.org"
+		err := provider.UpdateRecord(context.Background(), outside)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not within zone example.com")
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestMythicBeastsProvider_AccessToken(t *testing.T) {
+	t.Run("token is reused until it expires", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		for i := 0; i < 3; i++ {
+			_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, fake.issued)
+	})
+
+	t.Run("token about to expire is renewed", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		fake.expiresIn = 10 // Within the renewal margin
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		for i := 0; i < 2; i++ {
+			_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, fake.issued)
+	})
+
+	t.Run("concurrent requests share one token", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, fake.issued)
+	})
+
+	t.Run("rejected token is replaced", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+
+		require.NoError(t, provider.Validate(context.Background()))
+		fake.revokeTokens()
+
+		require.NoError(t, provider.Validate(context.Background()))
+		assert.Equal(t, 2, fake.issued)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		fake := newFakeMythicBeasts(t)
+		provider := newMythicBeastsTestProvider(t, fake, "wrong-secret")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid API key")
+		assert.NotContains(t, err.Error(), "wrong-secret")
+		assert.Equal(t, 1, fake.authCalls)
+	})
+}
+
+func TestMythicBeastsProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := newFakeMythicBeasts(t,
+		dns.MythicBeastsRecord{Host: "home", TTL: 120, Type: "A", Data: "192.0.2.1"},
+		dns.MythicBeastsRecord{Host: "home", TTL: 120, Type: "AAAA", Data: "2001:db8::1"},
+	)
+	provider := newMythicBeastsTestProvider(t, fake, "key-secret")
+	ctx := context.Background()
+
+	found, err := provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "home.example.com", found.Name)
+	assert.Equal(t, "192.0.2.1", found.Value)
+	assert.Equal(t, 120, found.TTL)
+	assert.Equal(t, "home", found.Metadata["host"])
+
+	missing, err := provider.GetRecord(ctx, "other.example.com", "A")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+	assert.Equal(t, []dns.MythicBeastsRecord{{Host: "home", TTL: 120, Type: "AAAA", Data: "2001:db8::1"}}, fake.records)
+
+	// Deleting a record that does not exist is not an error
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+}
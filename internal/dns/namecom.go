@@ -0,0 +1,360 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const (
+	namecomDefaultEndpoint = "https://api.name.com"
+
+	// namecomMinTTL is the lowest TTL accepted by Name.com
+	namecomMinTTL = 300
+
+	// namecomPageSize is the number of records requested per page when listing records
+	namecomPageSize = 1000
+)
+
+// NamecomProvider implements DNSProvider for the Name.com v4 API
+type NamecomProvider struct {
+	config   *config.NamecomConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+}
+
+// NamecomRecord represents a DNS record in the Name.com API
+type NamecomRecord struct {
+	ID         int    `json:"id,omitempty"`
+	DomainName string `json:"domainName,omitempty"`
+	Host       string `json:"host"`
+	FQDN       string `json:"fqdn,omitempty"`
+	Type       string `json:"type"`
+	Answer     string `json:"answer"`
+	TTL        int    `json:"ttl,omitempty"`
+	Priority   int    `json:"priority,omitempty"`
+}
+
+// namecomListResponse represents the response of the list records endpoint
+type namecomListResponse struct {
+	Records  []NamecomRecord `json:"records"`
+	NextPage int             `json:"nextPage"`
+	LastPage int             `json:"lastPage"`
+}
+
+// namecomErrorResponse represents an error returned by the Name.com API
+type namecomErrorResponse struct {
+	Message string `json:"message"`
+	Details string `json:"details"`
+}
+
+// NewNamecomProvider creates a new Name.com DNS provider
+func NewNamecomProvider(cfg *config.NamecomConfig, logger *zap.Logger) *NamecomProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("namecom config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = namecomDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &NamecomProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (n *NamecomProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(n.client, proxy)
+}
+
+// Name returns the provider name
+func (n *NamecomProvider) Name() string {
+	return "namecom"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (n *NamecomProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	n.logger.Info("updating DNS record",
+		zap.String("provider", "namecom"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("namecom", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeNameWithApex(record.Name, n.config.Domain, apexEmpty)
+	if err != nil {
+		return errors.NewDNSProviderError("namecom", record.Name, err)
+	}
+
+	ttl := record.TTL
+	if ttl < namecomMinTTL {
+		n.logger.Debug("TTL below Name.com minimum, using minimum",
+			zap.String("record", record.Name),
+			zap.Int("ttl", ttl),
+			zap.Int("min_ttl", namecomMinTTL),
+		)
+		ttl = namecomMinTTL
+	}
+
+	existing, err := n.findRecord(ctx, host, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("namecom", record.Name, err)
+	}
+
+	body := NamecomRecord{
+		Host:   host,
+		Type:   record.Type,
+		Answer: record.Value,
+		TTL:    ttl,
+	}
+
+	if existing != nil {
+		path := n.recordsPath() + "/" + strconv.Itoa(existing.ID)
+		if err := n.doRequest(ctx, http.MethodPut, path, body, nil); err != nil {
+			return errors.NewDNSProviderError("namecom", record.Name, fmt.Errorf("failed to update record: %w", err))
+		}
+
+		n.logger.Info("DNS record updated successfully",
+			zap.String("provider", "namecom"),
+			zap.String("record", record.Name),
+			zap.Int("record_id", existing.ID),
+		)
+		return nil
+	}
+
+	var created NamecomRecord
+	if err := n.doRequest(ctx, http.MethodPost, n.recordsPath(), body, &created); err != nil {
+		return errors.NewDNSProviderError("namecom", record.Name, fmt.Errorf("failed to create record: %w", err))
+	}
+
+	n.logger.Info("DNS record created successfully",
+		zap.String("provider", "namecom"),
+		zap.String("record", record.Name),
+		zap.Int("record_id", created.ID),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (n *NamecomProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	n.logger.Debug("getting DNS record",
+		zap.String("provider", "namecom"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("namecom", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeNameWithApex(name, n.config.Domain, apexEmpty)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("namecom", name, err)
+	}
+
+	found, err := n.findRecord(ctx, host, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("namecom", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Host, n.config.Domain),
+		Type:     found.Type,
+		Value:    found.Answer,
+		TTL:      found.TTL,
+		Provider: "namecom",
+		Metadata: map[string]string{
+			"namecom_id": strconv.Itoa(found.ID),
+			"host":       found.Host,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (n *NamecomProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	n.logger.Info("deleting DNS record",
+		zap.String("provider", "namecom"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("namecom", name, fmt.Errorf("empty record type"))
+	}
+
+	host, err := relativeNameWithApex(name, n.config.Domain, apexEmpty)
+	if err != nil {
+		return errors.NewDNSProviderError("namecom", name, err)
+	}
+
+	existing, err := n.findRecord(ctx, host, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("namecom", name, err)
+	}
+
+	if existing == nil {
+		n.logger.Warn("record not found for deletion",
+			zap.String("provider", "namecom"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	path := n.recordsPath() + "/" + strconv.Itoa(existing.ID)
+	if err := n.doRequest(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return errors.NewDNSProviderError("namecom", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	n.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "namecom"),
+		zap.String("record", name),
+		zap.Int("record_id", existing.ID),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (n *NamecomProvider) Validate(ctx context.Context) error {
+	n.logger.Debug("validating namecom provider configuration")
+
+	// Test API access by fetching the domain
+	path := "/v4/domains/" + url.PathEscape(n.config.Domain)
+	if err := n.doRequest(ctx, http.MethodGet, path, nil, nil); err != nil {
+		return errors.NewDNSProviderError("namecom", "validation", fmt.Errorf("failed to get domain %s: %w", n.config.Domain, err))
+	}
+
+	n.logger.Info("namecom provider validation successful")
+	return nil
+}
+
+// recordsPath returns the API path of the domain's records collection
+func (n *NamecomProvider) recordsPath() string {
+	return "/v4/domains/" + url.PathEscape(n.config.Domain) + "/records"
+}
+
+// findRecord finds a record by zone-relative host and type
+func (n *NamecomProvider) findRecord(ctx context.Context, host, recordType string) (*NamecomRecord, error) {
+	records, err := n.listRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if strings.EqualFold(record.Host, host) && record.Type == recordType {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// listRecords lists all DNS records for the domain, following pagination
+func (n *NamecomProvider) listRecords(ctx context.Context) ([]NamecomRecord, error) {
+	var records []NamecomRecord
+
+	for page := 1; page > 0; {
+		var resp namecomListResponse
+		path := fmt.Sprintf("%s?page=%d&perPage=%d", n.recordsPath(), page, namecomPageSize)
+		if err := n.doRequest(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list DNS records: %w", err)
+		}
+
+		records = append(records, resp.Records...)
+
+		// nextPage is omitted on the last page
+		if resp.NextPage <= page {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	return records, nil
+}
+
+// doRequest performs an authenticated API request, encoding body as JSON if non-nil
+// and decoding the response into out if non-nil
+func (n *NamecomProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	requestURL := n.endpoint + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(n.config.Username, n.config.APIToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			n.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr namecomErrorResponse
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&apiErr); decodeErr == nil && apiErr.Message != "" {
+			return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", strings.TrimSpace(apiErr.Message+" "+apiErr.Details)))
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeNamecom is a minimal in-memory Name.com v4 API for a single domain
+type fakeNamecom struct {
+	t        *testing.T
+	mu       sync.Mutex
+	records  map[int]dns.NamecomRecord
+	nextID   int
+	perPage  int
+	requests []string
+}
+
+func newFakeNamecom(t *testing.T, records ...dns.NamecomRecord) *fakeNamecom {
+	f := &fakeNamecom{t: t, records: make(map[int]dns.NamecomRecord), nextID: 100, perPage: 1}
+	for _, record := range records {
+		f.records[record.ID] = record
+	}
+	return f
+}
+
+func (f *fakeNamecom) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	username, token, ok := r.BasicAuth()
+	if !ok || username != "testuser" || token != "test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "Unauthenticated"})
+		return
+	}
+
+	const domainPath = "/v4/domains/example.com"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == domainPath:
+		_ = json.NewEncoder(w).Encode(map[string]string{"domainName": "example.com"})
+	case r.Method == http.MethodGet && r.URL.Path == domainPath+"/records":
+		f.listRecords(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == domainPath+"/records":
+		var record dns.NamecomRecord
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+		record.ID = f.nextID
+		f.nextID++
+		f.records[record.ID] = record
+		_ = json.NewEncoder(w).Encode(record)
+	case strings.HasPrefix(r.URL.Path, domainPath+"/records/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, domainPath+"/records/"))
+		require.NoError(f.t, err)
+		if _, exists := f.records[id]; !exists {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			var record dns.NamecomRecord
+			require.NoError(f.t, json.NewDecoder(r.Body).Decode(&record))
+			record.ID = id
+			f.records[id] = record
+			_ = json.NewEncoder(w).Encode(record)
+		case http.MethodDelete:
+			delete(f.records, id)
+			_, _ = w.Write([]byte("{}"))
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// listRecords serves one page of records ordered by ID
+func (f *fakeNamecom) listRecords(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	var ids []int
+	for id := range f.records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	lastPage := (len(ids) + f.perPage - 1) / f.perPage
+	resp := map[string]interface{}{"records": []dns.NamecomRecord{}, "lastPage": lastPage}
+	var pageRecords []dns.NamecomRecord
+	for i := (page - 1) * f.perPage; i < len(ids) && i < page*f.perPage; i++ {
+		pageRecords = append(pageRecords, f.records[ids[i]])
+	}
+	if pageRecords != nil {
+		resp["records"] = pageRecords
+	}
+	if page < lastPage {
+		resp["nextPage"] = page + 1
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newNamecomTestProvider(t *testing.T, fake *fakeNamecom, token string) *dns.NamecomProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewNamecomProvider(&config.NamecomConfig{
+		Username: "testuser",
+		APIToken: token,
+		Domain:   "example.com",
+		Endpoint: server.URL,
+	}, zap.NewNop())
+}
+
+func TestNamecomProvider_Name(t *testing.T) {
+	provider := dns.NewNamecomProvider(&config.NamecomConfig{
+		Username: "testuser",
+		APIToken: "test-token",
+		Domain:   "example.com",
+	}, zap.NewNop())
+
+	var _ interfaces.DNSProvider = provider
+	assert.Equal(t, "namecom", provider.Name())
+	assert.Nil(t, dns.NewNamecomProvider(nil, zap.NewNop()))
+}
+
+func TestNamecomProvider_UpdateRecord(t *testing.T) {
+	t.Run("updates existing apex record found on a later page", func(t *testing.T) {
+		fake := newFakeNamecom(t,
+			dns.NamecomRecord{ID: 1, Host: "www", Type: "A", Answer: "192.0.2.1", TTL: 300},
+			dns.NamecomRecord{ID: 2, Host: "", Type: "A", Answer: "192.0.2.2", TTL: 300},
+		)
+		provider := newNamecomTestProvider(t, fake, "test-token")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   600,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.10", fake.records[2].Answer)
+		assert.Equal(t, "", fake.records[2].Host)
+		assert.Equal(t, 600, fake.records[2].TTL)
+		assert.Contains(t, fake.requests, "PUT /v4/domains/example.com/records/2")
+	})
+
+	t.Run("creates missing record with minimum TTL", func(t *testing.T) {
+		fake := newFakeNamecom(t)
+		provider := newNamecomTestProvider(t, fake, "test-token")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.com",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   60,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, fake.records, 1)
+		created := fake.records[100]
+		assert.Equal(t, "home", created.Host)
+		assert.Equal(t, "203.0.113.10", created.Answer)
+		assert.Equal(t, 300, created.TTL)
+	})
+
+	t.Run("rejects record outside the domain", func(t *testing.T) {
+		fake := newFakeNamecom(t)
+		provider := newNamecomTestProvider(t, fake, "test-token")
+
+		err := provider.UpdateRecord(context.Background(), interfaces.DNSRecord{
+			Name:  "home.example.org",
+			Type:  "A",
+			Value: "203.0.113.10",
+			TTL:   300,
+		})
+		assert.Error(t, err)
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestNamecomProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := newFakeNamecom(t,
+		dns.NamecomRecord{ID: 1, Host: "home", Type: "A", Answer: "192.0.2.1", TTL: 300},
+		dns.NamecomRecord{ID: 2, Host: "home", Type: "AAAA", Answer: "2001:db8::1", TTL: 300},
+	)
+	provider := newNamecomTestProvider(t, fake, "test-token")
+
+	record, err := provider.GetRecord(context.Background(), "home.example.com", "AAAA")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "home.example.com", record.Name)
+	assert.Equal(t, "2001:db8::1", record.Value)
+	assert.Equal(t, "2", record.Metadata["namecom_id"])
+
+	missing, err := provider.GetRecord(context.Background(), "example.com", "A")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+	_, exists := fake.records[1]
+	assert.False(t, exists)
+	_, exists = fake.records[2]
+	assert.True(t, exists)
+
+	// Deleting a missing record is not an error
+	assert.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+}
+
+func TestNamecomProvider_Validate(t *testing.T) {
+	t.Run("valid credentials", func(t *testing.T) {
+		provider := newNamecomTestProvider(t, newFakeNamecom(t), "test-token")
+		assert.NoError(t, provider.Validate(context.Background()))
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		provider := newNamecomTestProvider(t, newFakeNamecom(t), "wrong-token")
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("HTTP %d", http.StatusUnauthorized))
+		assert.Contains(t, err.Error(), "Unauthenticated")
+	})
+}
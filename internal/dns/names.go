@@ -0,0 +1,46 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Zone apex markers used by provider APIs for zone-relative record names
+const (
+	apexAt    = "@" // Alibaba Cloud DNS, netcup, TransIP, Mythic Beasts, Domeneshop, EasyDNS, Loopia, Hetzner Console
+	apexEmpty = ""  // Name.com, Dynu
+	apexDot   = "." // Infomaniak
+)
+
+// relativeName converts a fully qualified record name to a name relative to the zone,
+// using "@" for the zone apex
+func relativeName(name, zone string) (string, error) {
+	return relativeNameWithApex(name, zone, apexAt)
+}
+
+// relativeNameWithApex converts a fully qualified record name to a name relative to the zone,
+// using the given marker for the zone apex
+func relativeNameWithApex(name, zone, apex string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	if name == zone || name == "@" {
+		return apex, nil
+	}
+
+	if strings.HasSuffix(name, "."+zone) {
+		return strings.TrimSuffix(name, "."+zone), nil
+	}
+
+	return "", fmt.Errorf("record %s is not within zone %s", name, zone)
+}
+
+// absoluteName converts a zone-relative record name to a fully qualified record name.
+// "@", "" and "." are all treated as the zone apex.
+func absoluteName(relative, zone string) string {
+	zone = strings.TrimSuffix(zone, ".")
+	if relative == apexAt || relative == apexEmpty || relative == apexDot {
+		return zone
+	}
+	return relative + "." + zone
+}
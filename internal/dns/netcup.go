@@ -0,0 +1,452 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const (
+	netcupDefaultEndpoint = "https://ccp.netcup.net/run/webservice/servers/endpoint.php?JSON"
+
+	// netcupStatusNoRecords is returned by infoDnsRecords when the zone has no records
+	netcupStatusNoRecords = 5029
+
+	// netcupLogoutTimeout bounds the logout, which still runs when the operation was cancelled
+	netcupLogoutTimeout = 10 * time.Second
+)
+
+// NetcupProvider implements DNSProvider for the netcup CCP DNS API
+type NetcupProvider struct {
+	config   *config.NetcupConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+}
+
+// NetcupDNSRecord represents a DNS record in the netcup CCP API
+type NetcupDNSRecord struct {
+	ID           string `json:"id,omitempty"`
+	Hostname     string `json:"hostname"`
+	Type         string `json:"type"`
+	Priority     string `json:"priority,omitempty"`
+	Destination  string `json:"destination"`
+	DeleteRecord bool   `json:"deleterecord"`
+	State        string `json:"state,omitempty"`
+}
+
+// netcupRequest represents a netcup JSON-RPC request
+type netcupRequest struct {
+	Action string      `json:"action"`
+	Param  interface{} `json:"param"`
+}
+
+// netcupResponse represents a netcup JSON-RPC response
+type netcupResponse struct {
+	ServerRequestID string          `json:"serverrequestid"`
+	Action          string          `json:"action"`
+	Status          string          `json:"status"`
+	StatusCode      int             `json:"statuscode"`
+	ShortMessage    string          `json:"shortmessage"`
+	LongMessage     string          `json:"longmessage"`
+	ResponseData    json.RawMessage `json:"responsedata"`
+}
+
+// netcupRecordSet is the dnsrecordset format used by infoDnsRecords and updateDnsRecords
+type netcupRecordSet struct {
+	DNSRecords []NetcupDNSRecord `json:"dnsrecords"`
+}
+
+// NewNetcupProvider creates a new netcup DNS provider
+func NewNetcupProvider(cfg *config.NetcupConfig, logger *zap.Logger) *NetcupProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("netcup config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = netcupDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &NetcupProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: endpoint,
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (n *NetcupProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(n.client, proxy)
+}
+
+// Name returns the provider name
+func (n *NetcupProvider) Name() string {
+	return "netcup"
+}
+
+// UpdateRecord updates or creates a DNS record
+func (n *NetcupProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	n.logger.Info("updating DNS record",
+		zap.String("provider", "netcup"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("netcup", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	hostname, err := relativeName(record.Name, n.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("netcup", record.Name, err)
+	}
+
+	// netcup only supports a zone-wide TTL
+	n.logger.Debug("netcup does not support per-record TTL, ignoring",
+		zap.String("record", record.Name),
+		zap.Int("ttl", record.TTL),
+	)
+
+	err = n.withSession(ctx, func(sessionID string) error {
+		existing, err := n.findRecord(ctx, sessionID, hostname, record.Type)
+		if err != nil {
+			return err
+		}
+
+		update := NetcupDNSRecord{
+			Hostname:    hostname,
+			Type:        record.Type,
+			Destination: record.Value,
+		}
+		if existing != nil {
+			update.ID = existing.ID
+			update.Priority = existing.Priority
+		}
+
+		if err := n.updateRecords(ctx, sessionID, []NetcupDNSRecord{update}); err != nil {
+			return err
+		}
+
+		if existing != nil {
+			n.logger.Info("DNS record updated successfully",
+				zap.String("provider", "netcup"),
+				zap.String("record", record.Name),
+				zap.String("record_id", existing.ID),
+			)
+		} else {
+			n.logger.Info("DNS record created successfully",
+				zap.String("provider", "netcup"),
+				zap.String("record", record.Name),
+			)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.NewDNSProviderError("netcup", record.Name, err)
+	}
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record
+func (n *NetcupProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	n.logger.Debug("getting DNS record",
+		zap.String("provider", "netcup"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("netcup", name, fmt.Errorf("empty record type"))
+	}
+
+	hostname, err := relativeName(name, n.config.Domain)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("netcup", name, err)
+	}
+
+	var found *NetcupDNSRecord
+	err = n.withSession(ctx, func(sessionID string) error {
+		found, err = n.findRecord(ctx, sessionID, hostname, rtype)
+		return err
+	})
+	if err != nil {
+		return nil, errors.NewDNSProviderError("netcup", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Hostname, n.config.Domain),
+		Type:     found.Type,
+		Value:    found.Destination,
+		Provider: "netcup",
+		Metadata: map[string]string{
+			"netcup_id": found.ID,
+			"hostname":  found.Hostname,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (n *NetcupProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	n.logger.Info("deleting DNS record",
+		zap.String("provider", "netcup"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("netcup", name, fmt.Errorf("empty record type"))
+	}
+
+	hostname, err := relativeName(name, n.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("netcup", name, err)
+	}
+
+	err = n.withSession(ctx, func(sessionID string) error {
+		existing, err := n.findRecord(ctx, sessionID, hostname, recordType)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			n.logger.Warn("record not found for deletion",
+				zap.String("provider", "netcup"),
+				zap.String("record", name),
+				zap.String("type", recordType),
+			)
+			return nil // Record doesn't exist, consider it deleted
+		}
+
+		existing.DeleteRecord = true
+		if err := n.updateRecords(ctx, sessionID, []NetcupDNSRecord{*existing}); err != nil {
+			return err
+		}
+
+		n.logger.Info("DNS record deleted successfully",
+			zap.String("provider", "netcup"),
+			zap.String("record", name),
+			zap.String("record_id", existing.ID),
+		)
+		return nil
+	})
+	if err != nil {
+		return errors.NewDNSProviderError("netcup", name, err)
+	}
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (n *NetcupProvider) Validate(ctx context.Context) error {
+	n.logger.Debug("validating netcup provider configuration")
+
+	// Test API access by logging in and listing the zone records
+	err := n.withSession(ctx, func(sessionID string) error {
+		_, err := n.listRecords(ctx, sessionID)
+		return err
+	})
+	if err != nil {
+		return errors.NewDNSProviderError("netcup", "validation", err)
+	}
+
+	n.logger.Info("netcup provider validation successful")
+	return nil
+}
+
+// withSession logs in, runs fn with the API session ID and logs out again.
+// A logout failure is logged but never replaces the error returned by fn. The session is logged out
+// even if ctx was cancelled during fn, since netcup limits the number of open sessions.
+func (n *NetcupProvider) withSession(ctx context.Context, fn func(sessionID string) error) error {
+	sessionID, err := n.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	fnErr := fn(sessionID)
+
+	logoutCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), netcupLogoutTimeout)
+	defer cancel()
+	if logoutErr := n.logout(logoutCtx, sessionID); logoutErr != nil {
+		n.logger.Warn("failed to log out of netcup API session",
+			zap.Error(logoutErr),
+			zap.NamedError("operation_error", fnErr),
+		)
+	}
+
+	return fnErr
+}
+
+// login creates a new API session
+func (n *NetcupProvider) login(ctx context.Context) (string, error) {
+	var data struct {
+		APISessionID string `json:"apisessionid"`
+	}
+
+	err := n.call(ctx, "login", map[string]string{
+		"customernumber": n.config.CustomerNumber,
+		"apikey":         n.config.APIKey,
+		"apipassword":    n.config.APIPassword,
+	}, &data)
+	if err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+
+	if data.APISessionID == "" {
+		return "", fmt.Errorf("login failed: empty session ID")
+	}
+
+	return data.APISessionID, nil
+}
+
+// logout ends an API session
+func (n *NetcupProvider) logout(ctx context.Context, sessionID string) error {
+	return n.call(ctx, "logout", map[string]string{
+		"customernumber": n.config.CustomerNumber,
+		"apikey":         n.config.APIKey,
+		"apisessionid":   sessionID,
+	}, nil)
+}
+
+// findRecord finds a record by relative hostname and type
+func (n *NetcupProvider) findRecord(ctx context.Context, sessionID, hostname, recordType string) (*NetcupDNSRecord, error) {
+	records, err := n.listRecords(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if strings.EqualFold(record.Hostname, hostname) && (recordType == "" || record.Type == recordType) {
+			rec := record
+			return &rec, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// listRecords lists all DNS records for the domain
+func (n *NetcupProvider) listRecords(ctx context.Context, sessionID string) ([]NetcupDNSRecord, error) {
+	var data netcupRecordSet
+
+	err := n.call(ctx, "infoDnsRecords", map[string]string{
+		"domainname":     n.config.Domain,
+		"customernumber": n.config.CustomerNumber,
+		"apikey":         n.config.APIKey,
+		"apisessionid":   sessionID,
+	}, &data)
+	if err != nil {
+		var apiErr *netcupAPIError
+		if stderrors.As(err, &apiErr) && apiErr.StatusCode == netcupStatusNoRecords {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	return data.DNSRecords, nil
+}
+
+// updateRecords pushes the given records in netcup's dnsrecordset format
+func (n *NetcupProvider) updateRecords(ctx context.Context, sessionID string, records []NetcupDNSRecord) error {
+	err := n.call(ctx, "updateDnsRecords", map[string]interface{}{
+		"domainname":     n.config.Domain,
+		"customernumber": n.config.CustomerNumber,
+		"apikey":         n.config.APIKey,
+		"apisessionid":   sessionID,
+		"dnsrecordset":   netcupRecordSet{DNSRecords: records},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update DNS records: %w", err)
+	}
+
+	return nil
+}
+
+// netcupAPIError represents an error status returned by the netcup API
+type netcupAPIError struct {
+	Action       string
+	StatusCode   int
+	ShortMessage string
+	LongMessage  string
+}
+
+func (e *netcupAPIError) Error() string {
+	return fmt.Sprintf("netcup API %s failed with status %d: %s %s", e.Action, e.StatusCode, e.ShortMessage, e.LongMessage)
+}
+
+// call performs a JSON-RPC call and decodes the response data into out if non-nil
+func (n *NetcupProvider) call(ctx context.Context, action string, param interface{}, out interface{}) error {
+	body, err := json.Marshal(netcupRequest{Action: action, Param: param})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			n.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.NewHTTPError(resp.StatusCode, n.endpoint, fmt.Errorf("unexpected status code"))
+	}
+
+	var apiResp netcupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if apiResp.Status != "success" {
+		return &netcupAPIError{
+			Action:       action,
+			StatusCode:   apiResp.StatusCode,
+			ShortMessage: apiResp.ShortMessage,
+			LongMessage:  apiResp.LongMessage,
+		}
+	}
+
+	if out == nil || len(apiResp.ResponseData) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(apiResp.ResponseData, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+
+	return nil
+}
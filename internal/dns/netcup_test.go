@@ -0,0 +1,224 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeNetcup is a minimal in-memory netcup CCP JSON-RPC API
+type fakeNetcup struct {
+	t            *testing.T
+	mu           sync.Mutex
+	records      []map[string]interface{}
+	actions      []string
+	updates      []map[string]interface{}
+	failUpdate   bool
+	failLogout   bool
+	noRecordsErr bool
+	onUpdate     func() // Called when updateDnsRecords is received
+}
+
+func (f *fakeNetcup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Action string                 `json:"action"`
+		Param  map[string]interface{} `json:"param"`
+	}
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&req))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, req.Action)
+
+	success := func(data interface{}) {
+		require.NoError(f.t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"action": req.Action, "status": "success", "statuscode": 2000, "responsedata": data,
+		}))
+	}
+	failure := func(code int, message string) {
+		require.NoError(f.t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"action": req.Action, "status": "error", "statuscode": code, "shortmessage": message, "responsedata": "",
+		}))
+	}
+
+	if req.Action != "login" && req.Param["apisessionid"] != "session-1" {
+		failure(4001, "invalid session")
+		return
+	}
+
+	switch req.Action {
+	case "login":
+		if req.Param["apipassword"] != "test-password" {
+			failure(4013, "login failed")
+			return
+		}
+		success(map[string]string{"apisessionid": "session-1"})
+	case "infoDnsRecords":
+		if f.noRecordsErr {
+			failure(5029, "no records")
+			return
+		}
+		success(map[string]interface{}{"dnsrecords": f.records})
+	case "updateDnsRecords":
+		if f.onUpdate != nil {
+			f.onUpdate()
+		}
+		if f.failUpdate {
+			failure(5028, "update rejected")
+			return
+		}
+		set := req.Param["dnsrecordset"].(map[string]interface{})
+		for _, record := range set["dnsrecords"].([]interface{}) {
+			f.updates = append(f.updates, record.(map[string]interface{}))
+		}
+		success(map[string]interface{}{"dnsrecords": f.records})
+	case "logout":
+		if f.failLogout {
+			failure(4001, "logout failed")
+			return
+		}
+		success("")
+	default:
+		f.t.Errorf("unexpected action %s", req.Action)
+	}
+}
+
+func newNetcupTestProvider(t *testing.T, fake *fakeNetcup, password string) *dns.NetcupProvider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return dns.NewNetcupProvider(&config.NetcupConfig{
+		CustomerNumber: "12345",
+		APIKey:         "test-key",
+		APIPassword:    password,
+		Domain:         "example.com",
+		Endpoint:       server.URL,
+	}, zap.NewNop())
+}
+
+func TestNetcupProvider_Name(t *testing.T) {
+	provider := dns.NewNetcupProvider(&config.NetcupConfig{
+		CustomerNumber: "12345",
+		APIKey:         "test-key",
+		APIPassword:    "test-password",
+		Domain:         "example.com",
+	}, zap.NewNop())
+
+	var _ interfaces.DNSProvider = provider
+	assert.Equal(t, "netcup", provider.Name())
+	assert.Nil(t, dns.NewNetcupProvider(nil, zap.NewNop()))
+}
+
+func TestNetcupProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:  "home.example.com",
+		Type:  "A",
+		Value: "203.0.113.10",
+		TTL:   300,
+	}
+
+	t.Run("updates existing record within a session", func(t *testing.T) {
+		fake := &fakeNetcup{t: t, records: []map[string]interface{}{
+			{"id": "1", "hostname": "home", "type": "A", "destination": "192.0.2.1", "deleterecord": false},
+		}}
+		provider := newNetcupTestProvider(t, fake, "test-password")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+
+		assert.Equal(t, []string{"login", "infoDnsRecords", "updateDnsRecords", "logout"}, fake.actions)
+		require.Len(t, fake.updates, 1)
+		assert.Equal(t, "1", fake.updates[0]["id"])
+		assert.Equal(t, "home", fake.updates[0]["hostname"])
+		assert.Equal(t, "203.0.113.10", fake.updates[0]["destination"])
+		assert.Equal(t, false, fake.updates[0]["deleterecord"])
+	})
+
+	t.Run("creates record in an empty zone", func(t *testing.T) {
+		fake := &fakeNetcup{t: t, noRecordsErr: true}
+		provider := newNetcupTestProvider(t, fake, "test-password")
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+
+		require.Len(t, fake.updates, 1)
+		_, hasID := fake.updates[0]["id"]
+		assert.False(t, hasID)
+	})
+
+	t.Run("logout failure does not mask update error", func(t *testing.T) {
+		fake := &fakeNetcup{t: t, failUpdate: true, failLogout: true}
+		provider := newNetcupTestProvider(t, fake, "test-password")
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "update rejected")
+		assert.NotContains(t, err.Error(), "logout failed")
+		assert.Equal(t, "logout", fake.actions[len(fake.actions)-1])
+	})
+
+	t.Run("logout failure after successful update is not an error", func(t *testing.T) {
+		fake := &fakeNetcup{t: t, failLogout: true}
+		provider := newNetcupTestProvider(t, fake, "test-password")
+
+		assert.NoError(t, provider.UpdateRecord(context.Background(), record))
+	})
+
+	t.Run("logs out after the update was cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		fake := &fakeNetcup{t: t, onUpdate: cancel}
+		provider := newNetcupTestProvider(t, fake, "test-password")
+
+		err := provider.UpdateRecord(ctx, record)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		assert.Equal(t, "logout", fake.actions[len(fake.actions)-1])
+	})
+
+	t.Run("login failure", func(t *testing.T) {
+		fake := &fakeNetcup{t: t}
+		provider := newNetcupTestProvider(t, fake, "wrong-password")
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "login failed")
+		assert.Equal(t, []string{"login"}, fake.actions)
+	})
+}
+
+func TestNetcupProvider_GetAndDeleteRecord(t *testing.T) {
+	fake := &fakeNetcup{t: t, records: []map[string]interface{}{
+		{"id": "1", "hostname": "@", "type": "A", "destination": "192.0.2.1", "deleterecord": false},
+		{"id": "2", "hostname": "home", "type": "A", "destination": "192.0.2.2", "deleterecord": false},
+	}}
+	provider := newNetcupTestProvider(t, fake, "test-password")
+
+	record, err := provider.GetRecord(context.Background(), "example.com", "A")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "example.com", record.Name)
+	assert.Equal(t, "192.0.2.1", record.Value)
+
+	missing, err := provider.GetRecord(context.Background(), "missing.example.com", "A")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+	require.Len(t, fake.updates, 1)
+	assert.Equal(t, "2", fake.updates[0]["id"])
+	assert.Equal(t, true, fake.updates[0]["deleterecord"])
+
+	assert.NoError(t, provider.Validate(context.Background()))
+}
@@ -0,0 +1,290 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/devhat/ipfailover/pkg/plugin"
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapio"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PluginProvider implements DNSProvider by calling an external plugin executable over gRPC,
+// see pkg/plugin. The plugin is started on first use and started again if it exits.
+type PluginProvider struct {
+	config *config.PluginConfig
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	client   *goplugin.Client
+	provider interfaces.DNSProvider
+	stderr   *zapio.Writer
+}
+
+// NewPluginProvider creates a new plugin DNS provider. The plugin is not started until the first call.
+func NewPluginProvider(cfg *config.PluginConfig, logger *zap.Logger) *PluginProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("plugin config is nil")
+		}
+		return nil
+	}
+
+	return &PluginProvider{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Name returns the provider name
+func (p *PluginProvider) Name() string {
+	return "plugin"
+}
+
+// UpdateRecord updates or creates a DNS record through the plugin
+func (p *PluginProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	p.logger.Info("updating DNS record",
+		zap.String("provider", "plugin"),
+		zap.String("plugin", p.config.Command),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	provider, err := p.dispense()
+	if err != nil {
+		return errors.NewDNSProviderError("plugin", record.Name, err)
+	}
+
+	if err := provider.UpdateRecord(ctx, record); err != nil {
+		return p.providerError(provider, record.Name, "failed to update record", err)
+	}
+
+	p.logger.Info("DNS record updated successfully",
+		zap.String("provider", "plugin"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// GetRecord retrieves an existing DNS record through the plugin
+func (p *PluginProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	p.logger.Debug("getting DNS record",
+		zap.String("provider", "plugin"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	provider, err := p.dispense()
+	if err != nil {
+		return nil, errors.NewDNSProviderError("plugin", name, err)
+	}
+
+	record, err := provider.GetRecord(ctx, name, rtype)
+	if err != nil {
+		return nil, p.providerError(provider, name, "failed to get record", err)
+	}
+	if record != nil {
+		record.Provider = "plugin"
+	}
+
+	return record, nil
+}
+
+// DeleteRecord deletes a DNS record through the plugin
+func (p *PluginProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	p.logger.Info("deleting DNS record",
+		zap.String("provider", "plugin"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	provider, err := p.dispense()
+	if err != nil {
+		return errors.NewDNSProviderError("plugin", name, err)
+	}
+
+	if err := provider.DeleteRecord(ctx, name, recordType); err != nil {
+		return p.providerError(provider, name, "failed to delete record", err)
+	}
+
+	p.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "plugin"),
+		zap.String("record", name),
+	)
+
+	return nil
+}
+
+// Validate starts the plugin and lets it check its configuration
+func (p *PluginProvider) Validate(ctx context.Context) error {
+	p.logger.Debug("validating plugin provider configuration")
+
+	provider, err := p.dispense()
+	if err != nil {
+		return errors.NewDNSProviderError("plugin", "validation", err)
+	}
+
+	if err := provider.Validate(ctx); err != nil {
+		return p.providerError(provider, "validation", "plugin validation failed", err)
+	}
+
+	p.logger.Info("plugin provider validation successful",
+		zap.String("plugin", p.config.Command),
+		zap.String("name", provider.Name()),
+	)
+	return nil
+}
+
+// Close stops the plugin process
+func (p *PluginProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopLocked()
+	return nil
+}
+
+// dispense returns the plugin's DNS provider, starting the plugin if it is not running
+func (p *PluginProvider) dispense() (interfaces.DNSProvider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil && !p.client.Exited() {
+		return p.provider, nil
+	}
+
+	if p.client != nil {
+		p.logger.Warn("DNS provider plugin exited, restarting",
+			zap.String("plugin", p.config.Command),
+		)
+		p.stopLocked()
+	}
+
+	cmd := exec.Command(p.config.Command)
+	cmd.Env = os.Environ()
+	for name, value := range p.config.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	stderr := &zapio.Writer{
+		Log:   p.logger.With(zap.String("plugin", p.config.Command)),
+		Level: zap.InfoLevel,
+	}
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  plugin.Handshake,
+		Plugins:          plugin.PluginSet(nil),
+		Cmd:              cmd,
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		Logger:           hclog.NewNullLogger(),
+		Stderr:           stderr,
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		_ = stderr.Close()
+		return nil, fmt.Errorf("failed to start plugin %s: %w", p.config.Command, err)
+	}
+
+	raw, err := rpcClient.Dispense(plugin.ProviderPluginName)
+	if err != nil {
+		client.Kill()
+		_ = stderr.Close()
+		return nil, fmt.Errorf("failed to dispense plugin %s: %w", p.config.Command, err)
+	}
+
+	provider, ok := raw.(interfaces.DNSProvider)
+	if !ok {
+		client.Kill()
+		_ = stderr.Close()
+		return nil, fmt.Errorf("plugin %s does not serve a DNS provider", p.config.Command)
+	}
+
+	p.client = client
+	p.provider = provider
+	p.stderr = stderr
+
+	p.logger.Info("started DNS provider plugin",
+		zap.String("plugin", p.config.Command),
+		zap.Int("protocol_version", client.NegotiatedVersion()),
+	)
+
+	return provider, nil
+}
+
+// stopLocked kills the plugin process, if any. p.mu must be held.
+func (p *PluginProvider) stopLocked() {
+	if p.client == nil {
+		return
+	}
+
+	p.client.Kill()
+	if err := p.stderr.Close(); err != nil {
+		p.logger.Debug("failed to flush plugin output", zap.Error(err))
+	}
+
+	p.client = nil
+	p.provider = nil
+	p.stderr = nil
+}
+
+// pluginStatusCodes maps the gRPC codes of requests a plugin rejected to HTTP status codes
+var pluginStatusCodes = map[codes.Code]int{
+	codes.InvalidArgument:  http.StatusBadRequest,
+	codes.Unauthenticated:  http.StatusUnauthorized,
+	codes.PermissionDenied: http.StatusForbidden,
+	codes.NotFound:         http.StatusNotFound,
+}
+
+// providerError translates a gRPC error of the plugin into a DNSProviderError. If the plugin
+// is unreachable, for example because it crashed, it is stopped so that the next call starts it again.
+// Requests the plugin rejected, see pluginStatusCodes, wrap an HTTPError so that they are not retried.
+func (p *PluginProvider) providerError(provider interfaces.DNSProvider, record, operation string, err error) error {
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Canceled:
+			err = fmt.Errorf("%s: %w", s.Message(), context.Canceled)
+		case codes.DeadlineExceeded:
+			err = fmt.Errorf("%s: %w", s.Message(), context.DeadlineExceeded)
+		case codes.Unavailable:
+			p.discard(provider)
+			err = fmt.Errorf("%s (%s)", s.Message(), s.Code())
+		case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.NotFound:
+			// Rejected requests fail the same way again, so they are reported as the HTTP
+			// status of the rejection, which is not retried
+			err = errors.NewHTTPError(pluginStatusCodes[s.Code()], "plugin:"+p.config.Command,
+				fmt.Errorf("%s (%s)", s.Message(), s.Code()))
+		default:
+			err = fmt.Errorf("%s (%s)", s.Message(), s.Code())
+		}
+	}
+	return errors.NewDNSProviderError("plugin", record, fmt.Errorf("%s: %w", operation, err))
+}
+
+// discard stops the plugin if it still serves provider, so that the next call starts it again
+func (p *PluginProvider) discard(provider interfaces.DNSProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != provider {
+		return // Already restarted
+	}
+
+	p.logger.Warn("DNS provider plugin is unavailable, restarting it on the next call",
+		zap.String("plugin", p.config.Command),
+	)
+	p.stopLocked()
+}
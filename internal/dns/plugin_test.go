@@ -0,0 +1,147 @@
+package dns_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// buildPlugin builds the plugin main package at dir into a temporary executable
+func buildPlugin(t *testing.T, dir string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping plugin build in short mode")
+	}
+
+	binary := filepath.Join(t.TempDir(), "plugin")
+	cmd := exec.Command("go", "build", "-o", binary, dir)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "building plugin: %s", output)
+	return binary
+}
+
+func newPluginTestProvider(t *testing.T, cfg *config.PluginConfig) *dns.PluginProvider {
+	require.NoError(t, cfg.Validate())
+
+	provider := dns.NewPluginProvider(cfg, zap.NewNop())
+	require.NotNil(t, provider)
+	t.Cleanup(func() {
+		assert.NoError(t, provider.Close())
+	})
+	return provider
+}
+
+func TestPluginProvider_Name(t *testing.T) {
+	provider := dns.NewPluginProvider(&config.PluginConfig{Command: "/usr/local/bin/plugin"}, zap.NewNop())
+	assert.Equal(t, "plugin", provider.Name())
+
+	assert.Nil(t, dns.NewPluginProvider(nil, zap.NewNop()))
+}
+
+func TestPluginProvider_HostsExample(t *testing.T) {
+	binary := buildPlugin(t, "../../examples/hosts-plugin")
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	provider := newPluginTestProvider(t, &config.PluginConfig{
+		Command: binary,
+		Env:     map[string]string{"HOSTS_FILE": hostsFile},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, provider.Validate(ctx))
+
+	found, err := provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	assert.Nil(t, found, "record does not exist yet")
+
+	record := interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "203.0.113.10", TTL: 300}
+	require.NoError(t, provider.UpdateRecord(ctx, record))
+	record.Value = "198.51.100.77"
+	require.NoError(t, provider.UpdateRecord(ctx, record))
+	require.NoError(t, provider.UpdateRecord(ctx, interfaces.DNSRecord{Name: "home.example.com", Type: "AAAA", Value: "2001:db8::1"}))
+
+	found, err = provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "198.51.100.77", found.Value)
+	assert.Equal(t, "plugin", found.Provider)
+
+	data, err := os.ReadFile(hostsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.77 home.example.com\n2001:db8::1 home.example.com\n", string(data))
+
+	require.NoError(t, provider.DeleteRecord(ctx, "home.example.com", "A"))
+	found, err = provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	t.Run("plugin errors", func(t *testing.T) {
+		err := provider.UpdateRecord(ctx, interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "2001:db8::1"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported record type A for address 2001:db8::1")
+		assert.True(t, errors.IsRetryableError(err), "plugin errors are DNS provider errors")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		provider := newPluginTestProvider(t, &config.PluginConfig{Command: binary})
+
+		err := provider.Validate(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HOSTS_FILE is not set")
+	})
+}
+
+func TestPluginProvider_RestartsAfterCrash(t *testing.T) {
+	binary := buildPlugin(t, "./testdata/crashplugin")
+	provider := newPluginTestProvider(t, &config.PluginConfig{Command: binary})
+	ctx := context.Background()
+
+	before, err := provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+
+	err = provider.UpdateRecord(ctx, interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "crash"})
+	require.Error(t, err)
+
+	after, err := provider.GetRecord(ctx, "home.example.com", "A")
+	require.NoError(t, err)
+	assert.NotEqual(t, before.Value, after.Value, "plugin was started again")
+}
+
+func TestPluginProvider_ErrorCodes(t *testing.T) {
+	binary := buildPlugin(t, "./testdata/crashplugin")
+	provider := newPluginTestProvider(t, &config.PluginConfig{Command: binary})
+	update := func(value string) error {
+		return provider.UpdateRecord(context.Background(), interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: value})
+	}
+
+	// A rejected request fails the same way again and is not retried
+	err := update("denied")
+	require.Error(t, err)
+	var httpErr *errors.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+	assert.Contains(t, err.Error(), "token may not edit the zone")
+	assert.False(t, errors.IsRetryableError(err))
+
+	err = update("busy")
+	require.Error(t, err)
+	assert.True(t, errors.IsRetryableError(err))
+}
+
+func TestPluginProvider_StartFailure(t *testing.T) {
+	provider := newPluginTestProvider(t, &config.PluginConfig{Command: filepath.Join(t.TempDir(), "missing")})
+
+	err := provider.Validate(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start plugin")
+}
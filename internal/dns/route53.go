@@ -3,47 +3,155 @@ package dns
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/devhat/ipfailover/internal/config"
 	"github.com/devhat/ipfailover/pkg/errors"
 	"github.com/devhat/ipfailover/pkg/interfaces"
 	"go.uber.org/zap"
 )
 
+// Defaults for waiting until changes are in sync, see Route53Config.WaitForSync
+const (
+	defaultRoute53SyncTimeout      = 2 * time.Minute
+	defaultRoute53SyncPollInterval = 5 * time.Second
+)
+
 // Route53Provider implements DNSProvider for AWS Route53
 type Route53Provider struct {
-	config *config.Route53Config
-	client *route53.Client
-	logger *zap.Logger
+	config           *config.Route53Config
+	client           *route53.Client
+	logger           *zap.Logger
+	syncPollInterval time.Duration
+	onSync           func(time.Duration)                   // Called with the time each change took to be in sync, may be nil
+	proxy            func(*http.Request) (*url.URL, error) // Set by SetProxy, nil uses the proxy environment variables
+}
+
+// Route53 credential modes, see Route53CredentialMode
+const (
+	Route53CredentialsStatic       = "static"
+	Route53CredentialsDefaultChain = "default_chain"
+	Route53CredentialsAssumeRole   = "assume_role"
+)
+
+// Metadata keys of alias records returned by GetRecord
+const (
+	Route53MetadataAliasTarget          = "alias_target_dns_name"
+	Route53MetadataAliasHostedZone      = "alias_hosted_zone_id"
+	Route53MetadataEvaluateTargetHealth = "evaluate_target_health"
+)
+
+// route53AssumeRoleSessionName is the session name of assumed roles without role_session_name, shown in CloudTrail
+const route53AssumeRoleSessionName = "ipfailover"
+
+// Route53CredentialMode describes the credentials a Route53 configuration uses: the static access key,
+// or else the default AWS credential chain (environment, shared config, instance or task role).
+// With role_arn these are the base credentials for assuming the role, e.g. "assume_role+default_chain".
+func Route53CredentialMode(cfg *config.Route53Config) string {
+	mode := Route53CredentialsDefaultChain
+	if cfg.AccessKeyID != "" {
+		mode = Route53CredentialsStatic
+	}
+	if cfg.RoleARN != "" {
+		mode = Route53CredentialsAssumeRole + "+" + mode
+	}
+	return mode
 }
 
 // NewRoute53Provider creates a new Route53 DNS provider
 func NewRoute53Provider(cfg *config.Route53Config, logger *zap.Logger) (*Route53Provider, error) {
-	// Create AWS config
-	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background(),
+	// Requests, including those assuming the role, go through the proxy set later by SetProxy
+	var provider *Route53Provider
+	httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(transport *http.Transport) {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if provider.proxy != nil {
+				return provider.proxy(req)
+			}
+			return http.ProxyFromEnvironment(req)
+		}
+	})
+
+	options := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(cfg.Region),
-		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+		awsconfig.WithHTTPClient(httpClient),
+	}
+	if cfg.AccessKeyID != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AccessKeyID,
 			cfg.SecretAccessKey,
 			"",
-		)),
-	)
+		)))
+	}
+
+	// Create AWS config
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background(), options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	// The role is assumed with the base credentials, and its temporary credentials are refreshed before they expire
+	if cfg.RoleARN != "" {
+		assumeRole := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsConfig), cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = route53AssumeRoleSessionName
+			if cfg.RoleSessionName != "" {
+				o.RoleSessionName = cfg.RoleSessionName
+			}
+			if cfg.ExternalID != "" {
+				o.ExternalID = aws.String(cfg.ExternalID)
+			}
+		})
+		awsConfig.Credentials = aws.NewCredentialsCache(assumeRole)
+	}
+
+	logger.Info("using AWS credentials",
+		zap.String("provider", "route53"),
+		zap.String("credential_mode", Route53CredentialMode(cfg)),
+		zap.String("role_arn", cfg.RoleARN),
+	)
+
 	client := route53.NewFromConfig(awsConfig)
 
+	provider = NewRoute53ProviderWithClient(cfg, client, logger)
+	return provider, nil
+}
+
+// NewRoute53ProviderWithClient creates a new Route53 DNS provider with a custom API client
+func NewRoute53ProviderWithClient(cfg *config.Route53Config, client *route53.Client, logger *zap.Logger) *Route53Provider {
 	return &Route53Provider{
-		config: cfg,
-		client: client,
-		logger: logger,
-	}, nil
+		config:           cfg,
+		client:           client,
+		logger:           logger,
+		syncPollInterval: defaultRoute53SyncPollInterval,
+	}
+}
+
+// WithSyncPollInterval sets how often the status of a change is polled while waiting for it to be in sync
+func (r *Route53Provider) WithSyncPollInterval(interval time.Duration) *Route53Provider {
+	r.syncPollInterval = interval
+	return r
+}
+
+// SetSyncObserver sets a function that is called with the time each change took to be in sync
+func (r *Route53Provider) SetSyncObserver(observe func(time.Duration)) {
+	r.onSync = observe
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable.
+// Providers created with NewRoute53ProviderWithClient keep the transport of their client.
+func (r *Route53Provider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	r.proxy = proxy
 }
 
 // Name returns the provider name
@@ -51,6 +159,40 @@ func (r *Route53Provider) Name() string {
 	return "route53"
 }
 
+// aliasTarget returns the alias target configured for the failover role of record, or nil
+// if the record is published as a plain record with its IP
+func (r *Route53Provider) aliasTarget(record interfaces.DNSRecord) *types.AliasTarget {
+	var dnsName, hostedZoneID string
+	switch record.Metadata[interfaces.MetadataRole] {
+	case interfaces.RolePrimary:
+		dnsName, hostedZoneID = r.config.AliasTargetDNSName, r.config.AliasHostedZoneID
+	case interfaces.RoleSecondary:
+		dnsName, hostedZoneID = r.config.SecondaryAliasTargetDNSName, r.config.SecondaryAliasHostedZoneID
+	}
+	if dnsName == "" {
+		return nil
+	}
+
+	return &types.AliasTarget{
+		DNSName:              aws.String(dnsName),
+		HostedZoneId:         aws.String(hostedZoneID),
+		EvaluateTargetHealth: r.config.EvaluateTargetHealth,
+	}
+}
+
+// aliasRole returns the failover role whose configured alias target is alias, or an empty
+// string if it is none of them
+func (r *Route53Provider) aliasRole(alias *types.AliasTarget) string {
+	dnsName := strings.TrimSuffix(aws.ToString(alias.DNSName), ".")
+	switch {
+	case r.config.AliasTargetDNSName != "" && strings.EqualFold(dnsName, strings.TrimSuffix(r.config.AliasTargetDNSName, ".")):
+		return interfaces.RolePrimary
+	case r.config.SecondaryAliasTargetDNSName != "" && strings.EqualFold(dnsName, strings.TrimSuffix(r.config.SecondaryAliasTargetDNSName, ".")):
+		return interfaces.RoleSecondary
+	}
+	return ""
+}
+
 // UpdateRecord updates or creates a DNS record
 func (r *Route53Provider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
 	r.logger.Info("updating DNS record",
@@ -86,6 +228,144 @@ func (r *Route53Provider) UpdateRecord(ctx context.Context, record interfaces.DN
 	return nil
 }
 
+// UpdateRecordIf updates or creates a DNS record only if it still matches expected.
+// The old record set is deleted and the new one created in a single change batch, which Route53
+// rejects as a whole if the record set no longer holds the values being deleted.
+func (r *Route53Provider) UpdateRecordIf(ctx context.Context, record interfaces.DNSRecord, expected *interfaces.DNSRecord) error {
+	r.logger.Info("updating DNS record",
+		zap.String("provider", "route53"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+		zap.Bool("conditional", true),
+	)
+
+	// Validate record type is not empty
+	if record.Type == "" {
+		return errors.NewDNSProviderError("route53", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	current, err := r.findRecord(ctx, record.Name, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("route53", record.Name, err)
+	}
+
+	if !route53RecordMatches(current, expected) {
+		return errors.NewConflictError("route53", record.Name, expectedValue(expected), route53RecordValue(current))
+	}
+
+	var changes []types.Change
+	if current != nil {
+		changes = append(changes, types.Change{
+			Action:            types.ChangeActionDelete,
+			ResourceRecordSet: current,
+		})
+	}
+	changes = append(changes, types.Change{
+		Action:            types.ChangeActionCreate,
+		ResourceRecordSet: r.newRecordSet(current, record),
+	})
+
+	input := &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.config.HostedZoneID),
+		ChangeBatch: &types.ChangeBatch{
+			Changes: changes,
+		},
+	}
+
+	output, err := r.client.ChangeResourceRecordSets(ctx, input)
+	if err != nil {
+		// A batch rejected because the record set changed after it was read is a conflict
+		after, findErr := r.findRecord(ctx, record.Name, record.Type)
+		if findErr == nil && !route53RecordMatches(after, expected) {
+			return errors.NewConflictError("route53", record.Name, expectedValue(expected), route53RecordValue(after))
+		}
+		return errors.NewDNSProviderError("route53", record.Name, fmt.Errorf("failed to apply conditional change batch: %w", err))
+	}
+
+	if err := r.waitForSync(ctx, record.Name, output.ChangeInfo); err != nil {
+		return errors.NewDNSProviderError("route53", record.Name, err)
+	}
+
+	r.logger.Info("DNS record updated successfully",
+		zap.String("provider", "route53"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// BatchKey identifies the hosted zone and credentials of the provider, see UpdateRecords
+func (r *Route53Provider) BatchKey() string {
+	return fmt.Sprintf("route53|%s|%s|%s|%s|%s",
+		r.config.Region, r.config.HostedZoneID, r.config.AccessKeyID, r.config.RoleARN, r.config.ExternalID)
+}
+
+// UpdateRecords updates or creates records in the hosted zone with a single change batch of UPSERT
+// changes, which Route53 applies atomically. Routing properties of existing record sets are preserved.
+func (r *Route53Provider) UpdateRecords(ctx context.Context, records []interfaces.DNSRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Type == "" {
+			return errors.NewDNSProviderError("route53", record.Name, fmt.Errorf("empty record type"))
+		}
+		names = append(names, record.Name)
+	}
+	batchName := strings.Join(names, ",")
+
+	r.logger.Info("updating DNS records in a single change batch",
+		zap.String("provider", "route53"),
+		zap.Strings("records", names),
+	)
+
+	existing, err := r.listRecords(ctx)
+	if err != nil {
+		return errors.NewDNSProviderError("route53", batchName, err)
+	}
+
+	changes := make([]types.Change, 0, len(records))
+	for _, record := range records {
+		var current *types.ResourceRecordSet
+		for i := range existing {
+			if r.matches(existing[i], record.Name, record.Type) {
+				current = &existing[i]
+				break
+			}
+		}
+		changes = append(changes, types.Change{
+			Action:            types.ChangeActionUpsert,
+			ResourceRecordSet: r.newRecordSet(current, record),
+		})
+	}
+
+	input := &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.config.HostedZoneID),
+		ChangeBatch: &types.ChangeBatch{
+			Changes: changes,
+		},
+	}
+
+	output, err := r.client.ChangeResourceRecordSets(ctx, input)
+	if err != nil {
+		return errors.NewDNSProviderError("route53", batchName, fmt.Errorf("failed to apply change batch: %w", err))
+	}
+
+	if err := r.waitForSync(ctx, batchName, output.ChangeInfo); err != nil {
+		return errors.NewDNSProviderError("route53", batchName, err)
+	}
+
+	r.logger.Info("DNS records updated successfully",
+		zap.String("provider", "route53"),
+		zap.Strings("records", names),
+	)
+
+	return nil
+}
+
 // GetRecord retrieves an existing DNS record
 func (r *Route53Provider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
 	r.logger.Debug("getting DNS record",
@@ -105,42 +385,72 @@ func (r *Route53Provider) GetRecord(ctx context.Context, name string, rtype stri
 	}
 
 	for _, record := range records {
-		// Skip records where record.Name is nil
-		if record.Name == nil {
-			continue
+		if r.matches(record, name, rtype) {
+			// Alias records report the DNS name of their target as value
+			result := r.dnsRecord(record, route53RecordValue(&record))
+			return &result, nil
 		}
+	}
 
-		if *record.Name == name && string(record.Type) == rtype {
-			var value string
-			// Only access record.ResourceRecords[0] if len > 0 and record.ResourceRecords[0].Value != nil
-			if len(record.ResourceRecords) > 0 && record.ResourceRecords[0].Value != nil {
-				value = *record.ResourceRecords[0].Value
-			}
+	return nil, nil // Record not found
+}
 
-			// Verify record.TTL != nil before converting to int and default to 0 if nil
-			var ttl int
-			if record.TTL != nil {
-				ttl = int(*record.TTL)
-			}
+// ListRecords returns all records of the hosted zone, see interfaces.RecordLister. Record sets
+// are listed once per value; alias record sets with the DNS name of their target as value.
+func (r *Route53Provider) ListRecords(ctx context.Context) ([]interfaces.DNSRecord, error) {
+	r.logger.Debug("listing DNS records",
+		zap.String("provider", "route53"),
+		zap.String("hosted_zone_id", r.config.HostedZoneID),
+	)
 
-			// Ensure Metadata map uses only non-nil values with fallbacks
-			metadata := make(map[string]string)
-			if record.Name != nil {
-				metadata["route53_id"] = *record.Name
-			}
+	recordSets, err := r.listRecords(ctx)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("route53", r.config.HostedZoneID, err)
+	}
+
+	var records []interfaces.DNSRecord
+	for _, recordSet := range recordSets {
+		if recordSet.AliasTarget != nil || len(recordSet.ResourceRecords) == 0 {
+			records = append(records, r.dnsRecord(recordSet, route53RecordValue(&recordSet)))
+			continue
+		}
+		for _, value := range recordSet.ResourceRecords {
+			records = append(records, r.dnsRecord(recordSet, aws.ToString(value.Value)))
+		}
+	}
+	return records, nil
+}
 
-			return &interfaces.DNSRecord{
-				Name:     *record.Name,
-				Type:     string(record.Type),
-				Value:    value,
-				TTL:      ttl,
-				Provider: "route53",
-				Metadata: metadata,
-			}, nil
+// dnsRecord converts a record set with one of its values
+func (r *Route53Provider) dnsRecord(recordSet types.ResourceRecordSet, value string) interfaces.DNSRecord {
+	// Verify recordSet.TTL != nil before converting to int and default to 0 if nil
+	var ttl int
+	if recordSet.TTL != nil {
+		ttl = int(*recordSet.TTL)
+	}
+
+	// Ensure Metadata map uses only non-nil values with fallbacks
+	metadata := make(map[string]string)
+	if recordSet.Name != nil {
+		metadata["route53_id"] = *recordSet.Name
+	}
+	if alias := recordSet.AliasTarget; alias != nil {
+		metadata[Route53MetadataAliasTarget] = aws.ToString(alias.DNSName)
+		metadata[Route53MetadataAliasHostedZone] = aws.ToString(alias.HostedZoneId)
+		metadata[Route53MetadataEvaluateTargetHealth] = strconv.FormatBool(alias.EvaluateTargetHealth)
+		if role := r.aliasRole(alias); role != "" {
+			metadata[interfaces.MetadataRole] = role
 		}
 	}
 
-	return nil, nil // Record not found
+	return interfaces.DNSRecord{
+		Name:     aws.ToString(recordSet.Name),
+		Type:     string(recordSet.Type),
+		Value:    value,
+		TTL:      ttl,
+		Provider: "route53",
+		Metadata: metadata,
+	}
 }
 
 // DeleteRecord deletes a DNS record
@@ -201,7 +511,7 @@ func (r *Route53Provider) findRecord(ctx context.Context, name, recordType strin
 	}
 
 	for _, record := range records {
-		if record.Name != nil && *record.Name == name && (recordType == "" || string(record.Type) == recordType) {
+		if r.matches(record, name, recordType) {
 			rec := record
 			return &rec, nil
 		}
@@ -240,16 +550,136 @@ func (r *Route53Provider) listRecords(ctx context.Context) ([]types.ResourceReco
 
 // updateExistingRecord updates an existing DNS record
 func (r *Route53Provider) updateExistingRecord(ctx context.Context, existingRecord *types.ResourceRecordSet, record interfaces.DNSRecord) error {
-	// Create new ResourceRecordSet preserving routing properties from existing record
+	change := types.Change{
+		Action:            types.ChangeActionUpsert,
+		ResourceRecordSet: r.newRecordSet(existingRecord, record),
+	}
+
+	input := &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.config.HostedZoneID),
+		ChangeBatch: &types.ChangeBatch{
+			Changes: []types.Change{change},
+		},
+	}
+
+	output, err := r.client.ChangeResourceRecordSets(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to update resource record set: %w", err)
+	}
+
+	if err := r.waitForSync(ctx, record.Name, output.ChangeInfo); err != nil {
+		return err
+	}
+
+	r.logger.Info("DNS record updated successfully",
+		zap.String("provider", "route53"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// waitForSync waits until a change is in sync on all Route53 authoritative servers, if wait_for_sync
+// is enabled. A change that is still pending after sync_timeout is returned as an error, which the
+// callers wrap in a retryable DNS provider error.
+func (r *Route53Provider) waitForSync(ctx context.Context, recordName string, change *types.ChangeInfo) error {
+	if !r.config.WaitForSync || change == nil || change.Id == nil {
+		return nil
+	}
+
+	timeout := r.config.SyncTimeout
+	if timeout <= 0 {
+		timeout = defaultRoute53SyncTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	ticker := time.NewTicker(r.syncPollInterval)
+	defer ticker.Stop()
+
+	notInSync := func() error {
+		return fmt.Errorf("change %s not in sync after %s: %w", *change.Id, time.Since(start).Round(time.Millisecond), ctx.Err())
+	}
+
+	for status := change.Status; status != types.ChangeStatusInsync; {
+		select {
+		case <-ctx.Done():
+			return notInSync()
+		case <-ticker.C:
+		}
+
+		output, err := r.client.GetChange(ctx, &route53.GetChangeInput{Id: change.Id})
+		if err != nil {
+			// A poll cut short by the deadline is a timeout, not a failure to get the status
+			if ctx.Err() != nil {
+				return notInSync()
+			}
+			return fmt.Errorf("failed to get status of change %s: %w", *change.Id, err)
+		}
+		status = output.ChangeInfo.Status
+	}
+
+	duration := time.Since(start)
+	r.logger.Debug("DNS change in sync",
+		zap.String("provider", "route53"),
+		zap.String("record", recordName),
+		zap.String("change_id", *change.Id),
+		zap.Duration("duration", duration),
+	)
+	if r.onSync != nil {
+		r.onSync(duration)
+	}
+
+	return nil
+}
+
+// matches reports whether recordSet is the record set of the record with name and type, empty
+// matching any type. With set_identifier only the record set with that identifier matches.
+func (r *Route53Provider) matches(recordSet types.ResourceRecordSet, name, recordType string) bool {
+	if recordSet.Name == nil || *recordSet.Name != name || (recordType != "" && string(recordSet.Type) != recordType) {
+		return false
+	}
+	return r.config.SetIdentifier == "" || aws.ToString(recordSet.SetIdentifier) == r.config.SetIdentifier
+}
+
+// newRecordSet creates the record set for record, as newRoute53RecordSet, with the configured
+// alias target and routing properties
+func (r *Route53Provider) newRecordSet(existingRecord *types.ResourceRecordSet, record interfaces.DNSRecord) *types.ResourceRecordSet {
+	recordSet := newRoute53RecordSet(existingRecord, record, r.aliasTarget(record))
+	if r.config.SetIdentifier != "" {
+		recordSet.SetIdentifier = aws.String(r.config.SetIdentifier)
+	}
+	if r.config.Failover != "" {
+		recordSet.Failover = types.ResourceRecordSetFailover(r.config.Failover)
+	}
+	if r.config.HealthCheckID != "" {
+		recordSet.HealthCheckId = aws.String(r.config.HealthCheckID)
+	}
+	return recordSet
+}
+
+// newRoute53RecordSet creates the record set for record, preserving routing properties from
+// the existing record set if there is one. With an alias target the record set is an alias
+// record, which has neither TTL nor values.
+func newRoute53RecordSet(existingRecord *types.ResourceRecordSet, record interfaces.DNSRecord, alias *types.AliasTarget) *types.ResourceRecordSet {
 	newRecordSet := &types.ResourceRecordSet{
 		Name: aws.String(record.Name),
 		Type: types.RRType(record.Type),
-		TTL:  aws.Int64(int64(record.TTL)),
-		ResourceRecords: []types.ResourceRecord{
+	}
+	if alias != nil {
+		newRecordSet.AliasTarget = alias
+	} else {
+		newRecordSet.TTL = aws.Int64(int64(record.TTL))
+		newRecordSet.ResourceRecords = []types.ResourceRecord{
 			{
 				Value: aws.String(record.Value),
 			},
-		},
+		}
+	}
+
+	if existingRecord == nil {
+		return newRecordSet
 	}
 
 	// Preserve routing properties from existing record
@@ -275,45 +705,40 @@ func (r *Route53Provider) updateExistingRecord(ctx context.Context, existingReco
 		newRecordSet.MultiValueAnswer = existingRecord.MultiValueAnswer
 	}
 
-	change := types.Change{
-		Action:            types.ChangeActionUpsert,
-		ResourceRecordSet: newRecordSet,
-	}
+	return newRecordSet
+}
 
-	input := &route53.ChangeResourceRecordSetsInput{
-		HostedZoneId: aws.String(r.config.HostedZoneID),
-		ChangeBatch: &types.ChangeBatch{
-			Changes: []types.Change{change},
-		},
+// route53RecordValue returns the first value of a record set, the DNS name of the target of an
+// alias record set, or an empty string if there is none
+func route53RecordValue(recordSet *types.ResourceRecordSet) string {
+	if recordSet == nil {
+		return ""
 	}
-
-	_, err := r.client.ChangeResourceRecordSets(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to update resource record set: %w", err)
+	if recordSet.AliasTarget != nil {
+		return aws.ToString(recordSet.AliasTarget.DNSName)
 	}
+	if len(recordSet.ResourceRecords) == 0 || recordSet.ResourceRecords[0].Value == nil {
+		return ""
+	}
+	return *recordSet.ResourceRecords[0].Value
+}
 
-	r.logger.Info("DNS record updated successfully",
-		zap.String("provider", "route53"),
-		zap.String("record", record.Name),
-	)
-
-	return nil
+// route53RecordMatches reports whether a record set, nil if it does not exist, holds the expected record
+func route53RecordMatches(recordSet *types.ResourceRecordSet, expected *interfaces.DNSRecord) bool {
+	if recordSet == nil || expected == nil {
+		return recordSet == nil && expected == nil
+	}
+	if route53RecordValue(recordSet) != expected.Value {
+		return false
+	}
+	return expected.TTL == 0 || (recordSet.TTL != nil && int(*recordSet.TTL) == expected.TTL)
 }
 
 // createNewRecord creates a new DNS record
 func (r *Route53Provider) createNewRecord(ctx context.Context, record interfaces.DNSRecord) error {
 	change := types.Change{
-		Action: types.ChangeActionCreate,
-		ResourceRecordSet: &types.ResourceRecordSet{
-			Name: aws.String(record.Name),
-			Type: types.RRType(record.Type),
-			TTL:  aws.Int64(int64(record.TTL)),
-			ResourceRecords: []types.ResourceRecord{
-				{
-					Value: aws.String(record.Value),
-				},
-			},
-		},
+		Action:            types.ChangeActionCreate,
+		ResourceRecordSet: r.newRecordSet(nil, record),
 	}
 
 	input := &route53.ChangeResourceRecordSetsInput{
@@ -323,11 +748,15 @@ func (r *Route53Provider) createNewRecord(ctx context.Context, record interfaces
 		},
 	}
 
-	_, err := r.client.ChangeResourceRecordSets(ctx, input)
+	output, err := r.client.ChangeResourceRecordSets(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to create resource record set: %w", err)
 	}
 
+	if err := r.waitForSync(ctx, record.Name, output.ChangeInfo); err != nil {
+		return err
+	}
+
 	r.logger.Info("DNS record created successfully",
 		zap.String("provider", "route53"),
 		zap.String("record", record.Name),
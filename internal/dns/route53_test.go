@@ -2,12 +2,24 @@ package dns_test
 
 import (
 	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/devhat/ipfailover/internal/config"
 	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/errors"
 	"github.com/devhat/ipfailover/pkg/interfaces"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -145,4 +157,736 @@ func TestRoute53Provider_ConfigurationValidation(t *testing.T) {
 		err := cfg.Validate()
 		assert.NoError(t, err)
 	})
+
+	t.Run("credentials are optional", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:       "us-east-1",
+			HostedZoneID: "test-zone",
+			RoleARN:      "arn:aws:iam::123456789012:role/dns-failover",
+			ExternalID:   "external-id",
+		}
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("access key without secret", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			AccessKeyID:  "test-key",
+			Region:       "us-east-1",
+			HostedZoneID: "test-zone",
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "access_key_id and secret_access_key must be set together")
+	})
+
+	t.Run("external ID without role", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:       "us-east-1",
+			HostedZoneID: "test-zone",
+			ExternalID:   "external-id",
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "external_id requires role_arn")
+	})
+
+	t.Run("role session name", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:          "us-east-1",
+			HostedZoneID:    "test-zone",
+			RoleSessionName: "ipfailover-edge-1",
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "role_session_name requires role_arn")
+
+		cfg.RoleARN = "arn:aws:iam::123456789012:role/dns-failover"
+		assert.NoError(t, cfg.Validate())
+
+		cfg.RoleSessionName = "edge 1"
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `role_session_name must be 2 to 64 letters, digits or +=,.@_- characters, got "edge 1"`)
+	})
+
+	t.Run("failover routing", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:       "us-east-1",
+			HostedZoneID: "test-zone",
+			Failover:     "PRIMARY",
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failover requires set_identifier")
+
+		cfg.SetIdentifier = "primary-site"
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Failover = "primary"
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `failover must be PRIMARY or SECONDARY, got "primary"`)
+	})
+
+	t.Run("negative sync timeout", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:       "us-east-1",
+			HostedZoneID: "test-zone",
+			WaitForSync:  true,
+			SyncTimeout:  -time.Second,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sync_timeout")
+	})
+
+	t.Run("alias target without hosted zone", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:             "us-east-1",
+			HostedZoneID:       "test-zone",
+			AliasTargetDNSName: "dualstack.primary-123456.us-east-1.elb.amazonaws.com.",
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "alias_target_dns_name and alias_hosted_zone_id must be set together")
+	})
+
+	t.Run("evaluate target health without alias", func(t *testing.T) {
+		cfg := &config.Route53Config{
+			Region:               "us-east-1",
+			HostedZoneID:         "test-zone",
+			EvaluateTargetHealth: true,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "evaluate_target_health requires")
+	})
+
+	t.Run("region is required", func(t *testing.T) {
+		cfg := &config.Route53Config{HostedZoneID: "test-zone"}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "region is required")
+	})
+}
+
+func TestRoute53Provider_CredentialModes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Route53Config
+		mode string
+	}{
+		{
+			name: "static access key",
+			cfg:  config.Route53Config{AccessKeyID: "test-key", SecretAccessKey: "test-secret"},
+			mode: "static",
+		},
+		{
+			name: "default credential chain",
+			cfg:  config.Route53Config{},
+			mode: "default_chain",
+		},
+		{
+			name: "role assumed with the default credential chain",
+			cfg:  config.Route53Config{RoleARN: "arn:aws:iam::123456789012:role/dns-failover", ExternalID: "external-id"},
+			mode: "assume_role+default_chain",
+		},
+		{
+			name: "role assumed with a static access key",
+			cfg:  config.Route53Config{AccessKeyID: "test-key", SecretAccessKey: "test-secret", RoleARN: "arn:aws:iam::123456789012:role/dns-failover"},
+			mode: "assume_role+static",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Region = "us-east-1"
+			cfg.HostedZoneID = "test-zone"
+			assert.Equal(t, tt.mode, dns.Route53CredentialMode(&cfg))
+
+			// Credentials are only resolved, and roles assumed, on the first API call
+			provider, err := dns.NewRoute53Provider(&cfg, zap.NewNop())
+			require.NoError(t, err)
+			assert.NotNil(t, provider)
+		})
+	}
+}
+
+// fakeRoute53RecordSet is a resource record set held by fakeRoute53
+type fakeRoute53RecordSet struct {
+	XMLName       xml.Name                `xml:"ResourceRecordSet"`
+	Name          string                  `xml:"Name"`
+	Type          string                  `xml:"Type"`
+	SetIdentifier string                  `xml:"SetIdentifier,omitempty"`
+	Failover      string                  `xml:"Failover,omitempty"`
+	TTL           int64                   `xml:"TTL,omitempty"`
+	Values        []string                `xml:"ResourceRecords>ResourceRecord>Value"`
+	Alias         *fakeRoute53AliasTarget `xml:"AliasTarget,omitempty"`
+	HealthCheckID string                  `xml:"HealthCheckId,omitempty"`
+}
+
+// key identifies the record set in fakeRoute53.recordSets
+func (r fakeRoute53RecordSet) key() string {
+	if r.SetIdentifier != "" {
+		return r.Name + " " + r.Type + " " + r.SetIdentifier
+	}
+	return r.Name + " " + r.Type
+}
+
+// MarshalXML writes the record set without a ResourceRecords element if it has no values, as
+// for alias record sets; the field tags alone would write an empty one
+func (r fakeRoute53RecordSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type resourceRecord struct {
+		Value string `xml:"Value"`
+	}
+	type resourceRecords struct {
+		Records []resourceRecord `xml:"ResourceRecord"`
+	}
+	wire := struct {
+		Name          string                  `xml:"Name"`
+		Type          string                  `xml:"Type"`
+		SetIdentifier string                  `xml:"SetIdentifier,omitempty"`
+		Failover      string                  `xml:"Failover,omitempty"`
+		TTL           int64                   `xml:"TTL,omitempty"`
+		Records       *resourceRecords        `xml:"ResourceRecords,omitempty"`
+		Alias         *fakeRoute53AliasTarget `xml:"AliasTarget,omitempty"`
+		HealthCheckID string                  `xml:"HealthCheckId,omitempty"`
+	}{Name: r.Name, Type: r.Type, SetIdentifier: r.SetIdentifier, Failover: r.Failover, TTL: r.TTL, Alias: r.Alias, HealthCheckID: r.HealthCheckID}
+	if len(r.Values) > 0 {
+		wire.Records = &resourceRecords{}
+		for _, value := range r.Values {
+			wire.Records.Records = append(wire.Records.Records, resourceRecord{Value: value})
+		}
+	}
+	return e.EncodeElement(wire, xml.StartElement{Name: xml.Name{Local: "ResourceRecordSet"}})
+}
+
+// fakeRoute53AliasTarget is the target of an alias record set
+type fakeRoute53AliasTarget struct {
+	HostedZoneID         string `xml:"HostedZoneId"`
+	DNSName              string `xml:"DNSName"`
+	EvaluateTargetHealth bool   `xml:"EvaluateTargetHealth"`
+}
+
+// fakeRoute53 is a minimal in-memory Route53 API for a single hosted zone.
+// Change batches are applied atomically and rejected if a DELETE does not match the current record set.
+type fakeRoute53 struct {
+	t            *testing.T
+	mu           sync.Mutex
+	recordSets   map[string]fakeRoute53RecordSet // "name type", followed by " set-identifier" if set -> record set
+	changes      int
+	beforeChange func(f *fakeRoute53) // Runs before a change batch is applied, with the lock held
+
+	// pendingPolls is the number of GetChange calls that report the change PENDING before it is INSYNC
+	pendingPolls int
+	changePolls  int
+}
+
+func newFakeRoute53(t *testing.T, recordSets ...fakeRoute53RecordSet) *fakeRoute53 {
+	f := &fakeRoute53{t: t, recordSets: make(map[string]fakeRoute53RecordSet)}
+	for _, recordSet := range recordSets {
+		f.recordSets[recordSet.key()] = recordSet
+	}
+	return f
+}
+
+func (f *fakeRoute53) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const rrsetPath = "/2013-04-01/hostedzone/Z123/rrset"
+	switch {
+	case r.Method == http.MethodGet && strings.TrimSuffix(r.URL.Path, "/") == rrsetPath:
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+		b.WriteString(`<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><ResourceRecordSets>`)
+		for _, recordSet := range f.recordSets {
+			data, err := xml.Marshal(recordSet)
+			require.NoError(f.t, err)
+			b.Write(data)
+		}
+		b.WriteString(`</ResourceRecordSets><IsTruncated>false</IsTruncated><MaxItems>100</MaxItems></ListResourceRecordSetsResponse>`)
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(b.String()))
+	case r.Method == http.MethodPost && strings.TrimSuffix(r.URL.Path, "/") == rrsetPath:
+		var request struct {
+			Changes []struct {
+				Action            string               `xml:"Action"`
+				ResourceRecordSet fakeRoute53RecordSet `xml:"ResourceRecordSet"`
+			} `xml:"ChangeBatch>Changes>Change"`
+		}
+		require.NoError(f.t, xml.NewDecoder(r.Body).Decode(&request))
+
+		if f.beforeChange != nil {
+			f.beforeChange(f)
+		}
+
+		// Apply the batch to a copy so that it is all or nothing
+		updated := make(map[string]fakeRoute53RecordSet, len(f.recordSets))
+		for key, recordSet := range f.recordSets {
+			updated[key] = recordSet
+		}
+		for _, change := range request.Changes {
+			recordSet := change.ResourceRecordSet
+			key := recordSet.key()
+			current, exists := updated[key]
+			switch change.Action {
+			case "DELETE":
+				if !exists || current.TTL != recordSet.TTL || fmt.Sprint(current.Values) != fmt.Sprint(recordSet.Values) || !reflect.DeepEqual(current.Alias, recordSet.Alias) {
+					f.invalidChangeBatch(w, fmt.Sprintf("Tried to delete resource record set [name='%s', type='%s'] but the values provided do not match the current values", recordSet.Name, recordSet.Type))
+					return
+				}
+				delete(updated, key)
+			case "CREATE":
+				if exists {
+					f.invalidChangeBatch(w, fmt.Sprintf("Tried to create resource record set [name='%s', type='%s'] but it already exists", recordSet.Name, recordSet.Type))
+					return
+				}
+				updated[key] = recordSet
+			case "UPSERT":
+				updated[key] = recordSet
+			}
+		}
+		f.recordSets = updated
+		f.changes++
+
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` +
+			`<ChangeResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">` +
+			`<ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status><SubmittedAt>2024-01-01T00:00:00Z</SubmittedAt></ChangeInfo>` +
+			`</ChangeResourceRecordSetsResponse>`))
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/change/C1":
+		f.changePolls++
+		status := "INSYNC"
+		if f.changePolls <= f.pendingPolls {
+			status = "PENDING"
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` +
+			`<GetChangeResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">` +
+			`<ChangeInfo><Id>/change/C1</Id><Status>` + status + `</Status><SubmittedAt>2024-01-01T00:00:00Z</SubmittedAt></ChangeInfo>` +
+			`</GetChangeResponse>`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// invalidChangeBatch writes the error Route53 returns for a rejected change batch
+func (f *fakeRoute53) invalidChangeBatch(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` +
+		`<InvalidChangeBatch xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><Messages><Message>` +
+		message + `</Message></Messages><RequestId>request-1</RequestId></InvalidChangeBatch>`))
+}
+
+func newRoute53TestProvider(t *testing.T, fake *fakeRoute53) *dns.Route53Provider {
+	return newRoute53TestProviderWithConfig(t, fake, &config.Route53Config{
+		Region:       "us-east-1",
+		HostedZoneID: "Z123",
+	})
+}
+
+func newRoute53TestProviderWithConfig(t *testing.T, fake *fakeRoute53, cfg *config.Route53Config) *dns.Route53Provider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := route53.New(route53.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+
+	return dns.NewRoute53ProviderWithClient(cfg, client, zap.NewNop())
+}
+
+func TestRoute53Provider_UpdateRecordIf(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com.",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "route53",
+	}
+	existing := fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", TTL: 300, Values: []string{"192.0.2.1"}}
+
+	t.Run("replaces unchanged record set", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		provider := newRoute53TestProvider(t, fake)
+
+		var _ interfaces.ConditionalUpdater = provider
+		expected, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+
+		require.NoError(t, provider.UpdateRecordIf(context.Background(), record, expected))
+		assert.Equal(t, []string{"203.0.113.10"}, fake.recordSets["home.example.com. A"].Values)
+		assert.Equal(t, 1, fake.changes)
+	})
+
+	t.Run("detects modification before the update", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		provider := newRoute53TestProvider(t, fake)
+
+		expected := &interfaces.DNSRecord{Name: record.Name, Type: "A", Value: "192.0.2.50", TTL: 300}
+
+		err := provider.UpdateRecordIf(context.Background(), record, expected)
+		var conflictErr *errors.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "192.0.2.50", conflictErr.Expected)
+		assert.Equal(t, "192.0.2.1", conflictErr.Actual)
+		assert.Zero(t, fake.changes)
+	})
+
+	t.Run("detects modification racing the change batch", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		provider := newRoute53TestProvider(t, fake)
+
+		expected, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+
+		// The record set is edited after the provider read it but before its change batch arrives
+		fake.beforeChange = func(f *fakeRoute53) {
+			f.recordSets["home.example.com. A"] = fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", TTL: 300, Values: []string{"198.51.100.99"}}
+			f.beforeChange = nil
+		}
+
+		err = provider.UpdateRecordIf(context.Background(), record, expected)
+		var conflictErr *errors.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "192.0.2.1", conflictErr.Expected)
+		assert.Equal(t, "198.51.100.99", conflictErr.Actual)
+		assert.Equal(t, []string{"198.51.100.99"}, fake.recordSets["home.example.com. A"].Values)
+		assert.Zero(t, fake.changes)
+	})
+
+	t.Run("creates missing record", func(t *testing.T) {
+		fake := newFakeRoute53(t)
+		provider := newRoute53TestProvider(t, fake)
+
+		require.NoError(t, provider.UpdateRecordIf(context.Background(), record, nil))
+		assert.Equal(t, []string{"203.0.113.10"}, fake.recordSets["home.example.com. A"].Values)
+	})
+}
+
+func TestRoute53Provider_WaitForSync(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com.",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "route53",
+	}
+	existing := fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", TTL: 300, Values: []string{"192.0.2.1"}}
+	waitConfig := func(timeout time.Duration) *config.Route53Config {
+		return &config.Route53Config{
+			Region:       "us-east-1",
+			HostedZoneID: "Z123",
+			WaitForSync:  true,
+			SyncTimeout:  timeout,
+		}
+	}
+
+	t.Run("polls until the change is in sync", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		fake.pendingPolls = 2
+		provider := newRoute53TestProviderWithConfig(t, fake, waitConfig(5*time.Second)).
+			WithSyncPollInterval(10 * time.Millisecond)
+
+		var synced []time.Duration
+		provider.SetSyncObserver(func(duration time.Duration) {
+			synced = append(synced, duration)
+		})
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Equal(t, 3, fake.changePolls, "PENDING twice, then INSYNC")
+		require.Len(t, synced, 1)
+		assert.GreaterOrEqual(t, synced[0], 30*time.Millisecond)
+	})
+
+	t.Run("conditional updates wait too", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		fake.pendingPolls = 1
+		provider := newRoute53TestProviderWithConfig(t, fake, waitConfig(5*time.Second)).
+			WithSyncPollInterval(10 * time.Millisecond)
+
+		expected, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+		require.NoError(t, provider.UpdateRecordIf(context.Background(), record, expected))
+		assert.Equal(t, 2, fake.changePolls)
+	})
+
+	t.Run("times out with a retryable error", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		fake.pendingPolls = 1000
+		provider := newRoute53TestProviderWithConfig(t, fake, waitConfig(50*time.Millisecond)).
+			WithSyncPollInterval(10 * time.Millisecond)
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "change /change/C1 not in sync after")
+		assert.True(t, errors.IsRetryableError(err))
+
+		// The change itself was applied
+		assert.Equal(t, []string{"203.0.113.10"}, fake.recordSets["home.example.com. A"].Values)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		fake := newFakeRoute53(t, existing)
+		fake.pendingPolls = 1000
+		provider := newRoute53TestProvider(t, fake)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		assert.Zero(t, fake.changePolls)
+	})
+}
+
+func TestRoute53Provider_UpdateRecords(t *testing.T) {
+	records := []interfaces.DNSRecord{
+		{Name: "home.example.com.", Type: "A", Value: "203.0.113.10", TTL: 300, Provider: "route53"},
+		{Name: "vpn.example.com.", Type: "A", Value: "203.0.113.10", TTL: 60, Provider: "route53"},
+	}
+
+	t.Run("applies all records in one change batch", func(t *testing.T) {
+		fake := newFakeRoute53(t, fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", TTL: 300, Values: []string{"192.0.2.1"}})
+		provider := newRoute53TestProvider(t, fake)
+
+		var _ interfaces.BatchUpdater = provider
+		require.NoError(t, provider.UpdateRecords(context.Background(), records))
+
+		assert.Equal(t, 1, fake.changes)
+		assert.Equal(t, []string{"203.0.113.10"}, fake.recordSets["home.example.com. A"].Values)
+		assert.Equal(t, []string{"203.0.113.10"}, fake.recordSets["vpn.example.com. A"].Values)
+		assert.Equal(t, int64(60), fake.recordSets["vpn.example.com. A"].TTL)
+	})
+
+	t.Run("empty record type", func(t *testing.T) {
+		fake := newFakeRoute53(t)
+		provider := newRoute53TestProvider(t, fake)
+
+		invalid := append([]interfaces.DNSRecord{}, records...)
+		invalid[1].Type = ""
+		err := provider.UpdateRecords(context.Background(), invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "empty record type")
+		assert.Zero(t, fake.changes)
+	})
+
+	t.Run("batch key identifies zone and credentials", func(t *testing.T) {
+		key := func(cfg config.Route53Config) string {
+			provider, err := dns.NewRoute53Provider(&cfg, zap.NewNop())
+			require.NoError(t, err)
+			return provider.BatchKey()
+		}
+		zone := config.Route53Config{Region: "us-east-1", HostedZoneID: "Z123"}
+		otherZone := zone
+		otherZone.HostedZoneID = "Z456"
+		otherRole := zone
+		otherRole.RoleARN = "arn:aws:iam::123456789012:role/dns-failover"
+
+		assert.Equal(t, key(zone), key(zone))
+		assert.NotEqual(t, key(zone), key(otherZone))
+		assert.NotEqual(t, key(zone), key(otherRole))
+	})
+}
+
+func TestRoute53Provider_ListRecords(t *testing.T) {
+	fake := newFakeRoute53(t,
+		fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", TTL: 60, Values: []string{"203.0.113.10", "203.0.113.11"}},
+		fakeRoute53RecordSet{Name: "example.com.", Type: "TXT", TTL: 300, Values: []string{`"v=spf1 -all"`}},
+		fakeRoute53RecordSet{Name: "example.com.", Type: "A", Alias: &fakeRoute53AliasTarget{
+			HostedZoneID: "Z35SXDOTRQ7X7K",
+			DNSName:      "dualstack.primary-123456.us-east-1.elb.amazonaws.com.",
+		}},
+	)
+	provider := newRoute53TestProvider(t, fake)
+
+	records, err := provider.ListRecords(context.Background())
+	require.NoError(t, err)
+
+	// Record sets with several values are listed once per value
+	assert.ElementsMatch(t, []interfaces.DNSRecord{
+		{Name: "home.example.com.", Type: "A", Value: "203.0.113.10", TTL: 60, Provider: "route53",
+			Metadata: map[string]string{"route53_id": "home.example.com."}},
+		{Name: "home.example.com.", Type: "A", Value: "203.0.113.11", TTL: 60, Provider: "route53",
+			Metadata: map[string]string{"route53_id": "home.example.com."}},
+		{Name: "example.com.", Type: "TXT", Value: `"v=spf1 -all"`, TTL: 300, Provider: "route53",
+			Metadata: map[string]string{"route53_id": "example.com."}},
+		{Name: "example.com.", Type: "A", Value: "dualstack.primary-123456.us-east-1.elb.amazonaws.com.", Provider: "route53",
+			Metadata: map[string]string{
+				"route53_id":                            "example.com.",
+				dns.Route53MetadataAliasTarget:          "dualstack.primary-123456.us-east-1.elb.amazonaws.com.",
+				dns.Route53MetadataAliasHostedZone:      "Z35SXDOTRQ7X7K",
+				dns.Route53MetadataEvaluateTargetHealth: "false",
+			}},
+	}, records)
+}
+
+func TestRoute53Provider_AliasRecords(t *testing.T) {
+	aliasConfig := &config.Route53Config{
+		Region:               "us-east-1",
+		HostedZoneID:         "Z123",
+		AliasTargetDNSName:   "dualstack.primary-123456.us-east-1.elb.amazonaws.com.",
+		AliasHostedZoneID:    "Z35SXDOTRQ7X7K",
+		EvaluateTargetHealth: true,
+	}
+	primaryAlias := &fakeRoute53AliasTarget{
+		HostedZoneID:         "Z35SXDOTRQ7X7K",
+		DNSName:              "dualstack.primary-123456.us-east-1.elb.amazonaws.com.",
+		EvaluateTargetHealth: true,
+	}
+	record := func(ip, role string) interfaces.DNSRecord {
+		return interfaces.DNSRecord{
+			Name:     "example.com.",
+			Type:     "A",
+			Value:    ip,
+			TTL:      60,
+			Provider: "route53",
+			Metadata: map[string]string{interfaces.MetadataRole: role},
+		}
+	}
+
+	t.Run("fails over from alias to plain record", func(t *testing.T) {
+		fake := newFakeRoute53(t, fakeRoute53RecordSet{Name: "example.com.", Type: "A", Alias: primaryAlias})
+		provider := newRoute53TestProviderWithConfig(t, fake, aliasConfig)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record("198.51.100.77", interfaces.RoleSecondary)))
+
+		recordSet := fake.recordSets["example.com. A"]
+		assert.Nil(t, recordSet.Alias)
+		assert.Equal(t, []string{"198.51.100.77"}, recordSet.Values)
+		assert.Equal(t, int64(60), recordSet.TTL)
+	})
+
+	t.Run("fails back from plain record to alias", func(t *testing.T) {
+		fake := newFakeRoute53(t, fakeRoute53RecordSet{Name: "example.com.", Type: "A", TTL: 60, Values: []string{"198.51.100.77"}})
+		provider := newRoute53TestProviderWithConfig(t, fake, aliasConfig)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record("203.0.113.10", interfaces.RolePrimary)))
+
+		recordSet := fake.recordSets["example.com. A"]
+		assert.Equal(t, primaryAlias, recordSet.Alias)
+		assert.Empty(t, recordSet.Values)
+		assert.Zero(t, recordSet.TTL)
+	})
+
+	t.Run("creates alias record", func(t *testing.T) {
+		fake := newFakeRoute53(t)
+		provider := newRoute53TestProviderWithConfig(t, fake, aliasConfig)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record("203.0.113.10", interfaces.RolePrimary)))
+		assert.Equal(t, primaryAlias, fake.recordSets["example.com. A"].Alias)
+	})
+
+	t.Run("switches between alias targets", func(t *testing.T) {
+		cfg := *aliasConfig
+		cfg.SecondaryAliasTargetDNSName = "dualstack.secondary-654321.eu-west-1.elb.amazonaws.com."
+		cfg.SecondaryAliasHostedZoneID = "Z32O12XQLNTSW2"
+		cfg.EvaluateTargetHealth = false
+		fake := newFakeRoute53(t, fakeRoute53RecordSet{Name: "example.com.", Type: "A", Alias: primaryAlias})
+		provider := newRoute53TestProviderWithConfig(t, fake, &cfg)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record("198.51.100.77", interfaces.RoleSecondary)))
+		assert.Equal(t, &fakeRoute53AliasTarget{
+			HostedZoneID: "Z32O12XQLNTSW2",
+			DNSName:      "dualstack.secondary-654321.eu-west-1.elb.amazonaws.com.",
+		}, fake.recordSets["example.com. A"].Alias)
+	})
+
+	t.Run("reports alias records", func(t *testing.T) {
+		fake := newFakeRoute53(t, fakeRoute53RecordSet{Name: "example.com.", Type: "A", Alias: primaryAlias})
+		provider := newRoute53TestProviderWithConfig(t, fake, aliasConfig)
+
+		found, err := provider.GetRecord(context.Background(), "example.com.", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "dualstack.primary-123456.us-east-1.elb.amazonaws.com.", found.Value)
+		assert.Zero(t, found.TTL)
+		assert.Equal(t, "dualstack.primary-123456.us-east-1.elb.amazonaws.com.", found.Metadata[dns.Route53MetadataAliasTarget])
+		assert.Equal(t, "Z35SXDOTRQ7X7K", found.Metadata[dns.Route53MetadataAliasHostedZone])
+		assert.Equal(t, "true", found.Metadata[dns.Route53MetadataEvaluateTargetHealth])
+		assert.Equal(t, interfaces.RolePrimary, found.Metadata[interfaces.MetadataRole])
+	})
+
+	t.Run("conditional update replaces alias record", func(t *testing.T) {
+		fake := newFakeRoute53(t, fakeRoute53RecordSet{Name: "example.com.", Type: "A", Alias: primaryAlias})
+		provider := newRoute53TestProviderWithConfig(t, fake, aliasConfig)
+
+		expected, err := provider.GetRecord(context.Background(), "example.com.", "A")
+		require.NoError(t, err)
+		require.NoError(t, provider.UpdateRecordIf(context.Background(), record("198.51.100.77", interfaces.RoleSecondary), expected))
+
+		recordSet := fake.recordSets["example.com. A"]
+		assert.Nil(t, recordSet.Alias)
+		assert.Equal(t, []string{"198.51.100.77"}, recordSet.Values)
+	})
+
+	t.Run("records without a role are plain", func(t *testing.T) {
+		fake := newFakeRoute53(t)
+		provider := newRoute53TestProviderWithConfig(t, fake, aliasConfig)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record("192.0.2.1", "")))
+		assert.Nil(t, fake.recordSets["example.com. A"].Alias)
+	})
+}
+
+func TestRoute53Provider_FailoverRouting(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:     "home.example.com.",
+		Type:     "A",
+		Value:    "203.0.113.10",
+		TTL:      300,
+		Provider: "route53",
+	}
+	primary := fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", SetIdentifier: "primary-site", Failover: "PRIMARY", TTL: 300, Values: []string{"192.0.2.1"}}
+	secondary := fakeRoute53RecordSet{Name: "home.example.com.", Type: "A", SetIdentifier: "secondary-site", Failover: "SECONDARY", TTL: 300, Values: []string{"192.0.2.2"}}
+	cfg := &config.Route53Config{
+		HostedZoneID:  "Z123",
+		Region:        "us-east-1",
+		SetIdentifier: "primary-site",
+		Failover:      "PRIMARY",
+		HealthCheckID: "abcdef11-2222-3333-4444-555555fedcba",
+	}
+
+	t.Run("updates only its own record set", func(t *testing.T) {
+		fake := newFakeRoute53(t, primary, secondary)
+		provider := newRoute53TestProviderWithConfig(t, fake, cfg)
+
+		current, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+		assert.Equal(t, "192.0.2.1", current.Value)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		updated := fake.recordSets["home.example.com. A primary-site"]
+		assert.Equal(t, []string{"203.0.113.10"}, updated.Values)
+		assert.Equal(t, "PRIMARY", updated.Failover)
+		assert.Equal(t, "abcdef11-2222-3333-4444-555555fedcba", updated.HealthCheckID)
+		assert.Equal(t, []string{"192.0.2.2"}, fake.recordSets["home.example.com. A secondary-site"].Values)
+	})
+
+	t.Run("creates missing record set with routing fields", func(t *testing.T) {
+		fake := newFakeRoute53(t, secondary)
+		provider := newRoute53TestProviderWithConfig(t, fake, cfg)
+
+		current, err := provider.GetRecord(context.Background(), record.Name, record.Type)
+		require.NoError(t, err)
+		assert.Nil(t, current)
+
+		require.NoError(t, provider.UpdateRecordIf(context.Background(), record, nil))
+		created := fake.recordSets["home.example.com. A primary-site"]
+		assert.Equal(t, []string{"203.0.113.10"}, created.Values)
+		assert.Equal(t, "primary-site", created.SetIdentifier)
+		assert.Equal(t, "PRIMARY", created.Failover)
+		assert.Equal(t, "abcdef11-2222-3333-4444-555555fedcba", created.HealthCheckID)
+	})
 }
@@ -0,0 +1,49 @@
+// Command crashplugin is a DNS provider plugin for tests. GetRecord returns its process ID
+// as record value, and UpdateRecord exits the process for the value "crash". The values
+// "denied" and "busy" fail the update with the PermissionDenied and ResourceExhausted codes.
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/devhat/ipfailover/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type crashProvider struct{}
+
+func (crashProvider) Name() string {
+	return "crash"
+}
+
+func (crashProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	switch record.Value {
+	case "crash":
+		os.Exit(1)
+	case "denied":
+		return status.Error(codes.PermissionDenied, "token may not edit the zone")
+	case "busy":
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+func (crashProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	return &interfaces.DNSRecord{Name: name, Type: rtype, Value: strconv.Itoa(os.Getpid())}, nil
+}
+
+func (crashProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	return nil
+}
+
+func (crashProvider) Validate(ctx context.Context) error {
+	return nil
+}
+
+func main() {
+	plugin.Serve(crashProvider{})
+}
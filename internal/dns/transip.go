@@ -0,0 +1,546 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const (
+	transipDefaultEndpoint = "https://api.transip.nl/v6"
+
+	// transipTokenLifetime is the lifetime requested for access tokens
+	transipTokenLifetime = 30 * time.Minute
+
+	// transipTokenRenewMargin is how long before expiry an access token is replaced
+	transipTokenRenewMargin = time.Minute
+)
+
+// TransIPProvider implements DNSProvider for the TransIP v6 REST API.
+// Requests are authorized with short-lived access tokens obtained by signing an
+// authentication request with the account's private key.
+type TransIPProvider struct {
+	config   *config.TransIPConfig
+	client   *http.Client
+	endpoint string
+	logger   *zap.Logger
+
+	tokenMu      sync.Mutex
+	token        string
+	tokenExpires time.Time
+}
+
+// TransIPDNSEntry represents a DNS entry in the TransIP API.
+// An entry is identified by its name, expire and type.
+type TransIPDNSEntry struct {
+	Name    string `json:"name"`
+	Expire  int    `json:"expire"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// transipDNSEntryRequest is the request body for adding, updating and removing a DNS entry
+type transipDNSEntryRequest struct {
+	DNSEntry TransIPDNSEntry `json:"dnsEntry"`
+}
+
+// transipDNSEntriesResponse represents the response of the list DNS entries endpoint
+type transipDNSEntriesResponse struct {
+	DNSEntries []TransIPDNSEntry `json:"dnsEntries"`
+}
+
+// transipAuthRequest represents the signed request body for obtaining an access token
+type transipAuthRequest struct {
+	Login          string `json:"login"`
+	Nonce          string `json:"nonce"`
+	ReadOnly       bool   `json:"read_only"`
+	ExpirationTime string `json:"expiration_time"`
+	GlobalKey      bool   `json:"global_key"`
+}
+
+// transipErrorResponse represents an error returned by the TransIP API
+type transipErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewTransIPProvider creates a new TransIP DNS provider
+func NewTransIPProvider(cfg *config.TransIPConfig, logger *zap.Logger) *TransIPProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("transip config is nil")
+		}
+		return nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = transipDefaultEndpoint
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	return &TransIPProvider{
+		config:   cfg,
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (t *TransIPProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(t.client, proxy)
+}
+
+// Name returns the provider name
+func (t *TransIPProvider) Name() string {
+	return "transip"
+}
+
+// UpdateRecord updates or creates a DNS record.
+// The TTL (expire) identifies an entry and cannot be changed in place, so an entry with a different
+// TTL is replaced. If adding the replacement fails, the previous entry is restored.
+func (t *TransIPProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	t.logger.Info("updating DNS record",
+		zap.String("provider", "transip"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	if record.Type == "" {
+		return errors.NewDNSProviderError("transip", record.Name, fmt.Errorf("empty record type"))
+	}
+
+	name, err := relativeName(record.Name, t.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("transip", record.Name, err)
+	}
+
+	existing, err := t.findEntry(ctx, name, record.Type)
+	if err != nil {
+		return errors.NewDNSProviderError("transip", record.Name, err)
+	}
+
+	entry := TransIPDNSEntry{
+		Name:    name,
+		Expire:  record.TTL,
+		Type:    record.Type,
+		Content: record.Value,
+	}
+
+	if existing == nil {
+		if err := t.doRequest(ctx, http.MethodPost, t.dnsPath(), transipDNSEntryRequest{DNSEntry: entry}, nil); err != nil {
+			return errors.NewDNSProviderError("transip", record.Name, fmt.Errorf("failed to create DNS entry: %w", err))
+		}
+
+		t.logger.Info("DNS record created successfully",
+			zap.String("provider", "transip"),
+			zap.String("record", record.Name),
+		)
+		return nil
+	}
+
+	if existing.Expire == entry.Expire {
+		if err := t.doRequest(ctx, http.MethodPatch, t.dnsPath(), transipDNSEntryRequest{DNSEntry: entry}, nil); err != nil {
+			return errors.NewDNSProviderError("transip", record.Name, fmt.Errorf("failed to update DNS entry: %w", err))
+		}
+	} else if err := t.replaceEntry(ctx, record, *existing, entry); err != nil {
+		return errors.NewDNSProviderError("transip", record.Name, err)
+	}
+
+	t.logger.Info("DNS record updated successfully",
+		zap.String("provider", "transip"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// replaceEntry removes an entry and adds its replacement, restoring the removed entry if adding fails
+func (t *TransIPProvider) replaceEntry(ctx context.Context, record interfaces.DNSRecord, existing, entry TransIPDNSEntry) error {
+	if err := t.doRequest(ctx, http.MethodDelete, t.dnsPath(), transipDNSEntryRequest{DNSEntry: existing}, nil); err != nil {
+		return fmt.Errorf("failed to remove DNS entry with TTL %d: %w", existing.Expire, err)
+	}
+
+	addErr := t.doRequest(ctx, http.MethodPost, t.dnsPath(), transipDNSEntryRequest{DNSEntry: entry}, nil)
+	if addErr == nil {
+		return nil
+	}
+	addErr = fmt.Errorf("failed to add DNS entry with TTL %d: %w", entry.Expire, addErr)
+
+	if err := t.doRequest(ctx, http.MethodPost, t.dnsPath(), transipDNSEntryRequest{DNSEntry: existing}, nil); err != nil {
+		t.logger.Error("DNS entry removed but the update failed and it could not be restored, the record is missing",
+			zap.String("provider", "transip"),
+			zap.String("record", record.Name),
+			zap.String("type", record.Type),
+			zap.String("value", existing.Content),
+			zap.Error(err),
+		)
+		return fmt.Errorf("%w; record is missing: failed to restore previous entry: %w", addErr, err)
+	}
+
+	t.logger.Warn("update failed, restored previous DNS entry",
+		zap.String("provider", "transip"),
+		zap.String("record", record.Name),
+	)
+	return addErr
+}
+
+// GetRecord retrieves an existing DNS record
+func (t *TransIPProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	t.logger.Debug("getting DNS record",
+		zap.String("provider", "transip"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if rtype == "" {
+		return nil, errors.NewDNSProviderError("transip", name, fmt.Errorf("empty record type"))
+	}
+
+	relative, err := relativeName(name, t.config.Domain)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("transip", name, err)
+	}
+
+	found, err := t.findEntry(ctx, relative, rtype)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("transip", name, err)
+	}
+
+	if found == nil {
+		return nil, nil // Record not found
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     absoluteName(found.Name, t.config.Domain),
+		Type:     found.Type,
+		Value:    found.Content,
+		TTL:      found.Expire,
+		Provider: "transip",
+		Metadata: map[string]string{
+			"name": found.Name,
+		},
+	}, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (t *TransIPProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	t.logger.Info("deleting DNS record",
+		zap.String("provider", "transip"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if recordType == "" {
+		return errors.NewDNSProviderError("transip", name, fmt.Errorf("empty record type"))
+	}
+
+	relative, err := relativeName(name, t.config.Domain)
+	if err != nil {
+		return errors.NewDNSProviderError("transip", name, err)
+	}
+
+	existing, err := t.findEntry(ctx, relative, recordType)
+	if err != nil {
+		return errors.NewDNSProviderError("transip", name, err)
+	}
+
+	if existing == nil {
+		t.logger.Warn("record not found for deletion",
+			zap.String("provider", "transip"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+
+	if err := t.doRequest(ctx, http.MethodDelete, t.dnsPath(), transipDNSEntryRequest{DNSEntry: *existing}, nil); err != nil {
+		return errors.NewDNSProviderError("transip", name, fmt.Errorf("failed to delete DNS entry: %w", err))
+	}
+
+	t.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "transip"),
+		zap.String("record", name),
+	)
+
+	return nil
+}
+
+// Validate checks if the provider configuration is valid
+func (t *TransIPProvider) Validate(ctx context.Context) error {
+	t.logger.Debug("validating transip provider configuration")
+
+	// Test the private key, credentials and access to the domain by fetching the domain info
+	path := "/domains/" + url.PathEscape(t.config.Domain)
+	if err := t.doRequest(ctx, http.MethodGet, path, nil, nil); err != nil {
+		return errors.NewDNSProviderError("transip", "validation", fmt.Errorf("failed to get domain %s: %w", t.config.Domain, err))
+	}
+
+	t.logger.Info("transip provider validation successful")
+	return nil
+}
+
+// dnsPath returns the API path of the domain's DNS entries
+func (t *TransIPProvider) dnsPath() string {
+	return "/domains/" + url.PathEscape(t.config.Domain) + "/dns"
+}
+
+// findEntry finds a DNS entry by zone-relative name and type
+func (t *TransIPProvider) findEntry(ctx context.Context, name, recordType string) (*TransIPDNSEntry, error) {
+	var resp transipDNSEntriesResponse
+	if err := t.doRequest(ctx, http.MethodGet, t.dnsPath(), nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list DNS entries: %w", err)
+	}
+
+	for _, entry := range resp.DNSEntries {
+		if strings.EqualFold(entry.Name, name) && entry.Type == recordType {
+			found := entry
+			return &found, nil
+		}
+	}
+
+	return nil, nil // Record not found
+}
+
+// doRequest performs an API request with an access token. If the token is rejected,
+// for example because it was revoked, a new token is requested and the request is retried once.
+func (t *TransIPProvider) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	token, err := t.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = t.doAuthorizedRequest(ctx, token, method, path, body, out)
+
+	var httpErr *errors.HTTPError
+	if stderrors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized {
+		t.logger.Debug("transip access token rejected, requesting a new one")
+		t.invalidateToken(token)
+
+		token, err = t.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+		err = t.doAuthorizedRequest(ctx, token, method, path, body, out)
+	}
+
+	return err
+}
+
+// doAuthorizedRequest performs an API request with the given access token,
+// encoding body as JSON if non-nil and decoding the response into out if non-nil
+func (t *TransIPProvider) doAuthorizedRequest(ctx context.Context, token, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.endpoint+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return t.send(req, out)
+}
+
+// accessToken returns the current access token, requesting a new one if there is none or it is about to expire
+func (t *TransIPProvider) accessToken(ctx context.Context) (string, error) {
+	t.tokenMu.Lock()
+	defer t.tokenMu.Unlock()
+
+	if t.token != "" && time.Now().Before(t.tokenExpires.Add(-transipTokenRenewMargin)) {
+		return t.token, nil
+	}
+
+	// The key is read for every new token so a replaced key file is picked up without a restart
+	key, err := loadTransIPPrivateKey(t.config.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	requested := time.Now()
+	token, err := t.requestToken(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	t.token = token
+	t.tokenExpires = requested.Add(transipTokenLifetime)
+
+	t.logger.Debug("obtained transip access token",
+		zap.Time("expires", t.tokenExpires),
+	)
+
+	return token, nil
+}
+
+// invalidateToken discards the access token unless it was already replaced
+func (t *TransIPProvider) invalidateToken(token string) {
+	t.tokenMu.Lock()
+	defer t.tokenMu.Unlock()
+
+	if t.token == token {
+		t.token = ""
+	}
+}
+
+// requestToken obtains a new access token with an authentication request signed by the private key
+func (t *TransIPProvider) requestToken(ctx context.Context, key *rsa.PrivateKey) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// Tokens are not restricted to whitelisted IP addresses, since requests are sent
+	// from either the primary or the secondary IP
+	data, err := json.Marshal(transipAuthRequest{
+		Login:          t.config.Login,
+		Nonce:          hex.EncodeToString(nonce),
+		ExpirationTime: fmt.Sprintf("%d minutes", int(transipTokenLifetime.Minutes())),
+		GlobalKey:      true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal authentication request: %w", err)
+	}
+
+	digest := sha512.Sum512(data)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA512, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign authentication request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint+"/auth", bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Signature", base64.StdEncoding.EncodeToString(signature))
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := t.send(req, &resp); err != nil {
+		return "", fmt.Errorf("failed to obtain access token: %w", err)
+	}
+
+	if resp.Token == "" {
+		return "", fmt.Errorf("failed to obtain access token: empty token")
+	}
+
+	return resp.Token, nil
+}
+
+// send performs a request and decodes the response into out if non-nil and the response has a body
+func (t *TransIPProvider) send(req *http.Request, out interface{}) error {
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			t.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	requestURL := req.URL.String()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr transipErrorResponse
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&apiErr); decodeErr == nil && apiErr.Error != "" {
+			return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", apiErr.Error))
+		}
+		return errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("unexpected status code"))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// loadTransIPPrivateKey parses the unencrypted RSA private key given as PEM data, or read from the file
+// the value points to. Errors never include the value, since it may contain key material.
+func loadTransIPPrivateKey(value string) (*rsa.PrivateKey, error) {
+	data := []byte(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		fileData, err := os.ReadFile(value)
+		if err != nil {
+			// The path error would repeat the value, which may be a malformed inline key
+			var pathErr *fs.PathError
+			if stderrors.As(err, &pathErr) {
+				err = pathErr.Err
+			}
+			return nil, fmt.Errorf("failed to read private key file: %w (private_key must be a file path or a PEM encoded key)", err)
+		}
+		data = fileData
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" || strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+		return nil, fmt.Errorf("private key is encrypted, TransIP API keys must be stored unencrypted (decrypt it with: openssl pkey -in encrypted.key -out transip.key)")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		key, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is a %T, TransIP API keys are RSA keys", parsed)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q, expected a PRIVATE KEY or RSA PRIVATE KEY block", block.Type)
+	}
+}
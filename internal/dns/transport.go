@@ -0,0 +1,37 @@
+package dns
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+)
+
+// newTLSTransport returns a transport connecting with tlsConfig, or nil, for http.DefaultTransport,
+// if tlsConfig is nil
+func newTLSTransport(tlsConfig *tls.Config) http.RoundTripper {
+	if tlsConfig == nil {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport
+}
+
+// newProxyTransport returns a copy of transport, or of http.DefaultTransport if transport is not
+// an *http.Transport, that sends requests through proxy
+func newProxyTransport(transport http.RoundTripper, proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+
+	clone := base.Clone()
+	clone.Proxy = proxy
+	return clone
+}
+
+// setClientProxy sends the requests of client through proxy, keeping the other settings of its transport
+func setClientProxy(client *http.Client, proxy func(*http.Request) (*url.URL, error)) {
+	client.Transport = newProxyTransport(client.Transport, proxy)
+}
@@ -0,0 +1,314 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookDefaultMethod       = http.MethodPost
+	webhookDefaultDeleteMethod = http.MethodDelete
+	webhookDefaultValueField   = "value"
+
+	// webhookMaxResponseSize bounds how much of a response is read
+	webhookMaxResponseSize = 1 << 20
+	// webhookMaxErrorLength bounds how much of an error response is included in errors
+	webhookMaxErrorLength = 200
+)
+
+// WebhookProvider implements DNSProvider by sending templated HTTP requests, for DNS backends
+// such as internal IPAM systems that have no dedicated provider. Any 2xx response is a success.
+type WebhookProvider struct {
+	config    *config.WebhookConfig
+	templates *config.WebhookTemplates
+	client    *http.Client
+	logger    *zap.Logger
+}
+
+// NewWebhookProvider creates a new webhook DNS provider. The configuration's templates are
+// parsed and its TLS certificate files loaded here; it returns nil if they are invalid, which
+// Validate of the configuration reports first.
+func NewWebhookProvider(cfg *config.WebhookConfig, logger *zap.Logger) *WebhookProvider {
+	if cfg == nil {
+		if logger != nil {
+			logger.Error("webhook config is nil")
+		}
+		return nil
+	}
+
+	templates, err := cfg.ParseTemplates()
+	if err != nil {
+		if logger != nil {
+			logger.Error("invalid webhook templates", zap.Error(err))
+		}
+		return nil
+	}
+
+	tlsConfig, err := cfg.TLS.ClientConfig()
+	if err != nil {
+		if logger != nil {
+			logger.Error("invalid webhook TLS configuration", zap.Error(err))
+		}
+		return nil
+	}
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: newTLSTransport(tlsConfig),
+	}
+
+	return &WebhookProvider{
+		config:    cfg,
+		templates: templates,
+		client:    client,
+		logger:    logger,
+	}
+}
+
+// SetProxy sends the API requests through proxy, see interfaces.ProxyConfigurable
+func (w *WebhookProvider) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	setClientProxy(w.client, proxy)
+}
+
+// Name returns the provider name
+func (w *WebhookProvider) Name() string {
+	return "webhook"
+}
+
+// UpdateRecord renders and sends the update request
+func (w *WebhookProvider) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	w.logger.Info("updating DNS record",
+		zap.String("provider", "webhook"),
+		zap.String("record", record.Name),
+		zap.String("type", record.Type),
+		zap.String("value", record.Value),
+	)
+
+	data := config.WebhookRecord{
+		Name:  record.Name,
+		Type:  record.Type,
+		Value: record.Value,
+		TTL:   record.TTL,
+	}
+
+	method := methodOrDefault(w.config.Method, webhookDefaultMethod)
+	if _, err := w.doRequest(ctx, method, w.templates.URL, w.templates.Body, data); err != nil {
+		return errors.NewDNSProviderError("webhook", record.Name, fmt.Errorf("failed to update record: %w", err))
+	}
+
+	w.logger.Info("DNS record updated successfully",
+		zap.String("provider", "webhook"),
+		zap.String("record", record.Name),
+	)
+
+	return nil
+}
+
+// GetRecord sends the get request and reads the record value from the JSON response.
+// Without get_url, and for a 404 response, the record is reported as not found.
+func (w *WebhookProvider) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	w.logger.Debug("getting DNS record",
+		zap.String("provider", "webhook"),
+		zap.String("record", name),
+		zap.String("type", rtype),
+	)
+
+	if w.templates.GetURL == nil {
+		return nil, nil // Record contents cannot be read back
+	}
+
+	body, err := w.doRequest(ctx, http.MethodGet, w.templates.GetURL, nil, config.WebhookRecord{Name: name, Type: rtype})
+	if isHTTPNotFound(err) {
+		return nil, nil // Record not found
+	}
+	if err != nil {
+		return nil, errors.NewDNSProviderError("webhook", name, fmt.Errorf("failed to get record: %w", err))
+	}
+
+	field := w.config.GetValueField
+	if field == "" {
+		field = webhookDefaultValueField
+	}
+
+	value, err := jsonField(body, field)
+	if err != nil {
+		return nil, errors.NewDNSProviderError("webhook", name, err)
+	}
+
+	return &interfaces.DNSRecord{
+		Name:     name,
+		Type:     rtype,
+		Value:    value,
+		Provider: "webhook",
+	}, nil
+}
+
+// DeleteRecord renders and sends the delete request. A 404 response counts as deleted.
+func (w *WebhookProvider) DeleteRecord(ctx context.Context, name, recordType string) error {
+	w.logger.Info("deleting DNS record",
+		zap.String("provider", "webhook"),
+		zap.String("record", name),
+		zap.String("type", recordType),
+	)
+
+	if w.templates.DeleteURL == nil {
+		return errors.NewDNSProviderError("webhook", name, fmt.Errorf("deleting records requires delete_url"))
+	}
+
+	method := methodOrDefault(w.config.DeleteMethod, webhookDefaultDeleteMethod)
+	_, err := w.doRequest(ctx, method, w.templates.DeleteURL, nil, config.WebhookRecord{Name: name, Type: recordType})
+	if isHTTPNotFound(err) {
+		w.logger.Warn("record not found for deletion",
+			zap.String("provider", "webhook"),
+			zap.String("record", name),
+			zap.String("type", recordType),
+		)
+		return nil // Record doesn't exist, consider it deleted
+	}
+	if err != nil {
+		return errors.NewDNSProviderError("webhook", name, fmt.Errorf("failed to delete record: %w", err))
+	}
+
+	w.logger.Info("DNS record deleted successfully",
+		zap.String("provider", "webhook"),
+		zap.String("record", name),
+	)
+
+	return nil
+}
+
+// Validate sends the validate request, if configured
+func (w *WebhookProvider) Validate(ctx context.Context) error {
+	w.logger.Debug("validating webhook provider configuration")
+
+	if w.templates.ValidateURL == nil {
+		w.logger.Debug("no webhook validate_url configured, skipping validation request")
+		return nil
+	}
+
+	if _, err := w.doRequest(ctx, http.MethodGet, w.templates.ValidateURL, nil, config.WebhookRecord{}); err != nil {
+		return errors.NewDNSProviderError("webhook", "validation", err)
+	}
+
+	w.logger.Info("webhook provider validation successful")
+	return nil
+}
+
+// doRequest renders the URL, header and body templates with data, sends the request and
+// returns the response body. Non-2xx responses are returned as HTTP errors.
+func (w *WebhookProvider) doRequest(ctx context.Context, method string, urlTemplate, bodyTemplate *template.Template, data config.WebhookRecord) ([]byte, error) {
+	requestURL, err := renderWebhookTemplate(urlTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var reqBody io.Reader
+	if bodyTemplate != nil {
+		body, err := renderWebhookTemplate(bodyTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if bodyTemplate != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, headerTemplate := range w.templates.Headers {
+		value, err := renderWebhookTemplate(headerTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			w.logger.Debug("failed to close response body", zap.Error(closeErr))
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := strings.TrimSpace(string(body))
+		if len(message) > webhookMaxErrorLength {
+			message = message[:webhookMaxErrorLength] + "..."
+		}
+		if message == "" {
+			message = "unexpected status code"
+		}
+		return nil, errors.NewHTTPError(resp.StatusCode, requestURL, fmt.Errorf("%s", message))
+	}
+
+	return body, nil
+}
+
+// renderWebhookTemplate executes a webhook template with data
+func renderWebhookTemplate(tmpl *template.Template, data config.WebhookRecord) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// methodOrDefault returns method in upper case, or fallback if it is empty
+func methodOrDefault(method, fallback string) string {
+	if method == "" {
+		return fallback
+	}
+	return strings.ToUpper(method)
+}
+
+// jsonField returns the value at a dot-separated path of a JSON object as a string
+func jsonField(body []byte, path string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("response has no field %s", path)
+		}
+		if value, ok = object[key]; !ok {
+			return "", fmt.Errorf("response has no field %s", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("field %s of the response is not a string", path)
+	}
+}
@@ -0,0 +1,256 @@
+package dns_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/dns"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// webhookRequest is a request received by the webhook test server
+type webhookRequest struct {
+	Method        string
+	URI           string
+	Authorization string
+	ContentType   string
+	Body          string
+}
+
+// webhookServer records the requests it receives and answers with a fixed status and body
+type webhookServer struct {
+	mu       sync.Mutex
+	status   int
+	body     string
+	requests []webhookRequest
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, webhookRequest{
+		Method:        r.Method,
+		URI:           r.URL.RequestURI(),
+		Authorization: r.Header.Get("Authorization"),
+		ContentType:   r.Header.Get("Content-Type"),
+		Body:          string(body),
+	})
+
+	w.WriteHeader(s.status)
+	_, _ = w.Write([]byte(s.body))
+}
+
+func newWebhookTestProvider(t *testing.T, status int, body string, configure func(cfg *config.WebhookConfig)) (*dns.WebhookProvider, *webhookServer) {
+	handler := &webhookServer{status: status, body: body}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.WebhookConfig{
+		URL:     server.URL + "/records/{{.Name}}?type={{.Type}}",
+		Method:  "put",
+		Headers: map[string]string{"Authorization": "Bearer ipam-token"},
+		Body:    `{"value": {{json .Value}}, "ttl": {{.TTL}}}`,
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	provider := dns.NewWebhookProvider(cfg, zap.NewNop())
+	require.NotNil(t, provider)
+	return provider, handler
+}
+
+func TestWebhookProvider_Name(t *testing.T) {
+	provider := dns.NewWebhookProvider(&config.WebhookConfig{URL: "https://ipam.example.com/{{.Name}}"}, zap.NewNop())
+	assert.Equal(t, "webhook", provider.Name())
+
+	assert.Nil(t, dns.NewWebhookProvider(nil, zap.NewNop()))
+	assert.Nil(t, dns.NewWebhookProvider(&config.WebhookConfig{URL: "https://ipam.example.com/{{.Nmae}}"}, zap.NewNop()))
+}
+
+func TestWebhookProvider_UpdateRecord(t *testing.T) {
+	record := interfaces.DNSRecord{
+		Name:  "home.example.com",
+		Type:  "A",
+		Value: "203.0.113.10",
+		TTL:   300,
+	}
+
+	t.Run("renders and sends the request", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusNoContent, "", nil)
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		require.Len(t, server.requests, 1)
+		assert.Equal(t, webhookRequest{
+			Method:        http.MethodPut,
+			URI:           "/records/home.example.com?type=A",
+			Authorization: "Bearer ipam-token",
+			ContentType:   "application/json",
+			Body:          `{"value": "203.0.113.10", "ttl": 300}`,
+		}, server.requests[0])
+	})
+
+	t.Run("defaults to POST without body", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusOK, "", func(cfg *config.WebhookConfig) {
+			cfg.Method = ""
+			cfg.Body = ""
+		})
+
+		require.NoError(t, provider.UpdateRecord(context.Background(), record))
+		require.Len(t, server.requests, 1)
+		assert.Equal(t, http.MethodPost, server.requests[0].Method)
+		assert.Empty(t, server.requests[0].ContentType)
+		assert.Empty(t, server.requests[0].Body)
+	})
+
+	t.Run("non-2xx response fails", func(t *testing.T) {
+		provider, _ := newWebhookTestProvider(t, http.StatusConflict, "address already assigned\n", nil)
+
+		err := provider.UpdateRecord(context.Background(), record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "409")
+		assert.Contains(t, err.Error(), "address already assigned")
+		assert.NotContains(t, err.Error(), "ipam-token")
+	})
+}
+
+func TestWebhookProvider_GetRecord(t *testing.T) {
+	t.Run("reads the value field", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusOK, `{"record": {"address": "192.0.2.1"}}`, func(cfg *config.WebhookConfig) {
+			cfg.GetURL = cfg.URL
+			cfg.GetValueField = "record.address"
+		})
+
+		found, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "192.0.2.1", found.Value)
+		assert.Equal(t, "home.example.com", found.Name)
+		assert.Equal(t, http.MethodGet, server.requests[0].Method)
+		assert.Equal(t, "/records/home.example.com?type=A", server.requests[0].URI)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		provider, _ := newWebhookTestProvider(t, http.StatusOK, `{"address": "192.0.2.1"}`, func(cfg *config.WebhookConfig) {
+			cfg.GetURL = cfg.URL
+		})
+
+		_, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "response has no field value")
+	})
+
+	t.Run("404 is not found", func(t *testing.T) {
+		provider, _ := newWebhookTestProvider(t, http.StatusNotFound, "", func(cfg *config.WebhookConfig) {
+			cfg.GetURL = cfg.URL
+		})
+
+		found, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("without get_url", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusOK, "", nil)
+
+		found, err := provider.GetRecord(context.Background(), "home.example.com", "A")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+		assert.Empty(t, server.requests)
+	})
+}
+
+func TestWebhookProvider_DeleteAndValidate(t *testing.T) {
+	t.Run("delete", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusOK, "", func(cfg *config.WebhookConfig) {
+			cfg.DeleteURL = cfg.URL
+		})
+
+		require.NoError(t, provider.DeleteRecord(context.Background(), "home.example.com", "A"))
+		require.Len(t, server.requests, 1)
+		assert.Equal(t, http.MethodDelete, server.requests[0].Method)
+		assert.Empty(t, server.requests[0].Body)
+	})
+
+	t.Run("delete without delete_url", func(t *testing.T) {
+		provider, _ := newWebhookTestProvider(t, http.StatusOK, "", nil)
+
+		err := provider.DeleteRecord(context.Background(), "home.example.com", "A")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires delete_url")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusUnauthorized, "invalid token", func(cfg *config.WebhookConfig) {
+			cfg.ValidateURL = cfg.URL[:len(cfg.URL)-len("/records/{{.Name}}?type={{.Type}}")] + "/health"
+		})
+
+		err := provider.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid token")
+		require.Len(t, server.requests, 1)
+		assert.Equal(t, "/health", server.requests[0].URI)
+		assert.Equal(t, "Bearer ipam-token", server.requests[0].Authorization)
+	})
+
+	t.Run("validate without validate_url", func(t *testing.T) {
+		provider, server := newWebhookTestProvider(t, http.StatusOK, "", nil)
+
+		require.NoError(t, provider.Validate(context.Background()))
+		assert.Empty(t, server.requests)
+	})
+}
+
+func TestWebhookProvider_TLS(t *testing.T) {
+	handler := &webhookServer{status: http.StatusOK}
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	certFile, _ := writeTLSServerFiles(t, server)
+
+	cfg := &config.WebhookConfig{
+		URL: server.URL + "/records/{{.Name}}",
+		TLS: &config.TLSConfig{CACertFile: certFile},
+	}
+	require.NoError(t, cfg.Validate())
+	provider := dns.NewWebhookProvider(cfg, zap.NewNop())
+	require.NotNil(t, provider)
+
+	record := interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 300}
+	require.NoError(t, provider.UpdateRecord(context.Background(), record))
+	assert.Len(t, handler.requests, 1)
+}
+
+func TestWebhookProvider_Proxy(t *testing.T) {
+	// The proxy answers for the webhook, whose host does not resolve
+	handler := &webhookServer{status: http.StatusOK}
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	provider := dns.NewWebhookProvider(&config.WebhookConfig{URL: "http://ipam.example.invalid/records/{{.Name}}"}, zap.NewNop())
+	require.NotNil(t, provider)
+	provider.SetProxy(http.ProxyURL(proxyURL))
+
+	record := interfaces.DNSRecord{Name: "home.example.com", Type: "A", Value: "198.51.100.77", TTL: 300}
+	require.NoError(t, provider.UpdateRecord(context.Background(), record))
+	assert.Equal(t, "ipam.example.invalid", proxiedHost)
+	require.Len(t, handler.requests, 1)
+	assert.Equal(t, "/records/home.example.com", handler.requests[0].URI)
+}
@@ -0,0 +1,210 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file implements the subset of XML-RPC (http://xmlrpc.com/spec.md) used by DNS provider APIs:
+// string, int, boolean, array and struct values.
+
+// xmlrpcMember is a named member of an XML-RPC struct
+type xmlrpcMember struct {
+	Name  string
+	Value interface{}
+}
+
+// xmlrpcStruct is an XML-RPC struct. Members are encoded in order, so request payloads are deterministic.
+type xmlrpcStruct []xmlrpcMember
+
+// marshalXMLRPCCall encodes a method call with the given parameters.
+// Parameters can be string, int, bool, []interface{} or xmlrpcStruct values.
+func marshalXMLRPCCall(method string, params ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<methodCall><methodName>")
+	if err := xml.EscapeText(&buf, []byte(method)); err != nil {
+		return nil, err
+	}
+	buf.WriteString("</methodName><params>")
+	for _, param := range params {
+		buf.WriteString("<param>")
+		if err := encodeXMLRPCValue(&buf, param); err != nil {
+			return nil, err
+		}
+		buf.WriteString("</param>")
+	}
+	buf.WriteString("</params></methodCall>")
+	return buf.Bytes(), nil
+}
+
+// encodeXMLRPCValue writes value as an XML-RPC <value> element
+func encodeXMLRPCValue(buf *bytes.Buffer, value interface{}) error {
+	buf.WriteString("<value>")
+	switch v := value.(type) {
+	case string:
+		buf.WriteString("<string>")
+		if err := xml.EscapeText(buf, []byte(v)); err != nil {
+			return err
+		}
+		buf.WriteString("</string>")
+	case int:
+		buf.WriteString("<int>" + strconv.Itoa(v) + "</int>")
+	case bool:
+		if v {
+			buf.WriteString("<boolean>1</boolean>")
+		} else {
+			buf.WriteString("<boolean>0</boolean>")
+		}
+	case []interface{}:
+		buf.WriteString("<array><data>")
+		for _, item := range v {
+			if err := encodeXMLRPCValue(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("</data></array>")
+	case xmlrpcStruct:
+		buf.WriteString("<struct>")
+		for _, member := range v {
+			buf.WriteString("<member><name>")
+			if err := xml.EscapeText(buf, []byte(member.Name)); err != nil {
+				return err
+			}
+			buf.WriteString("</name>")
+			if err := encodeXMLRPCValue(buf, member.Value); err != nil {
+				return err
+			}
+			buf.WriteString("</member>")
+		}
+		buf.WriteString("</struct>")
+	default:
+		return fmt.Errorf("unsupported XML-RPC parameter type %T", value)
+	}
+	buf.WriteString("</value>")
+	return nil
+}
+
+// xmlrpcValue is the wire form of an XML-RPC <value>. A value without a type element is a string.
+type xmlrpcValue struct {
+	String  *string `xml:"string"`
+	Int     *string `xml:"int"`
+	I4      *string `xml:"i4"`
+	Boolean *string `xml:"boolean"`
+	Double  *string `xml:"double"`
+	Array   *struct {
+		Values []xmlrpcValue `xml:"data>value"`
+	} `xml:"array"`
+	Struct *struct {
+		Members []struct {
+			Name  string      `xml:"name"`
+			Value xmlrpcValue `xml:"value"`
+		} `xml:"member"`
+	} `xml:"struct"`
+	Text string `xml:",chardata"`
+}
+
+// xmlrpcResponse is the wire form of an XML-RPC <methodResponse>
+type xmlrpcResponse struct {
+	XMLName xml.Name      `xml:"methodResponse"`
+	Params  []xmlrpcValue `xml:"params>param>value"`
+	Fault   *xmlrpcValue  `xml:"fault>value"`
+}
+
+// xmlrpcFault is an XML-RPC fault response
+type xmlrpcFault struct {
+	Code    int
+	Message string
+}
+
+// Error implements error
+func (f *xmlrpcFault) Error() string {
+	return fmt.Sprintf("XML-RPC fault %d: %s", f.Code, f.Message)
+}
+
+// unmarshalXMLRPCResponse decodes a method response into its single return value as
+// string, int, bool, float64, []interface{} or map[string]interface{}. A fault response
+// is returned as an *xmlrpcFault error.
+func unmarshalXMLRPCResponse(data []byte) (interface{}, error) {
+	var resp xmlrpcResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode XML-RPC response: %w", err)
+	}
+
+	if resp.Fault != nil {
+		decoded, err := resp.Fault.decode()
+		if err != nil {
+			return nil, err
+		}
+		fields, _ := decoded.(map[string]interface{})
+		fault := &xmlrpcFault{}
+		fault.Code, _ = fields["faultCode"].(int)
+		fault.Message, _ = fields["faultString"].(string)
+		return nil, fault
+	}
+
+	if len(resp.Params) != 1 {
+		return nil, fmt.Errorf("XML-RPC response has %d return values, expected 1", len(resp.Params))
+	}
+
+	return resp.Params[0].decode()
+}
+
+// decode converts the wire form of a value into a Go value
+func (v *xmlrpcValue) decode() (interface{}, error) {
+	switch {
+	case v.String != nil:
+		return *v.String, nil
+	case v.Int != nil, v.I4 != nil:
+		text := v.Int
+		if text == nil {
+			text = v.I4
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(*text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML-RPC int %q", *text)
+		}
+		return n, nil
+	case v.Boolean != nil:
+		switch strings.TrimSpace(*v.Boolean) {
+		case "1":
+			return true, nil
+		case "0":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid XML-RPC boolean %q", *v.Boolean)
+		}
+	case v.Double != nil:
+		f, err := strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML-RPC double %q", *v.Double)
+		}
+		return f, nil
+	case v.Array != nil:
+		items := make([]interface{}, 0, len(v.Array.Values))
+		for i := range v.Array.Values {
+			item, err := v.Array.Values[i].decode()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case v.Struct != nil:
+		fields := make(map[string]interface{}, len(v.Struct.Members))
+		for i := range v.Struct.Members {
+			member := &v.Struct.Members[i]
+			value, err := member.Value.decode()
+			if err != nil {
+				return nil, fmt.Errorf("member %s: %w", member.Name, err)
+			}
+			fields[member.Name] = value
+		}
+		return fields, nil
+	default:
+		return v.Text, nil
+	}
+}
@@ -0,0 +1,52 @@
+package fleet
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// NewRand returns a random number generator seeded with seed, or with the current time if seed is 0.
+// Tests pass a fixed seed to make the randomization deterministic.
+func NewRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// ShuffleEndpoints returns a shuffled copy of endpoints, so instances sharing a configuration
+// spread their first requests over all endpoints instead of all querying the first one
+func ShuffleEndpoints(endpoints []string, rng *rand.Rand) []string {
+	shuffled := make([]string, len(endpoints))
+	copy(shuffled, endpoints)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+// PhaseOffset returns the offset of the instance's poll phase within interval. It is derived from a
+// hash of the instance name, so it is stable across restarts and differs between instances.
+func PhaseOffset(instanceName string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(instanceName))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// NextPhaseTick returns the first time after now that lies offset past a multiple of interval
+// since the Unix epoch. Polling at these times keeps instances with different offsets apart
+// regardless of when they were started.
+func NextPhaseTick(now time.Time, interval, offset time.Duration) time.Time {
+	if interval <= 0 {
+		return now
+	}
+
+	sinceEpoch := time.Duration(now.UnixNano()) - offset
+	next := sinceEpoch - sinceEpoch%interval + interval
+	return time.Unix(0, int64(next+offset))
+}
@@ -0,0 +1,82 @@
+package fleet_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/fleet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShuffleEndpoints(t *testing.T) {
+	endpoints := []string{"https://a.example", "https://b.example", "https://c.example", "https://d.example"}
+
+	t.Run("same seed gives same order", func(t *testing.T) {
+		first := fleet.ShuffleEndpoints(endpoints, fleet.NewRand(42))
+		second := fleet.ShuffleEndpoints(endpoints, fleet.NewRand(42))
+		assert.Equal(t, first, second)
+		assert.ElementsMatch(t, endpoints, first)
+	})
+
+	t.Run("input is not modified", func(t *testing.T) {
+		original := append([]string(nil), endpoints...)
+		fleet.ShuffleEndpoints(endpoints, fleet.NewRand(7))
+		assert.Equal(t, original, endpoints)
+	})
+
+	t.Run("seeds spread the first endpoint", func(t *testing.T) {
+		first := make(map[string]bool)
+		for seed := int64(1); seed <= 50; seed++ {
+			first[fleet.ShuffleEndpoints(endpoints, fleet.NewRand(seed))[0]] = true
+		}
+		assert.Len(t, first, len(endpoints))
+	})
+}
+
+func TestPhaseOffset(t *testing.T) {
+	interval := 30 * time.Second
+
+	t.Run("stable per instance", func(t *testing.T) {
+		assert.Equal(t, fleet.PhaseOffset("edge-01", interval), fleet.PhaseOffset("edge-01", interval))
+	})
+
+	t.Run("within interval and spread across instances", func(t *testing.T) {
+		offsets := make(map[time.Duration]bool)
+		for i := 0; i < 20; i++ {
+			offset := fleet.PhaseOffset(fmt.Sprintf("edge-%02d", i), interval)
+			assert.GreaterOrEqual(t, offset, time.Duration(0))
+			assert.Less(t, offset, interval)
+			offsets[offset] = true
+		}
+		assert.Greater(t, len(offsets), 15)
+	})
+
+	t.Run("zero interval", func(t *testing.T) {
+		assert.Zero(t, fleet.PhaseOffset("edge-01", 0))
+	})
+}
+
+func TestNextPhaseTick(t *testing.T) {
+	interval := 30 * time.Second
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC) // A multiple of 30s since the epoch
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		offset time.Duration
+		want   time.Time
+	}{
+		{"before offset", base.Add(2 * time.Second), 7 * time.Second, base.Add(7 * time.Second)},
+		{"after offset", base.Add(10 * time.Second), 7 * time.Second, base.Add(37 * time.Second)},
+		{"exactly on tick", base.Add(7 * time.Second), 7 * time.Second, base.Add(37 * time.Second)},
+		{"zero offset", base.Add(time.Second), 0, base.Add(30 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fleet.NextPhaseTick(tt.now, interval, tt.offset)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,380 @@
+// Package healthcheck checks whether an IP address is reachable, by connecting to its ports over
+// TCP, TLS or HTTP or by pinging it
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/netbind"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Protocols of the reachability check
+const (
+	ProtocolTCP  = "tcp"  // The port accepts a TCP connection
+	ProtocolTLS  = "tls"  // The port completes a TLS handshake
+	ProtocolHTTP = "http" // The port answers an HTTP health check request as expected, see HTTPOptions
+	ProtocolICMP = "icmp" // The IP answers an ICMP echo request
+)
+
+// Policies deciding whether an IP with several ports is reachable
+const (
+	PolicyAny = "any" // One reachable port suffices
+	PolicyAll = "all" // Every port must be reachable
+)
+
+// Defaults of zero Options fields
+const (
+	DefaultPort     = 80
+	DefaultTimeout  = 3 * time.Second
+	DefaultAttempts = 1
+)
+
+// maxBodySize limits how much of a health check response is searched for HTTPOptions.BodyContains
+const maxBodySize = 64 * 1024
+
+// Options configures a Checker. Zero fields select the defaults: a single TCP connect to port 80
+// with the any policy, timing out after 3s.
+type Options struct {
+	Protocol string
+	Ports    []int
+	Policy   string
+	Timeout  time.Duration // Timeout of each attempt, after the last echo request with ProtocolICMP
+	Attempts int           // Attempts per port before the port counts as unreachable
+	Source   netbind.Source
+	HTTP     HTTPOptions // Health check request of ProtocolHTTP
+	ICMP     ICMPOptions // Echo requests of ProtocolICMP
+	Logger   *zap.Logger // Logs which ICMP socket mode is used, nil discards
+}
+
+// HTTPOptions configures the health check request of ProtocolHTTP
+type HTTPOptions struct {
+	// URL is requested from the checked IP, its host is kept in the Host header and the TLS server
+	// name so virtual hosts are served, and its port replaces Options.Ports. Empty requests / from
+	// each port over plain HTTP.
+	URL string
+
+	// ExpectedStatus lists the status codes of a healthy response, empty accepts any 2xx or 3xx status.
+	// Redirects are not followed.
+	ExpectedStatus []int
+
+	// BodyContains is text a healthy response body must contain, empty accepts any body
+	BodyContains string
+
+	// InsecureSkipVerify skips verifying the certificate of an https URL
+	InsecureSkipVerify bool
+}
+
+// probeFunc checks a single port of ip once, the port is 0 for ICMP
+type probeFunc func(ctx context.Context, source netbind.Source, ip string, port int) error
+
+// Checker implements HealthChecker by probing the configured ports of an IP
+type Checker struct {
+	ports    []int
+	policy   string
+	http     HTTPOptions
+	httpURL  *url.URL // Parsed HTTPOptions.URL, nil without URL
+	icmp     ICMPOptions
+	timeout  time.Duration
+	attempts int
+	source   netbind.Source
+	probe    probeFunc
+}
+
+// NewChecker creates a checker with the given options
+func NewChecker(opts Options) *Checker {
+	c := &Checker{
+		ports:    opts.Ports,
+		policy:   opts.Policy,
+		timeout:  opts.Timeout,
+		attempts: opts.Attempts,
+		source:   opts.Source,
+		http:     opts.HTTP,
+		icmp:     opts.ICMP,
+	}
+	if len(c.ports) == 0 {
+		c.ports = []int{DefaultPort}
+	}
+	if c.policy == "" {
+		c.policy = PolicyAny
+	}
+	if c.timeout <= 0 {
+		c.timeout = DefaultTimeout
+	}
+	if c.attempts <= 0 {
+		c.attempts = DefaultAttempts
+	}
+
+	switch opts.Protocol {
+	case ProtocolTLS:
+		c.probe = probeTLS
+	case ProtocolHTTP:
+		c.probe = c.probeHTTP
+		if parsed, err := url.Parse(opts.HTTP.URL); err == nil && opts.HTTP.URL != "" {
+			c.httpURL = parsed
+			c.ports = []int{urlPort(parsed)}
+		}
+	case ProtocolICMP:
+		c.probe = c.probeICMP
+		c.ports = []int{0}
+		if c.icmp.Count <= 0 {
+			c.icmp.Count = DefaultPingCount
+		}
+		if c.icmp.Interval <= 0 {
+			c.icmp.Interval = DefaultPingInterval
+		}
+		if c.icmp.LossThreshold <= 0 {
+			c.icmp.LossThreshold = DefaultPingLossThreshold
+		}
+		if c.icmp.Pinger == nil {
+			c.icmp.Pinger = NewSocketPinger(opts.Logger)
+		}
+	default:
+		c.probe = probeTCP
+	}
+	return c
+}
+
+// Budget returns how long a check takes at most, if every attempt times out
+func (c *Checker) Budget() time.Duration {
+	return c.attemptTimeout() * time.Duration(c.attempts)
+}
+
+// attemptTimeout returns the timeout of an attempt, including sending the echo requests with ProtocolICMP
+func (c *Checker) attemptTimeout() time.Duration {
+	if c.icmp.Pinger != nil {
+		return c.pingDuration() + c.timeout
+	}
+	return c.timeout
+}
+
+// Check probes the ports of ip concurrently, each up to the configured number of attempts, and
+// returns nil if the policy is met and the errors of the unreachable ports otherwise. Connections
+// are made from the source, whose IP is dropped for ips of the other family.
+func (c *Checker) Check(ctx context.Context, ip string) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("%q is not a valid IP address", ip)
+	}
+	source := c.source.ForFamily(parsed.To4() == nil)
+
+	errs := make([]error, len(c.ports))
+	var wg sync.WaitGroup
+	for i, port := range c.ports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.checkPort(ctx, source, ip, port)
+		}()
+	}
+	wg.Wait()
+
+	var failed error
+	reachable := 0
+	for _, err := range errs {
+		if err == nil {
+			reachable++
+		}
+		failed = multierr.Append(failed, err)
+	}
+	if c.policy == PolicyAny && reachable > 0 {
+		return nil
+	}
+	return failed
+}
+
+// checkPort probes a port until it is reachable or the attempts are used up, and returns the
+// error of the last attempt
+func (c *Checker) checkPort(ctx context.Context, source netbind.Source, ip string, port int) error {
+	var err error
+	for attempt := 0; attempt < c.attempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.attemptTimeout())
+		err = c.probe(attemptCtx, source, ip, port)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	return err
+}
+
+// probeTCP connects to the port
+func probeTCP(ctx context.Context, source netbind.Source, ip string, port int) error {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	conn, err := source.Bind(&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.Close()
+	return nil
+}
+
+// probeTLS completes a TLS handshake with the port. The certificate is not verified, since it is
+// issued for the host names of the server rather than its IP.
+func probeTLS(ctx context.Context, source netbind.Source, ip string, port int) error {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	dialer := &tls.Dialer{
+		NetDialer: source.Bind(&net.Dialer{}),
+		Config:    &tls.Config{InsecureSkipVerify: true},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+	}
+	_ = conn.Close()
+	return nil
+}
+
+// probeHTTP sends the health check request to the port, without following redirects, and checks
+// the status and body of the response. The errors tell a failed connection, a failed request, an
+// unexpected status and a body mismatch apart.
+func (c *Checker) probeHTTP(ctx context.Context, source netbind.Source, ip string, port int) error {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	target := "http://" + addr + "/"
+	if c.httpURL != nil {
+		target = c.httpURL.String()
+	}
+
+	// Every connection goes to the checked IP, whatever the host of the URL resolves to
+	dialer := source.Bind(&net.Dialer{})
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				conn, err := dialer.DialContext(ctx, network, addr)
+				if err != nil {
+					return nil, &connectError{addr: addr, err: err}
+				}
+				return conn, nil
+			},
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: c.http.InsecureSkipVerify}, // Explicitly configured
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request for %s: %w", target, err)
+	}
+	resp, err := client.Do(req)
+	var connectErr *connectError
+	if errors.As(err, &connectErr) {
+		return connectErr
+	}
+	if err != nil {
+		return fmt.Errorf("HTTP request to %s at %s failed: %w", target, addr, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if !c.expectedStatus(resp.StatusCode) {
+		return fmt.Errorf("%s at %s returned status %d, expected %s", target, addr, resp.StatusCode, c.describeExpectedStatus())
+	}
+
+	if c.http.BodyContains != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			return fmt.Errorf("failed to read response of %s at %s: %w", target, addr, err)
+		}
+		if !strings.Contains(string(body), c.http.BodyContains) {
+			return fmt.Errorf("response of %s at %s does not contain %q", target, addr, c.http.BodyContains)
+		}
+	}
+	return nil
+}
+
+// connectError is a failed connection of a health check request, reported without the request
+type connectError struct {
+	addr string
+	err  error
+}
+
+func (e *connectError) Error() string {
+	return fmt.Sprintf("failed to connect to %s: %v", e.addr, e.err)
+}
+
+func (e *connectError) Unwrap() error {
+	return e.err
+}
+
+// expectedStatus reports whether status is that of a healthy response
+func (c *Checker) expectedStatus(status int) bool {
+	if len(c.http.ExpectedStatus) == 0 {
+		return status >= 200 && status < 400
+	}
+	return slices.Contains(c.http.ExpectedStatus, status)
+}
+
+// describeExpectedStatus returns the expected status codes for error messages
+func (c *Checker) describeExpectedStatus() string {
+	if len(c.http.ExpectedStatus) == 0 {
+		return "2xx or 3xx"
+	}
+	codes := make([]string, len(c.http.ExpectedStatus))
+	for i, status := range c.http.ExpectedStatus {
+		codes[i] = strconv.Itoa(status)
+	}
+	return strings.Join(codes, ", ")
+}
+
+// urlPort returns the port of u, or the default port of its scheme
+func urlPort(u *url.URL) int {
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	if u.Scheme == "https" {
+		return 443
+	}
+	return 80
+}
+
+// MockChecker implements HealthChecker for testing, all IPs are reachable unless an error is set
+type MockChecker struct {
+	mu     sync.Mutex
+	errs   map[string]error
+	checks map[string]int
+}
+
+// NewMockChecker creates a new mock health checker
+func NewMockChecker() *MockChecker {
+	return &MockChecker{
+		errs:   make(map[string]error),
+		checks: make(map[string]int),
+	}
+}
+
+// Check returns the error set for ip
+func (m *MockChecker) Check(ctx context.Context, ip string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks[ip]++
+	return m.errs[ip]
+}
+
+// SetError sets the error returned for ip, nil makes it reachable (for testing)
+func (m *MockChecker) SetError(ip string, err error) {
+	m.mu.Lock()
+	m.errs[ip] = err
+	m.mu.Unlock()
+}
+
+// GetChecks returns how often ip was checked
+func (m *MockChecker) GetChecks(ip string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checks[ip]
+}
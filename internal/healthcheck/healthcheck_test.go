@@ -0,0 +1,271 @@
+package healthcheck_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listen returns the port of a TCP listener on 127.0.0.1 accepting connections until the test ends
+func listen(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort returns a port on 127.0.0.1 nothing listens on
+func closedPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+	return port
+}
+
+// serverPort returns the port of a test server
+func serverPort(t *testing.T, server *httptest.Server) int {
+	t.Helper()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	n, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return n
+}
+
+func TestChecker_TCP(t *testing.T) {
+	open, closed := listen(t), closedPort(t)
+	ctx := context.Background()
+
+	checker := healthcheck.NewChecker(healthcheck.Options{Ports: []int{open}, Timeout: time.Second})
+	assert.NoError(t, checker.Check(ctx, "127.0.0.1"))
+
+	checker = healthcheck.NewChecker(healthcheck.Options{Ports: []int{closed}, Timeout: time.Second, Attempts: 2})
+	err := checker.Check(ctx, "127.0.0.1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to 127.0.0.1:"+strconv.Itoa(closed))
+}
+
+func TestChecker_Policy(t *testing.T) {
+	open, closed := listen(t), closedPort(t)
+	ctx := context.Background()
+
+	checker := healthcheck.NewChecker(healthcheck.Options{
+		Ports:   []int{closed, open},
+		Policy:  healthcheck.PolicyAny,
+		Timeout: time.Second,
+	})
+	assert.NoError(t, checker.Check(ctx, "127.0.0.1"))
+
+	checker = healthcheck.NewChecker(healthcheck.Options{
+		Ports:   []int{closed, open},
+		Policy:  healthcheck.PolicyAll,
+		Timeout: time.Second,
+	})
+	err := checker.Check(ctx, "127.0.0.1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), strconv.Itoa(closed))
+	assert.NotContains(t, err.Error(), strconv.Itoa(open))
+}
+
+func TestChecker_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	checker := healthcheck.NewChecker(healthcheck.Options{
+		Protocol: healthcheck.ProtocolTLS,
+		Ports:    []int{serverPort(t, server)},
+		Timeout:  time.Second,
+	})
+	assert.NoError(t, checker.Check(context.Background(), "127.0.0.1"))
+
+	// A plain TCP listener accepts the connection but never completes the handshake
+	checker = healthcheck.NewChecker(healthcheck.Options{
+		Protocol: healthcheck.ProtocolTLS,
+		Ports:    []int{listen(t)},
+		Timeout:  time.Second,
+	})
+	err := checker.Check(context.Background(), "127.0.0.1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TLS handshake")
+}
+
+func TestChecker_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.WriteHeader(http.StatusFound)
+		case "/healthz":
+			_, _ = w.Write([]byte("status: ok, host: " + r.Host))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	port := strconv.Itoa(serverPort(t, server))
+	ctx := context.Background()
+
+	check := func(ports []int, opts healthcheck.HTTPOptions) error {
+		return healthcheck.NewChecker(healthcheck.Options{
+			Protocol: healthcheck.ProtocolHTTP,
+			Ports:    ports,
+			Timeout:  time.Second,
+			HTTP:     opts,
+		}).Check(ctx, "127.0.0.1")
+	}
+
+	t.Run("default request accepts 2xx and 3xx", func(t *testing.T) {
+		assert.NoError(t, check([]int{serverPort(t, server)}, healthcheck.HTTPOptions{}))
+	})
+
+	t.Run("URL host is kept, connection goes to the IP", func(t *testing.T) {
+		err := check(nil, healthcheck.HTTPOptions{
+			URL:          "http://www.example.com:" + port + "/healthz",
+			BodyContains: "host: www.example.com:" + port,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("connect failure", func(t *testing.T) {
+		err := check([]int{closedPort(t)}, healthcheck.HTTPOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to connect to 127.0.0.1:")
+	})
+
+	t.Run("bad status", func(t *testing.T) {
+		err := check(nil, healthcheck.HTTPOptions{URL: "http://www.example.com:" + port + "/broken"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "returned status 502, expected 2xx or 3xx")
+
+		err = check(nil, healthcheck.HTTPOptions{URL: "http://www.example.com:" + port + "/", ExpectedStatus: []int{200, 204}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "returned status 302, expected 200, 204")
+	})
+
+	t.Run("body mismatch", func(t *testing.T) {
+		err := check(nil, healthcheck.HTTPOptions{URL: "http://www.example.com:" + port + "/healthz", BodyContains: "status: degraded"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `does not contain "status: degraded"`)
+	})
+}
+
+func TestChecker_HTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("sni: " + r.TLS.ServerName))
+	}))
+	defer server.Close()
+	target := "https://www.example.com:" + strconv.Itoa(serverPort(t, server)) + "/"
+
+	check := func(opts healthcheck.HTTPOptions) error {
+		return healthcheck.NewChecker(healthcheck.Options{
+			Protocol: healthcheck.ProtocolHTTP,
+			Timeout:  time.Second,
+			HTTP:     opts,
+		}).Check(context.Background(), "127.0.0.1")
+	}
+
+	// The test certificate is not trusted
+	err := check(healthcheck.HTTPOptions{URL: target})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP request to "+target)
+
+	assert.NoError(t, check(healthcheck.HTTPOptions{URL: target, InsecureSkipVerify: true, BodyContains: "sni: www.example.com"}))
+}
+
+func TestChecker_ICMP(t *testing.T) {
+	checker := healthcheck.NewChecker(healthcheck.Options{
+		Protocol: healthcheck.ProtocolICMP,
+		Timeout:  time.Second,
+		ICMP:     healthcheck.ICMPOptions{Count: 2, Interval: 10 * time.Millisecond},
+	})
+	err := checker.Check(context.Background(), "127.0.0.1")
+	if errors.Is(err, os.ErrPermission) {
+		t.Skip("ICMP sockets require root, CAP_NET_RAW or net.ipv4.ping_group_range")
+	}
+	assert.NoError(t, err)
+}
+
+func TestChecker_ICMPLossThreshold(t *testing.T) {
+	pinger := healthcheck.NewMockPinger()
+	ctx := context.Background()
+	check := func(ip string, lossThreshold int) error {
+		return healthcheck.NewChecker(healthcheck.Options{
+			Protocol: healthcheck.ProtocolICMP,
+			Timeout:  time.Second,
+			ICMP:     healthcheck.ICMPOptions{Count: 4, LossThreshold: lossThreshold, Pinger: pinger},
+		}).Check(ctx, ip)
+	}
+
+	// By default a single reply suffices
+	assert.NoError(t, check("203.0.113.10", 0))
+	pinger.SetReplies("203.0.113.10", 1)
+	assert.NoError(t, check("203.0.113.10", 0))
+	pinger.SetReplies("203.0.113.10", 0)
+	err := check("203.0.113.10", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "4 of 4 echo requests unanswered (100% loss, threshold 100%)")
+
+	pinger.SetReplies("203.0.113.10", 3)
+	assert.NoError(t, check("203.0.113.10", 50))
+	pinger.SetReplies("203.0.113.10", 2)
+	err = check("203.0.113.10", 50)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 4 echo requests unanswered (50% loss, threshold 50%)")
+
+	pinger.SetError("198.51.100.77", errors.New("network is unreachable"))
+	err = check("198.51.100.77", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ping 198.51.100.77 failed: network is unreachable")
+	assert.Equal(t, 1, pinger.GetPings("198.51.100.77"))
+}
+
+func TestChecker_InvalidIP(t *testing.T) {
+	err := healthcheck.NewChecker(healthcheck.Options{}).Check(context.Background(), "not-an-ip")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a valid IP address")
+}
+
+func TestChecker_Budget(t *testing.T) {
+	assert.Equal(t, healthcheck.DefaultTimeout, healthcheck.NewChecker(healthcheck.Options{}).Budget())
+	assert.Equal(t, 6*time.Second, healthcheck.NewChecker(healthcheck.Options{Timeout: 2 * time.Second, Attempts: 3}).Budget())
+
+	// Sending the echo requests adds to the timeout of each attempt
+	assert.Equal(t, 2*(2*time.Second+time.Second), healthcheck.NewChecker(healthcheck.Options{
+		Protocol: healthcheck.ProtocolICMP,
+		Timeout:  time.Second,
+		Attempts: 2,
+		ICMP:     healthcheck.ICMPOptions{Count: 5, Interval: 500 * time.Millisecond},
+	}).Budget())
+}
+
+func TestMockChecker(t *testing.T) {
+	checker := healthcheck.NewMockChecker()
+	ctx := context.Background()
+
+	assert.NoError(t, checker.Check(ctx, "203.0.113.10"))
+	checker.SetError("203.0.113.10", errors.New("unreachable"))
+	assert.EqualError(t, checker.Check(ctx, "203.0.113.10"), "unreachable")
+	assert.Equal(t, 2, checker.GetChecks("203.0.113.10"))
+	assert.Zero(t, checker.GetChecks("198.51.100.77"))
+}
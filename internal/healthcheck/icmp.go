@@ -0,0 +1,319 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/netbind"
+	"go.uber.org/zap"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Protocol numbers of ICMP and ICMPv6, as expected by icmp.ParseMessage
+const (
+	protocolICMP   = 1
+	protocolICMPv6 = 58
+)
+
+// Defaults of zero ICMPOptions fields
+const (
+	DefaultPingCount         = 3
+	DefaultPingInterval      = 200 * time.Millisecond
+	DefaultPingLossThreshold = 100
+)
+
+// ICMPOptions configures the echo requests of ProtocolICMP
+type ICMPOptions struct {
+	Count    int           // Echo requests per attempt
+	Interval time.Duration // Time between the echo requests of an attempt
+
+	// LossThreshold is the packet loss in percent at which an attempt fails, 100 accepts a
+	// single reply
+	LossThreshold int
+
+	// Pinger sends the echo requests, nil selects the ICMP sockets of the host
+	Pinger Pinger
+}
+
+// Pinger sends ICMP echo requests
+type Pinger interface {
+	// Ping sends count echo requests to ip, interval apart, from source and returns how many
+	// were answered before ctx is done
+	Ping(ctx context.Context, source netbind.Source, ip string, count int, interval time.Duration) (int, error)
+}
+
+// echoSeq numbers the echo requests of the process, so replies to earlier, timed out requests are ignored
+var echoSeq atomic.Uint32
+
+// SocketPinger implements Pinger with raw ICMP sockets, which require root or CAP_NET_RAW, and
+// falls back to unprivileged ICMP datagram sockets if raw sockets are not permitted. On Linux,
+// datagram sockets require the group of the process in net.ipv4.ping_group_range and cannot be
+// bound to the source interface.
+type SocketPinger struct {
+	logger *zap.Logger
+
+	mu           sync.Mutex
+	decided      bool // Whether the socket mode was decided by the first ping
+	unprivileged bool
+}
+
+// NewSocketPinger creates a pinger that logs which socket mode it uses
+func NewSocketPinger(logger *zap.Logger) *SocketPinger {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SocketPinger{logger: logger}
+}
+
+// Ping sends count echo requests to ip and counts the replies
+func (p *SocketPinger) Ping(ctx context.Context, source netbind.Source, ip string, count int, interval time.Duration) (int, error) {
+	dst := net.ParseIP(ip)
+	if dst == nil {
+		return 0, fmt.Errorf("%q is not a valid IP address", ip)
+	}
+	isIPv6 := dst.To4() == nil
+
+	conn, unprivileged, err := p.listen(ctx, source, isIPv6)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var target net.Addr = &net.IPAddr{IP: dst}
+	if unprivileged {
+		target = &net.UDPAddr{IP: dst}
+	}
+	return ping(ctx, conn, target, isIPv6, unprivileged, count, interval)
+}
+
+// listen opens an ICMP socket, deciding the socket mode on the first call
+func (p *SocketPinger) listen(ctx context.Context, source netbind.Source, isIPv6 bool) (net.PacketConn, bool, error) {
+	network, udpNetwork := "ip4:icmp", "udp4"
+	if isIPv6 {
+		network, udpNetwork = "ip6:ipv6-icmp", "udp6"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.unprivileged {
+		conn, err := source.ListenConfig().ListenPacket(ctx, network, source.ListenAddr(isIPv6))
+		if err == nil {
+			if !p.decided {
+				p.decided = true
+				p.logger.Info("ICMP reachability checks use raw sockets")
+			}
+			return conn, false, nil
+		}
+		if p.decided || !errors.Is(err, os.ErrPermission) {
+			return nil, false, fmt.Errorf("failed to open ICMP socket: %w", err)
+		}
+	}
+
+	conn, err := icmp.ListenPacket(udpNetwork, source.ListenAddr(isIPv6))
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to open unprivileged ICMP socket, run as root, grant CAP_NET_RAW "+
+			"or add the group of the process to net.ipv4.ping_group_range: %w", err)
+	}
+	if !p.decided {
+		p.decided, p.unprivileged = true, true
+		p.logger.Warn("Raw ICMP sockets are not permitted, ICMP reachability checks use unprivileged datagram sockets",
+			zap.String("source_interface", source.Interface))
+	}
+	return conn, true, nil
+}
+
+// ping sends the echo requests over conn and counts the distinct replies from target. Raw
+// sockets receive all ICMP messages of the host, so replies are matched by ID and sequence
+// number; datagram sockets replace the ID by their port and only receive their own replies.
+func ping(ctx context.Context, conn net.PacketConn, target net.Addr, isIPv6, unprivileged bool, count int, interval time.Duration) (int, error) {
+	protocol := protocolICMP
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if isIPv6 {
+		protocol = protocolICMPv6
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, fmt.Errorf("failed to set ICMP deadline: %w", err)
+		}
+	}
+	// Unblock the reads below if ctx is cancelled before its deadline
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	id := os.Getpid() & 0xffff
+	first := int(echoSeq.Add(uint32(count))) - count
+	seqs := make(map[int]bool, count)
+	for i := range count {
+		seqs[(first+i)&0xffff] = false
+	}
+
+	replies := make(chan int, count)
+	readErr := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			if !sameIP(peer, target) {
+				continue
+			}
+			message, err := icmp.ParseMessage(protocol, buf[:n])
+			if err != nil || message.Type != replyType {
+				continue
+			}
+			if reply, ok := message.Body.(*icmp.Echo); ok && (unprivileged || reply.ID == id) {
+				select {
+				case replies <- reply.Seq:
+				default:
+				}
+			}
+		}
+	}()
+
+	send := func(seq int) error {
+		echo := &icmp.Echo{ID: id, Seq: seq, Data: []byte("ipfailover")}
+		request, err := (&icmp.Message{Type: echoType, Body: echo}).Marshal(nil)
+		if err != nil {
+			return fmt.Errorf("failed to encode ICMP echo request: %w", err)
+		}
+		if _, err := conn.WriteTo(request, target); err != nil {
+			return fmt.Errorf("failed to send ICMP echo request to %s: %w", target, err)
+		}
+		return nil
+	}
+
+	if err := send(first & 0xffff); err != nil {
+		return 0, err
+	}
+	sent := 1
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	received := 0
+	for received < count {
+		var tick <-chan time.Time
+		if sent < count {
+			tick = ticker.C
+		}
+		select {
+		case <-tick:
+			if err := send((first + sent) & 0xffff); err != nil {
+				return received, err
+			}
+			sent++
+		case seq := <-replies:
+			if answered, ok := seqs[seq]; ok && !answered {
+				seqs[seq] = true
+				received++
+			}
+		case err := <-readErr:
+			// The deadline ends the wait for the remaining replies
+			if ctx.Err() != nil || errors.Is(err, os.ErrDeadlineExceeded) {
+				return received, nil
+			}
+			return received, fmt.Errorf("failed to receive ICMP echo replies: %w", err)
+		}
+	}
+	return received, nil
+}
+
+// sameIP reports whether the IPs of the raw or datagram socket addresses a and b are equal
+func sameIP(a, b net.Addr) bool {
+	return addrIP(a).Equal(addrIP(b))
+}
+
+// addrIP returns the IP of a raw or datagram socket address
+func addrIP(addr net.Addr) net.IP {
+	switch addr := addr.(type) {
+	case *net.IPAddr:
+		return addr.IP
+	case *net.UDPAddr:
+		return addr.IP
+	}
+	return nil
+}
+
+// probeICMP pings ip and fails if the packet loss reaches the threshold
+func (c *Checker) probeICMP(ctx context.Context, source netbind.Source, ip string, _ int) error {
+	received, err := c.icmp.Pinger.Ping(ctx, source, ip, c.icmp.Count, c.icmp.Interval)
+	if err != nil {
+		return fmt.Errorf("ping %s failed: %w", ip, err)
+	}
+	loss := (c.icmp.Count - received) * 100 / c.icmp.Count
+	if loss >= c.icmp.LossThreshold {
+		return fmt.Errorf("ping %s: %d of %d echo requests unanswered (%d%% loss, threshold %d%%)",
+			ip, c.icmp.Count-received, c.icmp.Count, loss, c.icmp.LossThreshold)
+	}
+	return nil
+}
+
+// pingDuration returns how long sending the echo requests of an attempt takes
+func (c *Checker) pingDuration() time.Duration {
+	return time.Duration(c.icmp.Count-1) * c.icmp.Interval
+}
+
+// MockPinger implements Pinger for testing, answering a set number of echo requests per ping
+type MockPinger struct {
+	mu      sync.Mutex
+	replies map[string]int
+	errs    map[string]error
+	pings   map[string]int
+}
+
+// NewMockPinger creates a mock pinger that answers every echo request
+func NewMockPinger() *MockPinger {
+	return &MockPinger{
+		replies: make(map[string]int),
+		errs:    make(map[string]error),
+		pings:   make(map[string]int),
+	}
+}
+
+// Ping returns the replies and error set for ip, by default all echo requests are answered
+func (m *MockPinger) Ping(ctx context.Context, source netbind.Source, ip string, count int, interval time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pings[ip]++
+	if err := m.errs[ip]; err != nil {
+		return 0, err
+	}
+	if replies, ok := m.replies[ip]; ok {
+		return min(replies, count), nil
+	}
+	return count, nil
+}
+
+// SetReplies sets how many echo requests to ip are answered per ping (for testing)
+func (m *MockPinger) SetReplies(ip string, replies int) {
+	m.mu.Lock()
+	m.replies[ip] = replies
+	m.mu.Unlock()
+}
+
+// SetError sets the error returned for ip (for testing)
+func (m *MockPinger) SetError(ip string, err error) {
+	m.mu.Lock()
+	m.errs[ip] = err
+	m.mu.Unlock()
+}
+
+// GetPings returns how often ip was pinged
+func (m *MockPinger) GetPings(ip string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pings[ip]
+}
@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Environment variables passed to failover hooks
+const (
+	EnvFromIP = "FAILOVER_FROM_IP"
+	EnvToIP   = "FAILOVER_TO_IP"
+)
+
+// waitDelay bounds how long Run waits for the output of a hook's child processes after the hook itself was killed
+const waitDelay = 5 * time.Second
+
+// Run executes command with sh -c, passing fromIP and toIP in FAILOVER_FROM_IP and FAILOVER_TO_IP
+// in addition to the daemon's environment. The hook is killed once timeout elapses; a zero timeout
+// only stops it with ctx. The combined output is returned, also when the hook fails.
+func Run(ctx context.Context, command, fromIP, toIP string, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), EnvFromIP+"="+fromIP, EnvToIP+"="+toIP)
+	cmd.WaitDelay = waitDelay
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	out := strings.TrimSpace(output.String())
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return out, fmt.Errorf("hook did not finish: %w", ctxErr)
+		}
+		return out, fmt.Errorf("hook failed: %w", err)
+	}
+
+	return out, nil
+}
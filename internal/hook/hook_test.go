@@ -0,0 +1,50 @@
+package hook_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	ctx := context.Background()
+
+	t.Run("passes the IPs in the environment", func(t *testing.T) {
+		out, err := hook.Run(ctx, `echo "$FAILOVER_FROM_IP -> $FAILOVER_TO_IP"`, "203.0.113.10", "198.51.100.77", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.10 -> 198.51.100.77", out)
+	})
+
+	t.Run("non-zero exit status", func(t *testing.T) {
+		out, err := hook.Run(ctx, "echo draining failed >&2; exit 3", "203.0.113.10", "198.51.100.77", time.Minute)
+		require.Error(t, err)
+		assert.Equal(t, "draining failed", out)
+
+		var exitErr *exec.ExitError
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := hook.Run(ctx, "exec sleep 10", "203.0.113.10", "198.51.100.77", 100*time.Millisecond)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("zero timeout", func(t *testing.T) {
+		out, err := hook.Run(ctx, "echo done", "", "203.0.113.10", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "done", out)
+	})
+}
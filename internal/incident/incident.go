@@ -0,0 +1,164 @@
+package incident
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// NotifyFunc sends a notification event through the configured notification channels
+type NotifyFunc func(ctx context.Context, event interfaces.NotificationEvent)
+
+// Tracker maintains per-provider DNS update failure streaks in the state store and opens an
+// incident when a streak reaches the threshold. The incident is closed by the next successful update.
+// Exactly one notification is sent when an incident opens and one when it is resolved.
+type Tracker struct {
+	store     interfaces.StateStore
+	notify    NotifyFunc
+	threshold int
+	now       func() time.Time
+	logger    *zap.Logger
+}
+
+// NewTracker creates a new incident tracker. A threshold of 0 tracks streaks without opening incidents.
+// A nil notify only logs incidents.
+func NewTracker(store interfaces.StateStore, notify NotifyFunc, threshold int, logger *zap.Logger) *Tracker {
+	return NewTrackerWithClock(store, notify, threshold, time.Now, logger)
+}
+
+// NewTrackerWithClock creates a new incident tracker using now as the time source
+func NewTrackerWithClock(
+	store interfaces.StateStore,
+	notify NotifyFunc,
+	threshold int,
+	now func() time.Time,
+	logger *zap.Logger,
+) *Tracker {
+	return &Tracker{
+		store:     store,
+		notify:    notify,
+		threshold: threshold,
+		now:       now,
+		logger:    logger,
+	}
+}
+
+// RecordFailure extends the failure streak of provider and opens an incident once it reaches the threshold
+func (t *Tracker) RecordFailure(ctx context.Context, provider string, cause error) error {
+	streaks, err := t.streaks(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := t.now()
+	streak, ok := streaks[provider]
+	if !ok {
+		streak = interfaces.ProviderFailureStreak{
+			Provider:     provider,
+			FirstFailure: now,
+		}
+	}
+	streak.FailureCount++
+	streak.LastFailure = now
+	if cause != nil {
+		streak.LastError = cause.Error()
+	}
+
+	opened := streak.IncidentOpened == nil && t.threshold > 0 && streak.FailureCount >= t.threshold
+	if opened {
+		streak.IncidentOpened = &now
+	}
+
+	if err := t.store.SetProviderFailureStreak(ctx, streak); err != nil {
+		return err
+	}
+
+	if opened {
+		t.report(ctx, interfaces.NotificationEvent{
+			Type:     notification.TypeIncidentOpened,
+			Provider: provider,
+			Message: fmt.Sprintf("incident opened: %d consecutive DNS update failures for provider %s since %s, last error: %s",
+				streak.FailureCount, provider, streak.FirstFailure.Format(time.RFC3339), streak.LastError),
+			Timestamp: now,
+		})
+	}
+
+	return nil
+}
+
+// RecordSuccess ends the failure streak of provider and resolves its open incident, if any
+func (t *Tracker) RecordSuccess(ctx context.Context, provider string) error {
+	streaks, err := t.streaks(ctx)
+	if err != nil {
+		return err
+	}
+
+	streak, ok := streaks[provider]
+	if !ok {
+		return nil
+	}
+
+	if err := t.store.ResetProviderFailureStreak(ctx, provider); err != nil {
+		return err
+	}
+
+	if streak.IncidentOpened != nil {
+		now := t.now()
+		t.report(ctx, interfaces.NotificationEvent{
+			Type:     notification.TypeIncidentResolved,
+			Provider: provider,
+			Message: fmt.Sprintf("incident resolved: DNS updates for provider %s succeeded after %d consecutive failures, incident open for %s",
+				provider, streak.FailureCount, now.Sub(*streak.IncidentOpened).Round(time.Second)),
+			Timestamp: now,
+		})
+	}
+
+	return nil
+}
+
+// OpenIncidents returns the failure streaks with an open incident, ordered by provider
+func OpenIncidents(streaks map[string]interfaces.ProviderFailureStreak) []interfaces.ProviderFailureStreak {
+	incidents := make([]interfaces.ProviderFailureStreak, 0, len(streaks))
+	for _, streak := range streaks {
+		if streak.IncidentOpened != nil {
+			incidents = append(incidents, streak)
+		}
+	}
+
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].Provider < incidents[j].Provider
+	})
+	return incidents
+}
+
+// streaks returns the stored failure streaks, treating missing state as no streaks
+func (t *Tracker) streaks(ctx context.Context) (map[string]interfaces.ProviderFailureStreak, error) {
+	streaks, err := t.store.GetProviderFailureStreaks(ctx)
+	if err != nil && !errors.IsNotFoundError(err) {
+		return nil, err
+	}
+	return streaks, nil
+}
+
+// report logs an incident event, opened incidents as warnings, and sends it through notify
+func (t *Tracker) report(ctx context.Context, event interfaces.NotificationEvent) {
+	fields := []zap.Field{
+		zap.String("type", event.Type),
+		zap.String("provider", event.Provider),
+	}
+	if event.Type == notification.TypeIncidentOpened {
+		t.logger.Warn(event.Message, fields...)
+	} else {
+		t.logger.Info(event.Message, fields...)
+	}
+
+	if t.notify != nil {
+		t.notify(ctx, event)
+	}
+}
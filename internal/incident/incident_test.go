@@ -0,0 +1,197 @@
+package incident_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/incident"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/internal/state"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordingNotifier records the notification events sent by a tracker
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []interfaces.NotificationEvent
+}
+
+func (n *recordingNotifier) notify(ctx context.Context, event interfaces.NotificationEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+}
+
+// fakeNow returns a clock function starting at a fixed time that advances by a minute per call
+func fakeNow() func() time.Time {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+func TestTracker_OpenAndResolveIncident(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMockStateStore()
+	notifier := &recordingNotifier{}
+	tracker := incident.NewTrackerWithClock(store, notifier.notify, 3, fakeNow(), zap.NewNop())
+
+	// Below the threshold only the streak is tracked
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("connection refused")))
+
+	streaks, err := store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, streaks["cloudflare"].FailureCount)
+	assert.Nil(t, streaks["cloudflare"].IncidentOpened)
+	assert.Empty(t, incident.OpenIncidents(streaks))
+	assert.Empty(t, notifier.events)
+
+	// Reaching the threshold opens the incident
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("HTTP 502")))
+
+	streaks, err = store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	open := incident.OpenIncidents(streaks)
+	require.Len(t, open, 1)
+	assert.Equal(t, "cloudflare", open[0].Provider)
+	assert.Equal(t, 3, open[0].FailureCount)
+	assert.Equal(t, "HTTP 502", open[0].LastError)
+	assert.Equal(t, time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC), open[0].FirstFailure)
+	require.NotNil(t, open[0].IncidentOpened)
+
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, notification.TypeIncidentOpened, notifier.events[0].Type)
+	assert.Equal(t, "cloudflare", notifier.events[0].Provider)
+	assert.Contains(t, notifier.events[0].Message, "3 consecutive DNS update failures")
+	assert.Contains(t, notifier.events[0].Message, "HTTP 502")
+
+	// Further failures extend the streak without notifying again
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("HTTP 503")))
+
+	streaks, err = store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, streaks["cloudflare"].FailureCount)
+	assert.Equal(t, "HTTP 503", streaks["cloudflare"].LastError)
+	assert.Len(t, notifier.events, 1)
+
+	// The next success closes the incident
+	require.NoError(t, tracker.RecordSuccess(ctx, "cloudflare"))
+
+	streaks, err = store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, streaks)
+
+	require.Len(t, notifier.events, 2)
+	assert.Equal(t, notification.TypeIncidentResolved, notifier.events[1].Type)
+	assert.Equal(t, "cloudflare", notifier.events[1].Provider)
+	assert.Contains(t, notifier.events[1].Message, "after 4 consecutive failures")
+
+	// Further successes do not notify
+	require.NoError(t, tracker.RecordSuccess(ctx, "cloudflare"))
+	assert.Len(t, notifier.events, 2)
+}
+
+func TestTracker_SuccessBelowThreshold(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMockStateStore()
+	notifier := &recordingNotifier{}
+	tracker := incident.NewTrackerWithClock(store, notifier.notify, 3, fakeNow(), zap.NewNop())
+
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	require.NoError(t, tracker.RecordSuccess(ctx, "cloudflare"))
+
+	// The streak restarts after a success
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+
+	streaks, err := store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, streaks["cloudflare"].FailureCount)
+	assert.Empty(t, notifier.events)
+}
+
+func TestTracker_ProvidersAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMockStateStore()
+	notifier := &recordingNotifier{}
+	tracker := incident.NewTrackerWithClock(store, notifier.notify, 2, fakeNow(), zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, tracker.RecordFailure(ctx, "route53", fmt.Errorf("throttled")))
+		require.NoError(t, tracker.RecordSuccess(ctx, "cloudflare"))
+	}
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+
+	streaks, err := store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	open := incident.OpenIncidents(streaks)
+	require.Len(t, open, 1)
+	assert.Equal(t, "route53", open[0].Provider)
+	assert.Equal(t, 1, streaks["cloudflare"].FailureCount)
+	assert.Len(t, notifier.events, 1)
+}
+
+func TestTracker_ZeroThresholdDisablesIncidents(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMockStateStore()
+	notifier := &recordingNotifier{}
+	tracker := incident.NewTrackerWithClock(store, notifier.notify, 0, fakeNow(), zap.NewNop())
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	}
+
+	streaks, err := store.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 10, streaks["cloudflare"].FailureCount)
+	assert.Empty(t, incident.OpenIncidents(streaks))
+	assert.Empty(t, notifier.events)
+}
+
+func TestTracker_LogsIncidents(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMockStateStore()
+	core, logs := observer.New(zapcore.InfoLevel)
+	tracker := incident.NewTrackerWithClock(store, nil, 1, fakeNow(), zap.New(core))
+
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	require.NoError(t, tracker.RecordSuccess(ctx, "cloudflare"))
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Contains(t, entries[0].Message, "incident opened")
+	assert.Equal(t, "cloudflare", entries[0].ContextMap()["provider"])
+	assert.Equal(t, zapcore.InfoLevel, entries[1].Level)
+	assert.Contains(t, entries[1].Message, "incident resolved")
+}
+
+func TestTracker_IncidentSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	notifier := &recordingNotifier{}
+
+	tracker := incident.NewTrackerWithClock(state.NewFileStateStore(stateFile, zap.NewNop()), notifier.notify, 1, fakeNow(), zap.NewNop())
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	require.Len(t, notifier.events, 1)
+
+	// A new tracker on the same state file neither reopens nor forgets the incident
+	tracker = incident.NewTrackerWithClock(state.NewFileStateStore(stateFile, zap.NewNop()), notifier.notify, 1, fakeNow(), zap.NewNop())
+	require.NoError(t, tracker.RecordFailure(ctx, "cloudflare", fmt.Errorf("timeout")))
+	assert.Len(t, notifier.events, 1)
+
+	require.NoError(t, tracker.RecordSuccess(ctx, "cloudflare"))
+	require.Len(t, notifier.events, 2)
+	assert.Equal(t, notification.TypeIncidentResolved, notifier.events[1].Type)
+}
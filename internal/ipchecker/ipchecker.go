@@ -6,9 +6,11 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/devhat/ipfailover/internal/netbind"
 	"github.com/devhat/ipfailover/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -21,31 +23,93 @@ const (
 // HTTPChecker implements IPChecker using HTTP endpoints
 type HTTPChecker struct {
 	client    *http.Client
+	dialer    *net.Dialer
+	source    netbind.Source
+	network   string // Network the endpoints are connected over, empty for both IPv4 and IPv6
 	endpoints []string
 	logger    *zap.Logger
 }
 
 // NewHTTPChecker creates a new HTTP-based IP checker
 func NewHTTPChecker(endpoints []string, logger *zap.Logger) *HTTPChecker {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout: 5 * time.Second,
-			}).DialContext,
+			DialContext:         dialer.DialContext,
 			TLSHandshakeTimeout: 5 * time.Second,
 		},
 	}
 
 	return &HTTPChecker{
 		client:    client,
+		dialer:    dialer,
 		endpoints: endpoints,
 		logger:    logger,
 	}
 }
 
+// SetProxy sends the check requests through proxy, see http.Transport.Proxy.
+// Without a proxy, the checks connect directly.
+func (h *HTTPChecker) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	if transport, ok := h.client.Transport.(*http.Transport); ok {
+		transport.Proxy = proxy
+	}
+}
+
+// CheckResult is the outcome of a successful IP check
+type CheckResult struct {
+	IP           string
+	Endpoint     string        // Endpoint that returned the IP
+	ResponseTime time.Duration // Response time of Endpoint
+}
+
+// SetSource makes the checks connect from the local address and interface of source, e.g. to
+// detect the public IP of a chosen uplink
+func (h *HTTPChecker) SetSource(source netbind.Source) {
+	h.source = source
+	h.updateDialer()
+}
+
+// SetNetwork makes the checks connect to the endpoints over network, "tcp4" or "tcp6", so they
+// detect the public address of that family, and rejects addresses of the other family
+func (h *HTTPChecker) SetNetwork(network string) {
+	h.network = network
+	h.updateDialer()
+}
+
+// updateDialer applies the source and network to the connections of the checks
+func (h *HTTPChecker) updateDialer() {
+	transport, ok := h.client.Transport.(*http.Transport)
+	if !ok {
+		return
+	}
+
+	dialer := h.source.Bind(h.dialer)
+	network := h.network
+	if network == "" {
+		transport.DialContext = dialer.DialContext
+		return
+	}
+	transport.DialContext = func(ctx context.Context, _, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
 // GetCurrentIP returns the current public IP address
 func (h *HTTPChecker) GetCurrentIP(ctx context.Context) (string, error) {
+	result, err := h.Check(ctx)
+	if err != nil {
+		return "", err
+	}
+	return result.IP, nil
+}
+
+// Check returns the current public IP address, like GetCurrentIP, along with the endpoint that
+// returned it and its response time
+func (h *HTTPChecker) Check(ctx context.Context) (*CheckResult, error) {
 	var lastErr error
 
 	for i, endpoint := range h.endpoints {
@@ -54,6 +118,7 @@ func (h *HTTPChecker) GetCurrentIP(ctx context.Context) (string, error) {
 			zap.Int("attempt", i+1),
 		)
 
+		start := time.Now()
 		ip, err := h.checkEndpoint(ctx, endpoint)
 		if err != nil {
 			h.logger.Warn("IP check failed",
@@ -69,11 +134,11 @@ func (h *HTTPChecker) GetCurrentIP(ctx context.Context) (string, error) {
 				zap.String("endpoint", endpoint),
 				zap.String("ip", ip),
 			)
-			return ip, nil
+			return &CheckResult{IP: ip, Endpoint: endpoint, ResponseTime: time.Since(start)}, nil
 		}
 	}
 
-	return "", errors.NewIPCheckError("all endpoints failed", lastErr)
+	return nil, errors.NewIPCheckError("all endpoints failed", lastErr)
 }
 
 // checkEndpoint checks a single endpoint for the current IP
@@ -115,6 +180,11 @@ func (h *HTTPChecker) checkEndpoint(ctx context.Context, endpoint string) (strin
 		return "", fmt.Errorf("invalid IP address: %w", err)
 	}
 
+	// An endpoint may answer with the address of the other family, e.g. from a header set by a proxy
+	if ipv4 := net.ParseIP(ip).To4() != nil; (h.network == "tcp6" && ipv4) || (h.network == "tcp4" && !ipv4) {
+		return "", fmt.Errorf("endpoint returned %s, which is not an address of network %s", ip, h.network)
+	}
+
 	return ip, nil
 }
 
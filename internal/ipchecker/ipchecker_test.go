@@ -4,10 +4,12 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/devhat/ipfailover/internal/ipchecker"
+	"github.com/devhat/ipfailover/internal/netbind"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -106,6 +108,30 @@ func TestHTTPChecker_GetCurrentIP_MultipleEndpoints(t *testing.T) {
 	assert.Equal(t, "203.0.113.10", ip)
 }
 
+func TestHTTPChecker_Check(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer failing.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := w.Write([]byte("203.0.113.10")); err != nil {
+			t.Errorf("failed to write IP response: %v", err)
+		}
+	}))
+	defer slow.Close()
+
+	checker := ipchecker.NewHTTPChecker([]string{failing.URL, slow.URL}, zap.NewNop())
+
+	result, err := checker.Check(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", result.IP)
+	assert.Equal(t, slow.URL, result.Endpoint)
+	assert.GreaterOrEqual(t, result.ResponseTime, 20*time.Millisecond)
+}
+
 func TestHTTPChecker_GetCurrentIP_AllEndpointsFail(t *testing.T) {
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
@@ -257,3 +283,83 @@ func TestHTTPChecker_UserAgent(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "ipfailover/1.0", userAgent)
 }
+
+func TestHTTPChecker_Proxy(t *testing.T) {
+	// The proxy answers for the check endpoint, whose host does not resolve
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		if _, err := w.Write([]byte("203.0.113.10")); err != nil {
+			t.Errorf("failed to write mock response: %v", err)
+		}
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	assert.NoError(t, err)
+
+	checker := ipchecker.NewHTTPChecker([]string{"http://ip.example.invalid/"}, zap.NewNop())
+	checker.SetProxy(http.ProxyURL(proxyURL))
+
+	ip, err := checker.GetCurrentIP(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", ip)
+	assert.Equal(t, "http://ip.example.invalid/", proxiedURL)
+}
+
+func TestHTTPChecker_Source(t *testing.T) {
+	var remoteAddr string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+		if _, err := w.Write([]byte("203.0.113.10")); err != nil {
+			t.Errorf("failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	checker := ipchecker.NewHTTPChecker([]string{server.URL}, zap.NewNop())
+	checker.SetSource(netbind.Source{IP: "127.0.0.1"})
+
+	ip, err := checker.GetCurrentIP(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", ip)
+	assert.Regexp(t, `^127\.0\.0\.1:\d+$`, remoteAddr)
+
+	// A source the checker cannot connect from fails the check
+	checker = ipchecker.NewHTTPChecker([]string{server.URL}, zap.NewNop())
+	checker.SetSource(netbind.Source{IP: "192.0.2.1"})
+	_, err = checker.GetCurrentIP(context.Background())
+	assert.Error(t, err)
+}
+
+func TestHTTPChecker_Network(t *testing.T) {
+	answer := "2001:db8::10"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte(answer)); err != nil {
+			t.Errorf("failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	checker := ipchecker.NewHTTPChecker([]string{server.URL}, zap.NewNop())
+	checker.SetNetwork("tcp4")
+
+	// The test server listens on IPv4, an IPv6 answer is rejected
+	_, err := checker.GetCurrentIP(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not an address of network tcp4")
+	}
+
+	answer = "203.0.113.10"
+	ip, err := checker.GetCurrentIP(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", ip)
+
+	// The endpoint cannot be reached over IPv6
+	checker = ipchecker.NewHTTPChecker([]string{server.URL}, zap.NewNop())
+	checker.SetNetwork("tcp6")
+	_, err = checker.GetCurrentIP(context.Background())
+	assert.Error(t, err)
+}
@@ -2,8 +2,10 @@ package metrics
 
 import (
 	"context"
+	"crypto/subtle"
 	"net"
 	"net/http"
+	"net/netip"
 	"sync"
 	"time"
 
@@ -12,16 +14,48 @@ import (
 	"go.uber.org/zap"
 )
 
+// Directions of failovers, see ObserveFailover
+const (
+	DirectionPrimaryToSecondary = "primary_to_secondary"
+	DirectionSecondaryToPrimary = "secondary_to_primary"
+)
+
 // PrometheusCollector implements MetricsCollector using Prometheus
 type PrometheusCollector struct {
 	registry           *prometheus.Registry
 	ipChecksTotal      prometheus.Counter
 	ipCheckErrorsTotal prometheus.Counter
 	dnsUpdatesTotal    *prometheus.CounterVec
+	recordWritesTotal  *prometheus.CounterVec
+	recordNoopsTotal   *prometheus.CounterVec
 	dnsErrorsTotal     *prometheus.CounterVec
+	dryRunUpdatesTotal *prometheus.CounterVec
+	dnsConflictsTotal  *prometheus.CounterVec
+	providerRetries    *prometheus.CounterVec
+	httpRetries        *prometheus.CounterVec
+	circuitStateGauge  *prometheus.GaugeVec
+	changeSyncSeconds  *prometheus.HistogramVec
+	updateSeconds      *prometheus.HistogramVec
+	reachSeconds       *prometheus.HistogramVec
+	failureCountGauge  *prometheus.GaugeVec
+	startTimeGauge     prometheus.Gauge
+	buildInfoGauge     *prometheus.GaugeVec
 	currentIPGauge     *prometheus.GaugeVec
+	currentIPv6Gauge   *prometheus.GaugeVec
 	lastChangeGauge    prometheus.Gauge
+	forcedTargetGauge  *prometheus.GaugeVec
+	failoverSeconds    prometheus.Histogram
+	failoversTotal     *prometheus.CounterVec
+	suppressedTotal    prometheus.Counter
 	logger             *zap.Logger
+
+	handlersMu    sync.Mutex
+	handlers      map[string]http.Handler // Additional endpoints served by the metrics server
+	tlsCertFile   string                  // Serve HTTPS with this certificate and key, if both are set
+	tlsKeyFile    string
+	basicAuthUser string // Require HTTP Basic Auth for /metrics, if set
+	basicAuthPass string
+	allowedCIDRs  []netip.Prefix // Source IPs allowed to connect, all if empty
 }
 
 // NewPrometheusCollector creates a new Prometheus metrics collector
@@ -41,20 +75,96 @@ func NewPrometheusCollector(logger *zap.Logger) *PrometheusCollector {
 		}),
 		dnsUpdatesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "ipfailover_updates_total",
-			Help: "Total number of DNS updates by provider and record",
+			Help: "Total number of DNS updates by provider and record (record writes plus no-ops)",
+		}, []string{"provider", "record"}),
+		recordWritesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_record_writes_total",
+			Help: "Total number of DNS records changed at the provider by provider and record",
+		}, []string{"provider", "record"}),
+		recordNoopsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_record_noops_total",
+			Help: "Total number of DNS record updates skipped because the record was already correct by provider and record",
 		}, []string{"provider", "record"}),
 		dnsErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "ipfailover_update_errors_total",
 			Help: "Total number of failed DNS updates by provider and record",
 		}, []string{"provider", "record"}),
+		dryRunUpdatesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_dry_run_updates_total",
+			Help: "Total number of DNS updates skipped in dry-run mode by provider and record",
+		}, []string{"provider", "record"}),
+		dnsConflictsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_update_conflicts_total",
+			Help: "Total number of DNS updates that found the record modified concurrently by provider and record",
+		}, []string{"provider", "record"}),
+		providerRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_provider_retries_total",
+			Help: "Total number of retried DNS provider calls by provider and record",
+		}, []string{"provider", "record"}),
+		httpRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_provider_http_retries_total",
+			Help: "Total number of retried requests to DNS provider APIs by provider",
+		}, []string{"provider"}),
+		circuitStateGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ipfailover_circuit_state",
+			Help: "Circuit breaker state by DNS provider (0 closed, 1 half-open, 2 open)",
+		}, []string{"provider"}),
+		changeSyncSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ipfailover_change_sync_duration_seconds",
+			Help:    "Time until a DNS change was in sync on the provider's authoritative servers by provider",
+			Buckets: []float64{1, 5, 10, 20, 30, 45, 60, 90, 120, 180, 300},
+		}, []string{"provider"}),
+		updateSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ipfailover_provider_update_duration_seconds",
+			Help:    "Duration of DNS provider calls writing a record by provider and record",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"provider", "record"}),
+		reachSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ipfailover_reachability_check_duration_seconds",
+			Help:    "Duration of reachability checks of the primary and secondary IPs by IP",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"ip"}),
+		failureCountGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ipfailover_primary_failure_count",
+			Help: "Consecutive failed reachability checks of the primary IP, failover happens at failover_retries",
+		}, []string{"primary_ip"}),
+		startTimeGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ipfailover_start_timestamp_seconds",
+			Help: "Timestamp at which the daemon started, uptime is time() minus this",
+		}),
+		buildInfoGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ipfailover_build_info",
+			Help: "Version and build time of the daemon, always 1",
+		}, []string{"version", "build_time"}),
 		currentIPGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ipfailover_current_ip_info",
 			Help: "Current detected IP address",
 		}, []string{"ip"}),
+		currentIPv6Gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ipfailover_current_ipv6_info",
+			Help: "Current detected IPv6 address, in dual-stack mode",
+		}, []string{"ip"}),
 		lastChangeGauge: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "ipfailover_last_change_timestamp_seconds",
 			Help: "Timestamp of the last IP change",
 		}),
+		forcedTargetGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ipfailover_forced_target",
+			Help: "Target pinned by a manual failover (primary or secondary), always 1; absent while failover decisions are automatic",
+		}, []string{"target"}),
+		failoverSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ipfailover_failover_duration_seconds",
+			Help:    "Time from starting a failover or failback until its DNS records were updated",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 45, 60},
+		}),
+		failoversTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ipfailover_failover_total",
+			Help: "Total number of completed failovers by direction (primary_to_secondary or secondary_to_primary)",
+		}, []string{"direction"}),
+		suppressedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ipfailover_changes_suppressed_total",
+			Help: "Total number of IP changes suppressed because min_change_interval had not elapsed since the last change",
+		}),
 		logger: logger,
 	}
 
@@ -63,9 +173,27 @@ func NewPrometheusCollector(logger *zap.Logger) *PrometheusCollector {
 		pc.ipChecksTotal,
 		pc.ipCheckErrorsTotal,
 		pc.dnsUpdatesTotal,
+		pc.recordWritesTotal,
+		pc.recordNoopsTotal,
 		pc.dnsErrorsTotal,
+		pc.dryRunUpdatesTotal,
+		pc.dnsConflictsTotal,
+		pc.providerRetries,
+		pc.httpRetries,
+		pc.circuitStateGauge,
+		pc.changeSyncSeconds,
+		pc.updateSeconds,
+		pc.reachSeconds,
+		pc.failureCountGauge,
+		pc.startTimeGauge,
+		pc.buildInfoGauge,
 		pc.currentIPGauge,
+		pc.currentIPv6Gauge,
 		pc.lastChangeGauge,
+		pc.forcedTargetGauge,
+		pc.failoverSeconds,
+		pc.failoversTotal,
+		pc.suppressedTotal,
 	)
 
 	return pc
@@ -84,6 +212,8 @@ func (pc *PrometheusCollector) IncrementIPCheckErrors() {
 }
 
 // IncrementDNSUpdates increments the DNS updates counter
+//
+// Deprecated: use IncrementRecordWrites or IncrementRecordNoops, which also count towards ipfailover_updates_total.
 func (pc *PrometheusCollector) IncrementDNSUpdates(provider, record string) {
 	pc.dnsUpdatesTotal.WithLabelValues(provider, record).Inc()
 	pc.logger.Debug("incremented DNS updates counter",
@@ -92,6 +222,26 @@ func (pc *PrometheusCollector) IncrementDNSUpdates(provider, record string) {
 	)
 }
 
+// IncrementRecordWrites increments the record writes counter and the DNS updates counter
+func (pc *PrometheusCollector) IncrementRecordWrites(provider, record string) {
+	pc.recordWritesTotal.WithLabelValues(provider, record).Inc()
+	pc.dnsUpdatesTotal.WithLabelValues(provider, record).Inc()
+	pc.logger.Debug("incremented record writes counter",
+		zap.String("provider", provider),
+		zap.String("record", record),
+	)
+}
+
+// IncrementRecordNoops increments the record no-ops counter and the DNS updates counter
+func (pc *PrometheusCollector) IncrementRecordNoops(provider, record string) {
+	pc.recordNoopsTotal.WithLabelValues(provider, record).Inc()
+	pc.dnsUpdatesTotal.WithLabelValues(provider, record).Inc()
+	pc.logger.Debug("incremented record no-ops counter",
+		zap.String("provider", provider),
+		zap.String("record", record),
+	)
+}
+
 // IncrementDNSErrors increments the DNS update errors counter
 func (pc *PrometheusCollector) IncrementDNSErrors(provider, record string) {
 	pc.dnsErrorsTotal.WithLabelValues(provider, record).Inc()
@@ -101,6 +251,105 @@ func (pc *PrometheusCollector) IncrementDNSErrors(provider, record string) {
 	)
 }
 
+// IncrementDryRunUpdates increments the dry-run DNS updates counter
+func (pc *PrometheusCollector) IncrementDryRunUpdates(provider, record string) {
+	pc.dryRunUpdatesTotal.WithLabelValues(provider, record).Inc()
+	pc.logger.Debug("incremented dry-run DNS updates counter",
+		zap.String("provider", provider),
+		zap.String("record", record),
+	)
+}
+
+// IncrementDNSConflicts increments the DNS update conflicts counter
+func (pc *PrometheusCollector) IncrementDNSConflicts(provider, record string) {
+	pc.dnsConflictsTotal.WithLabelValues(provider, record).Inc()
+	pc.logger.Debug("incremented DNS conflicts counter",
+		zap.String("provider", provider),
+		zap.String("record", record),
+	)
+}
+
+// IncrementProviderRetries increments the provider retries counter
+func (pc *PrometheusCollector) IncrementProviderRetries(provider, record string) {
+	pc.providerRetries.WithLabelValues(provider, record).Inc()
+	pc.logger.Debug("incremented provider retries counter",
+		zap.String("provider", provider),
+		zap.String("record", record),
+	)
+}
+
+// IncrementHTTPRetries increments the provider API request retries counter
+func (pc *PrometheusCollector) IncrementHTTPRetries(provider string) {
+	pc.httpRetries.WithLabelValues(provider).Inc()
+	pc.logger.Debug("incremented provider HTTP retries counter",
+		zap.String("provider", provider),
+	)
+}
+
+// SetCircuitState sets the circuit breaker state gauge of a provider
+func (pc *PrometheusCollector) SetCircuitState(provider string, state int) {
+	pc.circuitStateGauge.WithLabelValues(provider).Set(float64(state))
+	pc.logger.Debug("set circuit state gauge",
+		zap.String("provider", provider),
+		zap.Int("state", state),
+	)
+}
+
+// ObserveChangeSync records the time until a DNS change was in sync
+func (pc *PrometheusCollector) ObserveChangeSync(provider string, duration time.Duration) {
+	pc.changeSyncSeconds.WithLabelValues(provider).Observe(duration.Seconds())
+	pc.logger.Debug("observed change sync duration",
+		zap.String("provider", provider),
+		zap.Duration("duration", duration),
+	)
+}
+
+// ObserveDNSUpdateDuration records the duration of a provider call writing a DNS record
+func (pc *PrometheusCollector) ObserveDNSUpdateDuration(provider, record string, duration time.Duration) {
+	pc.updateSeconds.WithLabelValues(provider, record).Observe(duration.Seconds())
+	pc.logger.Debug("observed DNS update duration",
+		zap.String("provider", provider),
+		zap.String("record", record),
+		zap.Duration("duration", duration),
+	)
+}
+
+// ObserveReachabilityDuration records the duration of a reachability check of an IP
+func (pc *PrometheusCollector) ObserveReachabilityDuration(ip string, duration time.Duration) {
+	pc.reachSeconds.WithLabelValues(ip).Observe(duration.Seconds())
+	pc.logger.Debug("observed reachability check duration",
+		zap.String("ip", ip),
+		zap.Duration("duration", duration),
+	)
+}
+
+// SetPrimaryFailureCount sets the primary failure count gauge of a primary IP
+func (pc *PrometheusCollector) SetPrimaryFailureCount(primaryIP string, count int) {
+	pc.failureCountGauge.WithLabelValues(primaryIP).Set(float64(count))
+	pc.logger.Debug("set primary failure count gauge",
+		zap.String("primary_ip", primaryIP),
+		zap.Int("count", count),
+	)
+}
+
+// SetStartTime sets the start timestamp gauge
+func (pc *PrometheusCollector) SetStartTime(t time.Time) {
+	pc.startTimeGauge.Set(float64(t.Unix()))
+	pc.logger.Debug("set start timestamp",
+		zap.Time("timestamp", t),
+	)
+}
+
+// SetBuildInfo sets the build info gauge
+func (pc *PrometheusCollector) SetBuildInfo(version, buildTime string) {
+	pc.buildInfoGauge.Reset()
+	pc.buildInfoGauge.WithLabelValues(version, buildTime).Set(1)
+	pc.logger.Debug("set build info",
+		zap.String("version", version),
+		zap.String("build_time", buildTime),
+	)
+}
+
 // SetCurrentIP sets the current IP gauge
 func (pc *PrometheusCollector) SetCurrentIP(ip string) {
 	// Reset all labels first
@@ -113,6 +362,15 @@ func (pc *PrometheusCollector) SetCurrentIP(ip string) {
 	)
 }
 
+// SetCurrentIPv6 sets the current IPv6 gauge
+func (pc *PrometheusCollector) SetCurrentIPv6(ip string) {
+	pc.currentIPv6Gauge.Reset()
+	pc.currentIPv6Gauge.WithLabelValues(ip).Set(1)
+	pc.logger.Debug("set current IPv6 gauge",
+		zap.String("ip", ip),
+	)
+}
+
 // SetLastChangeTime sets the last change timestamp
 func (pc *PrometheusCollector) SetLastChangeTime(t time.Time) {
 	pc.lastChangeGauge.Set(float64(t.Unix()))
@@ -121,10 +379,113 @@ func (pc *PrometheusCollector) SetLastChangeTime(t time.Time) {
 	)
 }
 
-// StartMetricsServer starts the Prometheus metrics HTTP server
+// SetForcedTarget sets the forced target gauge, which is removed if target is empty
+func (pc *PrometheusCollector) SetForcedTarget(target string) {
+	pc.forcedTargetGauge.Reset()
+	if target != "" {
+		pc.forcedTargetGauge.WithLabelValues(target).Set(1)
+	}
+	pc.logger.Debug("set forced target gauge",
+		zap.String("target", target),
+	)
+}
+
+// ObserveFailover counts a completed failover in direction and records how long it took
+func (pc *PrometheusCollector) ObserveFailover(direction string, duration time.Duration) {
+	pc.failoverSeconds.Observe(duration.Seconds())
+	pc.failoversTotal.WithLabelValues(direction).Inc()
+	pc.logger.Debug("observed failover",
+		zap.String("direction", direction),
+		zap.Duration("duration", duration),
+	)
+}
+
+// IncrementChangesSuppressed counts an IP change suppressed by the minimum change interval
+func (pc *PrometheusCollector) IncrementChangesSuppressed() {
+	pc.suppressedTotal.Inc()
+	pc.logger.Debug("incremented suppressed changes counter")
+}
+
+// Handle registers an additional endpoint on the metrics server, such as /status.
+// Handlers must be registered before StartMetricsServer is called.
+func (pc *PrometheusCollector) Handle(pattern string, handler http.Handler) {
+	pc.handlersMu.Lock()
+	defer pc.handlersMu.Unlock()
+
+	if pc.handlers == nil {
+		pc.handlers = make(map[string]http.Handler)
+	}
+	pc.handlers[pattern] = handler
+}
+
+// SetTLS makes the metrics server serve HTTPS with the PEM encoded certificate and key files.
+// It must be called before StartMetricsServer.
+func (pc *PrometheusCollector) SetTLS(certFile, keyFile string) {
+	pc.handlersMu.Lock()
+	defer pc.handlersMu.Unlock()
+
+	pc.tlsCertFile = certFile
+	pc.tlsKeyFile = keyFile
+}
+
+// SetBasicAuth requires HTTP Basic Auth with user and password for /metrics, an empty user
+// disables it. It must be called before StartMetricsServer.
+func (pc *PrometheusCollector) SetBasicAuth(user, password string) {
+	pc.handlersMu.Lock()
+	defer pc.handlersMu.Unlock()
+
+	pc.basicAuthUser = user
+	pc.basicAuthPass = password
+}
+
+// SetAllowedCIDRs restricts the metrics server to clients in the CIDR ranges, see ParseAllowedCIDRs;
+// empty allows all clients. It must be called before StartMetricsServer.
+func (pc *PrometheusCollector) SetAllowedCIDRs(cidrs []string) error {
+	prefixes, err := ParseAllowedCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+
+	pc.handlersMu.Lock()
+	defer pc.handlersMu.Unlock()
+
+	pc.allowedCIDRs = prefixes
+	return nil
+}
+
+// requireBasicAuth wraps handler to reject requests without the configured credentials
+func requireBasicAuth(handler http.Handler, user, password string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPassword, ok := r.BasicAuth()
+		// Compare both to take the same time whichever is wrong
+		userOK := subtle.ConstantTimeCompare([]byte(gotUser), []byte(user)) == 1
+		passwordOK := subtle.ConstantTimeCompare([]byte(gotPassword), []byte(password)) == 1
+		if !ok || !userOK || !passwordOK {
+			w.Header().Set("WWW-Authenticate", `Basic realm="ipfailover metrics", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// StartMetricsServer starts the Prometheus metrics HTTP server, or HTTPS server if SetTLS was called
 func (pc *PrometheusCollector) StartMetricsServer(ctx context.Context, addr string) error {
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.HandlerFor(pc.registry, promhttp.HandlerOpts{}))
+	pc.handlersMu.Lock()
+	for pattern, handler := range pc.handlers {
+		mux.Handle(pattern, handler)
+	}
+	certFile, keyFile := pc.tlsCertFile, pc.tlsKeyFile
+	user, password := pc.basicAuthUser, pc.basicAuthPass
+	allowedCIDRs := pc.allowedCIDRs
+	pc.handlersMu.Unlock()
+
+	var metricsHandler http.Handler = promhttp.HandlerFor(pc.registry, promhttp.HandlerOpts{})
+	if user != "" {
+		metricsHandler = requireBasicAuth(metricsHandler, user, password)
+	}
+	mux.Handle("/metrics", metricsHandler)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -151,11 +512,15 @@ func (pc *PrometheusCollector) StartMetricsServer(ctx context.Context, addr stri
 
 	server := &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: AllowCIDRs(allowedCIDRs, mux, pc.logger),
 	}
 
+	useTLS := certFile != "" && keyFile != ""
 	pc.logger.Info("starting metrics server",
 		zap.String("addr", addr),
+		zap.Bool("tls", useTLS),
+		zap.Bool("basic_auth", user != ""),
+		zap.Int("allowed_cidrs", len(allowedCIDRs)),
 	)
 
 	// Channel to receive server errors
@@ -163,6 +528,10 @@ func (pc *PrometheusCollector) StartMetricsServer(ctx context.Context, addr stri
 
 	// Start server in goroutine
 	go func() {
+		if useTLS {
+			errCh <- server.ServeTLS(listener, certFile, keyFile)
+			return
+		}
 		errCh <- server.Serve(listener)
 	}()
 
@@ -212,9 +581,27 @@ type MockCollector struct {
 	ipChecksCount      int
 	ipCheckErrorsCount int
 	dnsUpdatesCount    map[string]int // "provider:record" -> count
+	recordWritesCount  map[string]int // "provider:record" -> count
+	recordNoopsCount   map[string]int // "provider:record" -> count
 	dnsErrorsCount     map[string]int // "provider:record" -> count
+	dryRunUpdatesCount map[string]int // "provider:record" -> count
+	dnsConflictsCount  map[string]int // "provider:record" -> count
+	retriesCount       map[string]int // "provider:record" -> count
+	httpRetriesCount   map[string]int // provider -> count
+	circuitStates      map[string]int // provider -> state
 	currentIP          string
+	currentIPv6        string
 	lastChangeTime     time.Time
+	forcedTarget       string
+	changeSyncs        map[string][]time.Duration // provider -> observed durations
+	updateDurations    map[string][]time.Duration // "provider:record" -> observed durations
+	reachDurations     map[string][]time.Duration // ip -> observed durations
+	failureCounts      map[string]int             // primary IP -> failure count
+	startTime          time.Time
+	version            string
+	buildTime          string
+	failovers          map[string][]time.Duration // direction -> observed durations
+	changesSuppressed  int
 	// Note: Consider using a struct key type instead of "provider:record" string
 	// to avoid potential delimiter collisions in provider/record names
 }
@@ -222,8 +609,20 @@ type MockCollector struct {
 // NewMockCollector creates a new mock metrics collector
 func NewMockCollector() *MockCollector {
 	return &MockCollector{
-		dnsUpdatesCount: make(map[string]int),
-		dnsErrorsCount:  make(map[string]int),
+		dnsUpdatesCount:    make(map[string]int),
+		recordWritesCount:  make(map[string]int),
+		recordNoopsCount:   make(map[string]int),
+		dnsErrorsCount:     make(map[string]int),
+		dryRunUpdatesCount: make(map[string]int),
+		dnsConflictsCount:  make(map[string]int),
+		retriesCount:       make(map[string]int),
+		httpRetriesCount:   make(map[string]int),
+		circuitStates:      make(map[string]int),
+		changeSyncs:        make(map[string][]time.Duration),
+		updateDurations:    make(map[string][]time.Duration),
+		reachDurations:     make(map[string][]time.Duration),
+		failureCounts:      make(map[string]int),
+		failovers:          make(map[string][]time.Duration),
 	}
 }
 
@@ -242,6 +641,8 @@ func (m *MockCollector) IncrementIPCheckErrors() {
 }
 
 // IncrementDNSUpdates increments the DNS updates counter
+//
+// Deprecated: use IncrementRecordWrites or IncrementRecordNoops, which also count towards the DNS updates count.
 func (m *MockCollector) IncrementDNSUpdates(provider, record string) {
 	key := provider + ":" + record
 	m.mu.Lock()
@@ -249,6 +650,24 @@ func (m *MockCollector) IncrementDNSUpdates(provider, record string) {
 	m.mu.Unlock()
 }
 
+// IncrementRecordWrites increments the record writes counter and the DNS updates counter
+func (m *MockCollector) IncrementRecordWrites(provider, record string) {
+	key := provider + ":" + record
+	m.mu.Lock()
+	m.recordWritesCount[key]++
+	m.dnsUpdatesCount[key]++
+	m.mu.Unlock()
+}
+
+// IncrementRecordNoops increments the record no-ops counter and the DNS updates counter
+func (m *MockCollector) IncrementRecordNoops(provider, record string) {
+	key := provider + ":" + record
+	m.mu.Lock()
+	m.recordNoopsCount[key]++
+	m.dnsUpdatesCount[key]++
+	m.mu.Unlock()
+}
+
 // IncrementDNSErrors increments the DNS update errors counter
 func (m *MockCollector) IncrementDNSErrors(provider, record string) {
 	key := provider + ":" + record
@@ -257,6 +676,88 @@ func (m *MockCollector) IncrementDNSErrors(provider, record string) {
 	m.mu.Unlock()
 }
 
+// IncrementDryRunUpdates increments the dry-run DNS updates counter
+func (m *MockCollector) IncrementDryRunUpdates(provider, record string) {
+	key := provider + ":" + record
+	m.mu.Lock()
+	m.dryRunUpdatesCount[key]++
+	m.mu.Unlock()
+}
+
+// IncrementDNSConflicts increments the DNS update conflicts counter
+func (m *MockCollector) IncrementDNSConflicts(provider, record string) {
+	key := provider + ":" + record
+	m.mu.Lock()
+	m.dnsConflictsCount[key]++
+	m.mu.Unlock()
+}
+
+// IncrementProviderRetries increments the provider retries counter
+func (m *MockCollector) IncrementProviderRetries(provider, record string) {
+	key := provider + ":" + record
+	m.mu.Lock()
+	m.retriesCount[key]++
+	m.mu.Unlock()
+}
+
+// IncrementHTTPRetries increments the provider API request retries counter
+func (m *MockCollector) IncrementHTTPRetries(provider string) {
+	m.mu.Lock()
+	m.httpRetriesCount[provider]++
+	m.mu.Unlock()
+}
+
+// SetCircuitState sets the circuit breaker state gauge of a provider
+func (m *MockCollector) SetCircuitState(provider string, state int) {
+	m.mu.Lock()
+	m.circuitStates[provider] = state
+	m.mu.Unlock()
+}
+
+// ObserveChangeSync records the time until a DNS change was in sync
+func (m *MockCollector) ObserveChangeSync(provider string, duration time.Duration) {
+	m.mu.Lock()
+	m.changeSyncs[provider] = append(m.changeSyncs[provider], duration)
+	m.mu.Unlock()
+}
+
+// ObserveDNSUpdateDuration records the duration of a provider call writing a DNS record
+func (m *MockCollector) ObserveDNSUpdateDuration(provider, record string, duration time.Duration) {
+	key := provider + ":" + record
+	m.mu.Lock()
+	m.updateDurations[key] = append(m.updateDurations[key], duration)
+	m.mu.Unlock()
+}
+
+// ObserveReachabilityDuration records the duration of a reachability check of an IP
+func (m *MockCollector) ObserveReachabilityDuration(ip string, duration time.Duration) {
+	m.mu.Lock()
+	m.reachDurations[ip] = append(m.reachDurations[ip], duration)
+	m.mu.Unlock()
+}
+
+// SetPrimaryFailureCount sets the primary failure count gauge of a primary IP
+func (m *MockCollector) SetPrimaryFailureCount(primaryIP string, count int) {
+	m.mu.Lock()
+	m.failureCounts[primaryIP] = count
+	m.mu.Unlock()
+}
+
+// SetStartTime sets the start timestamp gauge
+func (m *MockCollector) SetStartTime(t time.Time) {
+	m.mu.Lock()
+	m.startTime = t
+	m.mu.Unlock()
+}
+
+// SetBuildInfo sets the build info gauge
+func (m *MockCollector) SetBuildInfo(version, buildTime string) {
+	m.mu.Lock()
+	m.version = version
+	m.buildTime = buildTime
+	m.mu.Unlock()
+}
+
 // SetCurrentIP sets the current IP gauge
 func (m *MockCollector) SetCurrentIP(ip string) {
 	m.mu.Lock()
@@ -264,6 +765,13 @@ func (m *MockCollector) SetCurrentIP(ip string) {
 	m.mu.Unlock()
 }
 
+// SetCurrentIPv6 sets the current IPv6 gauge
+func (m *MockCollector) SetCurrentIPv6(ip string) {
+	m.mu.Lock()
+	m.currentIPv6 = ip
+	m.mu.Unlock()
+}
+
 // SetLastChangeTime sets the last change timestamp
 func (m *MockCollector) SetLastChangeTime(t time.Time) {
 	m.mu.Lock()
@@ -271,6 +779,27 @@ func (m *MockCollector) SetLastChangeTime(t time.Time) {
 	m.mu.Unlock()
 }
 
+// SetForcedTarget sets the forced target
+func (m *MockCollector) SetForcedTarget(target string) {
+	m.mu.Lock()
+	m.forcedTarget = target
+	m.mu.Unlock()
+}
+
+// ObserveFailover counts a completed failover in direction and records how long it took
+func (m *MockCollector) ObserveFailover(direction string, duration time.Duration) {
+	m.mu.Lock()
+	m.failovers[direction] = append(m.failovers[direction], duration)
+	m.mu.Unlock()
+}
+
+// IncrementChangesSuppressed counts an IP change suppressed by the minimum change interval
+func (m *MockCollector) IncrementChangesSuppressed() {
+	m.mu.Lock()
+	m.changesSuppressed++
+	m.mu.Unlock()
+}
+
 // GetIPChecksCount returns the IP checks count
 func (m *MockCollector) GetIPChecksCount() int {
 	m.mu.RLock()
@@ -296,6 +825,24 @@ func (m *MockCollector) GetDNSUpdatesCount(provider, record string) int {
 	return count
 }
 
+// GetRecordWritesCount returns the record writes count for a provider and record
+func (m *MockCollector) GetRecordWritesCount(provider, record string) int {
+	key := provider + ":" + record
+	m.mu.RLock()
+	count := m.recordWritesCount[key]
+	m.mu.RUnlock()
+	return count
+}
+
+// GetRecordNoopsCount returns the record no-ops count for a provider and record
+func (m *MockCollector) GetRecordNoopsCount(provider, record string) int {
+	key := provider + ":" + record
+	m.mu.RLock()
+	count := m.recordNoopsCount[key]
+	m.mu.RUnlock()
+	return count
+}
+
 // GetDNSErrorsCount returns the DNS errors count for a provider and record
 func (m *MockCollector) GetDNSErrorsCount(provider, record string) int {
 	key := provider + ":" + record
@@ -305,6 +852,114 @@ func (m *MockCollector) GetDNSErrorsCount(provider, record string) int {
 	return count
 }
 
+// GetDryRunUpdatesCount returns the dry-run DNS updates count for a provider and record
+func (m *MockCollector) GetDryRunUpdatesCount(provider, record string) int {
+	key := provider + ":" + record
+	m.mu.RLock()
+	count := m.dryRunUpdatesCount[key]
+	m.mu.RUnlock()
+	return count
+}
+
+// GetDNSConflictsCount returns the DNS update conflicts count for a provider and record
+func (m *MockCollector) GetDNSConflictsCount(provider, record string) int {
+	key := provider + ":" + record
+	m.mu.RLock()
+	count := m.dnsConflictsCount[key]
+	m.mu.RUnlock()
+	return count
+}
+
+// GetProviderRetriesCount returns the provider retries count for a provider and record
+func (m *MockCollector) GetProviderRetriesCount(provider, record string) int {
+	key := provider + ":" + record
+	m.mu.RLock()
+	count := m.retriesCount[key]
+	m.mu.RUnlock()
+	return count
+}
+
+// GetHTTPRetriesCount returns the provider API request retries count for a provider
+func (m *MockCollector) GetHTTPRetriesCount(provider string) int {
+	m.mu.RLock()
+	count := m.httpRetriesCount[provider]
+	m.mu.RUnlock()
+	return count
+}
+
+// GetCircuitState returns the circuit breaker state of a provider, and whether it was set
+func (m *MockCollector) GetCircuitState(provider string) (int, bool) {
+	m.mu.RLock()
+	state, ok := m.circuitStates[provider]
+	m.mu.RUnlock()
+	return state, ok
+}
+
+// GetChangeSyncs returns the change sync durations observed for a provider
+func (m *MockCollector) GetChangeSyncs(provider string) []time.Duration {
+	m.mu.RLock()
+	durations := append([]time.Duration(nil), m.changeSyncs[provider]...)
+	m.mu.RUnlock()
+	return durations
+}
+
+// GetDNSUpdateDurations returns the DNS update durations observed for a provider and record
+func (m *MockCollector) GetDNSUpdateDurations(provider, record string) []time.Duration {
+	key := provider + ":" + record
+	m.mu.RLock()
+	durations := append([]time.Duration(nil), m.updateDurations[key]...)
+	m.mu.RUnlock()
+	return durations
+}
+
+// GetReachabilityDurations returns the reachability check durations observed for an IP
+func (m *MockCollector) GetReachabilityDurations(ip string) []time.Duration {
+	m.mu.RLock()
+	durations := append([]time.Duration(nil), m.reachDurations[ip]...)
+	m.mu.RUnlock()
+	return durations
+}
+
+// GetPrimaryFailureCount returns the primary failure count of a primary IP
+func (m *MockCollector) GetPrimaryFailureCount(primaryIP string) int {
+	m.mu.RLock()
+	count := m.failureCounts[primaryIP]
+	m.mu.RUnlock()
+	return count
+}
+
+// GetStartTime returns the start timestamp
+func (m *MockCollector) GetStartTime() time.Time {
+	m.mu.RLock()
+	t := m.startTime
+	m.mu.RUnlock()
+	return t
+}
+
+// GetBuildInfo returns the version and build time
+func (m *MockCollector) GetBuildInfo() (string, string) {
+	m.mu.RLock()
+	version, buildTime := m.version, m.buildTime
+	m.mu.RUnlock()
+	return version, buildTime
+}
+
+// GetFailovers returns the durations of the failovers observed in direction
+func (m *MockCollector) GetFailovers(direction string) []time.Duration {
+	m.mu.RLock()
+	durations := append([]time.Duration(nil), m.failovers[direction]...)
+	m.mu.RUnlock()
+	return durations
+}
+
+// GetChangesSuppressedCount returns the number of suppressed IP changes
+func (m *MockCollector) GetChangesSuppressedCount() int {
+	m.mu.RLock()
+	count := m.changesSuppressed
+	m.mu.RUnlock()
+	return count
+}
+
 // GetCurrentIP returns the current IP
 func (m *MockCollector) GetCurrentIP() string {
 	m.mu.RLock()
@@ -313,6 +968,22 @@ func (m *MockCollector) GetCurrentIP() string {
 	return ip
 }
 
+// GetCurrentIPv6 returns the current IPv6 address
+func (m *MockCollector) GetCurrentIPv6() string {
+	m.mu.RLock()
+	ip := m.currentIPv6
+	m.mu.RUnlock()
+	return ip
+}
+
+// GetForcedTarget returns the forced target
+func (m *MockCollector) GetForcedTarget() string {
+	m.mu.RLock()
+	target := m.forcedTarget
+	m.mu.RUnlock()
+	return target
+}
+
 // GetLastChangeTime returns the last change time
 func (m *MockCollector) GetLastChangeTime() time.Time {
 	m.mu.RLock()
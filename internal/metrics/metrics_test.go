@@ -1,11 +1,25 @@
 package metrics_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/devhat/ipfailover/internal/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -19,7 +33,10 @@ func TestPrometheusCollector(t *testing.T) {
 	collector.IncrementIPCheckErrors()
 	collector.IncrementDNSUpdates("cloudflare", "example.com")
 	collector.IncrementDNSErrors("cloudflare", "example.com")
+	collector.IncrementDryRunUpdates("cloudflare", "example.com")
+	collector.IncrementDNSConflicts("cloudflare", "example.com")
 	collector.SetCurrentIP("203.0.113.10")
+	collector.SetCurrentIPv6("2001:db8::10")
 	collector.SetLastChangeTime(time.Now())
 
 	// Test that metrics are registered (we can't easily test the actual values without
@@ -79,6 +96,18 @@ func TestMockCollector(t *testing.T) {
 		assert.Equal(t, 1, collector.GetDNSUpdatesCount("cpanel", "backup.example.com"))
 	})
 
+	t.Run("IncrementRecordWritesAndNoops", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.IncrementRecordWrites("cloudflare", "example.com")
+		collector.IncrementRecordNoops("cloudflare", "example.com")
+		collector.IncrementRecordNoops("cloudflare", "example.com")
+
+		assert.Equal(t, 1, collector.GetRecordWritesCount("cloudflare", "example.com"))
+		assert.Equal(t, 2, collector.GetRecordNoopsCount("cloudflare", "example.com"))
+		// The DNS updates count is the sum of writes and no-ops
+		assert.Equal(t, 3, collector.GetDNSUpdatesCount("cloudflare", "example.com"))
+	})
+
 	t.Run("IncrementDNSErrors", func(t *testing.T) {
 		collector := metrics.NewMockCollector()
 		collector.IncrementDNSErrors("cloudflare", "example.com")
@@ -87,6 +116,50 @@ func TestMockCollector(t *testing.T) {
 		assert.Equal(t, 2, collector.GetDNSErrorsCount("cloudflare", "example.com"))
 	})
 
+	t.Run("IncrementDryRunUpdates", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.IncrementDryRunUpdates("cloudflare", "example.com")
+
+		assert.Equal(t, 1, collector.GetDryRunUpdatesCount("cloudflare", "example.com"))
+		assert.Equal(t, 0, collector.GetDNSUpdatesCount("cloudflare", "example.com"))
+	})
+
+	t.Run("IncrementDNSConflicts", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.IncrementDNSConflicts("route53", "example.com")
+
+		assert.Equal(t, 1, collector.GetDNSConflictsCount("route53", "example.com"))
+		assert.Equal(t, 0, collector.GetDNSConflictsCount("cloudflare", "example.com"))
+	})
+
+	t.Run("IncrementHTTPRetries", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.IncrementHTTPRetries("cpanel")
+		collector.IncrementHTTPRetries("cpanel")
+		assert.Equal(t, 2, collector.GetHTTPRetriesCount("cpanel"))
+		assert.Equal(t, 0, collector.GetHTTPRetriesCount("webhook"))
+	})
+
+	t.Run("SetCircuitState", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		_, ok := collector.GetCircuitState("cloudflare")
+		assert.False(t, ok)
+
+		collector.SetCircuitState("cloudflare", 2)
+		state, ok := collector.GetCircuitState("cloudflare")
+		assert.True(t, ok)
+		assert.Equal(t, 2, state)
+	})
+
+	t.Run("ObserveChangeSync", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		assert.Empty(t, collector.GetChangeSyncs("route53"))
+
+		collector.ObserveChangeSync("route53", 30*time.Second)
+		collector.ObserveChangeSync("route53", 45*time.Second)
+		assert.Equal(t, []time.Duration{30 * time.Second, 45 * time.Second}, collector.GetChangeSyncs("route53"))
+	})
+
 	t.Run("SetCurrentIP", func(t *testing.T) {
 		collector := metrics.NewMockCollector()
 		collector.SetCurrentIP("203.0.113.10")
@@ -94,6 +167,10 @@ func TestMockCollector(t *testing.T) {
 
 		collector.SetCurrentIP("198.51.100.77")
 		assert.Equal(t, "198.51.100.77", collector.GetCurrentIP())
+
+		collector.SetCurrentIPv6("2001:db8::10")
+		assert.Equal(t, "2001:db8::10", collector.GetCurrentIPv6())
+		assert.Equal(t, "198.51.100.77", collector.GetCurrentIP())
 	})
 
 	t.Run("SetLastChangeTime", func(t *testing.T) {
@@ -104,6 +181,57 @@ func TestMockCollector(t *testing.T) {
 		actualTime := collector.GetLastChangeTime()
 		assert.Equal(t, now, actualTime)
 	})
+
+	t.Run("ObserveDNSUpdateDuration", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.ObserveDNSUpdateDuration("cloudflare", "example.com", 200*time.Millisecond)
+
+		assert.Equal(t, []time.Duration{200 * time.Millisecond}, collector.GetDNSUpdateDurations("cloudflare", "example.com"))
+		assert.Empty(t, collector.GetDNSUpdateDurations("route53", "example.com"))
+	})
+
+	t.Run("ObserveReachabilityDuration", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.ObserveReachabilityDuration("203.0.113.10", 20*time.Millisecond)
+
+		assert.Equal(t, []time.Duration{20 * time.Millisecond}, collector.GetReachabilityDurations("203.0.113.10"))
+	})
+
+	t.Run("SetPrimaryFailureCount", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.SetPrimaryFailureCount("203.0.113.10", 2)
+
+		assert.Equal(t, 2, collector.GetPrimaryFailureCount("203.0.113.10"))
+		assert.Zero(t, collector.GetPrimaryFailureCount("198.51.100.77"))
+	})
+
+	t.Run("SetStartTimeAndBuildInfo", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		now := time.Now()
+		collector.SetStartTime(now)
+		collector.SetBuildInfo("1.2.3", "2026-10-16T08:00:00Z")
+
+		assert.Equal(t, now, collector.GetStartTime())
+		version, buildTime := collector.GetBuildInfo()
+		assert.Equal(t, "1.2.3", version)
+		assert.Equal(t, "2026-10-16T08:00:00Z", buildTime)
+	})
+
+	t.Run("ObserveFailover", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.ObserveFailover(metrics.DirectionPrimaryToSecondary, 3*time.Second)
+
+		assert.Equal(t, []time.Duration{3 * time.Second}, collector.GetFailovers(metrics.DirectionPrimaryToSecondary))
+		assert.Empty(t, collector.GetFailovers(metrics.DirectionSecondaryToPrimary))
+	})
+
+	t.Run("IncrementChangesSuppressed", func(t *testing.T) {
+		collector := metrics.NewMockCollector()
+		collector.IncrementChangesSuppressed()
+		collector.IncrementChangesSuppressed()
+
+		assert.Equal(t, 2, collector.GetChangesSuppressedCount())
+	})
 }
 
 func TestMockCollector_InitialState(t *testing.T) {
@@ -113,6 +241,220 @@ func TestMockCollector_InitialState(t *testing.T) {
 	assert.Equal(t, 0, collector.GetIPCheckErrorsCount())
 	assert.Equal(t, 0, collector.GetDNSUpdatesCount("cloudflare", "example.com"))
 	assert.Equal(t, 0, collector.GetDNSErrorsCount("cloudflare", "example.com"))
+	assert.Equal(t, 0, collector.GetDryRunUpdatesCount("cloudflare", "example.com"))
+	assert.Equal(t, 0, collector.GetRecordWritesCount("cloudflare", "example.com"))
+	assert.Equal(t, 0, collector.GetRecordNoopsCount("cloudflare", "example.com"))
+	assert.Equal(t, 0, collector.GetDNSConflictsCount("cloudflare", "example.com"))
 	assert.Empty(t, collector.GetCurrentIP())
 	assert.Zero(t, collector.GetLastChangeTime())
 }
+
+func TestPrometheusCollector_RecordWritesAndNoops(t *testing.T) {
+	collector := metrics.NewPrometheusCollector(zap.NewNop())
+	collector.IncrementRecordWrites("cloudflare", "example.com")
+	collector.IncrementRecordNoops("cloudflare", "example.com")
+	collector.IncrementRecordNoops("cloudflare", "example.com")
+	collector.IncrementProviderRetries("cloudflare", "example.com")
+	collector.IncrementHTTPRetries("cpanel")
+	collector.SetCircuitState("cloudflare", 1)
+	collector.ObserveChangeSync("route53", 42*time.Second)
+	collector.ObserveDNSUpdateDuration("cloudflare", "example.com", 300*time.Millisecond)
+	collector.ObserveReachabilityDuration("203.0.113.10", 40*time.Millisecond)
+	collector.SetPrimaryFailureCount("203.0.113.10", 2)
+	collector.SetStartTime(time.Unix(1760000000, 0))
+	collector.SetBuildInfo("1.2.3", "2026-10-16T08:00:00Z")
+	collector.ObserveFailover(metrics.DirectionPrimaryToSecondary, 1500*time.Millisecond)
+	collector.ObserveFailover(metrics.DirectionSecondaryToPrimary, 40*time.Second)
+	collector.IncrementChangesSuppressed()
+	collector.SetForcedTarget("primary")
+	collector.SetForcedTarget("secondary")
+
+	// Reserve a free port for the metrics server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- collector.StartMetricsServer(ctx, addr)
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	var body string
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/metrics")
+		if err != nil {
+			return false
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false
+		}
+		body = string(data)
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Contains(t, body, `ipfailover_record_writes_total{provider="cloudflare",record="example.com"} 1`)
+	assert.Contains(t, body, `ipfailover_record_noops_total{provider="cloudflare",record="example.com"} 2`)
+	assert.Contains(t, body, `ipfailover_updates_total{provider="cloudflare",record="example.com"} 3`)
+	assert.Contains(t, body, `ipfailover_provider_retries_total{provider="cloudflare",record="example.com"} 1`)
+	assert.Contains(t, body, `ipfailover_provider_http_retries_total{provider="cpanel"} 1`)
+	assert.Contains(t, body, `ipfailover_circuit_state{provider="cloudflare"} 1`)
+	assert.Contains(t, body, `ipfailover_change_sync_duration_seconds_bucket{provider="route53",le="45"} 1`)
+	assert.Contains(t, body, `ipfailover_change_sync_duration_seconds_count{provider="route53"} 1`)
+	assert.Contains(t, body, `ipfailover_provider_update_duration_seconds_bucket{provider="cloudflare",record="example.com",le="0.5"} 1`)
+	assert.Contains(t, body, `ipfailover_provider_update_duration_seconds_count{provider="cloudflare",record="example.com"} 1`)
+	assert.Contains(t, body, `ipfailover_reachability_check_duration_seconds_bucket{ip="203.0.113.10",le="0.05"} 1`)
+	assert.Contains(t, body, `ipfailover_primary_failure_count{primary_ip="203.0.113.10"} 2`)
+	assert.Contains(t, body, `ipfailover_start_timestamp_seconds 1.76e+09`)
+	assert.Contains(t, body, `ipfailover_build_info{build_time="2026-10-16T08:00:00Z",version="1.2.3"} 1`)
+	assert.Contains(t, body, `ipfailover_failover_duration_seconds_bucket{le="2.5"} 1`)
+	assert.Contains(t, body, `ipfailover_failover_duration_seconds_count 2`)
+	assert.Contains(t, body, `ipfailover_failover_total{direction="primary_to_secondary"} 1`)
+	assert.Contains(t, body, `ipfailover_failover_total{direction="secondary_to_primary"} 1`)
+	assert.Contains(t, body, `ipfailover_changes_suppressed_total 1`)
+	assert.Contains(t, body, `ipfailover_forced_target{target="secondary"} 1`)
+	assert.NotContains(t, body, `ipfailover_forced_target{target="primary"}`)
+}
+
+func TestPrometheusCollector_Handle(t *testing.T) {
+	collector := metrics.NewPrometheusCollector(zap.NewNop())
+	collector.Handle("/status", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"open_incidents":[]}`))
+	}))
+
+	// Reserve a free port for the metrics server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- collector.StartMetricsServer(ctx, addr)
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	var body string
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/status")
+		if err != nil {
+			return false
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false
+		}
+		body = string(data)
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, `{"open_incidents":[]}`, body)
+}
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 and its key to PEM files
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "metrics.crt"), filepath.Join(dir, "metrics.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestPrometheusCollector_TLSAndBasicAuth(t *testing.T) {
+	collector := metrics.NewPrometheusCollector(zap.NewNop())
+	collector.SetTLS(writeCertificate(t))
+	collector.SetBasicAuth("prometheus", "secret")
+
+	// Reserve a free port for the metrics server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // Self-signed test certificate
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- collector.StartMetricsServer(ctx, addr)
+	}()
+	defer func() {
+		// Kept-alive connections, including ones dialed but never used, would hold up the shutdown
+		client.CloseIdleConnections()
+		cancel()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Error("metrics server did not shut down")
+		}
+	}()
+	get := func(path, user, password string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, "https://"+addr+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		return client.Do(req)
+	}
+
+	var status int
+	require.Eventually(t, func() bool {
+		resp, err := get("/metrics", "prometheus", "secret")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		status = resp.StatusCode
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, http.StatusOK, status)
+
+	for _, credentials := range [][2]string{{"", ""}, {"prometheus", "wrong"}, {"admin", "secret"}} {
+		resp, err := get("/metrics", credentials[0], credentials[1])
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "credentials %v", credentials)
+		assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+	}
+
+	// Only /metrics requires the credentials
+	resp, err := get("/health", "", "")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
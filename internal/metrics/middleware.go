@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ParseAllowedCIDRs parses CIDR ranges such as 10.0.0.0/8, a single IP address counting as a range
+// of one address
+func ParseAllowedCIDRs(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a CIDR range or IP address", cidr)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a CIDR range or IP address", cidr)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// AllowCIDRs wraps handler to reject requests from source IPs outside the ranges with 403 Forbidden.
+// Without ranges every request is allowed. The source is the address of the connection, forwarding
+// headers are ignored since they can be set by any client.
+func AllowCIDRs(prefixes []netip.Prefix, handler http.Handler, logger *zap.Logger) http.Handler {
+	if len(prefixes) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedSource(prefixes, r.RemoteAddr) {
+			logger.Debug("rejected request from source outside metrics_allowed_cidrs",
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.String("path", r.URL.Path),
+			)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// allowedSource reports whether the IP of remoteAddr, a host:port pair, is in one of the ranges
+func allowedSource(prefixes []netip.Prefix, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
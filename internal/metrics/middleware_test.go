@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAllowCIDRs(t *testing.T) {
+	prefixes, err := metrics.ParseAllowedCIDRs([]string{"10.0.0.0/8", "172.16.0.0/12", "192.0.2.7", "2001:db8::/32"})
+	require.NoError(t, err)
+	handler := metrics.AllowCIDRs(prefixes, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}), zap.NewNop())
+
+	tests := []struct {
+		remoteAddr string
+		status     int
+	}{
+		{"10.1.2.3:51000", http.StatusOK},
+		{"172.31.255.1:51000", http.StatusOK},
+		{"192.0.2.7:51000", http.StatusOK},
+		{"[2001:db8::1]:51000", http.StatusOK},
+		{"[::ffff:10.1.2.3]:51000", http.StatusOK},
+		{"172.32.0.1:51000", http.StatusForbidden},
+		{"192.0.2.8:51000", http.StatusForbidden},
+		{"[2001:db9::1]:51000", http.StatusForbidden},
+		{"not-an-address", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+
+	t.Run("forwarding headers are ignored", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = "203.0.113.10:51000"
+		req.Header.Set("X-Forwarded-For", "10.1.2.3")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
+
+func TestAllowCIDRs_Empty(t *testing.T) {
+	handler := metrics.AllowCIDRs(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}), zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = "203.0.113.10:51000"
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestParseAllowedCIDRs(t *testing.T) {
+	_, err := metrics.ParseAllowedCIDRs([]string{"10.0.0.0/33"})
+	assert.EqualError(t, err, `"10.0.0.0/33" is not a CIDR range or IP address`)
+
+	_, err = metrics.ParseAllowedCIDRs([]string{"localhost"})
+	assert.EqualError(t, err, `"localhost" is not a CIDR range or IP address`)
+
+	// Host bits are ignored
+	prefixes, err := metrics.ParseAllowedCIDRs([]string{"10.1.2.3/8"})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8", prefixes[0].String())
+}
@@ -0,0 +1,106 @@
+// Package netbind binds outbound connections to a local source address or network interface, so
+// that on hosts with several uplinks a connection leaves through a chosen one
+package netbind
+
+import (
+	"fmt"
+	"net"
+)
+
+// Source is the local address and network interface outbound connections are bound to.
+// Empty fields leave the choice to the operating system.
+type Source struct {
+	IP        string // Local IP address connections are made from
+	Interface string // Network interface connections are bound to, only supported on Linux
+}
+
+// IsZero reports whether the source leaves the choice to the operating system
+func (s Source) IsZero() bool {
+	return s.IP == "" && s.Interface == ""
+}
+
+// Validate checks that the interface exists and that the IP is assigned to it, or, without an
+// interface, to any local interface
+func (s Source) Validate() error {
+	var addrs []net.Addr
+	if s.Interface != "" {
+		if !bindToDeviceSupported {
+			return fmt.Errorf("source_interface is only supported on Linux")
+		}
+		iface, err := net.InterfaceByName(s.Interface)
+		if err != nil {
+			return fmt.Errorf("source_interface %s: %w", s.Interface, err)
+		}
+		if addrs, err = iface.Addrs(); err != nil {
+			return fmt.Errorf("failed to list addresses of source_interface %s: %w", s.Interface, err)
+		}
+	}
+
+	if s.IP == "" {
+		return nil
+	}
+	ip := net.ParseIP(s.IP)
+	if ip == nil {
+		return fmt.Errorf("source_ip %q is not a valid IP address", s.IP)
+	}
+	if s.Interface == "" {
+		var err error
+		if addrs, err = net.InterfaceAddrs(); err != nil {
+			return fmt.Errorf("failed to list local addresses: %w", err)
+		}
+	}
+	for _, addr := range addrs {
+		if prefix, ok := addr.(*net.IPNet); ok && prefix.IP.Equal(ip) {
+			return nil
+		}
+	}
+	if s.Interface != "" {
+		return fmt.Errorf("source_ip %s is not assigned to source_interface %s", s.IP, s.Interface)
+	}
+	return fmt.Errorf("source_ip %s is not assigned to a local interface", s.IP)
+}
+
+// ForFamily returns the source of connections to IPv6 addresses if ipv6 is set, and to IPv4
+// addresses otherwise. Its IP is dropped if it is of the other family, so the interface still
+// applies to the checks of both families in dual-stack mode.
+func (s Source) ForFamily(ipv6 bool) Source {
+	if ip := net.ParseIP(s.IP); ip != nil && (ip.To4() == nil) != ipv6 {
+		s.IP = ""
+	}
+	return s
+}
+
+// Bind returns a copy of dialer whose connections are made from the source. Validate should
+// have accepted the source.
+func (s Source) Bind(dialer *net.Dialer) *net.Dialer {
+	bound := *dialer
+	if s.IP != "" {
+		bound.LocalAddr = &net.TCPAddr{IP: net.ParseIP(s.IP)}
+	}
+	if s.Interface != "" {
+		bound.Control = bindToDevice(s.Interface)
+	}
+	return &bound
+}
+
+// ListenConfig returns a listen config whose packet connections are bound to the interface of the
+// source, for connectionless protocols such as ICMP. The local address is left to the caller, see
+// ListenAddr.
+func (s Source) ListenConfig() *net.ListenConfig {
+	config := &net.ListenConfig{}
+	if s.Interface != "" {
+		config.Control = bindToDevice(s.Interface)
+	}
+	return config
+}
+
+// ListenAddr returns the source IP, or the unspecified address of the family if it has none
+func (s Source) ListenAddr(ipv6 bool) string {
+	if s.IP != "" {
+		return s.IP
+	}
+	if ipv6 {
+		return "::"
+	}
+	return "0.0.0.0"
+}
@@ -0,0 +1,27 @@
+package netbind
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToDeviceSupported reports whether connections can be bound to a network interface
+const bindToDeviceSupported = true
+
+// bindToDevice returns a dialer control function binding sockets to the network interface with
+// SO_BINDTODEVICE, which requires CAP_NET_RAW on kernels before 5.7
+func bindToDevice(name string) func(network, address string, conn syscall.RawConn) error {
+	return func(network, address string, conn syscall.RawConn) error {
+		var bindErr error
+		err := conn.Control(func(fd uintptr) {
+			bindErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, name)
+		})
+		if err != nil {
+			return err
+		}
+		if bindErr != nil {
+			return fmt.Errorf("failed to bind to interface %s: %w", name, bindErr)
+		}
+		return nil
+	}
+}
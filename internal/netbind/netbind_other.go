@@ -0,0 +1,19 @@
+//go:build !linux
+
+package netbind
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToDeviceSupported reports whether connections can be bound to a network interface
+const bindToDeviceSupported = false
+
+// bindToDevice returns a dialer control function that fails, binding to a network interface
+// is only implemented on Linux
+func bindToDevice(name string) func(network, address string, conn syscall.RawConn) error {
+	return func(network, address string, conn syscall.RawConn) error {
+		return fmt.Errorf("binding to interface %s is only supported on Linux", name)
+	}
+}
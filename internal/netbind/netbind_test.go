@@ -0,0 +1,132 @@
+package netbind_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/netbind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopbackInterface returns the name of the interface with 127.0.0.1
+func loopbackInterface(t *testing.T) string {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		require.NoError(t, err)
+		for _, addr := range addrs {
+			if prefix, ok := addr.(*net.IPNet); ok && prefix.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+				return iface.Name
+			}
+		}
+	}
+	t.Skip("no interface with 127.0.0.1")
+	return ""
+}
+
+func TestSource_Validate(t *testing.T) {
+	assert.NoError(t, netbind.Source{}.Validate())
+	assert.NoError(t, netbind.Source{IP: "127.0.0.1"}.Validate())
+
+	err := netbind.Source{IP: "192.0.2.1"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source_ip 192.0.2.1 is not assigned to a local interface")
+
+	err = netbind.Source{IP: "not-an-ip"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a valid IP address")
+
+	if runtime.GOOS != "linux" {
+		err = netbind.Source{Interface: loopbackInterface(t)}.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only supported on Linux")
+		return
+	}
+
+	lo := loopbackInterface(t)
+	assert.NoError(t, netbind.Source{Interface: lo}.Validate())
+	assert.NoError(t, netbind.Source{IP: "127.0.0.1", Interface: lo}.Validate())
+
+	err = netbind.Source{Interface: "does-not-exist0"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source_interface does-not-exist0")
+
+	err = netbind.Source{IP: "192.0.2.1", Interface: lo}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not assigned to source_interface "+lo)
+}
+
+func TestSource_Bind(t *testing.T) {
+	remoteAddrs := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddrs <- r.RemoteAddr
+	}))
+	defer server.Close()
+
+	dialer := &net.Dialer{Timeout: time.Second}
+	bound := netbind.Source{IP: "127.0.0.1"}.Bind(dialer)
+	assert.Nil(t, dialer.LocalAddr, "the dialer is copied")
+
+	client := &http.Client{Transport: &http.Transport{DialContext: bound.DialContext}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	host, _, err := net.SplitHostPort(<-remoteAddrs)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+}
+
+func TestSource_ForFamily(t *testing.T) {
+	ipv4 := netbind.Source{IP: "192.0.2.1", Interface: "eth0"}
+	assert.Equal(t, ipv4, ipv4.ForFamily(false))
+	assert.Equal(t, netbind.Source{Interface: "eth0"}, ipv4.ForFamily(true))
+
+	ipv6 := netbind.Source{IP: "2001:db8::1"}
+	assert.Equal(t, ipv6, ipv6.ForFamily(true))
+	assert.True(t, ipv6.ForFamily(false).IsZero())
+}
+
+func TestSource_ListenAddr(t *testing.T) {
+	assert.Equal(t, "0.0.0.0", netbind.Source{}.ListenAddr(false))
+	assert.Equal(t, "::", netbind.Source{Interface: "eth0"}.ListenAddr(true))
+	assert.Equal(t, "192.0.2.1", netbind.Source{IP: "192.0.2.1"}.ListenAddr(false))
+	assert.Nil(t, netbind.Source{IP: "192.0.2.1"}.ListenConfig().Control)
+	assert.NotNil(t, netbind.Source{Interface: "eth0"}.ListenConfig().Control)
+}
+
+func TestSource_BindInterface(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("binding to an interface is only supported on Linux")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	dialer := netbind.Source{Interface: loopbackInterface(t)}.Bind(&net.Dialer{Timeout: time.Second})
+	conn, err := dialer.DialContext(context.Background(), "tcp", listener.Addr().String())
+	if errors.Is(err, syscall.EPERM) {
+		t.Skipf("binding to an interface requires CAP_NET_RAW: %v", err)
+	}
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	dialer = netbind.Source{Interface: "does-not-exist0"}.Bind(&net.Dialer{Timeout: time.Second})
+	_, err = dialer.DialContext(context.Background(), "tcp", listener.Addr().String())
+	assert.ErrorContains(t, err, "failed to bind to interface does-not-exist0")
+}
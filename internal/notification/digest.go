@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/devhat/ipfailover/pkg/interfaces"
+)
+
+// DefaultDigestTime is the local time of day the daily digest is sent without a configured time
+const DefaultDigestTime = "08:00"
+
+// digestMaxErrors bounds the errors listed in a digest, the most recent are kept
+const digestMaxErrors = 10
+
+// DigestSummary summarizes the checks and events of a digest period
+type DigestSummary struct {
+	Since          time.Time
+	Until          time.Time
+	Checks         int
+	CheckFailures  int
+	Failovers      int
+	Failbacks      int
+	UpdateFailures int
+	Incidents      int      // Provider incidents opened
+	Errors         []string // The most recent errors, prefixed with their time
+	DroppedErrors  int      // Errors of the period not listed in Errors
+}
+
+// Digest counts the checks and events of the current digest period. It is safe for concurrent use,
+// and its methods do nothing on a nil Digest.
+type Digest struct {
+	mu      sync.Mutex
+	summary DigestSummary
+}
+
+// NewDigest creates a digest whose period starts at now
+func NewDigest(now time.Time) *Digest {
+	return &Digest{summary: DigestSummary{Since: now}}
+}
+
+// RecordCheck counts an IP check, failed if err is not nil
+func (d *Digest) RecordCheck(err error) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.summary.Checks++
+	if err != nil {
+		d.summary.CheckFailures++
+		d.addError(time.Now(), fmt.Sprintf("IP check failed: %v", err))
+	}
+}
+
+// RecordEvent counts failovers, failbacks, failed DNS updates and opened provider incidents
+func (d *Digest) RecordEvent(event interfaces.NotificationEvent) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch event.Type {
+	case TypeFailover:
+		d.summary.Failovers++
+	case TypeFailback:
+		d.summary.Failbacks++
+	case TypeDNSUpdateFailed:
+		d.summary.UpdateFailures++
+		d.addError(event.Timestamp, event.Message)
+	case TypeIncidentOpened:
+		d.summary.Incidents++
+		d.addError(event.Timestamp, event.Message)
+	}
+}
+
+// addError appends an error, dropping the oldest beyond digestMaxErrors. d.mu must be held.
+func (d *Digest) addError(at time.Time, message string) {
+	d.summary.Errors = append(d.summary.Errors, at.Format(time.RFC3339)+" "+message)
+	if len(d.summary.Errors) > digestMaxErrors {
+		d.summary.Errors = d.summary.Errors[1:]
+		d.summary.DroppedErrors++
+	}
+}
+
+// Flush returns the summary of the period ending at now and starts the next period
+func (d *Digest) Flush(now time.Time) DigestSummary {
+	if d == nil {
+		return DigestSummary{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summary := d.summary
+	summary.Until = now
+	d.summary = DigestSummary{Since: now}
+	return summary
+}
+
+// NextDigestTime returns the first time after now at the time of day at, given as HH:MM in the
+// location of now, or DefaultDigestTime if at is empty
+func NextDigestTime(now time.Time, at string) (time.Time, error) {
+	if at == "" {
+		at = DefaultDigestTime
+	}
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid digest time %q: %w", at, err)
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
@@ -0,0 +1,106 @@
+package notification_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	digest := notification.NewDigest(start)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			digest.RecordCheck(nil)
+		}()
+	}
+	wg.Wait()
+	digest.RecordCheck(errors.New("all endpoints failed"))
+	digest.RecordEvent(interfaces.NotificationEvent{Type: notification.TypeFailover})
+	digest.RecordEvent(interfaces.NotificationEvent{Type: notification.TypeFailoverStarted})
+	digest.RecordEvent(interfaces.NotificationEvent{
+		Type:      notification.TypeDNSUpdateFailed,
+		Timestamp: start.Add(time.Hour),
+		Message:   "failed to update DNS records: API unavailable",
+	})
+	digest.RecordEvent(interfaces.NotificationEvent{Type: notification.TypeFailback})
+	digest.RecordEvent(interfaces.NotificationEvent{
+		Type:      notification.TypeIncidentOpened,
+		Provider:  "cloudflare",
+		Timestamp: start.Add(2 * time.Hour),
+		Message:   "incident opened: 3 consecutive DNS update failures for provider cloudflare",
+	})
+	digest.RecordEvent(interfaces.NotificationEvent{Type: notification.TypeIncidentResolved, Provider: "cloudflare"})
+
+	end := start.Add(24 * time.Hour)
+	summary := digest.Flush(end)
+	assert.Equal(t, start, summary.Since)
+	assert.Equal(t, end, summary.Until)
+	assert.Equal(t, 21, summary.Checks)
+	assert.Equal(t, 1, summary.CheckFailures)
+	assert.Equal(t, 1, summary.Failovers)
+	assert.Equal(t, 1, summary.Failbacks)
+	assert.Equal(t, 1, summary.UpdateFailures)
+	assert.Equal(t, 1, summary.Incidents)
+	require.Len(t, summary.Errors, 3)
+	assert.Contains(t, summary.Errors[0], "IP check failed: all endpoints failed")
+	assert.Equal(t, "2025-03-01T09:00:00Z failed to update DNS records: API unavailable", summary.Errors[1])
+	assert.Equal(t, "2025-03-01T10:00:00Z incident opened: 3 consecutive DNS update failures for provider cloudflare", summary.Errors[2])
+
+	// Flushing starts the next period
+	next := digest.Flush(end.Add(time.Hour))
+	assert.Equal(t, end, next.Since)
+	assert.Zero(t, next.Checks)
+	assert.Empty(t, next.Errors)
+
+	t.Run("keeps the most recent errors", func(t *testing.T) {
+		digest := notification.NewDigest(start)
+		for i := 1; i <= 15; i++ {
+			digest.RecordCheck(fmt.Errorf("failure %d", i))
+		}
+
+		summary := digest.Flush(end)
+		assert.Equal(t, 15, summary.CheckFailures)
+		require.Len(t, summary.Errors, 10)
+		assert.Contains(t, summary.Errors[0], "failure 6")
+		assert.Contains(t, summary.Errors[9], "failure 15")
+		assert.Equal(t, 5, summary.DroppedErrors)
+	})
+
+	t.Run("nil digest", func(t *testing.T) {
+		var digest *notification.Digest
+		digest.RecordCheck(errors.New("ignored"))
+		digest.RecordEvent(interfaces.NotificationEvent{Type: notification.TypeFailover})
+		assert.Zero(t, digest.Flush(end).Checks)
+	})
+}
+
+func TestNextDigestTime(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	next, err := notification.NextDigestTime(now, "18:15")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 18, 15, 0, 0, time.UTC), next)
+
+	next, err = notification.NextDigestTime(now, "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC), next, "defaults to 08:00 the next day")
+
+	next, err = notification.NextDigestTime(now, "12:30")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 2, 12, 30, 0, 0, time.UTC), next, "not at now")
+
+	_, err = notification.NextDigestTime(now, "25:00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// Default SMTP ports for implicit TLS and for submission with STARTTLS
+const (
+	smtpTLSPort        = 465
+	smtpSubmissionPort = 587
+)
+
+// EmailNotifier delivers notification events as plain-text emails over SMTP
+type EmailNotifier struct {
+	config *config.EmailConfig
+	logger *zap.Logger
+}
+
+// NewEmailNotifier creates a new email notifier
+func NewEmailNotifier(cfg *config.EmailConfig, logger *zap.Logger) *EmailNotifier {
+	return &EmailNotifier{config: cfg, logger: logger}
+}
+
+// Name returns the notification channel name
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends the event to all recipients. Failover progress and errors that recur every poll
+// during an outage, such as failed IP checks, are left to the other channels.
+func (n *EmailNotifier) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	switch event.Type {
+	case TypeFailoverStarted, TypeDNSUpdateFailed, TypeIPCheckFailed:
+		return nil
+	}
+
+	message := n.message(event)
+	if err := n.send(ctx, message); err != nil {
+		return fmt.Errorf("failed to send email notification: %w", err)
+	}
+
+	n.logger.Debug("email notification sent",
+		zap.String("type", event.Type),
+		zap.Strings("to", n.config.To),
+	)
+	return nil
+}
+
+// SendDigest sends a summary of the checks and events of a digest period to all recipients
+func (n *EmailNotifier) SendDigest(ctx context.Context, summary DigestSummary) error {
+	subject := fmt.Sprintf("[ipfailover] Daily digest: %d checks, %d failed", summary.Checks, summary.CheckFailures)
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "Summary from %s to %s\r\n\r\n",
+		summary.Since.Format(time.RFC3339), summary.Until.Format(time.RFC3339))
+	fmt.Fprintf(&body, "IP checks:          %d\r\n", summary.Checks)
+	fmt.Fprintf(&body, "Failed IP checks:   %d\r\n", summary.CheckFailures)
+	fmt.Fprintf(&body, "Failovers:          %d\r\n", summary.Failovers)
+	fmt.Fprintf(&body, "Failbacks:          %d\r\n", summary.Failbacks)
+	fmt.Fprintf(&body, "Failed DNS updates: %d\r\n", summary.UpdateFailures)
+	fmt.Fprintf(&body, "Provider incidents: %d\r\n", summary.Incidents)
+	if len(summary.Errors) > 0 {
+		body.WriteString("\r\nRecent errors:\r\n")
+		for _, message := range summary.Errors {
+			fmt.Fprintf(&body, "  - %s\r\n", message)
+		}
+		if summary.DroppedErrors > 0 {
+			fmt.Fprintf(&body, "  and %d earlier errors\r\n", summary.DroppedErrors)
+		}
+	}
+
+	if err := n.send(ctx, n.compose(subject, body.Bytes(), summary.Until)); err != nil {
+		return fmt.Errorf("failed to send email digest: %w", err)
+	}
+
+	n.logger.Debug("email digest sent", zap.Strings("to", n.config.To))
+	return nil
+}
+
+// subject returns the subject of an event email, rendered from the subject template if configured.
+// A template that fails to render for this event falls back to the default subject.
+func (n *EmailNotifier) subject(event interfaces.NotificationEvent) string {
+	tmpl, err := n.config.ParseSubjectTemplate()
+	if err == nil && tmpl != nil {
+		var subject strings.Builder
+		if err = tmpl.Execute(&subject, event); err == nil {
+			// Line breaks would end the header
+			return strings.Join(strings.Fields(subject.String()), " ")
+		}
+	}
+	if err != nil {
+		n.logger.Warn("failed to render email subject template, using the default subject", zap.Error(err))
+	}
+
+	if event.ToIP != "" {
+		return fmt.Sprintf("[ipfailover] DNS %s from %s to %s", event.Type, displayIP(event.FromIP), event.ToIP)
+	}
+	return "[ipfailover] " + event.Message
+}
+
+// message renders the event as an email with headers and a plain-text summary
+func (n *EmailNotifier) message(event interfaces.NotificationEvent) []byte {
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "%s\r\n\r\n", event.Message)
+	fmt.Fprintf(&body, "Event: %s\r\n", event.Type)
+	fmt.Fprintf(&body, "Time:  %s\r\n", event.Timestamp.UTC().Format(time.RFC3339))
+	if event.Provider != "" {
+		fmt.Fprintf(&body, "Provider: %s\r\n", event.Provider)
+	}
+	if event.ToIP != "" {
+		fmt.Fprintf(&body, "Old IP: %s\r\n", displayIP(event.FromIP))
+		fmt.Fprintf(&body, "New IP: %s\r\n", event.ToIP)
+		body.WriteString("\r\nUpdated DNS records:\r\n")
+		for _, record := range event.Records {
+			if previous := event.PreviousValues[record]; previous != "" {
+				fmt.Fprintf(&body, "  - %s (was %s)\r\n", record, previous)
+			} else {
+				fmt.Fprintf(&body, "  - %s\r\n", record)
+			}
+		}
+		if event.Cached {
+			body.WriteString("\r\nSome previous values are the last known values, the records could not be read.\r\n")
+		}
+	}
+
+	return n.compose(n.subject(event), body.Bytes(), event.Timestamp)
+}
+
+// compose adds the headers of a plain-text email to body
+func (n *EmailNotifier) compose(subject string, body []byte, date time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.Write(body)
+	return msg.Bytes()
+}
+
+// send delivers message over SMTP. The connection uses implicit TLS if enabled, or else
+// is upgraded with STARTTLS if the server offers it. ctx bounds the whole exchange.
+func (n *EmailNotifier) send(ctx context.Context, message []byte) error {
+	port := n.config.SMTPPort
+	if port == 0 {
+		port = smtpSubmissionPort
+		if n.config.TLSEnabled {
+			port = smtpTLSPort
+		}
+	}
+	addr := net.JoinHostPort(n.config.SMTPHost, strconv.Itoa(port))
+
+	tlsConfig := &tls.Config{ServerName: n.config.SMTPHost}
+
+	var conn net.Conn
+	var err error
+	if n.config.TLSEnabled {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if !n.config.TLSEnabled {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("STARTTLS failed: %w", err)
+			}
+		}
+	}
+
+	if n.config.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", n.config.SMTPUsername, n.config.SMTPPassword, n.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	// The envelope takes bare addresses, without the display names allowed in the configuration
+	if err := client.Mail(envelopeAddress(n.config.From)); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, to := range n.config.To {
+		if err := client.Rcpt(envelopeAddress(to)); err != nil {
+			return fmt.Errorf("RCPT TO %s rejected: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// envelopeAddress returns the bare address of an address that may include a display name
+func envelopeAddress(address string) string {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return address
+	}
+	return parsed.Address
+}
+
+// displayIP returns ip, or "none" if no IP was applied before
+func displayIP(ip string) string {
+	if ip == "" {
+		return "none"
+	}
+	return ip
+}
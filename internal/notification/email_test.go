@@ -0,0 +1,272 @@
+package notification_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSMTP is a minimal SMTP server on the loopback interface that records the envelope
+// and message of every mail, and accepts PLAIN authentication as user/secret
+type fakeSMTP struct {
+	listener net.Listener
+	mu       sync.Mutex
+	auth     string
+	from     string
+	to       []string
+	data     string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	f := &fakeSMTP{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		f.mu.Lock()
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			f.auth = string(credentials)
+			if f.auth == "\x00user\x00secret" {
+				reply("235 Authentication successful")
+			} else {
+				reply("535 Authentication failed")
+			}
+		case "MAIL":
+			f.from = line
+			reply("250 OK")
+		case "RCPT":
+			f.to = append(f.to, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil || dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			f.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			f.mu.Unlock()
+			return
+		default:
+			reply("250 OK")
+		}
+		f.mu.Unlock()
+	}
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	failover := interfaces.NotificationEvent{
+		Type:      notification.TypeFailover,
+		FromIP:    "203.0.113.10",
+		ToIP:      "198.51.100.77",
+		Records:   []string{"home.example.com", "vpn.example.com"},
+		Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+		Message:   "DNS records were changed from 203.0.113.10 to 198.51.100.77",
+
+		// The previous value of vpn.example.com is unknown
+		PreviousValues: map[string]string{"home.example.com": "203.0.113.10"},
+		Cached:         true,
+	}
+
+	t.Run("sends a summary of the failover", func(t *testing.T) {
+		server := newFakeSMTP(t)
+		notifier := notification.NewEmailNotifier(&config.EmailConfig{
+			SMTPHost: "127.0.0.1",
+			SMTPPort: server.port(),
+			From:     "ipfailover <ipfailover@example.com>",
+			To:       []string{"ops@example.com", "oncall@example.com"},
+		}, zap.NewNop())
+
+		require.NoError(t, notifier.Notify(context.Background(), failover))
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Empty(t, server.auth, "no authentication without a user name")
+		assert.Equal(t, "MAIL FROM:<ipfailover@example.com>", server.from)
+		assert.Equal(t, []string{"RCPT TO:<ops@example.com>", "RCPT TO:<oncall@example.com>"}, server.to)
+		assert.Contains(t, server.data, "Subject: [ipfailover] DNS failover from 203.0.113.10 to 198.51.100.77\r\n")
+		assert.Contains(t, server.data, "To: ops@example.com, oncall@example.com\r\n")
+		assert.Contains(t, server.data, "Time:  2025-03-01T12:30:00Z\r\n")
+		assert.Contains(t, server.data, "Old IP: 203.0.113.10\r\nNew IP: 198.51.100.77\r\n")
+		assert.Contains(t, server.data, "  - home.example.com (was 203.0.113.10)\r\n  - vpn.example.com\r\n")
+		assert.Contains(t, server.data, "\r\nSome previous values are the last known values, the records could not be read.\r\n")
+	})
+
+	t.Run("authenticates with user name and password", func(t *testing.T) {
+		server := newFakeSMTP(t)
+		notifier := notification.NewEmailNotifier(&config.EmailConfig{
+			SMTPHost:     "127.0.0.1",
+			SMTPPort:     server.port(),
+			SMTPUsername: "user",
+			SMTPPassword: "secret",
+			From:         "ipfailover@example.com",
+			To:           []string{"ops@example.com"},
+		}, zap.NewNop())
+
+		require.NoError(t, notifier.Notify(context.Background(), failover))
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Equal(t, "\x00user\x00secret", server.auth)
+		assert.NotEmpty(t, server.data)
+	})
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		server := newFakeSMTP(t)
+		notifier := notification.NewEmailNotifier(&config.EmailConfig{
+			SMTPHost:     "127.0.0.1",
+			SMTPPort:     server.port(),
+			SMTPUsername: "user",
+			SMTPPassword: "wrong",
+			From:         "ipfailover@example.com",
+			To:           []string{"ops@example.com"},
+		}, zap.NewNop())
+
+		err := notifier.Notify(context.Background(), failover)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "SMTP authentication failed")
+	})
+
+	t.Run("progress and recurring errors are not mailed", func(t *testing.T) {
+		server := newFakeSMTP(t)
+		notifier := notification.NewEmailNotifier(&config.EmailConfig{
+			SMTPHost: "127.0.0.1",
+			SMTPPort: server.port(),
+			From:     "ipfailover@example.com",
+			To:       []string{"ops@example.com"},
+		}, zap.NewNop())
+
+		for _, eventType := range []string{notification.TypeFailoverStarted, notification.TypeDNSUpdateFailed, notification.TypeIPCheckFailed} {
+			event := failover
+			event.Type = eventType
+			require.NoError(t, notifier.Notify(context.Background(), event))
+		}
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Empty(t, server.data)
+	})
+
+	t.Run("subject template", func(t *testing.T) {
+		server := newFakeSMTP(t)
+		notifier := notification.NewEmailNotifier(&config.EmailConfig{
+			SMTPHost:        "127.0.0.1",
+			SMTPPort:        server.port(),
+			From:            "ipfailover@example.com",
+			To:              []string{"ops@example.com"},
+			SubjectTemplate: "[home] {{.Type}} to {{.ToIP}}\n({{len .Records}} records)",
+		}, zap.NewNop())
+
+		require.NoError(t, notifier.Notify(context.Background(), failover))
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Contains(t, server.data, "Subject: [home] failover to 198.51.100.77 (2 records)\r\n")
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		require.NoError(t, listener.Close())
+
+		notifier := notification.NewEmailNotifier(&config.EmailConfig{
+			SMTPHost: "127.0.0.1",
+			SMTPPort: port,
+			From:     "ipfailover@example.com",
+			To:       []string{"ops@example.com"},
+		}, zap.NewNop())
+
+		err = notifier.Notify(context.Background(), failover)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to connect to 127.0.0.1:"+strconv.Itoa(port))
+	})
+}
+
+func TestEmailNotifier_SendDigest(t *testing.T) {
+	server := newFakeSMTP(t)
+	notifier := notification.NewEmailNotifier(&config.EmailConfig{
+		SMTPHost: "127.0.0.1",
+		SMTPPort: server.port(),
+		From:     "ipfailover@example.com",
+		To:       []string{"ops@example.com"},
+	}, zap.NewNop())
+
+	require.NoError(t, notifier.SendDigest(context.Background(), notification.DigestSummary{
+		Since:          time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+		Until:          time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC),
+		Checks:         2880,
+		CheckFailures:  3,
+		Failovers:      1,
+		Failbacks:      1,
+		UpdateFailures: 0,
+		Incidents:      1,
+		Errors:         []string{"2025-03-01T14:02:00Z IP check failed: all endpoints failed"},
+		DroppedErrors:  2,
+	}))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, []string{"RCPT TO:<ops@example.com>"}, server.to)
+	assert.Contains(t, server.data, "Subject: [ipfailover] Daily digest: 2880 checks, 3 failed\r\n")
+	assert.Contains(t, server.data, "Summary from 2025-03-01T08:00:00Z to 2025-03-02T08:00:00Z\r\n")
+	assert.Contains(t, server.data, "Failovers:          1\r\n")
+	assert.Contains(t, server.data, "Provider incidents: 1\r\n")
+	assert.Contains(t, server.data, "  - 2025-03-01T14:02:00Z IP check failed: all endpoints failed\r\n  and 2 earlier errors\r\n")
+}
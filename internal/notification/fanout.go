@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/multierr"
+)
+
+// Notification event types, see interfaces.NotificationEvent
+const (
+	TypeFailover         = "failover"          // DNS records were changed away from the primary IP
+	TypeFailback         = "failback"          // DNS records were changed back to the primary IP
+	TypeFailoverStarted  = "failover_started"  // DNS records are about to be changed away from the primary IP
+	TypeDNSUpdateFailed  = "dns_update_failed" // DNS records could not be changed to the target IP
+	TypeIPCheckFailed    = "ip_check_failed"   // The public IP could not be detected
+	TypeIncidentOpened   = "incident_opened"   // DNS updates of a provider failed incident_threshold times in a row
+	TypeIncidentResolved = "incident_resolved" // DNS updates of a provider with an open incident succeeded again
+	TypeError            = "error"
+	TypeTest             = "test" // Sent on startup to check the notification settings
+)
+
+// FanOutNotifier delivers each event to all of its notification providers concurrently
+type FanOutNotifier struct {
+	providers []interfaces.NotificationProvider
+}
+
+// NewFanOutNotifier creates a notifier delivering to providers
+func NewFanOutNotifier(providers ...interfaces.NotificationProvider) *FanOutNotifier {
+	return &FanOutNotifier{providers: providers}
+}
+
+// Name returns the notification channel name
+func (f *FanOutNotifier) Name() string {
+	return "fanout"
+}
+
+// Providers returns the notification providers events are delivered to
+func (f *FanOutNotifier) Providers() []interfaces.NotificationProvider {
+	return f.providers
+}
+
+// Notify delivers the event to all providers and waits for them. A failing provider does not
+// keep the event from the others; the errors of all failing providers are combined.
+func (f *FanOutNotifier) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	errs := make([]error, len(f.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range f.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := provider.Notify(ctx, event); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", provider.Name(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return multierr.Combine(errs...)
+}
@@ -0,0 +1,108 @@
+package notification_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+)
+
+// recordingProvider records the events it receives and fails with err
+type recordingProvider struct {
+	name    string
+	err     error
+	release chan struct{} // If set, Notify blocks until it is closed
+	mu      sync.Mutex
+	events  []interfaces.NotificationEvent
+}
+
+func (p *recordingProvider) Name() string {
+	return p.name
+}
+
+func (p *recordingProvider) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	if p.release != nil {
+		<-p.release
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func TestFanOutNotifier_Notify(t *testing.T) {
+	event := interfaces.NotificationEvent{
+		Type:      notification.TypeFailover,
+		FromIP:    "203.0.113.10",
+		ToIP:      "198.51.100.77",
+		Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+	}
+
+	t.Run("delivers to all providers", func(t *testing.T) {
+		email := &recordingProvider{name: "email"}
+		pagerDuty := &recordingProvider{name: "pagerduty"}
+		fanOut := notification.NewFanOutNotifier(email, pagerDuty)
+
+		require.NoError(t, fanOut.Notify(context.Background(), event))
+		assert.Equal(t, []interfaces.NotificationEvent{event}, email.events)
+		assert.Equal(t, []interfaces.NotificationEvent{event}, pagerDuty.events)
+	})
+
+	t.Run("providers are called concurrently", func(t *testing.T) {
+		// The first provider only returns once the second has been called
+		release := make(chan struct{})
+		slow := &recordingProvider{name: "slow", release: release}
+		fast := &fanOutReleaser{release: release}
+		fanOut := notification.NewFanOutNotifier(slow, fast)
+
+		done := make(chan error, 1)
+		go func() { done <- fanOut.Notify(context.Background(), event) }()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("fan-out notifier called its providers sequentially")
+		}
+		assert.Len(t, slow.events, 1)
+	})
+
+	t.Run("collects the errors of all failing providers", func(t *testing.T) {
+		email := &recordingProvider{name: "email", err: errors.New("SMTP server unreachable")}
+		webhook := &recordingProvider{name: "webhook"}
+		pagerDuty := &recordingProvider{name: "pagerduty", err: errors.New("invalid routing key")}
+		fanOut := notification.NewFanOutNotifier(email, webhook, pagerDuty)
+
+		err := fanOut.Notify(context.Background(), event)
+		require.Error(t, err)
+		assert.Len(t, multierr.Errors(err), 2)
+		assert.Contains(t, err.Error(), "email: SMTP server unreachable")
+		assert.Contains(t, err.Error(), "pagerduty: invalid routing key")
+		assert.Len(t, webhook.events, 1, "a failing provider does not keep the event from the others")
+	})
+
+	t.Run("no providers", func(t *testing.T) {
+		assert.NoError(t, notification.NewFanOutNotifier().Notify(context.Background(), event))
+	})
+}
+
+// fanOutReleaser closes release when notified
+type fanOutReleaser struct {
+	release chan struct{}
+}
+
+func (r *fanOutReleaser) Name() string {
+	return "releaser"
+}
+
+func (r *fanOutReleaser) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	close(r.release)
+	return nil
+}
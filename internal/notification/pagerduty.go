@@ -0,0 +1,175 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// DefaultPagerDutyEndpoint is the PagerDuty Events API v2 enqueue URL
+const DefaultPagerDutyEndpoint = "https://events.pagerduty.com/v2/enqueue"
+
+// defaultPagerDutySeverity is the severity of alerts unless configured
+const defaultPagerDutySeverity = "critical"
+
+// PagerDuty event actions
+const (
+	pagerDutyTrigger = "trigger"
+	pagerDutyResolve = "resolve"
+)
+
+// PagerDutyNotifier delivers failover and failback events to the PagerDuty Events API v2. Failing over
+// triggers an alert, and failing back to the primary IP resolves it: both use a dedup key derived
+// from the primary IP, so PagerDuty matches the resolve to the alert.
+type PagerDutyNotifier struct {
+	config   *config.PagerDutyConfig
+	endpoint string
+	client   *http.Client
+	logger   *zap.Logger
+}
+
+// pagerDutyEvent is an Events API v2 request; Payload is only sent with triggers
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	Component     string                 `json:"component"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// pagerDutyResponse is the Events API v2 response body
+type pagerDutyResponse struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Errors  []string `json:"errors"`
+}
+
+// NewPagerDutyNotifier creates a new PagerDuty notifier
+func NewPagerDutyNotifier(cfg *config.PagerDutyConfig, logger *zap.Logger) *PagerDutyNotifier {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultPagerDutyEndpoint
+	}
+
+	return &PagerDutyNotifier{
+		config:   cfg,
+		endpoint: endpoint,
+		client:   &http.Client{},
+		logger:   logger,
+	}
+}
+
+// PagerDutyDedupKey returns the dedup key of the alerts for a failover group with the given primary IP
+func PagerDutyDedupKey(primaryIP string) string {
+	return "ipfailover-" + primaryIP
+}
+
+// Name returns the notification channel name
+func (n *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+// Notify triggers an alert for a failover away from the primary IP and resolves it on failback.
+// Other events are not sent.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	var pdEvent pagerDutyEvent
+	switch event.Type {
+	case TypeFailover:
+		// The primary IP is the one failed over from
+		severity := n.config.Severity
+		if severity == "" {
+			severity = defaultPagerDutySeverity
+		}
+
+		pdEvent = pagerDutyEvent{
+			RoutingKey:  n.config.IntegrationKey,
+			EventAction: pagerDutyTrigger,
+			DedupKey:    PagerDutyDedupKey(event.FromIP),
+			Payload: &pagerDutyPayload{
+				Summary:   fmt.Sprintf("ipfailover: primary IP %s failed, DNS records moved to %s", event.FromIP, event.ToIP),
+				Source:    event.FromIP,
+				Severity:  severity,
+				Timestamp: event.Timestamp.UTC().Format(time.RFC3339),
+				Component: "ipfailover",
+				CustomDetails: map[string]interface{}{
+					"from_ip":         event.FromIP,
+					"to_ip":           event.ToIP,
+					"records":         event.Records,
+					"previous_values": event.PreviousValues,
+				},
+			},
+		}
+	case TypeFailback:
+		pdEvent = pagerDutyEvent{
+			RoutingKey:  n.config.IntegrationKey,
+			EventAction: pagerDutyResolve,
+			DedupKey:    PagerDutyDedupKey(event.ToIP),
+		}
+	default:
+		return nil
+	}
+
+	if err := n.send(ctx, pdEvent); err != nil {
+		return fmt.Errorf("failed to send PagerDuty %s event: %w", pdEvent.EventAction, err)
+	}
+
+	n.logger.Debug("PagerDuty event sent",
+		zap.String("event_action", pdEvent.EventAction),
+		zap.String("dedup_key", pdEvent.DedupKey),
+	)
+	return nil
+}
+
+// send posts event to the Events API
+func (n *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// The Events API accepts events with 202 Accepted
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	var result pagerDutyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result); err != nil || result.Message == "" {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%s: %s (%s)", resp.Status, result.Message, strings.Join(result.Errors, "; "))
+	}
+	return fmt.Errorf("%s: %s", resp.Status, result.Message)
+}
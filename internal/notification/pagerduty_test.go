@@ -0,0 +1,118 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakePagerDuty is a minimal Events API v2 that records the events it accepts
+type fakePagerDuty struct {
+	t      *testing.T
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (f *fakePagerDuty) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var event map[string]interface{}
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&event))
+	if event["routing_key"] != "integration-key" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "invalid event",
+			"message": "Event object is invalid",
+			"errors":  []string{"Invalid routing key"},
+		})
+		return
+	}
+
+	f.events = append(f.events, event)
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Event processed"})
+}
+
+func newPagerDutyTestNotifier(t *testing.T, cfg config.PagerDutyConfig) (*notification.PagerDutyNotifier, *fakePagerDuty) {
+	fake := &fakePagerDuty{t: t}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg.Endpoint = server.URL + "/v2/enqueue"
+	return notification.NewPagerDutyNotifier(&cfg, zap.NewNop()), fake
+}
+
+func TestPagerDutyNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	failover := interfaces.NotificationEvent{
+		Type:      notification.TypeFailover,
+		FromIP:    "203.0.113.10",
+		ToIP:      "198.51.100.77",
+		Records:   []string{"home.example.com"},
+		Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+	}
+	failback := failover
+	failback.Type = notification.TypeFailback
+	failback.FromIP, failback.ToIP = failover.ToIP, failover.FromIP
+
+	t.Run("failover triggers and failback resolves", func(t *testing.T) {
+		notifier, fake := newPagerDutyTestNotifier(t, config.PagerDutyConfig{IntegrationKey: "integration-key"})
+
+		require.NoError(t, notifier.Notify(ctx, failover))
+		require.NoError(t, notifier.Notify(ctx, failback))
+
+		require.Len(t, fake.events, 2)
+		trigger, resolve := fake.events[0], fake.events[1]
+
+		assert.Equal(t, "trigger", trigger["event_action"])
+		assert.Equal(t, "ipfailover-203.0.113.10", trigger["dedup_key"])
+		payload := trigger["payload"].(map[string]interface{})
+		assert.Equal(t, "critical", payload["severity"])
+		assert.Equal(t, "203.0.113.10", payload["source"])
+		assert.Equal(t, "2025-03-01T12:30:00Z", payload["timestamp"])
+		assert.Contains(t, payload["summary"], "198.51.100.77")
+
+		assert.Equal(t, "resolve", resolve["event_action"])
+		assert.Equal(t, trigger["dedup_key"], resolve["dedup_key"])
+		assert.NotContains(t, resolve, "payload")
+	})
+
+	t.Run("configured severity", func(t *testing.T) {
+		notifier, fake := newPagerDutyTestNotifier(t, config.PagerDutyConfig{IntegrationKey: "integration-key", Severity: "warning"})
+
+		require.NoError(t, notifier.Notify(ctx, failover))
+		require.Len(t, fake.events, 1)
+		assert.Equal(t, "warning", fake.events[0]["payload"].(map[string]interface{})["severity"])
+	})
+
+	t.Run("other events are not sent", func(t *testing.T) {
+		notifier, fake := newPagerDutyTestNotifier(t, config.PagerDutyConfig{IntegrationKey: "integration-key"})
+
+		require.NoError(t, notifier.Notify(ctx, interfaces.NotificationEvent{
+			Type:    notification.TypeTest,
+			Message: "test notification",
+		}))
+		assert.Empty(t, fake.events)
+	})
+
+	t.Run("rejected event", func(t *testing.T) {
+		notifier, _ := newPagerDutyTestNotifier(t, config.PagerDutyConfig{IntegrationKey: "wrong-key"})
+
+		err := notifier.Notify(ctx, failover)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to send PagerDuty trigger event")
+		assert.Contains(t, err.Error(), "Invalid routing key")
+	})
+}
@@ -0,0 +1,195 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/retry"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"go.uber.org/zap"
+)
+
+// Headers of the webhook requests
+const (
+	WebhookSignatureHeader = "X-Ipfailover-Signature" // "sha256=" and the hex HMAC-SHA256 of the body, with a secret
+	WebhookEventHeader     = "X-Ipfailover-Event"     // The event of the payload
+)
+
+// Webhook event names, see webhookEvent
+const (
+	webhookFailoverCompleted = "failover_completed"
+	webhookFailbackCompleted = "failback_completed"
+)
+
+// webhookRetryPolicy retries failed deliveries, so that the last retry is made about 7s after
+// the first attempt, well within the timeout of a notification
+var webhookRetryPolicy = retry.Policy{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	Jitter:     retry.DefaultJitter,
+}
+
+// WebhookNotifier delivers every notification event as JSON to an HTTP endpoint. Deliveries that fail
+// with a network error or a retryable status, such as 429 or 5xx, are retried with exponential backoff.
+type WebhookNotifier struct {
+	config *config.NotificationWebhookConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// webhookPayload is the request body
+type webhookPayload struct {
+	Event     string    `json:"event"`
+	FromIP    string    `json:"from_ip,omitempty"`
+	ToIP      string    `json:"to_ip,omitempty"`
+	Records   []string  `json:"records,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+
+	PreviousValues map[string]string `json:"previous_values,omitempty"`
+	Cached         bool              `json:"previous_values_cached,omitempty"`
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(cfg *config.NotificationWebhookConfig, logger *zap.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		config: cfg,
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// Name returns the notification channel name
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// webhookEvent returns the event name of a notification type. Completed IP changes are named after
+// their completion, to tell them from TypeFailoverStarted; other types keep their name.
+func webhookEvent(eventType string) string {
+	switch eventType {
+	case TypeFailover:
+		return webhookFailoverCompleted
+	case TypeFailback:
+		return webhookFailbackCompleted
+	default:
+		return eventType
+	}
+}
+
+// Notify sends the event, retrying failed deliveries until ctx ends
+func (n *WebhookNotifier) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	payload := webhookPayload{
+		Event:     webhookEvent(event.Type),
+		FromIP:    event.FromIP,
+		ToIP:      event.ToIP,
+		Records:   event.Records,
+		Provider:  event.Provider,
+		Timestamp: event.Timestamp.UTC(),
+		Message:   event.Message,
+
+		PreviousValues: event.PreviousValues,
+		Cached:         event.Cached,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := n.send(ctx, payload.Event, body)
+		if err == nil {
+			break
+		}
+		if attempt > webhookRetryPolicy.MaxRetries || !retryableDelivery(err) || ctx.Err() != nil {
+			return fmt.Errorf("failed to send webhook %s event: %w", payload.Event, err)
+		}
+
+		delay := webhookRetryPolicy.Delay(attempt)
+		n.logger.Warn("webhook notification failed, retrying",
+			zap.String("url", n.config.URL),
+			zap.String("event", payload.Event),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("failed to send webhook %s event: %w", payload.Event, err)
+		case <-timer.C:
+		}
+	}
+
+	n.logger.Debug("webhook notification sent",
+		zap.String("url", n.config.URL),
+		zap.String("event", payload.Event),
+	)
+	return nil
+}
+
+// retryableDelivery reports whether a failed delivery is retried: after network errors, and
+// statuses that errors.IsRetryableError accepts
+func retryableDelivery(err error) bool {
+	var httpErr *errors.HTTPError
+	if stderrors.As(err, &httpErr) {
+		return errors.IsRetryableError(err)
+	}
+	return true
+}
+
+// send makes one delivery attempt, failing with an errors.HTTPError for unsuccessful statuses
+func (n *WebhookNotifier) send(ctx context.Context, event string, body []byte) error {
+	method := n.config.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range n.config.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	if n.config.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(n.config.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode/100 != 2 {
+		return errors.NewHTTPError(resp.StatusCode, req.URL.Redacted(), fmt.Errorf("unexpected status %s", resp.Status))
+	}
+	return nil
+}
+
+// WebhookSignature returns the value of the WebhookSignatureHeader of a request body signed with secret
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,182 @@
+package notification_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/config"
+	"github.com/devhat/ipfailover/internal/notification"
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeWebhook records the requests it receives, failing the first failures of them with status
+type fakeWebhook struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	failures int
+	status   int
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body)
+	if len(f.requests) <= f.failures {
+		w.WriteHeader(f.status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newWebhookTestNotifier(t *testing.T, fake *fakeWebhook, cfg config.NotificationWebhookConfig) *notification.WebhookNotifier {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg.URL = server.URL + "/hooks/ipfailover"
+	return notification.NewWebhookNotifier(&cfg, zap.NewNop())
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	failover := interfaces.NotificationEvent{
+		Type:      notification.TypeFailover,
+		FromIP:    "203.0.113.10",
+		ToIP:      "198.51.100.77",
+		Records:   []string{"home.example.com"},
+		Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+		Message:   "DNS records were changed from 203.0.113.10 to 198.51.100.77",
+
+		PreviousValues: map[string]string{"home.example.com": "203.0.113.10"},
+		Cached:         true,
+	}
+
+	t.Run("payload and signature", func(t *testing.T) {
+		fake := &fakeWebhook{}
+		notifier := newWebhookTestNotifier(t, fake, config.NotificationWebhookConfig{
+			Headers: map[string]string{"Authorization": "Bearer ops-token"},
+			Secret:  "signing-secret",
+		})
+
+		require.NoError(t, notifier.Notify(ctx, failover))
+
+		require.Len(t, fake.requests, 1)
+		req, body := fake.requests[0], fake.bodies[0]
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/hooks/ipfailover", req.URL.Path)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer ops-token", req.Header.Get("Authorization"))
+		assert.Equal(t, "failover_completed", req.Header.Get(notification.WebhookEventHeader))
+		assert.JSONEq(t, `{
+			"event": "failover_completed",
+			"from_ip": "203.0.113.10",
+			"to_ip": "198.51.100.77",
+			"records": ["home.example.com"],
+			"timestamp": "2025-03-01T12:30:00Z",
+			"message": "DNS records were changed from 203.0.113.10 to 198.51.100.77",
+			"previous_values": {"home.example.com": "203.0.113.10"},
+			"previous_values_cached": true
+		}`, string(body))
+
+		// The receiver recomputes the signature of the body with the shared secret
+		signature := req.Header.Get(notification.WebhookSignatureHeader)
+		assert.Equal(t, "sha256=", signature[:7])
+		assert.True(t, hmac.Equal([]byte(notification.WebhookSignature("signing-secret", body)), []byte(signature)))
+		assert.NotEqual(t, notification.WebhookSignature("other-secret", body), signature)
+	})
+
+	t.Run("event names", func(t *testing.T) {
+		fake := &fakeWebhook{}
+		notifier := newWebhookTestNotifier(t, fake, config.NotificationWebhookConfig{Method: http.MethodPut})
+
+		for _, eventType := range []string{
+			notification.TypeFailoverStarted,
+			notification.TypeFailback,
+			notification.TypeDNSUpdateFailed,
+			notification.TypeIPCheckFailed,
+			notification.TypeIncidentOpened,
+			notification.TypeIncidentResolved,
+		} {
+			event := failover
+			event.Type = eventType
+			require.NoError(t, notifier.Notify(ctx, event))
+		}
+
+		var events []string
+		for i, body := range fake.bodies {
+			var payload map[string]interface{}
+			require.NoError(t, json.Unmarshal(body, &payload))
+			events = append(events, payload["event"].(string))
+			assert.Equal(t, http.MethodPut, fake.requests[i].Method)
+			assert.Empty(t, fake.requests[i].Header.Get(notification.WebhookSignatureHeader), "signed without a secret")
+		}
+		assert.Equal(t, []string{"failover_started", "failback_completed", "dns_update_failed", "ip_check_failed",
+			"incident_opened", "incident_resolved"}, events)
+	})
+
+	t.Run("incident payload", func(t *testing.T) {
+		fake := &fakeWebhook{}
+		notifier := newWebhookTestNotifier(t, fake, config.NotificationWebhookConfig{})
+
+		require.NoError(t, notifier.Notify(ctx, interfaces.NotificationEvent{
+			Type:      notification.TypeIncidentOpened,
+			Provider:  "cloudflare",
+			Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+			Message:   "incident opened: 3 consecutive DNS update failures for provider cloudflare",
+		}))
+
+		require.Len(t, fake.bodies, 1)
+		assert.JSONEq(t, `{
+			"event": "incident_opened",
+			"provider": "cloudflare",
+			"timestamp": "2025-03-01T12:30:00Z",
+			"message": "incident opened: 3 consecutive DNS update failures for provider cloudflare"
+		}`, string(fake.bodies[0]))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		fake := &fakeWebhook{failures: 1, status: http.StatusServiceUnavailable}
+		notifier := newWebhookTestNotifier(t, fake, config.NotificationWebhookConfig{Secret: "signing-secret"})
+
+		require.NoError(t, notifier.Notify(ctx, failover))
+		require.Len(t, fake.bodies, 2)
+		assert.Equal(t, fake.bodies[0], fake.bodies[1])
+		assert.Equal(t, fake.requests[0].Header.Get(notification.WebhookSignatureHeader), fake.requests[1].Header.Get(notification.WebhookSignatureHeader))
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		fake := &fakeWebhook{failures: 1, status: http.StatusBadRequest}
+		notifier := newWebhookTestNotifier(t, fake, config.NotificationWebhookConfig{})
+
+		err := notifier.Notify(ctx, failover)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to send webhook failover_completed event")
+		assert.Contains(t, err.Error(), "400 Bad Request")
+		assert.Len(t, fake.requests, 1)
+	})
+
+	t.Run("retries end with the context", func(t *testing.T) {
+		fake := &fakeWebhook{failures: 10, status: http.StatusBadGateway}
+		notifier := newWebhookTestNotifier(t, fake, config.NotificationWebhookConfig{})
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		err := notifier.Notify(ctx, failover)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "502 Bad Gateway")
+		assert.Len(t, fake.requests, 1)
+	})
+}
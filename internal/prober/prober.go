@@ -0,0 +1,170 @@
+package prober
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CheckFunc checks whether the given IP address is reachable
+type CheckFunc func(ctx context.Context, ip string) error
+
+// staleIntervals is the number of probe intervals after which a probe result is stale, see FreshStatus
+const staleIntervals = 2
+
+// Ticker abstracts time.Ticker so probing can be driven by a fake clock in tests
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Clock abstracts time so probing can be driven by a fake clock in tests
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// realClock implements Clock using the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return &realTicker{ticker: time.NewTicker(d)} }
+
+// realTicker implements Ticker using time.Ticker
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time { return t.ticker.C }
+
+func (t *realTicker) Stop() { t.ticker.Stop() }
+
+// TargetStatus is the aggregated probe state for a single target
+type TargetStatus struct {
+	Reachable            bool
+	ConsecutiveFailures  int
+	ConsecutiveSuccesses int
+	LastProbe            time.Time
+	LastError            error
+}
+
+// Prober periodically checks reachability of a fixed set of targets independently of the main poll loop
+type Prober struct {
+	targets  []string
+	interval time.Duration
+	timeout  time.Duration // Bounds each probe, the interval unless set with SetTimeout
+	check    CheckFunc
+	clock    Clock
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	statuses map[string]TargetStatus
+}
+
+// NewProber creates a new prober using the real clock
+func NewProber(targets []string, interval time.Duration, check CheckFunc, logger *zap.Logger) *Prober {
+	return NewProberWithClock(targets, interval, check, realClock{}, logger)
+}
+
+// NewProberWithClock creates a new prober driven by the given clock
+func NewProberWithClock(targets []string, interval time.Duration, check CheckFunc, clock Clock, logger *zap.Logger) *Prober {
+	return &Prober{
+		targets:  targets,
+		interval: interval,
+		timeout:  interval,
+		check:    check,
+		clock:    clock,
+		logger:   logger,
+		statuses: make(map[string]TargetStatus),
+	}
+}
+
+// SetTimeout sets the timeout of each probe, by default the probe interval. It must be called before Run.
+func (p *Prober) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		p.timeout = timeout
+	}
+}
+
+// Run probes all targets immediately and then on every interval until the context is cancelled
+func (p *Prober) Run(ctx context.Context) {
+	p.logger.Info("starting reachability prober",
+		zap.Strings("targets", p.targets),
+		zap.Duration("interval", p.interval),
+	)
+
+	ticker := p.clock.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.ProbeOnce(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("stopping reachability prober")
+			return
+		case <-ticker.C():
+			p.ProbeOnce(ctx)
+		}
+	}
+}
+
+// ProbeOnce checks every target once and updates their streaks
+func (p *Prober) ProbeOnce(ctx context.Context) {
+	for _, target := range p.targets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		err := p.check(checkCtx, target)
+		cancel()
+		now := p.clock.Now()
+
+		p.mu.Lock()
+		status := p.statuses[target]
+		status.LastProbe = now
+		status.LastError = err
+		if err != nil {
+			status.Reachable = false
+			status.ConsecutiveFailures++
+			status.ConsecutiveSuccesses = 0
+		} else {
+			status.Reachable = true
+			status.ConsecutiveSuccesses++
+			status.ConsecutiveFailures = 0
+		}
+		p.statuses[target] = status
+		p.mu.Unlock()
+
+		p.logger.Debug("probed target",
+			zap.String("target", target),
+			zap.Bool("reachable", status.Reachable),
+			zap.Int("consecutive_failures", status.ConsecutiveFailures),
+			zap.Int("consecutive_successes", status.ConsecutiveSuccesses),
+			zap.Error(err),
+		)
+	}
+}
+
+// Status returns the latest probe state for the target and whether it has been probed yet
+func (p *Prober) Status(target string) (TargetStatus, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status, ok := p.statuses[target]
+	return status, ok
+}
+
+// FreshStatus returns the latest probe state for the target like Status, unless it is older than
+// two probe intervals, such as when probes hang or the prober stopped
+func (p *Prober) FreshStatus(target string) (TargetStatus, bool) {
+	status, ok := p.Status(target)
+	if !ok || p.clock.Now().Sub(status.LastProbe) > staleIntervals*p.interval {
+		return TargetStatus{}, false
+	}
+	return status, true
+}
@@ -0,0 +1,243 @@
+package prober_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/prober"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeClock implements prober.Clock with manually driven ticks
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	ticker *fakeTicker
+	ready  chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ready: make(chan struct{}),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) prober.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ticker = &fakeTicker{ch: make(chan time.Time)}
+	close(c.ready)
+	return c.ticker
+}
+
+// Advance moves the clock forward and fires the ticker
+func (c *fakeClock) Advance(d time.Duration) {
+	<-c.ready
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	ticker := c.ticker
+	c.mu.Unlock()
+	ticker.ch <- now
+}
+
+// Forward moves the clock forward without firing the ticker
+func (c *fakeClock) Forward(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type fakeTicker struct {
+	ch chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() {}
+
+// scriptedCheck returns reachability results from a per-target script and signals each probe
+type scriptedCheck struct {
+	mu      sync.Mutex
+	results map[string][]error
+	probed  chan string
+}
+
+func (s *scriptedCheck) check(ctx context.Context, ip string) error {
+	s.mu.Lock()
+	var err error
+	if script := s.results[ip]; len(script) > 0 {
+		err = script[0]
+		s.results[ip] = script[1:]
+	}
+	s.mu.Unlock()
+
+	s.probed <- ip
+	return err
+}
+
+func waitForProbes(t *testing.T, probed <-chan string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-probed:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for probe %d of %d", i+1, n)
+		}
+	}
+}
+
+func TestProber_ProbeOnce(t *testing.T) {
+	errDown := fmt.Errorf("connection refused")
+	check := &scriptedCheck{
+		results: map[string][]error{
+			"203.0.113.10": {errDown, errDown, nil},
+		},
+		probed: make(chan string, 10),
+	}
+
+	p := prober.NewProberWithClock([]string{"203.0.113.10"}, 5*time.Second, check.check, newFakeClock(), zap.NewNop())
+
+	_, ok := p.Status("203.0.113.10")
+	assert.False(t, ok)
+
+	p.ProbeOnce(context.Background())
+	p.ProbeOnce(context.Background())
+
+	status, ok := p.Status("203.0.113.10")
+	require.True(t, ok)
+	assert.False(t, status.Reachable)
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, 0, status.ConsecutiveSuccesses)
+	assert.Equal(t, errDown, status.LastError)
+
+	p.ProbeOnce(context.Background())
+
+	status, ok = p.Status("203.0.113.10")
+	require.True(t, ok)
+	assert.True(t, status.Reachable)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Equal(t, 1, status.ConsecutiveSuccesses)
+	assert.NoError(t, status.LastError)
+}
+
+func TestProber_Run(t *testing.T) {
+	errDown := fmt.Errorf("connection refused")
+	check := &scriptedCheck{
+		results: map[string][]error{
+			"203.0.113.10":  {errDown, errDown, errDown},
+			"198.51.100.77": {nil, nil, nil},
+		},
+		probed: make(chan string, 10),
+	}
+	clock := newFakeClock()
+
+	p := prober.NewProberWithClock([]string{"203.0.113.10", "198.51.100.77"}, 5*time.Second, check.check, clock, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	// Initial probe happens immediately
+	waitForProbes(t, check.probed, 2)
+
+	clock.Advance(5 * time.Second)
+	waitForProbes(t, check.probed, 2)
+
+	clock.Advance(5 * time.Second)
+	waitForProbes(t, check.probed, 2)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("prober did not stop after context cancellation")
+	}
+
+	primary, ok := p.Status("203.0.113.10")
+	require.True(t, ok)
+	assert.False(t, primary.Reachable)
+	assert.Equal(t, 3, primary.ConsecutiveFailures)
+	assert.Equal(t, clock.Now(), primary.LastProbe)
+
+	secondary, ok := p.Status("198.51.100.77")
+	require.True(t, ok)
+	assert.True(t, secondary.Reachable)
+	assert.Equal(t, 3, secondary.ConsecutiveSuccesses)
+}
+
+func TestProber_FreshStatus(t *testing.T) {
+	check := &scriptedCheck{
+		results: map[string][]error{},
+		probed:  make(chan string, 10),
+	}
+	clock := newFakeClock()
+
+	p := prober.NewProberWithClock([]string{"203.0.113.10"}, 5*time.Second, check.check, clock, zap.NewNop())
+
+	_, ok := p.FreshStatus("203.0.113.10")
+	assert.False(t, ok)
+
+	p.ProbeOnce(context.Background())
+
+	status, ok := p.FreshStatus("203.0.113.10")
+	require.True(t, ok)
+	assert.True(t, status.Reachable)
+
+	clock.Forward(10 * time.Second)
+	_, ok = p.FreshStatus("203.0.113.10")
+	assert.True(t, ok, "a result two intervals old is still fresh")
+
+	clock.Forward(time.Second)
+	_, ok = p.FreshStatus("203.0.113.10")
+	assert.False(t, ok, "a result older than two intervals is stale")
+
+	_, ok = p.Status("203.0.113.10")
+	assert.True(t, ok, "Status keeps returning stale results")
+
+	p.ProbeOnce(context.Background())
+	_, ok = p.FreshStatus("203.0.113.10")
+	assert.True(t, ok)
+}
+
+func TestProber_ProbeTimeout(t *testing.T) {
+	hang := func(ctx context.Context, ip string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	p := prober.NewProberWithClock([]string{"203.0.113.10"}, time.Minute, hang, newFakeClock(), zap.NewNop())
+	p.SetTimeout(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		p.ProbeOnce(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("probe was not bounded by the timeout")
+	}
+
+	status, ok := p.Status("203.0.113.10")
+	require.True(t, ok)
+	assert.False(t, status.Reachable)
+	assert.ErrorIs(t, status.LastError, context.DeadlineExceeded)
+}
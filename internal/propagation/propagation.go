@@ -0,0 +1,97 @@
+package propagation
+
+import (
+	"context"
+	"net"
+	"sort"
+	"time"
+)
+
+// Checker confirms that a DNS change propagated by resolving the record from several resolvers
+type Checker struct {
+	// Resolvers are the addresses of the DNS resolvers to query, as host:port
+	Resolvers []string
+
+	// Interval is the time between lookups of a resolver that did not return the value yet.
+	// It also bounds each lookup.
+	Interval time.Duration
+
+	// Timeout is how long Wait polls before giving up
+	Timeout time.Duration
+
+	// LookupHost resolves host using the resolver at address. Nil queries the resolver over the network.
+	LookupHost func(ctx context.Context, resolver, host string) ([]string, error)
+}
+
+// Wait resolves name from every resolver until each returns value among its addresses, or until
+// Timeout elapses or ctx ends. It returns the resolvers that did not confirm the value, sorted.
+func (c *Checker) Wait(ctx context.Context, name, value string) []string {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	lookup := c.LookupHost
+	if lookup == nil {
+		lookup = lookupHost
+	}
+
+	pending := make(map[string]bool, len(c.Resolvers))
+	for _, resolver := range c.Resolvers {
+		pending[resolver] = true
+	}
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		for resolver := range pending {
+			if c.confirmed(ctx, lookup, resolver, name, value) {
+				delete(pending, resolver)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			unconfirmed := make([]string, 0, len(pending))
+			for resolver := range pending {
+				unconfirmed = append(unconfirmed, resolver)
+			}
+			sort.Strings(unconfirmed)
+			return unconfirmed
+		case <-ticker.C:
+		}
+	}
+}
+
+// confirmed reports whether the resolver returns value among the addresses of name
+func (c *Checker) confirmed(ctx context.Context, lookup func(ctx context.Context, resolver, host string) ([]string, error), resolver, name, value string) bool {
+	ctx, cancel := context.WithTimeout(ctx, c.Interval)
+	defer cancel()
+
+	addrs, err := lookup(ctx, resolver, name)
+	if err != nil {
+		return false
+	}
+
+	want := net.ParseIP(value)
+	for _, addr := range addrs {
+		if addr == value || (want != nil && want.Equal(net.ParseIP(addr))) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupHost resolves host by querying the DNS resolver at address directly, bypassing the system configuration
+func lookupHost(ctx context.Context, address, host string) ([]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+	return resolver.LookupHost(ctx, host)
+}
@@ -0,0 +1,103 @@
+package propagation_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/propagation"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolvers answers lookups from a table of addresses per resolver, which tests change over time
+type fakeResolvers struct {
+	mu      sync.Mutex
+	answers map[string][]string
+	lookups map[string]int
+}
+
+func (f *fakeResolvers) set(resolver string, addrs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.answers[resolver] = addrs
+}
+
+func (f *fakeResolvers) LookupHost(ctx context.Context, resolver, host string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups[resolver]++
+	if host != "home.example.com" {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+	addrs, ok := f.answers[resolver]
+	if !ok {
+		return nil, fmt.Errorf("lookup %s on %s: i/o timeout", host, resolver)
+	}
+	return addrs, nil
+}
+
+func newFakeChecker(timeout time.Duration) (*propagation.Checker, *fakeResolvers) {
+	fake := &fakeResolvers{answers: make(map[string][]string), lookups: make(map[string]int)}
+	return &propagation.Checker{
+		Resolvers:  []string{"8.8.8.8:53", "1.1.1.1:53"},
+		Interval:   10 * time.Millisecond,
+		Timeout:    timeout,
+		LookupHost: fake.LookupHost,
+	}, fake
+}
+
+func TestChecker_Wait(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("confirmed by all resolvers", func(t *testing.T) {
+		checker, fake := newFakeChecker(time.Second)
+		fake.set("8.8.8.8:53", "203.0.113.10")
+		fake.set("1.1.1.1:53", "192.0.2.1", "203.0.113.10")
+
+		assert.Empty(t, checker.Wait(ctx, "home.example.com", "203.0.113.10"))
+		assert.Equal(t, 1, fake.lookups["8.8.8.8:53"])
+	})
+
+	t.Run("polls until the change propagated", func(t *testing.T) {
+		checker, fake := newFakeChecker(5 * time.Second)
+		fake.set("8.8.8.8:53", "203.0.113.10")
+		fake.set("1.1.1.1:53", "198.51.100.77")
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			fake.set("1.1.1.1:53", "203.0.113.10")
+		}()
+
+		assert.Empty(t, checker.Wait(ctx, "home.example.com", "203.0.113.10"))
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		assert.Equal(t, 1, fake.lookups["8.8.8.8:53"], "confirmed resolvers are not queried again")
+		assert.Greater(t, fake.lookups["1.1.1.1:53"], 1)
+	})
+
+	t.Run("timeout returns unconfirmed resolvers", func(t *testing.T) {
+		checker, fake := newFakeChecker(100 * time.Millisecond)
+		fake.set("1.1.1.1:53", "203.0.113.10")
+
+		assert.Equal(t, []string{"8.8.8.8:53"}, checker.Wait(ctx, "home.example.com", "203.0.113.10"))
+	})
+
+	t.Run("IPv6 addresses compare by value", func(t *testing.T) {
+		checker, fake := newFakeChecker(time.Second)
+		fake.set("8.8.8.8:53", "2001:db8::1")
+		fake.set("1.1.1.1:53", "2001:0db8:0000::0001")
+
+		assert.Empty(t, checker.Wait(ctx, "home.example.com", "2001:db8::1"))
+	})
+
+	t.Run("context end stops waiting", func(t *testing.T) {
+		checker, _ := newFakeChecker(time.Hour)
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, []string{"1.1.1.1:53", "8.8.8.8:53"}, checker.Wait(ctx, "home.example.com", "203.0.113.10"))
+	})
+}
@@ -0,0 +1,81 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/devhat/ipfailover/pkg/errors"
+)
+
+// DefaultJitter is the fraction by which delays are randomly shortened or lengthened
+const DefaultJitter = 0.1
+
+// Policy configures retries with exponential backoff
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry. It doubles with every further retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries before jitter is applied. Zero is uncapped.
+	MaxDelay time.Duration
+
+	// Jitter randomly changes each delay by up to this fraction, e.g. 0.1 for ±10%
+	Jitter float64
+
+	// Float returns a random number in [0, 1) for the jitter. Nil uses math/rand.
+	Float func() float64
+}
+
+// Delay returns the delay before retry number attempt, starting at 1, including jitter
+func (p Policy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		if delay > time.Duration(math.MaxInt64/2) {
+			break // Doubling would overflow
+		}
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		random := rand.Float64
+		if p.Float != nil {
+			random = p.Float
+		}
+		// Scale by a factor in [1-Jitter, 1+Jitter)
+		delay = time.Duration(float64(delay) * (1 + p.Jitter*(2*random()-1)))
+	}
+
+	return delay
+}
+
+// Do calls fn until it succeeds, returns an error that errors.IsRetryableError rejects, or
+// MaxRetries retries are used up, waiting Delay between attempts. onRetry, if non-nil, is
+// called before each retry with its number, the error of the previous attempt and the delay.
+// Do returns the last error, also if ctx ends while waiting.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error, onRetry func(attempt int, err error, delay time.Duration)) error {
+	err := fn(ctx)
+	for attempt := 1; err != nil && attempt <= p.MaxRetries && errors.IsRetryableError(err); attempt++ {
+		delay := p.Delay(attempt)
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		err = fn(ctx)
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devhat/ipfailover/internal/retry"
+	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+	policy := retry.Policy{
+		BaseDelay: 500 * time.Millisecond,
+		MaxDelay:  30 * time.Second,
+	}
+
+	assert.Equal(t, 500*time.Millisecond, policy.Delay(1))
+	assert.Equal(t, time.Second, policy.Delay(2))
+	assert.Equal(t, 2*time.Second, policy.Delay(3))
+	assert.Equal(t, 16*time.Second, policy.Delay(6))
+	assert.Equal(t, 30*time.Second, policy.Delay(7), "capped at MaxDelay")
+	assert.Equal(t, 30*time.Second, policy.Delay(100))
+
+	t.Run("jitter", func(t *testing.T) {
+		policy.Jitter = 0.1
+
+		policy.Float = func() float64 { return 0 }
+		assert.Equal(t, 900*time.Millisecond, policy.Delay(2))
+		assert.Equal(t, 27*time.Second, policy.Delay(10), "jitter applies after the cap")
+
+		policy.Float = func() float64 { return 0.5 }
+		assert.Equal(t, time.Second, policy.Delay(2))
+
+		policy.Float = func() float64 { return 0.999 }
+		assert.InDelta(t, float64(1100*time.Millisecond), float64(policy.Delay(2)), float64(time.Millisecond))
+
+		policy.Float = nil
+		for i := 0; i < 100; i++ {
+			delay := policy.Delay(2)
+			assert.GreaterOrEqual(t, delay, 900*time.Millisecond)
+			assert.Less(t, delay, 1100*time.Millisecond)
+		}
+	})
+
+	t.Run("uncapped", func(t *testing.T) {
+		policy := retry.Policy{BaseDelay: time.Second}
+		assert.Equal(t, 1024*time.Second, policy.Delay(11))
+		assert.Positive(t, policy.Delay(200), "does not overflow")
+	})
+}
+
+func TestDo(t *testing.T) {
+	policy := retry.Policy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Millisecond,
+	}
+	retryable := errors.NewHTTPError(http.StatusServiceUnavailable, "https://api.example.com", fmt.Errorf("unavailable"))
+	permanent := errors.NewHTTPError(http.StatusUnauthorized, "https://api.example.com", fmt.Errorf("unauthorized"))
+
+	t.Run("succeeds after retryable errors", func(t *testing.T) {
+		calls := 0
+		var retries []int
+		err := retry.Do(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return retryable
+			}
+			return nil
+		}, func(attempt int, err error, delay time.Duration) {
+			retries = append(retries, attempt)
+			assert.Equal(t, retryable, err)
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{1, 2}, retries)
+	})
+
+	t.Run("gives up after MaxRetries", func(t *testing.T) {
+		calls := 0
+		err := retry.Do(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return retryable
+		}, nil)
+
+		assert.Equal(t, retryable, err)
+		assert.Equal(t, 4, calls, "first attempt plus 3 retries")
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		calls := 0
+		err := retry.Do(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return permanent
+		}, nil)
+
+		assert.Equal(t, permanent, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("zero retries", func(t *testing.T) {
+		calls := 0
+		err := retry.Do(context.Background(), retry.Policy{}, func(ctx context.Context) error {
+			calls++
+			return retryable
+		}, nil)
+
+		assert.Equal(t, retryable, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stops waiting when the context ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := retry.Policy{MaxRetries: 3, BaseDelay: time.Hour}
+
+		calls := 0
+		err := retry.Do(ctx, slow, func(ctx context.Context) error {
+			calls++
+			return retryable
+		}, func(attempt int, err error, delay time.Duration) {
+			cancel()
+		})
+
+		assert.Equal(t, retryable, err)
+		assert.Equal(t, 1, calls)
+	})
+}
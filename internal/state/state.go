@@ -6,21 +6,44 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	pkgerrors "github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
 	"go.uber.org/zap"
 )
 
+// CurrentSchemaVersion is the version of the persisted state format
+const CurrentSchemaVersion = 1
+
 // State represents the application state
 type State struct {
+	SchemaVersion       int       `json:"schema_version"`
 	LastAppliedIP       string    `json:"last_applied_ip"`
 	LastChangeTime      time.Time `json:"last_change_time"`
 	LastCheckTime       time.Time `json:"last_check_time"`
 	LastCheckIP         string    `json:"last_check_ip"`
 	UpdateCount         int       `json:"update_count"`
 	PrimaryFailureCount int       `json:"primary_failure_count"`
+	// FailureCountByIP holds the consecutive reachability failures of fallback IPs that failed since
+	// they were last reachable
+	FailureCountByIP map[string]int `json:"failure_count_by_ip,omitempty"`
+	// PrimaryRecoveredAt is when the primary IP became reachable again after a failover,
+	// and PrimarySuccessCount the number of consecutive successful checks since
+	PrimaryRecoveredAt  time.Time `json:"primary_recovered_at,omitzero"`
+	PrimarySuccessCount int       `json:"primary_success_count,omitempty"`
+	// PendingIP is a new target IP that is not applied until PendingIPCount consecutive polls selected it
+	PendingIP      string `json:"pending_ip,omitempty"`
+	PendingIPCount int    `json:"pending_ip_count,omitempty"`
+	// InLowTTLMode is set while the DNS records use the pre-failover TTL instead of the configured one
+	InLowTTLMode bool `json:"in_low_ttl_mode,omitempty"`
+	// ForcedTarget is the target pinned by a manual failover, "primary" or "secondary". Automatic
+	// failover decisions are suspended while it is set.
+	ForcedTarget string `json:"forced_target,omitempty"`
+	// ProviderFailureStreaks holds the failure streaks of providers whose last DNS update failed
+	ProviderFailureStreaks map[string]interfaces.ProviderFailureStreak `json:"provider_failure_streaks,omitempty"`
 }
 
 // FileStateStore implements StateStore using a JSON file
@@ -38,6 +61,24 @@ func NewFileStateStore(filePath string, logger *zap.Logger) *FileStateStore {
 	}
 }
 
+// RecordStateFile returns the path of the state file of a DNS record that fails over on its own.
+// It is stored next to stateFile, with the state name of the record inserted before the extension,
+// e.g. state.cdn.example.com_A_cloudflare.json for cdn.example.com/A/cloudflare. Characters other
+// than letters, digits, '-', '_' and '.' are replaced by '_'.
+func RecordStateFile(stateFile, record string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, record)
+
+	ext := filepath.Ext(stateFile)
+	return strings.TrimSuffix(stateFile, ext) + "." + name + ext
+}
+
 // GetLastAppliedIP returns the last IP that was successfully applied
 func (f *FileStateStore) GetLastAppliedIP(ctx context.Context) (string, error) {
 	if err := ctx.Err(); err != nil {
@@ -214,6 +255,89 @@ func (f *FileStateStore) GetUpdateCount(ctx context.Context) (int, error) {
 	return state.UpdateCount, nil
 }
 
+// ExportState returns the persisted state as pretty-printed JSON
+func (f *FileStateStore) ExportState(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return nil, err // Return the not found error directly
+		}
+		return nil, pkgerrors.NewStateError("export_state", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, pkgerrors.NewStateError("export_state", fmt.Errorf("failed to marshal state: %w", err))
+	}
+
+	return data, nil
+}
+
+// ImportState validates the given JSON state and replaces the persisted state with it
+func (f *FileStateStore) ImportState(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return pkgerrors.NewStateError("import_state", fmt.Errorf("failed to unmarshal state: %w", err))
+	}
+
+	if state.SchemaVersion != CurrentSchemaVersion {
+		return pkgerrors.NewStateError("import_state",
+			fmt.Errorf("incompatible schema version %d, expected %d", state.SchemaVersion, CurrentSchemaVersion))
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.saveState(ctx, &state); err != nil {
+		return pkgerrors.NewStateError("import_state", err)
+	}
+
+	f.logger.Info("state imported",
+		zap.String("state_file", f.filePath),
+		zap.String("last_applied_ip", state.LastAppliedIP),
+	)
+
+	return nil
+}
+
+// ResetState deletes the state file, so the next poll starts with a fresh state, and logs the
+// reset with reason. It reports whether a state file existed.
+func (f *FileStateStore) ResetState(ctx context.Context, reason string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	err := os.Remove(f.filePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, pkgerrors.NewStateError("reset_state", fmt.Errorf("failed to remove state file: %w", err))
+	}
+
+	f.logger.Info("state reset",
+		zap.String("state_file", f.filePath),
+		zap.String("reason", reason),
+		zap.Time("reset_at", time.Now()),
+	)
+
+	return true, nil
+}
+
 // loadState loads the state from the file
 func (f *FileStateStore) loadState(ctx context.Context) (*State, error) {
 	// Check if file exists
@@ -247,6 +371,11 @@ func (f *FileStateStore) loadState(ctx context.Context) (*State, error) {
 			return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 		}
 
+		// State files written before versioning was introduced use the first schema
+		if state.SchemaVersion == 0 {
+			state.SchemaVersion = CurrentSchemaVersion
+		}
+
 		return &state, nil
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -261,6 +390,8 @@ func (f *FileStateStore) saveState(ctx context.Context, state *State) error {
 		return fmt.Errorf("failed to create state directory: %w", err)
 	}
 
+	state.SchemaVersion = CurrentSchemaVersion
+
 	// Marshal state to JSON
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
@@ -316,6 +447,14 @@ type MockStateStore struct {
 	lastCheckTime       time.Time
 	updateCount         int
 	primaryFailureCount int
+	failureCountByIP    map[string]int
+	primaryRecoveredAt  time.Time
+	primarySuccessCount int
+	pendingIP           string
+	pendingIPCount      int
+	lowTTLMode          bool
+	forcedTarget        string
+	failureStreaks      map[string]interfaces.ProviderFailureStreak
 	mutex               sync.RWMutex
 }
 
@@ -435,6 +574,224 @@ func (m *MockStateStore) ResetPrimaryFailureCount(ctx context.Context) error {
 	return m.SetPrimaryFailureCount(ctx, 0)
 }
 
+// GetFailureCount returns the consecutive reachability failure count of a fallback IP
+func (m *MockStateStore) GetFailureCount(ctx context.Context, ip string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.failureCountByIP[ip], nil
+}
+
+// SetFailureCount sets the consecutive reachability failure count of a fallback IP
+func (m *MockStateStore) SetFailureCount(ctx context.Context, ip string, count int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.failureCountByIP = setFailureCount(m.failureCountByIP, ip, count)
+	return nil
+}
+
+// GetPrimaryRecovery returns the primary IP recovery time and consecutive success count
+func (m *MockStateStore) GetPrimaryRecovery(ctx context.Context) (time.Time, int, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.primaryRecoveredAt, m.primarySuccessCount, nil
+}
+
+// SetPrimaryRecovery stores the primary IP recovery time and consecutive success count
+func (m *MockStateStore) SetPrimaryRecovery(ctx context.Context, recoveredAt time.Time, successCount int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.primaryRecoveredAt = recoveredAt
+	m.primarySuccessCount = successCount
+	return nil
+}
+
+// ResetPrimaryRecovery clears the pending primary IP recovery
+func (m *MockStateStore) ResetPrimaryRecovery(ctx context.Context) error {
+	return m.SetPrimaryRecovery(ctx, time.Time{}, 0)
+}
+
+// GetPendingIP returns the pending IP and its consecutive occurrence count
+func (m *MockStateStore) GetPendingIP(ctx context.Context) (string, int, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.pendingIP, m.pendingIPCount, nil
+}
+
+// SetPendingIP stores the pending IP and its consecutive occurrence count
+func (m *MockStateStore) SetPendingIP(ctx context.Context, ip string, count int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pendingIP = ip
+	m.pendingIPCount = count
+	return nil
+}
+
+// ResetPendingIP clears the pending IP
+func (m *MockStateStore) ResetPendingIP(ctx context.Context) error {
+	return m.SetPendingIP(ctx, "", 0)
+}
+
+// GetLowTTLMode reports whether the DNS records use the pre-failover TTL
+func (m *MockStateStore) GetLowTTLMode(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.lowTTLMode, nil
+}
+
+// SetLowTTLMode stores whether the DNS records use the pre-failover TTL
+func (m *MockStateStore) SetLowTTLMode(ctx context.Context, enabled bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lowTTLMode = enabled
+	return nil
+}
+
+// GetForcedTarget returns the target pinned by a manual failover
+func (m *MockStateStore) GetForcedTarget(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.forcedTarget, nil
+}
+
+// SetForcedTarget stores the target pinned by a manual failover
+func (m *MockStateStore) SetForcedTarget(ctx context.Context, target string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.forcedTarget = target
+	return nil
+}
+
+// GetProviderFailureStreaks returns a copy of the provider failure streaks
+func (m *MockStateStore) GetProviderFailureStreaks(ctx context.Context) (map[string]interfaces.ProviderFailureStreak, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return copyFailureStreaks(m.failureStreaks), nil
+}
+
+// SetProviderFailureStreak stores the failure streak of a provider
+func (m *MockStateStore) SetProviderFailureStreak(ctx context.Context, streak interfaces.ProviderFailureStreak) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.failureStreaks == nil {
+		m.failureStreaks = make(map[string]interfaces.ProviderFailureStreak)
+	}
+	m.failureStreaks[streak.Provider] = streak
+	return nil
+}
+
+// ResetProviderFailureStreak removes the failure streak of a provider
+func (m *MockStateStore) ResetProviderFailureStreak(ctx context.Context, provider string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.failureStreaks, provider)
+	return nil
+}
+
+// DryRunStateStore is an in-memory state store seeded from persisted state.
+// Reads are served from the snapshot and writes are kept in memory only,
+// so failure counting works normally while the persisted state is never modified.
+type DryRunStateStore struct {
+	MockStateStore
+}
+
+// NewDryRunStateStore creates a dry-run state store seeded from the given store.
+// Missing state is treated as empty; other read errors are logged and the value left unset.
+func NewDryRunStateStore(ctx context.Context, base interfaces.StateStore, logger *zap.Logger) *DryRunStateStore {
+	store := &DryRunStateStore{}
+
+	logReadErr := func(field string, err error) {
+		if err != nil && !pkgerrors.IsNotFoundError(err) {
+			logger.Warn("failed to read persisted state for dry run",
+				zap.String("field", field),
+				zap.Error(err),
+			)
+		}
+	}
+
+	var err error
+	store.lastAppliedIP, err = base.GetLastAppliedIP(ctx)
+	logReadErr("last_applied_ip", err)
+
+	store.lastChangeTime, err = base.GetLastChangeTime(ctx)
+	logReadErr("last_change_time", err)
+
+	store.lastCheckIP, store.lastCheckTime, err = base.GetLastCheckInfo(ctx)
+	logReadErr("last_check_info", err)
+
+	store.primaryFailureCount, err = base.GetPrimaryFailureCount(ctx)
+	logReadErr("primary_failure_count", err)
+	// The failure counts of fallback IPs start at zero, since they are read by IP
+
+	store.primaryRecoveredAt, store.primarySuccessCount, err = base.GetPrimaryRecovery(ctx)
+	logReadErr("primary_recovery", err)
+
+	store.pendingIP, store.pendingIPCount, err = base.GetPendingIP(ctx)
+	logReadErr("pending_ip", err)
+
+	store.lowTTLMode, err = base.GetLowTTLMode(ctx)
+	logReadErr("in_low_ttl_mode", err)
+
+	store.forcedTarget, err = base.GetForcedTarget(ctx)
+	logReadErr("forced_target", err)
+
+	store.failureStreaks, err = base.GetProviderFailureStreaks(ctx)
+	logReadErr("provider_failure_streaks", err)
+
+	return store
+}
+
 // GetPrimaryFailureCount returns the current consecutive failure count for primary IP
 func (f *FileStateStore) GetPrimaryFailureCount(ctx context.Context) (int, error) {
 	if err := ctx.Err(); err != nil {
@@ -492,3 +849,332 @@ func (f *FileStateStore) SetPrimaryFailureCount(ctx context.Context, count int)
 func (f *FileStateStore) ResetPrimaryFailureCount(ctx context.Context) error {
 	return f.SetPrimaryFailureCount(ctx, 0)
 }
+
+// GetFailureCount returns the consecutive reachability failure count of a fallback IP
+func (f *FileStateStore) GetFailureCount(ctx context.Context, ip string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return 0, err // Return the not found error directly
+		}
+		return 0, pkgerrors.NewStateError("get_failure_count", err)
+	}
+
+	return state.FailureCountByIP[ip], nil
+}
+
+// SetFailureCount sets the consecutive reachability failure count of a fallback IP, zero removes it
+func (f *FileStateStore) SetFailureCount(ctx context.Context, ip string, count int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		// Missing or corrupted state files are replaced, as in the other setters
+		state = &State{}
+	}
+
+	if state.FailureCountByIP[ip] == count {
+		return nil
+	}
+	state.FailureCountByIP = setFailureCount(state.FailureCountByIP, ip, count)
+
+	if err := f.saveState(ctx, state); err != nil {
+		return pkgerrors.NewStateError("set_failure_count", err)
+	}
+
+	return nil
+}
+
+// setFailureCount sets the count of ip in counts, allocating counts if needed and removing zero counts
+func setFailureCount(counts map[string]int, ip string, count int) map[string]int {
+	if count == 0 {
+		delete(counts, ip)
+		return counts
+	}
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[ip] = count
+	return counts
+}
+
+// GetPrimaryRecovery returns the primary IP recovery time and consecutive success count
+func (f *FileStateStore) GetPrimaryRecovery(ctx context.Context) (time.Time, int, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return time.Time{}, 0, err // Return the not found error directly
+		}
+		return time.Time{}, 0, pkgerrors.NewStateError("get_primary_recovery", err)
+	}
+
+	return state.PrimaryRecoveredAt, state.PrimarySuccessCount, nil
+}
+
+// SetPrimaryRecovery stores the primary IP recovery time and consecutive success count
+func (f *FileStateStore) SetPrimaryRecovery(ctx context.Context, recoveredAt time.Time, successCount int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		// Missing or corrupted state files are replaced, as in the other setters
+		state = &State{}
+	}
+
+	state.PrimaryRecoveredAt = recoveredAt
+	state.PrimarySuccessCount = successCount
+
+	if err := f.saveState(ctx, state); err != nil {
+		return pkgerrors.NewStateError("set_primary_recovery", err)
+	}
+
+	return nil
+}
+
+// ResetPrimaryRecovery clears the pending primary IP recovery
+func (f *FileStateStore) ResetPrimaryRecovery(ctx context.Context) error {
+	return f.SetPrimaryRecovery(ctx, time.Time{}, 0)
+}
+
+// GetPendingIP returns the pending IP and its consecutive occurrence count
+func (f *FileStateStore) GetPendingIP(ctx context.Context) (string, int, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return "", 0, err // Return the not found error directly
+		}
+		return "", 0, pkgerrors.NewStateError("get_pending_ip", err)
+	}
+
+	return state.PendingIP, state.PendingIPCount, nil
+}
+
+// SetPendingIP stores the pending IP and its consecutive occurrence count
+func (f *FileStateStore) SetPendingIP(ctx context.Context, ip string, count int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		// Missing or corrupted state files are replaced, as in the other setters
+		state = &State{}
+	}
+
+	state.PendingIP = ip
+	state.PendingIPCount = count
+
+	if err := f.saveState(ctx, state); err != nil {
+		return pkgerrors.NewStateError("set_pending_ip", err)
+	}
+
+	return nil
+}
+
+// ResetPendingIP clears the pending IP
+func (f *FileStateStore) ResetPendingIP(ctx context.Context) error {
+	return f.SetPendingIP(ctx, "", 0)
+}
+
+// GetLowTTLMode reports whether the DNS records use the pre-failover TTL
+func (f *FileStateStore) GetLowTTLMode(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return false, err // Return the not found error directly
+		}
+		return false, pkgerrors.NewStateError("get_low_ttl_mode", err)
+	}
+
+	return state.InLowTTLMode, nil
+}
+
+// SetLowTTLMode stores whether the DNS records use the pre-failover TTL
+func (f *FileStateStore) SetLowTTLMode(ctx context.Context, enabled bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		// Missing or corrupted state files are replaced, as in the other setters
+		state = &State{}
+	}
+
+	state.InLowTTLMode = enabled
+
+	if err := f.saveState(ctx, state); err != nil {
+		return pkgerrors.NewStateError("set_low_ttl_mode", err)
+	}
+
+	return nil
+}
+
+// GetForcedTarget returns the target pinned by a manual failover
+func (f *FileStateStore) GetForcedTarget(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return "", err // Return the not found error directly
+		}
+		return "", pkgerrors.NewStateError("get_forced_target", err)
+	}
+
+	return state.ForcedTarget, nil
+}
+
+// SetForcedTarget stores the target pinned by a manual failover, an empty target clears it
+func (f *FileStateStore) SetForcedTarget(ctx context.Context, target string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		// Missing or corrupted state files are replaced, as in the other setters
+		state = &State{}
+	}
+
+	state.ForcedTarget = target
+
+	if err := f.saveState(ctx, state); err != nil {
+		return pkgerrors.NewStateError("set_forced_target", err)
+	}
+
+	return nil
+}
+
+// GetProviderFailureStreaks returns the failure streaks of providers whose last DNS update failed
+func (f *FileStateStore) GetProviderFailureStreaks(ctx context.Context) (map[string]interfaces.ProviderFailureStreak, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		if pkgerrors.IsNotFoundError(err) {
+			return nil, err // Return the not found error directly
+		}
+		return nil, pkgerrors.NewStateError("get_provider_failure_streaks", err)
+	}
+
+	return state.ProviderFailureStreaks, nil
+}
+
+// SetProviderFailureStreak stores the failure streak of a provider
+func (f *FileStateStore) SetProviderFailureStreak(ctx context.Context, streak interfaces.ProviderFailureStreak) error {
+	return f.updateProviderFailureStreaks(ctx, "set_provider_failure_streak", func(streaks map[string]interfaces.ProviderFailureStreak) {
+		streaks[streak.Provider] = streak
+	})
+}
+
+// ResetProviderFailureStreak removes the failure streak of a provider
+func (f *FileStateStore) ResetProviderFailureStreak(ctx context.Context, provider string) error {
+	return f.updateProviderFailureStreaks(ctx, "reset_provider_failure_streak", func(streaks map[string]interfaces.ProviderFailureStreak) {
+		delete(streaks, provider)
+	})
+}
+
+// updateProviderFailureStreaks applies update to the persisted provider failure streaks
+func (f *FileStateStore) updateProviderFailureStreaks(
+	ctx context.Context,
+	operation string,
+	update func(streaks map[string]interfaces.ProviderFailureStreak),
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, err := f.loadState(ctx)
+	if err != nil {
+		// Missing or corrupted state files are replaced, as in the other setters
+		state = &State{}
+	}
+
+	if state.ProviderFailureStreaks == nil {
+		state.ProviderFailureStreaks = make(map[string]interfaces.ProviderFailureStreak)
+	}
+	update(state.ProviderFailureStreaks)
+	if len(state.ProviderFailureStreaks) == 0 {
+		state.ProviderFailureStreaks = nil
+	}
+
+	if err := f.saveState(ctx, state); err != nil {
+		return pkgerrors.NewStateError(operation, err)
+	}
+
+	return nil
+}
+
+// copyFailureStreaks returns a copy of streaks so callers cannot modify the stored map
+func copyFailureStreaks(streaks map[string]interfaces.ProviderFailureStreak) map[string]interfaces.ProviderFailureStreak {
+	if streaks == nil {
+		return nil
+	}
+
+	copied := make(map[string]interfaces.ProviderFailureStreak, len(streaks))
+	for provider, streak := range streaks {
+		copied[provider] = streak
+	}
+	return copied
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/devhat/ipfailover/internal/state"
 	"github.com/devhat/ipfailover/pkg/errors"
+	"github.com/devhat/ipfailover/pkg/interfaces"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -148,6 +149,23 @@ func TestFileStateStore_AtomicWrite(t *testing.T) {
 	assert.Equal(t, "203.0.113.10", stateData["last_applied_ip"])
 }
 
+func TestRecordStateFile(t *testing.T) {
+	tests := []struct {
+		stateFile string
+		record    string
+		want      string
+	}{
+		{"/var/lib/ipfailover/state.json", "cdn.example.com", "/var/lib/ipfailover/state.cdn.example.com.json"},
+		{"/var/lib/ipfailover/state", "cdn.example.com", "/var/lib/ipfailover/state.cdn.example.com"},
+		{"state.json", "*.example.com", "state._.example.com.json"},
+		{"state.json", "cdn.example.com/A/cloudflare", "state.cdn.example.com_A_cloudflare.json"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, state.RecordStateFile(tt.stateFile, tt.record))
+	}
+}
+
 func TestMockStateStore(t *testing.T) {
 	t.Run("GetLastAppliedIP", func(t *testing.T) {
 		store := state.NewMockStateStore()
@@ -221,3 +239,343 @@ func TestFileStateStore_CorruptedFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "203.0.113.10", ip)
 }
+
+func TestFileStateStore_ExportImportState(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		tempDir := t.TempDir()
+		logger := zap.NewNop()
+
+		source := state.NewFileStateStore(filepath.Join(tempDir, "source.json"), logger)
+		require.NoError(t, source.SetLastAppliedIP(context.Background(), "203.0.113.10"))
+		require.NoError(t, source.SetPrimaryFailureCount(context.Background(), 2))
+
+		data, err := source.ExportState(context.Background())
+		require.NoError(t, err)
+
+		var exported map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &exported))
+		assert.Equal(t, float64(state.CurrentSchemaVersion), exported["schema_version"])
+
+		target := state.NewFileStateStore(filepath.Join(tempDir, "target.json"), logger)
+		require.NoError(t, target.ImportState(context.Background(), data))
+
+		ip, err := target.GetLastAppliedIP(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "203.0.113.10", ip)
+
+		count, err := target.GetPrimaryFailureCount(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("export without state file", func(t *testing.T) {
+		store := state.NewFileStateStore(filepath.Join(t.TempDir(), "state.json"), zap.NewNop())
+
+		_, err := store.ExportState(context.Background())
+		assert.Error(t, err)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+
+	t.Run("import rejects incompatible schema version", func(t *testing.T) {
+		stateFile := filepath.Join(t.TempDir(), "state.json")
+		store := state.NewFileStateStore(stateFile, zap.NewNop())
+
+		err := store.ImportState(context.Background(), []byte(`{"schema_version": 99, "last_applied_ip": "203.0.113.10"}`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "incompatible schema version")
+
+		_, statErr := os.Stat(stateFile)
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("import rejects invalid JSON", func(t *testing.T) {
+		store := state.NewFileStateStore(filepath.Join(t.TempDir(), "state.json"), zap.NewNop())
+
+		err := store.ImportState(context.Background(), []byte("invalid json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestFileStateStore_ResetState(t *testing.T) {
+	t.Run("removes state file", func(t *testing.T) {
+		stateFile := filepath.Join(t.TempDir(), "state.json")
+		store := state.NewFileStateStore(stateFile, zap.NewNop())
+		require.NoError(t, store.SetLastAppliedIP(context.Background(), "203.0.113.10"))
+
+		existed, err := store.ResetState(context.Background(), "migrating to a new config")
+		require.NoError(t, err)
+		assert.True(t, existed)
+
+		_, err = store.GetLastAppliedIP(context.Background())
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+
+	t.Run("without state file", func(t *testing.T) {
+		store := state.NewFileStateStore(filepath.Join(t.TempDir(), "state.json"), zap.NewNop())
+
+		existed, err := store.ResetState(context.Background(), "")
+		require.NoError(t, err)
+		assert.False(t, existed)
+	})
+}
+
+func TestFileStateStore_ProviderFailureStreaks(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := store.GetProviderFailureStreaks(ctx)
+	assert.True(t, errors.IsNotFoundError(err))
+
+	require.NoError(t, store.SetLastAppliedIP(ctx, "203.0.113.10"))
+
+	firstFailure := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	streak := interfaces.ProviderFailureStreak{
+		Provider:     "cloudflare",
+		FailureCount: 3,
+		FirstFailure: firstFailure,
+		LastFailure:  firstFailure.Add(time.Minute),
+		LastError:    "connection refused",
+	}
+	require.NoError(t, store.SetProviderFailureStreak(ctx, streak))
+	require.NoError(t, store.SetProviderFailureStreak(ctx, interfaces.ProviderFailureStreak{Provider: "route53", FailureCount: 1}))
+
+	// Reopening the file returns the persisted streaks
+	reopened := state.NewFileStateStore(stateFile, zap.NewNop())
+	streaks, err := reopened.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	require.Len(t, streaks, 2)
+	assert.Equal(t, 3, streaks["cloudflare"].FailureCount)
+	assert.True(t, firstFailure.Equal(streaks["cloudflare"].FirstFailure))
+	assert.Equal(t, "connection refused", streaks["cloudflare"].LastError)
+
+	// Other state is left untouched
+	ip, err := reopened.GetLastAppliedIP(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", ip)
+
+	require.NoError(t, reopened.ResetProviderFailureStreak(ctx, "cloudflare"))
+	require.NoError(t, reopened.ResetProviderFailureStreak(ctx, "route53"))
+	streaks, err = reopened.GetProviderFailureStreaks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, streaks)
+
+	data, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "provider_failure_streaks")
+}
+
+func TestFileStateStore_FailureCountByIP(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := store.GetFailureCount(ctx, "198.51.100.77")
+	assert.True(t, errors.IsNotFoundError(err))
+
+	require.NoError(t, store.SetPrimaryFailureCount(ctx, 4))
+	require.NoError(t, store.SetFailureCount(ctx, "198.51.100.77", 2))
+	require.NoError(t, store.SetFailureCount(ctx, "192.0.2.30", 1))
+
+	// Reopening the file returns the persisted counts
+	reopened := state.NewFileStateStore(stateFile, zap.NewNop())
+	count, err := reopened.GetFailureCount(ctx, "198.51.100.77")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = reopened.GetFailureCount(ctx, "203.0.113.99")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Other state is left untouched
+	count, err = reopened.GetPrimaryFailureCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	// Zero counts are removed from the file
+	require.NoError(t, reopened.SetFailureCount(ctx, "198.51.100.77", 0))
+	require.NoError(t, reopened.SetFailureCount(ctx, "192.0.2.30", 0))
+	data, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "failure_count_by_ip")
+}
+
+func TestFileStateStore_PrimaryRecovery(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	_, _, err := store.GetPrimaryRecovery(ctx)
+	assert.True(t, errors.IsNotFoundError(err))
+
+	require.NoError(t, store.SetPrimaryFailureCount(ctx, 4))
+
+	data, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "primary_recovered_at", "no recovery pending")
+
+	recoveredAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, store.SetPrimaryRecovery(ctx, recoveredAt, 2))
+
+	// Reopening the file returns the persisted recovery
+	reopened := state.NewFileStateStore(stateFile, zap.NewNop())
+	gotRecoveredAt, successCount, err := reopened.GetPrimaryRecovery(ctx)
+	require.NoError(t, err)
+	assert.True(t, recoveredAt.Equal(gotRecoveredAt))
+	assert.Equal(t, 2, successCount)
+
+	// Other state is left untouched
+	count, err := reopened.GetPrimaryFailureCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	require.NoError(t, reopened.ResetPrimaryRecovery(ctx))
+	gotRecoveredAt, successCount, err = reopened.GetPrimaryRecovery(ctx)
+	require.NoError(t, err)
+	assert.True(t, gotRecoveredAt.IsZero())
+	assert.Zero(t, successCount)
+}
+
+func TestFileStateStore_PendingIP(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	_, _, err := store.GetPendingIP(ctx)
+	assert.True(t, errors.IsNotFoundError(err))
+
+	require.NoError(t, store.SetLastAppliedIP(ctx, "203.0.113.10"))
+	require.NoError(t, store.SetPendingIP(ctx, "198.51.100.77", 2))
+
+	// Reopening the file returns the persisted pending IP
+	reopened := state.NewFileStateStore(stateFile, zap.NewNop())
+	pendingIP, pendingCount, err := reopened.GetPendingIP(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.77", pendingIP)
+	assert.Equal(t, 2, pendingCount)
+
+	// Other state is left untouched
+	lastAppliedIP, err := reopened.GetLastAppliedIP(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", lastAppliedIP)
+
+	require.NoError(t, reopened.ResetPendingIP(ctx))
+	pendingIP, pendingCount, err = reopened.GetPendingIP(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pendingIP)
+	assert.Zero(t, pendingCount)
+
+	data, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "pending_ip", "no change pending")
+}
+
+func TestFileStateStore_LowTTLMode(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := store.GetLowTTLMode(ctx)
+	assert.True(t, errors.IsNotFoundError(err))
+
+	require.NoError(t, store.SetLastAppliedIP(ctx, "203.0.113.10"))
+	require.NoError(t, store.SetLowTTLMode(ctx, true))
+
+	// Reopening the file returns the persisted mode
+	reopened := state.NewFileStateStore(stateFile, zap.NewNop())
+	lowTTL, err := reopened.GetLowTTLMode(ctx)
+	require.NoError(t, err)
+	assert.True(t, lowTTL)
+
+	lastAppliedIP, err := reopened.GetLastAppliedIP(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", lastAppliedIP)
+
+	require.NoError(t, reopened.SetLowTTLMode(ctx, false))
+	lowTTL, err = reopened.GetLowTTLMode(ctx)
+	require.NoError(t, err)
+	assert.False(t, lowTTL)
+
+	data, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "in_low_ttl_mode")
+}
+
+func TestFileStateStore_ForcedTarget(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := state.NewFileStateStore(stateFile, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := store.GetForcedTarget(ctx)
+	assert.True(t, errors.IsNotFoundError(err))
+
+	require.NoError(t, store.SetLastAppliedIP(ctx, "203.0.113.10"))
+	require.NoError(t, store.SetForcedTarget(ctx, "secondary"))
+
+	// The target persists across restarts
+	reopened := state.NewFileStateStore(stateFile, zap.NewNop())
+	target, err := reopened.GetForcedTarget(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", target)
+
+	lastAppliedIP, err := reopened.GetLastAppliedIP(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", lastAppliedIP)
+
+	require.NoError(t, reopened.SetForcedTarget(ctx, ""))
+	target, err = reopened.GetForcedTarget(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, target)
+
+	data, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "forced_target")
+}
+
+func TestDryRunStateStore(t *testing.T) {
+	t.Run("seeds from persisted state without writing", func(t *testing.T) {
+		stateFile := filepath.Join(t.TempDir(), "state.json")
+		base := state.NewFileStateStore(stateFile, zap.NewNop())
+		require.NoError(t, base.SetLastAppliedIP(context.Background(), "203.0.113.10"))
+		require.NoError(t, base.SetPrimaryFailureCount(context.Background(), 1))
+
+		before, err := os.ReadFile(stateFile)
+		require.NoError(t, err)
+
+		store := state.NewDryRunStateStore(context.Background(), base, zap.NewNop())
+
+		ip, err := store.GetLastAppliedIP(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "203.0.113.10", ip)
+
+		count, err := store.GetPrimaryFailureCount(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		require.NoError(t, store.SetPrimaryFailureCount(context.Background(), 2))
+		require.NoError(t, store.SetLastAppliedIP(context.Background(), "198.51.100.77"))
+		require.NoError(t, store.SetLastCheckInfo(context.Background(), "198.51.100.77", time.Now()))
+
+		ip, err = store.GetLastAppliedIP(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "198.51.100.77", ip)
+
+		after, err := os.ReadFile(stateFile)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("missing state file starts empty", func(t *testing.T) {
+		stateFile := filepath.Join(t.TempDir(), "state.json")
+		base := state.NewFileStateStore(stateFile, zap.NewNop())
+
+		store := state.NewDryRunStateStore(context.Background(), base, zap.NewNop())
+		require.NoError(t, store.SetLastAppliedIP(context.Background(), "203.0.113.10"))
+
+		ip, err := store.GetLastAppliedIP(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "203.0.113.10", ip)
+
+		_, statErr := os.Stat(stateFile)
+		assert.True(t, os.IsNotExist(statErr))
+	})
+}
@@ -0,0 +1,74 @@
+// Package tracing exports OpenTelemetry traces of DNS provider calls and reachability checks
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans
+const tracerName = "github.com/devhat/ipfailover"
+
+// Span attribute keys
+const (
+	AttrProvider    = attribute.Key("dns.provider")
+	AttrRecordName  = attribute.Key("dns.record.name")
+	AttrRecordType  = attribute.Key("dns.record.type")
+	AttrTargetIP    = attribute.Key("ip.target")
+	AttrRecordCount = attribute.Key("dns.record.count")
+)
+
+// Setup installs a global tracer provider exporting spans over OTLP gRPC to endpoint, either
+// host:port, connecting with TLS, or an http:// or https:// URL, http:// connecting without TLS.
+// Spans are batched in the background. The returned function flushes and stops the exporter.
+// An empty endpoint leaves tracing disabled and returns a no-op function.
+func Setup(ctx context.Context, endpoint, version, instance string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	option := otlptracegrpc.WithEndpoint(endpoint)
+	if strings.Contains(endpoint, "://") {
+		option = otlptracegrpc.WithEndpointURL(endpoint)
+	}
+	exporter, err := otlptracegrpc.New(ctx, option)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName("ipfailover"), semconv.ServiceVersion(version)}
+	if instance != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(instance))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span with the attributes. Without Setup the span is a no-op.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it as failed with err if err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,75 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devhat/ipfailover/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartAndEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, span := tracing.Start(context.Background(), "dns.UpdateRecord",
+		tracing.AttrProvider.String("cloudflare"),
+		tracing.AttrRecordName.String("home.example.com"),
+		tracing.AttrRecordType.String("A"),
+		tracing.AttrTargetIP.String("198.51.100.77"),
+	)
+	tracing.End(span, nil)
+
+	_, span = tracing.Start(context.Background(), "reachability.Check", tracing.AttrTargetIP.String("203.0.113.10"))
+	tracing.End(span, errors.New("connection refused"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "dns.UpdateRecord", spans[0].Name)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("dns.provider", "cloudflare"),
+		attribute.String("dns.record.name", "home.example.com"),
+		attribute.String("dns.record.type", "A"),
+		attribute.String("ip.target", "198.51.100.77"),
+	}, spans[0].Attributes)
+
+	assert.Equal(t, "reachability.Check", spans[1].Name)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "connection refused", spans[1].Status.Description)
+	require.Len(t, spans[1].Events, 1)
+	assert.Equal(t, "exception", spans[1].Events[0].Name)
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), "", "1.2.3", "edge-1")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// The exporter connects lazily, so nothing needs to listen on the endpoint
+	for _, endpoint := range []string{"127.0.0.1:4317", "http://127.0.0.1:4317"} {
+		shutdown, err := tracing.Setup(context.Background(), endpoint, "1.2.3", "edge-1")
+		require.NoError(t, err, endpoint)
+		assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		_ = shutdown(ctx)
+		cancel()
+	}
+}
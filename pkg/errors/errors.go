@@ -80,8 +80,26 @@ func (e *HTTPError) Unwrap() error {
 	return e.Err
 }
 
+// ConflictError reports that a DNS record was changed by someone else between being read and updated
+type ConflictError struct {
+	Provider string
+	Record   string
+	Expected string // Value the update was conditioned on, empty if the record was expected not to exist
+	Actual   string // Value found at the provider, empty if the record no longer exists
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("DNS record %s at provider %s was modified concurrently: expected %q, found %q", e.Record, e.Provider, e.Expected, e.Actual)
+}
+
 // IsRetryableError checks if an error is retryable
 func IsRetryableError(err error) bool {
+	// Check for ConflictError - retrying blindly would overwrite the concurrent change
+	var conflictErr *ConflictError
+	if stderrors.As(err, &conflictErr) {
+		return false
+	}
+
 	// Check for HTTPError - unwraps if wrapped
 	var httpErr *HTTPError
 	if stderrors.As(err, &httpErr) {
@@ -124,6 +142,16 @@ func NewDNSProviderError(provider, record string, err error) *DNSProviderError {
 	}
 }
 
+// NewConflictError creates a new DNS record conflict error
+func NewConflictError(provider, record, expected, actual string) *ConflictError {
+	return &ConflictError{
+		Provider: provider,
+		Record:   record,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
 // NewIPCheckError creates a new IP check error
 func NewIPCheckError(service string, err error) *IPCheckError {
 	return &IPCheckError{
@@ -2,6 +2,8 @@ package interfaces
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -15,6 +17,26 @@ type DNSRecord struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// MetadataRole is the DNSRecord metadata key that failover writes set to the role of the
+// written IP, RolePrimary or RoleSecondary. Providers may use it to apply per-role settings,
+// and set it on records returned by GetRecord whose value is not an IP, such as aliases.
+const MetadataRole = "failover_role"
+
+// Roles of the IP written by failover
+const (
+	RolePrimary   = "primary"
+	RoleSecondary = "secondary"
+)
+
+// RecordUpdateResult describes the outcome of a single DNS record update
+type RecordUpdateResult struct {
+	Record        DNSRecord `json:"record"`
+	PreviousValue string    `json:"previous_value,omitempty"`
+	// PreviousValueCached is true when PreviousValue is the last known value from local state
+	// rather than the value read from the provider before the update
+	PreviousValueCached bool `json:"previous_value_cached"`
+}
+
 // DNSProvider defines the interface for DNS operations
 type DNSProvider interface {
 	// Name returns the provider name (e.g., "cloudflare", "cpanel")
@@ -33,6 +55,39 @@ type DNSProvider interface {
 	Validate(ctx context.Context) error
 }
 
+// ConditionalUpdater is implemented by DNS providers that can make an update conditional on the
+// current contents of the record, so that a concurrent external change is detected instead of overwritten
+type ConditionalUpdater interface {
+	// UpdateRecordIf updates or creates a DNS record only if it still has the value and TTL of expected,
+	// as returned by GetRecord. A nil expected means the record must not exist yet.
+	// If the record was changed in the meantime, the returned error is an *errors.ConflictError.
+	UpdateRecordIf(ctx context.Context, record DNSRecord, expected *DNSRecord) error
+}
+
+// BatchUpdater is implemented by DNS providers that can update several records in a single atomic change
+type BatchUpdater interface {
+	// BatchKey identifies the zone and credentials of the provider. Records whose providers have
+	// equal keys can be updated together in one batch by any of these providers.
+	BatchKey() string
+
+	// UpdateRecords updates or creates all records in a single change, which is applied completely or not at all
+	UpdateRecords(ctx context.Context, records []DNSRecord) error
+}
+
+// RecordLister is implemented by DNS providers that can list the records of the zone they manage
+type RecordLister interface {
+	// ListRecords returns all records of the zone. Record sets with several values are listed
+	// as one record per value.
+	ListRecords(ctx context.Context) ([]DNSRecord, error)
+}
+
+// ProxyConfigurable is implemented by DNS providers whose API requests can be sent through an outbound proxy
+type ProxyConfigurable interface {
+	// SetProxy sends the provider's API requests through the proxy selected by proxy, see
+	// http.Transport.Proxy. It must be called before the provider is used.
+	SetProxy(proxy func(*http.Request) (*url.URL, error))
+}
+
 // IPChecker defines the interface for IP detection services
 type IPChecker interface {
 	// GetCurrentIP returns the current public IP address
@@ -42,6 +97,12 @@ type IPChecker interface {
 	Name() string
 }
 
+// HealthChecker defines the interface for checking whether an IP address is reachable
+type HealthChecker interface {
+	// Check returns nil if the IP address is reachable, and why it is not otherwise
+	Check(ctx context.Context, ip string) error
+}
+
 // StateStore defines the interface for persisting application state
 type StateStore interface {
 	// GetLastAppliedIP returns the last IP that was successfully applied
@@ -70,6 +131,91 @@ type StateStore interface {
 
 	// ResetPrimaryFailureCount resets the consecutive failure count for primary IP
 	ResetPrimaryFailureCount(ctx context.Context) error
+
+	// GetFailureCount returns the consecutive reachability failure count of a fallback IP
+	GetFailureCount(ctx context.Context, ip string) (int, error)
+
+	// SetFailureCount sets the consecutive reachability failure count of a fallback IP, zero removes it
+	SetFailureCount(ctx context.Context, ip string, count int) error
+
+	// GetPrimaryRecovery returns when the primary IP became reachable again after a failover and the
+	// number of consecutive successful checks since. A zero time means no recovery is pending.
+	GetPrimaryRecovery(ctx context.Context) (time.Time, int, error)
+
+	// SetPrimaryRecovery stores the primary IP recovery time and consecutive success count
+	SetPrimaryRecovery(ctx context.Context, recoveredAt time.Time, successCount int) error
+
+	// ResetPrimaryRecovery clears the pending primary IP recovery
+	ResetPrimaryRecovery(ctx context.Context) error
+
+	// GetPendingIP returns the IP a DNS change is pending for and the number of consecutive polls
+	// that selected it. An empty IP means no change is pending.
+	GetPendingIP(ctx context.Context) (string, int, error)
+
+	// SetPendingIP stores the pending IP and its consecutive occurrence count
+	SetPendingIP(ctx context.Context, ip string, count int) error
+
+	// ResetPendingIP clears the pending IP
+	ResetPendingIP(ctx context.Context) error
+
+	// GetLowTTLMode reports whether the DNS records were lowered to the pre-failover TTL
+	GetLowTTLMode(ctx context.Context) (bool, error)
+
+	// SetLowTTLMode stores whether the DNS records use the pre-failover TTL
+	SetLowTTLMode(ctx context.Context, enabled bool) error
+
+	// GetForcedTarget returns the target pinned by a manual failover, "primary" or "secondary".
+	// An empty target means failover decisions are automatic.
+	GetForcedTarget(ctx context.Context) (string, error)
+
+	// SetForcedTarget stores the target pinned by a manual failover, an empty target clears it
+	SetForcedTarget(ctx context.Context, target string) error
+
+	// GetProviderFailureStreaks returns the consecutive DNS update failure streaks, keyed by provider
+	GetProviderFailureStreaks(ctx context.Context) (map[string]ProviderFailureStreak, error)
+
+	// SetProviderFailureStreak stores the failure streak of a provider
+	SetProviderFailureStreak(ctx context.Context, streak ProviderFailureStreak) error
+
+	// ResetProviderFailureStreak removes the failure streak of a provider
+	ResetProviderFailureStreak(ctx context.Context, provider string) error
+}
+
+// ProviderFailureStreak tracks consecutive failed DNS updates of a provider.
+// Once the streak crosses the incident threshold an incident is opened, which is closed by the next successful update.
+type ProviderFailureStreak struct {
+	Provider     string    `json:"provider"`
+	FailureCount int       `json:"failure_count"`
+	FirstFailure time.Time `json:"first_failure"`
+	LastFailure  time.Time `json:"last_failure"`
+	LastError    string    `json:"last_error"`
+	// IncidentOpened is when the incident was opened, nil while the streak is below the threshold
+	IncidentOpened *time.Time `json:"incident_opened,omitempty"`
+}
+
+// NotificationEvent is an IP change, error or provider incident reported through the notification providers
+type NotificationEvent struct {
+	Type      string    `json:"type"` // e.g. "failover", "failback", "error", "incident_opened"
+	FromIP    string    `json:"from_ip,omitempty"`
+	ToIP      string    `json:"to_ip,omitempty"`
+	Records   []string  `json:"records,omitempty"`  // Names of the updated DNS records
+	Provider  string    `json:"provider,omitempty"` // DNS provider of an incident
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+
+	// PreviousValues holds the values the updated records had before, by record name
+	PreviousValues map[string]string `json:"previous_values,omitempty"`
+	// Cached is set if some previous values are the last known values from state, as the records were not read
+	Cached bool `json:"previous_values_cached,omitempty"`
+}
+
+// NotificationProvider delivers notification events through one channel, e.g. email or PagerDuty
+type NotificationProvider interface {
+	// Name returns the channel name (e.g., "email", "pagerduty")
+	Name() string
+
+	// Notify delivers an event
+	Notify(ctx context.Context, event NotificationEvent) error
 }
 
 // MetricsCollector defines the interface for metrics collection
@@ -80,18 +226,68 @@ type MetricsCollector interface {
 	// IncrementIPCheckErrors increments the IP check errors counter
 	IncrementIPCheckErrors()
 
-	// IncrementDNSUpdates increments the DNS updates counter
-	IncrementDNSUpdates(provider, record string)
+	// IncrementRecordWrites counts a DNS record that was changed at the provider
+	IncrementRecordWrites(provider, record string)
+
+	// IncrementRecordNoops counts a DNS record that was verified to already hold the target value
+	IncrementRecordNoops(provider, record string)
 
 	// IncrementDNSErrors increments the DNS update errors counter
 	IncrementDNSErrors(provider, record string)
 
+	// IncrementDryRunUpdates increments the counter of DNS updates skipped in dry-run mode
+	IncrementDryRunUpdates(provider, record string)
+
+	// IncrementDNSConflicts increments the counter of DNS updates that found the record modified concurrently
+	IncrementDNSConflicts(provider, record string)
+
+	// IncrementProviderRetries counts a retry of a failed DNS provider call
+	IncrementProviderRetries(provider, record string)
+
+	// IncrementHTTPRetries counts a retried request to a DNS provider API
+	IncrementHTTPRetries(provider string)
+
+	// SetCircuitState sets the circuit breaker state gauge of a DNS provider:
+	// 0 closed, 1 half-open, 2 open
+	SetCircuitState(provider string, state int)
+
+	// ObserveChangeSync records how long a DNS provider took to apply a change on its authoritative servers
+	ObserveChangeSync(provider string, duration time.Duration)
+
+	// ObserveDNSUpdateDuration records how long a DNS provider call writing a record took
+	ObserveDNSUpdateDuration(provider, record string, duration time.Duration)
+
+	// ObserveReachabilityDuration records how long a reachability check of an IP took
+	ObserveReachabilityDuration(ip string, duration time.Duration)
+
+	// SetPrimaryFailureCount sets the consecutive failed reachability checks of a primary IP
+	SetPrimaryFailureCount(primaryIP string, count int)
+
+	// SetStartTime sets the timestamp at which the daemon started
+	SetStartTime(t time.Time)
+
+	// SetBuildInfo sets the version and build time of the daemon
+	SetBuildInfo(version, buildTime string)
+
 	// SetCurrentIP sets the current IP gauge
 	SetCurrentIP(ip string)
 
+	// SetCurrentIPv6 sets the current IPv6 gauge, in dual-stack mode
+	SetCurrentIPv6(ip string)
+
 	// SetLastChangeTime sets the last change timestamp
 	SetLastChangeTime(t time.Time)
 
+	// SetForcedTarget sets the target pinned by a manual failover, empty if decisions are automatic
+	SetForcedTarget(target string)
+
+	// ObserveFailover counts a completed failover, primary_to_secondary, or failback,
+	// secondary_to_primary, and records how long it took
+	ObserveFailover(direction string, duration time.Duration)
+
+	// IncrementChangesSuppressed counts an IP change suppressed by the minimum change interval
+	IncrementChangesSuppressed()
+
 	// StartMetricsServer starts the metrics HTTP server
 	StartMetricsServer(ctx context.Context, addr string) error
 }
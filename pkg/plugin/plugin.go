@@ -0,0 +1,189 @@
+// Package plugin serves and consumes DNS providers running as separate executables.
+// A plugin implements interfaces.DNSProvider and calls Serve from its main function;
+// ipfailover launches it for DNS records configured with the plugin provider type and
+// talks to it over gRPC, see proto/provider.proto.
+package plugin
+
+import (
+	"context"
+
+	"github.com/devhat/ipfailover/pkg/interfaces"
+	"github.com/devhat/ipfailover/pkg/plugin/proto"
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/provider.proto
+
+// ProviderPluginName is the name under which plugins serve their DNS provider
+const ProviderPluginName = "dns_provider"
+
+// Handshake makes sure ipfailover and a plugin speak the same protocol version.
+// It also keeps plugin executables from doing anything when run directly.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "IPFAILOVER_PLUGIN",
+	MagicCookieValue: "dns-provider",
+}
+
+// PluginSet returns the plugins served and dispensed by ipfailover, with impl as the DNS provider
+func PluginSet(impl interfaces.DNSProvider) goplugin.PluginSet {
+	return goplugin.PluginSet{
+		ProviderPluginName: &DNSProviderPlugin{Impl: impl},
+	}
+}
+
+// Serve serves provider as a plugin. It is called from the plugin's main function
+// and returns when ipfailover stops the plugin.
+func Serve(provider interfaces.DNSProvider) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         PluginSet(provider),
+		GRPCServer:      goplugin.DefaultGRPCServer,
+	})
+}
+
+// DNSProviderPlugin is the go-plugin plugin of a DNS provider. Impl is only set in the plugin.
+type DNSProviderPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+	Impl interfaces.DNSProvider
+}
+
+// GRPCServer registers the DNS provider service in the plugin
+func (p *DNSProviderPlugin) GRPCServer(broker *goplugin.GRPCBroker, server *grpc.Server) error {
+	proto.RegisterDNSProviderServer(server, &grpcServer{impl: p.Impl})
+	return nil
+}
+
+// GRPCClient returns the DNS provider client used by ipfailover
+func (p *DNSProviderPlugin) GRPCClient(ctx context.Context, broker *goplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return &GRPCClient{client: proto.NewDNSProviderClient(conn)}, nil
+}
+
+// GRPCClient implements interfaces.DNSProvider by calling a plugin. Errors are
+// gRPC status errors, with the plugin's error message as status message.
+type GRPCClient struct {
+	client proto.DNSProviderClient
+}
+
+// Name returns the provider name reported by the plugin, or "" if the call fails
+func (c *GRPCClient) Name() string {
+	resp, err := c.client.Name(context.Background(), &proto.NameRequest{})
+	if err != nil {
+		return ""
+	}
+	return resp.GetName()
+}
+
+// UpdateRecord updates or creates a DNS record
+func (c *GRPCClient) UpdateRecord(ctx context.Context, record interfaces.DNSRecord) error {
+	_, err := c.client.UpdateRecord(ctx, &proto.UpdateRecordRequest{Record: toProtoRecord(record)})
+	return err
+}
+
+// GetRecord retrieves an existing DNS record, or nil if it does not exist
+func (c *GRPCClient) GetRecord(ctx context.Context, name string, rtype string) (*interfaces.DNSRecord, error) {
+	resp, err := c.client.GetRecord(ctx, &proto.GetRecordRequest{Name: name, Type: rtype})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetRecord() == nil {
+		return nil, nil // Record not found
+	}
+	record := fromProtoRecord(resp.GetRecord())
+	return &record, nil
+}
+
+// DeleteRecord deletes a DNS record
+func (c *GRPCClient) DeleteRecord(ctx context.Context, name, recordType string) error {
+	_, err := c.client.DeleteRecord(ctx, &proto.DeleteRecordRequest{Name: name, Type: recordType})
+	return err
+}
+
+// Validate checks if the provider configuration is valid
+func (c *GRPCClient) Validate(ctx context.Context) error {
+	_, err := c.client.Validate(ctx, &proto.ValidateRequest{})
+	return err
+}
+
+// grpcServer serves a DNS provider in the plugin
+type grpcServer struct {
+	proto.UnimplementedDNSProviderServer
+	impl interfaces.DNSProvider
+}
+
+func (s *grpcServer) Name(ctx context.Context, req *proto.NameRequest) (*proto.NameResponse, error) {
+	return &proto.NameResponse{Name: s.impl.Name()}, nil
+}
+
+func (s *grpcServer) UpdateRecord(ctx context.Context, req *proto.UpdateRecordRequest) (*proto.UpdateRecordResponse, error) {
+	if req.GetRecord() == nil {
+		return nil, status.Error(codes.InvalidArgument, "record is required")
+	}
+	if err := s.impl.UpdateRecord(ctx, fromProtoRecord(req.GetRecord())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.UpdateRecordResponse{}, nil
+}
+
+func (s *grpcServer) GetRecord(ctx context.Context, req *proto.GetRecordRequest) (*proto.GetRecordResponse, error) {
+	record, err := s.impl.GetRecord(ctx, req.GetName(), req.GetType())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if record == nil {
+		return &proto.GetRecordResponse{}, nil
+	}
+	return &proto.GetRecordResponse{Record: toProtoRecord(*record)}, nil
+}
+
+func (s *grpcServer) DeleteRecord(ctx context.Context, req *proto.DeleteRecordRequest) (*proto.DeleteRecordResponse, error) {
+	if err := s.impl.DeleteRecord(ctx, req.GetName(), req.GetType()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.DeleteRecordResponse{}, nil
+}
+
+func (s *grpcServer) Validate(ctx context.Context, req *proto.ValidateRequest) (*proto.ValidateResponse, error) {
+	if err := s.impl.Validate(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.ValidateResponse{}, nil
+}
+
+// toStatus converts a provider error to a gRPC status error, keeping status errors as they are
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch err {
+	case context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	case context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+func toProtoRecord(record interfaces.DNSRecord) *proto.DNSRecord {
+	return &proto.DNSRecord{
+		Name:     record.Name,
+		Type:     record.Type,
+		Value:    record.Value,
+		Ttl:      int64(record.TTL),
+		Metadata: record.Metadata,
+	}
+}
+
+func fromProtoRecord(record *proto.DNSRecord) interfaces.DNSRecord {
+	return interfaces.DNSRecord{
+		Name:     record.GetName(),
+		Type:     record.GetType(),
+		Value:    record.GetValue(),
+		TTL:      int(record.GetTtl()),
+		Metadata: record.GetMetadata(),
+	}
+}